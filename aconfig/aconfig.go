@@ -0,0 +1,104 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aconfig builds aconfig flags: the flags of a package are declared in .aconfig files by
+// an aconfig_declarations module, their values are set by the aconfig_values modules of the
+// aconfig_value_set modules that the release configuration selects, and java_aconfig_srcs and
+// cc_aconfig_srcs generate the code that reads them.
+//
+// The values of the flags of a package only reach the actions of that package, and every action
+// only updates its outputs when their contents change, so flipping the value of a flag only
+// rebuilds the generated code whose contents depend on it, and the modules that use that code.
+package aconfig
+
+import (
+	"android/soong/android"
+)
+
+var pctx = android.NewPackageContext("android/soong/aconfig")
+
+func init() {
+	registerAconfigBuildComponents(android.InitRegistrationContext)
+}
+
+func registerAconfigBuildComponents(ctx android.RegistrationContext) {
+	ctx.RegisterModuleType("aconfig_declarations", DeclarationsFactory)
+	ctx.RegisterModuleType("aconfig_values", ValuesFactory)
+	ctx.RegisterModuleType("aconfig_value_set", ValueSetFactory)
+	ctx.RegisterModuleType("java_aconfig_srcs", JavaAconfigSrcsFactory)
+	ctx.RegisterModuleType("cc_aconfig_srcs", CcAconfigSrcsFactory)
+	ctx.RegisterSingletonType("all_aconfig_declarations", allDeclarationsSingletonFactory)
+}
+
+var PrepareForTestWithAconfigBuildComponents = android.FixtureRegisterWithContext(registerAconfigBuildComponents)
+
+// FixtureSetAconfigValueSets sets the aconfig_value_set modules of the release configuration.
+func FixtureSetAconfigValueSets(valueSets ...string) android.FixturePreparer {
+	return android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+		variables.AconfigValueSets = valueSets
+	})
+}
+
+// updateIfChanged adds a command to a rule that copies a file that the rule wrote to outputPath
+// if their contents are different, and marks the rule as restat, so that the actions that depend
+// on outputPath only run when its contents change.
+func updateIfChanged(rule *android.RuleBuilder, tempPath, outputPath android.WritablePath) {
+	rule.Restat()
+	rule.Temporary(tempPath)
+	rule.Command().
+		Text("if ! cmp -s").Input(tempPath).Output(outputPath).Text(";").
+		Text("then cp").Input(tempPath).Output(outputPath).Text(";").
+		Text("fi")
+}
+
+func allDeclarationsSingletonFactory() android.Singleton {
+	return &allDeclarationsSingleton{}
+}
+
+// allDeclarationsSingleton dumps the flags of all the aconfig_declarations modules, with their
+// values in the current build, into $OUT/soong/all_aconfig_declarations.pb.
+type allDeclarationsSingleton struct {
+	output android.WritablePath
+}
+
+func (s *allDeclarationsSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	var caches android.Paths
+	ctx.VisitAllModules(func(module android.Module) {
+		if !module.Enabled() || !ctx.ModuleHasProvider(module, declarationsProvider) {
+			return
+		}
+		caches = append(caches, ctx.ModuleProvider(module, declarationsProvider).(declarationsInfo).cache)
+	})
+	if len(caches) == 0 {
+		return
+	}
+
+	s.output = android.PathForOutput(ctx, "all_aconfig_declarations.pb")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		BuiltTool("aconfig").
+		Text("dump --dedup --format protobuf").
+		FlagWithOutput("--out ", s.output).
+		FlagForEachInput("--cache ", android.SortedUniquePaths(caches))
+	rule.Build("all_aconfig_declarations", "all aconfig declarations")
+
+	ctx.Phony("all_aconfig_declarations", s.output)
+}
+
+func (s *allDeclarationsSingleton) MakeVars(ctx android.MakeVarsContext) {
+	if s.output == nil {
+		return
+	}
+	ctx.DistForGoal("droid", s.output)
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint/proptools"
+)
+
+var codegenModes = []string{"production", "test", "exported"}
+
+type codegenProperties struct {
+	// The aconfig_declarations module whose flags to generate code for.
+	Aconfig_declarations *string
+
+	// The mode of the generated code: "production", "test", whose flags can be overridden by
+	// tests, or "exported", for code outside of the platform. Defaults to "production".
+	Mode *string
+}
+
+type codegenLanguage int
+
+const (
+	javaCodegen codegenLanguage = iota
+	ccCodegen
+)
+
+// codegenModule generates the code that reads the aconfig flags of an aconfig_declarations
+// module. Java modules use it in their srcs, C++ modules in their srcs and generated_headers.
+type codegenModule struct {
+	android.ModuleBase
+
+	properties codegenProperties
+	language   codegenLanguage
+
+	// The generated .srcjar of Java code, or the generated .cc file of C++ code.
+	outputFile android.WritablePath
+
+	// The generated header of C++ code, and the include directory it is exported from.
+	header    android.WritablePath
+	headerDir android.Path
+}
+
+// java_aconfig_srcs generates a .srcjar with the Java classes that read the aconfig flags of an
+// aconfig_declarations module.
+func JavaAconfigSrcsFactory() android.Module {
+	return newCodegenModule(javaCodegen)
+}
+
+// cc_aconfig_srcs generates the C++ source and header that read the aconfig flags of an
+// aconfig_declarations module. The header is <package>.h, with the dots of the package replaced
+// by underscores.
+func CcAconfigSrcsFactory() android.Module {
+	return newCodegenModule(ccCodegen)
+}
+
+func newCodegenModule(language codegenLanguage) android.Module {
+	module := &codegenModule{language: language}
+	module.AddProperties(&module.properties)
+	android.InitAndroidModule(module)
+	return module
+}
+
+func (m *codegenModule) DepsMutator(ctx android.BottomUpMutatorContext) {
+	if declarations := proptools.String(m.properties.Aconfig_declarations); declarations != "" {
+		ctx.AddDependency(ctx.Module(), declarationsTag, declarations)
+	}
+}
+
+func (m *codegenModule) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	if proptools.String(m.properties.Aconfig_declarations) == "" {
+		ctx.PropertyErrorf("aconfig_declarations", "missing aconfig_declarations module")
+		return
+	}
+	mode := proptools.StringDefault(m.properties.Mode, "production")
+	if !android.InList(mode, codegenModes) {
+		ctx.PropertyErrorf("mode", "must be one of %s, got %q", strings.Join(codegenModes, ", "), mode)
+		return
+	}
+
+	var declarations *declarationsInfo
+	ctx.VisitDirectDepsWithTag(declarationsTag, func(dep android.Module) {
+		if !ctx.OtherModuleHasProvider(dep, declarationsProvider) {
+			ctx.PropertyErrorf("aconfig_declarations", "%s is not an aconfig_declarations module",
+				ctx.OtherModuleName(dep))
+			return
+		}
+		info := ctx.OtherModuleProvider(dep, declarationsProvider).(declarationsInfo)
+		declarations = &info
+	})
+	if declarations == nil {
+		return
+	}
+
+	// The code is generated into a temporary directory, and only copied to the outputs when it
+	// changes, so that flipping a flag whose value isn't compiled into the code, or the flag of
+	// another package, doesn't rebuild the modules that use the code.
+	genDir := android.PathForModuleOut(ctx, "aconfig", "gen")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().Text("rm -rf").Text(genDir.String())
+	rule.Command().Text("mkdir -p").Text(genDir.String())
+	switch m.language {
+	case javaCodegen:
+		rule.Command().
+			BuiltTool("aconfig").
+			Text("create-java-lib").
+			FlagWithArg("--mode ", mode).
+			FlagWithInput("--cache ", declarations.cache).
+			FlagWithArg("--out ", genDir.String())
+		m.outputFile = android.PathForModuleGen(ctx, ctx.ModuleName()+".srcjar")
+		rule.Restat()
+		rule.Command().
+			BuiltTool("soong_zip").
+			Flag("-write_if_changed").
+			Flag("-jar").
+			FlagWithOutput("-o ", m.outputFile).
+			FlagWithArg("-C ", genDir.String()).
+			FlagWithArg("-D ", genDir.String())
+	case ccCodegen:
+		base := strings.ReplaceAll(declarations.pkg, ".", "_")
+		genSource := android.PathForModuleOut(ctx, "aconfig", "gen", base+".cc")
+		genHeader := android.PathForModuleOut(ctx, "aconfig", "gen", "include", base+".h")
+		rule.Command().
+			BuiltTool("aconfig").
+			Text("create-cpp-lib").
+			FlagWithArg("--mode ", mode).
+			FlagWithInput("--cache ", declarations.cache).
+			FlagWithArg("--out ", genDir.String()).
+			ImplicitOutput(genSource).
+			ImplicitOutput(genHeader)
+		m.outputFile = android.PathForModuleGen(ctx, base+".cc")
+		m.headerDir = android.PathForModuleGen(ctx, "include")
+		m.header = android.PathForModuleGen(ctx, "include", base+".h")
+		updateIfChanged(rule, genSource, m.outputFile)
+		updateIfChanged(rule, genHeader, m.header)
+	}
+	rule.Build("aconfig_codegen", "aconfig codegen "+declarations.pkg)
+}
+
+func (m *codegenModule) OutputFiles(tag string) (android.Paths, error) {
+	if tag != "" {
+		return nil, fmt.Errorf("unsupported module reference tag %q", tag)
+	}
+	if m.outputFile == nil {
+		return nil, nil
+	}
+	return android.Paths{m.outputFile}, nil
+}
+
+// GeneratedSourceFiles, GeneratedHeaderDirs and GeneratedDeps implement
+// genrule.SourceFileGenerator for the generated_headers of C++ modules.
+
+func (m *codegenModule) GeneratedSourceFiles() android.Paths {
+	if m.language != ccCodegen || m.outputFile == nil {
+		return nil
+	}
+	return android.Paths{m.outputFile}
+}
+
+func (m *codegenModule) GeneratedHeaderDirs() android.Paths {
+	if m.headerDir == nil {
+		return nil
+	}
+	return android.Paths{m.headerDir}
+}
+
+func (m *codegenModule) GeneratedDeps() android.Paths {
+	if m.header == nil {
+		return nil
+	}
+	return android.Paths{m.header}
+}
+
+var _ android.OutputFileProducer = (*codegenModule)(nil)
@@ -0,0 +1,84 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aconfig
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+const codegenTestBp = `
+	aconfig_declarations {
+		name: "foo_flags",
+		package: "com.android.foo",
+		srcs: ["foo/foo.aconfig"],
+	}
+`
+
+func TestJavaAconfigSrcs(t *testing.T) {
+	result := prepareForTest.RunTestWithBp(t, codegenTestBp+`
+		java_aconfig_srcs {
+			name: "foo_flags_java",
+			aconfig_declarations: "foo_flags",
+		}
+	`)
+
+	module := result.ModuleForTests("foo_flags_java", "")
+	rule := module.Rule("aconfig_codegen").RelativeToTop()
+	android.AssertStringDoesContain(t, "codegen command", rule.RuleParams.Command,
+		"create-java-lib --mode production --cache out/soong/.intermediates/foo_flags/aconfig/cache.pb")
+	android.AssertStringDoesContain(t, "codegen command", rule.RuleParams.Command, "-write_if_changed")
+	android.AssertBoolEquals(t, "codegen restat", true, rule.RuleParams.Restat)
+	android.AssertPathRelativeToTopEquals(t, "srcjar",
+		"out/soong/.intermediates/foo_flags_java/gen/foo_flags_java.srcjar", rule.Output)
+}
+
+func TestCcAconfigSrcs(t *testing.T) {
+	result := prepareForTest.RunTestWithBp(t, codegenTestBp+`
+		cc_aconfig_srcs {
+			name: "foo_flags_cc",
+			aconfig_declarations: "foo_flags",
+			mode: "test",
+		}
+	`)
+
+	module := result.ModuleForTests("foo_flags_cc", "")
+	rule := module.Rule("aconfig_codegen").RelativeToTop()
+	android.AssertStringDoesContain(t, "codegen command", rule.RuleParams.Command,
+		"create-cpp-lib --mode test --cache out/soong/.intermediates/foo_flags/aconfig/cache.pb")
+	android.AssertBoolEquals(t, "codegen restat", true, rule.RuleParams.Restat)
+	android.AssertDeepEquals(t, "codegen outputs", []string{
+		"out/soong/.intermediates/foo_flags_cc/gen/com_android_foo.cc",
+		"out/soong/.intermediates/foo_flags_cc/gen/include/com_android_foo.h",
+	}, rule.AllOutputs())
+
+	codegen := module.Module().(*codegenModule)
+	android.AssertPathsRelativeToTopEquals(t, "generated header dirs", []string{
+		"out/soong/.intermediates/foo_flags_cc/gen/include",
+	}, codegen.GeneratedHeaderDirs())
+}
+
+func TestAconfigSrcsErrors(t *testing.T) {
+	prepareForTest.ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+		`module "foo_flags_java": mode: must be one of production, test, exported, got "debug"`,
+	)).RunTestWithBp(t, codegenTestBp+`
+		java_aconfig_srcs {
+			name: "foo_flags_java",
+			aconfig_declarations: "foo_flags",
+			mode: "debug",
+		}
+	`)
+}
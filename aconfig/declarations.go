@@ -0,0 +1,194 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aconfig
+
+import (
+	"android/soong/android"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+type dependencyTag struct {
+	blueprint.BaseDependencyTag
+	name string
+}
+
+var (
+	valueSetTag     = dependencyTag{name: "value_set"}
+	valuesTag       = dependencyTag{name: "values"}
+	declarationsTag = dependencyTag{name: "declarations"}
+)
+
+// declarationsInfo is provided by aconfig_declarations modules.
+type declarationsInfo struct {
+	pkg string
+
+	// The aconfig cache of the flags of the package, with their values in the current build.
+	cache android.Path
+}
+
+var declarationsProvider = blueprint.NewProvider(declarationsInfo{})
+
+// valuesInfo is provided by aconfig_values modules.
+type valuesInfo struct {
+	pkg    string
+	values android.Paths
+}
+
+var valuesProvider = blueprint.NewProvider(valuesInfo{})
+
+// valueSetInfo is provided by aconfig_value_set modules.
+type valueSetInfo struct {
+	// The files that set the values of the flags of each package.
+	values map[string]android.Paths
+}
+
+var valueSetProvider = blueprint.NewProvider(valueSetInfo{})
+
+type declarationsProperties struct {
+	// The package of the flags, e.g. "com.android.foo". The files of srcs declare flags of it.
+	Package *string
+
+	// The .aconfig files that declare the flags.
+	Srcs []string `android:"path"`
+}
+
+type declarationsModule struct {
+	android.ModuleBase
+
+	properties declarationsProperties
+}
+
+// aconfig_declarations declares the aconfig flags of a package. It builds an aconfig cache of the
+// flags with the values that the aconfig_value_set modules of the release configuration set for
+// the package, for java_aconfig_srcs and cc_aconfig_srcs to generate code from.
+func DeclarationsFactory() android.Module {
+	module := &declarationsModule{}
+	module.AddProperties(&module.properties)
+	android.InitAndroidModule(module)
+	return module
+}
+
+func (m *declarationsModule) DepsMutator(ctx android.BottomUpMutatorContext) {
+	ctx.AddDependency(ctx.Module(), valueSetTag, ctx.Config().AconfigValueSets()...)
+}
+
+func (m *declarationsModule) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	pkg := proptools.String(m.properties.Package)
+	if pkg == "" {
+		ctx.PropertyErrorf("package", "missing package of the flags")
+		return
+	}
+
+	// Only the values of the flags of the package are inputs, so that flipping a flag of
+	// another package doesn't rebuild the cache.
+	var values android.Paths
+	ctx.VisitDirectDepsWithTag(valueSetTag, func(dep android.Module) {
+		if ctx.OtherModuleHasProvider(dep, valueSetProvider) {
+			values = append(values, ctx.OtherModuleProvider(dep, valueSetProvider).(valueSetInfo).values[pkg]...)
+		}
+	})
+
+	cache := android.PathForModuleOut(ctx, "aconfig", "cache.pb")
+	tempCache := android.PathForModuleOut(ctx, "aconfig", "cache.pb.tmp")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		BuiltTool("aconfig").
+		Text("create-cache").
+		FlagWithArg("--package ", pkg).
+		FlagForEachInput("--declarations ", android.PathsForModuleSrc(ctx, m.properties.Srcs)).
+		FlagForEachInput("--values ", values).
+		FlagWithOutput("--cache ", tempCache)
+	updateIfChanged(rule, tempCache, cache)
+	rule.Build("aconfig_cache", "aconfig cache "+pkg)
+
+	ctx.SetProvider(declarationsProvider, declarationsInfo{
+		pkg:   pkg,
+		cache: cache,
+	})
+}
+
+type valuesProperties struct {
+	// The package of the flags whose values the files set.
+	Package *string
+
+	// The .values files that set the values of flags of the package.
+	Srcs []string `android:"path"`
+}
+
+type valuesModule struct {
+	android.ModuleBase
+
+	properties valuesProperties
+}
+
+// aconfig_values sets the values of aconfig flags of a package, for the aconfig_value_set modules
+// that list it.
+func ValuesFactory() android.Module {
+	module := &valuesModule{}
+	module.AddProperties(&module.properties)
+	android.InitAndroidModule(module)
+	return module
+}
+
+func (m *valuesModule) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	pkg := proptools.String(m.properties.Package)
+	if pkg == "" {
+		ctx.PropertyErrorf("package", "missing package of the flags")
+		return
+	}
+	ctx.SetProvider(valuesProvider, valuesInfo{
+		pkg:    pkg,
+		values: android.PathsForModuleSrc(ctx, m.properties.Srcs),
+	})
+}
+
+type valueSetProperties struct {
+	// The aconfig_values modules of the value set.
+	Values []string
+}
+
+type valueSetModule struct {
+	android.ModuleBase
+
+	properties valueSetProperties
+}
+
+// aconfig_value_set groups the aconfig_values modules of a release configuration. The release
+// configuration selects value sets with the AconfigValueSets product variable.
+func ValueSetFactory() android.Module {
+	module := &valueSetModule{}
+	module.AddProperties(&module.properties)
+	android.InitAndroidModule(module)
+	return module
+}
+
+func (m *valueSetModule) DepsMutator(ctx android.BottomUpMutatorContext) {
+	ctx.AddDependency(ctx.Module(), valuesTag, m.properties.Values...)
+}
+
+func (m *valueSetModule) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	values := make(map[string]android.Paths)
+	ctx.VisitDirectDepsWithTag(valuesTag, func(dep android.Module) {
+		if !ctx.OtherModuleHasProvider(dep, valuesProvider) {
+			ctx.PropertyErrorf("values", "%s is not an aconfig_values module", ctx.OtherModuleName(dep))
+			return
+		}
+		info := ctx.OtherModuleProvider(dep, valuesProvider).(valuesInfo)
+		values[info.pkg] = append(values[info.pkg], info.values...)
+	})
+	ctx.SetProvider(valueSetProvider, valueSetInfo{values: values})
+}
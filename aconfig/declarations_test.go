@@ -0,0 +1,96 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aconfig
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+var prepareForTest = android.GroupFixturePreparers(
+	android.PrepareForTestWithAndroidBuildComponents,
+	PrepareForTestWithAconfigBuildComponents,
+	FixtureSetAconfigValueSets("release_values"),
+	android.FixtureMergeMockFs(android.MockFS{
+		"foo/foo.aconfig":    nil,
+		"bar/bar.aconfig":    nil,
+		"values/foo.values":  nil,
+		"values/bar.values":  nil,
+		"values/bar2.values": nil,
+	}),
+	android.FixtureAddTextFile("values/Android.bp", `
+		aconfig_value_set {
+			name: "release_values",
+			values: ["foo_values", "bar_values"],
+		}
+		aconfig_values {
+			name: "foo_values",
+			package: "com.android.foo",
+			srcs: ["foo.values"],
+		}
+		aconfig_values {
+			name: "bar_values",
+			package: "com.android.bar",
+			srcs: ["bar.values", "bar2.values"],
+		}
+	`),
+)
+
+func TestAconfigDeclarations(t *testing.T) {
+	result := prepareForTest.RunTestWithBp(t, `
+		aconfig_declarations {
+			name: "foo_flags",
+			package: "com.android.foo",
+			srcs: ["foo/foo.aconfig"],
+		}
+		aconfig_declarations {
+			name: "bar_flags",
+			package: "com.android.bar",
+			srcs: ["bar/bar.aconfig"],
+		}
+	`)
+
+	// Only the values of its own package are inputs of the cache of a package.
+	foo := result.ModuleForTests("foo_flags", "").Rule("aconfig_cache").RelativeToTop()
+	android.AssertStringDoesContain(t, "foo cache command", foo.RuleParams.Command,
+		"create-cache --package com.android.foo --declarations foo/foo.aconfig --values values/foo.values "+
+			"--cache out/soong/.intermediates/foo_flags/aconfig/cache.pb.tmp")
+	android.AssertBoolEquals(t, "foo cache restat", true, foo.RuleParams.Restat)
+
+	bar := result.ModuleForTests("bar_flags", "").Rule("aconfig_cache").RelativeToTop()
+	android.AssertStringDoesContain(t, "bar cache command", bar.RuleParams.Command,
+		"--values values/bar.values --values values/bar2.values")
+	android.AssertStringDoesNotContain(t, "bar cache command", bar.RuleParams.Command, "foo.values")
+
+	all := result.SingletonForTests("all_aconfig_declarations").Rule("all_aconfig_declarations")
+	android.AssertPathsRelativeToTopEquals(t, "all_aconfig_declarations inputs", []string{
+		"out/soong/.intermediates/bar_flags/aconfig/cache.pb",
+		"out/soong/.intermediates/foo_flags/aconfig/cache.pb",
+	}, all.Implicits)
+	android.AssertPathRelativeToTopEquals(t, "all_aconfig_declarations output",
+		"out/soong/all_aconfig_declarations.pb", all.Output)
+}
+
+func TestAconfigDeclarationsErrors(t *testing.T) {
+	prepareForTest.ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+		`module "foo_flags": package: missing package of the flags`,
+	)).RunTestWithBp(t, `
+		aconfig_declarations {
+			name: "foo_flags",
+			srcs: ["foo/foo.aconfig"],
+		}
+	`)
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"strings"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// This file runs build actions through the action_cache wrapper when Config.ActionCacheDir is set.
+// The wrapper caches the outputs of an action in that directory by the digest of its command line
+// and of the contents of its inputs, with the paths in the output directory normalized, and
+// restores them when the same action runs again, in this or any other output directory. Only the
+// actions whose outputs are fully determined by their command lines and inputs opt into it, with
+// RuleBuilder.ActionCache or by calling ActionCacheCommand directly.
+
+// ActionCacheParams describes an action that runs through the action cache.
+type ActionCacheParams struct {
+	// The file that the list of Inputs is written to for the wrapper.
+	InputsFile WritablePath
+	// The inputs of the action, including its tools.
+	Inputs Paths
+	// Response files that the rule of the action writes with more inputs, e.g. "$out.rsp".
+	RspFiles []string
+	// Inputs whose contents are hashed with the paths in the output directory normalized, e.g.
+	// sbox manifests.
+	NormalizedInputs Paths
+	// The outputs of the action, not including its depfile.
+	Outputs WritablePaths
+	// The depfile of the action, if it has one. It's cached with the paths in the output
+	// directory normalized.
+	DepFile WritablePath
+}
+
+// ActionCacheCommand returns the start of a command line that runs a command through the action
+// cache, ending with "--", and the dependencies that the action needs for it. It writes the list
+// of inputs of the action to params.InputsFile. It returns "" if the action cache isn't used.
+func ActionCacheCommand(ctx BuilderContext, params ActionCacheParams) (string, Paths) {
+	cacheDir := ctx.Config().ActionCacheDir()
+	if cacheDir == "" {
+		return "", nil
+	}
+	writeRspFileRule(ctx, params.InputsFile, params.Inputs)
+
+	tool := ctx.Config().HostToolPath(ctx, "action_cache")
+	args := []string{
+		tool.String(),
+		"--cache_dir", cacheDir,
+		"--out_dir", ctx.Config().OutDir(),
+		"--inputs", params.InputsFile.String(),
+	}
+	for _, rspFile := range params.RspFiles {
+		args = append(args, "--inputs", rspFile)
+	}
+	for _, input := range params.NormalizedInputs {
+		args = append(args, "--normalized_input", input.String())
+	}
+	for _, output := range params.Outputs {
+		args = append(args, "--output", output.String())
+	}
+	if params.DepFile != nil {
+		args = append(args, "--depfile", params.DepFile.String())
+	}
+	args = append(args, "--")
+
+	deps := Paths{tool, params.InputsFile}
+	deps = append(deps, params.NormalizedInputs...)
+	return strings.Join(proptools.ShellEscapeList(args), " "), deps
+}
+
+// actionCacheInputsFile returns the file that lists the inputs of the RuleBuilder action with the
+// given first output for the action cache.
+func actionCacheInputsFile(ctx BuilderContext, output WritablePath) WritablePath {
+	return PathForOutput(ctx, "action_cache_inputs", hashSrcFiles(Paths{output})+".rsp")
+}
+
+// actionCacheInputHash is like hashSrcFiles, but the output directory is replaced in the paths,
+// so that the hash, which is part of the command line or the sbox manifest of the action, is the
+// same in every output directory.
+func actionCacheInputHash(ctx BuilderContext, srcFiles Paths) string {
+	prefix := strings.TrimSuffix(ctx.Config().OutDir(), "/") + "/"
+	paths := make([]string, len(srcFiles))
+	for i, path := range srcFiles {
+		if rel := strings.TrimPrefix(path.String(), prefix); rel != path.String() {
+			paths[i] = "$OUT_DIR/" + rel
+		} else {
+			paths[i] = path.String()
+		}
+	}
+	return hashStrings(paths)
+}
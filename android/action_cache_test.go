@@ -0,0 +1,124 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"strings"
+	"testing"
+)
+
+type actionCacheTestModule struct {
+	ModuleBase
+	properties struct {
+		Sbox bool
+	}
+}
+
+func actionCacheTestModuleFactory() Module {
+	module := &actionCacheTestModule{}
+	module.AddProperties(&module.properties)
+	InitAndroidModule(module)
+	return module
+}
+
+func (m *actionCacheTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	out := PathForModuleOut(ctx, "gen", ctx.ModuleName())
+	rule := NewRuleBuilder(pctx, ctx)
+	if m.properties.Sbox {
+		rule.Sbox(PathForModuleOut(ctx, "gen"), PathForModuleOut(ctx, "sbox.textproto"))
+	}
+	rule.ActionCache()
+	rule.Command().
+		Tool(PathForSource(ctx, "cp")).
+		Input(PathForSource(ctx, "in")).
+		Output(out).
+		ImplicitDepFile(out.ReplaceExtension(ctx, "d"))
+	rule.Build("rule", "desc")
+}
+
+var prepareForActionCacheTest = GroupFixturePreparers(
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("action_cache_test", actionCacheTestModuleFactory)
+	}),
+	FixtureWithRootAndroidBp(`
+		action_cache_test {
+			name: "foo",
+		}
+		action_cache_test {
+			name: "foo_sbox",
+			sbox: true,
+		}
+	`),
+	MockFS{"in": nil, "cp": nil}.AddToFixture(),
+)
+
+// actionCacheInputs returns the contents of the file that lists the inputs of the rule of the
+// module for the action cache.
+func actionCacheInputs(t *testing.T, module TestingModule) string {
+	t.Helper()
+	for _, output := range module.AllOutputs() {
+		if strings.Contains(output, "/action_cache_inputs/") {
+			return ContentFromFileRuleForTests(t, module.Output(output))
+		}
+	}
+	t.Fatalf("no action cache inputs file")
+	return ""
+}
+
+func TestRuleBuilderActionCache(t *testing.T) {
+	result := GroupFixturePreparers(
+		prepareForActionCacheTest,
+		FixtureMergeEnv(map[string]string{"SOONG_ACTION_CACHE_DIR": "/tmp/action_cache"}),
+	).RunTest(t)
+
+	t.Run("module", func(t *testing.T) {
+		module := result.ModuleForTests("foo", "")
+		command := module.Rule("rule").RuleParams.Command
+		AssertStringDoesContain(t, "wrapper", command, "bin/action_cache --cache_dir /tmp/action_cache ")
+		AssertStringDoesContain(t, "output", command, " --output out/soong/.intermediates/foo/gen/foo ")
+		AssertStringDoesContain(t, "depfile", command, " --depfile out/soong/.intermediates/foo/gen/foo.d ")
+		AssertStringDoesContain(t, "command", command, " -- bash -c 'cp in out/soong/.intermediates/foo/gen/foo")
+		AssertStringEquals(t, "inputs", "in cp\n", actionCacheInputs(t, module))
+	})
+	t.Run("sbox", func(t *testing.T) {
+		module := result.ModuleForTests("foo_sbox", "")
+		command := module.Output("gen/foo_sbox").RuleParams.Command
+		manifest := "out/soong/.intermediates/foo_sbox/sbox.textproto"
+		AssertStringDoesContain(t, "manifest", command, " --normalized_input "+manifest+" ")
+		AssertStringDoesContain(t, "sbox", command, " -- bash -c '")
+		// The manifest is hashed on its own, with its paths in the output directory normalized.
+		inputs := actionCacheInputs(t, module)
+		AssertStringDoesNotContain(t, "inputs", inputs, "sbox.textproto")
+		AssertStringDoesContain(t, "inputs", inputs, "bin/sbox")
+	})
+}
+
+func TestRuleBuilderActionCacheDisabled(t *testing.T) {
+	result := prepareForActionCacheTest.RunTest(t)
+
+	command := result.ModuleForTests("foo", "").Rule("rule").RuleParams.Command
+	AssertStringDoesNotContain(t, "command", command, "action_cache")
+}
+
+func TestActionCacheInputHash(t *testing.T) {
+	hash := func(buildDir string) string {
+		ctx := BuilderContextForTesting(TestConfig(buildDir, nil, "", nil))
+		return actionCacheInputHash(ctx, Paths{PathForTesting("in"), PathForOutput(ctx, "gen", "foo")})
+	}
+	AssertStringEquals(t, "hash in another output directory", hash("/tmp/out"), hash("/tmp/out2"))
+	if hash("/tmp/out") == hashSrcFiles(nil) {
+		t.Errorf("expected the hash to depend on the inputs")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/blueprint"
+)
+
+// The peak memory of actions that need a lot of it, like links and metalava, is estimated so that
+// the actions that need more than SOONG_HIGHMEM_ACTION_MB, 4096 by default, run in the highmem
+// pool, which limits how many of them run in parallel. The estimate is the peak memory recorded for
+// the output of the action in the SOONG_ACTION_MEMORY_PROFILE file of a previous build, scaled by
+// the change of its number of inputs, or else an estimate from its number of inputs.
+//
+// The actions assigned to the highmem pool are listed in $OUT/soong/action_memory.json.
+
+const (
+	actionMemoryProfileEnvVar    = "SOONG_ACTION_MEMORY_PROFILE"
+	highmemActionThresholdEnvVar = "SOONG_HIGHMEM_ACTION_MB"
+
+	defaultHighmemActionThresholdMB = 4096
+)
+
+func init() {
+	RegisterActionMemoryBuildComponents(InitRegistrationContext)
+}
+
+func RegisterActionMemoryBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("action_memory", actionMemorySingletonFactory)
+}
+
+var PrepareForTestWithActionMemory = FixtureRegisterWithContext(RegisterActionMemoryBuildComponents)
+
+// HighmemPool returns the pool of the actions that need significant RAM, which limits how many of
+// them run in parallel.
+func HighmemPool() blueprint.Pool {
+	return highmemPool
+}
+
+// ActionMemoryRecord is an entry of the SOONG_ACTION_MEMORY_PROFILE file, the peak memory of an
+// action in a previous build.
+type ActionMemoryRecord struct {
+	// The output of the action, relative to the Soong output directory, e.g.
+	// ".intermediates/external/foo/libfoo/android_arm64_armv8-a_shared/unstripped/libfoo.so".
+	Output string `json:"output"`
+
+	// The peak resident set size of the action in MB.
+	PeakMB int `json:"peak_mb"`
+
+	// The number of inputs of the action, if known.
+	Inputs int `json:"inputs,omitempty"`
+}
+
+// ActionMemoryModel estimates the peak memory of a kind of action from its number of inputs, for
+// the actions that have no record in the SOONG_ACTION_MEMORY_PROFILE file.
+type ActionMemoryModel struct {
+	Name       string
+	BaseMB     int
+	PerInputKB int
+}
+
+func (m ActionMemoryModel) estimate(inputs int) int {
+	return m.BaseMB + inputs*m.PerInputKB/1024
+}
+
+func (c *config) loadActionMemoryProfile() error {
+	if value := c.Getenv(highmemActionThresholdEnvVar); value != "" {
+		if threshold, err := strconv.Atoi(value); err != nil || threshold <= 0 {
+			return fmt.Errorf("%s must be a positive number of MB, got %q", highmemActionThresholdEnvVar, value)
+		}
+	}
+
+	file := c.Getenv(actionMemoryProfileEnvVar)
+	if file == "" {
+		return nil
+	}
+	data, err := os.ReadFile(absolutePath(file))
+	if err != nil {
+		return fmt.Errorf("%s: %s", actionMemoryProfileEnvVar, err)
+	}
+	return c.setActionMemoryProfile(file, data)
+}
+
+func (c *config) setActionMemoryProfile(file string, data []byte) error {
+	var records []ActionMemoryRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("%s: %s", file, err)
+	}
+	c.actionMemoryProfile = make(map[string]ActionMemoryRecord, len(records))
+	for i, record := range records {
+		if record.Output == "" || record.PeakMB <= 0 || record.Inputs < 0 {
+			return fmt.Errorf("%s: entry %d must have an output and a positive peak_mb", file, i)
+		}
+		c.actionMemoryProfile[record.Output] = record
+	}
+	return nil
+}
+
+// HighmemActionThresholdMB returns the estimated peak memory from which actions run in the
+// highmem pool.
+func (c *config) HighmemActionThresholdMB() int {
+	if threshold, err := strconv.Atoi(c.Getenv(highmemActionThresholdEnvVar)); err == nil {
+		return threshold
+	}
+	return defaultHighmemActionThresholdMB
+}
+
+var actionMemoryEstimatesKey = NewOnceKey("ActionMemoryEstimates")
+
+func actionMemoryEstimates(config Config) *sync.Map {
+	return config.Once(actionMemoryEstimatesKey, func() interface{} {
+		return &sync.Map{}
+	}).(*sync.Map)
+}
+
+// actionMemoryEstimate is an entry of action_memory.json, whose output is relative to the Soong
+// output directory like in the SOONG_ACTION_MEMORY_PROFILE file.
+type actionMemoryEstimate struct {
+	Output     string `json:"output"`
+	Action     string `json:"action"`
+	Inputs     int    `json:"inputs"`
+	EstimateMB int    `json:"estimate_mb"`
+	Historical bool   `json:"historical"`
+}
+
+// actionMemoryKey returns the path of the output of an action relative to the Soong output
+// directory, which identifies the action in the SOONG_ACTION_MEMORY_PROFILE file.
+func actionMemoryKey(config Config, output Path) string {
+	return strings.TrimPrefix(output.String(), config.soongOutDir+"/")
+}
+
+// EstimatePeakMemoryMB returns the estimated peak memory in MB of an action with the given output
+// and number of inputs, and whether the estimate is based on the SOONG_ACTION_MEMORY_PROFILE file.
+func EstimatePeakMemoryMB(ctx PathContext, model ActionMemoryModel, output Path, inputs int) (int, bool) {
+	config := ctx.Config()
+	if record, ok := config.actionMemoryProfile[actionMemoryKey(config, output)]; ok {
+		if record.Inputs > 0 && inputs > record.Inputs {
+			return record.PeakMB * inputs / record.Inputs, true
+		}
+		return record.PeakMB, true
+	}
+	return model.estimate(inputs), false
+}
+
+// UseHighmemPool returns true if the action with the given output and number of inputs is estimated
+// to need at least HighmemActionThresholdMB of memory, in which case it should run in the highmem
+// pool.
+func UseHighmemPool(ctx PathContext, model ActionMemoryModel, output Path, inputs int) bool {
+	estimate, historical := EstimatePeakMemoryMB(ctx, model, output, inputs)
+	if estimate < ctx.Config().HighmemActionThresholdMB() {
+		return false
+	}
+	key := actionMemoryKey(ctx.Config(), output)
+	actionMemoryEstimates(ctx.Config()).Store(key, actionMemoryEstimate{
+		Output:     key,
+		Action:     model.Name,
+		Inputs:     inputs,
+		EstimateMB: estimate,
+		Historical: historical,
+	})
+	return true
+}
+
+func actionMemorySingletonFactory() Singleton {
+	return &actionMemorySingleton{}
+}
+
+// actionMemorySingleton writes the actions assigned to the highmem pool by UseHighmemPool to
+// action_memory.json.
+type actionMemorySingleton struct{}
+
+func (s *actionMemorySingleton) GenerateBuildActions(ctx SingletonContext) {
+	if file := ctx.Config().Getenv(actionMemoryProfileEnvVar); file != "" {
+		ctx.AddNinjaFileDeps(file)
+	}
+
+	estimates := []actionMemoryEstimate{}
+	actionMemoryEstimates(ctx.Config()).Range(func(_, value interface{}) bool {
+		estimates = append(estimates, value.(actionMemoryEstimate))
+		return true
+	})
+	sort.Slice(estimates, func(i, j int) bool {
+		return estimates[i].Output < estimates[j].Output
+	})
+
+	data, err := json.MarshalIndent(estimates, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	WriteFileRule(ctx, PathForOutput(ctx, "action_memory.json"), string(data))
+}
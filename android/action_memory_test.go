@@ -0,0 +1,161 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+)
+
+func TestEstimatePeakMemoryMB(t *testing.T) {
+	config := TestConfig(t.TempDir(), map[string]string{
+		"SOONG_HIGHMEM_ACTION_MB": "2048",
+	}, "", nil)
+	err := config.setActionMemoryProfile("profile.json", []byte(`[
+		{"output": "libbig.so", "peak_mb": 3000, "inputs": 100},
+		{"output": "libsmall.so", "peak_mb": 200}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := PathContextForTesting(config)
+	model := ActionMemoryModel{Name: "link", BaseMB: 256, PerInputKB: 1024}
+
+	testCases := []struct {
+		name       string
+		output     string
+		inputs     int
+		estimate   int
+		historical bool
+		highmem    bool
+	}{
+		{
+			name:     "model",
+			output:   "libfoo.so",
+			inputs:   1000,
+			estimate: 1256,
+		},
+		{
+			name:     "large model",
+			output:   "libfoo.so",
+			inputs:   2000,
+			estimate: 2256,
+			highmem:  true,
+		},
+		{
+			name:       "historical",
+			output:     "libbig.so",
+			inputs:     50,
+			estimate:   3000,
+			historical: true,
+			highmem:    true,
+		},
+		{
+			name:       "historical with more inputs",
+			output:     "libbig.so",
+			inputs:     150,
+			estimate:   4500,
+			historical: true,
+			highmem:    true,
+		},
+		{
+			name:       "historical overrides the model",
+			output:     "libsmall.so",
+			inputs:     5000,
+			estimate:   200,
+			historical: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := PathForOutput(ctx, tc.output)
+			estimate, historical := EstimatePeakMemoryMB(ctx, model, output, tc.inputs)
+			AssertIntEquals(t, "estimate", tc.estimate, estimate)
+			AssertBoolEquals(t, "historical", tc.historical, historical)
+			AssertBoolEquals(t, "highmem", tc.highmem, UseHighmemPool(ctx, model, output, tc.inputs))
+		})
+	}
+}
+
+func TestActionMemoryProfileErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "invalid json",
+			data: `{`,
+			err:  "profile.json: unexpected end of JSON input",
+		},
+		{
+			name: "missing output",
+			data: `[{"peak_mb": 100}]`,
+			err:  "profile.json: entry 0 must have an output and a positive peak_mb",
+		},
+		{
+			name: "missing peak",
+			data: `[{"output": "libfoo.so", "peak_mb": 100}, {"output": "libbar.so"}]`,
+			err:  "profile.json: entry 1 must have an output and a positive peak_mb",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := TestConfig(t.TempDir(), nil, "", nil)
+			err := config.setActionMemoryProfile("profile.json", []byte(tc.data))
+			AssertErrorMessageEquals(t, "error", tc.err, err)
+		})
+	}
+}
+
+type highmemTestModule struct {
+	ModuleBase
+}
+
+func (m *highmemTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	model := ActionMemoryModel{Name: "link", BaseMB: 256, PerInputKB: 1024}
+	UseHighmemPool(ctx, model, PathForModuleOut(ctx, "small"), 10)
+	UseHighmemPool(ctx, model, PathForModuleOut(ctx, "large"), 1000)
+}
+
+func highmemTestModuleFactory() Module {
+	m := &highmemTestModule{}
+	InitAndroidModule(m)
+	return m
+}
+
+func TestActionMemoryReport(t *testing.T) {
+	result := GroupFixturePreparers(
+		PrepareForTestWithActionMemory,
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("test_highmem", highmemTestModuleFactory)
+		}),
+		FixtureMergeEnv(map[string]string{"SOONG_HIGHMEM_ACTION_MB": "1000"}),
+	).RunTestWithBp(t, `
+		test_highmem {
+			name: "foo",
+		}
+	`)
+
+	report := result.SingletonForTests("action_memory").Output("action_memory.json")
+	AssertStringEquals(t, "action_memory.json", `[
+  {
+    "output": ".intermediates/foo/large",
+    "action": "link",
+    "inputs": 1000,
+    "estimate_mb": 1256,
+    "historical": false
+  }
+]`, ContentFromFileRuleForTests(t, report))
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// When building with RBE, Soong records the cacheability of the actions of every module, and
+// writes it to $OUT/soong/soong_action_metadata.json along with the output directory of every
+// module. soong_ui matches the outputs of the actions in the reproxy logs against the output
+// directories to attribute the remote cache hits and misses of the build to module types.
+
+var actionMetadataOnceKey = NewOnceKey("action metadata")
+
+func init() {
+	RegisterActionMetadataBuildComponents(InitRegistrationContext)
+}
+
+func RegisterActionMetadataBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("action_metadata", actionMetadataSingletonFactory)
+}
+
+var PrepareForTestWithActionMetadata = FixtureRegisterWithContext(RegisterActionMetadataBuildComponents)
+
+// ActionMetadata is the cacheability of the actions of a rule.
+type ActionMetadata struct {
+	// The number of actions.
+	Actions int `json:"actions"`
+
+	// The number of actions with an input that changes in every build, like the build number, so
+	// that the fingerprint of their inputs is never stable.
+	VolatileInputs int `json:"volatile_inputs"`
+
+	// The number of actions whose outputs differ between runs with the same inputs, see
+	// BuildParams.NondeterministicOutputs.
+	NondeterministicOutputs int `json:"nondeterministic_outputs"`
+}
+
+func (a *ActionMetadata) add(other ActionMetadata) {
+	a.Actions += other.Actions
+	a.VolatileInputs += other.VolatileInputs
+	a.NondeterministicOutputs += other.NondeterministicOutputs
+}
+
+// ModuleTypeActionMetadata is the cacheability of the actions of the modules of a module type.
+type ModuleTypeActionMetadata struct {
+	// The number of module variants of the module type.
+	Variants int `json:"variants"`
+
+	// The cacheability of the actions by rule.
+	Rules map[string]*ActionMetadata `json:"rules"`
+}
+
+// SoongActionMetadata is the content of soong_action_metadata.json.
+type SoongActionMetadata struct {
+	// The cacheability of the actions by module type.
+	ModuleTypes map[string]*ModuleTypeActionMetadata `json:"module_types"`
+
+	// The module type of every module variant by its output directory.
+	ModuleOutDirs map[string]string `json:"module_out_dirs"`
+}
+
+// recordActionMetadata records the cacheability of an action of the module.
+func (m *ModuleBase) recordActionMetadata(ctx ModuleContext, params BuildParams) {
+	if params.Rule == nil {
+		return
+	}
+
+	metadata := ActionMetadata{Actions: 1}
+	if params.NondeterministicOutputs {
+		metadata.NondeterministicOutputs = 1
+	}
+	buildNumberFile := ctx.Config().BuildNumberFile(ctx)
+	inputs := append(Paths{params.Input, params.Implicit}, params.Inputs...)
+	inputs = append(inputs, params.Implicits...)
+	// An action that reads an order-only input sees it change without its inputs changing.
+	inputs = append(inputs, params.OrderOnly...)
+	for _, input := range inputs {
+		if input != nil && input.String() == buildNumberFile.String() {
+			metadata.VolatileInputs = 1
+			break
+		}
+	}
+
+	if m.actionMetadata == nil {
+		m.actionMetadata = make(map[string]*ActionMetadata)
+	}
+	rule := actionMetadataRuleName(params.Rule.String())
+	if m.actionMetadata[rule] == nil {
+		m.actionMetadata[rule] = &ActionMetadata{}
+	}
+	m.actionMetadata[rule].add(metadata)
+}
+
+// actionMetadataRuleName returns the name of a rule without the prefix of the rules that are local
+// to a module, like the rules of RuleBuilder.
+func actionMetadataRuleName(name string) string {
+	return strings.TrimPrefix(name, "<local rule>:")
+}
+
+func actionMetadataSingletonFactory() Singleton {
+	return &actionMetadataSingleton{}
+}
+
+type actionMetadataSingleton struct{}
+
+func (actionMetadataSingleton) GenerateBuildActions(ctx SingletonContext) {
+	if !ctx.Config().UseRBE() {
+		return
+	}
+
+	metadata := &SoongActionMetadata{
+		ModuleTypes:   make(map[string]*ModuleTypeActionMetadata),
+		ModuleOutDirs: make(map[string]string),
+	}
+	ctx.VisitAllModules(func(module Module) {
+		moduleType := ctx.ModuleType(module)
+		typeMetadata := metadata.ModuleTypes[moduleType]
+		if typeMetadata == nil {
+			typeMetadata = &ModuleTypeActionMetadata{Rules: make(map[string]*ActionMetadata)}
+			metadata.ModuleTypes[moduleType] = typeMetadata
+		}
+		typeMetadata.Variants++
+
+		actions := module.base().actionMetadata
+		if len(actions) == 0 {
+			return
+		}
+		for rule, ruleMetadata := range actions {
+			if typeMetadata.Rules[rule] == nil {
+				typeMetadata.Rules[rule] = &ActionMetadata{}
+			}
+			typeMetadata.Rules[rule].add(*ruleMetadata)
+		}
+
+		outDir := PathForOutput(ctx, ".intermediates", ctx.ModuleDir(module), ctx.ModuleName(module),
+			ctx.ModuleSubDir(module))
+		metadata.ModuleOutDirs[outDir.String()] = moduleType
+	})
+
+	ctx.Config().Once(actionMetadataOnceKey, func() interface{} {
+		return metadata
+	})
+}
+
+// WriteActionMetadata writes the cacheability of the actions of the build when building with RBE,
+// and removes the file of a previous build otherwise. It is written directly instead of by a
+// build action as it is only read by soong_ui.
+func WriteActionMetadata(config Config) error {
+	file := absolutePath(filepath.Join(config.SoongOutDir(), "soong_action_metadata.json"))
+	metadata, ok := config.Peek(actionMetadataOnceKey)
+	if !ok {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0666)
+}
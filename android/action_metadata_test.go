@@ -0,0 +1,96 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+)
+
+type actionMetadataTestModule struct {
+	ModuleBase
+}
+
+func (m *actionMetadataTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	ctx.Build(pctx, BuildParams{
+		Rule:   Touch,
+		Output: PathForModuleOut(ctx, "stable"),
+	})
+	ctx.Build(pctx, BuildParams{
+		Rule:     Touch,
+		Output:   PathForModuleOut(ctx, "volatile"),
+		Implicit: ctx.Config().BuildNumberFile(ctx),
+	})
+
+	rule := NewRuleBuilder(pctx, ctx).NondeterministicOutputs()
+	rule.Command().Text("date >").Output(PathForModuleOut(ctx, "date"))
+	rule.Build("date", "date")
+}
+
+func actionMetadataTestModuleFactory() Module {
+	m := &actionMetadataTestModule{}
+	InitAndroidModule(m)
+	return m
+}
+
+var prepareForActionMetadataTest = GroupFixturePreparers(
+	PrepareForTestWithActionMetadata,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("action_metadata_test", actionMetadataTestModuleFactory)
+	}),
+	FixtureWithRootAndroidBp(`
+		action_metadata_test {
+			name: "foo",
+		}
+	`),
+)
+
+func TestActionMetadata(t *testing.T) {
+	result := GroupFixturePreparers(
+		prepareForActionMetadataTest,
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.UseRBE = proptools.BoolPtr(true)
+		}),
+	).RunTest(t)
+
+	value, ok := result.Config.Peek(actionMetadataOnceKey)
+	if !ok {
+		t.Fatalf("expected action metadata when building with RBE")
+	}
+	metadata := value.(*SoongActionMetadata)
+
+	typeMetadata := metadata.ModuleTypes["action_metadata_test"]
+	if typeMetadata == nil {
+		t.Fatalf("expected action metadata for action_metadata_test, got %v", metadata.ModuleTypes)
+	}
+	AssertIntEquals(t, "variants", 1, typeMetadata.Variants)
+
+	touch := typeMetadata.Rules[Touch.String()]
+	AssertDeepEquals(t, "touch", &ActionMetadata{Actions: 2, VolatileInputs: 1}, touch)
+	date := typeMetadata.Rules[actionMetadataRuleName(result.ModuleForTests("foo", "").Rule("date").Rule.String())]
+	AssertDeepEquals(t, "date", &ActionMetadata{Actions: 1, NondeterministicOutputs: 1}, date)
+
+	AssertDeepEquals(t, "module out dirs",
+		map[string]string{"out/soong/.intermediates/foo": "action_metadata_test"}, metadata.ModuleOutDirs)
+}
+
+func TestActionMetadataWithoutRBE(t *testing.T) {
+	result := prepareForActionMetadataTest.RunTest(t)
+
+	if _, ok := result.Config.Peek(actionMetadataOnceKey); ok {
+		t.Errorf("expected no action metadata when not building with RBE")
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/google/blueprint/metrics"
+	"google.golang.org/protobuf/proto"
+
+	soong_metrics_proto "android/soong/ui/metrics/metrics_proto"
+)
+
+// When soong_build receives SIGINT or SIGTERM, for example because a CI job is preempted, it writes
+// the metrics collected so far and a checkpoint of the completed analysis state to
+// $OUT/soong/.analysis_checkpoint.json before exiting. The next soong_build run resumes from the
+// checkpoint if it was written for the same soong_build binary, product configuration and
+// environment.
+//
+// The signal is handled at the next safe point of the analysis, see CheckAnalysisInterrupt, so
+// that the checkpoint and the metrics aren't read while the analysis updates them. Every mutator and
+// GenerateBuildActions check for the signal before they visit a module, and the main goroutine
+// checks for it between the steps of soong_build. The other steps, e.g. parsing the Android.bp files
+// and writing the ninja file, have no safe points, so if no safe point is reached within
+// analysisInterruptTimeout soong_build exits without a checkpoint. A second signal exits right away,
+// without a checkpoint.
+//
+// Most of the analysis happens in Blueprint and can't be resumed. The resumable state is what can
+// be validated against the inputs of the next run: the results of the globs of the analysis, which
+// are reused if none of the directories they read changed, and the results of the Bazel cquery of
+// mixed builds, which are reused if the cquery inputs didn't change.
+
+const analysisCheckpointFileName = ".analysis_checkpoint.json"
+
+// AnalysisCheckpoint is the content of .analysis_checkpoint.json.
+type AnalysisCheckpoint struct {
+	// A hash of the soong_build binary, the product configuration and the environment. The
+	// checkpoint is only valid for a run with the same key.
+	Key string `json:"key"`
+
+	// The signal that interrupted the run.
+	Signal string `json:"signal"`
+
+	// The phases of the run that completed before it was interrupted, in the order they completed.
+	CompletedPhases []string `json:"completed_phases,omitempty"`
+
+	// The output of the Bazel cquery of mixed builds, and a hash of its inputs.
+	CqueryInputsHash string `json:"cquery_inputs_hash,omitempty"`
+	CqueryOutput     string `json:"cquery_output,omitempty"`
+
+	// The globs of the analysis.
+	Globs []CheckpointGlob `json:"globs,omitempty"`
+}
+
+var analysisCheckpointKey = NewOnceKey("analysisCheckpoint")
+
+// analysisCheckpointState holds the checkpoint of the current run, and the checkpoint of the
+// interrupted run it resumes, if any.
+type analysisCheckpointState struct {
+	sync.Mutex
+	current AnalysisCheckpoint
+	resumed *AnalysisCheckpoint
+
+	// The globs of the current run, and the globs of the resumed checkpoint by key.
+	globs        []recordedGlob
+	resumedGlobs map[string]*CheckpointGlob
+}
+
+func analysisCheckpoints(config Config) *analysisCheckpointState {
+	return config.Once(analysisCheckpointKey, func() interface{} {
+		return &analysisCheckpointState{}
+	}).(*analysisCheckpointState)
+}
+
+func analysisCheckpointFile(config Config) string {
+	return absolutePath(filepath.Join(config.SoongOutDir(), analysisCheckpointFileName))
+}
+
+// analysisCheckpointKeyFor returns the key of the checkpoints that are valid for the current run.
+func analysisCheckpointKeyFor(config Config) (string, error) {
+	h := sha256.New()
+	if executable, err := os.Executable(); err == nil {
+		if info, err := os.Stat(executable); err == nil {
+			fmt.Fprintf(h, "%s %d %d\n", executable, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	productVariables, err := json.Marshal(config.productVariables)
+	if err != nil {
+		return "", err
+	}
+	h.Write(productVariables)
+	for _, k := range SortedKeys(config.env) {
+		fmt.Fprintf(h, "\n%s=%s", k, config.env[k])
+	}
+	fmt.Fprintf(h, "\n%d", config.BuildMode)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ResumeAnalysis reads the checkpoint left by an interrupted soong_build run and, if it is valid for
+// this run, makes its state available to the steps that can be resumed. The checkpoint is removed
+// either way, it is only resumed once.
+func ResumeAnalysis(config Config) error {
+	file := analysisCheckpointFile(config)
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil {
+		return err
+	}
+
+	var checkpoint AnalysisCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		// A truncated checkpoint only means there is nothing to resume.
+		return nil
+	}
+	key, err := analysisCheckpointKeyFor(config)
+	if err != nil {
+		return err
+	}
+	if checkpoint.Key != key {
+		return nil
+	}
+
+	state := analysisCheckpoints(config)
+	state.Lock()
+	defer state.Unlock()
+	state.resumed = &checkpoint
+	state.resumedGlobs = make(map[string]*CheckpointGlob, len(checkpoint.Globs))
+	for i := range checkpoint.Globs {
+		glob := &checkpoint.Globs[i]
+		state.resumedGlobs[globKey(glob.Pattern, glob.Excludes, glob.Follow)] = glob
+	}
+	return nil
+}
+
+// resumedCqueryOutput returns the cquery output of the resumed checkpoint if its inputs hash is the
+// same as the one of the current run.
+func resumedCqueryOutput(config Config, inputsHash string) (string, bool) {
+	state := analysisCheckpoints(config)
+	state.Lock()
+	defer state.Unlock()
+	if state.resumed == nil || state.resumed.CqueryInputsHash != inputsHash {
+		return "", false
+	}
+	return state.resumed.CqueryOutput, true
+}
+
+// recordCqueryOutput records the cquery output of the current run in its checkpoint.
+func recordCqueryOutput(config Config, inputsHash, output string) {
+	state := analysisCheckpoints(config)
+	state.Lock()
+	defer state.Unlock()
+	state.current.CqueryInputsHash = inputsHash
+	state.current.CqueryOutput = output
+}
+
+// writeAnalysisCheckpoint writes the checkpoint of the current run.
+func writeAnalysisCheckpoint(config Config, sig os.Signal, completedPhases []string) error {
+	key, err := analysisCheckpointKeyFor(config)
+	if err != nil {
+		return err
+	}
+
+	state := analysisCheckpoints(config)
+	state.Lock()
+	checkpoint := state.current
+	globs := state.globs
+	state.Unlock()
+	checkpoint.Globs = checkpointGlobs(globs)
+	checkpoint.Key = key
+	checkpoint.Signal = sig.String()
+	checkpoint.CompletedPhases = completedPhases
+
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a second signal can't leave a truncated checkpoint.
+	file := analysisCheckpointFile(config)
+	if err := os.WriteFile(file+".tmp", data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+var analysisInterruptsKey = NewOnceKey("analysisInterrupts")
+
+// analysisInterruptTimeout is how long soong_build waits for a safe point after a signal before it
+// exits without a checkpoint.
+const analysisInterruptTimeout = 2 * time.Second
+
+// analysisInterruptPending is set when a signal is waiting for the next safe point, so that the safe
+// points of the mutators and GenerateBuildActions of every module only cost an atomic load.
+var analysisInterruptPending atomic.Bool
+
+// The states of analysisInterrupts.
+const (
+	interruptNone int32 = iota
+	// A signal is waiting for the next safe point.
+	interruptPending
+	// A safe point is writing the checkpoint.
+	interruptCheckpointing
+	// soong_build is exiting without a checkpoint.
+	interruptExiting
+)
+
+// analysisInterrupts forwards the signal that interrupted soong_build to the next safe point.
+type analysisInterrupts struct {
+	eventHandler *metrics.EventHandler
+	metricsFile  string
+
+	// The first signal, set before state becomes interruptPending.
+	signal os.Signal
+	state  atomic.Int32
+}
+
+func newAnalysisInterrupts(config Config, eventHandler *metrics.EventHandler, metricsFile string) *analysisInterrupts {
+	return config.Once(analysisInterruptsKey, func() interface{} {
+		return &analysisInterrupts{
+			eventHandler: eventHandler,
+			metricsFile:  metricsFile,
+		}
+	}).(*analysisInterrupts)
+}
+
+// interrupt makes the next safe point write a checkpoint for sig.
+func (interrupts *analysisInterrupts) interrupt(sig os.Signal) {
+	interrupts.signal = sig
+	interrupts.state.Store(interruptPending)
+	analysisInterruptPending.Store(true)
+}
+
+// HandleAnalysisInterrupts makes soong_build write a checkpoint of the analysis and the metrics
+// collected so far to metricsFile when it receives SIGINT or SIGTERM, at the next call to
+// CheckAnalysisInterrupt, and then exit. If there is no safe point within
+// analysisInterruptTimeout, or on a second signal, it exits right away. metricsFile may be empty to
+// skip writing the metrics.
+func HandleAnalysisInterrupts(config Config, eventHandler *metrics.EventHandler, metricsFile string) {
+	interrupts := newAnalysisInterrupts(config, eventHandler, metricsFile)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		fmt.Fprintf(os.Stderr, "soong_build: got %s, writing an analysis checkpoint at the next safe point, send it again to exit now\n", sig)
+		interrupts.interrupt(sig)
+		select {
+		case sig = <-signals:
+			os.Exit(signalExitCode(sig))
+		case <-time.After(analysisInterruptTimeout):
+			if interrupts.state.CompareAndSwap(interruptPending, interruptExiting) {
+				fmt.Fprintf(os.Stderr, "soong_build: no safe point within %s, exiting without a checkpoint\n", analysisInterruptTimeout)
+				os.Exit(signalExitCode(interrupts.signal))
+			}
+		}
+		sig = <-signals
+		os.Exit(signalExitCode(sig))
+	}()
+}
+
+// CheckAnalysisInterrupt is a safe point of the analysis: if soong_build was interrupted, it writes
+// the checkpoint and the metrics, and exits. It must be called where no other goroutine updates the
+// state of the checkpoint or the events of the metrics, i.e. from the main goroutine between the
+// steps of soong_build, or from the goroutines of a mutator or of GenerateBuildActions before they
+// visit a module.
+func CheckAnalysisInterrupt(config Config) {
+	if !analysisInterruptPending.Load() {
+		return
+	}
+	if sig, interrupted := checkpointIfInterrupted(config); interrupted {
+		os.Exit(signalExitCode(sig))
+	}
+}
+
+// checkpointIfInterrupted writes the checkpoint and the metrics if soong_build was interrupted, and
+// returns the signal that interrupted it. If another goroutine is already writing the checkpoint,
+// or soong_build is exiting without one, it waits for soong_build to exit.
+func checkpointIfInterrupted(config Config) (os.Signal, bool) {
+	v, ok := config.Peek(analysisInterruptsKey)
+	if !ok {
+		return nil, false
+	}
+	interrupts := v.(*analysisInterrupts)
+	switch interrupts.state.Load() {
+	case interruptNone:
+		return nil, false
+	case interruptPending:
+		if interrupts.state.CompareAndSwap(interruptPending, interruptCheckpointing) {
+			break
+		}
+		fallthrough
+	default:
+		select {}
+	}
+	sig := interrupts.signal
+
+	var completedPhases []string
+	for _, event := range interrupts.eventHandler.CompletedEvents() {
+		completedPhases = append(completedPhases, event.Id)
+	}
+	if err := writeAnalysisCheckpoint(config, sig, completedPhases); err != nil {
+		fmt.Fprintf(os.Stderr, "soong_build: error writing analysis checkpoint: %s\n", err)
+	}
+	if interrupts.metricsFile != "" {
+		if err := writeInterruptedMetrics(config, interrupts.eventHandler, interrupts.metricsFile, sig); err != nil {
+			fmt.Fprintf(os.Stderr, "soong_build: error writing metrics %s: %s\n", interrupts.metricsFile, err)
+		}
+	}
+	return sig, true
+}
+
+// signalExitCode returns the exit code of a process killed by sig.
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
+// writeInterruptedMetrics writes the metrics of the events that completed before soong_build was
+// interrupted, with an event that records the interruption.
+func writeInterruptedMetrics(config Config, eventHandler *metrics.EventHandler, metricsFile string, sig os.Signal) error {
+	// The mutators of the other goroutines may still record the mixed build modules.
+	config.mixedBuildsLock.Lock()
+	metrics := collectMetrics(config, eventHandler)
+	config.mixedBuildsLock.Unlock()
+	metrics.Events = append(metrics.Events, &soong_metrics_proto.PerfInfo{
+		Description:  proto.String("interrupted"),
+		Name:         proto.String("soong_build"),
+		NonZeroExit:  proto.Bool(true),
+		ErrorMessage: proto.String("interrupted by " + sig.String()),
+	})
+
+	buf, err := proto.Marshal(metrics)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(absolutePath(metricsFile), buf, 0666)
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/blueprint/pathtools"
+)
+
+// The globs of the analysis are the part of it that the checkpoint of an interrupted run can save
+// for the next run: their results only depend on the files and directories they read, whose
+// modification times are recorded in the checkpoint. The resumed run answers a glob from the
+// checkpoint instead of reading the directories again if none of them changed.
+
+// CheckpointGlob is the result of a glob in the checkpoint.
+type CheckpointGlob struct {
+	Pattern  string   `json:"pattern"`
+	Excludes []string `json:"excludes,omitempty"`
+	Follow   bool     `json:"follow,omitempty"`
+	Matches  []string `json:"matches,omitempty"`
+
+	// The files and directories the glob read, and their modification times in nanoseconds.
+	Deps        []string `json:"deps,omitempty"`
+	DepModTimes []int64  `json:"dep_mod_times,omitempty"`
+}
+
+// recordedGlob is a glob of the current run.
+type recordedGlob struct {
+	fs       pathtools.FileSystem
+	pattern  string
+	excludes []string
+	follow   pathtools.ShouldFollowSymlinks
+	result   pathtools.GlobResult
+	// When the glob started. The glob is only checkpointed if none of its dependencies changed
+	// since then.
+	start time.Time
+	// The modification times of the dependencies, if the glob was resumed from a checkpoint.
+	modTimes []int64
+}
+
+func globKey(pattern string, excludes []string, follow bool) string {
+	key := pattern + "\x00" + strings.Join(excludes, "\x00")
+	if follow {
+		key += "\x00follow"
+	}
+	return key
+}
+
+// AnalysisGlobFs is a pathtools.FileSystem that records the globs of the analysis for the
+// checkpoint, and answers the globs of the resumed checkpoint whose dependencies didn't change.
+type AnalysisGlobFs struct {
+	pathtools.FileSystem
+	config Config
+}
+
+func NewAnalysisGlobFs(config Config, fs pathtools.FileSystem) *AnalysisGlobFs {
+	return &AnalysisGlobFs{FileSystem: fs, config: config}
+}
+
+func (fs *AnalysisGlobFs) Glob(pattern string, excludes []string, follow pathtools.ShouldFollowSymlinks) (pathtools.GlobResult, error) {
+	state := analysisCheckpoints(fs.config)
+	if result, modTimes, ok := fs.resumedGlob(state, pattern, excludes, follow); ok {
+		state.Lock()
+		state.globs = append(state.globs, recordedGlob{pattern: pattern, excludes: excludes, follow: follow,
+			result: result, modTimes: modTimes})
+		state.Unlock()
+		return result, nil
+	}
+
+	start := time.Now()
+	result, err := fs.FileSystem.Glob(pattern, excludes, follow)
+	if err == nil {
+		state.Lock()
+		state.globs = append(state.globs, recordedGlob{fs: fs.FileSystem, pattern: pattern, excludes: excludes,
+			follow: follow, result: result, start: start})
+		state.Unlock()
+	}
+	return result, err
+}
+
+// resumedGlob returns the result of the glob from the resumed checkpoint, and the modification
+// times of its dependencies, if none of them changed.
+func (fs *AnalysisGlobFs) resumedGlob(state *analysisCheckpointState, pattern string, excludes []string,
+	follow pathtools.ShouldFollowSymlinks) (pathtools.GlobResult, []int64, bool) {
+
+	state.Lock()
+	glob := state.resumedGlobs[globKey(pattern, excludes, bool(follow))]
+	state.Unlock()
+	if glob == nil || len(glob.Deps) != len(glob.DepModTimes) {
+		return pathtools.GlobResult{}, nil, false
+	}
+	for i, dep := range glob.Deps {
+		info, err := fs.FileSystem.Stat(dep)
+		if err != nil || info.ModTime().UnixNano() != glob.DepModTimes[i] {
+			return pathtools.GlobResult{}, nil, false
+		}
+	}
+	return pathtools.GlobResult{
+		Pattern:  pattern,
+		Excludes: excludes,
+		Matches:  glob.Matches,
+		Deps:     glob.Deps,
+	}, glob.DepModTimes, true
+}
+
+// checkpointGlobs returns the globs of the current run for the checkpoint. Globs whose dependencies
+// changed after they started, or were removed, are left out, as their results may be stale, and so
+// are globs without dependencies, which can't be validated.
+func checkpointGlobs(globs []recordedGlob) []CheckpointGlob {
+	var ret []CheckpointGlob
+	for _, glob := range globs {
+		if len(glob.result.Deps) == 0 {
+			continue
+		}
+		modTimes := glob.modTimes
+		if modTimes == nil {
+			modTimes = make([]int64, 0, len(glob.result.Deps))
+			for _, dep := range glob.result.Deps {
+				info, err := glob.fs.Stat(dep)
+				if err != nil || !info.ModTime().Before(glob.start) {
+					modTimes = nil
+					break
+				}
+				modTimes = append(modTimes, info.ModTime().UnixNano())
+			}
+			if modTimes == nil {
+				continue
+			}
+		}
+		ret = append(ret, CheckpointGlob{
+			Pattern:     glob.pattern,
+			Excludes:    glob.excludes,
+			Follow:      bool(glob.follow),
+			Matches:     glob.result.Matches,
+			Deps:        glob.result.Deps,
+			DepModTimes: modTimes,
+		})
+	}
+	return ret
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/blueprint/metrics"
+	"github.com/google/blueprint/pathtools"
+
+	"android/soong/bazel/cquery"
+)
+
+func testCheckpointConfig(t *testing.T, buildDir string) Config {
+	t.Helper()
+	config := TestConfig(buildDir, nil, "", nil)
+	if err := os.MkdirAll(config.SoongOutDir(), 0777); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestResumeAnalysis(t *testing.T) {
+	buildDir := t.TempDir()
+
+	interrupted := testCheckpointConfig(t, buildDir)
+	recordCqueryOutput(interrupted, "inputs", "output")
+	if err := writeAnalysisCheckpoint(interrupted, syscall.SIGTERM, []string{"bazel"}); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := testCheckpointConfig(t, buildDir)
+	if err := ResumeAnalysis(resumed); err != nil {
+		t.Fatal(err)
+	}
+	output, ok := resumedCqueryOutput(resumed, "inputs")
+	AssertBoolEquals(t, "resumed", true, ok)
+	AssertStringEquals(t, "cquery output", "output", output)
+	_, ok = resumedCqueryOutput(resumed, "other inputs")
+	AssertBoolEquals(t, "resumed with other inputs", false, ok)
+
+	if _, err := os.Stat(analysisCheckpointFile(resumed)); !os.IsNotExist(err) {
+		t.Errorf("expected the checkpoint to be removed once resumed, got %v", err)
+	}
+}
+
+func TestResumeAnalysisWithOtherConfig(t *testing.T) {
+	buildDir := t.TempDir()
+
+	interrupted := testCheckpointConfig(t, buildDir)
+	recordCqueryOutput(interrupted, "inputs", "output")
+	if err := writeAnalysisCheckpoint(interrupted, syscall.SIGINT, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	other := testCheckpointConfig(t, buildDir)
+	other.TestProductVariables.DeviceName = stringPtr("other_device")
+	if err := ResumeAnalysis(other); err != nil {
+		t.Fatal(err)
+	}
+	_, ok := resumedCqueryOutput(other, "inputs")
+	AssertBoolEquals(t, "resumed", false, ok)
+}
+
+func TestInvokeBazelResumesCquery(t *testing.T) {
+	buildDir := t.TempDir()
+	label := "@//foo:foo"
+	cfg := configKey{arch: "arm64_armv8-a", osType: Android}
+
+	interrupted := testCheckpointConfig(t, buildDir)
+	bazelContext, _ := testBazelContext(t, map[bazelCommand]string{
+		cqueryCmd: "@//foo:foo|arm64_armv8-a|android>>out/foo/foo.txt",
+	})
+	bazelContext.QueueBazelRequest(label, cquery.GetOutputFiles, cfg)
+	if err := bazelContext.InvokeBazel(interrupted, &testInvokeBazelContext{}); err != nil {
+		t.Fatalf("Did not expect error invoking Bazel, but got %s", err)
+	}
+	if err := writeAnalysisCheckpoint(interrupted, syscall.SIGTERM, []string{"bazel"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cquery of the resumed run returns nothing, its results come from the checkpoint.
+	resumed := testCheckpointConfig(t, buildDir)
+	if err := ResumeAnalysis(resumed); err != nil {
+		t.Fatal(err)
+	}
+	bazelContext, _ = testBazelContext(t, map[bazelCommand]string{})
+	bazelContext.QueueBazelRequest(label, cquery.GetOutputFiles, cfg)
+	if err := bazelContext.InvokeBazel(resumed, &testInvokeBazelContext{}); err != nil {
+		t.Fatalf("Did not expect error invoking Bazel, but got %s", err)
+	}
+	verifyCqueryResult(t, bazelContext, label, cfg, "out/foo/foo.txt")
+}
+
+func TestCheckpointIfInterrupted(t *testing.T) {
+	config := testCheckpointConfig(t, t.TempDir())
+	if _, interrupted := checkpointIfInterrupted(config); interrupted {
+		t.Fatalf("expected no interruption without a signal handler")
+	}
+
+	eventHandler := &metrics.EventHandler{}
+	eventHandler.Begin("bazel")
+	eventHandler.End("bazel")
+	interrupts := newAnalysisInterrupts(config, eventHandler, "")
+	if _, interrupted := checkpointIfInterrupted(config); interrupted {
+		t.Fatalf("expected no interruption before a signal")
+	}
+	if _, err := os.Stat(analysisCheckpointFile(config)); !os.IsNotExist(err) {
+		t.Fatalf("expected no checkpoint before a signal, got %v", err)
+	}
+
+	// The signal goroutine only forwards the signal, the checkpoint is written at the safe point.
+	interrupts.interrupt(syscall.SIGTERM)
+	t.Cleanup(func() { analysisInterruptPending.Store(false) })
+	sig, interrupted := checkpointIfInterrupted(config)
+	AssertBoolEquals(t, "interrupted", true, interrupted)
+	AssertIntEquals(t, "exit code", 128+int(syscall.SIGTERM), signalExitCode(sig))
+
+	data, err := os.ReadFile(analysisCheckpointFile(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var checkpoint AnalysisCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		t.Fatal(err)
+	}
+	AssertStringEquals(t, "signal", syscall.SIGTERM.String(), checkpoint.Signal)
+	AssertArrayString(t, "completed phases", []string{"bazel"}, checkpoint.CompletedPhases)
+}
+
+func TestResumeAnalysisGlobs(t *testing.T) {
+	buildDir := t.TempDir()
+	srcDir := t.TempDir()
+	// Make the directory older than the glob, so that its result can be checkpointed.
+	old := time.Now().Add(-time.Minute)
+	for _, dir := range []string{"a", "b"} {
+		dir = filepath.Join(srcDir, dir)
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "foo.go"), nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pattern := func(dir string) string { return filepath.Join(srcDir, dir, "*.go") }
+
+	interrupted := testCheckpointConfig(t, buildDir)
+	fs := NewAnalysisGlobFs(interrupted, pathtools.OsFs)
+	for _, dir := range []string{"a", "b"} {
+		if _, err := fs.Glob(pattern(dir), nil, pathtools.FollowSymlinks); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeAnalysisCheckpoint(interrupted, syscall.SIGTERM, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files added to b after the checkpoint change the directory, so only the glob of a is resumed.
+	for _, dir := range []string{"a", "b"} {
+		if err := os.WriteFile(filepath.Join(srcDir, dir, "bar.go"), nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(filepath.Join(srcDir, "a"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := testCheckpointConfig(t, buildDir)
+	if err := ResumeAnalysis(resumed); err != nil {
+		t.Fatal(err)
+	}
+	fs = NewAnalysisGlobFs(resumed, pathtools.OsFs)
+	result, err := fs.Glob(pattern("a"), nil, pathtools.FollowSymlinks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertArrayString(t, "resumed glob", []string{filepath.Join(srcDir, "a", "foo.go")}, result.Matches)
+	result, err = fs.Glob(pattern("b"), nil, pathtools.FollowSymlinks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertArrayString(t, "changed glob", []string{
+		filepath.Join(srcDir, "b", "bar.go"),
+		filepath.Join(srcDir, "b", "foo.go"),
+	}, result.Matches)
+}
@@ -399,57 +399,80 @@ func (a *AndroidMkEntries) getDistContributions(mod blueprint.Module) *distContr
 			panic(fmt.Errorf(errorMessage, mod, goals, tag, name, tagPaths))
 		}
 
-		copiesForGoals := distContributions.getCopiesForGoals(goals)
-
-		// Iterate over each path adding a copy instruction to copiesForGoals
-		for _, path := range tagPaths {
-			// It's possible that the Path is nil from errant modules. Be defensive here.
-			if path == nil {
-				tagName := "default" // for error message readability
-				if dist.Tag != nil {
-					tagName = *dist.Tag
+		addCopies := func(goals, groupDir string) {
+			copiesForGoals := distContributions.getCopiesForGoals(goals)
+
+			// Iterate over each path adding a copy instruction to copiesForGoals
+			for _, path := range tagPaths {
+				// It's possible that the Path is nil from errant modules. Be defensive here.
+				if path == nil {
+					tagName := "default" // for error message readability
+					if dist.Tag != nil {
+						tagName = *dist.Tag
+					}
+					panic(fmt.Errorf("Dist file should not be nil for the %s tag in %s", tagName, name))
 				}
-				panic(fmt.Errorf("Dist file should not be nil for the %s tag in %s", tagName, name))
+
+				dest := filepath.Join(groupDir, distCopyDest(a.entryContext.Config(), dist, path))
+				copiesForGoals.addCopyInstruction(path, dest)
 			}
+		}
 
-			dest := filepath.Base(path.String())
+		if len(dist.Targets) > 0 || len(dist.Groups) == 0 {
+			addCopies(goals, "")
+		}
+		// The outputs of each dist group are copied into its own subdirectory by its own goal.
+		for _, group := range dist.Groups {
+			addCopies(distGroupGoal(group), group)
+		}
+	}
 
-			if dist.Dest != nil {
-				var err error
-				if dest, err = validateSafePath(*dist.Dest); err != nil {
-					// This was checked in ModuleBase.GenerateBuildActions
-					panic(err)
-				}
-			}
+	return distContributions
+}
 
-			ext := filepath.Ext(dest)
-			suffix := ""
-			if dist.Suffix != nil {
-				suffix = *dist.Suffix
-			}
+// distGroupGoal returns the goal that copies the outputs of the dist group to the dist directory,
+// which `m dist DIST_GROUPS=<group>` builds.
+func distGroupGoal(group string) string {
+	return "dist_group_" + group
+}
 
-			productString := ""
-			if dist.Append_artifact_with_product != nil && *dist.Append_artifact_with_product {
-				productString = fmt.Sprintf("_%s", a.entryContext.Config().DeviceProduct())
-			}
+// distCopyDest returns the destination within the dist directory that the given output file of
+// a module is copied to for the given dist struct.
+func distCopyDest(config Config, dist Dist, path Path) string {
+	dest := filepath.Base(path.String())
 
-			if suffix != "" || productString != "" {
-				dest = strings.TrimSuffix(dest, ext) + suffix + productString + ext
-			}
+	if dist.Dest != nil {
+		var err error
+		if dest, err = validateSafePath(*dist.Dest); err != nil {
+			// This was checked in ModuleBase.GenerateBuildActions
+			panic(err)
+		}
+	}
 
-			if dist.Dir != nil {
-				var err error
-				if dest, err = validateSafePath(*dist.Dir, dest); err != nil {
-					// This was checked in ModuleBase.GenerateBuildActions
-					panic(err)
-				}
-			}
+	ext := filepath.Ext(dest)
+	suffix := ""
+	if dist.Suffix != nil {
+		suffix = *dist.Suffix
+	}
 
-			copiesForGoals.addCopyInstruction(path, dest)
+	productString := ""
+	if dist.Append_artifact_with_product != nil && *dist.Append_artifact_with_product {
+		productString = fmt.Sprintf("_%s", config.DeviceProduct())
+	}
+
+	if suffix != "" || productString != "" {
+		dest = strings.TrimSuffix(dest, ext) + suffix + productString + ext
+	}
+
+	if dist.Dir != nil {
+		var err error
+		if dest, err = validateSafePath(*dist.Dir, dest); err != nil {
+			// This was checked in ModuleBase.GenerateBuildActions
+			panic(err)
 		}
 	}
 
-	return distContributions
+	return dest
 }
 
 // generateDistContributionsForMake generates make rules that will generate the
@@ -949,6 +972,10 @@ func shouldSkipAndroidMkProcessing(module *ModuleBase) bool {
 		module.commonProperties.HideFromMake ||
 		// Make does not understand LinuxBionic
 		module.Os() == LinuxBionic ||
+		// Make does not understand Wasi
+		module.Os() == Wasi ||
+		// Make does not understand the cross sysroot OSes
+		module.Os() == LinuxEmbedded || module.Os() == Qnx ||
 		// Make does not understand LinuxMusl, except when we are building with USE_HOST_MUSL=true
 		// and all host binaries are LinuxMusl
 		(module.Os() == LinuxMusl && module.Target().HostCross)
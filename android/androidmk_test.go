@@ -789,4 +789,50 @@ func TestGetDistContributions(t *testing.T) {
 			},
 		},
 	})
+
+	testHelper(t, "dist-groups", `
+			custom {
+				name: "foo",
+				dists: [
+					{
+						targets: ["my_goal"],
+						groups: ["symbols"],
+						dir: "foo",
+					},
+					{
+						groups: ["symbols", "sbom"],
+						tag: ".multiple",
+					},
+				],
+			}
+`, &distContributions{
+		copiesForGoals: []*copiesForGoals{
+			{
+				goals: "my_goal",
+				copies: []distCopy{
+					distCopyForTest("one.out", "foo/one.out"),
+				},
+			},
+			{
+				goals: "dist_group_symbols",
+				copies: []distCopy{
+					distCopyForTest("one.out", "symbols/foo/one.out"),
+				},
+			},
+			{
+				goals: "dist_group_symbols",
+				copies: []distCopy{
+					distCopyForTest("two.out", "symbols/two.out"),
+					distCopyForTest("three/four.out", "symbols/four.out"),
+				},
+			},
+			{
+				goals: "dist_group_sbom",
+				copies: []distCopy{
+					distCopyForTest("two.out", "sbom/two.out"),
+					distCopyForTest("three/four.out", "sbom/four.out"),
+				},
+			},
+		},
+	})
 }
@@ -861,7 +861,8 @@ type ModuleWithMinSdkVersionCheck interface {
 }
 
 // CheckMinSdkVersion checks if every dependency of an updatable module sets min_sdk_version
-// accordingly
+// accordingly. The violations of the modules in BUILD_BROKEN_MIN_SDK_VERSION_DIRS are written to
+// min_sdk_version_violations.txt instead of failing the build.
 func CheckMinSdkVersion(ctx ModuleContext, minSdkVersion ApiLevel, walk WalkPayloadDepsFunc) {
 	// do not enforce min_sdk_version for host
 	if ctx.Host() {
@@ -898,12 +899,13 @@ func CheckMinSdkVersion(ctx ModuleContext, minSdkVersion ApiLevel, walk WalkPayl
 		}
 		if err := to.ShouldSupportSdkVersion(ctx, minSdkVersion); err != nil {
 			toName := ctx.OtherModuleName(to)
-			ctx.OtherModuleErrorf(to, "should support min_sdk_version(%v) for %q: %v."+
-				"\n\nDependency path: %s\n\n"+
-				"Consider adding 'min_sdk_version: %q' to %q",
+			reportMinSdkVersionViolation(ctx, to, fmt.Sprintf(
+				"should support min_sdk_version(%v) for %q: %v."+
+					"\n\nDependency path: %s\n\n"+
+					"Consider adding 'min_sdk_version: %q' to %q",
 				minSdkVersion, ctx.ModuleName(), err.Error(),
-				ctx.GetPathString(false),
-				minSdkVersion, toName)
+				minSdkVersionPathString(ctx),
+				minSdkVersion, toName))
 			return false
 		}
 		return true
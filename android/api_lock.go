@@ -0,0 +1,272 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// An api_lock module declares that its directory provides a set of modules to the rest of the
+// tree under a contract recorded in a lock file: their names, module types, stability and link
+// types. Changes to the modules of the directory that break the contract, and dependencies from
+// other directories on modules that are not part of it, are errors at analysis time, so that a
+// refactor cannot silently change what a team provides to another.
+//
+// The lock file is JSON:
+//
+//	{
+//	    "modules": [
+//	        {
+//	            "name": "libfoo",
+//	            "type": "cc_library",
+//	            "stability": ["vendor_available"],
+//	            "link_types": ["shared", "static"]
+//	        }
+//	    ]
+//	}
+//
+// The current contract of every locked directory is written to
+// $OUT/soong/api_lock/<dir>/api_lock.json, which can be copied over the lock file when a change to
+// the contract is intended.
+
+func init() {
+	RegisterApiLockBuildComponents(InitRegistrationContext)
+}
+
+func RegisterApiLockBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterModuleType("api_lock", ApiLockFactory)
+	ctx.RegisterSingletonType("api_lock", apiLockSingletonFactory)
+}
+
+var PrepareForTestWithApiLock = FixtureRegisterWithContext(RegisterApiLockBuildComponents)
+
+// ApiLockContributor is implemented by modules that describe more of their contract in API lock
+// files than their name and module type.
+type ApiLockContributor interface {
+	// ApiLockLinkType returns how consumers link against this variant of the module, e.g. "shared"
+	// or "static", or "" if it doesn't apply.
+	ApiLockLinkType() string
+
+	// ApiLockStability returns the stability guarantees of this variant of the module, e.g.
+	// "llndk" or "vendor_available".
+	ApiLockStability() []string
+}
+
+type apiLockProperties struct {
+	// The lock file, relative to the directory of the module. Defaults to api_lock.json.
+	Lock_file *string
+}
+
+type apiLockModule struct {
+	ModuleBase
+
+	properties apiLockProperties
+
+	lockFile Path
+}
+
+// api_lock locks the contract of the modules of its directory with the modules of other
+// directories.
+func ApiLockFactory() Module {
+	module := &apiLockModule{}
+	module.AddProperties(&module.properties)
+	InitAndroidModule(module)
+	return module
+}
+
+func (m *apiLockModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	m.lockFile = PathForModuleSrc(ctx, proptools.StringDefault(m.properties.Lock_file, "api_lock.json"))
+}
+
+// ApiLockEntry is the contract of a module in an API lock file.
+type ApiLockEntry struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Stability []string `json:"stability,omitempty"`
+	LinkTypes []string `json:"link_types,omitempty"`
+}
+
+// ApiLockFile is the content of an API lock file.
+type ApiLockFile struct {
+	Modules []ApiLockEntry `json:"modules"`
+}
+
+func (e ApiLockEntry) equals(other ApiLockEntry) bool {
+	return e.Name == other.Name && e.Type == other.Type &&
+		strings.Join(e.Stability, " ") == strings.Join(other.Stability, " ") &&
+		strings.Join(e.LinkTypes, " ") == strings.Join(other.LinkTypes, " ")
+}
+
+func (e ApiLockEntry) String() string {
+	data, _ := json.Marshal(e)
+	return string(data)
+}
+
+func apiLockSingletonFactory() Singleton {
+	return &apiLockSingleton{}
+}
+
+type apiLockSingleton struct{}
+
+// lockedDir is a directory with an api_lock module.
+type lockedDir struct {
+	module Module
+	locked map[string]ApiLockEntry
+	actual map[string]*ApiLockEntry
+}
+
+func readApiLockFile(ctx SingletonContext, lockFile Path) (*ApiLockFile, error) {
+	ctx.AddNinjaFileDeps(lockFile.String())
+	r, err := ctx.Config().fs.Open(lockFile.String())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var lock ApiLockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("%s: %s", lockFile, err)
+	}
+	return &lock, nil
+}
+
+func (s *apiLockSingleton) GenerateBuildActions(ctx SingletonContext) {
+	dirs := make(map[string]*lockedDir)
+	ctx.VisitAllModules(func(module Module) {
+		lockModule, ok := module.(*apiLockModule)
+		if !ok || lockModule.lockFile == nil {
+			return
+		}
+		dir := ctx.ModuleDir(module)
+		if other, exists := dirs[dir]; exists {
+			ctx.ModuleErrorf(module, "%s already has an api_lock module %q", dir, other.module.Name())
+			return
+		}
+		lock, err := readApiLockFile(ctx, lockModule.lockFile)
+		if err != nil {
+			ctx.ModuleErrorf(module, "failed to read the lock file: %s", err)
+			return
+		}
+		locked := make(map[string]ApiLockEntry, len(lock.Modules))
+		for _, entry := range lock.Modules {
+			entry.Stability = SortedUniqueStrings(entry.Stability)
+			entry.LinkTypes = SortedUniqueStrings(entry.LinkTypes)
+			locked[entry.Name] = entry
+		}
+		dirs[dir] = &lockedDir{module: module, locked: locked, actual: make(map[string]*ApiLockEntry)}
+	})
+	if len(dirs) == 0 {
+		return
+	}
+
+	// Collect the current contract of the modules of the locked directories from all their
+	// variants.
+	ctx.VisitAllModules(func(module Module) {
+		dir := dirs[ctx.ModuleDir(module)]
+		if dir == nil || !module.Enabled() {
+			return
+		}
+		if _, ok := module.(*apiLockModule); ok {
+			return
+		}
+		name := ctx.ModuleName(module)
+		entry := dir.actual[name]
+		if entry == nil {
+			entry = &ApiLockEntry{Name: name, Type: ctx.ModuleType(module)}
+			dir.actual[name] = entry
+		}
+		if c, ok := module.(ApiLockContributor); ok {
+			entry.Stability = append(entry.Stability, c.ApiLockStability()...)
+			if linkType := c.ApiLockLinkType(); linkType != "" {
+				entry.LinkTypes = append(entry.LinkTypes, linkType)
+			}
+		}
+	})
+
+	for _, dirName := range SortedKeys(dirs) {
+		dir := dirs[dirName]
+		lockFile := dir.module.(*apiLockModule).lockFile
+
+		current := ApiLockFile{Modules: []ApiLockEntry{}}
+		for _, name := range SortedKeys(dir.actual) {
+			entry := dir.actual[name]
+			entry.Stability = SortedUniqueStrings(entry.Stability)
+			entry.LinkTypes = SortedUniqueStrings(entry.LinkTypes)
+			current.Modules = append(current.Modules, *entry)
+		}
+		data, err := json.MarshalIndent(current, "", "    ")
+		if err != nil {
+			ctx.Errorf("%s", err.Error())
+			return
+		}
+		currentFile := PathForOutput(ctx, "api_lock", dirName, "api_lock.json")
+		WriteFileRule(ctx, currentFile, string(data))
+
+		// The modules of the directory must still provide what the lock file promises. Modules
+		// that are not in the lock file are private to the directory.
+		for _, name := range SortedKeys(dir.locked) {
+			locked := dir.locked[name]
+			actual, ok := dir.actual[name]
+			if !ok {
+				ctx.ModuleErrorf(dir.module, "module %q is locked in %s but no longer exists.\n"+
+					"If this is intended, update %s from %s", name, lockFile, lockFile, currentFile)
+			} else if !locked.equals(*actual) {
+				ctx.ModuleErrorf(dir.module, "the contract of module %q changed:\n  locked:  %s\n  current: %s\n"+
+					"If this is intended, update %s from %s", name, locked, *actual, lockFile, currentFile)
+			}
+		}
+	}
+
+	// Modules of other directories may only depend on the locked modules of a locked directory,
+	// with one of their locked link types.
+	reported := make(map[string]bool)
+	ctx.VisitAllModules(func(module Module) {
+		moduleDir := ctx.ModuleDir(module)
+		ctx.VisitDirectDeps(module, func(dep Module) {
+			depDir := ctx.ModuleDir(dep)
+			dir := dirs[depDir]
+			if dir == nil || depDir == moduleDir || dep == dir.module {
+				return
+			}
+			depName := ctx.ModuleName(dep)
+			var err string
+			if locked, ok := dir.locked[depName]; !ok {
+				err = fmt.Sprintf("depends on %q which is not provided by the api_lock %q of %s",
+					depName, dir.module.Name(), depDir)
+			} else if c, ok := dep.(ApiLockContributor); ok {
+				if linkType := c.ApiLockLinkType(); linkType != "" && !InList(linkType, locked.LinkTypes) {
+					err = fmt.Sprintf("depends on the %s variant of %q but the api_lock %q of %s only provides %s",
+						linkType, depName, dir.module.Name(), depDir, strings.Join(locked.LinkTypes, ", "))
+				}
+			}
+			if err == "" {
+				return
+			}
+			key := ctx.ModuleName(module) + "\x00" + err
+			if !reported[key] {
+				reported[key] = true
+				ctx.ModuleErrorf(module, "%s", err)
+			}
+		})
+	})
+}
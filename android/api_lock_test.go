@@ -0,0 +1,236 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+type apiLockTestModule struct {
+	ModuleBase
+	properties struct {
+		Deps      []string
+		Link_type *string
+		Stability []string
+	}
+}
+
+type apiLockTestDepTag struct {
+	blueprint.BaseDependencyTag
+}
+
+func apiLockTestModuleFactory() Module {
+	m := &apiLockTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidModule(m)
+	return m
+}
+
+func (m *apiLockTestModule) DepsMutator(ctx BottomUpMutatorContext) {
+	ctx.AddDependency(ctx.Module(), apiLockTestDepTag{}, m.properties.Deps...)
+}
+
+func (m *apiLockTestModule) GenerateAndroidBuildActions(ModuleContext) {
+}
+
+func (m *apiLockTestModule) ApiLockLinkType() string {
+	return proptools.String(m.properties.Link_type)
+}
+
+func (m *apiLockTestModule) ApiLockStability() []string {
+	return m.properties.Stability
+}
+
+func TestApiLock(t *testing.T) {
+	providerBp := `
+		api_lock {
+			name: "provider_api_lock",
+		}
+
+		test_module {
+			name: "libfoo",
+			link_type: "shared",
+			stability: ["vendor_available"],
+		}
+
+		test_module {
+			name: "libfoo_internal",
+			link_type: "static",
+		}
+	`
+	lockFile := `{
+		"modules": [
+			{
+				"name": "libfoo",
+				"type": "test_module",
+				"stability": ["vendor_available"],
+				"link_types": ["shared"]
+			}
+		]
+	}`
+
+	testCases := []struct {
+		name          string
+		providerBp    string
+		lockFileName  string
+		lockFile      string
+		consumerDeps  string
+		expectedError string
+	}{
+		{
+			name:         "locked",
+			providerBp:   providerBp,
+			lockFile:     lockFile,
+			consumerDeps: `["libfoo"]`,
+		},
+		{
+			name:          "not locked",
+			providerBp:    providerBp,
+			lockFile:      lockFile,
+			consumerDeps:  `["libfoo_internal"]`,
+			expectedError: `depends on "libfoo_internal" which is not provided by the api_lock "provider_api_lock" of provider`,
+		},
+		{
+			name: "contract changed",
+			providerBp: `
+				api_lock {
+					name: "provider_api_lock",
+					lock_file: "contract.json",
+				}
+
+				test_module {
+					name: "libfoo",
+					link_type: "static",
+				}
+			`,
+			lockFileName:  "provider/contract.json",
+			lockFile:      `{"modules": [{"name": "libfoo", "type": "test_module", "link_types": ["static", "shared"]}]}`,
+			consumerDeps:  `["libfoo"]`,
+			expectedError: `the contract of module "libfoo" changed`,
+		},
+		{
+			name: "removed",
+			providerBp: `
+				api_lock {
+					name: "provider_api_lock",
+				}
+			`,
+			lockFile:      lockFile,
+			consumerDeps:  `[]`,
+			expectedError: `module "libfoo" is locked in provider/api_lock.json but no longer exists`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lockFileName := "provider/api_lock.json"
+			if tc.lockFileName != "" {
+				lockFileName = tc.lockFileName
+			}
+			errorHandler := FixtureExpectsNoErrors
+			if tc.expectedError != "" {
+				errorHandler = FixtureExpectsAtLeastOneErrorMatchingPattern(tc.expectedError)
+			}
+			GroupFixturePreparers(
+				PrepareForTestWithApiLock,
+				FixtureRegisterWithContext(func(ctx RegistrationContext) {
+					ctx.RegisterModuleType("test_module", apiLockTestModuleFactory)
+				}),
+				FixtureAddTextFile("provider/Android.bp", tc.providerBp),
+				FixtureAddTextFile(lockFileName, tc.lockFile),
+				FixtureAddTextFile("consumer/Android.bp", `
+					test_module {
+						name: "consumer",
+						deps: `+tc.consumerDeps+`,
+					}
+				`),
+			).
+				ExtendWithErrorHandler(errorHandler).
+				RunTest(t)
+		})
+	}
+}
+
+func TestApiLockLinkType(t *testing.T) {
+	GroupFixturePreparers(
+		PrepareForTestWithApiLock,
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("test_module", apiLockTestModuleFactory)
+		}),
+		FixtureAddTextFile("provider/Android.bp", `
+			api_lock {
+				name: "provider_api_lock",
+			}
+
+			test_module {
+				name: "libfoo",
+				link_type: "static",
+			}
+		`),
+		FixtureAddTextFile("provider/api_lock.json", `{"modules": [{"name": "libfoo", "type": "test_module", "link_types": ["shared"]}]}`),
+		FixtureAddTextFile("consumer/Android.bp", `
+			test_module {
+				name: "consumer",
+				deps: ["libfoo"],
+			}
+		`),
+	).
+		ExtendWithErrorHandler(FixtureExpectsAllErrorsToMatchAPattern([]string{
+			`the contract of module "libfoo" changed`,
+			`depends on the static variant of "libfoo" but the api_lock "provider_api_lock" of provider only provides shared`,
+		})).
+		RunTest(t)
+}
+
+func TestApiLockCurrentContract(t *testing.T) {
+	result := GroupFixturePreparers(
+		PrepareForTestWithApiLock,
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("test_module", apiLockTestModuleFactory)
+		}),
+		FixtureAddTextFile("provider/Android.bp", `
+			api_lock {
+				name: "provider_api_lock",
+			}
+
+			test_module {
+				name: "libfoo",
+				link_type: "shared",
+				stability: ["vendor_available", "llndk"],
+			}
+		`),
+		FixtureAddTextFile("provider/api_lock.json", `{"modules": []}`),
+	).RunTest(t)
+
+	out := result.SingletonForTests("api_lock").Output("api_lock/provider/api_lock.json")
+	AssertStringEquals(t, "current contract", `{
+    "modules": [
+        {
+            "name": "libfoo",
+            "type": "test_module",
+            "stability": [
+                "llndk",
+                "vendor_available"
+            ],
+            "link_types": [
+                "shared"
+            ]
+        }
+    ]
+}`, ContentFromFileRuleForTests(t, out))
+}
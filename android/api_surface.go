@@ -0,0 +1,154 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+)
+
+// The API surface consistency check verifies that the code a module depends on across languages is
+// available to the API surface the module is built against, e.g. that the JNI libraries of an app
+// built against the system SDK only use the NDK and not platform APIs. These mismatches otherwise
+// only show up as link failures at runtime on a device.
+//
+// Modules describe their API surface and the surfaces of their cross-language dependencies with
+// ApiSurfaceInfoProvider, and the api_surface_consistency singleton reports all the mismatches of
+// the build together.
+
+func init() {
+	RegisterApiSurfaceBuildComponents(InitRegistrationContext)
+}
+
+func RegisterApiSurfaceBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("api_surface_consistency", apiSurfaceConsistencySingletonFactory)
+}
+
+var PrepareForTestWithApiSurfaceConsistency = FixtureRegisterWithContext(RegisterApiSurfaceBuildComponents)
+
+// ApiSurface is an API surface that a module can be built against. Each surface includes the
+// surfaces before it.
+type ApiSurface int
+
+const (
+	ApiSurfacePublic ApiSurface = iota
+	ApiSurfaceSystem
+	ApiSurfaceTest
+	ApiSurfaceModuleLib
+	ApiSurfaceSystemServer
+	ApiSurfacePlatform
+)
+
+func (s ApiSurface) String() string {
+	switch s {
+	case ApiSurfacePublic:
+		return "public"
+	case ApiSurfaceSystem:
+		return "system"
+	case ApiSurfaceTest:
+		return "test"
+	case ApiSurfaceModuleLib:
+		return "module_lib"
+	case ApiSurfaceSystemServer:
+		return "system_server"
+	case ApiSurfacePlatform:
+		return "platform"
+	default:
+		panic(fmt.Errorf("unknown API surface %d", s))
+	}
+}
+
+// Includes returns whether the APIs of the other surface are available to code built against this
+// surface.
+func (s ApiSurface) Includes(other ApiSurface) bool {
+	return other <= s
+}
+
+// ApiSurfaceForSdkKind returns the API surface of an SDK kind. The kinds that are not an API
+// surface of the platform, like the core platform API, are considered the platform surface.
+func ApiSurfaceForSdkKind(kind SdkKind) ApiSurface {
+	switch kind {
+	case SdkPublic:
+		return ApiSurfacePublic
+	case SdkSystem:
+		return ApiSurfaceSystem
+	case SdkTest:
+		return ApiSurfaceTest
+	case SdkModule:
+		return ApiSurfaceModuleLib
+	case SdkSystemServer:
+		return ApiSurfaceSystemServer
+	default:
+		return ApiSurfacePlatform
+	}
+}
+
+// ApiSurfaceDep is a cross-language dependency of a module and the API surface it requires.
+type ApiSurfaceDep struct {
+	// The name of the dependency.
+	Module string
+	// The kind of the dependency, e.g. "jni".
+	Kind string
+	// The API surface the dependency is built against.
+	Surface ApiSurface
+	// The property of the module that adds the dependency.
+	Property string
+}
+
+// ApiSurfaceInfo describes the API surface of a module and of its cross-language dependencies.
+type ApiSurfaceInfo struct {
+	Surface ApiSurface
+	Deps    []ApiSurfaceDep
+}
+
+var ApiSurfaceInfoProvider = blueprint.NewProvider(ApiSurfaceInfo{})
+
+func apiSurfaceConsistencySingletonFactory() Singleton {
+	return &apiSurfaceConsistencySingleton{}
+}
+
+// apiSurfaceConsistencySingleton reports the cross-language dependencies that are not available to
+// the API surface of the modules that use them.
+type apiSurfaceConsistencySingleton struct{}
+
+func (s *apiSurfaceConsistencySingleton) GenerateBuildActions(ctx SingletonContext) {
+	var mismatches []string
+	ctx.VisitAllModules(func(module Module) {
+		if !ctx.ModuleHasProvider(module, ApiSurfaceInfoProvider) {
+			return
+		}
+		info := ctx.ModuleProvider(module, ApiSurfaceInfoProvider).(ApiSurfaceInfo)
+		for _, dep := range info.Deps {
+			if info.Surface.Includes(dep.Surface) {
+				continue
+			}
+			mismatches = append(mismatches, fmt.Sprintf(
+				"%s %q (%s): %s dependency %q in %q requires the %s API surface, but the module is built against the %s API surface",
+				ctx.ModuleType(module), ctx.ModuleName(module), ctx.ModuleSubDir(module),
+				dep.Kind, dep.Module, dep.Property, dep.Surface, info.Surface))
+		}
+	})
+	if len(mismatches) == 0 {
+		return
+	}
+
+	sort.Strings(mismatches)
+	mismatches = FirstUniqueStrings(mismatches)
+	ctx.Errorf("API surface consistency check failed, %d mismatches:\n  %s",
+		len(mismatches), strings.Join(mismatches, "\n  "))
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+)
+
+type apiSurfaceTestModule struct {
+	ModuleBase
+	properties struct {
+		Surface  string
+		Jni_libs []string
+	}
+}
+
+func apiSurfaceTestModuleFactory() Module {
+	m := &apiSurfaceTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidModule(m)
+	return m
+}
+
+var apiSurfacesByName = map[string]ApiSurface{
+	"public":   ApiSurfacePublic,
+	"system":   ApiSurfaceSystem,
+	"platform": ApiSurfacePlatform,
+}
+
+func (m *apiSurfaceTestModule) DepsMutator(ctx BottomUpMutatorContext) {
+	ctx.AddDependency(ctx.Module(), nil, m.properties.Jni_libs...)
+}
+
+func (m *apiSurfaceTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	info := ApiSurfaceInfo{Surface: apiSurfacesByName[m.properties.Surface]}
+	ctx.VisitDirectDeps(func(dep Module) {
+		info.Deps = append(info.Deps, ApiSurfaceDep{
+			Module:   ctx.OtherModuleName(dep),
+			Kind:     "jni",
+			Surface:  apiSurfacesByName[dep.(*apiSurfaceTestModule).properties.Surface],
+			Property: "jni_libs",
+		})
+	})
+	ctx.SetProvider(ApiSurfaceInfoProvider, info)
+}
+
+var prepareForApiSurfaceTest = GroupFixturePreparers(
+	PrepareForTestWithApiSurfaceConsistency,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("api_surface_test", apiSurfaceTestModuleFactory)
+	}),
+)
+
+func TestApiSurfaceIncludes(t *testing.T) {
+	AssertBoolEquals(t, "system includes public", true, ApiSurfaceSystem.Includes(ApiSurfacePublic))
+	AssertBoolEquals(t, "system includes system", true, ApiSurfaceSystem.Includes(ApiSurfaceSystem))
+	AssertBoolEquals(t, "system includes platform", false, ApiSurfaceSystem.Includes(ApiSurfacePlatform))
+	AssertBoolEquals(t, "platform includes module_lib", true, ApiSurfacePlatform.Includes(ApiSurfaceModuleLib))
+	AssertBoolEquals(t, "public includes test", false, ApiSurfacePublic.Includes(ApiSurfaceTest))
+
+	AssertDeepEquals(t, "system_current", ApiSurfaceSystem, ApiSurfaceForSdkKind(SdkSystem))
+	AssertDeepEquals(t, "core_platform", ApiSurfacePlatform, ApiSurfaceForSdkKind(SdkCorePlatform))
+}
+
+func TestApiSurfaceConsistency(t *testing.T) {
+	prepareForApiSurfaceTest.RunTestWithBp(t, `
+		api_surface_test {
+			name: "app",
+			surface: "system",
+			jni_libs: ["libndk"],
+		}
+
+		api_surface_test {
+			name: "platform_app",
+			surface: "platform",
+			jni_libs: ["libndk", "libplatform"],
+		}
+
+		api_surface_test {
+			name: "libndk",
+			surface: "public",
+		}
+
+		api_surface_test {
+			name: "libplatform",
+			surface: "platform",
+		}
+	`)
+}
+
+func TestApiSurfaceConsistencyErrors(t *testing.T) {
+	prepareForApiSurfaceTest.
+		ExtendWithErrorHandler(FixtureExpectsOneErrorPattern(
+			`API surface consistency check failed, 2 mismatches:`+
+				`\n  api_surface_test "public_app" \(\): jni dependency "libsystem" in "jni_libs" requires the system API surface, but the module is built against the public API surface`+
+				`\n  api_surface_test "system_app" \(\): jni dependency "libplatform" in "jni_libs" requires the platform API surface, but the module is built against the system API surface`)).
+		RunTestWithBp(t, `
+		api_surface_test {
+			name: "system_app",
+			surface: "system",
+			jni_libs: ["libplatform", "libsystem"],
+		}
+
+		api_surface_test {
+			name: "public_app",
+			surface: "public",
+			jni_libs: ["libsystem"],
+		}
+
+		api_surface_test {
+			name: "libsystem",
+			surface: "system",
+		}
+
+		api_surface_test {
+			name: "libplatform",
+			surface: "platform",
+		}
+	`)
+}
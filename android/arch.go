@@ -153,6 +153,7 @@ var (
 	Riscv64 = newArch("riscv64", "lib64")
 	X86     = newArch("x86", "lib32")
 	X86_64  = newArch("x86_64", "lib64")
+	Wasm32  = newArch("wasm32", "lib32")
 
 	Common = ArchType{
 		Name: COMMON_VARIANT,
@@ -318,6 +319,18 @@ var (
 	LinuxBionic = newOsType("linux_bionic", Host, false, Arm64, X86_64)
 	// Windows the OS for Windows host machines.
 	Windows = newOsType("windows", Host, true, X86, X86_64)
+	// Wasi is the OS for WebAssembly modules that run against the WebAssembly System Interface,
+	// e.g. in a sandboxed runtime on the device. Like Windows it is cross-compiled on the host
+	// and has to be explicitly enabled by modules.
+	Wasi = newOsType("wasi", Host, true, Wasm32)
+	// LinuxEmbedded is the OS for embedded Linux systems whose libc and libraries come from a
+	// cc_cross_sysroot module instead of from the tree. It is cross-compiled on the host, has to
+	// be explicitly enabled by modules and is limited to the directories of
+	// cc/config.CrossSysrootAllowedProjects.
+	LinuxEmbedded = newOsType("linux_embedded", Host, true, Arm64, X86_64)
+	// Qnx is the OS for QNX Neutrino systems, e.g. for automotive tooling. Like LinuxEmbedded it is
+	// built against a cc_cross_sysroot module.
+	Qnx = newOsType("qnx", Host, true, Arm64, X86_64)
 	// Android is the OS for target devices that run all of Android, including the Linux kernel
 	// and the Bionic libc runtime.
 	Android = newOsType("android", Device, false, Arm, Arm64, Riscv64, X86, X86_64)
@@ -1102,12 +1115,31 @@ func getChildPropertyStruct(ctx ArchVariantContext,
 		return reflect.Value{}, false
 	}
 
+	if r, ok := ctx.(*archPropertyBranchContext); ok {
+		r.selectBranch(userFriendlyField)
+	}
 	return child, true
 }
 
+// archPropertyBranchContext wraps the context of the os and arch mutators to record the arch and
+// target property branches, e.g. "arch.arm" or "target.android_arm64", that are selected for a
+// variant. The branches that no variant of a module selects are reported as dead.
+type archPropertyBranchContext struct {
+	BottomUpMutatorContext
+	module *ModuleBase
+}
+
+func (ctx *archPropertyBranchContext) selectBranch(branch string) {
+	props := &ctx.module.commonProperties
+	if !InList(branch, props.SelectedArchPropertyBranches) {
+		props.SelectedArchPropertyBranches = append(props.SelectedArchPropertyBranches, branch)
+	}
+}
+
 // Squash the appropriate OS-specific property structs into the matching top level property structs
 // based on the CompileOS value that was annotated on the variant.
-func (m *ModuleBase) setOSProperties(ctx BottomUpMutatorContext) {
+func (m *ModuleBase) setOSProperties(mctx BottomUpMutatorContext) {
+	ctx := &archPropertyBranchContext{mctx, m}
 	os := m.commonProperties.CompileOS
 
 	for i := range m.archProperties {
@@ -1388,7 +1420,7 @@ func getArchProperties(ctx BaseMutatorContext, archProperties interface{}, arch
 
 		if os == Linux {
 			field := "Glibc_" + archType.Name
-			userFriendlyField := "target.glibc_" + "_" + archType.Name
+			userFriendlyField := "target.glibc_" + archType.Name
 			if osArchProperties, ok := getChildPropertyStruct(ctx, targetProp, field, userFriendlyField); ok {
 				result = append(result, osArchProperties)
 			}
@@ -1396,7 +1428,7 @@ func getArchProperties(ctx BaseMutatorContext, archProperties interface{}, arch
 
 		if os == LinuxMusl {
 			field := "Musl_" + archType.Name
-			userFriendlyField := "target.musl_" + "_" + archType.Name
+			userFriendlyField := "target.musl_" + archType.Name
 			if osArchProperties, ok := getChildPropertyStruct(ctx, targetProp, field, userFriendlyField); ok {
 				result = append(result, osArchProperties)
 			}
@@ -1443,7 +1475,8 @@ func getArchProperties(ctx BaseMutatorContext, archProperties interface{}, arch
 
 // Squash the appropriate arch-specific property structs into the matching top level property
 // structs based on the CompileTarget value that was annotated on the variant.
-func (m *ModuleBase) setArchProperties(ctx BottomUpMutatorContext) {
+func (m *ModuleBase) setArchProperties(mctx BottomUpMutatorContext) {
+	ctx := &archPropertyBranchContext{mctx, m}
 	arch := m.Arch()
 	os := m.Os()
 
@@ -1602,6 +1635,19 @@ func decodeTargetProductVariables(config *config) (map[OsType][]Target, error) {
 		}
 	}
 
+	// An optional WebAssembly target.
+	if String(variables.WasiArch) != "" {
+		addTarget(targetConfig{os: Wasi, archName: *variables.WasiArch, nativeBridgeEnabled: NativeBridgeDisabled})
+	}
+
+	// Optional targets that are built against cc_cross_sysroot modules.
+	if String(variables.LinuxEmbeddedArch) != "" {
+		addTarget(targetConfig{os: LinuxEmbedded, archName: *variables.LinuxEmbeddedArch, nativeBridgeEnabled: NativeBridgeDisabled})
+	}
+	if String(variables.QnxArch) != "" {
+		addTarget(targetConfig{os: Qnx, archName: *variables.QnxArch, nativeBridgeEnabled: NativeBridgeDisabled})
+	}
+
 	// Optional device targets
 	if variables.DeviceArch != nil && *variables.DeviceArch != "" {
 		// The primary device target.
@@ -575,6 +575,47 @@ func TestArchMutatorNativeBridge(t *testing.T) {
 	}
 }
 
+func TestArchMutatorWasi(t *testing.T) {
+	bp := `
+		// This module is only enabled for the host and the device.
+		module {
+			name: "foo",
+			host_supported: true,
+		}
+
+		// This module is also enabled for WebAssembly.
+		module {
+			name: "bar",
+			host_supported: true,
+			target: {
+				wasi: {
+					enabled: true,
+				},
+			},
+		}
+	`
+
+	result := GroupFixturePreparers(
+		prepareForArchTest,
+		FixtureModifyConfig(ModifyTestConfigForWasi),
+		FixtureWithRootAndroidBp(bp),
+	).RunTest(t)
+
+	enabled := func(name, variant string) bool {
+		return InList(variant, result.ModuleVariantsForTests(name)) &&
+			result.ModuleForTests(name, variant).Module().Enabled()
+	}
+
+	AssertBoolEquals(t, "foo wasi_wasm32 enabled", false, enabled("foo", "wasi_wasm32"))
+	AssertBoolEquals(t, "bar wasi_wasm32 enabled", true, enabled("bar", "wasi_wasm32"))
+	AssertBoolEquals(t, "bar android enabled", true, enabled("bar", "android_arm64_armv8-a"))
+
+	bar := result.ModuleForTests("bar", "wasi_wasm32").Module()
+	AssertStringEquals(t, "bar os", "wasi", bar.Target().Os.String())
+	AssertStringEquals(t, "bar arch", "wasm32", bar.Target().Arch.ArchType.String())
+	AssertStringEquals(t, "bar os class", "host", bar.Target().Os.Class.String())
+}
+
 type testArchPropertiesModule struct {
 	ModuleBase
 	properties struct {
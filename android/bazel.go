@@ -53,6 +53,34 @@ type BazelConversionStatus struct {
 
 	// MissingBp2buildDep stores the module names of direct dependency that were not found
 	MissingDeps []string `blueprint:"mutated"`
+
+	// UnconvertedReason stores the reason that bp2build didn't convert the module, if it didn't.
+	UnconvertedReason UnconvertedReason `blueprint:"mutated"`
+}
+
+// UnconvertedReasonType is a reason that bp2build didn't convert a module.
+type UnconvertedReasonType string
+
+const (
+	// The module type has no bp2build converter, or its converter doesn't support the module.
+	UnconvertedReasonTypeUnsupported UnconvertedReasonType = "unsupported_module_type"
+	// The module is in the moduleDoNotConvert list of the bp2build allowlist.
+	UnconvertedReasonDenylisted UnconvertedReasonType = "denylisted"
+	// The module sets bazel_module: { bp2build_available: false }.
+	UnconvertedReasonOptedOut UnconvertedReasonType = "opted_out"
+	// Neither the module, its module type nor its directory is in the bp2build allowlist.
+	UnconvertedReasonNotAllowlisted UnconvertedReasonType = "not_allowlisted"
+	// A property of the module can't be converted.
+	UnconvertedReasonUnsupportedProperty UnconvertedReasonType = "unsupported_property"
+	// The converter of the module type created no targets for the module without saying why.
+	UnconvertedReasonNoTargets UnconvertedReasonType = "no_targets"
+)
+
+// UnconvertedReason is the reason that bp2build didn't convert a module, with details like the
+// name of the unsupported property.
+type UnconvertedReason struct {
+	Type   UnconvertedReasonType `json:",omitempty"`
+	Detail string                `json:",omitempty"`
 }
 
 type bazelModuleProperties struct {
@@ -71,6 +99,14 @@ type bazelModuleProperties struct {
 	// To defer the default setting for the directory, do not set the value.
 	Bp2build_available *bool
 
+	// If true, mixed builds build this module with Bazel, even if it isn't in the mixed builds
+	// allowlists of the build mode. If false, mixed builds never build it with Bazel. The module
+	// still has to be converted to Bazel, with bp2build or a handcrafted label, and its module type
+	// has to support mixed builds.
+	//
+	// To defer to the allowlists of the build mode, do not set the value.
+	Mixed_build_enabled *bool
+
 	// CanConvertToBazel is set via InitBazelModule to indicate that a module type can be converted to
 	// Bazel with Bp2build.
 	CanConvertToBazel bool `blueprint:"mutated"`
@@ -117,6 +153,7 @@ type Bazelable interface {
 	GetBazelLabel(ctx BazelConversionPathContext, module blueprint.Module) string
 	ShouldConvertWithBp2build(ctx BazelConversionContext) bool
 	shouldConvertWithBp2build(ctx bazelOtherModuleContext, module blueprint.Module) bool
+	bp2buildUnconvertedReason(ctx bazelOtherModuleContext, module blueprint.Module) UnconvertedReasonType
 	ConvertWithBp2build(ctx TopDownMutatorContext)
 
 	// namespacedVariableProps is a map from a soong config variable namespace
@@ -361,11 +398,40 @@ func MixedBuildsEnabled(ctx BaseModuleContext) bool {
 		ctx.Arch().ArchType != Riscv64 && // TODO(b/262192655) Riscv64 toolchains are not currently supported.
 		module.Enabled() &&
 		convertedToBazel(ctx, module) &&
-		ctx.Config().BazelContext.IsModuleNameAllowed(module.Name(), withinApex)
+		mixedBuildAllowed(ctx, module, withinApex)
 	ctx.Config().LogMixedBuild(ctx, mixedBuildEnabled)
 	return mixedBuildEnabled
 }
 
+// mixedBuildAllowed returns whether the module opted into mixed builds with
+// bazel_module: { mixed_build_enabled: true }, or, if it didn't set the property, whether the
+// allowlists of the build mode allow it. The denylist, e.g. BAZEL_DISABLED_MODULES, overrides the
+// property.
+func mixedBuildAllowed(ctx BaseModuleContext, module Module, withinApex bool) bool {
+	if ctx.Config().BazelContext.IsModuleNameDisabled(module.Name()) {
+		return false
+	}
+	if b, ok := module.(Bazelable); ok {
+		if enabled := b.bazelProps().Bazel_module.Mixed_build_enabled; enabled != nil {
+			return *enabled
+		}
+	}
+	return ctx.Config().BazelContext.IsModuleNameAllowed(module.Name(), withinApex)
+}
+
+// checkMixedBuildProperties reports an error if a module whose module type can't be built by Bazel
+// in mixed builds opts into mixed builds.
+func checkMixedBuildProperties(ctx BaseModuleContext, module Module) {
+	b, ok := module.(Bazelable)
+	if !ok || !proptools.Bool(b.bazelProps().Bazel_module.Mixed_build_enabled) {
+		return
+	}
+	if _, ok := module.(MixedBuildBuildable); !ok {
+		ctx.PropertyErrorf("bazel_module.mixed_build_enabled",
+			"module type %q does not support mixed builds", ctx.ModuleType())
+	}
+}
+
 // ConvertedToBazel returns whether this module has been converted (with bp2build or manually) to Bazel.
 func convertedToBazel(ctx BazelConversionContext, module blueprint.Module) bool {
 	b, ok := module.(Bazelable)
@@ -389,15 +455,23 @@ type bazelOtherModuleContext interface {
 }
 
 func (b *BazelModuleBase) shouldConvertWithBp2build(ctx bazelOtherModuleContext, module blueprint.Module) bool {
+	return b.bp2buildUnconvertedReason(ctx, module) == ""
+}
+
+// bp2buildUnconvertedReason returns the reason that the module shouldn't be converted with
+// bp2build, or "" if it should.
+func (b *BazelModuleBase) bp2buildUnconvertedReason(ctx bazelOtherModuleContext, module blueprint.Module) UnconvertedReasonType {
 	if !b.bazelProps().Bazel_module.CanConvertToBazel {
-		return false
+		return UnconvertedReasonTypeUnsupported
 	}
 
 	// In api_bp2build mode, all soong modules that can provide API contributions should be converted
 	// This is irrespective of its presence/absence in bp2build allowlists
 	if ctx.Config().BuildMode == ApiBp2build {
-		_, providesApis := module.(ApiProvider)
-		return providesApis
+		if _, providesApis := module.(ApiProvider); !providesApis {
+			return UnconvertedReasonTypeUnsupported
+		}
+		return ""
 	}
 
 	propValue := b.bazelProperties.Bazel_module.Bp2build_available
@@ -407,7 +481,7 @@ func (b *BazelModuleBase) shouldConvertWithBp2build(ctx bazelOtherModuleContext,
 	// trigger this conditional because unit tests run under the "." package path
 	isTestModule := packagePath == Bp2BuildTopLevel && proptools.BoolDefault(propValue, false)
 	if isTestModule {
-		return true
+		return ""
 	}
 
 	moduleName := module.Name()
@@ -417,14 +491,14 @@ func (b *BazelModuleBase) shouldConvertWithBp2build(ctx bazelOtherModuleContext,
 	allowlistConvert := moduleNameAllowed || moduleTypeAllowed
 	if moduleNameAllowed && moduleTypeAllowed {
 		ctx.ModuleErrorf("A module cannot be in moduleAlwaysConvert and also be in moduleTypeAlwaysConvert")
-		return false
+		return UnconvertedReasonNotAllowlisted
 	}
 
 	if allowlist.moduleDoNotConvert[moduleName] {
 		if moduleNameAllowed {
 			ctx.ModuleErrorf("a module cannot be in moduleDoNotConvert and also be in moduleAlwaysConvert")
 		}
-		return false
+		return UnconvertedReasonDenylisted
 	}
 
 	// This is a tristate value: true, false, or unset.
@@ -433,15 +507,24 @@ func (b *BazelModuleBase) shouldConvertWithBp2build(ctx bazelOtherModuleContext,
 			ctx.ModuleErrorf("A module cannot be in a directory marked Bp2BuildDefaultTrue"+
 				" or Bp2BuildDefaultTrueRecursively and also be in moduleAlwaysConvert. Directory: '%s'"+
 				" Module: '%s'", directoryPath, moduleName)
-			return false
+			return UnconvertedReasonNotAllowlisted
 		}
 
 		// Allow modules to explicitly opt-out.
-		return proptools.BoolDefault(propValue, true)
+		if !proptools.BoolDefault(propValue, true) {
+			return UnconvertedReasonOptedOut
+		}
+		return ""
 	}
 
 	// Allow modules to explicitly opt-in.
-	return proptools.BoolDefault(propValue, allowlistConvert)
+	if !proptools.BoolDefault(propValue, allowlistConvert) {
+		if propValue != nil {
+			return UnconvertedReasonOptedOut
+		}
+		return UnconvertedReasonNotAllowlisted
+	}
+	return ""
 }
 
 // bp2buildDefaultTrueRecursively checks that the package contains a prefix from the
@@ -494,7 +577,12 @@ func registerBp2buildConversionMutator(ctx RegisterMutatorsContext) {
 
 func convertWithBp2build(ctx TopDownMutatorContext) {
 	bModule, ok := ctx.Module().(Bazelable)
-	if !ok || !bModule.shouldConvertWithBp2build(ctx, ctx.Module()) {
+	if !ok {
+		ctx.MarkBp2buildUnconvertible(UnconvertedReasonTypeUnsupported, "")
+		return
+	}
+	if reason := bModule.bp2buildUnconvertedReason(ctx, ctx.Module()); reason != "" {
+		ctx.MarkBp2buildUnconvertible(reason, "")
 		return
 	}
 
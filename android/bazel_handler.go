@@ -16,6 +16,8 @@ package android
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"os/exec"
@@ -207,6 +209,10 @@ type BazelContext interface {
 	// (for example, that it is MixedBuildBuildable).
 	IsModuleNameAllowed(moduleName string, withinApex bool) bool
 
+	// Returns true if the module with the given name is in the denylist of mixed builds, which
+	// takes precedence over the mixed_build_enabled property of the module.
+	IsModuleNameDisabled(moduleName string) bool
+
 	IsModuleDclaAllowed(moduleName string) bool
 
 	// Returns the bazel output base (the root directory for all bazel intermediate outputs).
@@ -252,6 +258,12 @@ type mixedBuildBazelContext struct {
 	// Depsets which should be used for Bazel's build statements.
 	depsets []bazel.AqueryDepset
 
+	// The hash of the inputs of the last cquery, and whether the outputs of the last cquery and
+	// aquery were read from the query cache.
+	cqueryInputsHash string
+	cqueryCached     bool
+	aqueryCached     bool
+
 	// Per-module allowlist/denylist functionality to control whether analysis of
 	// modules are handled by Bazel. For modules which do not have a Bazel definition
 	// (or do not sufficiently support bazel handling via MixedBuildBuildable),
@@ -349,6 +361,10 @@ func (m MockBazelContext) IsModuleNameAllowed(_ string, _ bool) bool {
 	return true
 }
 
+func (m MockBazelContext) IsModuleNameDisabled(_ string) bool {
+	return false
+}
+
 func (m MockBazelContext) IsModuleDclaAllowed(_ string) bool {
 	return true
 }
@@ -495,6 +511,10 @@ func (n noopBazelContext) IsModuleNameAllowed(_ string, _ bool) bool {
 	return false
 }
 
+func (n noopBazelContext) IsModuleNameDisabled(_ string) bool {
+	return false
+}
+
 func (n noopBazelContext) IsModuleDclaAllowed(_ string) bool {
 	return false
 }
@@ -629,7 +649,7 @@ func (p *bazelPaths) BazelMetricsDir() string {
 }
 
 func (context *mixedBuildBazelContext) IsModuleNameAllowed(moduleName string, withinApex bool) bool {
-	if context.bazelDisabledModules[moduleName] {
+	if context.IsModuleNameDisabled(moduleName) {
 		return false
 	}
 	if context.bazelEnabledModules[moduleName] {
@@ -642,6 +662,10 @@ func (context *mixedBuildBazelContext) IsModuleNameAllowed(moduleName string, wi
 	return context.modulesDefaultToBazel
 }
 
+func (context *mixedBuildBazelContext) IsModuleNameDisabled(moduleName string) bool {
+	return context.bazelDisabledModules[moduleName]
+}
+
 func (context *mixedBuildBazelContext) IsModuleDclaAllowed(moduleName string) bool {
 	return context.bazelDclaEnabledModules[moduleName]
 }
@@ -669,6 +693,8 @@ type mockBazelRunner struct {
 	tokens     map[*exec.Cmd]bazelCommand
 	commands   []bazelCommand
 	extraFlags []string
+	// The commands that were issued, in order.
+	issuedCommands []bazelCommand
 }
 
 func (r *mockBazelRunner) createBazelCommand(_ Config, _ *bazelPaths, _ bazel.RunName,
@@ -685,6 +711,7 @@ func (r *mockBazelRunner) createBazelCommand(_ Config, _ *bazelPaths, _ bazel.Ru
 
 func (r *mockBazelRunner) issueBazelCommand(bazelCmd *exec.Cmd, _ *metrics.EventHandler) (string, string, error) {
 	if command, ok := r.tokens[bazelCmd]; ok {
+		r.issuedCommands = append(r.issuedCommands, command)
 		return r.bazelCommandResults[command], "", nil
 	}
 	return "", "", nil
@@ -1130,8 +1157,11 @@ func (context *mixedBuildBazelContext) InvokeBazel(config Config, ctx invokeBaze
 	if err := context.runAquery(config, ctx); err != nil {
 		return err
 	}
-	if err := context.generateBazelSymlinks(config, ctx); err != nil {
-		return err
+	// The symlinks were already generated by the run that cached the query outputs.
+	if !context.cqueryCached || !context.aqueryCached {
+		if err := context.generateBazelSymlinks(config, ctx); err != nil {
+			return err
+		}
 	}
 
 	// Clear requests.
@@ -1171,10 +1201,30 @@ func (context *mixedBuildBazelContext) runCquery(config Config, ctx invokeBazelC
 	}
 
 	cqueryCommandWithFlag := context.createBazelCommand(config, context.paths, bazel.CqueryBuildRootRunName, cqueryCmd, extraFlags...)
-	cqueryOutput, cqueryErrorMessage, cqueryErr := context.issueBazelCommand(cqueryCommandWithFlag, eventHandler)
-	if cqueryErr != nil {
-		return cqueryErr
+	inputsHash := cqueryInputsHash(config, context.paths, cqueryCommandWithFlag.Args,
+		context.mainBzlFileContents(), context.mainBuildFileContents(), context.cqueryStarlarkFileContents())
+	cacheKey, err := bazelQueryCacheKey(config, inputsHash, cqueryCommandWithFlag.Args)
+	if err != nil {
+		return err
 	}
+	context.cqueryInputsHash = inputsHash
+	cqueryOutput, resumed := resumedCqueryOutput(config, inputsHash)
+	context.cqueryCached = false
+	if !resumed {
+		cqueryOutput, context.cqueryCached = readBazelQueryCache(config, context.paths, "cquery", cacheKey)
+	}
+	var cqueryErrorMessage string
+	if !resumed && !context.cqueryCached {
+		var cqueryErr error
+		cqueryOutput, cqueryErrorMessage, cqueryErr = context.issueBazelCommand(cqueryCommandWithFlag, eventHandler)
+		if cqueryErr != nil {
+			return cqueryErr
+		}
+		if err := writeBazelQueryCache(context.paths, "cquery", cacheKey, cqueryOutput); err != nil {
+			return err
+		}
+	}
+	recordCqueryOutput(config, inputsHash, cqueryOutput)
 	cqueryCommandPrint := fmt.Sprintf("cquery command line:\n  %s \n\n\n", printableCqueryCommand(cqueryCommandWithFlag))
 	if err := os.WriteFile(filepath.Join(soongInjectionPath, "cquery.out"), []byte(cqueryCommandPrint+cqueryOutput), 0666); err != nil {
 		return err
@@ -1197,6 +1247,33 @@ func (context *mixedBuildBazelContext) runCquery(config Config, ctx invokeBazelC
 	return nil
 }
 
+// cqueryInputsHash returns a hash of the inputs of the cquery of mixed builds: its command line,
+// the files generated for it, the BUILD files generated by bp2build, and the BUILD files of the
+// source tree with the directories their globs can match.
+func cqueryInputsHash(config Config, paths *bazelPaths, args []string, contents ...[]byte) string {
+	h := sha256.New()
+	for _, arg := range args {
+		fmt.Fprintf(h, "%s\n", arg)
+	}
+	for _, c := range contents {
+		fmt.Fprintf(h, "%d\n", len(c))
+		h.Write(c)
+	}
+	bazelBuildList := absolutePath(filepath.Join(filepath.Dir(config.moduleListFile), "bazel.list"))
+	if data, err := os.ReadFile(bazelBuildList); err == nil {
+		for _, file := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if info, err := os.Stat(absolutePath(file)); err == nil {
+				fmt.Fprintf(h, "%s %d %d\n", file, info.Size(), info.ModTime().UnixNano())
+			} else {
+				fmt.Fprintf(h, "%s missing\n", file)
+			}
+			hashBazelPackageDirs(h, filepath.Dir(file))
+		}
+	}
+	hashBp2buildFiles(h, absolutePath(paths.bp2buildDir()))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func writeFileBytesIfChanged(path string, contents []byte, perm os.FileMode) error {
 	oldContents, err := os.ReadFile(path)
 	if err != nil || !bytes.Equal(contents, oldContents) {
@@ -1233,11 +1310,22 @@ func (context *mixedBuildBazelContext) runAquery(config Config, ctx invokeBazelC
 			extraFlags = append(extraFlags, "--instrumentation_filter="+strings.Join(paths, ","))
 		}
 	}
-	aqueryOutput, _, err := context.issueBazelCommand(context.createBazelCommand(config, context.paths, bazel.AqueryBuildRootRunName, aqueryCmd,
-		extraFlags...), eventHandler)
+	aqueryCommand := context.createBazelCommand(config, context.paths, bazel.AqueryBuildRootRunName, aqueryCmd, extraFlags...)
+	cacheKey, err := bazelQueryCacheKey(config, context.cqueryInputsHash, aqueryCommand.Args)
 	if err != nil {
 		return err
 	}
+	var aqueryOutput string
+	aqueryOutput, context.aqueryCached = readBazelQueryCache(config, context.paths, "aquery", cacheKey)
+	if !context.aqueryCached {
+		aqueryOutput, _, err = context.issueBazelCommand(aqueryCommand, eventHandler)
+		if err != nil {
+			return err
+		}
+		if err := writeBazelQueryCache(context.paths, "aquery", cacheKey, aqueryOutput); err != nil {
+			return err
+		}
+	}
 	context.buildStatements, context.depsets, err = bazel.AqueryBuildStatements([]byte(aqueryOutput), eventHandler)
 	return err
 }
@@ -0,0 +1,139 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// The outputs of the Bazel cquery and aquery of mixed builds are cached in
+// $OUT/soong/bazel/query_cache across soong_build runs. A cached output is only used by a run with
+// the same key: the same soong_build binary, product configuration and environment, the same query
+// command line and the same inputs of the cquery, i.e. the files generated for it, the BUILD files
+// generated by bp2build and the BUILD files of the source tree. The directories of the packages of
+// the source tree are part of the inputs too, as a new source file can be matched by a glob of a
+// BUILD file. When both queries are cached, Bazel isn't invoked at all.
+//
+// soong_build --force_bazel_requery ignores the cache, e.g. when the Bazel output base was cleaned.
+
+// queryCacheDir returns the directory of the cached query outputs.
+func (p *bazelPaths) queryCacheDir() string {
+	return filepath.Join(p.intermediatesDir(), "query_cache")
+}
+
+// bazelQueryCacheKey returns the key of the output of a query with the given command line args, run
+// for the cquery inputs with the hash inputsHash.
+func bazelQueryCacheKey(config Config, inputsHash string, args []string) (string, error) {
+	configKey, err := analysisCheckpointKeyFor(config)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", configKey, inputsHash)
+	for _, arg := range args {
+		fmt.Fprintf(h, "%s\n", arg)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// bp2buildDir returns the directory of the BUILD files generated by bp2build, which are planted in
+// the Bazel workspace.
+func (p *bazelPaths) bp2buildDir() string {
+	return filepath.Join(p.soongOutDir, "bp2build")
+}
+
+// hashBp2buildFiles writes the paths, sizes and modification times of the files generated by
+// bp2build to h.
+func hashBp2buildFiles(h io.Writer, dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			fmt.Fprintf(h, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		}
+		return nil
+	})
+}
+
+// hashBazelPackageDirs writes the modification times of the directories of the Bazel package in
+// dir to h. Adding or removing a file changes the modification time of its directory, which
+// covers the files matched by the globs of the package. The subdirectories that have their own
+// BUILD file are other packages, and are hashed with them.
+func hashBazelPackageDirs(h io.Writer, dir string) {
+	root := absolutePath(dir)
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != root && isBazelPackage(path) {
+			return filepath.SkipDir
+		}
+		if info, err := d.Info(); err == nil {
+			fmt.Fprintf(h, "%s/ %d\n", path, info.ModTime().UnixNano())
+		}
+		return nil
+	})
+}
+
+// isBazelPackage returns true if the directory has a BUILD file.
+func isBazelPackage(dir string) bool {
+	for _, name := range []string{"BUILD.bazel", "BUILD"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// readBazelQueryCache returns the cached output of the query if it was cached with key.
+func readBazelQueryCache(config Config, paths *bazelPaths, query, key string) (string, bool) {
+	if config.ForceBazelRequery() {
+		return "", false
+	}
+	dir := absolutePath(paths.queryCacheDir())
+	cachedKey, err := os.ReadFile(filepath.Join(dir, query+".key"))
+	if err != nil || string(cachedKey) != key {
+		return "", false
+	}
+	output, err := os.ReadFile(filepath.Join(dir, query+".out"))
+	if err != nil {
+		return "", false
+	}
+	return string(output), true
+}
+
+// writeBazelQueryCache caches the output of the query with key. The key is removed before the
+// output is written and written last, so that an interrupted write leaves no valid entry.
+func writeBazelQueryCache(paths *bazelPaths, query, key, output string) error {
+	dir := absolutePath(paths.queryCacheDir())
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	keyFile := filepath.Join(dir, query+".key")
+	if err := os.Remove(keyFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, query+".out"), []byte(output), 0666); err != nil {
+		return err
+	}
+	return os.WriteFile(keyFile, []byte(key), 0666)
+}
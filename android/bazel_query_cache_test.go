@@ -0,0 +1,135 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"android/soong/bazel/cquery"
+)
+
+func TestInvokeBazelCachesQueries(t *testing.T) {
+	cfg := configKey{arch: "arm64_armv8-a", osType: Android}
+	results := map[bazelCommand]string{
+		bazelCommand{command: "cquery", expression: "deps(@soong_injection//mixed_builds:buildroot, 2)"}: strings.Join([]string{
+			`@//foo:foo|arm64_armv8-a|android>>out/foo/foo.txt`,
+			`@//foo:bar|arm64_armv8-a|android>>out/foo/bar.txt`,
+		}, "\n"),
+	}
+	firstContext, _ := testBazelContext(t, results)
+
+	invoke := func(config Config, labels ...string) *mixedBuildBazelContext {
+		t.Helper()
+		bazelContext := &mixedBuildBazelContext{
+			bazelRunner: &mockBazelRunner{bazelCommandResults: results},
+			paths:       firstContext.paths,
+		}
+		for _, label := range labels {
+			bazelContext.QueueBazelRequest(label, cquery.GetOutputFiles, cfg)
+		}
+		if err := bazelContext.InvokeBazel(config, &testInvokeBazelContext{}); err != nil {
+			t.Fatalf("Did not expect error invoking Bazel, but got %s", err)
+		}
+		return bazelContext
+	}
+	issuedCommands := func(bazelContext *mixedBuildBazelContext) []string {
+		var commands []string
+		for _, command := range bazelContext.bazelRunner.(*mockBazelRunner).issuedCommands {
+			commands = append(commands, command.command)
+		}
+		return commands
+	}
+
+	bazelContext := invoke(testConfig, "@//foo:foo")
+	AssertDeepEquals(t, "issued commands of the first run", []string{"cquery", "aquery", "build"}, issuedCommands(bazelContext))
+
+	// The outputs of the queries are cached, Bazel isn't invoked.
+	bazelContext = invoke(testConfig, "@//foo:foo")
+	AssertDeepEquals(t, "issued commands of the cached run", []string(nil), issuedCommands(bazelContext))
+	verifyCqueryResult(t, bazelContext, "@//foo:foo", cfg, "out/foo/foo.txt")
+
+	// A change to the requests changes the inputs of the queries.
+	bazelContext = invoke(testConfig, "@//foo:foo", "@//foo:bar")
+	AssertDeepEquals(t, "issued commands after a change", []string{"cquery", "aquery", "build"}, issuedCommands(bazelContext))
+	verifyCqueryResult(t, bazelContext, "@//foo:bar", cfg, "out/foo/bar.txt")
+
+	// A change to the BUILD files generated by bp2build changes the inputs of the queries.
+	bp2buildDir := firstContext.paths.bp2buildDir()
+	if err := os.MkdirAll(filepath.Join(bp2buildDir, "foo"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bp2buildDir, "foo", "BUILD.bazel"), []byte("# generated"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	bazelContext = invoke(testConfig, "@//foo:foo", "@//foo:bar")
+	AssertDeepEquals(t, "issued commands after bp2build", []string{"cquery", "aquery", "build"}, issuedCommands(bazelContext))
+
+	// --force_bazel_requery ignores the cache.
+	forceRequeryConfig := TestConfig("out", nil, "", nil)
+	forceRequeryConfig.forceBazelRequery = true
+	bazelContext = invoke(forceRequeryConfig, "@//foo:foo", "@//foo:bar")
+	AssertDeepEquals(t, "issued commands with --force_bazel_requery", []string{"cquery", "aquery", "build"}, issuedCommands(bazelContext))
+}
+
+func TestHashBazelPackageDirs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(path string) {
+		t.Helper()
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("pkg/BUILD.bazel")
+	writeFile("pkg/src/a.cpp")
+	writeFile("pkg/sub/BUILD")
+	writeFile("pkg/sub/b.cpp")
+
+	hash := func() string {
+		t.Helper()
+		// Reset the modification times, so that only the files added by the test change them.
+		past := time.Unix(0, 0)
+		for _, d := range []string{"pkg", "pkg/src", "pkg/sub"} {
+			if err := os.Chtimes(filepath.Join(dir, d), past, past); err != nil {
+				t.Fatal(err)
+			}
+		}
+		h := sha256.New()
+		hashBazelPackageDirs(h, filepath.Join(dir, "pkg"))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	before := hash()
+	writeFile("pkg/sub/c.cpp")
+	AssertStringEquals(t, "hash after a change to a subpackage", before, hash())
+
+	// Reset the times before the new file, which sets the time of its directory.
+	before = hash()
+	writeFile("pkg/src/d.cpp")
+	h := sha256.New()
+	hashBazelPackageDirs(h, filepath.Join(dir, "pkg"))
+	if hex.EncodeToString(h.Sum(nil)) == before {
+		t.Errorf("expected a new file in the package to change the hash")
+	}
+}
@@ -436,3 +436,139 @@ func TestShouldKeepExistingBuildFileForDir(t *testing.T) {
 		}
 	}
 }
+
+type mixedBuildTestModule struct {
+	ModuleBase
+	BazelModuleBase
+
+	mixedBuildEnabled bool
+}
+
+func (m *mixedBuildTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	m.mixedBuildEnabled = MixedBuildsEnabled(ctx)
+}
+
+func (m *mixedBuildTestModule) ConvertWithBp2build(ctx TopDownMutatorContext) {}
+
+// mixedBuildTestModuleFactory creates a module type that doesn't support mixed builds.
+func mixedBuildTestModuleFactory() Module {
+	module := &mixedBuildTestModule{}
+	InitAndroidModule(module)
+	InitBazelModule(module)
+	return module
+}
+
+type mixedBuildBuildableTestModule struct {
+	mixedBuildTestModule
+}
+
+func (m *mixedBuildBuildableTestModule) IsMixedBuildSupported(ctx BaseModuleContext) bool {
+	return true
+}
+
+func (m *mixedBuildBuildableTestModule) QueueBazelCall(ctx BaseModuleContext) {}
+
+func (m *mixedBuildBuildableTestModule) ProcessBazelQueryResponse(ctx ModuleContext) {}
+
+func mixedBuildBuildableTestModuleFactory() Module {
+	module := &mixedBuildBuildableTestModule{}
+	InitAndroidModule(module)
+	InitBazelModule(module)
+	return module
+}
+
+var prepareForMixedBuildOptInTest = FixtureRegisterWithContext(func(ctx RegistrationContext) {
+	ctx.RegisterModuleType("test_bazel_module", mixedBuildTestModuleFactory)
+	ctx.RegisterModuleType("test_mixed_build_module", mixedBuildBuildableTestModuleFactory)
+})
+
+func TestMixedBuildOptIn(t *testing.T) {
+	result := GroupFixturePreparers(
+		prepareForMixedBuildOptInTest,
+		FixtureWithRootAndroidBp(`
+			test_mixed_build_module {
+				name: "opted_in",
+				bazel_module: { label: "//foo:opted_in", mixed_build_enabled: true },
+			}
+			test_mixed_build_module {
+				name: "opted_out",
+				bazel_module: { label: "//foo:opted_out", mixed_build_enabled: false },
+			}
+			test_mixed_build_module {
+				name: "allowlist",
+				bazel_module: { label: "//foo:allowlist" },
+			}
+			test_mixed_build_module {
+				name: "not_converted",
+				bazel_module: { mixed_build_enabled: true },
+			}
+		`),
+	).RunTest(t)
+
+	// The BazelContext of the tests doesn't allow any module, only the opted in module that is
+	// converted to Bazel is built with Bazel.
+	for name, expected := range map[string]bool{
+		"opted_in":      true,
+		"opted_out":     false,
+		"allowlist":     false,
+		"not_converted": false,
+	} {
+		module := result.ModuleForTests(name, "").Module().(*mixedBuildBuildableTestModule)
+		AssertBoolEquals(t, name+" mixed build enabled", expected, module.mixedBuildEnabled)
+	}
+}
+
+func TestMixedBuildOptInDenylist(t *testing.T) {
+	result := GroupFixturePreparers(
+		prepareForMixedBuildOptInTest,
+		FixtureModifyConfig(func(config Config) {
+			config.BazelContext = &mixedBuildBazelContext{
+				bazelDisabledModules: map[string]bool{"opted_in_disabled": true, "allowlist_disabled": true},
+				bazelEnabledModules:  map[string]bool{"allowlist": true, "allowlist_disabled": true},
+			}
+		}),
+		FixtureWithRootAndroidBp(`
+			test_mixed_build_module {
+				name: "opted_in",
+				bazel_module: { label: "//foo:opted_in", mixed_build_enabled: true },
+			}
+			test_mixed_build_module {
+				name: "opted_in_disabled",
+				bazel_module: { label: "//foo:opted_in_disabled", mixed_build_enabled: true },
+			}
+			test_mixed_build_module {
+				name: "allowlist",
+				bazel_module: { label: "//foo:allowlist" },
+			}
+			test_mixed_build_module {
+				name: "allowlist_disabled",
+				bazel_module: { label: "//foo:allowlist_disabled" },
+			}
+		`),
+	).RunTest(t)
+
+	// The denylist wins over both the mixed_build_enabled property and the allowlist.
+	for name, expected := range map[string]bool{
+		"opted_in":           true,
+		"opted_in_disabled":  false,
+		"allowlist":          true,
+		"allowlist_disabled": false,
+	} {
+		module := result.ModuleForTests(name, "").Module().(*mixedBuildBuildableTestModule)
+		AssertBoolEquals(t, name+" mixed build enabled", expected, module.mixedBuildEnabled)
+	}
+}
+
+func TestMixedBuildOptInUnsupportedModuleType(t *testing.T) {
+	GroupFixturePreparers(
+		prepareForMixedBuildOptInTest,
+		FixtureWithRootAndroidBp(`
+			test_bazel_module {
+				name: "foo",
+				bazel_module: { label: "//foo:foo", mixed_build_enabled: true },
+			}
+		`),
+	).ExtendWithErrorHandler(FixtureExpectsAtLeastOneErrorMatchingPattern(
+		`module "foo": bazel_module.mixed_build_enabled: module type "test_bazel_module" does not support mixed builds`,
+	)).RunTest(t)
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/blueprint/pathtools"
+)
+
+// This file caches the parsing that Soong does for the Android.bp files across soong_build runs.
+// Expanding the fragments that an Android.bp file includes parses and prints every fragment, which
+// is the same for every run until the Android.bp file or one of its fragments changes. The cache
+// keeps the expanded contents of each Android.bp file that includes fragments under
+// out/soong/bp_cache, in a file named after the hash of the path and the contents of the Android.bp
+// file, with the hashes of the fragments that were included. An entry is used when the hashes of
+// the fragments still match, and rewritten otherwise.
+
+// bpCacheEntry is the cached expansion of an Android.bp file.
+type bpCacheEntry struct {
+	// The hashes of the contents of the fragments that the Android.bp file included, by path.
+	Fragments map[string]string
+	// The contents of the Android.bp file with the fragments expanded.
+	Expanded []byte
+}
+
+// bpCache is a cache of expanded Android.bp files in a directory.
+type bpCache struct {
+	dir string
+
+	mutex sync.Mutex
+	// The entries that were read or written by this run, which PruneCache keeps.
+	used map[string]bool
+}
+
+// bpCacheKey returns the key of the entry for the Android.bp file name with the contents data.
+// Fragments are relative to the Android.bp file, so its path is part of the key.
+func bpCacheKey(name string, data []byte) string {
+	h := sha256.New()
+	io.WriteString(h, name)
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashBpFile returns the hash of the contents of a file.
+func hashBpFile(fs pathtools.FileSystem, name string) (string, error) {
+	r, err := fs.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *bpCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *bpCache) markUsed(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.used[key] = true
+}
+
+// get returns the cached expansion for key and the fragments it included, if the fragments are
+// unchanged.
+func (c *bpCache) get(fs pathtools.FileSystem, key string) ([]byte, []string, bool) {
+	data, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return nil, nil, false
+	}
+	var entry bpCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, nil, false
+	}
+	fragments := make([]string, 0, len(entry.Fragments))
+	for fragment, hash := range entry.Fragments {
+		if h, err := hashBpFile(fs, fragment); err != nil || h != hash {
+			return nil, nil, false
+		}
+		fragments = append(fragments, fragment)
+	}
+	sort.Strings(fragments)
+	c.markUsed(key)
+	return entry.Expanded, fragments, true
+}
+
+// put writes the expansion for key and the hashes of the fragments it included. Failing to write
+// the cache only makes the next run expand the file again, so errors are ignored.
+func (c *bpCache) put(fs pathtools.FileSystem, key string, expanded []byte, fragments []string) {
+	entry := bpCacheEntry{Fragments: make(map[string]string), Expanded: expanded}
+	for _, fragment := range fragments {
+		hash, err := hashBpFile(fs, fragment)
+		if err != nil {
+			return
+		}
+		entry.Fragments[fragment] = hash
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0777); err != nil {
+		return
+	}
+	// Write the entry to a temporary file first, so concurrent or interrupted runs never read a
+	// partial entry.
+	f, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.entryPath(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return
+	}
+	c.markUsed(key)
+}
+
+// prune removes the entries that weren't used by this run, which belong to previous contents of
+// the Android.bp files.
+func (c *bpCache) prune() error {
+	entries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, entry := range entries {
+		name := entry.Name()
+		if c.used[strings.TrimSuffix(name, ".json")] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
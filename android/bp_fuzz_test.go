@@ -0,0 +1,74 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+)
+
+var prepareForBpFuzzTest = GroupFixturePreparers(
+	PrepareForTestWithArchMutator,
+	PrepareForTestWithAllowMissingDependencies,
+	PrepareForTestWithDefaults,
+	PrepareForTestWithSoongConfigModuleBuildComponents,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("test", propsTestModuleFactory)
+		ctx.RegisterModuleType("test_defaults", propsTestModuleDefaultsFactory)
+	}),
+)
+
+// FuzzAndroidBp checks that parsing an Android.bp file and unpacking the properties of its modules,
+// including arch variant, defaults and soong config variable properties, reports errors instead of
+// panicking. The seed corpus in testdata/fuzz/FuzzAndroidBp is run as part of the normal tests,
+// run the fuzzer with:
+//
+//	go test ./android -run '^$' -fuzz FuzzAndroidBp
+func FuzzAndroidBp(f *testing.F) {
+	f.Add(`
+		test_defaults {
+			name: "foo_defaults",
+			a: "a",
+			c: ["c"],
+			arch: {
+				arm64: {
+					a: "arm64",
+				},
+			},
+		}
+
+		test {
+			name: "foo",
+			defaults: ["foo_defaults"],
+			b: true,
+			d: 42,
+			nested: {
+				e: "e",
+			},
+			slice_of_struct: [
+				{
+					g: "g",
+					h: true,
+					i: ["i"],
+				},
+			],
+		}
+	`)
+
+	f.Fuzz(func(t *testing.T, bp string) {
+		prepareForBpFuzzTest.
+			ExtendWithErrorHandler(FixtureIgnoreErrors).
+			RunTestWithBp(t, bp)
+	})
+}
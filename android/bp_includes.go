@@ -0,0 +1,283 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/blueprint/parser"
+	"github.com/google/blueprint/pathtools"
+)
+
+// This file expands the build fragments that Android.bp files include. A line
+//
+//	//#include "common_tests.bpi"
+//
+// at the top level of an Android.bp file is replaced with the variables and modules of the
+// fragment file, whose path is relative to the directory of the including file. Fragments are
+// Blueprint files that can include other fragments, so large generated Android.bp files can share
+// lists of tests or arch matrices instead of repeating them. The directive is a comment, so tools
+// that only format or parse a single Android.bp file keep working.
+//
+// The contents of a fragment replace the directive on a single line, so the lines of the including
+// file keep their numbers, and errors in the variables and modules of the fragment point to the
+// include line. Syntax errors in fragments, missing fragments and include cycles are reported with
+// the position in the fragment and the chain of includes that led to it. A fragment is only
+// included once into an Android.bp file, even if several of its fragments include it, as
+// Blueprint doesn't allow a variable to be assigned twice.
+
+// bpIncludeDirectiveRe matches the include directive. Lines that start with //#include but don't
+// match it are reported as malformed.
+var bpIncludeDirectiveRe = regexp.MustCompile(`^//#include\s+"([^"]+)"$`)
+
+const bpIncludeDirectivePrefix = "//#include"
+
+// BpIncludeFs is a pathtools.FileSystem that expands the include directives of the Android.bp files
+// that it opens.
+type BpIncludeFs struct {
+	pathtools.FileSystem
+
+	mutex sync.Mutex
+	// The fragments that were included, which soong_build depends on.
+	fragments map[string]bool
+
+	// The cache of expanded Android.bp files, or nil if they are expanded on every run.
+	cache *bpCache
+}
+
+func NewBpIncludeFs(fs pathtools.FileSystem) *BpIncludeFs {
+	return &BpIncludeFs{FileSystem: fs, fragments: make(map[string]bool)}
+}
+
+// SetCacheDir caches the expanded contents of the Android.bp files that include fragments in dir,
+// so the fragments of unchanged files aren't parsed again by later runs.
+func (fs *BpIncludeFs) SetCacheDir(dir string) {
+	fs.cache = &bpCache{dir: dir, used: make(map[string]bool)}
+}
+
+// PruneCache removes the cached expansions that weren't used since SetCacheDir. It must only be
+// called after all the Android.bp files were opened.
+func (fs *BpIncludeFs) PruneCache() error {
+	if fs.cache == nil {
+		return nil
+	}
+	return fs.cache.prune()
+}
+
+// Fragments returns the paths of the fragments that the Android.bp files opened so far included.
+func (fs *BpIncludeFs) Fragments() []string {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fragments := make([]string, 0, len(fs.fragments))
+	for fragment := range fs.fragments {
+		fragments = append(fragments, fragment)
+	}
+	sort.Strings(fragments)
+	return fragments
+}
+
+type bpIncludeReader struct {
+	*bytes.Reader
+}
+
+func (bpIncludeReader) Close() error { return nil }
+
+// Open opens the file, with the fragments of an Android.bp file expanded.
+func (fs *BpIncludeFs) Open(name string) (pathtools.ReaderAtSeekerCloser, error) {
+	r, err := fs.FileSystem.Open(name)
+	if err != nil || filepath.Base(name) != "Android.bp" {
+		return r, err
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(data, []byte(bpIncludeDirectivePrefix)) {
+		return bpIncludeReader{bytes.NewReader(data)}, nil
+	}
+
+	var key string
+	if fs.cache != nil {
+		key = bpCacheKey(name, data)
+		if expanded, fragments, ok := fs.cache.get(fs.FileSystem, key); ok {
+			fs.addFragments(fragments)
+			return bpIncludeReader{bytes.NewReader(expanded)}, nil
+		}
+	}
+
+	e := &bpIncluder{fs: fs.FileSystem, included: make(map[string]bool)}
+	expanded, err := e.expand(name, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	fragments := make([]string, 0, len(e.included))
+	for fragment := range e.included {
+		fragments = append(fragments, fragment)
+	}
+	fs.addFragments(fragments)
+	if fs.cache != nil {
+		fs.cache.put(fs.FileSystem, key, expanded, fragments)
+	}
+	return bpIncludeReader{bytes.NewReader(expanded)}, nil
+}
+
+func (fs *BpIncludeFs) addFragments(fragments []string) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	for _, fragment := range fragments {
+		fs.fragments[fragment] = true
+	}
+}
+
+// bpInclude is an include directive in the chain of includes that led to a fragment.
+type bpInclude struct {
+	file string
+	line int
+}
+
+func (i bpInclude) String() string {
+	return fmt.Sprintf("%s:%d", i.file, i.line)
+}
+
+// includedFrom describes the chain of includes, innermost first, for the errors.
+func includedFrom(chain []bpInclude) string {
+	var s strings.Builder
+	for i := len(chain) - 1; i >= 0; i-- {
+		fmt.Fprintf(&s, "\n    included from %s", chain[i])
+	}
+	return s.String()
+}
+
+// bpIncluder expands the include directives of an Android.bp file.
+type bpIncluder struct {
+	fs pathtools.FileSystem
+	// The fragments that were included into the Android.bp file.
+	included map[string]bool
+}
+
+// expand returns the contents of file, with the directives replaced with the contents of the
+// fragments on a single line. chain is the chain of includes that led to file.
+func (e *bpIncluder) expand(file string, data []byte, chain []bpInclude) ([]byte, error) {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		if !strings.HasPrefix(trimmed, bpIncludeDirectivePrefix) {
+			out.WriteString(text)
+			out.WriteByte('\n')
+			continue
+		}
+		include := bpInclude{file, line}
+		m := bpIncludeDirectiveRe.FindStringSubmatch(trimmed)
+		if m == nil {
+			return nil, fmt.Errorf("%s: malformed include directive, expected %s \"<path>\"%s",
+				include, bpIncludeDirectivePrefix, includedFrom(chain))
+		}
+		fragment, err := e.include(include, filepath.Join(filepath.Dir(file), m[1]), chain)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(fragment)
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %s%s", file, err, includedFrom(chain))
+	}
+	return out.Bytes(), nil
+}
+
+// include returns the contents of the fragment that include includes on a single line, or nothing
+// if the fragment was already included.
+func (e *bpIncluder) include(include bpInclude, fragment string, chain []bpInclude) ([]byte, error) {
+	if fragment == ".." || strings.HasPrefix(fragment, "../") {
+		return nil, fmt.Errorf("%s: can't include %q, it's outside of the source tree%s",
+			include, fragment, includedFrom(chain))
+	}
+	chain = append(append([]bpInclude(nil), chain...), include)
+	for _, c := range chain {
+		if c.file == fragment {
+			return nil, fmt.Errorf("%s: include cycle: %s", include, describeIncludeCycle(chain, fragment))
+		}
+	}
+	if e.included[fragment] {
+		return nil, nil
+	}
+	e.included[fragment] = true
+
+	r, err := e.fs.Open(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to include %q: %s%s", include, fragment, err, includedFrom(chain[:len(chain)-1]))
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to include %q: %s%s", include, fragment, err, includedFrom(chain[:len(chain)-1]))
+	}
+	expanded, err := e.expand(fragment, data, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	// The nested fragments are on the lines of their directives, so the syntax errors of the
+	// fragment have the positions in its file.
+	file, errs := parser.Parse(fragment, bytes.NewReader(expanded), parser.NewScope(nil))
+	if len(errs) > 0 {
+		var msgs []string
+		for _, err := range errs {
+			msgs = append(msgs, err.Error())
+		}
+		return nil, fmt.Errorf("%s%s", strings.Join(msgs, "\n"), includedFrom(chain))
+	}
+	// Without comments, the printed fragment has no line comments and its strings have no line
+	// breaks, so it can be joined into a single line.
+	file.Comments = nil
+	printed, err := parser.Print(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s%s", fragment, err, includedFrom(chain))
+	}
+	return bytes.TrimSpace(bytes.ReplaceAll(printed, []byte("\n"), []byte(" "))), nil
+}
+
+// describeIncludeCycle describes the includes of the chain from the first one in the cycle back to
+// fragment, e.g. "a/x.bpi:2 includes a/y.bpi, a/y.bpi:1 includes a/x.bpi".
+func describeIncludeCycle(chain []bpInclude, fragment string) string {
+	start := 0
+	for i, c := range chain {
+		if c.file == fragment {
+			start = i
+			break
+		}
+	}
+	var hops []string
+	for i := start; i < len(chain); i++ {
+		next := fragment
+		if i+1 < len(chain) {
+			next = chain[i+1].file
+		}
+		hops = append(hops, fmt.Sprintf("%s includes %s", chain[i], next))
+	}
+	return strings.Join(hops, ", ")
+}
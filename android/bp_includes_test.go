@@ -0,0 +1,257 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/blueprint/parser"
+	"github.com/google/blueprint/pathtools"
+)
+
+func readBpIncludes(t *testing.T, fs *BpIncludeFs, name string) (string, error) {
+	t.Helper()
+	r, err := fs.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), nil
+}
+
+func TestBpIncludes(t *testing.T) {
+	androidBp := `//#include "common.bpi"
+//#include "tests/matrix.bpi"
+
+cc_test {
+    name: "foo",
+    srcs: common_srcs,
+}
+`
+	fs := NewBpIncludeFs(pathtools.MockFs(map[string][]byte{
+		"dir/Android.bp": []byte(androidBp),
+		"dir/common.bpi": []byte(`// Sources of every test.
+common_srcs = [
+    "a.cpp", // The main source.
+    "b  c.cpp",
+]
+`),
+		"dir/tests/matrix.bpi": []byte(`//#include "../common.bpi"
+
+cc_defaults {
+    name: "matrix",
+    srcs: common_srcs,
+}
+`),
+	}))
+
+	expanded, err := readBpIncludes(t, fs, "dir/Android.bp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertIntEquals(t, "lines", strings.Count(androidBp, "\n"), strings.Count(expanded, "\n"))
+	AssertStringDoesContain(t, "strings keep their spaces", expanded, `"b  c.cpp"`)
+	AssertStringDoesNotContain(t, "comments are dropped", expanded, "The main source")
+
+	file, errs := parser.ParseAndEval("dir/Android.bp", bytes.NewReader([]byte(expanded)), parser.NewScope(nil))
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors parsing:\n%s\nerrors: %v", expanded, errs)
+	}
+	var moduleTypes []string
+	for _, def := range file.Defs {
+		if m, ok := def.(*parser.Module); ok {
+			moduleTypes = append(moduleTypes, m.Type)
+		}
+	}
+	AssertArrayString(t, "modules", []string{"cc_defaults", "cc_test"}, moduleTypes)
+
+	// common.bpi is only included once, and both fragments are dependencies.
+	AssertArrayString(t, "fragments", []string{"dir/common.bpi", "dir/tests/matrix.bpi"}, fs.Fragments())
+}
+
+func TestBpIncludesUnchanged(t *testing.T) {
+	fs := NewBpIncludeFs(pathtools.MockFs(map[string][]byte{
+		"dir/Android.bp": []byte("// No includes.\n"),
+		"dir/foo.bpi":    []byte("//#include \"missing.bpi\"\n"),
+		"dir/Android.mk": []byte("//#include \"missing.bpi\"\n"),
+	}))
+	for _, file := range []string{"dir/Android.bp", "dir/foo.bpi", "dir/Android.mk"} {
+		if _, err := readBpIncludes(t, fs, file); err != nil {
+			t.Errorf("%s: unexpected error %s", file, err)
+		}
+	}
+	AssertIntEquals(t, "fragments", 0, len(fs.Fragments()))
+}
+
+func TestBpIncludesErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		files         map[string]string
+		expectedError string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"dir/Android.bp": "//#include \"a.bpi\"\n",
+				"dir/a.bpi":      "//#include \"b.bpi\"\n",
+				"dir/b.bpi":      "//#include \"a.bpi\"\n",
+			},
+			expectedError: "dir/b.bpi:1: include cycle: dir/a.bpi:1 includes dir/b.bpi, dir/b.bpi:1 includes dir/a.bpi",
+		},
+		{
+			name: "self include",
+			files: map[string]string{
+				"dir/Android.bp": "\n//#include \"Android.bp\"\n",
+			},
+			expectedError: "dir/Android.bp:2: include cycle: dir/Android.bp:2 includes dir/Android.bp",
+		},
+		{
+			name: "syntax error",
+			files: map[string]string{
+				"dir/Android.bp":  "//#include \"a.bpi\"\n",
+				"dir/a.bpi":       "a = [\"a\"]\n//#include \"sub/bad.bpi\"\n",
+				"dir/sub/bad.bpi": "b = [\"b\"]\nc = }\n",
+			},
+			expectedError: "dir/sub/bad.bpi:2:",
+		},
+		{
+			name: "syntax error blame",
+			files: map[string]string{
+				"dir/Android.bp":  "//#include \"a.bpi\"\n",
+				"dir/a.bpi":       "a = [\"a\"]\n//#include \"sub/bad.bpi\"\n",
+				"dir/sub/bad.bpi": "b = [\"b\"]\nc = }\n",
+			},
+			expectedError: "\n    included from dir/a.bpi:2\n    included from dir/Android.bp:1",
+		},
+		{
+			name: "missing fragment",
+			files: map[string]string{
+				"dir/Android.bp": "//#include \"missing.bpi\"\n",
+			},
+			expectedError: `dir/Android.bp:1: failed to include "dir/missing.bpi"`,
+		},
+		{
+			name: "malformed directive",
+			files: map[string]string{
+				"dir/Android.bp": "//#include missing.bpi\n",
+			},
+			expectedError: "dir/Android.bp:1: malformed include directive",
+		},
+		{
+			name: "outside of the source tree",
+			files: map[string]string{
+				"Android.bp": "//#include \"../outside.bpi\"\n",
+			},
+			expectedError: `Android.bp:1: can't include "../outside.bpi", it's outside of the source tree`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := make(map[string][]byte)
+			for name, contents := range tc.files {
+				files[name] = []byte(contents)
+			}
+			name := "dir/Android.bp"
+			if _, ok := tc.files[name]; !ok {
+				name = "Android.bp"
+			}
+			_, err := readBpIncludes(t, NewBpIncludeFs(pathtools.MockFs(files)), name)
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.expectedError)
+			}
+			AssertStringDoesContain(t, "error", err.Error(), tc.expectedError)
+		})
+	}
+}
+
+func TestBpIncludesCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	files := map[string][]byte{
+		"dir/Android.bp": []byte("//#include \"common.bpi\"\n"),
+		"dir/common.bpi": []byte("common_srcs = [\"a.cpp\"]\n"),
+	}
+	open := func() (string, []string) {
+		t.Helper()
+		fs := NewBpIncludeFs(pathtools.MockFs(files))
+		fs.SetCacheDir(cacheDir)
+		expanded, err := readBpIncludes(t, fs, "dir/Android.bp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.PruneCache(); err != nil {
+			t.Fatal(err)
+		}
+		return expanded, fs.Fragments()
+	}
+	entries := func() []string {
+		t.Helper()
+		matches, err := filepath.Glob(filepath.Join(cacheDir, "*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches
+	}
+
+	expanded, fragments := open()
+	AssertStringDoesContain(t, "expanded", expanded, `"a.cpp"`)
+	AssertArrayString(t, "fragments", []string{"dir/common.bpi"}, fragments)
+	AssertIntEquals(t, "entries", 1, len(entries()))
+
+	// An unchanged file is read from the cache instead of being expanded again.
+	entry := entries()[0]
+	data, err := os.ReadFile(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cached bpCacheEntry
+	if err := json.Unmarshal(data, &cached); err != nil {
+		t.Fatal(err)
+	}
+	cached.Expanded = []byte("common_srcs = [\"cached.cpp\"]\n")
+	data, err = json.Marshal(cached)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(entry, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	expanded, fragments = open()
+	AssertStringDoesContain(t, "cached", expanded, `"cached.cpp"`)
+	AssertArrayString(t, "cached fragments", []string{"dir/common.bpi"}, fragments)
+
+	// A change to a fragment invalidates the entry of the Android.bp file.
+	files["dir/common.bpi"] = []byte("common_srcs = [\"b.cpp\"]\n")
+	expanded, _ = open()
+	AssertStringDoesContain(t, "changed fragment", expanded, `"b.cpp"`)
+	AssertIntEquals(t, "entries after changing the fragment", 1, len(entries()))
+
+	// A change to the Android.bp file uses a new entry, and the stale one is pruned.
+	files["dir/Android.bp"] = []byte("//#include \"common.bpi\"\n\n")
+	expanded, _ = open()
+	AssertStringDoesContain(t, "changed Android.bp", expanded, `"b.cpp"`)
+	if e := entries(); len(e) != 1 || e[0] == entry {
+		t.Errorf("expected only a new entry after changing the Android.bp file, got %q", e)
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Build flags, also known as release flags, are declared in JSON files listed in the
+// BuildFlagDeclarations product variable, e.g.:
+//
+//	{
+//	    "flags": [
+//	        {
+//	            "name": "RELEASE_FOO",
+//	            "type": "bool",
+//	            "default": "false",
+//	            "lifecycle": "development",
+//	            "description": "Enables foo."
+//	        }
+//	    ]
+//	}
+//
+// The release configuration sets their values with the BuildFlagValues product variable. The flags
+// are evaluated when the Config is created, and are available to Android.bp files as the Soong
+// config variables of the "build_flags" namespace, to the module implementations with
+// Config.BuildFlag, and to the code of the modules with the java_build_flag_constants and
+// cc_build_flag_constants module types. `m build_flags` writes the state of the flags of the
+// build to $OUT/soong/build_flags.json.
+
+const (
+	// BuildFlagBool flags are "true" or "false".
+	BuildFlagBool = "bool"
+	// BuildFlagString flags are any string.
+	BuildFlagString = "string"
+)
+
+const (
+	// BuildFlagInDevelopment flags can be set by the release configuration.
+	BuildFlagInDevelopment = "development"
+	// BuildFlagLaunched flags have their default value, they can't be set by the release
+	// configuration anymore.
+	BuildFlagLaunched = "launched"
+	// BuildFlagObsolete flags are only kept until their uses are removed. They have their default
+	// value, and can't be set by the release configuration nor generated into code.
+	BuildFlagObsolete = "obsolete"
+)
+
+// BuildFlagsSoongConfigNamespace is the Soong config namespace of the build flags, for use in
+// soong_config_module_type.
+const BuildFlagsSoongConfigNamespace = "build_flags"
+
+// BuildFlagDeclaration is the declaration of a build flag.
+type BuildFlagDeclaration struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Lifecycle   string `json:"lifecycle"`
+	Description string `json:"description,omitempty"`
+}
+
+type buildFlagDeclarations struct {
+	Flags []*BuildFlagDeclaration `json:"flags"`
+}
+
+// BuildFlag is a build flag evaluated for the current build.
+type BuildFlag struct {
+	BuildFlagDeclaration
+
+	// The file that declares the flag.
+	DeclaredIn string `json:"declared_in"`
+
+	// The value of the flag in the current build.
+	Value string `json:"value"`
+
+	// Whether the value is set by the release configuration rather than the default one.
+	Overridden bool `json:"overridden"`
+}
+
+// Bool returns the value of a bool flag.
+func (f *BuildFlag) Bool() bool {
+	return f.Value == "true"
+}
+
+func validBuildFlagValue(flagType, value string) bool {
+	switch flagType {
+	case BuildFlagBool:
+		return value == "true" || value == "false"
+	case BuildFlagString:
+		return true
+	}
+	return false
+}
+
+// readBuildFlagDeclarations reads a build flag declarations file.
+func readBuildFlagDeclarations(file string, data []byte) ([]*BuildFlagDeclaration, error) {
+	var declarations buildFlagDeclarations
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&declarations); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	for _, d := range declarations.Flags {
+		switch {
+		case d.Name == "":
+			return nil, fmt.Errorf("%s: build flag without a name", file)
+		case d.Type != BuildFlagBool && d.Type != BuildFlagString:
+			return nil, fmt.Errorf("%s: build flag %s: type must be %q or %q, not %q",
+				file, d.Name, BuildFlagBool, BuildFlagString, d.Type)
+		case d.Lifecycle != BuildFlagInDevelopment && d.Lifecycle != BuildFlagLaunched && d.Lifecycle != BuildFlagObsolete:
+			return nil, fmt.Errorf("%s: build flag %s: lifecycle must be %q, %q or %q, not %q",
+				file, d.Name, BuildFlagInDevelopment, BuildFlagLaunched, BuildFlagObsolete, d.Lifecycle)
+		case !validBuildFlagValue(d.Type, d.Default):
+			return nil, fmt.Errorf("%s: build flag %s: invalid %s default value %q", file, d.Name, d.Type, d.Default)
+		}
+	}
+	return declarations.Flags, nil
+}
+
+// evaluateBuildFlags evaluates the declared build flags with the values of the release
+// configuration.
+func evaluateBuildFlags(declarations map[string][]*BuildFlagDeclaration, values map[string]string) (map[string]*BuildFlag, error) {
+	flags := make(map[string]*BuildFlag)
+	for _, file := range SortedKeys(declarations) {
+		for _, d := range declarations[file] {
+			if other, exists := flags[d.Name]; exists {
+				return nil, fmt.Errorf("build flag %s is declared in both %s and %s", d.Name, other.DeclaredIn, file)
+			}
+			flags[d.Name] = &BuildFlag{
+				BuildFlagDeclaration: *d,
+				DeclaredIn:           file,
+				Value:                d.Default,
+			}
+		}
+	}
+
+	for _, name := range SortedKeys(values) {
+		value := values[name]
+		flag, ok := flags[name]
+		if !ok {
+			return nil, fmt.Errorf("build flag %s is set by the release configuration but not declared", name)
+		}
+		if !validBuildFlagValue(flag.Type, value) {
+			return nil, fmt.Errorf("build flag %s: invalid %s value %q", name, flag.Type, value)
+		}
+		switch flag.Lifecycle {
+		case BuildFlagLaunched, BuildFlagObsolete:
+			// Setting the default value keeps working while the release configurations are cleaned up.
+			if value != flag.Default {
+				return nil, fmt.Errorf("build flag %s is %s, it can't be set to %q by the release configuration anymore",
+					name, flag.Lifecycle, value)
+			}
+		default:
+			flag.Value = value
+			flag.Overridden = value != flag.Default
+		}
+	}
+	return flags, nil
+}
+
+// loadBuildFlags reads the build flag declaration files and evaluates the build flags of the
+// build. It also makes them available as Soong config variables.
+func (c *config) loadBuildFlags() error {
+	declarations := make(map[string][]*BuildFlagDeclaration)
+	for _, file := range c.productVariables.BuildFlagDeclarations {
+		data, err := os.ReadFile(absolutePath(file))
+		if err != nil {
+			return fmt.Errorf("build flag declarations: %s", err)
+		}
+		if declarations[file], err = readBuildFlagDeclarations(file, data); err != nil {
+			return err
+		}
+	}
+	return c.setBuildFlags(declarations)
+}
+
+func (c *config) setBuildFlags(declarations map[string][]*BuildFlagDeclaration) error {
+	flags, err := evaluateBuildFlags(declarations, c.productVariables.BuildFlagValues)
+	if err != nil {
+		return err
+	}
+	c.buildFlags = flags
+
+	if len(flags) == 0 {
+		return nil
+	}
+	if c.productVariables.VendorVars == nil {
+		c.productVariables.VendorVars = make(map[string]map[string]string)
+	}
+	vars := make(map[string]string, len(flags))
+	for name, flag := range flags {
+		vars[name] = flag.Value
+	}
+	c.productVariables.VendorVars[BuildFlagsSoongConfigNamespace] = vars
+	return nil
+}
+
+// BuildFlag returns a build flag of the build, or false if it isn't declared.
+func (c *config) BuildFlag(name string) (*BuildFlag, bool) {
+	flag, ok := c.buildFlags[name]
+	return flag, ok
+}
+
+// BuildFlags returns the build flags of the build, sorted by name.
+func (c *config) BuildFlags() []*BuildFlag {
+	flags := make([]*BuildFlag, 0, len(c.buildFlags))
+	for _, name := range SortedKeys(c.buildFlags) {
+		flags = append(flags, c.buildFlags[name])
+	}
+	return flags
+}
+
+func init() {
+	RegisterBuildFlagsBuildComponents(InitRegistrationContext)
+}
+
+func RegisterBuildFlagsBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("build_flags", buildFlagsSingletonFactory)
+}
+
+var PrepareForTestWithBuildFlags = FixtureRegisterWithContext(RegisterBuildFlagsBuildComponents)
+
+// FixtureDeclareBuildFlags declares build flags in the test config, as if they were read from the
+// given declarations file, and sets the values of the release configuration.
+func FixtureDeclareBuildFlags(file string, declarations string, values map[string]string) FixturePreparer {
+	return FixtureModifyConfig(func(config Config) {
+		flags, err := readBuildFlagDeclarations(file, []byte(declarations))
+		if err != nil {
+			panic(err)
+		}
+		config.productVariables.BuildFlagValues = values
+		if err := config.setBuildFlags(map[string][]*BuildFlagDeclaration{file: flags}); err != nil {
+			panic(err)
+		}
+	})
+}
+
+func buildFlagsSingletonFactory() Singleton {
+	return &buildFlagsSingleton{}
+}
+
+// buildFlagsSingleton writes the state of the build flags of the build.
+type buildFlagsSingleton struct{}
+
+func (s *buildFlagsSingleton) GenerateBuildActions(ctx SingletonContext) {
+	// soong_build reruns when a declaration file changes.
+	ctx.AddNinjaFileDeps(ctx.Config().productVariables.BuildFlagDeclarations...)
+
+	data, err := json.MarshalIndent(ctx.Config().BuildFlags(), "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	output := PathForOutput(ctx, "build_flags.json")
+	WriteFileRule(ctx, output, string(data))
+	ctx.Phony("build_flags", output)
+}
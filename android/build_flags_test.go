@@ -0,0 +1,148 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+const testBuildFlagDeclarations = `{
+	"flags": [
+		{"name": "RELEASE_FOO", "type": "bool", "default": "false", "lifecycle": "development"},
+		{"name": "RELEASE_BAR", "type": "string", "default": "bar", "lifecycle": "development"},
+		{"name": "RELEASE_LAUNCHED", "type": "bool", "default": "true", "lifecycle": "launched"}
+	]
+}`
+
+func TestBuildFlags(t *testing.T) {
+	bp := `
+		soong_config_module_type {
+			name: "flagged_test",
+			module_type: "test",
+			config_namespace: "build_flags",
+			bool_variables: ["RELEASE_FOO"],
+			properties: ["cflags"],
+		}
+
+		flagged_test {
+			name: "foo",
+			soong_config_variables: {
+				RELEASE_FOO: {
+					cflags: ["-DFOO"],
+				},
+			},
+		}
+	`
+
+	result := GroupFixturePreparers(
+		PrepareForTestWithDefaults,
+		PrepareForTestWithSoongConfigModuleBuildComponents,
+		PrepareForTestWithBuildFlags,
+		prepareForSoongConfigTestModule,
+		FixtureDeclareBuildFlags("build/flags.json", testBuildFlagDeclarations, map[string]string{
+			"RELEASE_FOO":      "true",
+			"RELEASE_LAUNCHED": "true",
+		}),
+		FixtureWithRootAndroidBp(bp),
+	).RunTest(t)
+
+	foo, _ := result.Config.BuildFlag("RELEASE_FOO")
+	AssertBoolEquals(t, "RELEASE_FOO", true, foo.Bool())
+	AssertBoolEquals(t, "RELEASE_FOO overridden", true, foo.Overridden)
+	launched, _ := result.Config.BuildFlag("RELEASE_LAUNCHED")
+	AssertBoolEquals(t, "RELEASE_LAUNCHED overridden", false, launched.Overridden)
+
+	module := result.ModuleForTests("foo", "").Module().(*soongConfigTestModule)
+	AssertDeepEquals(t, "cflags", []string{"-DFOO"}, module.props.Cflags)
+
+	output := result.SingletonForTests("build_flags").Output("build_flags.json")
+	var report []*BuildFlag
+	if err := json.Unmarshal([]byte(ContentFromFileRuleForTests(t, output)), &report); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, flag := range report {
+		names = append(names, fmt.Sprintf("%s=%s", flag.Name, flag.Value))
+	}
+	AssertDeepEquals(t, "report", []string{"RELEASE_BAR=bar", "RELEASE_FOO=true", "RELEASE_LAUNCHED=true"}, names)
+}
+
+func TestBuildFlagErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		declarations map[string]string
+		values       map[string]string
+		err          string
+	}{
+		{
+			name: "invalid type",
+			declarations: map[string]string{
+				"a.json": `{"flags": [{"name": "RELEASE_FOO", "type": "int", "default": "1", "lifecycle": "development"}]}`,
+			},
+			err: `a.json: build flag RELEASE_FOO: type must be "bool" or "string", not "int"`,
+		},
+		{
+			name: "invalid default",
+			declarations: map[string]string{
+				"a.json": `{"flags": [{"name": "RELEASE_FOO", "type": "bool", "default": "yes", "lifecycle": "development"}]}`,
+			},
+			err: `a.json: build flag RELEASE_FOO: invalid bool default value "yes"`,
+		},
+		{
+			name: "declared twice",
+			declarations: map[string]string{
+				"a.json": testBuildFlagDeclarations,
+				"b.json": `{"flags": [{"name": "RELEASE_FOO", "type": "bool", "default": "true", "lifecycle": "development"}]}`,
+			},
+			err: "build flag RELEASE_FOO is declared in both a.json and b.json",
+		},
+		{
+			name:         "undeclared",
+			declarations: map[string]string{"a.json": testBuildFlagDeclarations},
+			values:       map[string]string{"RELEASE_BAZ": "true"},
+			err:          "build flag RELEASE_BAZ is set by the release configuration but not declared",
+		},
+		{
+			name:         "invalid value",
+			declarations: map[string]string{"a.json": testBuildFlagDeclarations},
+			values:       map[string]string{"RELEASE_FOO": "1"},
+			err:          `build flag RELEASE_FOO: invalid bool value "1"`,
+		},
+		{
+			name:         "launched",
+			declarations: map[string]string{"a.json": testBuildFlagDeclarations},
+			values:       map[string]string{"RELEASE_LAUNCHED": "false"},
+			err:          `build flag RELEASE_LAUNCHED is launched, it can't be set to "false" by the release configuration anymore`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			declarations := make(map[string][]*BuildFlagDeclaration)
+			var err error
+			for file, content := range tc.declarations {
+				if declarations[file], err = readBuildFlagDeclarations(file, []byte(content)); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				_, err = evaluateBuildFlags(declarations, tc.values)
+			}
+			AssertErrorMessageEquals(t, "error", tc.err, err)
+		})
+	}
+}
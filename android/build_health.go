@@ -0,0 +1,204 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/google/blueprint"
+)
+
+// The build health report lists, by directory, the modules that are candidates for a cleanup of
+// the tree: modules that nothing depends on, modules that are not converted to Bazel, prebuilts
+// that are shadowed by their source module and modules that are disabled. It is written to
+// $OUT/soong/build_health.json and built with `m build_health`.
+//
+// Soong doesn't know the PRODUCT_PACKAGES of the product, so a module that no other module
+// depends on may still be installed by the product. Unreferenced modules don't install anything
+// either, so nothing but building them explicitly builds them.
+
+func init() {
+	RegisterBuildHealthBuildComponents(InitRegistrationContext)
+}
+
+func RegisterBuildHealthBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("build_health", buildHealthSingletonFactory)
+}
+
+var PrepareForTestWithBuildHealth = FixtureRegisterWithContext(RegisterBuildHealthBuildComponents)
+
+// BuildHealth is the content of build_health.json.
+type BuildHealth struct {
+	// The health of the modules by directory.
+	Directories map[string]*DirectoryBuildHealth `json:"directories"`
+}
+
+// DirectoryBuildHealth is the health of the modules of a directory. The module lists are sorted.
+type DirectoryBuildHealth struct {
+	// The number of modules in the directory.
+	Modules int `json:"modules"`
+
+	// The modules that no other module depends on.
+	Undepended []string `json:"undepended,omitempty"`
+
+	// The modules that no other module depends on and that don't install anything.
+	Unreferenced []string `json:"unreferenced,omitempty"`
+
+	// The modules that are not converted to Bazel, neither by bp2build nor by hand.
+	Unconverted []string `json:"unconverted,omitempty"`
+
+	// The prebuilt modules that are not used because their source module is preferred.
+	ShadowedPrebuilts []string `json:"shadowed_prebuilts,omitempty"`
+
+	// The modules whose variants are all disabled.
+	Disabled []string `json:"disabled,omitempty"`
+}
+
+// moduleHealth is the health of all the variants of a module.
+type moduleHealth struct {
+	name      string
+	dir       string
+	enabled   bool
+	installed bool
+	depended  bool
+	converted bool
+	shadowed  bool
+}
+
+func buildHealthSingletonFactory() Singleton {
+	return &buildHealthSingleton{}
+}
+
+type buildHealthSingleton struct{}
+
+func (s *buildHealthSingleton) GenerateBuildActions(ctx SingletonContext) {
+	modules := make(map[string]*moduleHealth)
+	healthOf := func(module Module) *moduleHealth {
+		dir := ctx.ModuleDir(module)
+		name := ctx.ModuleName(module)
+		key := dir + ":" + name
+		health := modules[key]
+		if health == nil {
+			health = &moduleHealth{name: name, dir: dir}
+			modules[key] = health
+		}
+		return health
+	}
+
+	ctx.VisitAllModules(func(module Module) {
+		health := healthOf(module)
+		if !module.Enabled() {
+			return
+		}
+		health.enabled = true
+		if len(module.FilesToInstall()) > 0 {
+			health.installed = true
+		}
+		if isConvertedToBazel(ctx, module) {
+			health.converted = true
+		}
+		if p := GetEmbeddedPrebuilt(module); p != nil && p.SourceExists() && !p.UsePrebuilt() {
+			health.shadowed = true
+		}
+
+		name := RemoveOptionalPrebuiltPrefix(ctx.ModuleName(module))
+		ctx.VisitDirectDeps(module, func(dep Module) {
+			// The dependencies between the variants of a module, or between a module and its
+			// prebuilt, don't make it used.
+			if RemoveOptionalPrebuiltPrefix(ctx.ModuleName(dep)) == name {
+				return
+			}
+			healthOf(dep).depended = true
+		})
+	})
+
+	report := BuildHealth{Directories: make(map[string]*DirectoryBuildHealth)}
+	for _, health := range modules {
+		dir := report.Directories[health.dir]
+		if dir == nil {
+			dir = &DirectoryBuildHealth{}
+			report.Directories[health.dir] = dir
+		}
+		dir.Modules++
+		if !health.enabled {
+			dir.Disabled = append(dir.Disabled, health.name)
+			continue
+		}
+		if health.shadowed {
+			dir.ShadowedPrebuilts = append(dir.ShadowedPrebuilts, health.name)
+		}
+		if !health.depended {
+			dir.Undepended = append(dir.Undepended, health.name)
+			if !health.installed {
+				dir.Unreferenced = append(dir.Unreferenced, health.name)
+			}
+		}
+		if !health.converted {
+			dir.Unconverted = append(dir.Unconverted, health.name)
+		}
+	}
+	for _, dir := range report.Directories {
+		sort.Strings(dir.Undepended)
+		sort.Strings(dir.Unreferenced)
+		sort.Strings(dir.Unconverted)
+		sort.Strings(dir.ShadowedPrebuilts)
+		sort.Strings(dir.Disabled)
+	}
+
+	jsonStr, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	output := PathForOutput(ctx, "build_health.json")
+	WriteFileRule(ctx, output, string(jsonStr))
+	ctx.Phony("build_health", output)
+}
+
+// isConvertedToBazel returns whether a module is converted to Bazel, either by bp2build or by
+// hand.
+func isConvertedToBazel(ctx SingletonContext, module Module) bool {
+	b, ok := module.(Bazelable)
+	if !ok {
+		return false
+	}
+	return b.HasHandcraftedLabel() || b.shouldConvertWithBp2build(&buildHealthBazelContext{ctx}, module)
+}
+
+// buildHealthBazelContext adapts a SingletonContext to the context used to decide whether a module
+// is converted with bp2build.
+type buildHealthBazelContext struct {
+	ctx SingletonContext
+}
+
+// ModuleErrorf ignores the errors in the bp2build allowlists, bp2build reports them.
+func (c *buildHealthBazelContext) ModuleErrorf(format string, args ...interface{}) {}
+
+func (c *buildHealthBazelContext) Config() Config {
+	return c.ctx.Config()
+}
+
+func (c *buildHealthBazelContext) OtherModuleType(m blueprint.Module) string {
+	return c.ctx.ModuleType(m)
+}
+
+func (c *buildHealthBazelContext) OtherModuleName(m blueprint.Module) string {
+	return c.ctx.ModuleName(m)
+}
+
+func (c *buildHealthBazelContext) OtherModuleDir(m blueprint.Module) string {
+	return c.ctx.ModuleDir(m)
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildHealth(t *testing.T) {
+	result := GroupFixturePreparers(
+		PrepareForTestWithArchMutator,
+		PrepareForTestWithBuildHealth,
+		FixtureRegisterWithContext(registerTestPrebuiltBuildComponents),
+		FixtureAddTextFile("other/Android.bp", `
+			source {
+				name: "qux",
+			}
+		`),
+		FixtureWithRootAndroidBp(`
+			source {
+				name: "foo",
+				deps: [":bar", ":qux"],
+			}
+
+			source {
+				name: "bar",
+			}
+
+			prebuilt {
+				name: "bar",
+				srcs: ["prebuilt_file"],
+			}
+
+			source {
+				name: "baz",
+				enabled: false,
+			}
+		`),
+	).RunTest(t)
+
+	output := result.SingletonForTests("build_health").Output("build_health.json")
+	var health BuildHealth
+	if err := json.Unmarshal([]byte(ContentFromFileRuleForTests(t, output)), &health); err != nil {
+		t.Fatal(err)
+	}
+
+	AssertDeepEquals(t, "build health", map[string]*DirectoryBuildHealth{
+		".": {
+			Modules:           4,
+			Undepended:        []string{"foo", "prebuilt_bar"},
+			Unreferenced:      []string{"foo", "prebuilt_bar"},
+			Unconverted:       []string{"bar", "foo", "prebuilt_bar"},
+			ShadowedPrebuilts: []string{"prebuilt_bar"},
+			Disabled:          []string{"baz"},
+		},
+		"other": {
+			Modules:     1,
+			Unconverted: []string{"qux"},
+		},
+	}, health.Directories)
+}
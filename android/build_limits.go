@@ -0,0 +1,126 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// This file checks that the actions of modules stay within the limits of the OS they run on, so
+// that an action that can't run fails the analysis with the module that defines it, instead of
+// failing in ninja with "Argument list too long" or "File name too long".
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/blueprint"
+)
+
+const (
+	// MAX_ARG_STRLEN of Linux, the maximum length of an argument passed to execve. Ninja runs the
+	// commands with /bin/sh -c, so the whole command is a single argument.
+	defaultMaxCommandLineLength = 128 * 1024
+
+	// NAME_MAX and PATH_MAX of Linux.
+	maxFileNameLength = 255
+	maxPathLength     = 4096
+)
+
+var buildLimitsRuleParamsKey = NewOnceKey("buildLimitsRuleParams")
+
+// packageRuleParamsForConfig returns the params of a rule defined by a PackageContext for the
+// config, or false if the rule isn't defined by a PackageContext of Soong.
+func packageRuleParamsForConfig(config Config, rule blueprint.Rule) (blueprint.RuleParams, bool) {
+	cache := config.Once(buildLimitsRuleParamsKey, func() interface{} {
+		return &sync.Map{}
+	}).(*sync.Map)
+	if params, ok := cache.Load(rule); ok {
+		return params.(blueprint.RuleParams), true
+	}
+	f, ok := packageRuleParams[rule]
+	if !ok {
+		return blueprint.RuleParams{}, false
+	}
+	params, err := f(config)
+	if err != nil {
+		return blueprint.RuleParams{}, false
+	}
+	cache.Store(rule, params)
+	return params, true
+}
+
+// checkBuildLimits returns an error if the command or the response file of an action, whose rule
+// has the given params, or the paths of its outputs exceed the limits of the config. References
+// to variables other than $in, $out and the arguments of the action are not expanded, so the
+// lengths are lower bounds.
+func checkBuildLimits(config Config, os OsType, rule blueprint.RuleParams, params BuildParams) error {
+	var outputs []string
+	for _, output := range []WritablePath{params.Output, params.ImplicitOutput} {
+		if output != nil {
+			outputs = append(outputs, output.String())
+		}
+	}
+	for _, output := range params.Outputs {
+		outputs = append(outputs, output.String())
+	}
+	for _, output := range params.ImplicitOutputs {
+		outputs = append(outputs, output.String())
+	}
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	for _, output := range outputs {
+		if len(output) > maxPathLength {
+			return fmt.Errorf("output %s is %d bytes long, more than the limit of %d bytes",
+				output, len(output), maxPathLength)
+		}
+		for _, name := range strings.Split(output, "/") {
+			if len(name) > maxFileNameLength {
+				return fmt.Errorf("output %s has a file name of %d bytes, more than the limit of %d bytes",
+					output, len(name), maxFileNameLength)
+			}
+		}
+		if limit := config.WindowsMaxPathLength(); limit > 0 && os == Windows && len(output) > limit {
+			return fmt.Errorf("output %s is %d characters long, more than the limit of %d characters of Windows",
+				output, len(output), limit)
+		}
+	}
+
+	vars := make(map[string]string)
+	for k, v := range params.Args {
+		vars[k] = v
+	}
+	inputs := params.Inputs.Strings()
+	if params.Input != nil {
+		inputs = append(inputs, params.Input.String())
+	}
+	vars["in"] = strings.Join(inputs, " ")
+	vars["in_newline"] = strings.Join(inputs, "\n")
+	vars["out"] = strings.Join(outputs, " ")
+
+	if limit := config.MaxCommandLineLength(); limit > 0 {
+		if n := len(expandNinjaVariables(rule.Command, vars)); n > limit {
+			return fmt.Errorf("the command of the %s action that writes %s is %d bytes long, more than the limit "+
+				"of %d bytes, pass the long arguments in a response file instead",
+				params.Rule.String(), outputs[0], n, limit)
+		}
+	}
+	if limit := config.MaxRspfileLength(); limit > 0 && rule.RspfileContent != "" {
+		if n := len(expandNinjaVariables(rule.RspfileContent, vars)); n > limit {
+			return fmt.Errorf("the response file of the %s action that writes %s is %d bytes long, more than "+
+				"the limit of %d bytes", params.Rule.String(), outputs[0], n, limit)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/blueprint"
+)
+
+type buildLimitsTestModule struct {
+	ModuleBase
+	properties struct {
+		Args []string
+		Out  *string
+	}
+}
+
+func buildLimitsTestModuleFactory() Module {
+	m := &buildLimitsTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidModule(m)
+	return m
+}
+
+func (m *buildLimitsTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	rule := NewRuleBuilder(pctx, ctx)
+	rule.Command().Text("echo").Flags(m.properties.Args).
+		FlagWithOutput("> ", PathForModuleOut(ctx, String(m.properties.Out)))
+	rule.Build("echo", "echo")
+}
+
+func runBuildLimitsTest(t *testing.T, bp string, errorPattern string) {
+	t.Helper()
+	GroupFixturePreparers(
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("build_limits_test", buildLimitsTestModuleFactory)
+		}),
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.Max_command_line_length = intPtr(256)
+		}),
+		FixtureWithRootAndroidBp(bp),
+	).ExtendWithErrorHandler(FixtureExpectsAtLeastOneErrorMatchingPattern(errorPattern)).RunTest(t)
+}
+
+func TestBuildLimitsCommandLine(t *testing.T) {
+	runBuildLimitsTest(t, `
+		build_limits_test {
+			name: "foo",
+			args: ["`+strings.Repeat("a", 300)+`"],
+			out: "foo.txt",
+		}
+	`, `module "foo": the command of the .* action that writes .*/foo.txt is \d+ bytes long, more than the limit of 256 bytes`)
+}
+
+func TestBuildLimitsFileName(t *testing.T) {
+	runBuildLimitsTest(t, `
+		build_limits_test {
+			name: "foo",
+			out: "`+strings.Repeat("a", 256)+`",
+		}
+	`, `module "foo": output .* has a file name of 256 bytes, more than the limit of 255 bytes`)
+}
+
+func TestCheckBuildLimits(t *testing.T) {
+	config := TestConfig(t.TempDir(), nil, "", nil)
+	config.productVariables.Windows_max_path_length = intPtr(40)
+	config.productVariables.Max_rspfile_length = intPtr(40)
+	ctx := PathContextForTesting(config)
+
+	params := BuildParams{
+		Rule:   Cp,
+		Inputs: PathsForTesting("a.txt", "b.txt"),
+		Output: PathForOutput(ctx, "some", "long", "path", "to", "the", "output.txt"),
+	}
+	rule := blueprint.RuleParams{Command: "cp $in $out"}
+	if err := checkBuildLimits(config, Linux, rule, params); err != nil {
+		t.Errorf("unexpected error for Linux: %s", err)
+	}
+	err := checkBuildLimits(config, Windows, rule, params)
+	if err == nil || !strings.Contains(err.Error(), "more than the limit of 40 characters of Windows") {
+		t.Errorf("expected an error for the Windows path limit, got %v", err)
+	}
+
+	params.Output = PathForOutput(ctx, "output.txt")
+	rule.RspfileContent = "$in " + strings.Repeat("x", 40)
+	err = checkBuildLimits(config, Linux, rule, params)
+	if err == nil || !strings.Contains(err.Error(), "the response file of the") {
+		t.Errorf("expected an error for the response file limit, got %v", err)
+	}
+}
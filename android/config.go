@@ -84,13 +84,31 @@ type CmdArgs struct {
 	OutDir      string
 	SoongOutDir string
 
-	SymlinkForestMarker string
-	Bp2buildMarker      string
-	BazelQueryViewDir   string
-	BazelApiBp2buildDir string
-	ModuleGraphFile     string
-	ModuleActionsFile   string
-	DocFile             string
+	SymlinkForestMarker  string
+	Bp2buildMarker       string
+	Bp2buildDiff         bool
+	BazelQueryViewDir    string
+	BazelQueryViewFilter string
+	BazelApiBp2buildDir  string
+	ModuleGraphFile      string
+	ModuleActionsFile    string
+	DocFile              string
+	ImpactOf             string
+	ImpactFile           string
+	Explain              string
+	ExplainFile          string
+	Query                string
+	QueryFile            string
+	ListModules          bool
+	ListModulesFilter    string
+	ListModulesFile      string
+	ServeDocs            string
+	MutatorPipelineFile  string
+	ConfigDumpFile       string
+	ModuleDepsFile       string
+	DependencyCyclesFile string
+	LogModules           string
+	NinjaWeightFile      string
 
 	MultitreeBuild bool
 
@@ -99,7 +117,8 @@ type CmdArgs struct {
 	BazelModeStaging         bool
 	BazelForceEnabledModules string
 
-	UseBazelProxy bool
+	UseBazelProxy     bool
+	ForceBazelRequery bool
 
 	BuildFromTextStub bool
 }
@@ -129,6 +148,39 @@ const (
 	// Generate a documentation file for module type definitions and exit.
 	GenerateDocFile
 
+	// Write the modules and actions that are affected by a change to a source file and exit.
+	GenerateImpact
+
+	// Write the mutator pipeline and the variants and dependencies created by each mutator and exit.
+	GenerateMutatorPipeline
+
+	// Write the action that generates an output file and exit.
+	GenerateExplain
+
+	// Write the fully resolved configuration of the product and exit.
+	GenerateConfigDump
+
+	// Write the direct dependents of each module variant and exit.
+	GenerateModuleDeps
+
+	// Resolve the dependencies of the modules, explain the dependency cycles between them and exit.
+	ExplainDependencyCycles
+
+	// Write the modules that match a query of the module graph and exit.
+	GenerateQuery
+
+	// Write the names, types and Android.bp files of the modules, before generating their build
+	// actions, and exit.
+	GenerateModuleList
+
+	// Serve the documentation of the module types and an index of the modules over HTTP until
+	// killed.
+	ServeDocs
+
+	// Print a diff of the BUILD files that bp2build would generate against the previously
+	// generated ones, without writing them, and exit.
+	Bp2buildDiff
+
 	// Use bazel during analysis of many allowlisted build modules. The allowlist
 	// is considered a "developer mode" allowlist, as some modules may be
 	// allowlisted on an experimental basis.
@@ -239,9 +291,29 @@ type config struct {
 	// runs standalone.
 	katiEnabled bool
 
-	captureBuild      bool // true for tests, saves build parameters for each module
+	captureBuild      bool // true for tests, GenerateImpact and GenerateExplain, saves build parameters for each module
 	ignoreEnvironment bool // true for tests, returns empty from all Getenv calls
 
+	// true for tests, GenerateModuleDeps and ExplainDependencyCycles, records the mutator and tag of
+	// each dependency, see dependencyOrigin.
+	recordDependencyOrigins bool
+
+	// The mutators of the pipeline and the variants and dependencies they created, recorded in
+	// GenerateMutatorPipeline mode.
+	mutatorPipeline *mutatorPipelineStats
+
+	// The structured debug logs of the modules matching --log_modules, see OpenModuleLog.
+	moduleLog *moduleLog
+
+	// The build flags of the build by name, see loadBuildFlags.
+	buildFlags map[string]*BuildFlag
+
+	// The peak memory of actions in a previous build by output, see loadActionMemoryProfile.
+	actionMemoryProfile map[string]ActionMemoryRecord
+
+	// The weight list to write for the actions of the build, see WriteNinjaWeightFile.
+	ninjaWeightFile string
+
 	fs         pathtools.FileSystem
 	mockBpList string
 
@@ -282,6 +354,9 @@ type config struct {
 	// unix sockets, instead of spawning Bazel as a subprocess.
 	UseBazelProxy bool
 
+	// If true, the Bazel queries of mixed builds are run even if their outputs are cached.
+	forceBazelRequery bool
+
 	// If buildFromTextStub is true then the Java API stubs are
 	// built from the signature text files, not the source Java files.
 	buildFromTextStub bool
@@ -481,13 +556,15 @@ func NewConfig(cmdArgs CmdArgs, availableEnv map[string]string) (Config, error)
 		multilibConflicts: make(map[ArchType]bool),
 
 		moduleListFile:            cmdArgs.ModuleListFile,
+		ninjaWeightFile:           cmdArgs.NinjaWeightFile,
 		fs:                        pathtools.NewOsFs(absSrcDir),
 		mixedBuildDisabledModules: make(map[string]struct{}),
 		mixedBuildEnabledModules:  make(map[string]struct{}),
 		bazelForceEnabledModules:  make(map[string]struct{}),
 
-		MultitreeBuild: cmdArgs.MultitreeBuild,
-		UseBazelProxy:  cmdArgs.UseBazelProxy,
+		MultitreeBuild:    cmdArgs.MultitreeBuild,
+		UseBazelProxy:     cmdArgs.UseBazelProxy,
+		forceBazelRequery: cmdArgs.ForceBazelRequery,
 
 		buildFromTextStub: cmdArgs.BuildFromTextStub,
 	}
@@ -518,6 +595,18 @@ func NewConfig(cmdArgs CmdArgs, availableEnv map[string]string) (Config, error)
 		return Config{}, err
 	}
 
+	if err := config.loadBuildFlags(); err != nil {
+		return Config{}, err
+	}
+
+	if err := config.validateSourceDateEpoch(); err != nil {
+		return Config{}, err
+	}
+
+	if err := config.loadActionMemoryProfile(); err != nil {
+		return Config{}, err
+	}
+
 	KatiEnabledMarkerFile := filepath.Join(cmdArgs.SoongOutDir, ".soong.kati_enabled")
 	if _, err := os.Stat(absolutePath(KatiEnabledMarkerFile)); err == nil {
 		config.katiEnabled = true
@@ -595,10 +684,32 @@ func NewConfig(cmdArgs CmdArgs, availableEnv map[string]string) (Config, error)
 	setBuildMode(cmdArgs.BazelApiBp2buildDir, ApiBp2build)
 	setBuildMode(cmdArgs.ModuleGraphFile, GenerateModuleGraph)
 	setBuildMode(cmdArgs.DocFile, GenerateDocFile)
+	setBuildMode(cmdArgs.ImpactOf, GenerateImpact)
+	setBuildMode(cmdArgs.MutatorPipelineFile, GenerateMutatorPipeline)
+	setBuildMode(cmdArgs.Explain, GenerateExplain)
+	setBuildMode(cmdArgs.ConfigDumpFile, GenerateConfigDump)
+	setBuildMode(cmdArgs.ModuleDepsFile, GenerateModuleDeps)
+	setBuildMode(cmdArgs.DependencyCyclesFile, ExplainDependencyCycles)
+	setBuildMode(cmdArgs.Query, GenerateQuery)
+	setBuildMode(cmdArgs.ServeDocs, ServeDocs)
+	setBazelMode(cmdArgs.Bp2buildDiff, "--bp2build_diff", Bp2buildDiff)
+	setBazelMode(cmdArgs.ListModules, "--list_modules", GenerateModuleList)
 	setBazelMode(cmdArgs.BazelModeDev, "--bazel-mode-dev", BazelDevMode)
 	setBazelMode(cmdArgs.BazelMode, "--bazel-mode", BazelProdMode)
 	setBazelMode(cmdArgs.BazelModeStaging, "--bazel-mode-staging", BazelStagingMode)
 
+	// The impact of a change is computed from the inputs and outputs of the build actions, and
+	// outputs are explained by the actions that generate them.
+	config.captureBuild = config.BuildMode == GenerateImpact || config.BuildMode == GenerateExplain
+
+	// The origins of dependencies are only needed to describe them.
+	config.recordDependencyOrigins = config.BuildMode == GenerateModuleDeps ||
+		config.BuildMode == ExplainDependencyCycles
+
+	if config.BuildMode == GenerateMutatorPipeline {
+		config.mutatorPipeline = newMutatorPipelineStats()
+	}
+
 	for _, module := range strings.Split(cmdArgs.BazelForceEnabledModules, ",") {
 		config.bazelForceEnabledModules[module] = struct{}{}
 	}
@@ -647,7 +758,7 @@ func (c *config) IsMixedBuildsEnabled() bool {
 		if c.productVariables.DeviceArch != nil && *c.productVariables.DeviceArch == "riscv64" {
 			return false
 		}
-		if c.IsEnvTrue("GLOBAL_THINLTO") {
+		if c.GlobalThinLto() {
 			return false
 		}
 		if len(c.productVariables.SanitizeHost) > 0 {
@@ -669,6 +780,12 @@ func (c *config) IsMixedBuildsEnabled() bool {
 	return globalMixedBuildsSupport && bazelModeEnabled
 }
 
+// ForceBazelRequery returns true if the Bazel queries of mixed builds must be run even if their
+// outputs are cached.
+func (c *config) ForceBazelRequery() bool {
+	return c.forceBazelRequery
+}
+
 func (c *config) SetAllowMissingDependencies() {
 	c.productVariables.Allow_missing_dependencies = proptools.BoolPtr(true)
 }
@@ -823,6 +940,106 @@ func (c *config) HasDeviceProduct() bool {
 	return c.productVariables.DeviceProduct != nil
 }
 
+// AllowedDuplicateInstall returns true if the product allows more than one module to install
+// to the given path, relative to the output directory.
+func (c *config) AllowedDuplicateInstall(path string) bool {
+	return InList(path, c.productVariables.AllowedDuplicateInstalls)
+}
+
+// InstallOverride returns the product's override of how the named module is installed, if any.
+func (c *config) InstallOverride(name string) (InstallOverride, bool) {
+	override, ok := c.productVariables.InstallOverrides[name]
+	return override, ok
+}
+
+// AppResourceOverlay returns the product's resource overlay of the named app, if any.
+func (c *config) AppResourceOverlay(name string) (AppResourceOverlay, bool) {
+	overlay, ok := c.productVariables.AppResourceOverlays[name]
+	return overlay, ok
+}
+
+// ShellcheckEnabledForDir returns whether shellcheck runs by default on the shell scripts of
+// modules in dir, which is the case when it is enabled for the product and dir is not in or under
+// one of the ShellcheckExcludeDirs.
+func (c *config) ShellcheckEnabledForDir(dir string) bool {
+	if !Bool(c.productVariables.ShellcheckEnabled) {
+		return false
+	}
+	for _, exclude := range c.productVariables.ShellcheckExcludeDirs {
+		exclude = strings.TrimSuffix(exclude, "/")
+		if dir == exclude || strings.HasPrefix(dir, exclude+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// ConfigFileOverlays returns the overlays, relative to the root of the source tree, that the product
+// applies onto the named config_file module.
+func (c *config) ConfigFileOverlays(name string) []string {
+	return c.productVariables.ConfigFileOverlays[name]
+}
+
+// ProvenanceAttestation returns the configuration of the provenance attestation of the product,
+// if it signs one.
+func (c *config) ProvenanceAttestation() (ProvenanceAttestation, bool) {
+	attestation := c.productVariables.ProvenanceAttestation
+	if attestation == nil || String(attestation.SigningKey) == "" {
+		return ProvenanceAttestation{}, false
+	}
+	return *attestation, true
+}
+
+// ProvenanceAttestationSigningKey returns the path to the signing key of the provenance
+// attestation, which may be outside of the source tree, so that the attestation is generated again
+// when the key is rotated.
+func (c *config) ProvenanceAttestationSigningKey(ctx PathContext) Path {
+	return pathForBuildToolDep(ctx, String(c.productVariables.ProvenanceAttestation.SigningKey))
+}
+
+// CompilerCache is a compiler cache that wraps the cacheable compile actions of the build.
+type CompilerCache struct {
+	// Name of the compiler cache, "ccache" or "sccache".
+	Name string
+
+	// Path of the compiler cache binary.
+	Exec string
+}
+
+// SupportsRust returns whether the compiler cache can cache rustc actions.
+func (c CompilerCache) SupportsRust() bool {
+	return c.Name == "sccache"
+}
+
+// CompilerCache returns the compiler cache of the build, if one is configured. SOONG_COMPILER_CACHE,
+// or else the CompilerCache product variable, selects ccache or sccache, and
+// SOONG_COMPILER_CACHE_EXEC is the path of its binary. USE_CCACHE and CCACHE_EXEC still select
+// ccache. Remote builds and an explicit CC_WRAPPER don't use the compiler cache.
+func (c *config) CompilerCache() (CompilerCache, bool) {
+	if c.UseGoma() || c.UseRBE() || c.Getenv("CC_WRAPPER") != "" {
+		return CompilerCache{}, false
+	}
+
+	name := c.Getenv("SOONG_COMPILER_CACHE")
+	if name == "" {
+		name = String(c.productVariables.CompilerCache)
+	}
+	if name == "" && c.IsEnvTrue("USE_CCACHE") {
+		name = "ccache"
+	}
+	exec := c.Getenv("SOONG_COMPILER_CACHE_EXEC")
+	if exec == "" && name == "ccache" {
+		exec = c.Getenv("CCACHE_EXEC")
+	}
+
+	// soong_ui rejects an unknown SOONG_COMPILER_CACHE and a missing binary before the build
+	// starts.
+	if (name != "ccache" && name != "sccache") || exec == "" {
+		return CompilerCache{}, false
+	}
+	return CompilerCache{Name: name, Exec: exec}, true
+}
+
 func (c *config) DeviceResourceOverlays() []string {
 	return c.productVariables.DeviceResourceOverlays
 }
@@ -996,6 +1213,18 @@ func (c *config) DefaultAppCertificate(ctx PathContext) (pem, key SourcePath) {
 	return defaultDir.Join(ctx, "testkey.x509.pem"), defaultDir.Join(ctx, "testkey.pk8")
 }
 
+// DefaultJdkVersion returns the JDK version that the java modules of the product are compiled
+// with when they don't set jdk_version, or "" for the default of the build.
+func (c *config) DefaultJdkVersion() string {
+	return String(c.productVariables.DefaultJdkVersion)
+}
+
+// DefaultClangVersion returns the clang release that the cc modules of the product are built
+// with when they don't set clang_version, or "" for the default of the build.
+func (c *config) DefaultClangVersion() string {
+	return String(c.productVariables.DefaultClangVersion)
+}
+
 func (c *config) ApexKeyDir(ctx ModuleContext) SourcePath {
 	// TODO(b/121224311): define another variable such as TARGET_APEX_KEY_OVERRIDE
 	defaultCert := String(c.productVariables.DefaultAppCertificate)
@@ -1046,6 +1275,45 @@ func (c *config) AlwaysUsePrebuiltSdks() bool {
 	return Bool(c.productVariables.Always_use_prebuilt_sdks)
 }
 
+// Returns true if java modules are compiled against only the classes of their direct
+// dependencies, not those of the static libraries merged into them.
+func (c *config) StrictJavaDeps() bool {
+	return Bool(c.productVariables.Strict_java_deps)
+}
+
+// Returns true if the build writes binary transparency log entries for the signed APKs and APEXes
+// that it installs.
+func (c *config) TransparencyLog() bool {
+	return Bool(c.productVariables.Transparency_log)
+}
+
+// Returns true if the build counts the uses of the module types, their properties and the Soong
+// config variables in the Android.bp files.
+func (c *config) ModuleTypeCensus() bool {
+	return Bool(c.productVariables.Module_type_census)
+}
+
+// Returns the maximum length of the commands of actions, after expanding their inputs, outputs
+// and arguments, or 0 if the length isn't limited. Defaults to the maximum length of an argument
+// of execve on Linux.
+func (c *config) MaxCommandLineLength() int {
+	if c.productVariables.Max_command_line_length != nil {
+		return *c.productVariables.Max_command_line_length
+	}
+	return defaultMaxCommandLineLength
+}
+
+// Returns the maximum length of the response files of actions, or 0 if the length isn't limited.
+func (c *config) MaxRspfileLength() int {
+	return proptools.IntDefault(c.productVariables.Max_rspfile_length, 0)
+}
+
+// Returns the maximum length of the paths of the outputs of Windows modules, or 0 if the length
+// isn't limited.
+func (c *config) WindowsMaxPathLength() int {
+	return proptools.IntDefault(c.productVariables.Windows_max_path_length, 0)
+}
+
 func (c *config) MinimizeJavaDebugInfo() bool {
 	return Bool(c.productVariables.MinimizeJavaDebugInfo) && !Bool(c.productVariables.Eng)
 }
@@ -1132,6 +1400,53 @@ func (c *config) RunErrorProne() bool {
 	return c.IsEnvTrue("RUN_ERROR_PRONE")
 }
 
+// NormalizeJavaOutputs returns true if the jars produced by java compile and dex actions should
+// be rewritten with sorted entries and fixed timestamps, so that identical inputs produce
+// byte-identical outputs and downstream actions get more remote cache hits.
+func (c *config) NormalizeJavaOutputs() bool {
+	return c.IsEnvTrue("SOONG_NORMALIZE_JAVA_OUTPUTS")
+}
+
+// IncrementalJavac returns true if java modules should only recompile the sources affected by a
+// change instead of the whole module. Dependencies between classes are tracked at the class
+// level, which misses some changes such as inlined constants, so this is opt-in.
+func (c *config) IncrementalJavac() bool {
+	return c.IsEnvTrue("SOONG_INCREMENTAL_JAVAC")
+}
+
+// ContentKeyedIntermediates returns true if the actions built with BuildContentKeyed should write
+// their outputs to paths keyed on the contents of the actions instead of the modules, so that the
+// same action of different modules, products or branches has the same output path.
+func (c *config) ContentKeyedIntermediates() bool {
+	return c.IsEnvTrue("SOONG_CONTENT_KEYED_INTERMEDIATES")
+}
+
+// NinjaShards returns the number of files that the build actions of the ninja file are split into,
+// from SOONG_NINJA_SHARDS. Defaults to 1, which writes a single ninja file.
+func (c *config) NinjaShards() int {
+	v := c.Getenv("SOONG_NINJA_SHARDS")
+	if v == "" {
+		return 1
+	}
+	shards, err := strconv.Atoi(v)
+	if err != nil || shards < 1 {
+		fmt.Fprintf(os.Stderr, "bad SOONG_NINJA_SHARDS value: %q, will write a single ninja file\n", v)
+		return 1
+	}
+	return shards
+}
+
+// ActionCacheDir returns the directory of the local action cache, from SOONG_ACTION_CACHE_DIR or
+// else the ActionCacheDir product variable, or "" if the action cache isn't used. The actions
+// that opt into the cache reuse the outputs of the same actions of other builds, including builds
+// in other output directories.
+func (c *config) ActionCacheDir() string {
+	if dir := c.Getenv("SOONG_ACTION_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	return String(c.productVariables.ActionCacheDir)
+}
+
 // XrefCorpusName returns the Kythe cross-reference corpus name.
 func (c *config) XrefCorpusName() string {
 	return c.Getenv("XREF_CORPUS")
@@ -1540,6 +1855,111 @@ func (c *config) CFIDisabledForPath(path string) bool {
 	return HasAnyPrefix(path, c.productVariables.CFIExcludePaths)
 }
 
+// GlobalThinLto returns true if cc modules are built with ThinLTO by default, either because the
+// product sets GlobalThinLto or because GLOBAL_THINLTO is set in the environment.
+func (c *config) GlobalThinLto() bool {
+	return Bool(c.productVariables.GlobalThinLto) || c.IsEnvTrue("GLOBAL_THINLTO")
+}
+
+// LtoExemptions returns the product's exemptions from ThinLTO by default.
+func (c *config) LtoExemptions() []LtoExemption {
+	return c.productVariables.LtoExemptions
+}
+
+// LtoExemptionFor returns the first exemption from ThinLTO by default that applies to the named
+// module in the given directory, if any.
+func (c *config) LtoExemptionFor(name, dir string) (LtoExemption, bool) {
+	for _, exemption := range c.productVariables.LtoExemptions {
+		if exemption.Module != nil && *exemption.Module == name {
+			return exemption, true
+		}
+		if exemption.Path != nil {
+			path := strings.TrimSuffix(*exemption.Path, "/")
+			if dir == path || strings.HasPrefix(dir, path+"/") {
+				return exemption, true
+			}
+		}
+	}
+	return LtoExemption{}, false
+}
+
+// SplitDebugInfo returns true if the debug info of device binaries is split into files named
+// after their build ids by default.
+func (c *config) SplitDebugInfo() bool {
+	return Bool(c.productVariables.SplitDebugInfo)
+}
+
+// BreakpadSymbols returns true if Breakpad symbols are generated for cc binaries and shared
+// libraries by default.
+func (c *config) BreakpadSymbols() bool {
+	return Bool(c.productVariables.BreakpadSymbols)
+}
+
+// HardeningConfigs returns the product's configs of memory tagging and branch protection.
+func (c *config) HardeningConfigs() []HardeningConfig {
+	return c.productVariables.HardeningConfigs
+}
+
+// HardeningSetting returns a setting of the HardeningConfigs product variable for the modules of a
+// partition in a directory, and a description of the config that it came from, if any config sets
+// it. The config of the longest matching directory takes precedence, then the config of the
+// partition.
+func (c *config) HardeningSetting(partition, dir string, setting func(HardeningConfig) *string) (string, string, bool) {
+	value, source, matchLen := "", "", -1
+	for _, hardening := range c.productVariables.HardeningConfigs {
+		v := setting(hardening)
+		if v == nil {
+			continue
+		}
+		if hardening.Path != nil {
+			path := strings.TrimSuffix(*hardening.Path, "/")
+			if (dir == path || strings.HasPrefix(dir, path+"/")) && len(path) > matchLen {
+				value, source, matchLen = *v, "path "+path, len(path)
+			}
+		} else if hardening.Partition != nil && *hardening.Partition == partition && matchLen < 0 {
+			value, source, matchLen = *v, "partition "+partition, 0
+		}
+	}
+	return value, source, matchLen >= 0
+}
+
+// LicensePolicyFile returns the path of the product's license policy file, relative to the top of
+// the source tree, or "" if the product has none.
+func (c *config) LicensePolicyFile() string {
+	return String(c.productVariables.LicensePolicyFile)
+}
+
+// CcFlagPolicySeverity returns "error" if cc modules that use restricted compiler or linker flags
+// fail the build, or "warning" if they are only reported. Defaults to "warning".
+func (c *config) CcFlagPolicySeverity() string {
+	return StringDefault(c.productVariables.CcFlagPolicySeverity, "warning")
+}
+
+// CcFlagPolicyAllowlist returns the product's exceptions to the restricted cc flags.
+func (c *config) CcFlagPolicyAllowlist() []CcFlagPolicyException {
+	return c.productVariables.CcFlagPolicyAllowlist
+}
+
+// CcFlagPolicyExceptionFor returns the first exception to the restricted cc flags that allows the
+// named module in the given directory to use the restricted flag, if any.
+func (c *config) CcFlagPolicyExceptionFor(name, dir, restrictedFlag string) (CcFlagPolicyException, bool) {
+	for _, exception := range c.productVariables.CcFlagPolicyAllowlist {
+		if len(exception.Flags) > 0 && !InList(restrictedFlag, exception.Flags) {
+			continue
+		}
+		if exception.Module != nil && *exception.Module == name {
+			return exception, true
+		}
+		if exception.Path != nil {
+			path := strings.TrimSuffix(*exception.Path, "/")
+			if dir == path || strings.HasPrefix(dir, path+"/") {
+				return exception, true
+			}
+		}
+	}
+	return CcFlagPolicyException{}, false
+}
+
 func (c *config) CFIEnabledForPath(path string) bool {
 	if len(c.productVariables.CFIIncludePaths) == 0 {
 		return false
@@ -1619,6 +2039,10 @@ func (c *config) EnforceInterPartitionJavaSdkLibrary() bool {
 	return Bool(c.productVariables.EnforceInterPartitionJavaSdkLibrary)
 }
 
+func (c *config) EnforceGeneratedHeaderIncludeDirs() bool {
+	return Bool(c.productVariables.EnforceGeneratedHeaderIncludeDirs)
+}
+
 func (c *config) InterPartitionJavaLibraryAllowList() []string {
 	return c.productVariables.InterPartitionJavaLibraryAllowList
 }
@@ -1639,6 +2063,11 @@ func (c *config) ProductHiddenAPIStubsTest() []string {
 	return c.productVariables.ProductHiddenAPIStubsTest
 }
 
+// AconfigValueSets returns the aconfig_value_set modules of the release configuration.
+func (c *config) AconfigValueSets() []string {
+	return c.productVariables.AconfigValueSets
+}
+
 func (c *deviceConfig) TargetFSConfigGen() []string {
 	return c.config.productVariables.TargetFSConfigGen
 }
@@ -1866,6 +2295,23 @@ func (c *deviceConfig) BuildBrokenInputDir(name string) bool {
 	return InList(name, c.config.productVariables.BuildBrokenInputDirModules)
 }
 
+func (c *deviceConfig) BuildBrokenSourceVisibility() bool {
+	return c.config.productVariables.BuildBrokenSourceVisibility
+}
+
+// BuildBrokenMinSdkVersion returns whether the min_sdk_version violations of the modules in the
+// given directory are reported instead of failing the build, because the directory or one of its
+// parents is listed in BUILD_BROKEN_MIN_SDK_VERSION_DIRS.
+func (c *deviceConfig) BuildBrokenMinSdkVersion(dir string) bool {
+	for _, d := range c.config.productVariables.BuildBrokenMinSdkVersionDirs {
+		d = strings.TrimSuffix(d, "/")
+		if dir == d || strings.HasPrefix(dir, d+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *deviceConfig) BuildBrokenDepfile() bool {
 	return Bool(c.config.productVariables.BuildBrokenDepfile)
 }
@@ -0,0 +1,103 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// The config dump is the fully resolved configuration of a product, written when soong_build runs
+// with --dump_config so that two products or two branches can be compared with
+// soong_config_diff. It is written after the analysis, so that it includes all the environment
+// variables the analysis depends on.
+
+// ConfigDump is the content of the config dump. It is written as JSON with sorted keys, so that
+// dumps of the same configuration are identical.
+type ConfigDump struct {
+	// The product variables read from soong.variables. Unset variables are left out.
+	ProductVariables map[string]interface{} `json:"product_variables"`
+
+	// The targets by OS, e.g. "android_arm64_armv8-a", in the order they are configured in.
+	Targets map[string][]string `json:"targets"`
+
+	// The targets of the tools that run on the build machine.
+	BuildOSTarget       string `json:"build_os_target"`
+	BuildOSCommonTarget string `json:"build_os_common_target"`
+
+	// The targets of the common modules and of the first architecture of the device, if it is
+	// configured.
+	AndroidCommonTarget      string `json:"android_common_target,omitempty"`
+	AndroidFirstDeviceTarget string `json:"android_first_device_target,omitempty"`
+
+	// The environment variables the analysis depends on and their values.
+	EnvDeps map[string]string `json:"env_deps"`
+}
+
+// DumpConfig returns the config dump of a configuration. It freezes the environment of the
+// configuration, see Config.EnvDeps.
+func DumpConfig(config Config) (*ConfigDump, error) {
+	// Convert the product variables to a map to sort them by name and leave out the unset ones.
+	data, err := json.Marshal(config.productVariables)
+	if err != nil {
+		return nil, err
+	}
+	var productVariables map[string]interface{}
+	if err := json.Unmarshal(data, &productVariables); err != nil {
+		return nil, err
+	}
+	for name, value := range productVariables {
+		if value == nil {
+			delete(productVariables, name)
+		}
+	}
+
+	dump := &ConfigDump{
+		ProductVariables:    productVariables,
+		Targets:             make(map[string][]string),
+		BuildOSTarget:       config.BuildOSTarget.String(),
+		BuildOSCommonTarget: config.BuildOSCommonTarget.String(),
+		EnvDeps:             config.EnvDeps(),
+	}
+	for os, targets := range config.Targets {
+		if len(targets) == 0 {
+			continue
+		}
+		for _, target := range targets {
+			dump.Targets[os.String()] = append(dump.Targets[os.String()], target.String())
+		}
+	}
+	if config.AndroidCommonTarget.Os != NoOsType {
+		dump.AndroidCommonTarget = config.AndroidCommonTarget.String()
+	}
+	if config.AndroidFirstDeviceTarget.Os != NoOsType {
+		dump.AndroidFirstDeviceTarget = config.AndroidFirstDeviceTarget.String()
+	}
+	return dump, nil
+}
+
+// WriteConfigDump writes the config dump of a configuration.
+func WriteConfigDump(w io.Writer, config Config) error {
+	dump, err := DumpConfig(config)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDumpConfig(t *testing.T) {
+	config := TestArchConfig(t.TempDir(), map[string]string{"FOO": "bar"}, "", nil)
+	config.Getenv("FOO")
+
+	dump, err := DumpConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	AssertDeepEquals(t, "Platform_sdk_version", float64(30), dump.ProductVariables["Platform_sdk_version"])
+	if _, ok := dump.ProductVariables["BuildId"]; ok {
+		t.Errorf("expected the unset BuildId to be left out")
+	}
+	AssertDeepEquals(t, "android targets",
+		[]string{"android_arm64_armv8-a", "android_arm_armv7-a-neon"}, dump.Targets["android"])
+	AssertStringEquals(t, "first device target", "android_arm64_armv8-a", dump.AndroidFirstDeviceTarget)
+	AssertStringEquals(t, "common target", "android_common", dump.AndroidCommonTarget)
+	AssertDeepEquals(t, "env deps", map[string]string{"FOO": "bar"}, dump.EnvDeps)
+}
+
+func TestWriteConfigDumpIsCanonical(t *testing.T) {
+	write := func() string {
+		var buf bytes.Buffer
+		if err := WriteConfigDump(&buf, TestArchConfig(t.TempDir(), nil, "", nil)); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	AssertStringEquals(t, "config dumps", write(), write())
+}
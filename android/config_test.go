@@ -16,6 +16,7 @@ package android
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -180,3 +181,36 @@ func TestConfiguredJarList(t *testing.T) {
 		assertStringEquals(t, "apex1:jarA", list5.String())
 	})
 }
+
+func TestTestConfigSnapshot(t *testing.T) {
+	result := GroupFixturePreparers(
+		PrepareForTestWithArchMutator,
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.Unbundled_build_apps = []string{"foo"}
+		}),
+		FixtureMergeEnv(map[string]string{"FOO": "bar"}),
+	).RunTest(t)
+
+	snapshot, err := SnapshotTestConfig(result.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := GroupFixturePreparers(
+		FixtureRestoreConfigSnapshot(snapshot),
+		// Preparers after the snapshot modify the config built from it.
+		FixtureMergeEnv(map[string]string{"BAZ": "qux"}),
+	).RunTest(t)
+
+	AssertDeepEquals(t, "Targets", result.Config.Targets, restored.Config.Targets)
+	AssertDeepEquals(t, "BuildOSCommonTarget", result.Config.BuildOSCommonTarget, restored.Config.BuildOSCommonTarget)
+	AssertDeepEquals(t, "AndroidFirstDeviceTarget", result.Config.AndroidFirstDeviceTarget, restored.Config.AndroidFirstDeviceTarget)
+	AssertArrayString(t, "Unbundled_build_apps", []string{"foo"}, restored.Config.productVariables.Unbundled_build_apps)
+	AssertStringEquals(t, "FOO", "bar", restored.Config.Getenv("FOO"))
+	AssertStringEquals(t, "BAZ", "qux", restored.Config.Getenv("BAZ"))
+	AssertStringEquals(t, "PATH", os.Getenv("PATH"), restored.Config.Getenv("PATH"))
+
+	if _, err := TestConfigFromSnapshot(t.TempDir(), []byte(`{"Targets": [{"Os": "plan9"}]}`), "", nil); err == nil {
+		t.Errorf("expected an error for an unknown os")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/blueprint"
+)
+
+// This file places the outputs of selected actions at paths keyed on the contents of the actions
+// rather than on the modules that build them. The path of an intermediate output normally
+// contains the directory, name and variant of its module, so the same action of two modules, or
+// of two products or branches whose variants are named differently, has different outputs and
+// misses the remote cache. With SOONG_CONTENT_KEYED_INTERMEDIATES=true, the output of an action
+// built with BuildContentKeyed is instead written to
+// out/soong/.intermediates/.content/<key>/<name>, where key is a hash of the rule, the arguments
+// and the inputs of the action.
+
+func init() {
+	RegisterContentKeyedActionsBuildComponents(InitRegistrationContext)
+}
+
+func RegisterContentKeyedActionsBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("content_keyed_actions", contentKeyedActionsSingletonFactory)
+}
+
+var PrepareForTestWithContentKeyedActions = FixtureRegisterWithContext(RegisterContentKeyedActionsBuildComponents)
+
+var contentKeyedActionsKey = NewOnceKey("contentKeyedActions")
+
+// contentKeyedActions holds the actions registered with BuildContentKeyed by key, for the
+// contentKeyedActionsSingleton to build each of them once.
+type contentKeyedActions struct {
+	sync.Mutex
+	actions map[string]contentKeyedAction
+}
+
+type contentKeyedAction struct {
+	pctx   PackageContext
+	params BuildParams
+}
+
+func contentKeyedActionsForConfig(config Config) *contentKeyedActions {
+	return config.Once(contentKeyedActionsKey, func() interface{} {
+		return &contentKeyedActions{actions: make(map[string]contentKeyedAction)}
+	}).(*contentKeyedActions)
+}
+
+// BuildContentKeyed registers params, whose Output is a path in the output directory of the
+// module, and returns the path of the output. It is a drop-in replacement for ctx.Build for
+// actions whose output only depends on the rule, the arguments and the inputs of the action.
+//
+// When ContentKeyedIntermediates is set, the output is written to
+// out/soong/.intermediates/.content/<key>/<name of Output> instead, and the action is built
+// once by the content_keyed_actions singleton, however many modules register it. The action must
+// have a single output. Users of the output, including install rules, must use the returned path
+// rather than Output. Actions whose rule isn't a package rule, e.g. from pctx.StaticRule, are
+// built by the module as usual, since the rules of a module can't be used by other modules.
+func BuildContentKeyed(ctx ModuleContext, pctx PackageContext, params BuildParams) Path {
+	if !ctx.Config().ContentKeyedIntermediates() || len(ctx.GetMissingDependencies()) > 0 {
+		ctx.Build(pctx, params)
+		return params.Output
+	}
+	rule, ok := packageRuleParamsForConfig(ctx.Config(), params.Rule)
+	if !ok {
+		ctx.Build(pctx, params)
+		return params.Output
+	}
+	if params.Output == nil || len(params.Outputs) > 0 || len(params.ImplicitOutputs) > 0 ||
+		params.ImplicitOutput != nil || len(params.SymlinkOutputs) > 0 || params.SymlinkOutput != nil {
+		panic(fmt.Errorf("content keyed actions must have exactly one output"))
+	}
+
+	key := contentKey(rule, params)
+	params.Output = PathForIntermediates(ctx, ".content", key, params.Output.Base())
+	if params.Depfile != nil {
+		params.Depfile = PathForIntermediates(ctx, ".content", key, params.Depfile.Base())
+	}
+
+	actions := contentKeyedActionsForConfig(ctx.Config())
+	actions.Lock()
+	defer actions.Unlock()
+	if _, exists := actions.actions[key]; !exists {
+		actions.actions[key] = contentKeyedAction{pctx, params}
+	}
+	return params.Output
+}
+
+// contentKey returns a hash of everything that determines the output of an action built with
+// BuildContentKeyed: the rule, the arguments, the paths of the inputs and the name of the output.
+// The description and the order of the inputs within each kind don't change the key.
+func contentKey(rule blueprint.RuleParams, params BuildParams) string {
+	h := sha256.New()
+	write := func(kind string, values ...string) {
+		for _, v := range values {
+			fmt.Fprintf(h, "%s %d:%s\n", kind, len(v), v)
+		}
+	}
+	write("rule", params.Rule.String(), rule.Command, rule.Rspfile, rule.RspfileContent)
+	write("command_dep", rule.CommandDeps...)
+	argNames := SortedKeys(params.Args)
+	for _, name := range argNames {
+		write("arg", name, params.Args[name])
+	}
+	sortedPaths := func(paths Paths, path Path) []string {
+		if path != nil {
+			paths = append(Paths{path}, paths...)
+		}
+		ret := paths.Strings()
+		sort.Strings(ret)
+		return ret
+	}
+	write("input", sortedPaths(params.Inputs, params.Input)...)
+	write("implicit", sortedPaths(params.Implicits, params.Implicit)...)
+	write("order_only", sortedPaths(params.OrderOnly, nil)...)
+	write("validation", sortedPaths(params.Validations, params.Validation)...)
+	write("output", params.Output.Base())
+	return fmt.Sprintf("%x", h.Sum(nil))[:32]
+}
+
+func contentKeyedActionsSingletonFactory() Singleton {
+	return &contentKeyedActionsSingleton{}
+}
+
+type contentKeyedActionsSingleton struct{}
+
+// GenerateBuildActions builds the actions registered with BuildContentKeyed, in the order of their
+// keys so that the ninja file doesn't depend on the order the modules were analyzed in.
+func (s *contentKeyedActionsSingleton) GenerateBuildActions(ctx SingletonContext) {
+	actions := contentKeyedActionsForConfig(ctx.Config())
+	for _, key := range SortedKeys(actions.actions) {
+		action := actions.actions[key]
+		ctx.Build(action.pctx, action.params)
+	}
+}
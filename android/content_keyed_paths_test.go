@@ -0,0 +1,118 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+)
+
+type contentKeyedTestModule struct {
+	ModuleBase
+	properties struct {
+		Src *string `android:"path"`
+	}
+	output Path
+}
+
+func (m *contentKeyedTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	m.output = BuildContentKeyed(ctx, pctx, BuildParams{
+		Rule:        Cp,
+		Description: "copy",
+		Input:       PathForModuleSrc(ctx, proptools.String(m.properties.Src)),
+		Output:      PathForModuleOut(ctx, "copied", "out.txt"),
+	})
+}
+
+func contentKeyedTestModuleFactory() Module {
+	m := &contentKeyedTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidModule(m)
+	return m
+}
+
+const contentKeyedTestBp = `
+	content_keyed_test {
+		name: "foo",
+		src: "a.txt",
+	}
+
+	content_keyed_test {
+		name: "bar",
+		src: "a.txt",
+	}
+
+	content_keyed_test {
+		name: "baz",
+		src: "b.txt",
+	}
+`
+
+var prepareForContentKeyedTest = GroupFixturePreparers(
+	PrepareForTestWithContentKeyedActions,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("content_keyed_test", contentKeyedTestModuleFactory)
+	}),
+	FixtureWithRootAndroidBp(contentKeyedTestBp),
+	FixtureMergeMockFs(MockFS{
+		"a.txt": nil,
+		"b.txt": nil,
+	}),
+)
+
+func TestContentKeyedIntermediates(t *testing.T) {
+	result := GroupFixturePreparers(
+		prepareForContentKeyedTest,
+		FixtureMergeEnv(map[string]string{"SOONG_CONTENT_KEYED_INTERMEDIATES": "true"}),
+	).RunTest(t)
+
+	output := func(name string) Path {
+		return result.ModuleForTests(name, "").Module().(*contentKeyedTestModule).output
+	}
+	foo, bar, baz := output("foo"), output("bar"), output("baz")
+
+	assertContentKeyed := func(path Path) {
+		t.Helper()
+		if !strings.HasPrefix(PathRelativeToTop(path), "out/soong/.intermediates/.content/") {
+			t.Errorf("expected a content keyed path, got %q", PathRelativeToTop(path))
+		}
+	}
+	assertContentKeyed(foo)
+	assertContentKeyed(baz)
+	AssertStringEquals(t, "output name", "out.txt", foo.Base())
+	AssertPathRelativeToTopEquals(t, "same action", PathRelativeToTop(foo), bar)
+	if PathRelativeToTop(foo) == PathRelativeToTop(baz) {
+		t.Errorf("expected different inputs to have different paths, got %q", PathRelativeToTop(foo))
+	}
+
+	// The modules don't build the actions, the singleton builds each of them once.
+	AssertDeepEquals(t, "module outputs", []string(nil), result.ModuleForTests("foo", "").AllOutputs())
+	singleton := result.SingletonForTests("content_keyed_actions")
+	AssertIntEquals(t, "singleton outputs", 2, len(singleton.AllOutputs()))
+	AssertPathRelativeToTopEquals(t, "input", "a.txt", singleton.Output(PathRelativeToTop(foo)).Input)
+}
+
+func TestContentKeyedIntermediatesDisabled(t *testing.T) {
+	result := prepareForContentKeyedTest.RunTest(t)
+
+	module := result.ModuleForTests("foo", "")
+	AssertPathRelativeToTopEquals(t, "output", "out/soong/.intermediates/foo/copied/out.txt",
+		module.Module().(*contentKeyedTestModule).output)
+	module.Output("copied/out.txt")
+	AssertDeepEquals(t, "singleton outputs", []string(nil),
+		result.SingletonForTests("content_keyed_actions").AllOutputs())
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// The dead arch branches report lists, by directory and module, the arch, multilib and target
+// property branches that are set in Android.bp files but that no variant of the module selects
+// in the current product, e.g. `arch: { riscv64: {...} }` in a product without riscv64 targets,
+// or a `target: { windows: {...} }` of a module that is never built for Windows. It is written to
+// $OUT/soong/dead_arch_branches.json and built with `m dead_arch_branches`.
+//
+// A branch is only dead for the product it is reported for. The branches that are reported for
+// every supported product are candidates for a cleanup of the tree.
+
+func init() {
+	RegisterDeadArchBranchesBuildComponents(InitRegistrationContext)
+}
+
+func RegisterDeadArchBranchesBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("dead_arch_branches", deadArchBranchesSingletonFactory)
+}
+
+var PrepareForTestWithDeadArchBranches = FixtureRegisterWithContext(RegisterDeadArchBranchesBuildComponents)
+
+// DeadArchBranches is the content of dead_arch_branches.json. It maps the directories to the
+// modules in them to the sorted property branches of the module that no variant selects.
+type DeadArchBranches struct {
+	Directories map[string]map[string][]string `json:"directories"`
+}
+
+// archPropertyBranches returns the arch, multilib and target property branches that are set for
+// the module, e.g. "arch.arm", "arch.arm.neon", "multilib.lib32" or "target.android_arm64".
+func (m *ModuleBase) archPropertyBranches() []string {
+	var branches []string
+	for _, shards := range m.archProperties {
+		for _, archProperties := range shards {
+			root := reflect.ValueOf(archProperties).Elem()
+			for _, group := range []string{"Arch", "Multilib", "Target"} {
+				groupValue := root.FieldByName(group).Elem()
+				if !groupValue.IsValid() || groupValue.IsNil() {
+					continue
+				}
+				groupValue = groupValue.Elem()
+				prefix := proptools.PropertyNameForField(group) + "."
+				for i := 0; i < groupValue.NumField(); i++ {
+					branch := groupValue.Field(i)
+					if branch.IsZero() {
+						continue
+					}
+					name := prefix + proptools.PropertyNameForField(groupValue.Type().Field(i).Name)
+					branches = append(branches, name)
+					if group != "Arch" {
+						continue
+					}
+					// The arch structs also hold the arch variant, cpu variant and arch feature
+					// branches, e.g. "arch.arm.neon", next to the embedded properties of the arch.
+					for j := 0; j < branch.NumField(); j++ {
+						field := branch.Type().Field(j)
+						if field.Anonymous || branch.Field(j).IsZero() {
+							continue
+						}
+						branches = append(branches, name+"."+proptools.PropertyNameForField(field.Name))
+					}
+				}
+			}
+		}
+	}
+	return FirstUniqueStrings(branches)
+}
+
+func deadArchBranchesSingletonFactory() Singleton {
+	return &deadArchBranchesSingleton{}
+}
+
+type deadArchBranchesSingleton struct{}
+
+// moduleArchBranches is the set and selected property branches of all the variants of a module.
+type moduleArchBranches struct {
+	name     string
+	dir      string
+	set      []string
+	selected map[string]bool
+}
+
+func (s *deadArchBranchesSingleton) GenerateBuildActions(ctx SingletonContext) {
+	modules := make(map[string]*moduleArchBranches)
+	ctx.VisitAllModules(func(module Module) {
+		base := module.base()
+		if len(base.archProperties) == 0 {
+			return
+		}
+		dir := ctx.ModuleDir(module)
+		name := ctx.ModuleName(module)
+		key := dir + ":" + name
+		branches := modules[key]
+		if branches == nil {
+			// The branches are set in the Android.bp file, so they are the same for all the
+			// variants.
+			branches = &moduleArchBranches{
+				name:     name,
+				dir:      dir,
+				set:      base.archPropertyBranches(),
+				selected: make(map[string]bool),
+			}
+			modules[key] = branches
+		}
+		for _, branch := range base.commonProperties.SelectedArchPropertyBranches {
+			branches.selected[branch] = true
+		}
+	})
+
+	report := DeadArchBranches{Directories: make(map[string]map[string][]string)}
+	for _, branches := range modules {
+		var dead []string
+		for _, branch := range branches.set {
+			if !branches.selected[branch] {
+				dead = append(dead, branch)
+			}
+		}
+		if len(dead) == 0 {
+			continue
+		}
+		sort.Strings(dead)
+		dir := report.Directories[branches.dir]
+		if dir == nil {
+			dir = make(map[string][]string)
+			report.Directories[branches.dir] = dir
+		}
+		dir[branches.name] = dead
+	}
+
+	jsonStr, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	output := PathForOutput(ctx, "dead_arch_branches.json")
+	WriteFileRule(ctx, output, string(jsonStr))
+	ctx.Phony("dead_arch_branches", output)
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDeadArchBranches(t *testing.T) {
+	result := GroupFixturePreparers(
+		PrepareForTestWithArchMutator,
+		PrepareForTestWithDeadArchBranches,
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("module", func() Module {
+				module := &testArchPropertiesModule{}
+				module.AddProperties(&module.properties)
+				InitAndroidArchModule(module, HostAndDeviceSupported, MultilibBoth)
+				return module
+			})
+		}),
+		FixtureWithRootAndroidBp(`
+			module {
+				name: "foo",
+				arch: {
+					arm: { a: ["arm"] },
+					arm64: {
+						armv8_2a: { a: ["armv8_2a"] },
+					},
+					riscv64: { a: ["riscv64"] },
+				},
+				multilib: {
+					lib32: { a: ["lib32"] },
+				},
+				target: {
+					android: { a: ["android"] },
+					android_x86: { a: ["android_x86"] },
+					host: { a: ["host"] },
+					windows: { a: ["windows"] },
+				},
+			}
+
+			module {
+				name: "bar",
+				target: {
+					android: { a: ["android"] },
+				},
+			}
+		`),
+	).RunTest(t)
+
+	output := result.SingletonForTests("dead_arch_branches").Output("dead_arch_branches.json")
+	var report DeadArchBranches
+	if err := json.Unmarshal([]byte(ContentFromFileRuleForTests(t, output)), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	AssertDeepEquals(t, "dead arch branches", map[string]map[string][]string{
+		".": {
+			"foo": {"arch.arm64.armv8_2a", "arch.riscv64", "target.android_x86", "target.windows"},
+		},
+	}, report.Directories)
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/blueprint"
+)
+
+// DependencyCycleHint is implemented by dependency tags that can suggest how to break a
+// dependency cycle that contains a dependency with the tag.
+type DependencyCycleHint interface {
+	DependencyCycleHint() string
+}
+
+// dependencyOrigin records the tag and the mutator of dependencies added by a mutator, so that a
+// dependency cycle can be explained in terms of the properties that caused it.
+type dependencyOrigin struct {
+	tag     blueprint.DependencyTag
+	mutator string
+	names   []string
+}
+
+func (m *ModuleBase) addDependencyOrigin(tag blueprint.DependencyTag, mutator string, names []string) {
+	m.dependencyOrigins = append(m.dependencyOrigins, dependencyOrigin{tag, mutator, names})
+}
+
+// dependencyOrigin returns the origin of the dependency on the module with the given name, if it
+// was recorded.
+func (m *ModuleBase) dependencyOrigin(name string) (dependencyOrigin, bool) {
+	for _, origin := range m.dependencyOrigins {
+		for _, n := range origin.names {
+			if n == name || RemoveOptionalPrebuiltPrefix(n) == name {
+				return origin, true
+			}
+		}
+	}
+	return dependencyOrigin{}, false
+}
+
+const dependencyCycleError = "encountered dependency cycle"
+
+// ExplainDependencyCycles adds an error describing the shortest dependency cycle between modules,
+// with the dependency tag and mutator of each dependency in the cycle and hints on how to break
+// it, if errs contains a dependency cycle error. The cycle reported by blueprint is the first
+// one that is found, which can be a long path through a large strongly connected component.
+func (ctx *Context) ExplainDependencyCycles(errs []error) []error {
+	found := false
+	for _, err := range errs {
+		if strings.Contains(err.Error(), dependencyCycleError) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errs
+	}
+
+	var modules []blueprint.Module
+	index := make(map[blueprint.Module]int)
+	ctx.VisitAllModules(func(module blueprint.Module) {
+		index[module] = len(modules)
+		modules = append(modules, module)
+	})
+	graph := make([][]int, len(modules))
+	for i, module := range modules {
+		ctx.VisitDirectDeps(module, func(dep blueprint.Module) {
+			if j, ok := index[dep]; ok {
+				graph[i] = append(graph[i], j)
+			}
+		})
+	}
+
+	cycle := shortestCycle(graph)
+	if cycle == nil {
+		return errs
+	}
+
+	describe := func(module blueprint.Module) string {
+		s := fmt.Sprintf("module %q", ctx.ModuleName(module))
+		if variant := ctx.ModuleSubDir(module); variant != "" {
+			s += fmt.Sprintf(" variant %q", variant)
+		}
+		return s
+	}
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "shortest dependency cycle has %d modules:", len(cycle))
+	for i, from := range cycle {
+		to := cycle[(i+1)%len(cycle)]
+		fmt.Fprintf(&sb, "\n    %s depends on %s", describe(modules[from]), describe(modules[to]))
+
+		module, ok := modules[from].(Module)
+		if !ok {
+			continue
+		}
+		origin, ok := module.base().dependencyOrigin(ctx.ModuleName(modules[to]))
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n        added by the %q mutator with tag %T", origin.mutator, origin.tag)
+		if hint, ok := origin.tag.(DependencyCycleHint); ok && hint.DependencyCycleHint() != "" {
+			fmt.Fprintf(&sb, "\n        hint: %s", hint.DependencyCycleHint())
+		}
+	}
+
+	return append(errs, fmt.Errorf("%s", sb.String()))
+}
+
+// shortestCycle returns the nodes of a shortest cycle of the graph, given as the successors of
+// each node, or nil if the graph has no cycles.
+func shortestCycle(graph [][]int) []int {
+	var shortest []int
+	for _, component := range stronglyConnectedComponents(graph) {
+		inComponent := make(map[int]bool, len(component))
+		for _, node := range component {
+			inComponent[node] = true
+		}
+		for _, start := range component {
+			cycle := shortestCycleFrom(graph, start, inComponent)
+			if cycle != nil && (shortest == nil || len(cycle) < len(shortest)) {
+				shortest = cycle
+			}
+			if len(shortest) == 1 {
+				return shortest
+			}
+		}
+	}
+	return shortest
+}
+
+// shortestCycleFrom returns the shortest cycle through start that only contains nodes of the
+// same strongly connected component, using a breadth first search.
+func shortestCycleFrom(graph [][]int, start int, inComponent map[int]bool) []int {
+	parent := map[int]int{start: -1}
+	queue := []int{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range graph[node] {
+			if next == start {
+				var cycle []int
+				for n := node; n != -1; n = parent[n] {
+					cycle = append([]int{n}, cycle...)
+				}
+				return cycle
+			}
+			if _, seen := parent[next]; !seen && inComponent[next] {
+				parent[next] = node
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// stronglyConnectedComponents returns the strongly connected components of the graph that
+// contain a cycle, using an iterative version of Tarjan's algorithm.
+func stronglyConnectedComponents(graph [][]int) [][]int {
+	const unvisited = -1
+	indexes := make([]int, len(graph))
+	lowlinks := make([]int, len(graph))
+	onStack := make([]bool, len(graph))
+	for i := range indexes {
+		indexes[i] = unvisited
+	}
+
+	var components [][]int
+	var stack []int
+	next := 0
+
+	type frame struct {
+		node, edge int
+	}
+
+	for root := range graph {
+		if indexes[root] != unvisited {
+			continue
+		}
+		frames := []frame{{root, 0}}
+		indexes[root], lowlinks[root] = next, next
+		next++
+		stack = append(stack, root)
+		onStack[root] = true
+
+		for len(frames) > 0 {
+			f := &frames[len(frames)-1]
+			if f.edge < len(graph[f.node]) {
+				succ := graph[f.node][f.edge]
+				f.edge++
+				if indexes[succ] == unvisited {
+					indexes[succ], lowlinks[succ] = next, next
+					next++
+					stack = append(stack, succ)
+					onStack[succ] = true
+					frames = append(frames, frame{succ, 0})
+				} else if onStack[succ] && indexes[succ] < lowlinks[f.node] {
+					lowlinks[f.node] = indexes[succ]
+				}
+				continue
+			}
+
+			node := f.node
+			frames = frames[:len(frames)-1]
+			if len(frames) > 0 {
+				parent := frames[len(frames)-1].node
+				if lowlinks[node] < lowlinks[parent] {
+					lowlinks[parent] = lowlinks[node]
+				}
+			}
+
+			if lowlinks[node] == indexes[node] {
+				var component []int
+				for {
+					n := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[n] = false
+					component = append(component, n)
+					if n == node {
+						break
+					}
+				}
+				if len(component) > 1 || hasEdge(graph, node, node) {
+					components = append(components, component)
+				}
+			}
+		}
+	}
+	return components
+}
+
+func hasEdge(graph [][]int, from, to int) bool {
+	for _, succ := range graph[from] {
+		if succ == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShortestCycle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		graph    [][]int
+		expected []int
+	}{
+		{
+			name:     "no cycle",
+			graph:    [][]int{{1, 2}, {2}, {}},
+			expected: nil,
+		},
+		{
+			name:     "self loop",
+			graph:    [][]int{{1}, {1}},
+			expected: []int{1},
+		},
+		{
+			// 0 -> 1 -> 2 -> 3 -> 4 -> 0 is the cycle found by a depth first search, but
+			// 2 -> 3 -> 2 is shorter.
+			name:     "shortest cycle in component",
+			graph:    [][]int{{1}, {2}, {3}, {4, 2}, {0}},
+			expected: []int{3, 2},
+		},
+		{
+			name:     "shortest of two components",
+			graph:    [][]int{{1}, {2}, {0}, {4}, {3}},
+			expected: []int{4, 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := shortestCycle(tc.graph); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestExplainDependencyCycles(t *testing.T) {
+	bp := `
+		deps {
+			name: "foo",
+			deps: ["bar"],
+		}
+
+		deps {
+			name: "bar",
+			deps: ["baz"],
+		}
+
+		deps {
+			name: "baz",
+			deps: ["qux"],
+		}
+
+		deps {
+			name: "qux",
+			deps: ["foo", "baz"],
+		}
+	`
+
+	GroupFixturePreparers(
+		prepareForModuleTests,
+		PrepareForTestWithArchMutator,
+	).
+		ExtendWithErrorHandler(FixtureExpectsAtLeastOneErrorMatchingPattern(
+			`shortest dependency cycle has 2 modules:`+
+				`\n    module "(baz|qux)" variant "android_common" depends on module "(baz|qux)" variant "android_common"`+
+				`\n        added by the "deps" mutator with tag android.installDepTag`+
+				`\n    module "(baz|qux)" variant "android_common" depends on module "(baz|qux)" variant "android_common"`+
+				`\n        added by the "deps" mutator with tag android.installDepTag`)).
+		RunTestWithBp(t, bp)
+}
+
+func TestDependencyOriginsNotRecorded(t *testing.T) {
+	bp := `
+		deps {
+			name: "foo",
+			deps: ["bar"],
+		}
+
+		deps {
+			name: "bar",
+		}
+	`
+
+	result := GroupFixturePreparers(
+		prepareForModuleTests,
+		PrepareForTestWithArchMutator,
+	).RunTestWithBp(t, bp)
+	foo := result.ModuleForTests("foo", "android_common").Module()
+	if _, ok := foo.base().dependencyOrigin("bar"); !ok {
+		t.Errorf("expected the origin of the dependency on bar to be recorded in tests")
+	}
+
+	result = GroupFixturePreparers(
+		prepareForModuleTests,
+		PrepareForTestWithArchMutator,
+		FixtureModifyConfig(func(config Config) {
+			config.recordDependencyOrigins = false
+		}),
+	).RunTestWithBp(t, bp)
+	foo = result.ModuleForTests("foo", "android_common").Module()
+	AssertIntEquals(t, "dependency origins", 0, len(foo.base().dependencyOrigins))
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// The dist_owners singleton maps each artifact that modules copy to the dist directory with their
+// dist and dists properties back to the directory of the module that produces it, the OWNERS files
+// that apply to that directory and its nearest METADATA file. Release tooling reads the resulting
+// $OUT/soong/dist_owners.json to find the reviewers and approvers of a change to an artifact.
+//
+// The OWNERS and METADATA files are not searched for here: soong_ui already indexes them with the
+// finder and writes their paths to OWNERS.list and METADATA.list next to Android.bp.list.
+
+func init() {
+	RegisterDistOwnersBuildComponents(InitRegistrationContext)
+}
+
+func RegisterDistOwnersBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("dist_owners", distOwnersSingletonFactory)
+}
+
+var PrepareForTestWithDistOwners = FixtureRegisterWithContext(RegisterDistOwnersBuildComponents)
+
+// DistOwnersEntry is an entry of dist_owners.json.
+type DistOwnersEntry struct {
+	// The path of the artifact within the dist directory.
+	Artifact string `json:"artifact"`
+
+	// The goals that copy the artifact to the dist directory.
+	Goals []string `json:"goals"`
+
+	// The modules that produce the artifact.
+	Modules []string `json:"modules"`
+
+	// The directories of the modules, relative to the top of the source tree.
+	Dirs []string `json:"dirs"`
+
+	// The OWNERS files that apply to the directories, nearest first.
+	Owners []string `json:"owners,omitempty"`
+
+	// The nearest METADATA files of the directories.
+	Metadata []string `json:"metadata,omitempty"`
+}
+
+func distOwnersSingletonFactory() Singleton {
+	return &distOwnersSingleton{}
+}
+
+type distOwnersSingleton struct{}
+
+// readFinderFileList returns the paths in a file list written by the finder of soong_ui, or nil if
+// the file list does not exist.
+func readFinderFileList(ctx SingletonContext, name string) map[string]bool {
+	file := filepath.Join(filepath.Dir(ctx.Config().moduleListFile), name)
+	data, err := os.ReadFile(absolutePath(file))
+	if err != nil {
+		return nil
+	}
+	ctx.AddNinjaFileDeps(file)
+
+	files := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files[filepath.Clean(line)] = true
+		}
+	}
+	return files
+}
+
+// filesInParentDirs returns the file with the given name in dir and each of its parent
+// directories that is in files, nearest first.
+func filesInParentDirs(files map[string]bool, dir, name string) []string {
+	var ret []string
+	for {
+		if file := filepath.Join(dir, name); files[file] {
+			ret = append(ret, file)
+		}
+		if dir == "." || dir == "/" {
+			return ret
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func (s *distOwnersSingleton) GenerateBuildActions(ctx SingletonContext) {
+	owners := readFinderFileList(ctx, "OWNERS.list")
+	metadata := readFinderFileList(ctx, "METADATA.list")
+
+	entries := make(map[string]*DistOwnersEntry)
+	ctx.VisitAllModules(func(module Module) {
+		m := module.base()
+		if !m.Enabled() {
+			return
+		}
+		for _, dist := range m.Dists() {
+			tag := proptools.StringDefault(dist.Tag, DefaultDistTag)
+			for _, path := range m.distFiles[tag] {
+				if path == nil {
+					continue
+				}
+				dest := distCopyDest(ctx.Config(), dist, path)
+				entry := entries[dest]
+				if entry == nil {
+					entry = &DistOwnersEntry{Artifact: dest}
+					entries[dest] = entry
+				}
+				dir := ctx.ModuleDir(module)
+				entry.Goals = append(entry.Goals, dist.Targets...)
+				entry.Modules = append(entry.Modules, ctx.ModuleName(module))
+				entry.Dirs = append(entry.Dirs, dir)
+				entry.Owners = append(entry.Owners, filesInParentDirs(owners, dir, "OWNERS")...)
+				if nearest := filesInParentDirs(metadata, dir, "METADATA"); len(nearest) > 0 {
+					entry.Metadata = append(entry.Metadata, nearest[0])
+				}
+			}
+		}
+	})
+
+	ret := make([]*DistOwnersEntry, 0, len(entries))
+	for _, entry := range entries {
+		entry.Goals = SortedUniqueStrings(entry.Goals)
+		entry.Modules = SortedUniqueStrings(entry.Modules)
+		entry.Dirs = SortedUniqueStrings(entry.Dirs)
+		entry.Owners = FirstUniqueStrings(entry.Owners)
+		entry.Metadata = SortedUniqueStrings(entry.Metadata)
+		ret = append(ret, entry)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Artifact < ret[j].Artifact
+	})
+
+	data, err := json.MarshalIndent(ret, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	WriteFileRule(ctx, PathForOutput(ctx, "dist_owners.json"), string(data))
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDistOwners(t *testing.T) {
+	fileListDir := t.TempDir()
+	writeFileList := func(name, contents string) {
+		if err := os.WriteFile(filepath.Join(fileListDir, name), []byte(contents), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFileList("OWNERS.list", "./OWNERS\nvendor/OWNERS\nvendor/foo/bar/OWNERS\nother/OWNERS")
+	writeFileList("METADATA.list", "vendor/foo/METADATA")
+
+	result := GroupFixturePreparers(
+		PrepareForTestWithDistOwners,
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("custom", customModuleFactory)
+		}),
+		FixtureModifyConfig(func(config Config) {
+			config.moduleListFile = filepath.Join(fileListDir, "Android.bp.list")
+		}),
+		FixtureAddTextFile("vendor/foo/bar/Android.bp", `
+			custom {
+				name: "foo",
+				dists: [
+					{
+						targets: ["sdk"],
+					},
+					{
+						targets: ["droidcore"],
+						tag: ".multiple",
+					},
+				],
+			}
+		`),
+		FixtureAddTextFile("other/Android.bp", `
+			custom {
+				name: "bar",
+				dist: {
+					targets: ["droidcore"],
+					dest: "renamed.out",
+				},
+			}
+
+			custom {
+				name: "baz",
+			}
+		`),
+	).RunTest(t)
+
+	var entries []DistOwnersEntry
+	out := result.SingletonForTests("dist_owners").Output("dist_owners.json")
+	if err := json.Unmarshal([]byte(ContentFromFileRuleForTests(t, out)), &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []DistOwnersEntry{
+		{
+			Artifact: "four.out",
+			Goals:    []string{"droidcore"},
+			Modules:  []string{"foo"},
+			Dirs:     []string{"vendor/foo/bar"},
+			Owners:   []string{"vendor/foo/bar/OWNERS", "vendor/OWNERS", "OWNERS"},
+			Metadata: []string{"vendor/foo/METADATA"},
+		},
+		{
+			Artifact: "one.out",
+			Goals:    []string{"sdk"},
+			Modules:  []string{"foo"},
+			Dirs:     []string{"vendor/foo/bar"},
+			Owners:   []string{"vendor/foo/bar/OWNERS", "vendor/OWNERS", "OWNERS"},
+			Metadata: []string{"vendor/foo/METADATA"},
+		},
+		{
+			Artifact: "renamed.out",
+			Goals:    []string{"droidcore"},
+			Modules:  []string{"bar"},
+			Dirs:     []string{"other"},
+			Owners:   []string{"other/OWNERS", "OWNERS"},
+		},
+		{
+			Artifact: "two.out",
+			Goals:    []string{"droidcore"},
+			Modules:  []string{"foo"},
+			Dirs:     []string{"vendor/foo/bar"},
+			Owners:   []string{"vendor/foo/bar/OWNERS", "vendor/OWNERS", "OWNERS"},
+			Metadata: []string{"vendor/foo/METADATA"},
+		},
+	}
+	AssertDeepEquals(t, "dist_owners.json", expected, entries)
+}
+
+func TestFilesInParentDirs(t *testing.T) {
+	files := map[string]bool{
+		"OWNERS":         true,
+		"a/b/OWNERS":     true,
+		"a/b/c/d/OWNERS": true,
+	}
+	AssertDeepEquals(t, "nested", []string{"a/b/c/d/OWNERS", "a/b/OWNERS", "OWNERS"},
+		filesInParentDirs(files, "a/b/c/d", "OWNERS"))
+	AssertDeepEquals(t, "root", []string{"OWNERS"}, filesInParentDirs(files, ".", "OWNERS"))
+	AssertDeepEquals(t, "none", []string(nil), filesInParentDirs(files, "x", "METADATA"))
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// This file answers "how is this file generated" for soong_build --explain, from the build
+// actions captured during analysis rather than from build.ninja: the rule, command line, inputs,
+// outputs and pool of the action that generates a file, and the module variant or singleton that
+// owns the action.
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/blueprint"
+)
+
+// Explanation is the action that generates an output file.
+type Explanation struct {
+	// The explained output file.
+	Output string
+
+	// The module variant that owns the action, or the singleton when Singleton is set.
+	Module     string
+	Variant    string
+	ModuleType string
+	Dir        string
+	Singleton  string
+
+	Rule        string
+	Description string
+	Pool        string
+
+	// The command line of the action, with $in, $out and the arguments of the rule expanded.
+	// Package variables, like ${config.ClangBin}, are left as written in the rule, as they are
+	// only evaluated when build.ninja is written.
+	Command string
+
+	Inputs          []string
+	Implicits       []string
+	OrderOnly       []string
+	Validations     []string
+	Outputs         []string
+	ImplicitOutputs []string
+
+	Args map[string]string
+}
+
+// Explain returns the action of ctx that generates path, a file relative to the root of the source
+// tree. The build parameters must have been captured, which is the case in the GenerateExplain
+// build mode.
+func Explain(ctx *Context, path string) (*Explanation, error) {
+	path = filepath.Clean(path)
+	config := ctx.Config()
+
+	var explanation *Explanation
+	explain := func(params BuildParams, ruleParams map[blueprint.Rule]blueprint.RuleParams,
+		variables map[string]string) bool {
+		if !InList(path, writablePathStrings(params.outputs())) {
+			return false
+		}
+		explanation = newExplanation(config, path, params, ruleParams, variables)
+		return true
+	}
+
+	ctx.VisitAllModules(func(m blueprint.Module) {
+		module, ok := m.(Module)
+		if !ok || explanation != nil {
+			return
+		}
+		for _, params := range module.base().buildParams {
+			if explain(params, module.base().ruleParams, module.base().variables) {
+				explanation.Module = ctx.ModuleName(m)
+				explanation.Variant = ctx.ModuleSubDir(m)
+				explanation.ModuleType = ctx.ModuleType(m)
+				explanation.Dir = ctx.ModuleDir(m)
+				return
+			}
+		}
+	})
+	if explanation != nil {
+		return explanation, nil
+	}
+
+	for _, s := range ctx.Singletons() {
+		if adaptor, ok := s.(*singletonAdaptor); ok {
+			for _, params := range adaptor.buildParams {
+				if explain(params, adaptor.ruleParams, nil) {
+					explanation.Singleton = ctx.SingletonName(s)
+					return explanation, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no action generates %q", path)
+}
+
+// outputs returns the explicit, implicit and symlink outputs of the action.
+func (p BuildParams) outputs() []WritablePath {
+	outputs := append([]WritablePath{p.Output}, p.Outputs...)
+	outputs = append(outputs, p.ImplicitOutput)
+	outputs = append(outputs, p.ImplicitOutputs...)
+	outputs = append(outputs, p.SymlinkOutput)
+	return append(outputs, p.SymlinkOutputs...)
+}
+
+func writablePathStrings(paths []WritablePath) []string {
+	var ret []string
+	for _, p := range paths {
+		if p != nil {
+			ret = append(ret, p.String())
+		}
+	}
+	return ret
+}
+
+func pathStrings(paths ...Path) []string {
+	var ret []string
+	for _, p := range paths {
+		if p != nil {
+			ret = append(ret, p.String())
+		}
+	}
+	return ret
+}
+
+func newExplanation(config Config, path string, params BuildParams,
+	ruleParams map[blueprint.Rule]blueprint.RuleParams, variables map[string]string) *Explanation {
+
+	e := &Explanation{
+		Output:          path,
+		Rule:            params.Rule.String(),
+		Inputs:          append(pathStrings(params.Input), params.Inputs.Strings()...),
+		Implicits:       append(pathStrings(params.Implicit), params.Implicits.Strings()...),
+		OrderOnly:       params.OrderOnly.Strings(),
+		Validations:     append(pathStrings(params.Validation), params.Validations.Strings()...),
+		Outputs:         writablePathStrings(append([]WritablePath{params.Output}, params.Outputs...)),
+		ImplicitOutputs: writablePathStrings(append([]WritablePath{params.ImplicitOutput}, params.ImplicitOutputs...)),
+		Args:            params.Args,
+	}
+
+	// Local rules of modules and singletons are captured with their actions, the rules of
+	// PackageContexts are evaluated for the config.
+	rule, ok := ruleParams[params.Rule]
+	if !ok {
+		if f, found := packageRuleParams[params.Rule]; found {
+			if p, err := f(config); err == nil {
+				rule = p
+			}
+		}
+	}
+
+	// The variables of the module, like ${moduleDesc}, are shadowed by the arguments of the rule.
+	vars := make(map[string]string)
+	for k, v := range variables {
+		vars[k] = v
+	}
+	for k, v := range params.Args {
+		vars[k] = v
+	}
+	vars["in"] = strings.Join(e.Inputs, " ")
+	vars["in_newline"] = strings.Join(e.Inputs, "\n")
+	vars["out"] = strings.Join(e.Outputs, " ")
+	e.Command = expandNinjaVariables(rule.Command, vars)
+	if params.Description != "" {
+		e.Description = expandNinjaVariables(params.Description, vars)
+	} else {
+		e.Description = expandNinjaVariables(rule.Description, vars)
+	}
+	if rule.Pool != nil {
+		e.Pool = rule.Pool.String()
+	}
+	return e
+}
+
+// expandNinjaVariables expands the references to vars in a ninja string, and unescapes "$$",
+// "$ " and "$:". References to other variables are left as they are.
+func expandNinjaVariables(s string, vars map[string]string) string {
+	isVarChar := func(c byte) bool {
+		return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch c := s[i+1]; {
+		case c == '$' || c == ' ' || c == ':':
+			sb.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				sb.WriteString(s[i:])
+				return sb.String()
+			}
+			name := s[i+2 : i+end]
+			if value, ok := vars[name]; ok {
+				sb.WriteString(value)
+			} else {
+				sb.WriteString(s[i : i+end+1])
+			}
+			i += end
+		default:
+			j := i + 1
+			for j < len(s) && isVarChar(s[j]) {
+				j++
+			}
+			if value, ok := vars[s[i+1:j]]; ok {
+				sb.WriteString(value)
+			} else {
+				sb.WriteString(s[i:j])
+			}
+			i = j - 1
+		}
+	}
+	return sb.String()
+}
+
+// WriteExplanation writes the explanation as text. Every field is written, in a fixed order, so
+// the output can be compared and parsed.
+func WriteExplanation(w io.Writer, e *Explanation) error {
+	// Lines have no trailing whitespace, empty fields are written as "name:".
+	var sb strings.Builder
+	line := func(s string) {
+		sb.WriteString(strings.TrimRight(s, " \t"))
+		sb.WriteByte('\n')
+	}
+	field := func(name, value string) {
+		line(name + ": " + value)
+	}
+	list := func(name string, values []string) {
+		line(name + ":")
+		for _, v := range values {
+			line("    " + v)
+		}
+	}
+
+	field("output", e.Output)
+	if e.Singleton != "" {
+		field("singleton", e.Singleton)
+	} else {
+		field("module", e.Module)
+		field("variant", e.Variant)
+		field("module type", e.ModuleType)
+		field("directory", e.Dir)
+	}
+	field("rule", e.Rule)
+	field("description", e.Description)
+	field("pool", e.Pool)
+	var command []string
+	if e.Command != "" {
+		command = strings.Split(e.Command, "\n")
+	}
+	list("command", command)
+	list("inputs", e.Inputs)
+	list("implicit inputs", e.Implicits)
+	list("order-only inputs", e.OrderOnly)
+	list("validations", e.Validations)
+	list("outputs", e.Outputs)
+	list("implicit outputs", e.ImplicitOutputs)
+	var args []string
+	for _, k := range SortedStringKeys(e.Args) {
+		args = append(args, k+" = "+e.Args[k])
+	}
+	list("args", args)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	result := prepareForImpactTest.RunTest(t)
+	ctx := result.TestContext.Context
+
+	// The output of b is copied from its gen output with the Cp rule of the package context.
+	output := result.ModuleForTests("b", "").Module().(*impactTestModule).output.String()
+	explanation, err := Explain(ctx, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertStringEquals(t, "module", "b", explanation.Module)
+	AssertStringEquals(t, "module type", "impact_test", explanation.ModuleType)
+	AssertStringEquals(t, "dir", ".", explanation.Dir)
+	AssertDeepEquals(t, "outputs", []string{output}, explanation.Outputs)
+	if len(explanation.Inputs) != 1 {
+		t.Fatalf("expected one input, got %q", explanation.Inputs)
+	}
+	gen := explanation.Inputs[0]
+	AssertStringDoesContain(t, "command", explanation.Command, "rm -f "+output+" && cp ")
+	AssertStringDoesContain(t, "command", explanation.Command, gen+" "+output)
+
+	// The gen output is generated by a rule of the rule builder, which is local to the module.
+	explanation, err = Explain(ctx, gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertStringEquals(t, "module", "b", explanation.Module)
+	AssertStringDoesContain(t, "command", explanation.Command, "cat ")
+	AssertStringDoesContain(t, "command", explanation.Command, "> "+gen)
+
+	if _, err := Explain(ctx, "out/soong/missing"); err == nil {
+		t.Errorf("expected an error for a file that no action generates")
+	}
+}
+
+func TestExpandNinjaVariables(t *testing.T) {
+	vars := map[string]string{"in": "a b", "out": "c", "flags": "-x"}
+	AssertStringEquals(t, "expanded",
+		"tool -x a b -o c ${config.Tool} $$undefined $:",
+		expandNinjaVariables("tool $flags ${in} -o $out ${config.Tool} $$$$undefined $$:", vars))
+}
+
+func TestWriteExplanation(t *testing.T) {
+	explanation := &Explanation{
+		Output:      "out/soong/.intermediates/foo/foo",
+		Module:      "foo",
+		ModuleType:  "impact_test",
+		Dir:         "foo",
+		Rule:        "Cp",
+		Description: "cp out/soong/.intermediates/foo/foo",
+		Command:     "cp in out/soong/.intermediates/foo/foo",
+		Inputs:      []string{"in"},
+		Outputs:     []string{"out/soong/.intermediates/foo/foo"},
+		Args:        map[string]string{"cpFlags": "-f", "extraCmds": ""},
+	}
+	var buf bytes.Buffer
+	if err := WriteExplanation(&buf, explanation); err != nil {
+		t.Fatal(err)
+	}
+	expected := `output: out/soong/.intermediates/foo/foo
+module: foo
+variant:
+module type: impact_test
+directory: foo
+rule: Cp
+description: cp out/soong/.intermediates/foo/foo
+pool:
+command:
+    cp in out/soong/.intermediates/foo/foo
+inputs:
+    in
+implicit inputs:
+order-only inputs:
+validations:
+outputs:
+    out/soong/.intermediates/foo/foo
+implicit outputs:
+args:
+    cpFlags = -f
+    extraCmds =
+`
+	AssertStringEquals(t, "explanation", expected, buf.String())
+}
@@ -214,6 +214,7 @@ type fileGroup struct {
 var _ MixedBuildBuildable = (*fileGroup)(nil)
 var _ SourceFileProducer = (*fileGroup)(nil)
 var _ FileGroupAsLibrary = (*fileGroup)(nil)
+var _ SourceVisibilityReferencer = (*fileGroup)(nil)
 
 // filegroup contains a list of files that are referenced by other modules
 // properties (such as "srcs") using the syntax ":<name>". filegroup are
@@ -251,6 +252,19 @@ func (fg *fileGroup) GenerateAndroidBuildActions(ctx ModuleContext) {
 	}
 }
 
+// SourceVisibilityDirs returns the directories of the srcs that are referenced by path, so that
+// they can be checked against the visibility of the packages containing them.
+func (fg *fileGroup) SourceVisibilityDirs(ctx BaseModuleContext) []string {
+	var dirs []string
+	for _, src := range fg.properties.Srcs {
+		if m, _ := SrcIsModuleWithTag(src); m != "" {
+			continue
+		}
+		dirs = append(dirs, filepath.Dir(filepath.Join(moduleSrcDir(ctx), src)))
+	}
+	return FirstUniqueStrings(dirs)
+}
+
 func (fg *fileGroup) Srcs() Paths {
 	return append(Paths{}, fg.srcs...)
 }
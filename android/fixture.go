@@ -951,7 +951,7 @@ func (s *standardTestRunner) PostParseProcessor(customResult CustomTestResult) {
 	// GenerateAndroidBuildActions methods on all the modules.
 	extraNinjaDeps, errs := ctx.PrepareBuildActions(cfg)
 	result.NinjaDeps = append(result.NinjaDeps, extraNinjaDeps...)
-	result.CollateErrs(errs)
+	result.CollateErrs(ctx.ExplainDependencyCycles(errs))
 }
 
 var defaultTestRunner FixtureTestRunner = &standardTestRunner{}
@@ -0,0 +1,41 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// generatedBpDirName is the directory in the Soong output directory that holds the Blueprint files
+// written by the module list providers, the Android.bp.gen executables that soong_ui runs before
+// soong_build. The provider in <dir> writes out/soong/.generated_bp/<dir>/Android.bp. It must match
+// moduleListProviderOutDir in ui/build.
+const generatedBpDirName = ".generated_bp"
+
+// moduleSrcDir returns the directory that the source paths of the module are relative to. It is
+// the directory of the module, except for the modules generated by a module list provider, whose
+// source paths are relative to the directory of the provider.
+func moduleSrcDir(ctx EarlyModulePathContext) string {
+	dir := ctx.ModuleDir()
+	generatedDir := filepath.Join(ctx.Config().soongOutDir, generatedBpDirName)
+	if dir == generatedDir {
+		return "."
+	}
+	if strings.HasPrefix(dir, generatedDir+"/") {
+		return strings.TrimPrefix(dir, generatedDir+"/")
+	}
+	return dir
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+)
+
+func TestGeneratedBpModuleSrcs(t *testing.T) {
+	result := GroupFixturePreparers(
+		PrepareForTestWithFilegroup,
+		// soong_ui passes a relative Soong output directory, like the one the module list
+		// providers write their Blueprint files to.
+		FixtureModifyConfig(func(config Config) {
+			config.soongOutDir = "out/soong"
+		}),
+		FixtureAddTextFile("out/soong/.generated_bp/foo/Android.bp", `
+			filegroup {
+				name: "foo",
+				srcs: [
+					"a.txt",
+					"sub/*.txt",
+				],
+			}
+		`),
+		FixtureAddTextFile("out/soong/.generated_bp/Android.bp", `
+			filegroup {
+				name: "root",
+				srcs: ["b.txt"],
+			}
+		`),
+		FixtureMergeMockFs(MockFS{
+			"foo/a.txt":     nil,
+			"foo/sub/c.txt": nil,
+			"b.txt":         nil,
+		}),
+	).RunTest(t)
+
+	foo := result.ModuleForTests("foo", "").Module().(*fileGroup)
+	AssertPathsRelativeToTopEquals(t, "foo srcs", []string{"foo/a.txt", "foo/sub/c.txt"}, foo.srcs)
+	root := result.ModuleForTests("root", "").Module().(*fileGroup)
+	AssertPathsRelativeToTopEquals(t, "root srcs", []string{"b.txt"}, root.srcs)
+}
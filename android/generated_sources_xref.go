@@ -0,0 +1,146 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+)
+
+// The generated sources xref index maps the source files that the build generates, like the java
+// and C++ files generated from aidl, proto and sysprop files or the stubs generated by metalava,
+// back to the module that generates them and to the files they are generated from, so that code
+// search can link generated code to its origin. Files are identified by their Kythe VNames, as in
+// the compilation units of the xref goals. The index is written to
+// $OUT/soong/generated_sources_xref.json when Kythe cross-references are emitted, i.e. when
+// XREF_CORPUS is set, and is built with `m xref_generated_sources` and by the xref_java and
+// xref_cxx goals.
+
+func init() {
+	RegisterGeneratedSourcesXrefBuildComponents(InitRegistrationContext)
+}
+
+func RegisterGeneratedSourcesXrefBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("generated_sources_xref", generatedSourcesXrefSingletonFactory)
+}
+
+var PrepareForTestWithGeneratedSourcesXref = FixtureRegisterWithContext(RegisterGeneratedSourcesXrefBuildComponents)
+
+// generatedSource is a set of files that a module generates from inputs with a generator.
+type generatedSource struct {
+	kind    string
+	outputs Paths
+	inputs  Paths
+}
+
+// RecordGeneratedSources records, for the generated sources xref index, that the module generates
+// outputs from inputs with a generator of kind, e.g. "aidl". Outputs may be srcjars, in which case
+// all the files of the srcjar are generated from inputs. It does nothing when Kythe
+// cross-references are not emitted.
+func RecordGeneratedSources(ctx ModuleContext, kind string, outputs, inputs Paths) {
+	if !ctx.Config().EmitXrefRules() {
+		return
+	}
+	m := ctx.Module().base()
+	m.generatedSources = append(m.generatedSources, generatedSource{
+		kind:    kind,
+		outputs: outputs,
+		inputs:  inputs,
+	})
+}
+
+// GeneratedSourcesXrefPath returns the path of the generated sources xref index.
+func GeneratedSourcesXrefPath(ctx PathContext) OutputPath {
+	return PathForOutput(ctx, "generated_sources_xref.json")
+}
+
+// XrefVName is the Kythe VName of a file, as mapped by build/soong/vnames.json: the files in the
+// out directory have the "out" root and a path relative to it.
+type XrefVName struct {
+	Corpus string `json:"corpus"`
+	Root   string `json:"root,omitempty"`
+	Path   string `json:"path"`
+}
+
+func xrefVName(ctx PathContext, corpus string, path Path) XrefVName {
+	if rel, isRel := MaybeRel(ctx, ctx.Config().OutDir(), path.String()); isRel {
+		return XrefVName{Corpus: corpus, Root: "out", Path: rel}
+	}
+	return XrefVName{Corpus: corpus, Path: path.String()}
+}
+
+// GeneratedSourcesXref is the content of generated_sources_xref.json.
+type GeneratedSourcesXref struct {
+	// The generated files, sorted by their path from the root of the source tree.
+	GeneratedFiles []GeneratedFileXref `json:"generated_files"`
+}
+
+// GeneratedFileXref is a generated file, the module that generates it and its sources.
+type GeneratedFileXref struct {
+	File      XrefVName   `json:"file"`
+	Generator string      `json:"generator"`
+	Module    string      `json:"module"`
+	ModuleDir string      `json:"module_dir"`
+	Sources   []XrefVName `json:"sources"`
+}
+
+func generatedSourcesXrefSingletonFactory() Singleton {
+	return &generatedSourcesXrefSingleton{}
+}
+
+type generatedSourcesXrefSingleton struct{}
+
+func (s *generatedSourcesXrefSingleton) GenerateBuildActions(ctx SingletonContext) {
+	if !ctx.Config().EmitXrefRules() {
+		return
+	}
+	corpus := ctx.Config().XrefCorpusName()
+
+	files := make(map[string]GeneratedFileXref)
+	ctx.VisitAllModules(func(module Module) {
+		for _, generated := range module.base().generatedSources {
+			var sources []XrefVName
+			for _, input := range generated.inputs {
+				sources = append(sources, xrefVName(ctx, corpus, input))
+			}
+			for _, output := range generated.outputs {
+				// Variants that generate the same file generate it from the same sources.
+				if _, exists := files[output.String()]; exists {
+					continue
+				}
+				files[output.String()] = GeneratedFileXref{
+					File:      xrefVName(ctx, corpus, output),
+					Generator: generated.kind,
+					Module:    ctx.ModuleName(module),
+					ModuleDir: ctx.ModuleDir(module),
+					Sources:   sources,
+				}
+			}
+		}
+	})
+
+	index := GeneratedSourcesXref{GeneratedFiles: []GeneratedFileXref{}}
+	for _, path := range SortedStringKeys(files) {
+		index.GeneratedFiles = append(index.GeneratedFiles, files[path])
+	}
+
+	jsonStr, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	output := GeneratedSourcesXrefPath(ctx)
+	WriteFileRule(ctx, output, string(jsonStr))
+	ctx.Phony("xref_generated_sources", output)
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type generatedSourcesTestModule struct {
+	ModuleBase
+	properties struct {
+		Srcs []string `android:"path"`
+	}
+}
+
+func generatedSourcesTestModuleFactory() Module {
+	m := &generatedSourcesTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidModule(m)
+	return m
+}
+
+func (m *generatedSourcesTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	for _, src := range PathsForModuleSrc(ctx, m.properties.Srcs) {
+		cpp := PathForModuleGen(ctx, "aidl", src.Rel()+".cpp")
+		header := PathForModuleGen(ctx, "aidl", "include", src.Rel()+".h")
+		ctx.Build(pctx, BuildParams{
+			Rule:           Cp,
+			Input:          src,
+			Output:         cpp,
+			ImplicitOutput: header,
+		})
+		RecordGeneratedSources(ctx, "aidl", Paths{cpp, header}, Paths{src})
+	}
+}
+
+var prepareForGeneratedSourcesXrefTest = GroupFixturePreparers(
+	PrepareForTestWithGeneratedSourcesXref,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("generated_sources", generatedSourcesTestModuleFactory)
+	}),
+	FixtureAddTextFile("foo/Android.bp", `
+		generated_sources {
+			name: "foo",
+			srcs: ["IFoo.aidl"],
+		}
+	`),
+	FixtureAddFile("foo/IFoo.aidl", nil),
+)
+
+func TestGeneratedSourcesXref(t *testing.T) {
+	result := GroupFixturePreparers(
+		prepareForGeneratedSourcesXrefTest,
+		FixtureMergeEnv(map[string]string{"XREF_CORPUS": "android.googlesource.com/platform/superproject"}),
+	).RunTest(t)
+
+	output := result.SingletonForTests("generated_sources_xref").Output("generated_sources_xref.json")
+	var index GeneratedSourcesXref
+	if err := json.Unmarshal([]byte(ContentFromFileRuleForTests(t, output)), &index); err != nil {
+		t.Fatal(err)
+	}
+
+	corpus := "android.googlesource.com/platform/superproject"
+	source := []XrefVName{{Corpus: corpus, Path: "foo/IFoo.aidl"}}
+	AssertDeepEquals(t, "generated files", []GeneratedFileXref{
+		{
+			File:      XrefVName{Corpus: corpus, Root: "out", Path: "soong/.intermediates/foo/gen/aidl/IFoo.aidl.cpp"},
+			Generator: "aidl",
+			Module:    "foo",
+			ModuleDir: "foo",
+			Sources:   source,
+		},
+		{
+			File:      XrefVName{Corpus: corpus, Root: "out", Path: "soong/.intermediates/foo/gen/aidl/include/IFoo.aidl.h"},
+			Generator: "aidl",
+			Module:    "foo",
+			ModuleDir: "foo",
+			Sources:   source,
+		},
+	}, index.GeneratedFiles)
+}
+
+func TestGeneratedSourcesXrefDisabled(t *testing.T) {
+	result := prepareForGeneratedSourcesXrefTest.RunTest(t)
+
+	// The index is only written when Kythe cross-references are emitted.
+	if result.SingletonForTests("generated_sources_xref").MaybeOutput("generated_sources_xref.json").Rule != nil {
+		t.Errorf("expected no generated sources xref index")
+	}
+}
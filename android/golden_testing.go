@@ -0,0 +1,304 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// This file contains a framework for golden tests of the build rules generated by module types.
+//
+// A golden test runs a fixture, renders every build statement of the selected module variants,
+// including the ninja command with the rule's arguments, $in and $out expanded, and compares them
+// against a golden file checked in next to the test, e.g.
+//
+//	result := prepareForCcTest.RunTestWithBp(t, bp)
+//	android.AssertBuildRulesMatchGolden(t, result, "testdata/golden/libfoo.golden",
+//		android.GoldenModule{"libfoo", "android_arm64_armv8-a_shared"})
+//
+// Build statements are matched by module, variant and outputs, so reordering the statements of a
+// module does not fail the test, and mismatches are reported as the tokens of each field that were
+// added or removed rather than as a textual diff of the whole file.
+//
+// After an intended change of the generated rules, update the golden files by running the tests
+// with SOONG_UPDATE_GOLDEN=true and review the changes to the golden files together with the
+// change itself.
+
+// updateGoldenEnvVar is the environment variable that makes golden tests write the golden files
+// instead of checking them.
+const updateGoldenEnvVar = "SOONG_UPDATE_GOLDEN"
+
+// GoldenModule identifies a module variant whose build rules are checked by a golden test.
+type GoldenModule struct {
+	Name    string
+	Variant string
+}
+
+// goldenStatement is a single build statement of a golden file.
+type goldenStatement struct {
+	// The module, variant and outputs of the statement, which identify it across changes.
+	key string
+
+	// The rule and the other fields of the statement, in the order they are written.
+	fields []goldenField
+}
+
+type goldenField struct {
+	name  string
+	value string
+}
+
+// AssertBuildRulesMatchGolden checks that the build statements generated for the given module
+// variants match the golden file, relative to the directory of the test. If SOONG_UPDATE_GOLDEN
+// is true then the golden file is written instead.
+func AssertBuildRulesMatchGolden(t *testing.T, result *TestResult, goldenFile string, modules ...GoldenModule) {
+	t.Helper()
+
+	actual := renderGoldenModules(result, modules)
+
+	if os.Getenv(updateGoldenEnvVar) == "true" {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenFile, []byte(actual), 0666); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("updated golden file %s", goldenFile)
+		return
+	}
+
+	expected, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatalf("%s, run the test with %s=true to create it", err, updateGoldenEnvVar)
+	}
+
+	if diffs := diffGolden(string(expected), actual); len(diffs) > 0 {
+		t.Errorf("build rules do not match golden file %s, run the test with %s=true to update it:\n%s",
+			goldenFile, updateGoldenEnvVar, strings.Join(diffs, "\n"))
+	}
+}
+
+// renderGoldenModules returns the contents of the golden file for the given module variants.
+func renderGoldenModules(result *TestResult, modules []GoldenModule) string {
+	sb := strings.Builder{}
+	for i, module := range modules {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "module %s %s\n", module.Name, module.Variant)
+		testingModule := result.ModuleForTests(module.Name, module.Variant)
+		for _, bparams := range testingModule.provider.BuildParamsForTests() {
+			params := testingModule.newTestingBuildParams(bparams)
+			sb.WriteString("\n")
+			for _, field := range goldenFields(params) {
+				if field.name == "build" {
+					fmt.Fprintf(&sb, "build %s\n", field.value)
+				} else {
+					fmt.Fprintf(&sb, "  %s: %s\n", field.name, field.value)
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+// goldenFields returns the fields of a build statement that are written to the golden file,
+// omitting the empty ones.
+func goldenFields(params TestingBuildParams) []goldenField {
+	inputs := append(PathsIfNonNil(params.Input), params.Inputs...).Strings()
+	outputs := append(WritablePaths(nil), params.Outputs...)
+	if params.Output != nil {
+		outputs = append(WritablePaths{params.Output}, outputs...)
+	}
+	implicitOutputs := append(WritablePaths(nil), params.ImplicitOutputs...)
+	if params.ImplicitOutput != nil {
+		implicitOutputs = append(WritablePaths{params.ImplicitOutput}, implicitOutputs...)
+	}
+
+	expand := func(s string) string {
+		return expandGoldenVariables(s, params.Args, inputs, outputs.Strings())
+	}
+
+	var fields []goldenField
+	add := func(name string, values ...string) {
+		value := strings.Join(values, " ")
+		if value != "" {
+			// Keep each field on a single line.
+			fields = append(fields, goldenField{name, strings.ReplaceAll(value, "\n", `\n`)})
+		}
+	}
+	add("build", strings.Join(outputs.Strings(), " ")+": "+params.Rule.String())
+	add("implicit_outputs", implicitOutputs.Strings()...)
+	add("inputs", inputs...)
+	add("implicits", append(PathsIfNonNil(params.Implicit), params.Implicits...).Strings()...)
+	add("order_only", params.OrderOnly.Strings()...)
+	add("validations", append(PathsIfNonNil(params.Validation), params.Validations...).Strings()...)
+	add("command", expand(params.RuleParams.Command))
+	add("rspfile_content", expand(params.RuleParams.RspfileContent))
+	return fields
+}
+
+var goldenVariableRegexp = regexp.MustCompile(`\$\$|\$\{[\w.-]+\}|\$[\w-]+`)
+
+// expandGoldenVariables expands $in, $out and the rule's arguments the same way as ninja would.
+// Other variables, e.g. the ones of a PackageContext, are left as is.
+func expandGoldenVariables(s string, args map[string]string, inputs, outputs []string) string {
+	return goldenVariableRegexp.ReplaceAllStringFunc(s, func(variable string) string {
+		name := strings.TrimSuffix(strings.TrimLeft(variable, "${"), "}")
+		switch {
+		case variable == "$$":
+			return variable
+		case name == "in":
+			return strings.Join(inputs, " ")
+		case name == "out":
+			return strings.Join(outputs, " ")
+		}
+		if value, ok := args[name]; ok {
+			return value
+		}
+		return variable
+	})
+}
+
+// parseGolden parses the contents of a golden file into its build statements.
+func parseGolden(contents string) (map[string]goldenStatement, error) {
+	statements := make(map[string]goldenStatement)
+	module, key := "", ""
+	for i, line := range strings.Split(contents, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "module "):
+			module, key = strings.TrimPrefix(line, "module "), ""
+		case strings.HasPrefix(line, "build "):
+			build := strings.TrimPrefix(line, "build ")
+			outputs := build
+			if colon := strings.LastIndex(build, ": "); colon != -1 {
+				outputs = build[:colon]
+			}
+			key = module + ": " + outputs
+			statements[key] = goldenStatement{key: key, fields: []goldenField{{"build", build}}}
+		case strings.HasPrefix(line, "  ") && key != "":
+			name, value, found := strings.Cut(strings.TrimPrefix(line, "  "), ": ")
+			if !found {
+				return nil, fmt.Errorf("line %d: expected a field, got %q", i+1, line)
+			}
+			s := statements[key]
+			s.fields = append(s.fields, goldenField{name, value})
+			statements[key] = s
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", i+1, line)
+		}
+	}
+	return statements, nil
+}
+
+// diffGolden compares the expected and actual contents of a golden file and returns a
+// description of each difference.
+func diffGolden(expected, actual string) []string {
+	expectedStatements, err := parseGolden(expected)
+	if err != nil {
+		return []string{"invalid golden file: " + err.Error()}
+	}
+	actualStatements, err := parseGolden(actual)
+	if err != nil {
+		return []string{"invalid build rules: " + err.Error()}
+	}
+
+	keys := SortedUniqueStrings(append(SortedStringKeys(expectedStatements), SortedStringKeys(actualStatements)...))
+
+	var diffs []string
+	for _, key := range keys {
+		e, inExpected := expectedStatements[key]
+		a, inActual := actualStatements[key]
+		if !inExpected {
+			diffs = append(diffs, "added build statement for "+key)
+			continue
+		}
+		if !inActual {
+			diffs = append(diffs, "removed build statement for "+key)
+			continue
+		}
+
+		expectedFields := goldenFieldMap(e)
+		actualFields := goldenFieldMap(a)
+		for _, name := range SortedUniqueStrings(append(SortedStringKeys(expectedFields), SortedStringKeys(actualFields)...)) {
+			removed, added := diffGoldenTokens(strings.Fields(expectedFields[name]), strings.Fields(actualFields[name]))
+			if len(removed) == 0 && len(added) == 0 {
+				continue
+			}
+			diff := fmt.Sprintf("%s of %s changed:", name, key)
+			for _, token := range removed {
+				diff += "\n    - " + token
+			}
+			for _, token := range added {
+				diff += "\n    + " + token
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+func goldenFieldMap(s goldenStatement) map[string]string {
+	fields := make(map[string]string)
+	for _, field := range s.fields {
+		fields[field.name] = field.value
+	}
+	return fields
+}
+
+// diffGoldenTokens returns the tokens that were removed from a and added to b, ignoring the
+// tokens of the longest common subsequence of a and b.
+func diffGoldenTokens(a, b []string) (removed, added []string) {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	removed = append(removed, a[i:]...)
+	added = append(added, b[j:]...)
+	return removed, added
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandGoldenVariables(t *testing.T) {
+	AssertStringEquals(t, "expanded command",
+		"rm -f out/a out/b && cp $${cpFlags} -v in/a $$HOME ${config.Foo} $unknown",
+		expandGoldenVariables("rm -f $out && cp $${cpFlags} ${flags} ${in} $$HOME ${config.Foo} $unknown",
+			map[string]string{"flags": "-v"}, []string{"in/a"}, []string{"out/a", "out/b"}))
+}
+
+func TestDiffGolden(t *testing.T) {
+	expected := `module foo android_common
+
+build out/foo.o: cc
+  inputs: foo.c
+  command: clang -c -O2 -Wall foo.c -o out/foo.o
+
+build out/foo.so: ld
+  command: clang -shared out/foo.o -o out/foo.so
+
+build out/foo.stripped: strip
+  command: strip out/foo.so
+`
+
+	actual := `module foo android_common
+
+build out/foo.so: ld
+  command: clang    -shared out/foo.o -o out/foo.so
+
+build out/foo.o: cc
+  inputs: foo.c
+  implicits: foo.h
+  command: clang -c -O3 -Wall foo.c -o out/foo.o -Werror
+
+build out/foo.toc: toc
+  command: toc out/foo.so
+`
+
+	AssertArrayString(t, "diffs", []string{
+		"command of foo android_common: out/foo.o changed:\n    - -O2\n    + -O3\n    + -Werror",
+		"implicits of foo android_common: out/foo.o changed:\n    + foo.h",
+		"removed build statement for foo android_common: out/foo.stripped",
+		"added build statement for foo android_common: out/foo.toc",
+	}, diffGolden(expected, actual))
+
+	AssertArrayString(t, "diffs of identical files", nil, diffGolden(expected, expected))
+
+	diffs := diffGolden("garbage", expected)
+	AssertStringListContains(t, "diffs of invalid golden file",
+		diffs, `invalid golden file: line 1: unexpected "garbage"`)
+}
+
+func TestAssertBuildRulesMatchGolden(t *testing.T) {
+	bp := `
+		deps {
+			name: "foo",
+		}
+	`
+
+	result := GroupFixturePreparers(
+		prepareForModuleTests,
+		PrepareForTestWithArchMutator,
+	).RunTestWithBp(t, bp)
+
+	goldenFile := filepath.Join(t.TempDir(), "golden", "foo.golden")
+	foo := GoldenModule{"foo", "android_common"}
+
+	t.Setenv(updateGoldenEnvVar, "true")
+	AssertBuildRulesMatchGolden(t, result, goldenFile, foo)
+
+	golden, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertStringDoesContain(t, "golden file", string(golden),
+		"build out/soong/.intermediates/foo/android_common/foo: android/soong/android.Touch\n"+
+			"  command: touch out/soong/.intermediates/foo/android_common/foo\n")
+	if !strings.HasPrefix(string(golden), "module foo android_common\n") {
+		t.Errorf("expected golden file to start with the module, got:\n%s", golden)
+	}
+
+	t.Setenv(updateGoldenEnvVar, "")
+	AssertBuildRulesMatchGolden(t, result, goldenFile, foo)
+}
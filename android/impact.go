@@ -0,0 +1,237 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// This file answers "what rebuilds if I touch this file" for soong_build --impact_of. A source
+// file affects the actions that read it, directly or through the outputs of other actions, and
+// the modules and singletons that own those actions. Adding or removing a file that matches a
+// glob, or changing a Blueprint file, also reruns soong_build itself, which can change any action.
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/pathtools"
+)
+
+// Impact is the estimated impact of a change to a source file on the build.
+type Impact struct {
+	// The changed file, relative to the root of the source tree.
+	Path string
+
+	// Whether changing the file reruns soong_build, because it is a Blueprint file.
+	RerunsSoongBuild bool
+
+	// Globs that match the file. Adding or removing the file reruns soong_build, and modules
+	// that use the globs may gain or lose sources.
+	Globs []string `json:",omitempty"`
+
+	// Modules that own actions affected by the change.
+	Modules []ImpactedModule `json:",omitempty"`
+
+	// Singletons that own actions affected by the change.
+	Singletons []ImpactedSingleton `json:",omitempty"`
+
+	// Number of actions that are rerun when the file changes.
+	Actions int
+}
+
+// ImpactedModule is a module variant that owns actions affected by a change.
+type ImpactedModule struct {
+	Name    string
+	Variant string `json:",omitempty"`
+	Dir     string
+
+	// Whether an action of the module reads the changed file, rather than an output derived
+	// from it.
+	Direct bool
+
+	// Number of actions of the module that are rerun.
+	Actions int
+}
+
+// ImpactedSingleton is a singleton that owns actions affected by a change.
+type ImpactedSingleton struct {
+	Name string
+
+	// Number of actions of the singleton that are rerun.
+	Actions int
+}
+
+// impactAction is a build action, with the index of the module or singleton that owns it.
+type impactAction struct {
+	owner   int
+	phony   bool
+	inputs  []string
+	outputs []string
+}
+
+func newImpactAction(owner int, params BuildParams) impactAction {
+	action := impactAction{owner: owner, phony: params.Rule == blueprint.Phony}
+	// Order-only dependencies and validations don't rerun an action when they change.
+	addInputs := func(paths ...Path) {
+		for _, p := range paths {
+			if p != nil {
+				action.inputs = append(action.inputs, p.String())
+			}
+		}
+	}
+	addInputs(params.Input, params.Implicit)
+	addInputs(params.Inputs...)
+	addInputs(params.Implicits...)
+	addOutputs := func(paths ...WritablePath) {
+		for _, p := range paths {
+			if p != nil {
+				action.outputs = append(action.outputs, p.String())
+			}
+		}
+	}
+	addOutputs(params.Output, params.ImplicitOutput)
+	addOutputs(params.Outputs...)
+	addOutputs(params.ImplicitOutputs...)
+	return action
+}
+
+// ImpactOf returns the impact of a change to path, a file relative to the root of the source
+// tree, on the build actions of ctx. The build parameters must have been captured, which is the
+// case in the GenerateImpact build mode.
+func ImpactOf(ctx *Context, path string) *Impact {
+	path = filepath.Clean(path)
+	impact := &Impact{
+		Path:             path,
+		RerunsSoongBuild: filepath.Ext(path) == ".bp",
+	}
+
+	for _, glob := range ctx.Globs() {
+		if impactGlobMatches(glob.Pattern, glob.Excludes, path) {
+			impact.Globs = append(impact.Globs, glob.Pattern)
+		}
+	}
+	impact.Globs = SortedUniqueStrings(impact.Globs)
+
+	// Modules are owners 0 to len(modules)-1, singletons follow.
+	var modules []blueprint.Module
+	var actions []impactAction
+	ctx.VisitAllModules(func(m blueprint.Module) {
+		if module, ok := m.(Module); ok {
+			for _, params := range module.base().buildParams {
+				actions = append(actions, newImpactAction(len(modules), params))
+			}
+			modules = append(modules, m)
+		}
+	})
+	var singletons []blueprint.Singleton
+	for _, s := range ctx.Singletons() {
+		if adaptor, ok := s.(*singletonAdaptor); ok {
+			for _, params := range adaptor.buildParams {
+				actions = append(actions, newImpactAction(len(modules)+len(singletons), params))
+			}
+			singletons = append(singletons, s)
+		}
+	}
+
+	readers := make(map[string][]int)
+	for i, action := range actions {
+		for _, input := range action.inputs {
+			readers[input] = append(readers[input], i)
+		}
+	}
+
+	// Walk from the changed file through the outputs of the actions that read it.
+	ownerActions := make(map[int]int)
+	direct := make(map[int]bool)
+	for _, i := range readers[path] {
+		direct[actions[i].owner] = true
+	}
+	visitedFiles := map[string]bool{path: true}
+	visitedActions := make(map[int]bool)
+	queue := []string{path}
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		for _, i := range readers[file] {
+			if visitedActions[i] {
+				continue
+			}
+			visitedActions[i] = true
+			action := actions[i]
+			if !action.phony {
+				ownerActions[action.owner]++
+				impact.Actions++
+			}
+			for _, output := range action.outputs {
+				if !visitedFiles[output] {
+					visitedFiles[output] = true
+					queue = append(queue, output)
+				}
+			}
+		}
+	}
+
+	for owner, count := range ownerActions {
+		if owner < len(modules) {
+			m := modules[owner]
+			impact.Modules = append(impact.Modules, ImpactedModule{
+				Name:    ctx.ModuleName(m),
+				Variant: ctx.ModuleSubDir(m),
+				Dir:     ctx.ModuleDir(m),
+				Direct:  direct[owner],
+				Actions: count,
+			})
+		} else {
+			impact.Singletons = append(impact.Singletons, ImpactedSingleton{
+				Name:    ctx.SingletonName(singletons[owner-len(modules)]),
+				Actions: count,
+			})
+		}
+	}
+	sort.Slice(impact.Modules, func(i, j int) bool {
+		a, b := impact.Modules[i], impact.Modules[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Variant < b.Variant
+	})
+	sort.Slice(impact.Singletons, func(i, j int) bool {
+		return impact.Singletons[i].Name < impact.Singletons[j].Name
+	})
+
+	return impact
+}
+
+func impactGlobMatches(pattern string, excludes []string, path string) bool {
+	if match, err := pathtools.Match(pattern, path); err != nil || !match {
+		return false
+	}
+	for _, exclude := range excludes {
+		if match, err := pathtools.Match(exclude, path); err == nil && match {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteImpact writes the impact as JSON.
+func WriteImpact(w io.Writer, impact *Impact) error {
+	data, err := json.MarshalIndent(impact, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/google/blueprint"
+)
+
+type impactTestModule struct {
+	ModuleBase
+	properties struct {
+		Srcs []string `android:"path"`
+		Deps []string
+	}
+
+	output Path
+}
+
+var impactTestDepTag = struct{ blueprint.BaseDependencyTag }{}
+
+func (m *impactTestModule) DepsMutator(ctx BottomUpMutatorContext) {
+	ctx.AddDependency(ctx.Module(), impactTestDepTag, m.properties.Deps...)
+}
+
+func (m *impactTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	inputs := PathsForModuleSrc(ctx, m.properties.Srcs)
+	ctx.VisitDirectDepsWithTag(impactTestDepTag, func(dep Module) {
+		inputs = append(inputs, dep.(*impactTestModule).output)
+	})
+
+	output := PathForModuleOut(ctx, "gen", ctx.ModuleName())
+	rule := NewRuleBuilder(pctx, ctx)
+	rule.Command().Text("cat").Inputs(inputs).FlagWithOutput("> ", output)
+	rule.Build("gen", "gen")
+
+	m.output = PathForModuleOut(ctx, ctx.ModuleName())
+	ctx.Build(pctx, BuildParams{
+		Rule:   Cp,
+		Input:  output,
+		Output: m.output.(WritablePath),
+	})
+}
+
+func impactTestModuleFactory() Module {
+	m := &impactTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidModule(m)
+	return m
+}
+
+var prepareForImpactTest = GroupFixturePreparers(
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("impact_test", impactTestModuleFactory)
+	}),
+	FixtureMergeMockFs(MockFS{
+		"a/x.txt":   nil,
+		"a/y.txt":   nil,
+		"other.txt": nil,
+	}),
+	FixtureWithRootAndroidBp(`
+		impact_test {
+			name: "a",
+			srcs: ["a/*.txt"],
+		}
+
+		impact_test {
+			name: "b",
+			deps: ["a"],
+		}
+
+		impact_test {
+			name: "c",
+			srcs: ["other.txt"],
+		}
+	`),
+)
+
+func TestImpactOf(t *testing.T) {
+	result := prepareForImpactTest.RunTest(t)
+
+	impact := ImpactOf(result.TestContext.Context, "./a/x.txt")
+	AssertStringEquals(t, "path", "a/x.txt", impact.Path)
+	AssertBoolEquals(t, "reruns soong_build", false, impact.RerunsSoongBuild)
+	AssertDeepEquals(t, "globs", []string{"a/*.txt"}, impact.Globs)
+	AssertDeepEquals(t, "modules", []ImpactedModule{
+		{Name: "a", Dir: ".", Direct: true, Actions: 2},
+		{Name: "b", Dir: ".", Direct: false, Actions: 2},
+	}, impact.Modules)
+	AssertIntEquals(t, "actions", 4, impact.Actions)
+
+	impact = ImpactOf(result.TestContext.Context, "other.txt")
+	if impact.Globs != nil {
+		t.Errorf("expected no globs, got %q", impact.Globs)
+	}
+	AssertDeepEquals(t, "modules", []ImpactedModule{
+		{Name: "c", Dir: ".", Direct: true, Actions: 2},
+	}, impact.Modules)
+	AssertIntEquals(t, "actions", 2, impact.Actions)
+}
+
+func TestImpactOfBlueprintFile(t *testing.T) {
+	result := prepareForImpactTest.RunTest(t)
+
+	impact := ImpactOf(result.TestContext.Context, "Android.bp")
+	AssertBoolEquals(t, "reruns soong_build", true, impact.RerunsSoongBuild)
+	AssertIntEquals(t, "actions", 0, impact.Actions)
+}
+
+func TestWriteImpact(t *testing.T) {
+	impact := &Impact{
+		Path:    "a/x.txt",
+		Modules: []ImpactedModule{{Name: "a", Dir: "a", Direct: true, Actions: 1}},
+		Actions: 1,
+	}
+	var buf bytes.Buffer
+	if err := WriteImpact(&buf, impact); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "Path": "a/x.txt",
+  "RerunsSoongBuild": false,
+  "Modules": [
+    {
+      "Name": "a",
+      "Dir": "a",
+      "Direct": true,
+      "Actions": 1
+    }
+  ],
+  "Actions": 1
+}
+`
+	if !reflect.DeepEqual(expected, buf.String()) {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func init() {
+	RegisterInstallConflictsBuildComponents(InitRegistrationContext)
+}
+
+func RegisterInstallConflictsBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("install_conflicts", installConflictsSingletonFactory)
+}
+
+var PrepareForTestWithInstallConflicts = FixtureRegisterWithContext(RegisterInstallConflictsBuildComponents)
+
+func installConflictsSingletonFactory() Singleton {
+	return &installConflictsSingleton{}
+}
+
+// installConflictsSingleton reports install paths that are installed by more than one module
+// during analysis, instead of leaving it to ninja or Make to fail on the duplicate rules without
+// saying where the modules came from.
+type installConflictsSingleton struct{}
+
+// installOwner describes a module that installs to a path.
+type installOwner struct {
+	name             string
+	variant          string
+	blueprintFile    string
+	productVariables []string
+}
+
+func (o installOwner) String() string {
+	s := fmt.Sprintf("%q", o.name)
+	if o.variant != "" {
+		s += fmt.Sprintf(" variant %q", o.variant)
+	}
+	s += " defined in " + o.blueprintFile
+	if len(o.productVariables) > 0 {
+		s += ", enabled with " + strings.Join(o.productVariables, ", ")
+	} else {
+		s += ", not conditional on product variables"
+	}
+	return s
+}
+
+func (s *installConflictsSingleton) GenerateBuildActions(ctx SingletonContext) {
+	owners := make(map[string][]installOwner)
+
+	ctx.VisitAllModules(func(module Module) {
+		if !module.Enabled() {
+			return
+		}
+		base := module.base()
+		owner := installOwner{
+			name:             ctx.ModuleName(module),
+			variant:          ctx.ModuleSubDir(module),
+			blueprintFile:    ctx.BlueprintFile(module),
+			productVariables: base.appliedProductVariables,
+		}
+		seen := make(map[string]bool)
+		for _, install := range base.installFiles {
+			// Key on the path relative to the output directory, which is also the form used by
+			// the allowlist.
+			path := install.path
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			owners[path] = append(owners[path], owner)
+		}
+	})
+
+	product := "<none>"
+	if ctx.Config().HasDeviceProduct() {
+		product = ctx.Config().DeviceProduct()
+	}
+
+	for _, path := range SortedStringKeys(owners) {
+		if len(owners[path]) < 2 || ctx.Config().AllowedDuplicateInstall(path) {
+			continue
+		}
+		pathOwners := owners[path]
+		sort.SliceStable(pathOwners, func(i, j int) bool {
+			return pathOwners[i].String() < pathOwners[j].String()
+		})
+		sb := strings.Builder{}
+		fmt.Fprintf(&sb, "%q is installed by multiple modules in product %q:", path, product)
+		for _, owner := range pathOwners {
+			sb.WriteString("\n    ")
+			sb.WriteString(owner.String())
+		}
+		sb.WriteString("\nIf this is intentional, add the path to AllowedDuplicateInstalls for the product.")
+		ctx.Errorf("%s", sb.String())
+	}
+}
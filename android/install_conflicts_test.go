@@ -0,0 +1,113 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+)
+
+type installConflictsTestModule struct {
+	ModuleBase
+	properties struct {
+		Stem *string
+	}
+}
+
+func (m *installConflictsTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	outputFile := PathForModuleOut(ctx, ctx.ModuleName())
+	ctx.Build(pctx, BuildParams{
+		Rule:   Touch,
+		Output: outputFile,
+	})
+	stem := proptools.StringDefault(m.properties.Stem, ctx.ModuleName())
+	ctx.InstallFile(PathForModuleInstall(ctx, "bin"), stem, outputFile)
+}
+
+func installConflictsTestModuleFactory() Module {
+	m := &installConflictsTestModule{}
+	m.AddProperties(&m.properties)
+	m.variableProperties = struct {
+		Product_variables struct {
+			Eng struct {
+				Stem *string
+			}
+		}
+	}{}
+	InitAndroidModule(m)
+	return m
+}
+
+var prepareForInstallConflictsTest = GroupFixturePreparers(
+	PrepareForTestWithInstallConflicts,
+	PrepareForTestWithVariables,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("test_install", installConflictsTestModuleFactory)
+	}),
+	FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+		variables.Eng = proptools.BoolPtr(true)
+	}),
+)
+
+func TestInstallConflicts(t *testing.T) {
+	bp := `
+		test_install {
+			name: "foo",
+		}
+
+		test_install {
+			name: "bar",
+			product_variables: {
+				eng: {
+					stem: "foo",
+				},
+			},
+		}
+
+		test_install {
+			name: "baz",
+		}
+	`
+
+	prepareForInstallConflictsTest.
+		ExtendWithErrorHandler(FixtureExpectsAllErrorsToMatchAPattern([]string{
+			regexp.QuoteMeta(`"target/product/test_device/system/bin/foo" is installed by multiple modules in product "test_product":` +
+				"\n    \"bar\" defined in Android.bp, enabled with product_variables.eng" +
+				"\n    \"foo\" defined in Android.bp, not conditional on product variables"),
+		})).
+		RunTestWithBp(t, bp)
+}
+
+func TestInstallConflictsAllowed(t *testing.T) {
+	bp := `
+		test_install {
+			name: "foo",
+		}
+
+		test_install {
+			name: "bar",
+			stem: "foo",
+		}
+	`
+
+	GroupFixturePreparers(
+		prepareForInstallConflictsTest,
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.AllowedDuplicateInstalls = []string{"target/product/test_device/system/bin/foo"}
+		}),
+	).RunTestWithBp(t, bp)
+}
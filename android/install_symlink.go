@@ -0,0 +1,157 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/blueprint/proptools"
+)
+
+func init() {
+	RegisterInstallSymlinkBuildComponents(InitRegistrationContext)
+}
+
+func RegisterInstallSymlinkBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterModuleType("install_symlink", InstallSymlinkFactory)
+	ctx.RegisterSingletonType("install_symlinks", installSymlinksSingletonFactory)
+}
+
+var PrepareForTestWithInstallSymlink = FixtureRegisterWithContext(RegisterInstallSymlinkBuildComponents)
+
+type installSymlinkProperties struct {
+	// Where the symlink is installed, relative to the root of the partition of the module, e.g.
+	// "bin/sh".
+	Installed_location *string
+
+	// The target of the symlink, either an absolute path on the device, e.g. "/system/bin/mksh",
+	// or a path relative to the directory of the symlink.
+	Symlink_target *string
+
+	// Whether to check that a module installs the target of the symlink on the device. Targets in
+	// /apex are only resolved at runtime and are never checked. Set it to false for targets that
+	// are installed by Make. Defaults to true.
+	Check_target *bool
+}
+
+type installSymlink struct {
+	ModuleBase
+
+	properties installSymlinkProperties
+
+	// The path of the symlink and of its target on the device, for the installSymlinksSingleton.
+	devicePath       string
+	deviceTargetPath string
+}
+
+// install_symlink installs a symlink on the device, and checks that a module installs its target
+// on the device for the current product. The partition of the symlink is set with the usual
+// properties, e.g. vendor: true.
+func InstallSymlinkFactory() Module {
+	module := &installSymlink{}
+	module.AddProperties(&module.properties)
+	InitAndroidArchModule(module, DeviceSupported, MultilibCommon)
+	return module
+}
+
+func (m *installSymlink) GenerateAndroidBuildActions(ctx ModuleContext) {
+	location := proptools.String(m.properties.Installed_location)
+	if location == "" {
+		ctx.PropertyErrorf("installed_location", "missing the location of the symlink")
+		return
+	}
+	if filepath.IsAbs(location) || location != path.Clean(location) || strings.HasPrefix(location, "../") {
+		ctx.PropertyErrorf("installed_location", "must be a clean path relative to the root of the partition, got %q", location)
+		return
+	}
+	target := proptools.String(m.properties.Symlink_target)
+	if target == "" {
+		ctx.PropertyErrorf("symlink_target", "missing the target of the symlink")
+		return
+	}
+
+	installPath := ctx.InstallAbsoluteSymlink(PathForModuleInstall(ctx, path.Dir(location)), path.Base(location), target)
+
+	if !proptools.BoolDefault(m.properties.Check_target, true) {
+		return
+	}
+	devicePath, ok := installPath.devicePath(ctx.Config())
+	if !ok {
+		return
+	}
+	m.devicePath = devicePath
+	if path.IsAbs(target) {
+		m.deviceTargetPath = path.Clean(target)
+	} else {
+		m.deviceTargetPath = path.Join(path.Dir(devicePath), target)
+	}
+	if m.deviceTargetPath == m.devicePath {
+		ctx.PropertyErrorf("symlink_target", "the symlink %s points to itself", devicePath)
+	}
+}
+
+// devicePath returns the path of an installed file on the device, e.g. /system/bin/sh, or false if
+// the file is not installed on the device.
+func (p InstallPath) devicePath(config Config) (string, bool) {
+	productDir := path.Join("target", "product", config.DeviceName()) + "/"
+	if !strings.HasPrefix(p.path, productDir) {
+		return "", false
+	}
+	return "/" + strings.TrimPrefix(p.path, productDir), true
+}
+
+func installSymlinksSingletonFactory() Singleton {
+	return &installSymlinksSingleton{}
+}
+
+// installSymlinksSingleton reports the install_symlink modules whose target is not installed on
+// the device by any module.
+type installSymlinksSingleton struct{}
+
+func (s *installSymlinksSingleton) GenerateBuildActions(ctx SingletonContext) {
+	// The files installed on the device, and their directories.
+	installed := make(map[string]bool)
+	var symlinks []*installSymlink
+	ctx.VisitAllModules(func(module Module) {
+		if !module.Enabled() {
+			return
+		}
+		if symlink, ok := module.(*installSymlink); ok && symlink.deviceTargetPath != "" {
+			symlinks = append(symlinks, symlink)
+		}
+		for _, file := range module.FilesToInstall() {
+			devicePath, ok := file.devicePath(ctx.Config())
+			if !ok {
+				continue
+			}
+			for p := devicePath; p != "/" && !installed[p]; p = path.Dir(p) {
+				installed[p] = true
+			}
+		}
+	})
+
+	for _, symlink := range symlinks {
+		target := symlink.deviceTargetPath
+		if target == "/apex" || strings.HasPrefix(target, "/apex/") {
+			continue
+		}
+		if !installed[target] {
+			ctx.ModuleErrorf(symlink, "the target %s of the symlink %s is not installed on the device by any module, "+
+				"set check_target: false if it is installed by Make", target, symlink.devicePath)
+		}
+	}
+}
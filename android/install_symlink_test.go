@@ -0,0 +1,122 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"regexp"
+	"testing"
+)
+
+var prepareForInstallSymlinkTest = GroupFixturePreparers(
+	PrepareForTestWithArchMutator,
+	PrepareForTestWithInstallSymlink,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("test_install", installConflictsTestModuleFactory)
+	}),
+)
+
+func TestInstallSymlink(t *testing.T) {
+	bp := `
+		test_install {
+			name: "mksh",
+		}
+
+		install_symlink {
+			name: "sh_symlink",
+			installed_location: "bin/sh",
+			symlink_target: "/system/bin/mksh",
+		}
+
+		install_symlink {
+			name: "relative_symlink",
+			installed_location: "xbin/sh",
+			symlink_target: "../bin/sh",
+		}
+
+		install_symlink {
+			name: "dir_symlink",
+			installed_location: "etc/bin",
+			symlink_target: "/system/bin",
+		}
+
+		install_symlink {
+			name: "apex_symlink",
+			installed_location: "bin/linker",
+			symlink_target: "/apex/com.android.runtime/bin/linker",
+		}
+
+		install_symlink {
+			name: "make_symlink",
+			installed_location: "bin/toybox_vendor",
+			symlink_target: "/vendor/bin/toybox",
+			check_target: false,
+		}
+	`
+
+	result := prepareForInstallSymlinkTest.RunTestWithBp(t, bp)
+
+	symlink := result.ModuleForTests("sh_symlink", "android_common").Output("out/soong/target/product/test_device/system/bin/sh")
+	AssertStringEquals(t, "symlink target", "/system/bin/mksh", symlink.Args["fromPath"])
+
+	relative := result.ModuleForTests("relative_symlink", "android_common").Output("out/soong/target/product/test_device/system/xbin/sh")
+	AssertStringEquals(t, "relative symlink target", "../bin/sh", relative.Args["fromPath"])
+}
+
+func TestInstallSymlinkMissingTarget(t *testing.T) {
+	bp := `
+		install_symlink {
+			name: "sh_symlink",
+			installed_location: "bin/sh",
+			symlink_target: "/system/bin/mksh",
+		}
+
+		install_symlink {
+			name: "vendor_symlink",
+			vendor: true,
+			installed_location: "bin/sh",
+			symlink_target: "mksh",
+		}
+	`
+
+	prepareForInstallSymlinkTest.
+		ExtendWithErrorHandler(FixtureExpectsAllErrorsToMatchAPattern([]string{
+			regexp.QuoteMeta(`the target /system/bin/mksh of the symlink /system/bin/sh is not installed on the device by any module`),
+			regexp.QuoteMeta(`the target /vendor/bin/mksh of the symlink /vendor/bin/sh is not installed on the device by any module`),
+		})).
+		RunTestWithBp(t, bp)
+}
+
+func TestInstallSymlinkErrors(t *testing.T) {
+	bp := `
+		install_symlink {
+			name: "self_symlink",
+			installed_location: "bin/self",
+			symlink_target: "self",
+		}
+
+		install_symlink {
+			name: "bad_location",
+			installed_location: "../bin/sh",
+			symlink_target: "/system/bin/mksh",
+		}
+	`
+
+	prepareForInstallSymlinkTest.
+		ExtendWithErrorHandler(FixtureExpectsAllErrorsToMatchAPattern([]string{
+			regexp.QuoteMeta(`symlink_target: the symlink /system/bin/self points to itself`),
+			regexp.QuoteMeta(`installed_location: must be a clean path relative to the root of the partition, got "../bin/sh"`),
+		})).
+		RunTestWithBp(t, bp)
+}
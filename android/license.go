@@ -15,9 +15,6 @@
 package android
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/google/blueprint"
 
 	"android/soong/bazel"
@@ -71,6 +68,14 @@ type bazelLicenseAttributes struct {
 	Visibility       []string
 }
 
+// The genrule that concatenates the license texts of a license.
+type bazelLicenseTextAttributes struct {
+	Srcs                bazel.LabelListAttribute
+	Outs                []string
+	Cmd                 string
+	Applicable_licenses bazel.LabelListAttribute
+}
+
 func (m *licenseModule) ConvertWithBp2build(ctx TopDownMutatorContext) {
 	attrs := &bazelLicenseAttributes{
 		License_kinds:    m.properties.License_kinds,
@@ -79,15 +84,26 @@ func (m *licenseModule) ConvertWithBp2build(ctx TopDownMutatorContext) {
 		Visibility:       m.properties.Visibility,
 	}
 
-	// TODO(asmundak): Soong supports multiple license texts while Bazel's license
-	// rule does not. Have android_license create a genrule to concatenate multiple
-	// license texts.
-	if len(m.properties.License_text) > 1 && ctx.Config().IsEnvTrue("BP2BUILD_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "warning: using only the first license_text item from //%s:%s\n",
-			ctx.ModuleDir(), m.Name())
-	}
-	if len(m.properties.License_text) >= 1 {
+	// Bazel's license rule supports a single license text, multiple license texts are concatenated
+	// into one.
+	if len(m.properties.License_text) == 1 {
 		attrs.License_text.SetValue(BazelLabelForModuleSrcSingle(ctx, m.properties.License_text[0]))
+	} else if len(m.properties.License_text) > 1 {
+		name := m.Name() + "_license_text"
+		ctx.CreateBazelTargetModule(
+			bazel.BazelTargetModuleProperties{
+				Rule_class: "genrule",
+			},
+			CommonAttributes{Name: name},
+			&bazelLicenseTextAttributes{
+				Srcs: bazel.MakeLabelListAttribute(BazelLabelForModuleSrc(ctx, m.properties.License_text)),
+				Outs: []string{name + ".txt"},
+				Cmd:  "cat $(SRCS) > $(OUTS)",
+				// The license text must not have the package's licenses, which may include this
+				// license, otherwise Bazel reports a cyclic reference error.
+				Applicable_licenses: bazel.LabelListAttribute{Value: bazel.LabelList{Includes: []bazel.Label{}}, EmitEmptyList: true},
+			})
+		attrs.License_text.SetValue(bazel.Label{Label: ":" + name})
 	}
 
 	ctx.CreateBazelTargetModule(
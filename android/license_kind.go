@@ -67,6 +67,21 @@ func (m *licenseKindModule) ConvertWithBp2build(ctx TopDownMutatorContext) {
 		attrs)
 }
 
+// bazelLicenseType returns the license type of Bazel's licenses() function that corresponds to a
+// license condition, or false if there is none.
+func bazelLicenseType(condition string) (string, bool) {
+	switch condition {
+	case "notice", "permissive", "reciprocal", "restricted", "unencumbered", "by_exception_only":
+		return condition, true
+	case "restricted_if_statically_linked", "restricted_allows_dynamic_linking":
+		return "restricted", true
+	case "proprietary", "not_allowed":
+		return "by_exception_only", true
+	default:
+		return "", false
+	}
+}
+
 func (m *licenseKindModule) DepsMutator(ctx BottomUpMutatorContext) {
 	// Nothing to do.
 }
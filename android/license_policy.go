@@ -0,0 +1,286 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The license policy of a product, set with the LicensePolicyFile product variable, lists the
+// dependencies that the licenses of the modules don't allow, e.g. proprietary vendor code linking
+// restricted code statically, based on the license kinds and conditions that the license and
+// license_kind modules give the modules. Every direct dependency between two modules is checked
+// against the rules of the policy, and a dependency that breaks a rule is an error of the
+// dependent module that names both modules.
+//
+// The policy file is JSON:
+//
+//	{
+//	    "rules": [
+//	        {
+//	            "name": "no-restricted-in-vendor",
+//	            "description": "Proprietary vendor code can't link restricted code statically.",
+//	            "dependent": {
+//	                "partitions": ["vendor", "odm"],
+//	                "conditions": ["proprietary", "by_exception_only"]
+//	            },
+//	            "dependency": {
+//	                "conditions": ["restricted", "restricted_if_statically_linked"],
+//	                "link_types": ["static"]
+//	            }
+//	        },
+//	        {
+//	            "name": "apex-notices",
+//	            "dependent": {"module_types": ["apex"]},
+//	            "dependency": {"conditions": ["notice"]},
+//	            "require_license_text": true
+//	        }
+//	    ]
+//	}
+//
+// A rule applies to a dependency when the dependent module matches the "dependent" selector and
+// the dependency matches the "dependency" selector. The dependency breaks the rule, unless the
+// rule sets "require_license_text", in which case it only breaks the rule if no license text
+// applies to the dependency, so that its notice can't be shipped.
+
+func init() {
+	RegisterLicensePolicyBuildComponents(InitRegistrationContext)
+}
+
+func RegisterLicensePolicyBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("license_policy", licensePolicySingletonFactory)
+}
+
+var PrepareForTestWithLicensePolicy = FixtureRegisterWithContext(RegisterLicensePolicyBuildComponents)
+
+// LicensePolicySelector selects modules by their properties. A module matches when it matches
+// every list that is set, and it matches a list when any of the values applies to it.
+type LicensePolicySelector struct {
+	// The module types, e.g. "apex".
+	ModuleTypes []string `json:"module_types,omitempty"`
+	// The partitions of device modules, e.g. "vendor". Host modules never match.
+	Partitions []string `json:"partitions,omitempty"`
+	// The license kinds, e.g. "SPDX-license-identifier-GPL-2.0".
+	Kinds []string `json:"kinds,omitempty"`
+	// The license conditions, e.g. "restricted".
+	Conditions []string `json:"conditions,omitempty"`
+	// How the dependent module links against the variant of the dependency, e.g. "static" or
+	// "shared". Only applies to dependencies.
+	LinkTypes []string `json:"link_types,omitempty"`
+}
+
+// LicensePolicyRule is a rule of a license policy file.
+type LicensePolicyRule struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	Dependent   LicensePolicySelector `json:"dependent"`
+	Dependency  LicensePolicySelector `json:"dependency"`
+	// Only report the dependencies that have no license text.
+	RequireLicenseText bool `json:"require_license_text,omitempty"`
+}
+
+// LicensePolicy is the content of a license policy file.
+type LicensePolicy struct {
+	Rules []LicensePolicyRule `json:"rules"`
+}
+
+// licensePolicyModule holds the properties of a module that the selectors of a license policy
+// match.
+type licensePolicyModule struct {
+	name       string
+	moduleType string
+	// The partition of a device module, or "" for a host module.
+	partition  string
+	kinds      []string
+	conditions []string
+	linkType   string
+}
+
+func anyInList(values []string, list []string) bool {
+	for _, v := range values {
+		if InList(v, list) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s LicensePolicySelector) matches(m licensePolicyModule) bool {
+	if len(s.ModuleTypes) > 0 && !InList(m.moduleType, s.ModuleTypes) {
+		return false
+	}
+	if len(s.Partitions) > 0 && (m.partition == "" || !InList(m.partition, s.Partitions)) {
+		return false
+	}
+	if len(s.Kinds) > 0 && !anyInList(m.kinds, s.Kinds) {
+		return false
+	}
+	if len(s.Conditions) > 0 && !anyInList(m.conditions, s.Conditions) {
+		return false
+	}
+	if len(s.LinkTypes) > 0 && !InList(m.linkType, s.LinkTypes) {
+		return false
+	}
+	return true
+}
+
+// String describes the module for the errors, e.g.
+// "libfoo" (static, vendor, kinds: SPDX-license-identifier-GPL-2.0, conditions: restricted).
+func (m licensePolicyModule) String() string {
+	var details []string
+	if m.linkType != "" {
+		details = append(details, m.linkType)
+	}
+	if m.partition != "" {
+		details = append(details, m.partition)
+	}
+	kinds, conditions := "none", "none"
+	if len(m.kinds) > 0 {
+		kinds = strings.Join(m.kinds, " ")
+	}
+	if len(m.conditions) > 0 {
+		conditions = strings.Join(m.conditions, " ")
+	}
+	details = append(details, "kinds: "+kinds, "conditions: "+conditions)
+	return fmt.Sprintf("%q (%s)", m.name, strings.Join(details, ", "))
+}
+
+func readLicensePolicy(ctx SingletonContext, file string) (*LicensePolicy, error) {
+	ctx.AddNinjaFileDeps(file)
+	r, err := ctx.Config().fs.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var policy LicensePolicy
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	names := make(map[string]bool)
+	for i, rule := range policy.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("%s: rule %d has no name", file, i)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("%s: duplicate rule %q", file, rule.Name)
+		}
+		names[rule.Name] = true
+		if len(rule.Dependent.LinkTypes) > 0 {
+			return nil, fmt.Errorf("%s: rule %q: link_types only applies to dependencies", file, rule.Name)
+		}
+	}
+	return &policy, nil
+}
+
+func licensePolicySingletonFactory() Singleton {
+	return &licensePolicySingleton{}
+}
+
+type licensePolicySingleton struct{}
+
+func (s *licensePolicySingleton) GenerateBuildActions(ctx SingletonContext) {
+	file := ctx.Config().LicensePolicyFile()
+	if file == "" {
+		return
+	}
+	policy, err := readLicensePolicy(ctx, file)
+	if err != nil {
+		ctx.Errorf("failed to read the license policy: %s", err)
+		return
+	}
+	if len(policy.Rules) == 0 {
+		return
+	}
+
+	describe := func(module Module) licensePolicyModule {
+		m := licensePolicyModule{
+			name:       ctx.ModuleName(module),
+			moduleType: ctx.ModuleType(module),
+			kinds:      module.base().commonProperties.Effective_license_kinds,
+			conditions: module.base().commonProperties.Effective_license_conditions,
+		}
+		if module.Target().Os.Class == Device {
+			m.partition = module.PartitionTag(ctx.DeviceConfig())
+		}
+		return m
+	}
+	// The license and license_kind modules that the modules depend on aren't code.
+	isLicense := func(module Module) bool {
+		switch module.(type) {
+		case *licenseModule, *licenseKindModule:
+			return true
+		}
+		return false
+	}
+
+	reported := make(map[string]bool)
+	ctx.VisitAllModules(func(module Module) {
+		if !module.Enabled() || isLicense(module) {
+			return
+		}
+		dependent := describe(module)
+		var rules []LicensePolicyRule
+		for _, rule := range policy.Rules {
+			if rule.Dependent.matches(dependent) {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) == 0 {
+			return
+		}
+		ctx.VisitDirectDeps(module, func(dep Module) {
+			if !dep.Enabled() || isLicense(dep) {
+				return
+			}
+			dependency := describe(dep)
+			if c, ok := dep.(ApiLockContributor); ok {
+				dependency.linkType = c.ApiLockLinkType()
+			}
+			for _, rule := range rules {
+				if !rule.Dependency.matches(dependency) {
+					continue
+				}
+				if rule.RequireLicenseText && len(dep.base().commonProperties.Effective_license_text) > 0 {
+					continue
+				}
+				key := dependent.name + "\x00" + dependency.name + "\x00" + rule.Name
+				if reported[key] {
+					continue
+				}
+				reported[key] = true
+				problem := "depends on"
+				if rule.RequireLicenseText {
+					problem = "depends on a module without license text,"
+				}
+				msg := fmt.Sprintf("breaks license policy rule %q of %s: %s %s %s",
+					rule.Name, file, dependent, problem, dependency)
+				if rule.Description != "" {
+					msg += "\n" + rule.Description
+				}
+				ctx.ModuleErrorf(module, "%s", msg)
+			}
+		})
+	})
+}
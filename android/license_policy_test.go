@@ -0,0 +1,202 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+)
+
+type licensePolicyTestModule struct {
+	ModuleBase
+	properties struct {
+		Deps      []string
+		Link_type *string
+	}
+}
+
+func (m *licensePolicyTestModule) DepsMutator(ctx BottomUpMutatorContext) {
+	ctx.AddVariationDependencies(nil, dependencyLicensesTag{name: "deps"}, m.properties.Deps...)
+}
+
+func (m *licensePolicyTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {}
+
+func (m *licensePolicyTestModule) ApiLockLinkType() string {
+	return proptools.String(m.properties.Link_type)
+}
+
+func (m *licensePolicyTestModule) ApiLockStability() []string {
+	return nil
+}
+
+func licensePolicyTestModuleFactory() Module {
+	m := &licensePolicyTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidArchModule(m, DeviceSupported, MultilibCommon)
+	return m
+}
+
+const licensePolicyTestBp = `
+	license_kind {
+		name: "SPDX-license-identifier-GPL-2.0",
+		conditions: ["restricted"],
+	}
+
+	license_kind {
+		name: "SPDX-license-identifier-Apache-2.0",
+		conditions: ["notice"],
+	}
+
+	license_kind {
+		name: "legacy_proprietary",
+		conditions: ["proprietary"],
+	}
+
+	license {
+		name: "gpl",
+		license_kinds: ["SPDX-license-identifier-GPL-2.0"],
+		license_text: ["COPYING"],
+	}
+
+	license {
+		name: "apache",
+		license_kinds: ["SPDX-license-identifier-Apache-2.0"],
+	}
+
+	license {
+		name: "proprietary",
+		license_kinds: ["legacy_proprietary"],
+	}
+
+	policy_test_module {
+		name: "vendor_bin",
+		vendor: true,
+		licenses: ["proprietary"],
+		deps: ["libgpl_static", "libgpl_shared", "libapache"],
+	}
+
+	policy_test_module {
+		name: "system_bin",
+		licenses: ["proprietary"],
+		deps: ["libgpl_static"],
+	}
+
+	policy_test_module {
+		name: "libgpl_static",
+		licenses: ["gpl"],
+		link_type: "static",
+	}
+
+	policy_test_module {
+		name: "libgpl_shared",
+		licenses: ["gpl"],
+		link_type: "shared",
+	}
+
+	policy_test_module {
+		name: "libapache",
+		licenses: ["apache"],
+		link_type: "shared",
+	}
+`
+
+const licensePolicyTestPolicy = `{
+	"rules": [
+		{
+			"name": "no-restricted-in-vendor",
+			"description": "Proprietary vendor code can't link restricted code statically.",
+			"dependent": {
+				"partitions": ["vendor"],
+				"conditions": ["proprietary"]
+			},
+			"dependency": {
+				"conditions": ["restricted"],
+				"link_types": ["static"]
+			}
+		},
+		{
+			"name": "notices",
+			"dependent": {"partitions": ["vendor"]},
+			"dependency": {"conditions": ["notice", "restricted"]},
+			"require_license_text": true
+		}
+	]
+}`
+
+func testLicensePolicy(t *testing.T, policy string, errorHandler FixtureErrorHandler) {
+	t.Helper()
+	GroupFixturePreparers(
+		PrepareForTestWithLicenses,
+		PrepareForTestWithLicensePolicy,
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("policy_test_module", licensePolicyTestModuleFactory)
+		}),
+		FixtureWithRootAndroidBp(licensePolicyTestBp),
+		FixtureMergeMockFs(MockFS{
+			"COPYING":             nil,
+			"license_policy.json": []byte(policy),
+		}),
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.LicensePolicyFile = proptools.StringPtr("license_policy.json")
+		}),
+	).ExtendWithErrorHandler(errorHandler).RunTest(t)
+}
+
+func TestLicensePolicy(t *testing.T) {
+	testLicensePolicy(t, licensePolicyTestPolicy, FixtureExpectsAllErrorsToMatchAPattern([]string{
+		`breaks license policy rule "no-restricted-in-vendor" of license_policy.json: ` +
+			`"vendor_bin" \(vendor, kinds: legacy_proprietary, conditions: proprietary\) depends on ` +
+			`"libgpl_static" \(static, system, kinds: SPDX-license-identifier-GPL-2.0, conditions: restricted\)\n` +
+			`Proprietary vendor code can't link restricted code statically.`,
+		`breaks license policy rule "notices" of license_policy.json: ` +
+			`"vendor_bin" .* depends on a module without license text, "libapache" \(shared, system, ` +
+			`kinds: SPDX-license-identifier-Apache-2.0, conditions: notice\)`,
+	}))
+}
+
+func TestLicensePolicyErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{
+			name:   "unknown field",
+			policy: `{"rules": [{"name": "foo", "dependency": {"license": ["restricted"]}}]}`,
+			err:    `license_policy.json: json: unknown field "license"`,
+		},
+		{
+			name:   "missing name",
+			policy: `{"rules": [{"dependency": {"conditions": ["restricted"]}}]}`,
+			err:    `license_policy.json: rule 0 has no name`,
+		},
+		{
+			name:   "duplicate name",
+			policy: `{"rules": [{"name": "foo"}, {"name": "foo"}]}`,
+			err:    `license_policy.json: duplicate rule "foo"`,
+		},
+		{
+			name:   "dependent link types",
+			policy: `{"rules": [{"name": "foo", "dependent": {"link_types": ["static"]}}]}`,
+			err:    `license_policy.json: rule "foo": link_types only applies to dependencies`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testLicensePolicy(t, tc.policy, FixtureExpectsAtLeastOneErrorMatchingPattern(tc.err))
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+)
+
+// This file lists the modules of soong_build --list_modules, which only parses the Android.bp
+// files, so that the modules of the tree are listed without a full analysis. The mutators only run
+// when the filter selects variants, as the variants are created by the mutators.
+
+// ModuleListFilter selects the modules of soong_build --list_modules. The filter is a
+// comma-separated list of terms:
+//
+//	type=<module type>
+//	  keep only the modules of the module type.
+//	path=<directory>
+//	  keep only the modules defined in the directory or in its subdirectories.
+//	variant=<pattern>
+//	  keep only the modules with a variant whose name, e.g. android_arm64_armv8-a_shared, matches
+//	  the regular expression, and only list the matching variants.
+//
+// A module is kept if it matches any of the terms of each kind that is set.
+type ModuleListFilter struct {
+	types    map[string]bool
+	paths    []string
+	variants []*regexp.Regexp
+}
+
+// ParseModuleListFilter parses the value of --list_modules_filter. An empty value returns a nil
+// filter, which keeps all the modules.
+func ParseModuleListFilter(s string) (*ModuleListFilter, error) {
+	var filter *ModuleListFilter
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		if filter == nil {
+			filter = &ModuleListFilter{}
+		}
+		key, value, hasValue := strings.Cut(term, "=")
+		if !hasValue || value == "" {
+			return nil, fmt.Errorf("invalid module list filter %q", term)
+		}
+		switch key {
+		case "type":
+			if filter.types == nil {
+				filter.types = make(map[string]bool)
+			}
+			filter.types[value] = true
+		case "path":
+			filter.paths = append(filter.paths, filepath.Clean(value))
+		case "variant":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid module list filter %q: %s", term, err)
+			}
+			filter.variants = append(filter.variants, re)
+		default:
+			return nil, fmt.Errorf("invalid module list filter %q", term)
+		}
+	}
+	return filter, nil
+}
+
+// NeedsVariants returns true if the filter selects variants, which are only known once the
+// mutators have run.
+func (f *ModuleListFilter) NeedsVariants() bool {
+	return f != nil && f.variants != nil
+}
+
+func (f *ModuleListFilter) matchesModule(moduleType, dir string) bool {
+	if f == nil {
+		return true
+	}
+	if f.types != nil && !f.types[moduleType] {
+		return false
+	}
+	if f.paths == nil {
+		return true
+	}
+	for _, path := range f.paths {
+		if path == "." || dir == path || strings.HasPrefix(dir, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *ModuleListFilter) matchesVariant(variant string) bool {
+	if f == nil || f.variants == nil {
+		return true
+	}
+	for _, re := range f.variants {
+		if re.MatchString(variant) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListedModule is a module listed by soong_build --list_modules.
+type ListedModule struct {
+	Name string
+	Type string
+	// The path of the Android.bp file that defines the module.
+	Blueprint string
+	// The names of the variants of the module that match the filter, empty if the mutators didn't
+	// run.
+	Variants []string
+}
+
+// ListModules returns the modules that match filter, sorted by name and Android.bp file.
+func ListModules(ctx *Context, filter *ModuleListFilter) []ListedModule {
+	type key struct{ name, blueprint string }
+	modules := make(map[key]*ListedModule)
+	ctx.VisitAllModules(func(module blueprint.Module) {
+		k := key{ctx.ModuleName(module), ctx.BlueprintFile(module)}
+		moduleType := ctx.ModuleType(module)
+		if !filter.matchesModule(moduleType, filepath.Dir(k.blueprint)) {
+			return
+		}
+		variant := ctx.ModuleSubDir(module)
+		if !filter.matchesVariant(variant) {
+			return
+		}
+		if modules[k] == nil {
+			modules[k] = &ListedModule{Name: k.name, Type: moduleType, Blueprint: k.blueprint}
+		}
+		// The modules aren't split into variants before the mutators run.
+		if variant != "" {
+			modules[k].Variants = append(modules[k].Variants, variant)
+		}
+	})
+
+	ret := make([]ListedModule, 0, len(modules))
+	for _, m := range modules {
+		sort.Strings(m.Variants)
+		ret = append(ret, *m)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Name != ret[j].Name {
+			return ret[i].Name < ret[j].Name
+		}
+		return ret[i].Blueprint < ret[j].Blueprint
+	})
+	return ret
+}
+
+// WriteModuleList writes the modules, one per line, with tab-separated columns: the name, the
+// module type, the Android.bp file and the space-separated variants of the module.
+func WriteModuleList(w io.Writer, modules []ListedModule) error {
+	for _, m := range modules {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Name, m.Type, m.Blueprint, strings.Join(m.Variants, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"strings"
+	"testing"
+)
+
+type listModulesTestModule struct {
+	ModuleBase
+}
+
+func (m *listModulesTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {}
+
+func listModulesTestModuleFactory() Module {
+	m := &listModulesTestModule{}
+	InitAndroidArchModule(m, HostAndDeviceDefault, MultilibFirst)
+	return m
+}
+
+func TestListModules(t *testing.T) {
+	result := GroupFixturePreparers(
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("list_library", listModulesTestModuleFactory)
+			ctx.RegisterModuleType("list_binary", listModulesTestModuleFactory)
+		}),
+		FixtureWithRootAndroidBp(`
+			list_binary {
+				name: "bin",
+			}
+		`),
+		FixtureAddTextFile("external/foo/Android.bp", `
+			list_library {
+				name: "libfoo",
+			}
+		`),
+		FixtureAddTextFile("external/foobar/Android.bp", `
+			list_library {
+				name: "libfoobar",
+			}
+		`),
+		FixtureAddTextFile("system/bar/Android.bp", `
+			list_library {
+				name: "libbar",
+			}
+			list_binary {
+				name: "barbin",
+				device_supported: false,
+			}
+		`),
+	).RunTest(t)
+	ctx := result.TestContext.Context
+
+	names := func(modules []ListedModule) []string {
+		var ret []string
+		for _, m := range modules {
+			ret = append(ret, m.Name)
+		}
+		return ret
+	}
+
+	testCases := []struct {
+		filter   string
+		expected []string
+	}{
+		{"", []string{"barbin", "bin", "libbar", "libfoo", "libfoobar"}},
+		{"type=list_library", []string{"libbar", "libfoo", "libfoobar"}},
+		{"type=list_binary,type=list_library", []string{"barbin", "bin", "libbar", "libfoo", "libfoobar"}},
+		{"path=external/foo", []string{"libfoo"}},
+		{"path=external", []string{"libfoo", "libfoobar"}},
+		{"path=external/foo,path=system", []string{"barbin", "libbar", "libfoo"}},
+		{"path=.", []string{"barbin", "bin", "libbar", "libfoo", "libfoobar"}},
+		{"type=list_binary,path=system", []string{"barbin"}},
+		{"variant=^android", []string{"bin", "libbar", "libfoo", "libfoobar"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			filter, err := ParseModuleListFilter(tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, "modules", tc.expected, names(ListModules(ctx, filter)))
+		})
+	}
+
+	filter, _ := ParseModuleListFilter("path=system/bar,type=list_library,variant=^android")
+	modules := ListModules(ctx, filter)
+	AssertIntEquals(t, "modules", 1, len(modules))
+	AssertStringEquals(t, "type", "list_library", modules[0].Type)
+	AssertStringEquals(t, "blueprint", "system/bar/Android.bp", modules[0].Blueprint)
+	for _, variant := range modules[0].Variants {
+		if !strings.HasPrefix(variant, "android") {
+			t.Errorf("expected only the android variants, got %q", variant)
+		}
+	}
+
+	var out strings.Builder
+	if err := WriteModuleList(&out, modules); err != nil {
+		t.Fatal(err)
+	}
+	AssertStringEquals(t, "module list",
+		"libbar\tlist_library\tsystem/bar/Android.bp\t"+strings.Join(modules[0].Variants, " ")+"\n", out.String())
+}
+
+func TestListModulesWithoutMutators(t *testing.T) {
+	bp := `
+		list_library {
+			name: "libfoo",
+		}
+		list_binary {
+			name: "bin",
+		}
+	`
+	config := TestArchConfig(t.TempDir(), nil, bp, nil)
+	ctx := NewTestContext(config)
+	ctx.RegisterModuleType("list_library", listModulesTestModuleFactory)
+	ctx.RegisterModuleType("list_binary", listModulesTestModuleFactory)
+	ctx.Register()
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	FailIfErrored(t, errs)
+
+	filter, err := ParseModuleListFilter("type=list_library")
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertBoolEquals(t, "needs variants", false, filter.NeedsVariants())
+
+	var out strings.Builder
+	if err := WriteModuleList(&out, ListModules(ctx.Context, filter)); err != nil {
+		t.Fatal(err)
+	}
+	AssertStringEquals(t, "module list", "libfoo\tlist_library\tAndroid.bp\t\n", out.String())
+
+	filter, err = ParseModuleListFilter("type=list_library,variant=^android")
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertBoolEquals(t, "needs variants", true, filter.NeedsVariants())
+}
+
+func TestParseModuleListFilterErrors(t *testing.T) {
+	for _, filter := range []string{"cc_library", "type=", "arch=arm64", "variant=("} {
+		if _, err := ParseModuleListFilter(filter); err == nil {
+			t.Errorf("expected an error for %q", filter)
+		}
+	}
+}
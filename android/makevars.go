@@ -81,6 +81,11 @@ type BaseMakeVarsContext interface {
 	// directory on the build server with the given filename when any of the
 	// specified goals are built.
 	DistForGoalsWithFilename(goals []string, path Path, filename string)
+
+	// DistForGroup creates a rule to copy one or more Paths to the subdirectory of the
+	// artifacts directory for the named dist group when the group is selected with
+	// `m dist DIST_GROUPS=<group>`.
+	DistForGroup(group string, paths ...Path)
 }
 
 // MakeVarsContext contains the set of functions available for MakeVarsProvider
@@ -607,3 +612,9 @@ func (c *makeVarsContext) DistForGoals(goals []string, paths ...Path) {
 func (c *makeVarsContext) DistForGoalsWithFilename(goals []string, path Path, filename string) {
 	c.addDist(goals, []string{path.String() + ":" + filename})
 }
+
+func (c *makeVarsContext) DistForGroup(group string, paths ...Path) {
+	for _, path := range paths {
+		c.DistForGoalWithFilename(distGroupGoal(group), path, filepath.Join(group, path.Base()))
+	}
+}
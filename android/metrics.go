@@ -30,6 +30,12 @@ var soongMetricsOnceKey = NewOnceKey("soong metrics")
 type SoongMetrics struct {
 	Modules  int
 	Variants int
+
+	// The number of build actions of the modules.
+	Actions int
+
+	// The number of modules by module type.
+	ModuleTypes map[string]int
 }
 
 func readSoongMetrics(config Config) (SoongMetrics, bool) {
@@ -50,12 +56,14 @@ func soongMetricsSingletonFactory() Singleton { return soongMetricsSingleton{} }
 type soongMetricsSingleton struct{}
 
 func (soongMetricsSingleton) GenerateBuildActions(ctx SingletonContext) {
-	metrics := SoongMetrics{}
+	metrics := SoongMetrics{ModuleTypes: make(map[string]int)}
 	ctx.VisitAllModules(func(m Module) {
 		if ctx.PrimaryModule(m) == m {
 			metrics.Modules++
+			metrics.ModuleTypes[ctx.ModuleType(m)]++
 		}
 		metrics.Variants++
+		metrics.Actions += m.base().buildActions
 	})
 	ctx.Config().Once(soongMetricsOnceKey, func() interface{} {
 		return metrics
@@ -0,0 +1,226 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/blueprint/metrics"
+)
+
+// soong_build appends the key metrics of every analysis of the tree to
+// $OUT/soong/soong_build_history.jsonl, one JSON object per line, and keeps the last
+// metricsHistoryLength runs. When the analysis of a run takes significantly longer than the median
+// of the recent runs for the same product, it prints a hint with the module types whose number of
+// modules grew the most since the previous run, so that the developer who caused a regression of
+// the analysis time notices it.
+
+const (
+	metricsHistoryFileName = "soong_build_history.jsonl"
+	metricsHistoryLength   = 100
+
+	// The number of recent runs that the analysis time is compared with, and the minimum number of
+	// them that are needed to compare.
+	metricsHistoryWindow  = 10
+	metricsHistoryMinRuns = 3
+
+	// The analysis time regresses if it is both this much longer than the median of the recent runs
+	// and longer by at least analysisRegressionMinDuration.
+	analysisRegressionRatio       = 1.25
+	analysisRegressionMinDuration = 10 * time.Second
+)
+
+// MetricsHistoryEntry is a line of soong_build_history.jsonl, the key metrics of a run of
+// soong_build.
+type MetricsHistoryEntry struct {
+	// The end of the run in seconds since the Unix epoch.
+	Time int64 `json:"time"`
+
+	Product    string `json:"product"`
+	AnalysisMs int64  `json:"analysis_ms"`
+	Actions    int    `json:"actions"`
+	Modules    int    `json:"modules"`
+	Variants   int    `json:"variants"`
+
+	// The number of modules by module type.
+	ModuleTypes map[string]int `json:"module_types"`
+}
+
+func (e MetricsHistoryEntry) analysisTime() time.Duration {
+	return time.Duration(e.AnalysisMs) * time.Millisecond
+}
+
+func newMetricsHistoryEntry(config Config, eventHandler *metrics.EventHandler, now time.Time) MetricsHistoryEntry {
+	entry := MetricsHistoryEntry{
+		Time:    now.Unix(),
+		Product: config.DeviceProduct(),
+	}
+	for _, event := range eventHandler.CompletedEvents() {
+		if event.Id == "soong_build" || event.Id == "mixed_build" {
+			entry.AnalysisMs += int64(event.RuntimeNanoseconds() / uint64(time.Millisecond))
+		}
+	}
+	if soongMetrics, ok := readSoongMetrics(config); ok {
+		entry.Actions = soongMetrics.Actions
+		entry.Modules = soongMetrics.Modules
+		entry.Variants = soongMetrics.Variants
+		entry.ModuleTypes = soongMetrics.ModuleTypes
+	}
+	return entry
+}
+
+// readMetricsHistory reads soong_build_history.jsonl. Lines that can't be parsed, e.g. of an
+// interrupted write, are skipped.
+func readMetricsHistory(file string) ([]MetricsHistoryEntry, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var history []MetricsHistoryEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var entry MetricsHistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			history = append(history, entry)
+		}
+	}
+	return history, scanner.Err()
+}
+
+func writeMetricsHistory(file string, history []MetricsHistoryEntry) error {
+	buf := &bytes.Buffer{}
+	for _, entry := range history {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	tmpFile := file + ".tmp"
+	if err := os.WriteFile(tmpFile, buf.Bytes(), 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
+
+// analysisRegressionHint returns a hint if the analysis time of entry regressed compared to the
+// recent runs in history for the same product, or an empty string.
+func analysisRegressionHint(history []MetricsHistoryEntry, entry MetricsHistoryEntry) string {
+	var recent []MetricsHistoryEntry
+	for i := len(history) - 1; i >= 0 && len(recent) < metricsHistoryWindow; i-- {
+		if history[i].Product == entry.Product && history[i].AnalysisMs > 0 {
+			recent = append(recent, history[i])
+		}
+	}
+	if len(recent) < metricsHistoryMinRuns {
+		return ""
+	}
+
+	times := make([]time.Duration, len(recent))
+	for i, e := range recent {
+		times[i] = e.analysisTime()
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	median := times[len(times)/2]
+	if len(times)%2 == 0 {
+		median = (times[len(times)/2-1] + times[len(times)/2]) / 2
+	}
+
+	current := entry.analysisTime()
+	if float64(current) < float64(median)*analysisRegressionRatio ||
+		current-median < analysisRegressionMinDuration {
+		return ""
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "soong_build: the analysis took %s, %d%% longer than the median of the last %d runs for %s (%s).\n",
+		current.Round(time.Second), int((float64(current)/float64(median)-1)*100), len(recent), entry.Product,
+		median.Round(time.Second))
+
+	// recent[0] is the previous run.
+	previous := recent[0]
+	if entry.Variants != previous.Variants || entry.Actions != previous.Actions {
+		fmt.Fprintf(sb, "Since the previous run, module variants went from %d to %d and actions from %d to %d.\n",
+			previous.Variants, entry.Variants, previous.Actions, entry.Actions)
+	}
+	type growth struct {
+		moduleType string
+		added      int
+	}
+	var growths []growth
+	for moduleType, count := range entry.ModuleTypes {
+		if added := count - previous.ModuleTypes[moduleType]; added > 0 {
+			growths = append(growths, growth{moduleType, added})
+		}
+	}
+	sort.Slice(growths, func(i, j int) bool {
+		if growths[i].added != growths[j].added {
+			return growths[i].added > growths[j].added
+		}
+		return growths[i].moduleType < growths[j].moduleType
+	})
+	if len(growths) > 5 {
+		growths = growths[:5]
+	}
+	if len(growths) > 0 {
+		var list []string
+		for _, g := range growths {
+			list = append(list, fmt.Sprintf("%s (+%d)", g.moduleType, g.added))
+		}
+		fmt.Fprintf(sb, "Module types with the most new modules: %s.\n", strings.Join(list, ", "))
+	}
+	fmt.Fprintf(sb, "The history of the runs is in %s.", metricsHistoryFileName)
+	return sb.String()
+}
+
+// AppendMetricsHistory appends the metrics of a full analysis of the tree to
+// soong_build_history.jsonl, and returns a hint if its analysis time regressed compared to the
+// recent runs, or an empty string.
+func AppendMetricsHistory(config Config, eventHandler *metrics.EventHandler) (string, error) {
+	switch config.BuildMode {
+	case AnalysisNoBazel, BazelProdMode, BazelStagingMode, BazelDevMode:
+	default:
+		// Only runs that analyze the tree to write build.ninja are comparable.
+		return "", nil
+	}
+
+	file := absolutePath(filepath.Join(config.SoongOutDir(), metricsHistoryFileName))
+	history, err := readMetricsHistory(file)
+	if err != nil {
+		return "", err
+	}
+
+	entry := newMetricsHistoryEntry(config, eventHandler, time.Now())
+	hint := analysisRegressionHint(history, entry)
+
+	history = append(history, entry)
+	if len(history) > metricsHistoryLength {
+		history = history[len(history)-metricsHistoryLength:]
+	}
+	return hint, writeMetricsHistory(file, history)
+}
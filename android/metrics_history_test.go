@@ -0,0 +1,116 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func historyEntry(product string, analysisSeconds int64, moduleTypes map[string]int) MetricsHistoryEntry {
+	modules := 0
+	for _, count := range moduleTypes {
+		modules += count
+	}
+	return MetricsHistoryEntry{
+		Product:     product,
+		AnalysisMs:  analysisSeconds * 1000,
+		Modules:     modules,
+		Variants:    modules * 2,
+		Actions:     modules * 10,
+		ModuleTypes: moduleTypes,
+	}
+}
+
+func TestAnalysisRegressionHint(t *testing.T) {
+	types := map[string]int{"cc_library": 100, "java_library": 50}
+	history := []MetricsHistoryEntry{
+		historyEntry("aosp_arm64", 60, types),
+		historyEntry("aosp_arm64", 62, types),
+		historyEntry("aosp_x86_64", 200, types),
+		historyEntry("aosp_arm64", 58, types),
+	}
+
+	testCases := []struct {
+		name    string
+		history []MetricsHistoryEntry
+		entry   MetricsHistoryEntry
+		want    string
+	}{
+		{
+			name:    "no regression",
+			history: history,
+			entry:   historyEntry("aosp_arm64", 70, types),
+		},
+		{
+			name:    "not enough history",
+			history: history[:2],
+			entry:   historyEntry("aosp_arm64", 120, types),
+		},
+		{
+			name:    "other product",
+			history: history,
+			entry:   historyEntry("aosp_x86_64", 260, types),
+		},
+		{
+			name:    "regression",
+			history: history,
+			entry: historyEntry("aosp_arm64", 90, map[string]int{
+				"cc_library": 130, "java_library": 50, "genrule": 10, "rust_library": 10,
+			}),
+			want: "soong_build: the analysis took 1m30s, 50% longer than the median of the last 3 runs for aosp_arm64 (1m0s).\n" +
+				"Since the previous run, module variants went from 300 to 400 and actions from 1500 to 2000.\n" +
+				"Module types with the most new modules: cc_library (+30), genrule (+10), rust_library (+10).\n" +
+				"The history of the runs is in soong_build_history.jsonl.",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			AssertStringEquals(t, "hint", tc.want, analysisRegressionHint(tc.history, tc.entry))
+		})
+	}
+}
+
+func TestMetricsHistoryFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), metricsHistoryFileName)
+
+	history, err := readMetricsHistory(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertIntEquals(t, "entries of a missing file", 0, len(history))
+
+	want := []MetricsHistoryEntry{
+		historyEntry("aosp_arm64", 60, map[string]int{"cc_library": 1}),
+		historyEntry("aosp_arm64", 61, map[string]int{"cc_library": 2}),
+	}
+	if err := writeMetricsHistory(file, want); err != nil {
+		t.Fatal(err)
+	}
+	// A truncated line of an interrupted write is skipped.
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time": 1, "prod`)
+	f.Close()
+
+	history, err = readMetricsHistory(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, "history", want, history)
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+func init() {
+	RegisterMinSdkVersionCheckBuildComponents(InitRegistrationContext)
+}
+
+func RegisterMinSdkVersionCheckBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("min_sdk_version_violations", minSdkVersionViolationsSingletonFactory)
+}
+
+var PrepareForTestWithMinSdkVersionCheck = FixtureRegisterWithContext(RegisterMinSdkVersionCheckBuildComponents)
+
+// MinSdkVersionExplainer is implemented by modules that can explain where their effective
+// min_sdk_version comes from. It is used to describe each module of the dependency path of a
+// min_sdk_version violation.
+type MinSdkVersionExplainer interface {
+	// ExplainMinSdkVersion returns the effective min_sdk_version of the module as written by the
+	// user, e.g. "29" or "apex_inherit", and the property or product variable that set it, e.g.
+	// "min_sdk_version" or "sdk_version". It returns an empty value if the module doesn't set it.
+	ExplainMinSdkVersion(ctx EarlyModuleContext) (value string, property string)
+}
+
+// explainMinSdkVersion returns a description of the effective min_sdk_version of a module, or an
+// empty string if the module can't explain it.
+func explainMinSdkVersion(ctx EarlyModuleContext, module Module) string {
+	explainer, ok := module.(MinSdkVersionExplainer)
+	if !ok {
+		return ""
+	}
+	value, property := explainer.ExplainMinSdkVersion(ctx)
+	if value == "" {
+		return "min_sdk_version not set"
+	}
+	return fmt.Sprintf("min_sdk_version %s set by %s", value, property)
+}
+
+// minSdkVersionPathString is like GetPathString, but adds the effective min_sdk_version of each
+// module of the dependency path and where it comes from. It is supposed to be called in the visit
+// function passed to CheckMinSdkVersion.
+func minSdkVersionPathString(ctx ModuleContext) string {
+	sb := strings.Builder{}
+	tagPath := ctx.GetTagPath()
+	for i, m := range ctx.GetWalkPath() {
+		if i > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(fmt.Sprintf("           via tag %s\n", PrettyPrintTag(tagPath[i-1])))
+			sb.WriteString("    -> ")
+		}
+		sb.WriteString(m.String())
+		if explanation := explainMinSdkVersion(ctx, m); explanation != "" {
+			sb.WriteString(" (" + explanation + ")")
+		}
+	}
+	return sb.String()
+}
+
+var minSdkVersionViolationsKey = NewOnceKey("minSdkVersionViolations")
+
+// minSdkVersionViolationsList holds the min_sdk_version violations that were reported instead of
+// failing the build because the directory of the checked module is listed in
+// BUILD_BROKEN_MIN_SDK_VERSION_DIRS.
+type minSdkVersionViolationsList struct {
+	sync.Mutex
+	entries []string
+}
+
+func minSdkVersionViolations(config Config) *minSdkVersionViolationsList {
+	return config.Once(minSdkVersionViolationsKey, func() interface{} {
+		return &minSdkVersionViolationsList{}
+	}).(*minSdkVersionViolationsList)
+}
+
+// reportMinSdkVersionViolation reports a min_sdk_version violation of a dependency of the current
+// module, either as an error or in the bulk report in migration mode.
+func reportMinSdkVersionViolation(ctx ModuleContext, to Module, message string) {
+	if !ctx.DeviceConfig().BuildBrokenMinSdkVersion(ctx.ModuleDir()) {
+		ctx.OtherModuleErrorf(to, "%s", message)
+		return
+	}
+
+	violations := minSdkVersionViolations(ctx.Config())
+	violations.Lock()
+	defer violations.Unlock()
+	violations.entries = append(violations.entries,
+		fmt.Sprintf("%s: %s", to.String(), message))
+}
+
+func minSdkVersionViolationsSingletonFactory() Singleton {
+	return &minSdkVersionViolationsSingleton{}
+}
+
+// minSdkVersionViolationsSingleton writes the min_sdk_version violations reported in migration mode
+// to $OUT/soong/min_sdk_version_violations.txt.
+type minSdkVersionViolationsSingleton struct{}
+
+func (s *minSdkVersionViolationsSingleton) GenerateBuildActions(ctx SingletonContext) {
+	violations := minSdkVersionViolations(ctx.Config())
+	if len(violations.entries) == 0 {
+		return
+	}
+	entries := SortedUniqueStrings(violations.entries)
+
+	output := PathForOutput(ctx, "min_sdk_version_violations.txt")
+	WriteFileRule(ctx, output, strings.Join(entries, "\n\n"))
+	ctx.Phony("min_sdk_version_violations", output)
+}
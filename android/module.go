@@ -93,6 +93,10 @@ type BuildParams struct {
 	// Whether to skip outputting a default target statement which will be built by Ninja when no
 	// targets are specified on Ninja's command line.
 	Default bool
+	// Whether the outputs of the action differ between runs with the same inputs, for example
+	// because they embed the current date. Only used to attribute remote cache misses, see
+	// action_metadata.go.
+	NondeterministicOutputs bool
 	// Args is a key value mapping for replacements of variables within the Rule
 	Args map[string]string
 }
@@ -374,6 +378,11 @@ type BaseModuleContext interface {
 	Windows() bool
 	Debug() bool
 	PrimaryArch() bool
+
+	// DebugLog writes a structured debug log entry, tagged with the module, its variant and the
+	// current mutator, to the module log if the module matches the --log_modules patterns of
+	// soong_build. keyvals are alternating keys and values that are added to the entry as fields.
+	DebugLog(message string, keyvals ...interface{})
 }
 
 // Deprecated: use EarlyModuleContext instead
@@ -562,6 +571,8 @@ type Module interface {
 	Bp2buildTargets() []bp2buildInfo
 	GetUnconvertedBp2buildDeps() []string
 	GetMissingBp2buildDeps() []string
+	// GetUnconvertedReason returns the reason that bp2build didn't convert this module, or nil
+	GetUnconvertedReason() *UnconvertedReason
 
 	BuildParamsForTests() []BuildParams
 	RuleParamsForTests() map[blueprint.Rule]blueprint.RuleParams
@@ -580,6 +591,10 @@ type Module interface {
 	HostRequiredModuleNames() []string
 	TargetRequiredModuleNames() []string
 
+	// DlopenDepNames returns the names of the modules that this module loads at runtime without
+	// depending on them at build time.
+	DlopenDepNames() []string
+
 	FilesToInstall() InstallPaths
 	PackagingSpecs() []PackagingSpec
 
@@ -637,6 +652,11 @@ type Dist struct {
 	// built
 	Targets []string `android:"arch_variant"`
 
+	// Named dist groups that the output belongs to, e.g. "symbols", "apks" or "sbom". The
+	// outputs of a group are copied to the <group> subdirectory of the $DIST_DIR when the group
+	// is selected with `m dist DIST_GROUPS=<group>,...`, even without targets.
+	Groups []string `android:"arch_variant"`
+
 	// The name of the output artifact. This defaults to the basename of the output of
 	// the module.
 	Dest *string `android:"arch_variant"`
@@ -725,6 +745,12 @@ type commonProperties struct {
 	// and so prevent early detection of changes that have broken those modules.
 	Enabled *bool `android:"arch_variant"`
 
+	// Former names of this module that continue to resolve to it, so that a module can be renamed
+	// without updating all of its users at the same time. Each use of an alias is reported as a
+	// warning, and after the alias's remove_after date it is an error. Aliases must be set on the
+	// module itself, not through defaults.
+	Aliases []ModuleAlias
+
 	// Controls the visibility of this module to other modules. Allowable values are one or more of
 	// these formats:
 	//
@@ -848,6 +874,10 @@ type commonProperties struct {
 	// Whether this module is installed to debug ramdisk
 	Debug_ramdisk *bool
 
+	// Whether products may change the install location, permissions or symlinks of this module
+	// through the InstallOverrides product variable. Defaults to false.
+	Relocatable *bool
+
 	// Whether this module is built for non-native architectures (also known as native bridge binary)
 	Native_bridge_supported *bool `android:"arch_variant"`
 
@@ -866,6 +896,11 @@ type commonProperties struct {
 	// names of other modules to install on target if this module is installed
 	Target_required []string `android:"arch_variant"`
 
+	// names of modules that this module loads at runtime, e.g. with dlopen, without depending on
+	// them at build time. Unlike required, they are not installed with this module. Instead, images
+	// that contain this module fail to build if they don't contain the modules as well.
+	Dlopen_deps []string `android:"arch_variant"`
+
 	// The OsType of artifacts that this module variant is responsible for creating.
 	//
 	// Set by osMutator
@@ -876,6 +911,11 @@ type commonProperties struct {
 	// Set by archMutator
 	CompileTarget Target `blueprint:"mutated"`
 
+	// The arch and target property branches, e.g. "arch.arm", that are selected for this variant.
+	//
+	// Set by osMutator and archMutator
+	SelectedArchPropertyBranches []string `blueprint:"mutated"`
+
 	// The additional arch specific targets (e.g. 32/64 bit) that this module variant is
 	// responsible for creating.
 	//
@@ -1452,6 +1492,18 @@ type ModuleBase struct {
 	variableProperties      interface{}
 	hostAndDeviceProperties hostAndDeviceProperties
 
+	// The product_variables that were set for the product and applied to this module, used to
+	// report which product config enabled a module.
+	appliedProductVariables []string
+
+	// The tags and mutators of the dependencies added by mutators, used to explain dependency
+	// cycles. Only recorded when the configuration needs them, see recordDependencyOrigins.
+	dependencyOrigins []dependencyOrigin
+
+	// The names of the dependencies added by mutators that resolve through aliases, used to report
+	// the uses of aliases.
+	aliasDependencies []string
+
 	// Arch specific versions of structs in GetProperties() prior to
 	// initialization in InitAndroidArchModule, lets call it `generalProperties`.
 	// The outer index has the same order as generalProperties and the inner index
@@ -1496,6 +1548,24 @@ type ModuleBase struct {
 
 	registerProps []interface{}
 
+	// The cacheability of the actions of the module by rule, see action_metadata.go.
+	actionMetadata map[string]*ActionMetadata
+
+	// The number of build actions of the module, for the metrics of the build.
+	buildActions int
+
+	// The sources generated by the module, for the generated sources xref index, see
+	// generated_sources_xref.go.
+	generatedSources []generatedSource
+
+	// The signed artifacts installed by the module, for the transparency log, see
+	// transparency_log.go.
+	signedArtifacts []SignedArtifact
+
+	// The Soong config variables that the properties of the module depend on, for the module type
+	// census, see module_type_census.go.
+	soongConfigVariables []string
+
 	// For tests
 	buildParams []BuildParams
 	ruleParams  map[blueprint.Rule]blueprint.RuleParams
@@ -1584,6 +1654,16 @@ func (m *ModuleBase) GetMissingBp2buildDeps() []string {
 	return FirstUniqueStrings(m.commonProperties.BazelConversionStatus.MissingDeps)
 }
 
+// GetUnconvertedReason returns the reason that bp2build didn't convert this module, or nil if it
+// wasn't marked as unconvertible.
+func (m *ModuleBase) GetUnconvertedReason() *UnconvertedReason {
+	reason := m.commonProperties.BazelConversionStatus.UnconvertedReason
+	if reason.Type == "" {
+		return nil
+	}
+	return &reason
+}
+
 func (m *ModuleBase) AddJSONData(d *map[string]interface{}) {
 	(*d)["Android"] = map[string]interface{}{
 		// Properties set in Blueprint or in blueprint of a defaults modules
@@ -1779,7 +1859,7 @@ func (m *ModuleBase) visibilityProperties() []visibilityProperty {
 }
 
 func (m *ModuleBase) Dists() []Dist {
-	if len(m.distProperties.Dist.Targets) > 0 {
+	if len(m.distProperties.Dist.Targets) > 0 || len(m.distProperties.Dist.Groups) > 0 {
 		// Make a copy of the underlying Dists slice to protect against
 		// backing array modifications with repeated calls to this method.
 		distsCopy := append([]Dist(nil), m.distProperties.Dists...)
@@ -2161,6 +2241,10 @@ func (m *ModuleBase) TargetRequiredModuleNames() []string {
 	return m.base().commonProperties.Target_required
 }
 
+func (m *ModuleBase) DlopenDepNames() []string {
+	return m.base().commonProperties.Dlopen_deps
+}
+
 func (m *ModuleBase) InitRc() Paths {
 	return append(Paths{}, m.initRcPaths...)
 }
@@ -2306,6 +2390,8 @@ func (m *ModuleBase) baseModuleContextFactory(ctx blueprint.BaseModuleContext) b
 }
 
 func (m *ModuleBase) GenerateBuildActions(blueprintCtx blueprint.ModuleContext) {
+	CheckAnalysisInterrupt(blueprintCtx.Config().(Config))
+
 	ctx := &moduleContext{
 		module:            m.module,
 		bp:                blueprintCtx,
@@ -2362,6 +2448,7 @@ func (m *ModuleBase) GenerateBuildActions(blueprintCtx blueprint.ModuleContext)
 	for i := range m.distProperties.Dists {
 		checkDistProperties(ctx, fmt.Sprintf("dists[%d]", i), &m.distProperties.Dists[i])
 	}
+	checkMixedBuildProperties(ctx, m.module)
 
 	if m.Enabled() {
 		// ensure all direct android.Module deps are enabled
@@ -2376,6 +2463,11 @@ func (m *ModuleBase) GenerateBuildActions(blueprintCtx blueprint.ModuleContext)
 			return
 		}
 
+		if _, ok := ctx.Config().InstallOverride(ctx.ModuleName()); ok && ctx.Device() && !Bool(m.commonProperties.Relocatable) {
+			ctx.ModuleErrorf("the product overrides the install of this module in InstallOverrides, but the module does not set relocatable: true")
+			return
+		}
+
 		if mixedBuildMod, handled := m.isHandledByBazel(ctx); handled {
 			mixedBuildMod.ProcessBazelQueryResponse(ctx)
 		} else {
@@ -2445,6 +2537,10 @@ func (m *ModuleBase) isHandledByBazel(ctx ModuleContext) (MixedBuildBuildable, b
 	return nil, false
 }
 
+// distGroupRegexp matches the names of the dist groups, which are part of the names of the goals
+// that copy their outputs.
+var distGroupRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
 // Check the supplied dist structure to make sure that it is valid.
 //
 // property - the base property, e.g. dist or dists[1], which is combined with the
@@ -2468,6 +2564,12 @@ func checkDistProperties(ctx *moduleContext, property string, dist *Dist) {
 			ctx.PropertyErrorf(property+".suffix", "Suffix may not contain a '/' character.")
 		}
 	}
+	for _, group := range dist.Groups {
+		if !distGroupRegexp.MatchString(group) {
+			ctx.PropertyErrorf(property+".groups", "invalid dist group %q, must match %s",
+				group, distGroupRegexp)
+		}
+	}
 
 }
 
@@ -2622,6 +2724,12 @@ type moduleContext struct {
 	katiInstalls []katiInstall
 	katiSymlinks []katiInstall
 
+	// Whether the symlinks of the product's install override have been installed.
+	installedOverrideSymlinks bool
+
+	// The params of the rules defined by the module, to check the limits of its actions.
+	localRuleParams map[blueprint.Rule]blueprint.RuleParams
+
 	// For tests
 	buildParams []BuildParams
 	ruleParams  map[blueprint.Rule]blueprint.RuleParams
@@ -2786,6 +2894,10 @@ func (m *moduleContext) Rule(pctx PackageContext, name string, params blueprint.
 
 	rule := m.bp.Rule(pctx.PackageContext, name, params, argNames...)
 
+	if m.localRuleParams == nil {
+		m.localRuleParams = make(map[blueprint.Rule]blueprint.RuleParams)
+	}
+	m.localRuleParams[rule] = params
 	if m.config.captureBuild {
 		m.ruleParams[rule] = params
 	}
@@ -2807,6 +2919,22 @@ func (m *moduleContext) Build(pctx PackageContext, params BuildParams) {
 		m.buildParams = append(m.buildParams, params)
 	}
 
+	m.module.base().buildActions++
+	if m.config.UseRBE() {
+		m.module.base().recordActionMetadata(m, params)
+	}
+	recordNinjaWeightAction(m.config, params)
+
+	rule, ok := m.localRuleParams[params.Rule]
+	if !ok {
+		rule, ok = packageRuleParamsForConfig(m.config, params.Rule)
+	}
+	if ok {
+		if err := checkBuildLimits(m.config, m.Os(), rule, params); err != nil {
+			m.ModuleErrorf("%s", err.Error())
+		}
+	}
+
 	bparams := convertBuildParams(params)
 	err := validateBuildParams(bparams)
 	if err != nil {
@@ -3283,9 +3411,31 @@ func (m *moduleContext) packageFile(fullInstallPath InstallPath, srcPath Path, e
 	return spec
 }
 
+// installOverride returns the product's override of how this module is installed, if any.  Only
+// device modules that set relocatable: true can be overridden.
+func (m *moduleContext) installOverride() (InstallOverride, bool) {
+	if !m.Device() || !Bool(m.module.base().commonProperties.Relocatable) {
+		return InstallOverride{}, false
+	}
+	return m.Config().InstallOverride(m.ModuleName())
+}
+
 func (m *moduleContext) installFile(installPath InstallPath, name string, srcPath Path, deps []Path,
 	executable bool, extraZip *extraFilesZip) InstallPath {
 
+	override, overridden := m.installOverride()
+	if overridden {
+		if override.Dir != nil {
+			installPath = installPath.relocate(m, *override.Dir)
+			if extraZip != nil {
+				extraZip.dir = installPath
+			}
+		}
+		if override.Executable != nil {
+			executable = *override.Executable
+		}
+	}
+
 	fullInstallPath := installPath.Join(m, name)
 	m.module.base().hooks.runInstallHooks(m, srcPath, fullInstallPath, false)
 
@@ -3349,6 +3499,16 @@ func (m *moduleContext) installFile(installPath InstallPath, name string, srcPat
 
 	m.checkbuildFiles = append(m.checkbuildFiles, srcPath)
 
+	if overridden && len(override.Symlinks) > 0 {
+		if m.installedOverrideSymlinks {
+			m.ModuleErrorf("the product overrides the symlinks of this module in InstallOverrides, but the module installs more than one file")
+		}
+		m.installedOverrideSymlinks = true
+		for _, symlink := range override.Symlinks {
+			m.InstallSymlink(installPath, symlink, fullInstallPath)
+		}
+	}
+
 	return fullInstallPath
 }
 
@@ -3794,13 +3954,24 @@ func (c *buildTargetSingleton) GenerateBuildActions(ctx SingletonContext) {
 		hostCross bool
 	}
 	osDeps := map[osAndCross]Paths{}
+	var wasiInstalls InstallPaths
 	ctx.VisitAllModules(func(module Module) {
 		if module.Enabled() {
 			key := osAndCross{os: module.Target().Os, hostCross: module.Target().HostCross}
 			osDeps[key] = append(osDeps[key], module.base().checkbuildFiles...)
+			if key.os == Wasi {
+				wasiInstalls = append(wasiInstalls, module.base().installFiles...)
+			}
 		}
 	})
 
+	// Make doesn't know about the WebAssembly modules, so nothing installs them unless they are
+	// requested by name. Create a wasi_modules phony rule that installs all of them into
+	// out/host/wasi-wasm32.
+	if len(wasiInstalls) > 0 {
+		ctx.Phony("wasi_modules", wasiInstalls.Paths()...)
+	}
+
 	osClass := make(map[string]Paths)
 	for key, deps := range osDeps {
 		var className string
@@ -0,0 +1,281 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/blueprint"
+)
+
+// This file implements module aliases, which allow a module to be renamed without updating all
+// of its users at the same time, e.g.
+//
+//	cc_library {
+//	    name: "libfoo",
+//	    aliases: [
+//	        {
+//	            name: "libfoo_legacy",
+//	            remove_after: "2027-01-01",
+//	        },
+//	    ],
+//	}
+//
+// A dependency on libfoo_legacy resolves to libfoo. Each module that uses an alias causes a
+// warning when droidcore is built, and every use of an alias is listed in $OUT/soong/module_aliases.json. Once the
+// platform_security_patch of the product is after the remove_after date of an alias, using the
+// alias is an error.
+
+func init() {
+	RegisterModuleAliasesBuildComponents(InitRegistrationContext)
+}
+
+// moduleAliasWarnings prints the warnings about the uses of aliases, which are only reported by
+// the build so that soong_build itself stays quiet.
+var moduleAliasWarnings = pctx.AndroidStaticRule("moduleAliasWarnings",
+	blueprint.RuleParams{
+		Command:     `cat $in >&2 && touch $out`,
+		Description: "module alias warnings",
+	})
+
+func RegisterModuleAliasesBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("module_aliases", moduleAliasesSingletonFactory)
+}
+
+var PrepareForTestWithModuleAliases = FixtureRegisterWithContext(RegisterModuleAliasesBuildComponents)
+
+// ModuleAlias is a former name of a module.
+type ModuleAlias struct {
+	// The former name of the module.
+	Name *string
+
+	// The date, as YYYY-MM-DD, after which the alias is removed. Using the alias is an error in
+	// products whose platform_security_patch is after this date.
+	Remove_after *string
+}
+
+const moduleAliasDateLayout = "2006-01-02"
+
+// moduleAlias is an alias registered in a namespace.
+type moduleAlias struct {
+	name          string
+	target        string
+	removeAfter   string
+	blueprintFile string
+}
+
+// addAliases registers the aliases of a module in the namespace. It is called while the
+// blueprint files are parsed, so the aliases must be set on the module itself rather than through
+// defaults.
+func (n *Namespace) addAliases(module Module, blueprintFile string) []error {
+	n.aliasesLock.Lock()
+	defer n.aliasesLock.Unlock()
+
+	var errs []error
+	if alias, exists := n.aliases[module.Name()]; exists {
+		errs = append(errs, fmt.Errorf("module %q has the same name as an alias of module %q defined in %s",
+			module.Name(), alias.target, alias.blueprintFile))
+	}
+
+	for _, a := range module.base().commonProperties.Aliases {
+		alias := moduleAlias{
+			name:          String(a.Name),
+			target:        module.Name(),
+			removeAfter:   String(a.Remove_after),
+			blueprintFile: blueprintFile,
+		}
+		if alias.name == "" {
+			errs = append(errs, fmt.Errorf("module %q: aliases: name must be set", alias.target))
+			continue
+		}
+		if alias.removeAfter != "" {
+			if _, err := time.Parse(moduleAliasDateLayout, alias.removeAfter); err != nil {
+				errs = append(errs, fmt.Errorf("module %q: alias %q: remove_after must be a date of the form YYYY-MM-DD, got %q",
+					alias.target, alias.name, alias.removeAfter))
+				continue
+			}
+		}
+		if existing, exists := n.aliases[alias.name]; exists {
+			errs = append(errs, fmt.Errorf("module %q: alias %q is already an alias of module %q defined in %s",
+				alias.target, alias.name, existing.target, existing.blueprintFile))
+			continue
+		}
+		if _, exists := n.moduleContainer.ModuleFromName(alias.name, nil); exists {
+			errs = append(errs, fmt.Errorf("module %q: alias %q is the name of another module",
+				alias.target, alias.name))
+			continue
+		}
+		n.aliases[alias.name] = alias
+		if n.resolver != nil {
+			atomic.AddInt32(&n.resolver.aliasCount, 1)
+		}
+	}
+	return errs
+}
+
+// alias returns the alias with the given name in the namespace, if there is one.
+func (n *Namespace) alias(name string) (moduleAlias, bool) {
+	n.aliasesLock.Lock()
+	defer n.aliasesLock.Unlock()
+	alias, ok := n.aliases[name]
+	return alias, ok
+}
+
+func moduleAliasesSingletonFactory() Singleton {
+	return &moduleAliasesSingleton{}
+}
+
+// moduleAliasesSingleton reports the modules that depend on other modules through their aliases.
+type moduleAliasesSingleton struct{}
+
+// moduleAliasUsage is an entry of module_aliases.json.
+type moduleAliasUsage struct {
+	Alias         string `json:"alias"`
+	Module        string `json:"module"`
+	UsedBy        string `json:"used_by"`
+	BlueprintFile string `json:"blueprint_file"`
+	RemoveAfter   string `json:"remove_after,omitempty"`
+}
+
+func (s *moduleAliasesSingleton) GenerateBuildActions(ctx SingletonContext) {
+	securityPatch := ctx.Config().PlatformSecurityPatch()
+
+	usages := make(map[moduleAliasUsage]bool)
+	var warnings []string
+	ctx.VisitAllModules(func(module Module) {
+		ctx.VisitDirectDeps(module, func(dep Module) {
+			for _, alias := range dep.base().commonProperties.Aliases {
+				name := String(alias.Name)
+				if name == "" || !module.base().dependsOnAlias(name) {
+					continue
+				}
+				usage := moduleAliasUsage{
+					Alias:         name,
+					Module:        ctx.ModuleName(dep),
+					UsedBy:        ctx.ModuleName(module),
+					BlueprintFile: ctx.BlueprintFile(module),
+					RemoveAfter:   String(alias.Remove_after),
+				}
+				if usages[usage] {
+					continue
+				}
+				usages[usage] = true
+
+				if usage.RemoveAfter != "" && securityPatch != "" && securityPatch > usage.RemoveAfter {
+					ctx.ModuleErrorf(module, "depends on %q through its alias %q, which was removed after %s, use %q instead",
+						usage.Module, usage.Alias, usage.RemoveAfter, usage.Module)
+					continue
+				}
+				deadline := ""
+				if usage.RemoveAfter != "" {
+					deadline = fmt.Sprintf(", the alias will be removed after %s", usage.RemoveAfter)
+				}
+				warnings = append(warnings, fmt.Sprintf("warning: %s: module %q depends on %q through its alias %q%s",
+					usage.BlueprintFile, usage.UsedBy, usage.Module, usage.Alias, deadline))
+			}
+		})
+	})
+
+	report := make([]moduleAliasUsage, 0, len(usages))
+	for usage := range usages {
+		report = append(report, usage)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].UsedBy != report[j].UsedBy {
+			return report[i].UsedBy < report[j].UsedBy
+		}
+		return report[i].Alias < report[j].Alias
+	})
+
+	jsonStr, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	WriteFileRule(ctx, ModuleAliasesReportPath(ctx), string(jsonStr))
+
+	if len(warnings) == 0 {
+		return
+	}
+	sort.Strings(warnings)
+	warningsFile := PathForOutput(ctx, "module_aliases_warnings.txt")
+	WriteFileRule(ctx, warningsFile, strings.Join(warnings, "\n"))
+	stamp := PathForOutput(ctx, "module_aliases_warnings.stamp")
+	ctx.Build(pctx, BuildParams{
+		Rule:   moduleAliasWarnings,
+		Input:  warningsFile,
+		Output: stamp,
+	})
+	ctx.Phony("droidcore", stamp)
+}
+
+// ModuleAliasesReportPath returns the path of the report of the modules that use aliases.
+func ModuleAliasesReportPath(ctx PathContext) WritablePath {
+	return PathForOutput(ctx, "module_aliases.json")
+}
+
+// hasAliases returns true if any namespace has aliases.
+func (r *NameResolver) hasAliases() bool {
+	return atomic.LoadInt32(&r.aliasCount) > 0
+}
+
+// isAlias returns true if a dependency on name from a module in namespace resolves through an
+// alias, following the lookup of ModuleFromName.
+func (r *NameResolver) isAlias(name string, namespace *Namespace) bool {
+	if nsName, moduleName, isAbs := r.parseFullyQualifiedName(name); isAbs {
+		ns, found := r.namespaceAt(nsName)
+		if !found {
+			return false
+		}
+		_, found = ns.alias(moduleName)
+		return found
+	}
+	for _, candidate := range r.getNamespacesToSearchForModule(namespace) {
+		if _, found := candidate.alias(name); found {
+			return true
+		}
+	}
+	return false
+}
+
+// addAliasDependencies records the names of dependencies of the module in namespace that resolve
+// through aliases. It is called for every dependency added by a mutator, so it returns early when
+// no module has aliases, which is the common case.
+func (m *ModuleBase) addAliasDependencies(namespace *Namespace, names []string) {
+	if namespace == nil || namespace.resolver == nil || !namespace.resolver.hasAliases() {
+		return
+	}
+	for _, name := range names {
+		if namespace.resolver.isAlias(name, namespace) {
+			m.aliasDependencies = append(m.aliasDependencies, name)
+		}
+	}
+}
+
+// dependsOnAlias returns true if a mutator added a dependency on the given alias, either directly
+// or through a fully qualified name.
+func (m *ModuleBase) dependsOnAlias(alias string) bool {
+	for _, name := range m.aliasDependencies {
+		if name == alias || strings.HasSuffix(name, ":"+alias) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+var prepareForModuleAliasesTest = GroupFixturePreparers(
+	prepareForModuleTests,
+	PrepareForTestWithArchMutator,
+	PrepareForTestWithModuleAliases,
+	// The uses of aliases are reported without the origins of dependencies, which soong_build
+	// only records when it needs them.
+	FixtureModifyConfig(func(config Config) {
+		config.recordDependencyOrigins = false
+	}),
+)
+
+const moduleAliasesTestBp = `
+	deps {
+		name: "foo",
+		aliases: [
+			{
+				name: "foo_old",
+				remove_after: "2027-01-01",
+			},
+		],
+	}
+
+	deps {
+		name: "bar",
+		deps: ["foo_old"],
+	}
+`
+
+func TestModuleAliases(t *testing.T) {
+	result := prepareForModuleAliasesTest.RunTestWithBp(t, moduleAliasesTestBp)
+
+	bar := result.ModuleForTests("bar", "android_common").Module()
+	var deps []string
+	result.VisitDirectDeps(bar, func(dep blueprint.Module) {
+		deps = append(deps, result.ModuleName(dep))
+	})
+	AssertArrayString(t, "deps of bar", []string{"foo"}, deps)
+
+	report := result.SingletonForTests("module_aliases").Output("module_aliases.json")
+	AssertStringEquals(t, "module_aliases.json", `[
+  {
+    "alias": "foo_old",
+    "module": "foo",
+    "used_by": "bar",
+    "blueprint_file": "Android.bp",
+    "remove_after": "2027-01-01"
+  }
+]`, ContentFromFileRuleForTests(t, report))
+
+	aliases := result.SingletonForTests("module_aliases")
+	warnings := aliases.Output("module_aliases_warnings.txt")
+	AssertStringEquals(t, "module_aliases_warnings.txt",
+		`warning: Android.bp: module "bar" depends on "foo" through its alias "foo_old", the alias will be removed after 2027-01-01`,
+		ContentFromFileRuleForTests(t, warnings))
+	stamp := aliases.Output("module_aliases_warnings.stamp")
+	AssertPathRelativeToTopEquals(t, "warnings input", "out/soong/module_aliases_warnings.txt", stamp.Input)
+}
+
+func TestModuleAliasesRemoved(t *testing.T) {
+	GroupFixturePreparers(
+		prepareForModuleAliasesTest,
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.Platform_security_patch = proptools.StringPtr("2027-02-05")
+		}),
+	).
+		ExtendWithErrorHandler(FixtureExpectsOneErrorPattern(
+			`module "bar" variant "android_common": depends on "foo" through its alias "foo_old", which was removed after 2027-01-01, use "foo" instead`)).
+		RunTestWithBp(t, moduleAliasesTestBp)
+}
+
+func TestModuleAliasesErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bp       string
+		expected string
+	}{
+		{
+			name: "invalid date",
+			bp: `
+				deps {
+					name: "foo",
+					aliases: [{name: "foo_old", remove_after: "next year"}],
+				}
+			`,
+			expected: `alias "foo_old": remove_after must be a date of the form YYYY-MM-DD, got "next year"`,
+		},
+		{
+			name: "duplicate alias",
+			bp: `
+				deps {
+					name: "foo",
+					aliases: [{name: "old"}],
+				}
+
+				deps {
+					name: "bar",
+					aliases: [{name: "old"}],
+				}
+			`,
+			expected: `module "bar": alias "old" is already an alias of module "foo" defined in Android.bp`,
+		},
+		{
+			name: "alias of another module",
+			bp: `
+				deps {
+					name: "foo",
+					aliases: [{name: "bar"}],
+				}
+
+				deps {
+					name: "bar",
+				}
+			`,
+			expected: `module "bar" has the same name as an alias of module "foo" defined in Android.bp`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prepareForModuleAliasesTest.
+				ExtendWithErrorHandler(FixtureExpectsAtLeastOneErrorMatchingPattern(tc.expected)).
+				RunTestWithBp(t, tc.bp)
+		})
+	}
+}
+
+// BenchmarkAddAliasDependencies measures the cost that aliases add to every dependency added by a
+// mutator, with and without aliases in the build.
+func BenchmarkAddAliasDependencies(b *testing.B) {
+	names := []string{"libc", "libm", "libdl", "libfoo", "//vendor/foo:libbar"}
+	for _, withAliases := range []bool{false, true} {
+		b.Run(fmt.Sprintf("aliases=%t", withAliases), func(b *testing.B) {
+			resolver := NewNameResolver(TestConfig(b.TempDir(), nil, "", nil))
+			namespace := resolver.rootNamespace
+			if withAliases {
+				namespace.aliases["libfoo_old"] = moduleAlias{name: "libfoo_old", target: "libfoo"}
+				resolver.aliasCount++
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var m ModuleBase
+				m.addAliasDependencies(namespace, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// This file writes the reverse dependencies of the modules for soong_build --module_deps_file,
+// so that tools answering "what is affected by a change to this module" can look up the
+// dependents of a module instead of walking the whole module graph.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/google/blueprint"
+)
+
+// ModuleDependent is a module variant that directly depends on another module variant.
+type ModuleDependent struct {
+	Name    string
+	Variant string `json:",omitempty"`
+	Dir     string
+
+	// The type of the dependency tag, if the dependency was added by a mutator of Soong.
+	Tag string `json:",omitempty"`
+}
+
+// ModuleVariantDependents are the direct dependents of a variant of a module.
+type ModuleVariantDependents struct {
+	Variant    string            `json:",omitempty"`
+	Dependents []ModuleDependent `json:",omitempty"`
+}
+
+// ModuleDeps are the variants of each module and their direct dependents, keyed by module name.
+type ModuleDeps map[string][]ModuleVariantDependents
+
+// ReverseDependencies returns the direct dependents of all the variants of all the modules of
+// ctx. The variants of a module and their dependents are sorted by variant and name.
+func ReverseDependencies(ctx *Context) ModuleDeps {
+	type variant struct {
+		name, variant string
+	}
+	dependents := make(map[variant][]ModuleDependent)
+	ctx.VisitAllModules(func(module blueprint.Module) {
+		v := variant{ctx.ModuleName(module), ctx.ModuleSubDir(module)}
+		if _, ok := dependents[v]; !ok {
+			dependents[v] = nil
+		}
+		ctx.VisitDirectDeps(module, func(dep blueprint.Module) {
+			dependent := ModuleDependent{
+				Name:    ctx.ModuleName(module),
+				Variant: ctx.ModuleSubDir(module),
+				Dir:     ctx.ModuleDir(module),
+			}
+			if m, ok := module.(Module); ok {
+				if origin, ok := m.base().dependencyOrigin(ctx.ModuleName(dep)); ok {
+					dependent.Tag = fmt.Sprintf("%T", origin.tag)
+				}
+			}
+			d := variant{ctx.ModuleName(dep), ctx.ModuleSubDir(dep)}
+			dependents[d] = append(dependents[d], dependent)
+		})
+	})
+
+	deps := make(ModuleDeps)
+	for v, ds := range dependents {
+		sort.Slice(ds, func(i, j int) bool {
+			if ds[i].Name != ds[j].Name {
+				return ds[i].Name < ds[j].Name
+			}
+			return ds[i].Variant < ds[j].Variant
+		})
+		deps[v.name] = append(deps[v.name], ModuleVariantDependents{
+			Variant:    v.variant,
+			Dependents: ds,
+		})
+	}
+	for _, vs := range deps {
+		sort.Slice(vs, func(i, j int) bool { return vs[i].Variant < vs[j].Variant })
+	}
+	return deps
+}
+
+// WriteModuleDeps writes the reverse dependencies of the modules of ctx as JSON.
+func WriteModuleDeps(w io.Writer, ctx *Context) error {
+	data, err := json.MarshalIndent(ReverseDependencies(ctx), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/blueprint"
+)
+
+type moduleDepsTestDepTag struct {
+	blueprint.BaseDependencyTag
+}
+
+type moduleDepsTestModule struct {
+	ModuleBase
+	properties struct {
+		Deps []string
+	}
+}
+
+func (m *moduleDepsTestModule) DepsMutator(ctx BottomUpMutatorContext) {
+	ctx.AddDependency(ctx.Module(), moduleDepsTestDepTag{}, m.properties.Deps...)
+}
+
+func (m *moduleDepsTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {}
+
+func moduleDepsTestModuleFactory() Module {
+	m := &moduleDepsTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidModule(m)
+	return m
+}
+
+func TestReverseDependencies(t *testing.T) {
+	result := GroupFixturePreparers(
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("module_deps_test", moduleDepsTestModuleFactory)
+		}),
+		FixtureAddTextFile("foo/Android.bp", `
+			module_deps_test {
+				name: "a",
+			}
+
+			module_deps_test {
+				name: "c",
+				deps: ["a", "b"],
+			}
+		`),
+		FixtureWithRootAndroidBp(`
+			module_deps_test {
+				name: "b",
+				deps: ["a"],
+			}
+		`),
+	).RunTest(t)
+
+	tag := "android.moduleDepsTestDepTag"
+	AssertDeepEquals(t, "reverse dependencies", ModuleDeps{
+		"a": {{Dependents: []ModuleDependent{
+			{Name: "b", Dir: ".", Tag: tag},
+			{Name: "c", Dir: "foo", Tag: tag},
+		}}},
+		"b": {{Dependents: []ModuleDependent{
+			{Name: "c", Dir: "foo", Tag: tag},
+		}}},
+		"c": {{}},
+	}, ReverseDependencies(result.TestContext.Context))
+
+	var buf bytes.Buffer
+	if err := WriteModuleDeps(&buf, result.TestContext.Context); err != nil {
+		t.Fatal(err)
+	}
+	var written ModuleDeps
+	if err := json.Unmarshal(buf.Bytes(), &written); err != nil {
+		t.Fatal(err)
+	}
+	AssertIntEquals(t, "dependents of a", 2, len(written["a"][0].Dependents))
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// This file builds the searchable index of the modules of the product that soong_build
+// --serve_docs serves next to the documentation of the module types.
+
+import (
+	"strings"
+)
+
+// ModuleIndexEntry is a module in the index of the modules of the product.
+type ModuleIndexEntry struct {
+	Name string
+	Type string
+	Dir  string
+
+	// The names of the modules that the module directly depends on, and of the modules that
+	// directly depend on it, in any of their variants.
+	Deps  []string
+	Rdeps []string
+}
+
+// Matches returns true if every term of a search is a case insensitive substring of the name,
+// the type or the directory of the module.
+func (e ModuleIndexEntry) Matches(search string) bool {
+	for _, term := range strings.Fields(strings.ToLower(search)) {
+		if !strings.Contains(strings.ToLower(e.Name), term) &&
+			!strings.Contains(strings.ToLower(e.Type), term) &&
+			!strings.Contains(strings.ToLower(e.Dir), term) {
+			return false
+		}
+	}
+	return true
+}
+
+// ModuleIndex returns the index of the modules of ctx, sorted by name.
+func ModuleIndex(ctx *Context) []ModuleIndexEntry {
+	g := newQueryGraph(ctx)
+	index := make([]ModuleIndexEntry, 0, len(g.variants))
+	for _, name := range SortedKeys(g.variants) {
+		variant := g.variants[name][0]
+		index = append(index, ModuleIndexEntry{
+			Name:  name,
+			Type:  ctx.ModuleType(variant),
+			Dir:   ctx.ModuleDir(variant),
+			Deps:  SortedKeys(g.deps[name]),
+			Rdeps: SortedKeys(g.rdeps[name]),
+		})
+	}
+	return index
+}
+
+// SearchModuleIndex returns the modules of the index that match a search, see
+// ModuleIndexEntry.Matches.
+func SearchModuleIndex(index []ModuleIndexEntry, search string) []ModuleIndexEntry {
+	var result []ModuleIndexEntry
+	for _, entry := range index {
+		if entry.Matches(search) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
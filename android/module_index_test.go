@@ -0,0 +1,63 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+)
+
+func TestModuleIndex(t *testing.T) {
+	result := GroupFixturePreparers(
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("query_library", queryTestModuleFactory)
+			ctx.RegisterModuleType("query_binary", queryTestModuleFactory)
+		}),
+		FixtureAddTextFile("external/liba/Android.bp", `
+			query_library {
+				name: "liba",
+			}
+		`),
+		FixtureWithRootAndroidBp(`
+			query_binary {
+				name: "bin",
+				deps: ["liba"],
+			}
+		`),
+	).RunTest(t)
+
+	index := ModuleIndex(result.TestContext.Context)
+	AssertDeepEquals(t, "module index", []ModuleIndexEntry{
+		{Name: "bin", Type: "query_binary", Dir: ".", Deps: []string{"liba"}},
+		{Name: "liba", Type: "query_library", Dir: "external/liba", Rdeps: []string{"bin"}},
+	}, index)
+
+	testCases := []struct {
+		search   string
+		expected []string
+	}{
+		{search: "", expected: []string{"bin", "liba"}},
+		{search: "LIBA", expected: []string{"liba"}},
+		{search: "query_binary", expected: []string{"bin"}},
+		{search: "external lib", expected: []string{"liba"}},
+		{search: "external bin", expected: nil},
+	}
+	for _, tc := range testCases {
+		var names []string
+		for _, entry := range SearchModuleIndex(index, tc.search) {
+			names = append(names, entry.Name)
+		}
+		AssertDeepEquals(t, "search "+tc.search, tc.expected, names)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The module log holds the structured debug logs that mutators and module implementations emit
+// with BaseModuleContext.DebugLog. It is enabled by running soong_build with
+// --log_modules <patterns>, where <patterns> is a comma-separated list of shell patterns matched
+// against the module names, e.g. "libfoo,libbar*". Only the logs of the matching modules are
+// written, one JSON object per line, so that debugging a module doesn't require adding prints to
+// soong_build and rebuilding it.
+
+// ModuleLogEntry is a line of the module log.
+type ModuleLogEntry struct {
+	Time time.Time `json:"time"`
+
+	Module string `json:"module"`
+	Dir    string `json:"dir"`
+	// The variations of the module when the entry was logged, e.g. "os:android,arch:arm64".
+	Variant string `json:"variant,omitempty"`
+	// The mutator that logged the entry, or empty if the entry was logged while generating the
+	// build actions of the module.
+	Mutator string `json:"mutator,omitempty"`
+
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// moduleLog writes the module log, see Config.moduleLog.
+type moduleLog struct {
+	patterns []string
+
+	lock    sync.Mutex
+	encoder *json.Encoder
+}
+
+func newModuleLog(patterns string, w io.Writer) *moduleLog {
+	return &moduleLog{
+		patterns: strings.Split(patterns, ","),
+		encoder:  json.NewEncoder(w),
+	}
+}
+
+// OpenModuleLog creates the module log file of this run if soong_build runs with --log_modules.
+// The file is left open until soong_build exits.
+func OpenModuleLog(config Config, patterns, file string) error {
+	if patterns == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(absolutePath(file)), 0777); err != nil {
+		return err
+	}
+	f, err := os.Create(absolutePath(file))
+	if err != nil {
+		return err
+	}
+	config.moduleLog = newModuleLog(patterns, f)
+	return nil
+}
+
+// matches returns whether the logs of a module are written.
+func (l *moduleLog) matches(name string) bool {
+	for _, pattern := range l.patterns {
+		if match, _ := filepath.Match(pattern, name); match {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *moduleLog) write(entry *ModuleLogEntry) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	// The log is for debugging, failing to write it doesn't fail the build.
+	l.encoder.Encode(entry)
+}
+
+// moduleLogFields converts alternating keys and values to the fields of a module log entry. A key
+// without a value gets the value "MISSING".
+func moduleLogFields(keyvals []interface{}) map[string]string {
+	if len(keyvals) == 0 {
+		return nil
+	}
+	fields := make(map[string]string, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 < len(keyvals) {
+			fields[key] = fmt.Sprint(keyvals[i+1])
+		} else {
+			fields[key] = "MISSING"
+		}
+	}
+	return fields
+}
+
+// debugLog writes an entry for the current module to the module log if it matches --log_modules.
+func (b *baseModuleContext) debugLog(mutator, message string, keyvals []interface{}) {
+	log := b.Config().moduleLog
+	if log == nil || !log.matches(b.ModuleName()) {
+		return
+	}
+
+	base := b.Module().base()
+	variant := make([]string, len(base.commonProperties.DebugMutators))
+	for i, m := range base.commonProperties.DebugMutators {
+		variant[i] = m + ":" + base.commonProperties.DebugVariations[i]
+	}
+	log.write(&ModuleLogEntry{
+		Time:    time.Now(),
+		Module:  b.ModuleName(),
+		Dir:     b.ModuleDir(),
+		Variant: strings.Join(variant, ","),
+		Mutator: mutator,
+		Message: message,
+		Fields:  moduleLogFields(keyvals),
+	})
+}
+
+func (b *baseModuleContext) DebugLog(message string, keyvals ...interface{}) {
+	b.debugLog("", message, keyvals)
+}
+
+func (t *topDownMutatorContext) DebugLog(message string, keyvals ...interface{}) {
+	t.debugLog(t.MutatorName(), message, keyvals)
+}
+
+func (b *bottomUpMutatorContext) DebugLog(message string, keyvals ...interface{}) {
+	b.debugLog(b.MutatorName(), message, keyvals)
+}
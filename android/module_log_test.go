@@ -0,0 +1,72 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestModuleLog(t *testing.T) {
+	bp := `
+		test {
+			name: "libfoo",
+		}
+
+		test {
+			name: "libbar",
+		}
+	`
+
+	var buf bytes.Buffer
+	GroupFixturePreparers(
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("test", mutatorTestModuleFactory)
+			ctx.PreDepsMutators(func(ctx RegisterMutatorsContext) {
+				ctx.BottomUp("log_test", func(ctx BottomUpMutatorContext) {
+					ctx.DebugLog("visited", "type", ctx.ModuleType(), "incomplete")
+				})
+			})
+		}),
+		FixtureModifyConfig(func(config Config) {
+			config.moduleLog = newModuleLog("libf*,libbaz", &buf)
+		}),
+		FixtureWithRootAndroidBp(bp),
+	).RunTest(t)
+
+	var entries []ModuleLogEntry
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var entry ModuleLogEntry
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %#v", entries)
+	}
+	entry := entries[0]
+	AssertStringEquals(t, "module", "libfoo", entry.Module)
+	AssertStringEquals(t, "dir", ".", entry.Dir)
+	AssertStringEquals(t, "mutator", "log_test", entry.Mutator)
+	AssertStringEquals(t, "message", "visited", entry.Message)
+	AssertDeepEquals(t, "fields", map[string]string{
+		"type":       "test",
+		"incomplete": "MISSING",
+	}, entry.Fields)
+}
@@ -16,6 +16,7 @@ package android
 
 import (
 	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -331,6 +332,24 @@ func TestDistErrorChecking(t *testing.T) {
 		RunTestWithBp(t, bp)
 }
 
+func TestDistGroupErrorChecking(t *testing.T) {
+	bp := `
+		deps {
+			name: "foo",
+			dists: [
+				{
+					groups: ["symbols", "Invalid group"],
+				},
+			],
+		}
+	`
+
+	prepareForModuleTests.
+		ExtendWithErrorHandler(FixtureExpectsAtLeastOneErrorMatchingPattern(
+			`module "foo": dists\[0\].groups: invalid dist group "Invalid group"`)).
+		RunTestWithBp(t, bp)
+}
+
 func TestInstall(t *testing.T) {
 	if runtime.GOOS != "linux" {
 		t.Skip("requires linux")
@@ -560,6 +579,60 @@ func TestInstallKatiEnabled(t *testing.T) {
 	assertOrderOnlys(symlinkRule("foo"))
 }
 
+func TestInstallOverrides(t *testing.T) {
+	bp := `
+		deps {
+			name: "foo",
+			relocatable: true,
+		}
+	`
+
+	result := GroupFixturePreparers(
+		prepareForModuleTests,
+		PrepareForTestWithArchMutator,
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.InstallOverrides = map[string]InstallOverride{
+				"foo": {
+					Dir:        proptools.StringPtr("bin/sub"),
+					Executable: proptools.BoolPtr(true),
+					Symlinks:   []string{"foo_link"},
+				},
+			}
+		}),
+	).RunTestWithBp(t, bp)
+
+	foo := result.ModuleForTests("foo", "android_common")
+
+	install := foo.Output("out/soong/target/product/test_device/system/bin/sub/foo")
+	AssertStringEquals(t, "install rule", CpExecutable.String(), install.Rule.String())
+
+	symlink := foo.Output("out/soong/target/product/test_device/system/bin/sub/foo_link")
+	AssertPathRelativeToTopEquals(t, "symlink input", "out/soong/target/product/test_device/system/bin/sub/foo", symlink.Input)
+
+	// The host variant is not affected by the product's overrides.
+	result.ModuleForTests("foo", result.Config.BuildOSCommonTarget.String()).Output("out/soong/host/linux-x86/foo")
+}
+
+func TestInstallOverridesNotRelocatable(t *testing.T) {
+	bp := `
+		deps {
+			name: "foo",
+		}
+	`
+
+	GroupFixturePreparers(
+		prepareForModuleTests,
+		PrepareForTestWithArchMutator,
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.InstallOverrides = map[string]InstallOverride{
+				"foo": {Dir: proptools.StringPtr("bin")},
+			}
+		}),
+	).ExtendWithErrorHandler(FixtureExpectsAtLeastOneErrorMatchingPattern(
+		`module "foo" variant "android_common": the product overrides the install of this module in InstallOverrides, but the module does not set relocatable: true`)).
+		RunTestWithBp(t, bp)
+}
+
 type PropsTestModuleEmbedded struct {
 	Embedded_prop *string
 }
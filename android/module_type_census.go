@@ -0,0 +1,202 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// When the product sets Module_type_census, the build counts the modules of each module type, the
+// properties that are set on them and the Soong config variables that they use, by directory. The
+// census is written to $OUT/soong/module_type_census.json, which is built with
+// `m module_type_census` and is in the "module_type_census" dist group. It is used to find the
+// modules affected by the removal of a module type or a property.
+
+func init() {
+	RegisterModuleTypeCensusBuildComponents(InitRegistrationContext)
+}
+
+func RegisterModuleTypeCensusBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("module_type_census", moduleTypeCensusSingletonFactory)
+}
+
+// RegisterModuleTypeCensusMutator registers the mutator that counts the module types and their
+// properties. It must run before the defaults are applied, so that only the properties that are
+// set in the module definitions are counted.
+func RegisterModuleTypeCensusMutator(ctx RegisterMutatorsContext) {
+	ctx.BottomUp("module_type_census", moduleTypeCensusMutator).Parallel()
+}
+
+var PrepareForTestWithModuleTypeCensus = GroupFixturePreparers(
+	FixtureRegisterWithContext(RegisterModuleTypeCensusBuildComponents),
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.PreArchMutators(RegisterModuleTypeCensusMutator)
+	}),
+)
+
+// CensusUsage is the number of uses of a module type, a property or a Soong config variable, in
+// total and by directory.
+type CensusUsage struct {
+	Count int            `json:"count"`
+	Dirs  map[string]int `json:"dirs"`
+}
+
+func (u *CensusUsage) add(dir string) {
+	u.Count++
+	u.Dirs[dir]++
+}
+
+// ModuleTypeCensusEntry is the number of modules of a module type and of the uses of each of its
+// properties.
+type ModuleTypeCensusEntry struct {
+	CensusUsage
+	Properties map[string]*CensusUsage `json:"properties"`
+}
+
+// ModuleTypeCensus is the census of the module types, keyed by module type, and of the Soong
+// config variables, keyed by namespace and variable joined with a dot.
+type ModuleTypeCensus struct {
+	ModuleTypes          map[string]*ModuleTypeCensusEntry `json:"module_types"`
+	SoongConfigVariables map[string]*CensusUsage           `json:"soong_config_variables"`
+
+	lock sync.Mutex
+}
+
+func newCensusUsage() *CensusUsage {
+	return &CensusUsage{Dirs: make(map[string]int)}
+}
+
+func (c *ModuleTypeCensus) add(moduleType, dir string, properties, soongConfigVariables []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := c.ModuleTypes[moduleType]
+	if entry == nil {
+		entry = &ModuleTypeCensusEntry{
+			CensusUsage: *newCensusUsage(),
+			Properties:  make(map[string]*CensusUsage),
+		}
+		c.ModuleTypes[moduleType] = entry
+	}
+	entry.add(dir)
+	for _, property := range properties {
+		if entry.Properties[property] == nil {
+			entry.Properties[property] = newCensusUsage()
+		}
+		entry.Properties[property].add(dir)
+	}
+	for _, variable := range soongConfigVariables {
+		if c.SoongConfigVariables[variable] == nil {
+			c.SoongConfigVariables[variable] = newCensusUsage()
+		}
+		c.SoongConfigVariables[variable].add(dir)
+	}
+}
+
+var moduleTypeCensusKey = NewOnceKey("moduleTypeCensus")
+
+func moduleTypeCensus(config Config) *ModuleTypeCensus {
+	return config.Once(moduleTypeCensusKey, func() interface{} {
+		return &ModuleTypeCensus{
+			ModuleTypes:          make(map[string]*ModuleTypeCensusEntry),
+			SoongConfigVariables: make(map[string]*CensusUsage),
+		}
+	}).(*ModuleTypeCensus)
+}
+
+func moduleTypeCensusMutator(ctx BottomUpMutatorContext) {
+	if !ctx.Config().ModuleTypeCensus() {
+		return
+	}
+	m := ctx.Module()
+	var properties []string
+	for _, p := range m.GetProperties() {
+		properties = append(properties, setProperties(reflect.ValueOf(p), "")...)
+	}
+	moduleTypeCensus(ctx.Config()).add(ctx.ModuleType(), ctx.ModuleDir(),
+		FirstUniqueStrings(properties), FirstUniqueStrings(m.base().soongConfigVariables))
+}
+
+// setProperties returns the names of the properties in v that are set, with the names of the
+// property structs that contain them joined with dots.
+func setProperties(v reflect.Value, prefix string) []string {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Elem().Kind() == reflect.Struct {
+			return setProperties(v.Elem(), prefix)
+		}
+		return []string{prefix}
+	case reflect.Struct:
+		var ret []string
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" || proptools.HasTag(field, "blueprint", "mutated") {
+				continue
+			}
+			name := prefix
+			if !field.Anonymous {
+				name = proptools.PropertyNameForField(field.Name)
+				if prefix != "" {
+					name = prefix + "." + name
+				}
+			}
+			ret = append(ret, setProperties(v.Field(i), name)...)
+		}
+		return ret
+	default:
+		if v.IsZero() {
+			return nil
+		}
+		return []string{prefix}
+	}
+}
+
+func moduleTypeCensusSingletonFactory() Singleton {
+	return &moduleTypeCensusSingleton{}
+}
+
+type moduleTypeCensusSingleton struct {
+	census Path
+}
+
+func (s *moduleTypeCensusSingleton) GenerateBuildActions(ctx SingletonContext) {
+	if !ctx.Config().ModuleTypeCensus() {
+		return
+	}
+
+	data, err := json.MarshalIndent(moduleTypeCensus(ctx.Config()), "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	census := PathForOutput(ctx, "module_type_census.json")
+	WriteFileRule(ctx, census, string(data))
+	s.census = census
+
+	ctx.Phony("module_type_census", census)
+}
+
+func (s *moduleTypeCensusSingleton) MakeVars(ctx MakeVarsContext) {
+	if s.census != nil {
+		ctx.DistForGroup("module_type_census", s.census)
+	}
+}
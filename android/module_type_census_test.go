@@ -0,0 +1,114 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+)
+
+var prepareForModuleTypeCensusTest = GroupFixturePreparers(
+	PrepareForTestWithModuleTypeCensus,
+	PrepareForTestWithDefaults,
+	PrepareForTestWithSoongConfigModuleBuildComponents,
+	prepareForSoongConfigTestModule,
+	FixtureWithRootAndroidBp(`
+		soong_config_bool_variable {
+			name: "feature1",
+		}
+
+		soong_config_module_type {
+			name: "acme_test",
+			module_type: "test",
+			config_namespace: "acme",
+			bool_variables: ["feature1"],
+			properties: ["cflags"],
+		}
+
+		test_defaults {
+			name: "defaults",
+			cflags: ["-DDEFAULTS"],
+		}
+
+		test {
+			name: "foo",
+			defaults: ["defaults"],
+		}
+	`),
+	FixtureAddTextFile("bar/Android.bp", `
+		acme_test {
+			name: "bar",
+			cflags: ["-DBAR"],
+			soong_config_variables: {
+				feature1: {
+					cflags: ["-DFEATURE1"],
+				},
+			},
+		}
+
+		test {
+			name: "baz",
+		}
+	`),
+)
+
+func TestModuleTypeCensus(t *testing.T) {
+	result := GroupFixturePreparers(
+		prepareForModuleTypeCensusTest,
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.Module_type_census = proptools.BoolPtr(true)
+		}),
+	).RunTest(t)
+
+	var census ModuleTypeCensus
+	content := ContentFromFileRuleForTests(t, result.SingletonForTests("module_type_census").Output("module_type_census.json"))
+	if err := json.Unmarshal([]byte(content), &census); err != nil {
+		t.Fatal(err)
+	}
+
+	test := census.ModuleTypes["test"]
+	if test == nil {
+		t.Fatalf("expected the test module type in the census, got %v", census.ModuleTypes)
+	}
+	AssertIntEquals(t, "test modules", 2, test.Count)
+	AssertDeepEquals(t, "test dirs", map[string]int{".": 1, "bar": 1}, test.Dirs)
+	AssertDeepEquals(t, "test defaults", &CensusUsage{Count: 1, Dirs: map[string]int{".": 1}}, test.Properties["defaults"])
+	// The cflags of foo come from its defaults.
+	if test.Properties["cflags"] != nil {
+		t.Errorf("expected no cflags for test modules, got %+v", test.Properties["cflags"])
+	}
+
+	acmeTest := census.ModuleTypes["acme_test"]
+	if acmeTest == nil {
+		t.Fatalf("expected the acme_test module type in the census, got %v", census.ModuleTypes)
+	}
+	AssertDeepEquals(t, "acme_test cflags", &CensusUsage{Count: 1, Dirs: map[string]int{"bar": 1}}, acmeTest.Properties["cflags"])
+
+	AssertDeepEquals(t, "test_defaults cflags", &CensusUsage{Count: 1, Dirs: map[string]int{".": 1}},
+		census.ModuleTypes["test_defaults"].Properties["cflags"])
+
+	AssertDeepEquals(t, "soong config variables", map[string]*CensusUsage{
+		"acme.feature1": {Count: 1, Dirs: map[string]int{"bar": 1}},
+	}, census.SoongConfigVariables)
+}
+
+func TestModuleTypeCensusDisabled(t *testing.T) {
+	result := prepareForModuleTypeCensusTest.RunTest(t)
+	if result.SingletonForTests("module_type_census").MaybeOutput("module_type_census.json").Rule != nil {
+		t.Errorf("expected no module type census without Module_type_census")
+	}
+}
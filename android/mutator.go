@@ -45,6 +45,7 @@ func RegisterMutatorsForApiBazelConversion(ctx *Context, preArchMutators []Regis
 func registerMutatorsForBazelConversion(ctx *Context, bp2buildMutators []RegisterMutatorFunc) {
 	mctx := &registerMutatorsContext{
 		bazelConversionMode: true,
+		phase:               "bp2build",
 	}
 
 	allMutators := append([]RegisterMutatorFunc{
@@ -80,15 +81,20 @@ func collateRegisteredMutators(preArch, preDeps, postDeps, finalDeps []RegisterM
 		}
 	}
 
+	mctx.phase = "pre_arch"
 	register(preArch)
 
+	mctx.phase = "pre_deps"
 	register(preDeps)
 
+	mctx.phase = "deps"
 	register([]RegisterMutatorFunc{registerDepsMutator})
 
+	mctx.phase = "post_deps"
 	register(postDeps)
 
 	mctx.finalPhase = true
+	mctx.phase = "final_deps"
 	register(finalDeps)
 
 	return mctx.mutators
@@ -98,6 +104,8 @@ type registerMutatorsContext struct {
 	mutators            sortableComponents
 	finalPhase          bool
 	bazelConversionMode bool
+	// The phase the mutators are registered in, recorded in the mutator pipeline.
+	phase string
 }
 
 type RegisterMutatorsContext interface {
@@ -144,6 +152,12 @@ var preArch = []RegisterMutatorFunc{
 	// This must run before the defaults so that defaults modules can pick up the package default.
 	RegisterLicensesPackageMapper,
 
+	// Count the module types and the properties set on them.
+	//
+	// This must run before the defaults mutators so that the properties applied from defaults
+	// modules are not counted for the modules that reference them.
+	RegisterModuleTypeCensusMutator,
+
 	// Apply properties from defaults modules to the referencing modules.
 	//
 	// Any mutators that are added before this will not see any modules created by
@@ -273,6 +287,11 @@ type TopDownMutatorContext interface {
 	// This function can be used to create alias definitions in a directory that is different
 	// from the directory of the visited Soong module.
 	CreateBazelTargetAliasInDir(dir string, name string, actual bazel.Label)
+
+	// MarkBp2buildUnconvertible records the reason that bp2build doesn't convert the module, for the
+	// bp2build conversion report. A converter that can't convert a module calls it instead of
+	// creating targets, with details like the name of the unsupported property.
+	MarkBp2buildUnconvertible(reasonType UnconvertedReasonType, detail string)
 }
 
 type topDownMutatorContext struct {
@@ -423,17 +442,18 @@ func (x *registerMutatorsContext) BottomUp(name string, m BottomUpMutator) Mutat
 	finalPhase := x.finalPhase
 	bazelConversionMode := x.bazelConversionMode
 	f := func(ctx blueprint.BottomUpMutatorContext) {
+		CheckAnalysisInterrupt(ctx.Config().(Config))
 		if a, ok := ctx.Module().(Module); ok {
 			m(bottomUpMutatorContextFactory(ctx, a, finalPhase, bazelConversionMode))
 		}
 	}
-	mutator := &mutator{name: x.mutatorName(name), bottomUpMutator: f}
+	mutator := &mutator{name: x.mutatorName(name), bottomUpMutator: f, phase: x.phase}
 	x.mutators = append(x.mutators, mutator)
 	return mutator
 }
 
 func (x *registerMutatorsContext) BottomUpBlueprint(name string, m blueprint.BottomUpMutator) MutatorHandle {
-	mutator := &mutator{name: name, bottomUpMutator: m}
+	mutator := &mutator{name: name, bottomUpMutator: m, phase: x.phase}
 	x.mutators = append(x.mutators, mutator)
 	return mutator
 }
@@ -541,6 +561,7 @@ type TransitionMutator interface {
 }
 
 type androidTransitionMutator struct {
+	name                string
 	finalPhase          bool
 	bazelConversionMode bool
 	mutator             TransitionMutator
@@ -550,7 +571,11 @@ func (a *androidTransitionMutator) Split(ctx blueprint.BaseModuleContext) []stri
 	if m, ok := ctx.Module().(Module); ok {
 		moduleContext := m.base().baseModuleContextFactory(ctx)
 		moduleContext.bazelConversionMode = a.bazelConversionMode
-		return a.mutator.Split(&moduleContext)
+		variations := a.mutator.Split(&moduleContext)
+		if len(variations) > 1 || len(variations) == 1 && variations[0] != "" {
+			moduleContext.Config().mutatorPipeline.record(a.name, ctx.ModuleType(), len(variations), 0)
+		}
+		return variations
 	} else {
 		return []string{""}
 	}
@@ -597,6 +622,7 @@ func (a *androidTransitionMutator) IncomingTransition(ctx blueprint.IncomingTran
 }
 
 func (a *androidTransitionMutator) Mutate(ctx blueprint.BottomUpMutatorContext, variation string) {
+	CheckAnalysisInterrupt(ctx.Config().(Config))
 	if am, ok := ctx.Module().(Module); ok {
 		a.mutator.Mutate(bottomUpMutatorContextFactory(ctx, am, a.finalPhase, a.bazelConversionMode), variation)
 	}
@@ -604,13 +630,15 @@ func (a *androidTransitionMutator) Mutate(ctx blueprint.BottomUpMutatorContext,
 
 func (x *registerMutatorsContext) Transition(name string, m TransitionMutator) {
 	atm := &androidTransitionMutator{
+		name:                name,
 		finalPhase:          x.finalPhase,
 		bazelConversionMode: x.bazelConversionMode,
 		mutator:             m,
 	}
 	mutator := &mutator{
 		name:              name,
-		transitionMutator: atm}
+		transitionMutator: atm,
+		phase:             x.phase}
 	x.mutators = append(x.mutators, mutator)
 }
 
@@ -623,6 +651,7 @@ func (x *registerMutatorsContext) mutatorName(name string) string {
 
 func (x *registerMutatorsContext) TopDown(name string, m TopDownMutator) MutatorHandle {
 	f := func(ctx blueprint.TopDownMutatorContext) {
+		CheckAnalysisInterrupt(ctx.Config().(Config))
 		if a, ok := ctx.Module().(Module); ok {
 			moduleContext := a.base().baseModuleContextFactory(ctx)
 			moduleContext.bazelConversionMode = x.bazelConversionMode
@@ -633,7 +662,7 @@ func (x *registerMutatorsContext) TopDown(name string, m TopDownMutator) Mutator
 			m(actx)
 		}
 	}
-	mutator := &mutator{name: x.mutatorName(name), topDownMutator: f}
+	mutator := &mutator{name: x.mutatorName(name), topDownMutator: f, phase: x.phase}
 	x.mutators = append(x.mutators, mutator)
 	return mutator
 }
@@ -655,6 +684,7 @@ func (mutator *mutator) register(ctx *Context) {
 	if mutator.parallel {
 		handle.Parallel()
 	}
+	ctx.config.mutatorPipeline.addMutator(mutator)
 }
 
 type MutatorHandle interface {
@@ -738,6 +768,13 @@ func (t *topDownMutatorContext) CreateBazelTargetAliasInDir(
 	mod.base().addBp2buildInfo(info)
 }
 
+func (t *topDownMutatorContext) MarkBp2buildUnconvertible(reasonType UnconvertedReasonType, detail string) {
+	t.Module().base().commonProperties.BazelConversionStatus.UnconvertedReason = UnconvertedReason{
+		Type:   reasonType,
+		Detail: detail,
+	}
+}
+
 // ApexAvailableTags converts the apex_available property value of an ApexModule
 // module and returns it as a list of keyed tags.
 func ApexAvailableTags(mod Module) bazel.StringListAttribute {
@@ -825,11 +862,33 @@ func (b *bottomUpMutatorContext) Rename(name string) {
 	b.Module().base().commonProperties.DebugName = name
 }
 
+// recordMutatorPipelineStats records the variants and dependencies created by the mutator for the
+// mutator pipeline.
+func (b *bottomUpMutatorContext) recordMutatorPipelineStats(variants, dependencies int) {
+	b.Config().mutatorPipeline.record(b.MutatorName(), b.ModuleType(), variants, dependencies)
+}
+
+// recordDependencies records the origin of the dependencies of the module on names when the
+// configuration needs them, and the names that resolve through aliases.
+func (b *bottomUpMutatorContext) recordDependencies(module blueprint.Module, tag blueprint.DependencyTag, names []string) {
+	m, ok := module.(Module)
+	if !ok {
+		return
+	}
+	if b.Config().recordDependencyOrigins {
+		m.base().addDependencyOrigin(tag, b.MutatorName(), names)
+	}
+	m.base().addAliasDependencies(b.Namespace(), names)
+}
+
 func (b *bottomUpMutatorContext) AddDependency(module blueprint.Module, tag blueprint.DependencyTag, name ...string) []blueprint.Module {
+	b.recordDependencies(module, tag, name)
+	b.recordMutatorPipelineStats(0, len(name))
 	return b.bp.AddDependency(module, tag, name...)
 }
 
 func (b *bottomUpMutatorContext) AddReverseDependency(module blueprint.Module, tag blueprint.DependencyTag, name string) {
+	b.recordMutatorPipelineStats(0, 1)
 	b.bp.AddReverseDependency(module, tag, name)
 }
 
@@ -839,6 +898,7 @@ func (b *bottomUpMutatorContext) CreateVariations(variations ...string) []Module
 	}
 
 	modules := b.bp.CreateVariations(variations...)
+	b.recordMutatorPipelineStats(len(variations), 0)
 
 	aModules := make([]Module, len(modules))
 	for i := range variations {
@@ -857,6 +917,7 @@ func (b *bottomUpMutatorContext) CreateLocalVariations(variations ...string) []M
 	}
 
 	modules := b.bp.CreateLocalVariations(variations...)
+	b.recordMutatorPipelineStats(len(variations), 0)
 
 	aModules := make([]Module, len(modules))
 	for i := range variations {
@@ -879,16 +940,21 @@ func (b *bottomUpMutatorContext) SetDefaultDependencyVariation(variation *string
 
 func (b *bottomUpMutatorContext) AddVariationDependencies(variations []blueprint.Variation, tag blueprint.DependencyTag,
 	names ...string) []blueprint.Module {
+	b.recordDependencies(b.Module(), tag, names)
+	b.recordMutatorPipelineStats(0, len(names))
 	return b.bp.AddVariationDependencies(variations, tag, names...)
 }
 
 func (b *bottomUpMutatorContext) AddFarVariationDependencies(variations []blueprint.Variation,
 	tag blueprint.DependencyTag, names ...string) []blueprint.Module {
 
+	b.recordDependencies(b.Module(), tag, names)
+	b.recordMutatorPipelineStats(0, len(names))
 	return b.bp.AddFarVariationDependencies(variations, tag, names...)
 }
 
 func (b *bottomUpMutatorContext) AddInterVariantDependency(tag blueprint.DependencyTag, from, to blueprint.Module) {
+	b.recordMutatorPipelineStats(0, 1)
 	b.bp.AddInterVariantDependency(tag, from, to)
 }
 
@@ -0,0 +1,164 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+)
+
+// The mutator pipeline is the list of the mutators in the order they run, with the variants and
+// dependencies that each of them created. It is recorded when soong_build runs with
+// --mutator_pipeline_file, so that the ordering constraints between mutators can be reasoned about
+// without reading the registration code.
+//
+// The variants and dependencies are counted when they are created through the Soong mutator
+// contexts, which includes the os and arch mutators. Mutators registered with BottomUpBlueprint
+// that use the Blueprint context directly are not counted.
+
+// MutatorPipelineEntry is a mutator of the pipeline.
+type MutatorPipelineEntry struct {
+	Name string `json:"name"`
+
+	// The phase the mutator was registered in, e.g. pre_arch or post_deps.
+	Phase string `json:"phase"`
+
+	// bottom_up, top_down or transition.
+	Kind     string `json:"kind"`
+	Parallel bool   `json:"parallel"`
+
+	// The number of variants the mutator split modules into, and the number of dependencies it
+	// added.
+	Variants     int `json:"variants"`
+	Dependencies int `json:"dependencies"`
+
+	// The module types whose modules the mutator created variants of or added dependencies to, by
+	// decreasing number of variants and dependencies.
+	ModuleTypes []*MutatorModuleTypeStats `json:"module_types,omitempty"`
+}
+
+// MutatorModuleTypeStats are the variants and dependencies a mutator created for the modules of a
+// module type.
+type MutatorModuleTypeStats struct {
+	ModuleType   string `json:"module_type"`
+	Variants     int    `json:"variants"`
+	Dependencies int    `json:"dependencies"`
+}
+
+// mutatorStats are the variants and dependencies created by a mutator by module type.
+type mutatorStats struct {
+	lock        sync.Mutex
+	moduleTypes map[string]*MutatorModuleTypeStats
+}
+
+// mutatorPipelineStats records the mutator pipeline of a run, see Config.mutatorPipeline.
+type mutatorPipelineStats struct {
+	// The mutators in the order they were registered in, which is the order they run in.
+	entries []*MutatorPipelineEntry
+	// The stats of the mutators by name. Mutators are added while registering them and the map is
+	// only read while the mutators run.
+	stats map[string]*mutatorStats
+}
+
+func newMutatorPipelineStats() *mutatorPipelineStats {
+	return &mutatorPipelineStats{
+		stats: make(map[string]*mutatorStats),
+	}
+}
+
+// addMutator adds a mutator to the pipeline when it is registered.
+func (p *mutatorPipelineStats) addMutator(m *mutator) {
+	if p == nil {
+		return
+	}
+	kind := "bottom_up"
+	parallel := m.parallel
+	if m.topDownMutator != nil {
+		kind = "top_down"
+	} else if m.transitionMutator != nil {
+		kind = "transition"
+		// Blueprint always runs transition mutators in parallel.
+		parallel = true
+	}
+	p.entries = append(p.entries, &MutatorPipelineEntry{
+		Name:     m.name,
+		Phase:    m.phase,
+		Kind:     kind,
+		Parallel: parallel,
+	})
+	p.stats[m.name] = &mutatorStats{moduleTypes: make(map[string]*MutatorModuleTypeStats)}
+}
+
+// record adds the variants and dependencies created by a mutator for a module.
+func (p *mutatorPipelineStats) record(mutatorName, moduleType string, variants, dependencies int) {
+	if p == nil || variants == 0 && dependencies == 0 {
+		return
+	}
+	s := p.stats[mutatorName]
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	m := s.moduleTypes[moduleType]
+	if m == nil {
+		m = &MutatorModuleTypeStats{ModuleType: moduleType}
+		s.moduleTypes[moduleType] = m
+	}
+	m.Variants += variants
+	m.Dependencies += dependencies
+}
+
+// MutatorPipeline returns the mutators of the pipeline in the order they ran, with the variants
+// and dependencies they created. It returns nil unless soong_build was run with
+// --mutator_pipeline_file.
+func MutatorPipeline(config Config) []*MutatorPipelineEntry {
+	p := config.mutatorPipeline
+	if p == nil {
+		return nil
+	}
+	ret := make([]*MutatorPipelineEntry, 0, len(p.entries))
+	for _, e := range p.entries {
+		entry := *e
+		entry.ModuleTypes = nil
+		for _, m := range p.stats[e.Name].moduleTypes {
+			moduleType := *m
+			entry.Variants += moduleType.Variants
+			entry.Dependencies += moduleType.Dependencies
+			entry.ModuleTypes = append(entry.ModuleTypes, &moduleType)
+		}
+		sort.Slice(entry.ModuleTypes, func(i, j int) bool {
+			a, b := entry.ModuleTypes[i], entry.ModuleTypes[j]
+			if a.Variants+a.Dependencies != b.Variants+b.Dependencies {
+				return a.Variants+a.Dependencies > b.Variants+b.Dependencies
+			}
+			return a.ModuleType < b.ModuleType
+		})
+		ret = append(ret, &entry)
+	}
+	return ret
+}
+
+// WriteMutatorPipeline writes the mutator pipeline as JSON.
+func WriteMutatorPipeline(w io.Writer, config Config) error {
+	data, err := json.MarshalIndent(MutatorPipeline(config), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+)
+
+func TestMutatorPipeline(t *testing.T) {
+	bp := `
+		test {
+			name: "foo",
+			deps_missing_deps: ["bar"],
+		}
+
+		test {
+			name: "bar",
+		}
+	`
+
+	result := GroupFixturePreparers(
+		FixtureModifyConfig(func(config Config) {
+			config.mutatorPipeline = newMutatorPipelineStats()
+		}),
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("test", mutatorTestModuleFactory)
+			ctx.PreArchMutators(func(ctx RegisterMutatorsContext) {
+				ctx.BottomUp("split", func(ctx BottomUpMutatorContext) {
+					ctx.CreateVariations("a", "b")
+				}).Parallel()
+				ctx.TopDown("nothing", func(ctx TopDownMutatorContext) {})
+			})
+		}),
+		FixtureWithRootAndroidBp(bp),
+	).RunTest(t)
+
+	entries := map[string]*MutatorPipelineEntry{}
+	index := map[string]int{}
+	for i, entry := range MutatorPipeline(result.Config) {
+		entries[entry.Name] = entry
+		index[entry.Name] = i
+	}
+	for _, name := range []string{"split", "nothing", "deps"} {
+		if entries[name] == nil {
+			t.Fatalf("expected %q in the mutator pipeline", name)
+		}
+	}
+	if !(index["split"] < index["nothing"] && index["nothing"] < index["deps"]) {
+		t.Errorf("expected split, nothing and deps in that order, got %v", index)
+	}
+
+	AssertDeepEquals(t, "split", &MutatorPipelineEntry{
+		Name:     "split",
+		Phase:    "pre_arch",
+		Kind:     "bottom_up",
+		Parallel: true,
+		Variants: 4,
+		ModuleTypes: []*MutatorModuleTypeStats{
+			{ModuleType: "test", Variants: 4},
+		},
+	}, entries["split"])
+
+	AssertDeepEquals(t, "nothing", &MutatorPipelineEntry{
+		Name:  "nothing",
+		Phase: "pre_arch",
+		Kind:  "top_down",
+	}, entries["nothing"])
+
+	// Both variants of foo depend on bar.
+	AssertDeepEquals(t, "deps", &MutatorPipelineEntry{
+		Name:         "deps",
+		Phase:        "deps",
+		Kind:         "bottom_up",
+		Parallel:     true,
+		Dependencies: 2,
+		ModuleTypes: []*MutatorModuleTypeStats{
+			{ModuleType: "test", Dependencies: 2},
+		},
+	}, entries["deps"])
+}
+
+func TestMutatorPipelineDisabled(t *testing.T) {
+	result := GroupFixturePreparers(
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("test", mutatorTestModuleFactory)
+		}),
+		FixtureWithRootAndroidBp(`test { name: "foo" }`),
+	).RunTest(t)
+
+	if pipeline := MutatorPipeline(result.Config); pipeline != nil {
+		t.Errorf("expected no mutator pipeline, got %v", pipeline)
+	}
+}
@@ -89,6 +89,9 @@ type NameResolver struct {
 
 	// func telling whether to export a namespace to Kati
 	namespaceExportFilter func(*Namespace) bool
+
+	// number of aliases registered in all namespaces, for atomic.AddInt32
+	aliasCount int32
 }
 
 // NameResolverConfig provides the subset of the Config interface needed by the
@@ -127,6 +130,7 @@ func (r *NameResolver) newNamespace(path string) *Namespace {
 	namespace := NewNamespace(path)
 
 	namespace.exportToKati = r.namespaceExportFilter(namespace)
+	namespace.resolver = r
 
 	return namespace
 }
@@ -223,6 +227,10 @@ func (r *NameResolver) NewModule(ctx blueprint.NamespaceContext, moduleGroup blu
 		// inform the module whether its namespace is one that we want to export to Make
 		amod.base().commonProperties.NamespaceExportedToMake = ns.exportToKati
 		amod.base().commonProperties.DebugName = module.Name()
+
+		if errs := ns.addAliases(amod, ctx.ModulePath()); len(errs) > 0 {
+			return nil, errs
+		}
 	}
 
 	return ns, nil
@@ -286,14 +294,27 @@ func (r *NameResolver) ModuleFromName(name string, namespace blueprint.Namespace
 			return blueprint.ModuleGroup{}, false
 		}
 		container := namespace.moduleContainer
-		return container.ModuleFromName(moduleName, nil)
+		if group, found := container.ModuleFromName(moduleName, nil); found {
+			return group, true
+		}
+		if alias, found := namespace.alias(moduleName); found {
+			return container.ModuleFromName(alias.target, nil)
+		}
+		return blueprint.ModuleGroup{}, false
 	}
-	for _, candidate := range r.getNamespacesToSearchForModule(namespace) {
+	searchOrder := r.getNamespacesToSearchForModule(namespace)
+	for _, candidate := range searchOrder {
 		group, found = candidate.moduleContainer.ModuleFromName(name, nil)
 		if found {
 			return group, true
 		}
 	}
+	// Only fall back to aliases once no namespace has a module with the name.
+	for _, candidate := range searchOrder {
+		if alias, found := candidate.alias(name); found {
+			return candidate.moduleContainer.ModuleFromName(alias.target, nil)
+		}
+	}
 	return blueprint.ModuleGroup{}, false
 
 }
@@ -406,10 +427,21 @@ type Namespace struct {
 	exportToKati bool
 
 	moduleContainer blueprint.NameInterface
+
+	// aliases of the modules in this namespace, see ModuleAlias
+	aliases     map[string]moduleAlias
+	aliasesLock sync.Mutex
+
+	// the resolver that created the namespace, used to look up aliases from module contexts
+	resolver *NameResolver
 }
 
 func NewNamespace(path string) *Namespace {
-	return &Namespace{Path: path, moduleContainer: blueprint.NewSimpleNameInterface()}
+	return &Namespace{
+		Path:            path,
+		moduleContainer: blueprint.NewSimpleNameInterface(),
+		aliases:         make(map[string]moduleAlias),
+	}
 }
 
 var _ blueprint.Namespace = (*Namespace)(nil)
@@ -0,0 +1,263 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"android/soong/shared"
+)
+
+// This file splits the ninja file that soong_build writes into shards with SOONG_NINJA_SHARDS,
+// so that the multi-GB ninja file of a large tree isn't written by a single writer. Blueprint
+// writes the ninja file as a header with the global variables, pools and rules, followed by a
+// section with the actions of each module and singleton. The header stays in the top-level ninja
+// file, and each section goes whole to one of the shards, which are written in parallel. The
+// top-level ninja file includes the shards with subninja statements, so the actions of a shard
+// can use the global rules and variables, and the rules and variables of a section stay local to
+// its shard.
+
+// ninjaSectionMarker starts the comment that blueprint writes at the start of the section of a
+// module or singleton.
+var ninjaSectionMarker = []byte("# # # # # # # #")
+
+// NinjaShardFiles returns the paths of the shards of the ninja file outFile.
+func NinjaShardFiles(outFile string, shards int) []string {
+	base := strings.TrimSuffix(outFile, ".ninja")
+	files := make([]string, shards)
+	for i := range files {
+		files[i] = fmt.Sprintf("%s.shard%d.ninja", base, i)
+	}
+	return files
+}
+
+// ninjaShard writes the sections sent to it to a shard in its own goroutine.
+type ninjaShard struct {
+	sections chan []byte
+	done     chan error
+	size     int
+}
+
+func newNinjaShard(w io.Writer) *ninjaShard {
+	s := &ninjaShard{
+		sections: make(chan []byte, 16),
+		done:     make(chan error, 1),
+	}
+	go func() {
+		var err error
+		for section := range s.sections {
+			if err == nil {
+				_, err = w.Write(section)
+			}
+		}
+		s.done <- err
+	}()
+	return s
+}
+
+// ninjaShardWriter splits the ninja file written to it between the top-level ninja file and
+// the shards. It implements both io.Writer and io.StringWriter, like the writers that blueprint
+// writes ninja files to.
+type ninjaShardWriter struct {
+	top    io.Writer
+	shards []*ninjaShard
+	// The paths of the shards, for the subninja statements.
+	shardFiles []string
+
+	// The incomplete last line written so far.
+	line []byte
+	// The section that is being written, or nil before the first section.
+	section []byte
+	err     error
+}
+
+func newNinjaShardWriter(top io.Writer, shards []io.Writer, shardFiles []string) *ninjaShardWriter {
+	w := &ninjaShardWriter{top: top, shardFiles: shardFiles}
+	for _, shard := range shards {
+		w.shards = append(w.shards, newNinjaShard(shard))
+	}
+	return w
+}
+
+func (w *ninjaShardWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.line = append(w.line, p...)
+			break
+		}
+		w.line = append(w.line, p[:i+1]...)
+		p = p[i+1:]
+		w.writeLine(w.line)
+		w.line = w.line[:0]
+	}
+	return n, w.err
+}
+
+func (w *ninjaShardWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *ninjaShardWriter) writeLine(line []byte) {
+	if bytes.HasPrefix(line, ninjaSectionMarker) {
+		w.flushSection()
+		w.section = []byte{}
+	}
+	if w.section == nil {
+		_, w.err = w.top.Write(line)
+	} else {
+		w.section = append(w.section, line...)
+	}
+}
+
+// flushSection sends the current section to the shard that has been sent the fewest bytes, so
+// that the shards have about the same size.
+func (w *ninjaShardWriter) flushSection() {
+	if len(w.section) == 0 {
+		return
+	}
+	smallest := w.shards[0]
+	for _, shard := range w.shards[1:] {
+		if shard.size < smallest.size {
+			smallest = shard
+		}
+	}
+	smallest.size += len(w.section)
+	smallest.sections <- w.section
+	w.section = nil
+}
+
+var ninjaPathEscaper = strings.NewReplacer("$", "$$", " ", "$ ", ":", "$:")
+
+// Close writes the last section and the subninja statements of the shards to the top-level ninja
+// file, and waits for the shards to be written.
+func (w *ninjaShardWriter) Close() error {
+	if len(w.line) > 0 {
+		w.writeLine(w.line)
+		w.line = nil
+	}
+	w.flushSection()
+	for _, shard := range w.shards {
+		close(shard.sections)
+	}
+	for _, shard := range w.shards {
+		if err := <-shard.done; err != nil && w.err == nil {
+			w.err = err
+		}
+	}
+	if w.err != nil {
+		return w.err
+	}
+	for _, file := range w.shardFiles {
+		if _, err := fmt.Fprintf(w.top, "\nsubninja %s\n", ninjaPathEscaper.Replace(file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteShardedNinjaFile writes the ninja file of ctx to outFile, relative to topDir, with its
+// build actions split into shards. It returns the paths of the shards, which the ninja file
+// depends on so that soong_build runs again when one of them is deleted.
+func WriteShardedNinjaFile(ctx *Context, topDir, outFile string, shards int) ([]string, error) {
+	shardFiles := NinjaShardFiles(outFile, shards)
+	if err := removeStaleNinjaShards(shared.JoinPath(topDir, outFile), shards); err != nil {
+		return nil, err
+	}
+
+	var writers []io.Writer
+	var closers []func() error
+	create := func(file string) (*bufio.Writer, error) {
+		f, err := os.Create(shared.JoinPath(topDir, file))
+		if err != nil {
+			return nil, err
+		}
+		buf := bufio.NewWriterSize(f, 16*1024*1024)
+		closers = append(closers, func() error {
+			if err := buf.Flush(); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		})
+		return buf, nil
+	}
+	closeAll := func() error {
+		var firstErr error
+		for _, c := range closers {
+			if err := c(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	for _, file := range shardFiles {
+		buf, err := create(file)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		writers = append(writers, buf)
+	}
+	// The top-level ninja file is closed last, once its shards are complete.
+	top, err := create(outFile)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+
+	w := newNinjaShardWriter(top, writers, shardFiles)
+	err = ctx.Context.WriteBuildFile(w)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := closeAll(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return shardFiles, nil
+}
+
+// removeStaleNinjaShards removes the shards of outFile from a previous run with more shards.
+func removeStaleNinjaShards(outFile string, shards int) error {
+	base := strings.TrimSuffix(outFile, ".ninja")
+	matches, err := filepath.Glob(base + ".shard*.ninja")
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		index := strings.TrimSuffix(strings.TrimPrefix(match, base+".shard"), ".ninja")
+		if i, err := strconv.Atoi(index); err == nil && i >= shards {
+			if err := os.Remove(match); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
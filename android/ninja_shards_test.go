@@ -0,0 +1,108 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const ninjaShardsTestHeader = `# ******************************************************************************
+# ***            This file is generated and should not be edited             ***
+# ******************************************************************************
+
+ninja_required_version = 1.7.0
+
+g.android.soong.cc = prebuilts/clang
+
+rule g.android.soong.Cp
+    command = cp $in $out
+
+`
+
+const ninjaShardsTestFoo = `# # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # #
+# Module:  foo
+# Variant: android_arm64
+
+rule m.foo_android_arm64.gen
+    command = gen $in $out
+
+build out/foo: m.foo_android_arm64.gen foo.in
+
+`
+
+const ninjaShardsTestBar = `# # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # #
+# Module:  bar
+# Variant: android_arm64
+
+build out/bar: g.android.soong.Cp bar.in
+
+`
+
+const ninjaShardsTestSingleton = `# # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # # #
+# Singleton: phony
+
+build all: phony out/foo out/bar
+`
+
+func TestNinjaShardWriter(t *testing.T) {
+	var top, shard0, shard1 strings.Builder
+	w := newNinjaShardWriter(&top, []io.Writer{&shard0, &shard1},
+		[]string{"out/soong/build.shard0.ninja", "out/soong/build shard1.ninja"})
+
+	// Blueprint writes the ninja file in pieces that don't line up with the lines.
+	contents := ninjaShardsTestHeader + ninjaShardsTestFoo + ninjaShardsTestBar + ninjaShardsTestSingleton
+	for i := 0; i < len(contents); i += 7 {
+		end := i + 7
+		if end > len(contents) {
+			end = len(contents)
+		}
+		if _, err := w.WriteString(contents[i:end]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	AssertStringEquals(t, "top-level ninja file", ninjaShardsTestHeader+
+		"\nsubninja out/soong/build.shard0.ninja\n"+
+		"\nsubninja out/soong/build$ shard1.ninja\n", top.String())
+	// Each section goes whole to the shard that has been sent the fewest bytes.
+	AssertStringEquals(t, "shard 0", ninjaShardsTestFoo, shard0.String())
+	AssertStringEquals(t, "shard 1", ninjaShardsTestBar+ninjaShardsTestSingleton, shard1.String())
+}
+
+func TestRemoveStaleNinjaShards(t *testing.T) {
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "build.ninja")
+	for _, file := range append(NinjaShardFiles(outFile, 4), outFile) {
+		if err := os.WriteFile(file, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := removeStaleNinjaShards(outFile, 2); err != nil {
+		t.Fatal(err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "*"))
+	AssertDeepEquals(t, "remaining files", []string{
+		filepath.Join(dir, "build.ninja"),
+		filepath.Join(dir, "build.shard0.ninja"),
+		filepath.Join(dir, "build.shard1.ninja"),
+	}, matches)
+}
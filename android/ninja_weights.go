@@ -0,0 +1,209 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// With --ninja_weight_file, soong_build writes the estimated duration of every action of the
+// build to a weight list, the lines "<output>,<weight>" that ninja reads with
+// -o usesweightlist=<file> to start the actions on the critical path first. The weight of an
+// action is 1 plus its estimated duration in milliseconds: its duration in the .ninja_log of the
+// previous build, or else the average duration of the actions of the same rule in that log, or
+// else 0.
+
+var (
+	ninjaWeightActionsKey = NewOnceKey("NinjaWeightActions")
+	ninjaWeightHistoryKey = NewOnceKey("NinjaWeightHistory")
+)
+
+func init() {
+	RegisterNinjaWeightsBuildComponents(InitRegistrationContext)
+}
+
+func RegisterNinjaWeightsBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("ninja_weights", ninjaWeightsSingletonFactory)
+}
+
+var PrepareForTestWithNinjaWeights = FixtureRegisterWithContext(RegisterNinjaWeightsBuildComponents)
+
+// ninjaWeightAction is the first output and the rule of an action.
+type ninjaWeightAction struct {
+	output string
+	rule   string
+}
+
+type ninjaWeightActions struct {
+	sync.Mutex
+	actions []ninjaWeightAction
+}
+
+func ninjaWeightActionsFor(config Config) *ninjaWeightActions {
+	return config.Once(ninjaWeightActionsKey, func() interface{} {
+		return &ninjaWeightActions{}
+	}).(*ninjaWeightActions)
+}
+
+// recordNinjaWeightAction records an action of the build for the weight list when soong_build
+// writes one.
+func recordNinjaWeightAction(config Config, params BuildParams) {
+	if config.ninjaWeightFile == "" || params.Rule == nil {
+		return
+	}
+	var output WritablePath
+	if params.Output != nil {
+		output = params.Output
+	} else if len(params.Outputs) > 0 {
+		output = params.Outputs[0]
+	} else {
+		return
+	}
+
+	actions := ninjaWeightActionsFor(config)
+	actions.Lock()
+	defer actions.Unlock()
+	actions.actions = append(actions.actions, ninjaWeightAction{
+		output: output.String(),
+		rule:   actionMetadataRuleName(params.Rule.String()),
+	})
+}
+
+// parseNinjaLog returns the duration in milliseconds of every output in a .ninja_log, whose lines
+// are "<start>\t<end>\t<mtime>\t<output>\t<command hash>". An output that was built more than
+// once has the duration of its last build.
+func parseNinjaLog(r io.Reader) (map[string]int, error) {
+	durations := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.HasPrefix(scanner.Text(), "#") {
+			continue
+		}
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("line %d: expected 5 fields, got %d", line, len(fields))
+		}
+		start, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid start time %q", line, fields[0])
+		}
+		end, err := strconv.Atoi(fields[1])
+		if err != nil || end < start {
+			return nil, fmt.Errorf("line %d: invalid end time %q", line, fields[1])
+		}
+		durations[fields[3]] = end - start
+	}
+	return durations, scanner.Err()
+}
+
+// ninjaWeight is an entry of the weight list.
+type ninjaWeight struct {
+	output string
+	weight int
+}
+
+// ninjaWeightsFor estimates the weights of the actions from the durations of the outputs of a
+// previous build, sorted by output.
+func ninjaWeightsFor(actions []ninjaWeightAction, durations map[string]int) []ninjaWeight {
+	type ruleTotal struct{ duration, actions int }
+	ruleTotals := make(map[string]*ruleTotal)
+	for _, action := range actions {
+		if duration, ok := durations[action.output]; ok {
+			if ruleTotals[action.rule] == nil {
+				ruleTotals[action.rule] = &ruleTotal{}
+			}
+			ruleTotals[action.rule].duration += duration
+			ruleTotals[action.rule].actions++
+		}
+	}
+
+	weights := make([]ninjaWeight, 0, len(actions))
+	for _, action := range actions {
+		// The weight of an action is at least 1, the weight of the actions that aren't in the list.
+		weight := 1
+		if duration, ok := durations[action.output]; ok {
+			weight += duration
+		} else if total := ruleTotals[action.rule]; total != nil {
+			weight += total.duration / total.actions
+		}
+		weights = append(weights, ninjaWeight{action.output, weight})
+	}
+	sort.Slice(weights, func(i, j int) bool {
+		return weights[i].output < weights[j].output
+	})
+	return weights
+}
+
+func ninjaWeightsSingletonFactory() Singleton {
+	return &ninjaWeightsSingleton{}
+}
+
+// ninjaWeightsSingleton reads the .ninja_log of the previous build when soong_build writes a weight
+// list. The log isn't a dependency of the ninja file, the weights are updated by the next run of
+// soong_build for any other reason.
+type ninjaWeightsSingleton struct{}
+
+func (ninjaWeightsSingleton) GenerateBuildActions(ctx SingletonContext) {
+	if ctx.Config().ninjaWeightFile == "" {
+		return
+	}
+
+	durations := make(map[string]int)
+	ninjaLog := filepath.Join(ctx.Config().OutDir(), ".ninja_log")
+	if f, err := os.Open(absolutePath(ninjaLog)); err == nil {
+		defer f.Close()
+		if durations, err = parseNinjaLog(f); err != nil {
+			// A corrupt log only loses the history, the weights fall back to 1.
+			durations = make(map[string]int)
+		}
+	} else if !os.IsNotExist(err) {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+
+	ctx.Config().Once(ninjaWeightHistoryKey, func() interface{} {
+		return durations
+	})
+}
+
+// WriteNinjaWeightFile writes the weight list of the actions of the build to the --ninja_weight_file
+// if it is set. It is written directly instead of by a build action as ninja reads it before it
+// runs any action.
+func WriteNinjaWeightFile(config Config) error {
+	if config.ninjaWeightFile == "" {
+		return nil
+	}
+	durations, _ := config.Peek(ninjaWeightHistoryKey)
+	if durations == nil {
+		durations = map[string]int{}
+	}
+	actions := ninjaWeightActionsFor(config)
+	actions.Lock()
+	defer actions.Unlock()
+
+	var sb strings.Builder
+	for _, weight := range ninjaWeightsFor(actions.actions, durations.(map[string]int)) {
+		fmt.Fprintf(&sb, "%s,%d\n", weight.output, weight.weight)
+	}
+	return os.WriteFile(absolutePath(config.ninjaWeightFile), []byte(sb.String()), 0666)
+}
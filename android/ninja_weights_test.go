@@ -0,0 +1,95 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNinjaLog(t *testing.T) {
+	durations, err := parseNinjaLog(strings.NewReader(strings.Join([]string{
+		"# ninja log v5",
+		"0\t100\t0\tout/a\t1",
+		"10\t20\t0\tout/b\t2",
+		"200\t500\t0\tout/a\t1",
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int{"out/a": 300, "out/b": 10}
+	if !reflect.DeepEqual(durations, expected) {
+		t.Errorf("expected %v, got %v", expected, durations)
+	}
+
+	for _, log := range []string{"0\t100\tout/a", "x\t100\t0\tout/a\t1", "100\t0\t0\tout/a\t1"} {
+		if _, err := parseNinjaLog(strings.NewReader(log)); err == nil {
+			t.Errorf("expected an error for %q", log)
+		}
+	}
+}
+
+func TestNinjaWeightsFor(t *testing.T) {
+	actions := []ninjaWeightAction{
+		{"out/c", "cc"},
+		{"out/a", "cc"},
+		{"out/b", "cc"},
+		{"out/d", "ld"},
+	}
+	durations := map[string]int{"out/a": 100, "out/b": 300, "out/old": 1000}
+	expected := []ninjaWeight{
+		{"out/a", 101},
+		{"out/b", 301},
+		{"out/c", 201},
+		{"out/d", 1},
+	}
+	if weights := ninjaWeightsFor(actions, durations); !reflect.DeepEqual(weights, expected) {
+		t.Errorf("expected %v, got %v", expected, weights)
+	}
+}
+
+func TestWriteNinjaWeightFile(t *testing.T) {
+	weightFile := filepath.Join(t.TempDir(), "weights")
+	result := GroupFixturePreparers(
+		prepareForActionMetadataTest,
+		PrepareForTestWithNinjaWeights,
+		FixtureModifyConfig(func(config Config) {
+			config.ninjaWeightFile = weightFile
+			stable := filepath.Join(config.SoongOutDir(), ".intermediates/foo/stable")
+			log := fmt.Sprintf("# ninja log v5\n0\t40\t0\t%s\t1\n", stable)
+			if err := os.WriteFile(filepath.Join(config.OutDir(), ".ninja_log"), []byte(log), 0666); err != nil {
+				t.Fatal(err)
+			}
+		}),
+	).RunTest(t)
+
+	if err := WriteNinjaWeightFile(result.Config); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(weightFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foo := result.ModuleForTests("foo", "")
+	stable := foo.Output("stable").Output.String()
+	volatile := foo.Output("volatile").Output.String()
+	AssertStringDoesContain(t, "weight of the action in the log", string(data), stable+",41\n")
+	AssertStringDoesContain(t, "weight of an action of the same rule", string(data), volatile+",41\n")
+}
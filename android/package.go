@@ -45,6 +45,11 @@ type bazelPackageAttributes struct {
 	Default_package_metadata bazel.LabelListAttribute
 }
 
+// The legacy licenses() declaration of a package, see bp2build's licensesTemplate.
+type bazelLicensesAttributes struct {
+	License_types []string
+}
+
 type packageModule struct {
 	ModuleBase
 	BazelModuleBase
@@ -84,6 +89,48 @@ func (p *packageModule) ConvertWithBp2build(ctx TopDownMutatorContext) {
 			// FIXME(asmundak): once b/221436821 is resolved
 			Default_visibility: []string{"//visibility:public"},
 		})
+
+	if licenseTypes := p.bazelLicenseTypes(ctx); len(licenseTypes) > 0 {
+		ctx.CreateBazelTargetModule(
+			bazel.BazelTargetModuleProperties{
+				Rule_class: "licenses",
+			},
+			CommonAttributes{},
+			&bazelLicensesAttributes{
+				License_types: licenseTypes,
+			})
+	}
+}
+
+// bazelLicenseTypes returns the license types of the package for Bazel's licenses() function,
+// from the conditions of the license kinds of its default applicable licenses. The license and
+// license_kind modules are looked up by name, as there are no dependencies during bp2build.
+func (p *packageModule) bazelLicenseTypes(ctx TopDownMutatorContext) []string {
+	var licenseTypes []string
+	for _, name := range p.properties.Default_applicable_licenses {
+		module, ok := ctx.ModuleFromName(name)
+		if !ok {
+			continue
+		}
+		license, ok := module.(*licenseModule)
+		if !ok {
+			continue
+		}
+		for _, kindName := range license.properties.License_kinds {
+			kindModule, ok := ctx.ModuleFromName(kindName)
+			if !ok {
+				continue
+			}
+			if kind, ok := kindModule.(*licenseKindModule); ok {
+				for _, condition := range kind.properties.Conditions {
+					if licenseType, ok := bazelLicenseType(condition); ok {
+						licenseTypes = append(licenseTypes, licenseType)
+					}
+				}
+			}
+		}
+	}
+	return SortedUniqueStrings(licenseTypes)
 }
 
 func (p *packageModule) GenerateAndroidBuildActions(ModuleContext) {
@@ -118,7 +118,7 @@ func (p PackageContext) PoolFunc(name string,
 func (p PackageContext) RuleFunc(name string,
 	f func(PackageRuleContext) blueprint.RuleParams, argNames ...string) blueprint.Rule {
 
-	return p.PackageContext.RuleFunc(name, func(config interface{}) (blueprint.RuleParams, error) {
+	return p.recordRule(name, func(config interface{}) (blueprint.RuleParams, error) {
 		ctx := &configErrorWrapper{p, config.(Config), nil}
 		params := f(ctx)
 		if len(ctx.errors) > 0 {
@@ -133,6 +133,19 @@ func (p PackageContext) RuleFunc(name string,
 	}, argNames...)
 }
 
+// packageRuleParams holds the functions that return the params of the rules defined by the
+// PackageContexts of Soong, so that the commands of their actions can be explained. Rules are
+// only defined during Go package initialization, so the map isn't modified afterwards.
+var packageRuleParams = make(map[blueprint.Rule]func(config interface{}) (blueprint.RuleParams, error))
+
+func (p PackageContext) recordRule(name string,
+	f func(config interface{}) (blueprint.RuleParams, error), argNames ...string) blueprint.Rule {
+
+	rule := p.PackageContext.RuleFunc(name, f, argNames...)
+	packageRuleParams[rule] = f
+	return rule
+}
+
 // SourcePathVariable returns a Variable whose value is the source directory
 // appended with the supplied path. It may only be called during a Go package's
 // initialization - either from the init() function or as part of a
@@ -258,7 +271,7 @@ type RemoteRuleSupports struct {
 func (p PackageContext) AndroidRemoteStaticRule(name string, supports RemoteRuleSupports, params blueprint.RuleParams,
 	argNames ...string) blueprint.Rule {
 
-	return p.PackageContext.RuleFunc(name, func(config interface{}) (blueprint.RuleParams, error) {
+	return p.recordRule(name, func(config interface{}) (blueprint.RuleParams, error) {
 		ctx := &configErrorWrapper{p, config.(Config), nil}
 		if ctx.Config().UseGoma() && !supports.Goma {
 			// When USE_GOMA=true is set and the rule is not supported by goma, restrict jobs to the
@@ -289,6 +302,15 @@ func (p PackageContext) RemoteStaticRules(name string, ruleParams blueprint.Rule
 		p.AndroidRemoteStaticRule(name+"RE", RemoteRuleSupports{RBE: true}, ruleParamsRE, append(commonArgs, reArgs...)...)
 }
 
+// HighmemStaticRule returns a locally executable rule in the highmem pool based on the given
+// RuleParams, which may be the RuleParams of RemoteStaticRules, for the actions that are estimated
+// to need significant RAM, see UseHighmemPool.
+func (p PackageContext) HighmemStaticRule(name string, ruleParams blueprint.RuleParams, argNames ...string) blueprint.Rule {
+	ruleParams.Command = strings.ReplaceAll(ruleParams.Command, "$reTemplate", "")
+	ruleParams.Pool = highmemPool
+	return p.AndroidStaticRule(name, ruleParams, argNames...)
+}
+
 // MultiCommandStaticRules returns a pair of rules based on the given RuleParams, where the first
 // rule is a locally executable rule and the second rule is a remotely executable rule. This
 // function supports multiple remote execution wrappers placed in the template when commands are
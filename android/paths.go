@@ -689,7 +689,8 @@ func expandOneSrcPath(input sourcePathInput) (Paths, error) {
 // It intended for use in globs that only list files that exist, so it allows '$' in
 // filenames.
 func pathsForModuleSrcFromFullPath(ctx EarlyModulePathContext, paths []string, incDirs bool) Paths {
-	prefix := ctx.ModuleDir() + "/"
+	srcDir := moduleSrcDir(ctx)
+	prefix := srcDir + "/"
 	if prefix == "./" {
 		prefix = ""
 	}
@@ -704,7 +705,7 @@ func pathsForModuleSrcFromFullPath(ctx EarlyModulePathContext, paths []string, i
 			continue
 		}
 
-		srcPath, err := safePathForSource(ctx, ctx.ModuleDir(), path[len(prefix):])
+		srcPath, err := safePathForSource(ctx, srcDir, path[len(prefix):])
 		if err != nil {
 			reportPathError(ctx, err)
 			continue
@@ -725,7 +726,7 @@ func PathsWithOptionalDefaultForModuleSrc(ctx ModuleMissingDepsPathContext, inpu
 	}
 	// Use Glob so that if the default doesn't exist, a dependency is added so that when it
 	// is created, we're run again.
-	path := filepath.Join(ctx.ModuleDir(), def)
+	path := filepath.Join(moduleSrcDir(ctx), def)
 	return Glob(ctx, path, nil)
 }
 
@@ -1445,7 +1446,7 @@ func pathForModuleSrc(ctx EarlyModulePathContext, paths ...string) SourcePath {
 		reportPathError(ctx, err)
 	}
 
-	path, err := pathForSource(ctx, ctx.ModuleDir(), p)
+	path, err := pathForSource(ctx, moduleSrcDir(ctx), p)
 	if err != nil {
 		reportPathError(ctx, err)
 	}
@@ -1711,6 +1712,16 @@ func (p InstallPath) withRel(rel string) InstallPath {
 	return p
 }
 
+// relocate returns the InstallPath for dir relative to the root of the partition of p.
+func (p InstallPath) relocate(ctx PathContext, dir string) InstallPath {
+	path, err := validatePath(dir)
+	if err != nil {
+		reportPathError(ctx, err)
+	}
+	p.basePath = basePath{p.partitionDir, ""}.withRel(path)
+	return p
+}
+
 // Deprecated: ToMakePath is a noop, PathForModuleInstall always returns Make paths when building
 // embedded in Make.
 func (p InstallPath) ToMakePath() InstallPath {
@@ -260,6 +260,18 @@ func TestPathForModuleInstall(t *testing.T) {
 			out:          "host/linux-x86/bin/my_test",
 			partitionDir: "host/linux-x86",
 		},
+		{
+			name: "wasi binary",
+			ctx: &testModuleInstallPathContext{
+				baseModuleContext: baseModuleContext{
+					os:     Wasi,
+					target: Target{Os: Wasi, Arch: Arch{ArchType: Wasm32}},
+				},
+			},
+			in:           []string{"bin", "my_test.wasm"},
+			out:          "host/wasi-wasm32/bin/my_test.wasm",
+			partitionDir: "host/wasi-wasm32",
+		},
 
 		{
 			name: "system binary",
@@ -0,0 +1,82 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PrebuiltArtifactProperties describe the contents of a prebuilt APK or APEX. The ones that are
+// set are checked against the artifact itself at build time, so that wrong metadata fails the
+// build instead of being discovered on device.
+type PrebuiltArtifactProperties struct {
+	Artifact_contents struct {
+		// The minSdkVersion declared in the manifest of the artifact. For APKs, the dex files must
+		// also be in a format that this API level supports.
+		Min_sdk_version *string
+
+		// The native libraries embedded in the artifact, all of them, relative to lib/ for APKs,
+		// e.g. "arm64-v8a/libfoo.so", and to the root of the payload for APEXes, e.g.
+		// "lib64/libfoo.so". An empty list checks that the artifact embeds no native library.
+		Native_libs []string
+
+		// Whether the artifact contains dex code. Only supported for APKs.
+		Has_code *bool
+	}
+}
+
+// CheckPrebuiltArtifact creates the rule that checks a prebuilt APK or APEX against the
+// artifact_contents properties of its module, and returns its timestamp file, or nil if none of
+// them is set. The installation of the artifact should depend on it.
+func CheckPrebuiltArtifact(ctx ModuleContext, props *PrebuiltArtifactProperties, artifact Path) Path {
+	contents := props.Artifact_contents
+	if contents.Min_sdk_version == nil && contents.Native_libs == nil && contents.Has_code == nil {
+		return nil
+	}
+
+	isApex := strings.HasSuffix(artifact.Base(), ".apex") || strings.HasSuffix(artifact.Base(), ".capex")
+	if isApex && contents.Has_code != nil {
+		ctx.PropertyErrorf("artifact_contents.has_code", "is only supported for APKs")
+	}
+
+	timestamp := PathForModuleOut(ctx, "check_prebuilt_artifact.timestamp")
+	rule := NewRuleBuilder(pctx, ctx)
+	cmd := rule.Command().BuiltTool("check_prebuilt_artifact")
+	if contents.Min_sdk_version != nil {
+		cmd.FlagWithInput("--aapt2 ", ctx.Config().HostToolPath(ctx, "aapt2")).
+			FlagWithArg("--min-sdk-version ", *contents.Min_sdk_version)
+	}
+	if contents.Native_libs != nil {
+		if len(contents.Native_libs) == 0 {
+			cmd.Flag("--no-native-libs")
+		}
+		cmd.FlagForEachArg("--native-lib ", contents.Native_libs)
+		if isApex {
+			cmd.Flag("--deapexer").BuiltTool("deapexer").
+				Flag("--debugfs").BuiltTool("debugfs").
+				Flag("--blkid").BuiltTool("blkid").
+				Flag("--fsckerofs").BuiltTool("fsck.erofs")
+		}
+	}
+	if contents.Has_code != nil {
+		cmd.FlagWithArg("--has-code ", strconv.FormatBool(*contents.Has_code))
+	}
+	cmd.Input(artifact)
+	rule.Command().Text("touch").Output(timestamp)
+	rule.Build("check_prebuilt_artifact", "check prebuilt artifact "+ctx.ModuleName())
+
+	return timestamp
+}
@@ -0,0 +1,444 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// This file evaluates the queries of soong_build --query over the analyzed module graph, so that
+// questions like "what does libfoo depend on" or "which cc_library modules set this property" are
+// answered from the module graph instead of by searching the JSON module graph or build.ninja.
+//
+// A query is one of:
+//
+//	name                    the module called name, or all the modules for *
+//	deps(q)                 the modules of q and their transitive dependencies
+//	deps(q, depth)          the same, up to depth dependencies away from the modules of q
+//	rdeps(q)                the modules of q and the modules that transitively depend on them
+//	rdeps(q, depth)         the same, up to depth dependencies away from the modules of q
+//	kind(pattern, q)        the modules of q whose module type matches the regular expression
+//	attr(name, pattern, q)  the modules of q with a variant whose property name, e.g. "srcs" or
+//	                        "target.host.cflags", has a value matching the regular expression
+//
+// Arguments that contain spaces, commas or parentheses are quoted with double quotes. The queries
+// work on modules, a module matches when any of its variants matches.
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+// queryGraph is the module graph of a query, by module name.
+type queryGraph struct {
+	ctx      *Context
+	variants map[string][]blueprint.Module
+	deps     map[string]map[string]bool
+	rdeps    map[string]map[string]bool
+}
+
+func newQueryGraph(ctx *Context) *queryGraph {
+	g := &queryGraph{
+		ctx:      ctx,
+		variants: make(map[string][]blueprint.Module),
+		deps:     make(map[string]map[string]bool),
+		rdeps:    make(map[string]map[string]bool),
+	}
+	addEdge := func(edges map[string]map[string]bool, from, to string) {
+		if edges[from] == nil {
+			edges[from] = make(map[string]bool)
+		}
+		edges[from][to] = true
+	}
+	ctx.VisitAllModules(func(module blueprint.Module) {
+		name := ctx.ModuleName(module)
+		g.variants[name] = append(g.variants[name], module)
+		ctx.VisitDirectDeps(module, func(dep blueprint.Module) {
+			// Dependencies between the variants of a module are not dependencies of the module.
+			if depName := ctx.ModuleName(dep); depName != name {
+				addEdge(g.deps, name, depName)
+				addEdge(g.rdeps, depName, name)
+			}
+		})
+	})
+	return g
+}
+
+// closure returns the modules and the modules reachable from them through edges, up to depth edges
+// away, or without limit for a negative depth.
+func (g *queryGraph) closure(modules map[string]bool, edges map[string]map[string]bool, depth int) map[string]bool {
+	result := make(map[string]bool, len(modules))
+	frontier := make([]string, 0, len(modules))
+	for m := range modules {
+		result[m] = true
+		frontier = append(frontier, m)
+	}
+	for ; len(frontier) > 0 && depth != 0; depth-- {
+		var next []string
+		for _, m := range frontier {
+			for to := range edges[m] {
+				if !result[to] {
+					result[to] = true
+					next = append(next, to)
+				}
+			}
+		}
+		frontier = next
+	}
+	return result
+}
+
+// queryExpr is a parsed query.
+type queryExpr interface {
+	eval(g *queryGraph) (map[string]bool, error)
+}
+
+type queryName string
+
+func (q queryName) eval(g *queryGraph) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if q == "*" {
+		for name := range g.variants {
+			result[name] = true
+		}
+		return result, nil
+	}
+	if _, ok := g.variants[string(q)]; !ok {
+		return nil, fmt.Errorf("no module named %q", string(q))
+	}
+	result[string(q)] = true
+	return result, nil
+}
+
+type queryDeps struct {
+	reverse bool
+	depth   int
+	arg     queryExpr
+}
+
+func (q queryDeps) eval(g *queryGraph) (map[string]bool, error) {
+	modules, err := q.arg.eval(g)
+	if err != nil {
+		return nil, err
+	}
+	if q.reverse {
+		return g.closure(modules, g.rdeps, q.depth), nil
+	}
+	return g.closure(modules, g.deps, q.depth), nil
+}
+
+type queryKind struct {
+	pattern *regexp.Regexp
+	arg     queryExpr
+}
+
+func (q queryKind) eval(g *queryGraph) (map[string]bool, error) {
+	modules, err := q.arg.eval(g)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool)
+	for name := range modules {
+		for _, variant := range g.variants[name] {
+			if q.pattern.MatchString(g.ctx.ModuleType(variant)) {
+				result[name] = true
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+type queryAttr struct {
+	name    string
+	pattern *regexp.Regexp
+	arg     queryExpr
+}
+
+func (q queryAttr) eval(g *queryGraph) (map[string]bool, error) {
+	modules, err := q.arg.eval(g)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool)
+	for name := range modules {
+		for _, variant := range g.variants[name] {
+			if q.matches(variant) {
+				result[name] = true
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// matches returns true if the property of a module variant has a value matching the pattern. A
+// list matches when any of its elements matches, unset properties never match.
+func (q queryAttr) matches(module blueprint.Module) bool {
+	m, ok := module.(Module)
+	if !ok {
+		return false
+	}
+	fields := strings.Split(q.name, ".")
+	for _, props := range m.GetProperties() {
+		v := reflect.ValueOf(props)
+		for _, field := range fields {
+			for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+				if v.IsNil() {
+					break
+				}
+				v = v.Elem()
+			}
+			if v.Kind() != reflect.Struct {
+				v = reflect.Value{}
+				break
+			}
+			v = v.FieldByName(proptools.FieldNameForProperty(field))
+			if !v.IsValid() {
+				break
+			}
+		}
+		if !v.IsValid() {
+			continue
+		}
+		for _, value := range queryAttrValues(v) {
+			if q.pattern.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// queryAttrValues returns the values of a property as strings, or nil if it is unset.
+func queryAttrValues(v reflect.Value) []string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return []string{v.String()}
+	case reflect.Bool:
+		return []string{strconv.FormatBool(v.Bool())}
+	case reflect.Int, reflect.Int64:
+		return []string{strconv.FormatInt(v.Int(), 10)}
+	case reflect.Slice:
+		var values []string
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, queryAttrValues(v.Index(i))...)
+		}
+		return values
+	}
+	return nil
+}
+
+// queryParser is a recursive descent parser of queries.
+type queryParser struct {
+	tokens []string
+	pos    int
+}
+
+// tokenizeQuery splits a query into words, quoted strings, and the punctuation "(", ")" and ",".
+func tokenizeQuery(query string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			// Quoted strings are marked with a leading quote so that they are never punctuation.
+			tokens = append(tokens, `"`+query[i+1:i+1+end])
+			i += end + 2
+		default:
+			end := strings.IndexAny(query[i:], " \t\n(),\"")
+			if end < 0 {
+				end = len(query) - i
+			}
+			tokens = append(tokens, query[i:i+end])
+			i += end
+		}
+	}
+	return tokens, nil
+}
+
+func (p *queryParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token
+}
+
+func (p *queryParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) expect(token string) error {
+	if next := p.next(); next != token {
+		if next == "" {
+			return fmt.Errorf("expected %q at the end of the query", token)
+		}
+		return fmt.Errorf("expected %q, got %q", token, strings.TrimPrefix(next, `"`))
+	}
+	return nil
+}
+
+// word returns the next word or quoted string.
+func (p *queryParser) word(what string) (string, error) {
+	token := p.next()
+	if token == "" {
+		return "", fmt.Errorf("expected %s at the end of the query", what)
+	}
+	if token == "(" || token == ")" || token == "," {
+		return "", fmt.Errorf("expected %s, got %q", what, token)
+	}
+	return strings.TrimPrefix(token, `"`), nil
+}
+
+func (p *queryParser) pattern() (*regexp.Regexp, error) {
+	pattern, err := p.word("a pattern")
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile(pattern)
+}
+
+func (p *queryParser) expr() (queryExpr, error) {
+	name, err := p.word("a module name or a function")
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != "(" {
+		return queryName(name), nil
+	}
+	p.next()
+
+	var expr queryExpr
+	switch name {
+	case "deps", "rdeps":
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		depth := -1
+		if p.peek() == "," {
+			p.next()
+			word, err := p.word("a depth")
+			if err != nil {
+				return nil, err
+			}
+			if depth, err = strconv.Atoi(word); err != nil || depth < 0 {
+				return nil, fmt.Errorf("%s: depth must be a non-negative number, got %q", name, word)
+			}
+		}
+		expr = queryDeps{reverse: name == "rdeps", depth: depth, arg: arg}
+	case "kind":
+		pattern, err := p.pattern()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		expr = queryKind{pattern: pattern, arg: arg}
+	case "attr":
+		attr, err := p.word("a property name")
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		pattern, err := p.pattern()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		expr = queryAttr{name: attr, pattern: pattern, arg: arg}
+	default:
+		return nil, fmt.Errorf("unknown function %q, expected deps, rdeps, kind or attr", name)
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// parseQuery parses a query of soong_build --query.
+func parseQuery(query string) (queryExpr, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	expr, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.next(); token != "" {
+		return nil, fmt.Errorf("unexpected %q after the end of the query", strings.TrimPrefix(token, `"`))
+	}
+	return expr, nil
+}
+
+// Query returns the sorted names of the modules of ctx that match a query of soong_build --query.
+func Query(ctx *Context, query string) ([]string, error) {
+	expr, err := parseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %s", query, err)
+	}
+	modules, err := expr.eval(newQueryGraph(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return SortedKeys(modules), nil
+}
+
+// WriteQueryResult writes the names of the modules that match a query, one per line.
+func WriteQueryResult(w io.Writer, modules []string) error {
+	for _, module := range modules {
+		if _, err := fmt.Fprintln(w, module); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+)
+
+type queryTestModule struct {
+	ModuleBase
+	properties struct {
+		Deps      []string
+		Cflags    []string
+		Host_only *bool
+		Nested    struct {
+			Value *string
+		}
+	}
+}
+
+func (m *queryTestModule) DepsMutator(ctx BottomUpMutatorContext) {
+	ctx.AddDependency(ctx.Module(), moduleDepsTestDepTag{}, m.properties.Deps...)
+}
+
+func (m *queryTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {}
+
+func queryTestModuleFactory() Module {
+	m := &queryTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidModule(m)
+	return m
+}
+
+func TestQuery(t *testing.T) {
+	result := GroupFixturePreparers(
+		FixtureRegisterWithContext(func(ctx RegistrationContext) {
+			ctx.RegisterModuleType("query_library", queryTestModuleFactory)
+			ctx.RegisterModuleType("query_binary", queryTestModuleFactory)
+		}),
+		FixtureWithRootAndroidBp(`
+			query_library {
+				name: "liba",
+				cflags: ["-DA", "-Wall"],
+			}
+
+			query_library {
+				name: "libb",
+				deps: ["liba"],
+				nested: {
+					value: "hello world",
+				},
+			}
+
+			query_binary {
+				name: "bin",
+				deps: ["libb"],
+				host_only: true,
+			}
+
+			query_binary {
+				name: "other",
+				deps: ["liba"],
+			}
+		`),
+	).RunTest(t)
+
+	testCases := []struct {
+		query    string
+		expected []string
+		err      string
+	}{
+		{query: "liba", expected: []string{"liba"}},
+		{query: "deps(bin)", expected: []string{"bin", "liba", "libb"}},
+		{query: "deps(bin, 1)", expected: []string{"bin", "libb"}},
+		{query: "deps(bin, 0)", expected: []string{"bin"}},
+		{query: "rdeps(liba)", expected: []string{"bin", "liba", "libb", "other"}},
+		{query: "rdeps(liba, 1)", expected: []string{"liba", "libb", "other"}},
+		{query: "kind(query_binary, *)", expected: []string{"bin", "other"}},
+		{query: "kind(^query_lib, rdeps(liba))", expected: []string{"liba", "libb"}},
+		{query: "attr(cflags, -Wall, *)", expected: []string{"liba"}},
+		{query: "attr(host_only, true, *)", expected: []string{"bin"}},
+		{query: `attr(nested.value, "^hello w", deps(bin))`, expected: []string{"libb"}},
+		{query: "attr(unknown, .*, *)", expected: nil},
+		{query: "libc", err: `no module named "libc"`},
+		{query: "deps(libc)", err: `no module named "libc"`},
+		{query: "deps(bin", err: `expected ")" at the end of the query`},
+		{query: "deps(bin, -1)", err: `depth must be a non-negative number, got "-1"`},
+		{query: "kind((, *)", err: `expected a pattern, got "("`},
+		{query: "kind([, *)", err: "error parsing regexp"},
+		{query: "somepath(bin, liba)", err: `unknown function "somepath"`},
+		{query: "bin liba", err: `unexpected "liba" after the end of the query`},
+		{query: `attr(cflags, "-W`, err: "unterminated string"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			modules, err := Query(result.TestContext.Context, tc.query)
+			if tc.err != "" {
+				if err == nil {
+					t.Fatalf("expected error %q, got %v", tc.err, modules)
+				}
+				AssertStringDoesContain(t, "error", err.Error(), tc.err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, "modules", tc.expected, modules)
+		})
+	}
+}
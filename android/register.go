@@ -101,6 +101,7 @@ type mutator struct {
 	topDownMutator    blueprint.TopDownMutator
 	transitionMutator blueprint.TransitionMutator
 	parallel          bool
+	phase             string
 }
 
 var _ sortableComponent = &mutator{}
@@ -159,7 +160,7 @@ type Context struct {
 }
 
 func NewContext(config Config) *Context {
-	ctx := &Context{blueprint.NewContext(), config}
+	ctx := &Context{Context: blueprint.NewContext(), config: config}
 	ctx.SetSrcDir(absSrcDir)
 	ctx.AddIncludeTags(config.IncludeTags()...)
 	ctx.AddSourceRootDirs(config.SourceRootDirs()...)
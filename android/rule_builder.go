@@ -57,6 +57,9 @@ type RuleBuilder struct {
 	sboxInputs       bool
 	sboxManifestPath WritablePath
 	missingDeps      []string
+	actionCache      bool
+
+	nondeterministicOutputs bool
 }
 
 // NewRuleBuilder returns a newly created RuleBuilder.
@@ -113,6 +116,22 @@ func (r *RuleBuilder) HighMem() *RuleBuilder {
 	return r
 }
 
+// NondeterministicOutputs marks the outputs of the rule as differing between runs with the same
+// inputs, so that remote cache misses of the rule are attributed to it.
+func (r *RuleBuilder) NondeterministicOutputs() *RuleBuilder {
+	r.nondeterministicOutputs = true
+	return r
+}
+
+// ActionCache marks the outputs of the rule as fully determined by its command line and the
+// contents of its inputs and tools, so that it runs through the local action cache when
+// Config.ActionCacheDir is set and reuses the outputs of the same action of another build. Rules
+// that run remotely with Rewrapper don't use the action cache.
+func (r *RuleBuilder) ActionCache() *RuleBuilder {
+	r.actionCache = true
+	return r
+}
+
 // Remoteable marks the rule as supporting remote execution.
 func (r *RuleBuilder) Remoteable(supports RemoteRuleSupports) *RuleBuilder {
 	r.remoteable = supports
@@ -515,6 +534,12 @@ func (r *RuleBuilder) Build(name string, desc string) {
 
 	commandString := strings.Join(commands, " && ")
 
+	useActionCache := r.actionCache && r.rbeParams == nil && r.ctx.Config().ActionCacheDir() != ""
+	inputHash := hashSrcFiles
+	if useActionCache {
+		inputHash = func(paths Paths) string { return actionCacheInputHash(r.ctx, paths) }
+	}
+
 	if r.sbox {
 		// If running the command inside sbox, write the rule data out to an sbox
 		// manifest.textproto.
@@ -601,7 +626,7 @@ func (r *RuleBuilder) Build(name string, desc string) {
 		// Add a hash of the list of input files to the manifest so that the textproto file
 		// changes when the list of input files changes and causes the sbox rule that
 		// depends on it to rerun.
-		command.InputHash = proto.String(hashSrcFiles(inputs))
+		command.InputHash = proto.String(inputHash(inputs))
 
 		// Verify that the manifest textproto is not inside the sbox output directory, otherwise
 		// it will get deleted when the sbox rule clears its output directory.
@@ -673,7 +698,32 @@ func (r *RuleBuilder) Build(name string, desc string) {
 		// If not using sbox the rule will run the command directly, put the hash of the
 		// list of input files in a comment at the end of the command line to ensure ninja
 		// reruns the rule when the list of input files changes.
-		commandString += " # hash of input list: " + hashSrcFiles(inputs)
+		commandString += " # hash of input list: " + inputHash(inputs)
+	}
+
+	if useActionCache {
+		// The action cache hashes the contents of every file that the command reads, including
+		// the files listed in the rsp files and the sbox manifest, whose paths in the output
+		// directory are normalized.
+		cacheInputs := append(Paths{}, inputs...)
+		cacheInputs = append(cacheInputs, tools...)
+		for _, rspFile := range rspFiles {
+			cacheInputs = append(cacheInputs, rspFile.paths...)
+		}
+		var normalizedInputs Paths
+		if r.sbox {
+			normalizedInputs = Paths{r.sboxManifestPath}
+			cacheInputs, _ = FilterPathList(cacheInputs, normalizedInputs)
+		}
+		actionCacheCmd, actionCacheDeps := ActionCacheCommand(r.ctx, ActionCacheParams{
+			InputsFile:       actionCacheInputsFile(r.ctx, outputs[0]),
+			Inputs:           FirstUniquePaths(cacheInputs),
+			NormalizedInputs: normalizedInputs,
+			Outputs:          outputs,
+			DepFile:          depFile,
+		})
+		commandString = actionCacheCmd + " bash -c " + proptools.ShellEscape(commandString)
+		inputs = FirstUniquePaths(append(inputs, actionCacheDeps...))
 	}
 
 	// Ninja doesn't like multiple outputs when depfiles are enabled, move all but the first output to
@@ -734,6 +784,8 @@ func (r *RuleBuilder) Build(name string, desc string) {
 		Depfile:         depFile,
 		Deps:            depFormat,
 		Description:     desc,
+
+		NondeterministicOutputs: r.nondeterministicOutputs,
 	})
 }
 
@@ -1331,9 +1383,12 @@ func ninjaNameEscape(s string) string {
 // hashSrcFiles returns a hash of the list of source files.  It is used to ensure the command line
 // or the sbox textproto manifest change even if the input files are not listed on the command line.
 func hashSrcFiles(srcFiles Paths) string {
+	return hashStrings(srcFiles.Strings())
+}
+
+func hashStrings(strs []string) string {
 	h := sha256.New()
-	srcFileList := strings.Join(srcFiles.Strings(), "\n")
-	h.Write([]byte(srcFileList))
+	h.Write([]byte(strings.Join(strs, "\n")))
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
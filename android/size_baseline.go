@@ -0,0 +1,120 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// This file implements size baselines, which catch size regressions of apps and APEXes. A module
+// with a size baseline has a checked-in file containing the expected size of its output in bytes,
+// and building the module fails, or warns, when the output grows past it by more than the allowed
+// increase. Building with UPDATE_SIZE_BASELINES=true writes the current sizes to the baseline
+// files instead.
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+func init() {
+	pctx.SourcePathVariable("sizeBaselineCheckCmd", "build/soong/scripts/size-baseline-check.sh")
+}
+
+var sizeBaselineCheck = pctx.AndroidStaticRule("sizeBaselineCheck",
+	blueprint.RuleParams{
+		Command:     `${sizeBaselineCheckCmd} $flags $in $baseline $out`,
+		CommandDeps: []string{"${sizeBaselineCheckCmd}"},
+		Description: "size baseline check $in",
+	},
+	"flags", "baseline")
+
+type SizeBaselineProperties struct {
+	Size_baseline struct {
+		// Checked-in file containing the expected size in bytes of the output of the module.
+		// Create or update it by building the module with UPDATE_SIZE_BASELINES=true.
+		File *string
+
+		// The number of bytes by which the output may exceed the baseline. Defaults to 0.
+		Max_increase_bytes *int64
+
+		// The percentage of the baseline by which the output may exceed it. Can't be set together
+		// with max_increase_bytes.
+		Max_increase_percent *int64
+
+		// "error" to fail the build when the output exceeds the baseline, or "warning" to only
+		// report it. Defaults to "error".
+		Severity *string
+	}
+}
+
+// CheckSizeBaseline returns a file created by a rule that checks the size of the output of the
+// module against its size baseline, or nil if the module has no size baseline. The caller must
+// make building or installing the output depend on the returned file.
+func CheckSizeBaseline(ctx ModuleContext, props *SizeBaselineProperties, output Path) Path {
+	baseline := &props.Size_baseline
+	if baseline.File == nil {
+		if baseline.Max_increase_bytes != nil || baseline.Max_increase_percent != nil || baseline.Severity != nil {
+			ctx.PropertyErrorf("size_baseline.file", "must be set to use a size baseline")
+		}
+		return nil
+	}
+
+	var flags []string
+	if baseline.Max_increase_bytes != nil && baseline.Max_increase_percent != nil {
+		ctx.PropertyErrorf("size_baseline.max_increase_percent", "can't be set together with max_increase_bytes")
+	} else if baseline.Max_increase_bytes != nil {
+		if *baseline.Max_increase_bytes < 0 {
+			ctx.PropertyErrorf("size_baseline.max_increase_bytes", "must not be negative, got %d", *baseline.Max_increase_bytes)
+		}
+		flags = append(flags, "--max-increase-bytes", strconv.FormatInt(*baseline.Max_increase_bytes, 10))
+	} else if baseline.Max_increase_percent != nil {
+		if *baseline.Max_increase_percent < 0 {
+			ctx.PropertyErrorf("size_baseline.max_increase_percent", "must not be negative, got %d", *baseline.Max_increase_percent)
+		}
+		flags = append(flags, "--max-increase-percent", strconv.FormatInt(*baseline.Max_increase_percent, 10))
+	}
+
+	switch proptools.String(baseline.Severity) {
+	case "", "error":
+	case "warning":
+		flags = append(flags, "--warn")
+	default:
+		ctx.PropertyErrorf("size_baseline.severity", `must be "error" or "warning", got %q`, *baseline.Severity)
+	}
+
+	checkFile := PathForModuleOut(ctx, "size_baseline", output.Base()+".stamp")
+	params := BuildParams{
+		Rule:   sizeBaselineCheck,
+		Input:  output,
+		Output: checkFile,
+		Args:   map[string]string{},
+	}
+	if ctx.Config().IsEnvTrue("UPDATE_SIZE_BASELINES") {
+		// The baseline file is written by the rule, and may not exist yet.
+		flags = append(flags, "--update")
+		path, err := pathForSource(ctx, moduleSrcDir(ctx), *baseline.File)
+		if err != nil {
+			reportPathError(ctx, err)
+		}
+		params.Args["baseline"] = path.String()
+	} else {
+		baselineFile := PathForModuleSrc(ctx, *baseline.File)
+		params.Implicit = baselineFile
+		params.Args["baseline"] = baselineFile.String()
+	}
+	params.Args["flags"] = strings.Join(flags, " ")
+	ctx.Build(pctx, params)
+	return checkFile
+}
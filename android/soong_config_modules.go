@@ -485,6 +485,12 @@ func configModuleFactory(factory blueprint.ModuleFactory, moduleType *soongconfi
 			// conditional on Soong config variables by reading the product
 			// config variables from Make.
 			AddLoadHook(module, func(ctx LoadHookContext) {
+				if m, ok := module.(Module); ok && ctx.Config().ModuleTypeCensus() {
+					for _, v := range soongconfig.ReferencedVariables(moduleType, conditionalProps) {
+						m.base().soongConfigVariables = append(m.base().soongConfigVariables,
+							moduleType.ConfigNamespace+"."+v)
+					}
+				}
 				config := ctx.Config().VendorConfig(moduleType.ConfigNamespace)
 				newProps, err := soongconfig.PropertiesToApply(moduleType, conditionalProps, config)
 				if err != nil {
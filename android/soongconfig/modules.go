@@ -77,6 +77,18 @@ func Parse(r io.Reader, from string) (*SoongConfigDefinition, []error) {
 				}
 			}
 		}
+
+		// Each variable becomes a field of the soong_config_variables property struct.
+		seen := make(map[string]bool, len(moduleType.Variables))
+		for _, v := range moduleType.Variables {
+			field := proptools.FieldNameForProperty(v.variableProperty())
+			if seen[field] {
+				return nil, []error{
+					fmt.Errorf("duplicate variable %q in module type %q", v.variableProperty(), name),
+				}
+			}
+			seen[field] = true
+		}
 	}
 
 	return mtDef, nil
@@ -182,10 +194,13 @@ func processStringVariableDef(v *SoongConfigDefinition, def *parser.Module) (err
 	for _, name := range stringProps.Values {
 		if err := checkVariableName(name); err != nil {
 			return []error{fmt.Errorf("soong_config_string_variable: values property error %s", err)}
-		} else if _, ok := vals[name]; ok {
+		}
+		// Each value becomes a field of the variable's property struct.
+		field := proptools.FieldNameForProperty(CanonicalizeToProperty(name))
+		if vals[field] {
 			return []error{fmt.Errorf("soong_config_string_variable: values property error: duplicate value: %q", name)}
 		}
-		vals[name] = true
+		vals[field] = true
 	}
 
 	v.variables[base.variable] = &stringVariable{
@@ -225,6 +240,10 @@ func processVariableDef(def *parser.Module,
 		return baseVariable{}, []error{fmt.Errorf("name property must be set")}
 	}
 
+	if err := checkVariableName(props.Name); err != nil {
+		return baseVariable{}, []error{fmt.Errorf("name property error %s", err)}
+	}
+
 	return baseVariable{
 		variable: props.Name,
 	}, nil
@@ -538,6 +557,37 @@ func PropertiesToApply(moduleType *ModuleType, props reflect.Value, config Soong
 	return ret, nil
 }
 
+// ReferencedVariables returns the names of the variables of the ModuleType that are referenced by
+// the soong_config_variables property in props, as created by CreateProperties.
+func ReferencedVariables(moduleType *ModuleType, props reflect.Value) []string {
+	var ret []string
+	props = props.Elem().FieldByName(SoongConfigProperty)
+	for i, c := range moduleType.Variables {
+		if referenced(props.Field(i)) {
+			ret = append(ret, c.variableProperty())
+		}
+	}
+	return ret
+}
+
+// referenced returns whether any of the property structs that a variable was initialized with by
+// initializeProperties was set.
+func referenced(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		return !v.IsNil() && referenced(v.Elem())
+	case reflect.Ptr:
+		return !v.IsNil()
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if referenced(v.Field(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type ModuleType struct {
 	BaseModuleType  string
 	ConfigNamespace string
@@ -548,6 +598,10 @@ type ModuleType struct {
 }
 
 func newModuleType(props *ModuleTypeProperties) (*ModuleType, []error) {
+	if err := checkAffectableProperties(props.Properties); err != nil {
+		return nil, []error{fmt.Errorf("properties %s", err)}
+	}
+
 	mt := &ModuleType{
 		affectableProperties: props.Properties,
 		ConfigNamespace:      props.Config_namespace,
@@ -583,6 +637,39 @@ func checkVariableName(name string) error {
 		return fmt.Errorf("name must not be blank")
 	} else if name == conditionsDefault {
 		return fmt.Errorf("%q is reserved", conditionsDefault)
+	} else if c := name[0]; !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+		// The name is used as the name of a property, which must start with a letter.
+		return fmt.Errorf("%q must start with a letter", name)
+	}
+	return nil
+}
+
+// checkAffectableProperties checks that each affectable property maps to a distinct field of the
+// properties struct of the module type.
+func checkAffectableProperties(properties []string) error {
+	fieldPaths := make([]string, len(properties))
+	fields := make(map[string]string, len(properties))
+	for i, property := range properties {
+		var fieldPath []string
+		for _, name := range strings.Split(property, ".") {
+			if name == "" {
+				return fmt.Errorf("%q is not a valid property name", property)
+			}
+			fieldPath = append(fieldPath, proptools.FieldNameForProperty(name))
+		}
+		fieldPaths[i] = strings.Join(fieldPath, ".")
+		if other, ok := fields[fieldPaths[i]]; ok {
+			return fmt.Errorf("%q is a duplicate of %q", property, other)
+		}
+		fields[fieldPaths[i]] = property
+	}
+	for i, property := range properties {
+		for prefix := fieldPaths[i]; strings.Contains(prefix, "."); {
+			prefix = prefix[:strings.LastIndex(prefix, ".")]
+			if other, ok := fields[prefix]; ok {
+				return fmt.Errorf("%q conflicts with %q", property, other)
+			}
+		}
 	}
 	return nil
 }
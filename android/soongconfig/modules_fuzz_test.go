@@ -0,0 +1,63 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package soongconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+type fuzzProperties struct {
+	Cflags  []string
+	Enabled *bool
+	Srcs    []string
+	Nested  struct {
+		Value *string
+	}
+}
+
+// FuzzParse checks that parsing soong_config_module_type definitions and creating the properties
+// of the module types never panics. The seed corpus in testdata/fuzz/FuzzParse is run as part of
+// the normal tests, run the fuzzer with:
+//
+//	go test ./android/soongconfig -run '^$' -fuzz FuzzParse
+func FuzzParse(f *testing.F) {
+	f.Add(`
+		soong_config_module_type {
+			name: "acme_cc_defaults",
+			module_type: "cc_defaults",
+			config_namespace: "acme",
+			variables: ["board"],
+			bool_variables: ["feature"],
+			value_variables: ["size"],
+			properties: ["cflags", "srcs", "nested.value"],
+		}
+
+		soong_config_string_variable {
+			name: "board",
+			values: ["soc_a", "soc_b"],
+		}
+	`)
+
+	f.Fuzz(func(t *testing.T, bp string) {
+		def, errs := Parse(strings.NewReader(bp), "Android.bp")
+		if len(errs) > 0 {
+			return
+		}
+		for _, moduleType := range def.ModuleTypes {
+			CreateProperties([]interface{}{&fuzzProperties{}}, moduleType)
+		}
+	})
+}
@@ -16,6 +16,7 @@ package soongconfig
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/blueprint/proptools"
@@ -414,6 +415,101 @@ func Test_PropertiesToApply_String_Error(t *testing.T) {
 	}
 }
 
+func Test_ParseErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		bp   string
+		want string
+	}{
+		{
+			name: "value canonicalized to a duplicate",
+			bp: `
+				soong_config_string_variable {
+					name: "board",
+					values: ["soc-a", "soc_a"],
+				}`,
+			want: `soong_config_string_variable: values property error: duplicate value: "soc_a"`,
+		},
+		{
+			name: "value not starting with a letter",
+			bp: `
+				soong_config_string_variable {
+					name: "board",
+					values: ["5.10"],
+				}`,
+			want: `soong_config_string_variable: values property error "5.10" must start with a letter`,
+		},
+		{
+			name: "variable name not starting with a letter",
+			bp: `
+				soong_config_bool_variable {
+					name: "_feature",
+				}`,
+			want: `name property error "_feature" must start with a letter`,
+		},
+		{
+			name: "duplicate variables",
+			bp: `
+				soong_config_module_type {
+					name: "acme_cc_defaults",
+					module_type: "cc_defaults",
+					config_namespace: "acme",
+					bool_variables: ["feature", "Feature"],
+					properties: ["cflags"],
+				}`,
+			want: `duplicate variable "Feature" in module type "acme_cc_defaults"`,
+		},
+		{
+			name: "duplicate properties",
+			bp: `
+				soong_config_module_type {
+					name: "acme_cc_defaults",
+					module_type: "cc_defaults",
+					config_namespace: "acme",
+					bool_variables: ["feature"],
+					properties: ["cflags", "Cflags"],
+				}`,
+			want: `properties "Cflags" is a duplicate of "cflags"`,
+		},
+		{
+			name: "conflicting properties",
+			bp: `
+				soong_config_module_type {
+					name: "acme_cc_defaults",
+					module_type: "cc_defaults",
+					config_namespace: "acme",
+					bool_variables: ["feature"],
+					properties: ["nested.value", "nested"],
+				}`,
+			want: `properties "nested.value" conflicts with "nested"`,
+		},
+		{
+			name: "empty property",
+			bp: `
+				soong_config_module_type {
+					name: "acme_cc_defaults",
+					module_type: "cc_defaults",
+					config_namespace: "acme",
+					bool_variables: ["feature"],
+					properties: ["nested..value"],
+				}`,
+			want: `properties "nested..value" is not a valid property name`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errs := Parse(strings.NewReader(tc.bp), "Android.bp")
+			if len(errs) != 1 {
+				t.Fatalf("expected one error, got %q", errs)
+			}
+			if errs[0].Error() != tc.want {
+				t.Errorf("expected error %q, got %q", tc.want, errs[0].Error())
+			}
+		})
+	}
+}
+
 func Test_Bp2BuildSoongConfigDefinitionsAddVars(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -0,0 +1,127 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SOURCE_DATE_EPOCH pins the timestamps that tools embed in their outputs to a time in seconds since
+// the Unix epoch, see https://reproducible-builds.org/specs/source-date-epoch/. soong_ui also uses
+// it as the BUILD_DATETIME when BUILD_DATETIME is not set. Rules whose tools embed the time of the
+// build pass it to them with the ${android.SourceDateEpochEnv} ninja variable, or with
+// SourceDateEpochEnv in RuleBuilder commands. Those are apexer and the filesystem images.
+//
+// soong_zip, merge_zips and zip2zip don't get it, because they give the zip entries they write a
+// fixed time, jar.DefaultTime. Neither does signapk, which derives the times of the entries it
+// writes from the signing certificate. Their outputs don't depend on the time of the build.
+//
+// `m check_embedded_timestamps` checks the files installed by Soong for timestamps of the time of
+// the build that are left.
+
+const sourceDateEpochEnvVar = "SOURCE_DATE_EPOCH"
+
+func init() {
+	pctx.VariableFunc("SourceDateEpochEnv", func(ctx PackageVarContext) string {
+		return SourceDateEpochEnv(ctx.Config())
+	})
+
+	RegisterSourceDateEpochBuildComponents(InitRegistrationContext)
+}
+
+func RegisterSourceDateEpochBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("check_embedded_timestamps", embeddedTimestampsSingletonFactory)
+}
+
+var PrepareForTestWithSourceDateEpoch = FixtureRegisterWithContext(RegisterSourceDateEpochBuildComponents)
+
+// parseSourceDateEpoch parses the value of SOURCE_DATE_EPOCH, which must be a non-negative integer.
+func parseSourceDateEpoch(value string) (int64, error) {
+	epoch, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || epoch < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative number of seconds since the Unix epoch, got %q",
+			sourceDateEpochEnvVar, value)
+	}
+	return epoch, nil
+}
+
+// validateSourceDateEpoch returns an error if SOURCE_DATE_EPOCH is set to an invalid value.
+func (c *config) validateSourceDateEpoch() error {
+	if value := c.Getenv(sourceDateEpochEnvVar); value != "" {
+		_, err := parseSourceDateEpoch(value)
+		return err
+	}
+	return nil
+}
+
+// SourceDateEpoch returns the value of SOURCE_DATE_EPOCH, or false if it is not set.
+func (c *config) SourceDateEpoch() (int64, bool) {
+	value := c.Getenv(sourceDateEpochEnvVar)
+	if value == "" {
+		return 0, false
+	}
+	epoch, err := parseSourceDateEpoch(value)
+	if err != nil {
+		// Checked when the config is created.
+		panic(err)
+	}
+	return epoch, true
+}
+
+// SourceDateEpochEnv returns the environment variable assignment that passes SOURCE_DATE_EPOCH to a
+// command, e.g. "SOURCE_DATE_EPOCH=1700000000", or an empty string if it is not set.
+func SourceDateEpochEnv(config Config) string {
+	if epoch, ok := config.SourceDateEpoch(); ok {
+		return sourceDateEpochEnvVar + "=" + strconv.FormatInt(epoch, 10)
+	}
+	return ""
+}
+
+func embeddedTimestampsSingletonFactory() Singleton {
+	return &embeddedTimestampsSingleton{}
+}
+
+// embeddedTimestampsSingleton creates the check_embedded_timestamps phony target, which checks the
+// files installed on the device by Soong for timestamps of the time of the build.
+type embeddedTimestampsSingleton struct{}
+
+func (s *embeddedTimestampsSingleton) GenerateBuildActions(ctx SingletonContext) {
+	var files Paths
+	ctx.VisitAllModules(func(module Module) {
+		if !module.Enabled() {
+			return
+		}
+		for _, file := range module.FilesToInstall() {
+			if _, ok := file.devicePath(ctx.Config()); ok {
+				files = append(files, file)
+			}
+		}
+	})
+	files = SortedUniquePaths(files)
+
+	report := PathForOutput(ctx, "embedded_timestamps.txt")
+	rule := NewRuleBuilder(pctx, ctx)
+	cmd := rule.Command().
+		BuiltTool("find_embedded_timestamps").
+		FlagWithOutput("-o ", report)
+	if epoch, ok := ctx.Config().SourceDateEpoch(); ok {
+		cmd.FlagWithArg("-epoch ", fmt.Sprint(epoch))
+	}
+	cmd.FlagWithRspFileInputList("@", PathForOutput(ctx, "embedded_timestamps.rsp"), files)
+	rule.Build("check_embedded_timestamps", fmt.Sprintf("check %d installed files for embedded timestamps", len(files)))
+
+	ctx.Phony("check_embedded_timestamps", report)
+}
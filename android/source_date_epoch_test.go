@@ -0,0 +1,84 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+)
+
+func TestParseSourceDateEpoch(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  int64
+		err   bool
+	}{
+		{value: "0", want: 0},
+		{value: "1700000000", want: 1700000000},
+		{value: "-1", err: true},
+		{value: "yesterday", err: true},
+		{value: "1700000000.5", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseSourceDateEpoch(tc.value)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected an error, got %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertIntEquals(t, "epoch", int(tc.want), int(got))
+		})
+	}
+}
+
+var prepareForSourceDateEpochTest = GroupFixturePreparers(
+	PrepareForTestWithArchMutator,
+	PrepareForTestWithSourceDateEpoch,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("test_install", installConflictsTestModuleFactory)
+	}),
+	FixtureWithRootAndroidBp(`
+		test_install {
+			name: "foo",
+		}
+	`),
+)
+
+func TestCheckEmbeddedTimestamps(t *testing.T) {
+	result := GroupFixturePreparers(
+		prepareForSourceDateEpochTest,
+		FixtureMergeEnv(map[string]string{"SOURCE_DATE_EPOCH": "1700000000"}),
+	).RunTest(t)
+
+	AssertStringEquals(t, "SourceDateEpochEnv", "SOURCE_DATE_EPOCH=1700000000", SourceDateEpochEnv(result.Config))
+
+	rule := result.SingletonForTests("check_embedded_timestamps").Rule("check_embedded_timestamps")
+	AssertStringDoesContain(t, "command", rule.RuleParams.Command, "-epoch 1700000000")
+	AssertStringListContains(t, "inputs", PathsRelativeToTop(rule.Inputs),
+		"out/soong/target/product/test_device/system/bin/foo")
+}
+
+func TestCheckEmbeddedTimestampsWithoutEpoch(t *testing.T) {
+	result := prepareForSourceDateEpochTest.RunTest(t)
+
+	AssertStringEquals(t, "SourceDateEpochEnv", "", SourceDateEpochEnv(result.Config))
+
+	rule := result.SingletonForTests("check_embedded_timestamps").Rule("check_embedded_timestamps")
+	AssertStringDoesNotContain(t, "command", rule.RuleParams.Command, "-epoch")
+}
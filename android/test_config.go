@@ -16,6 +16,7 @@ package android
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -25,6 +26,32 @@ import (
 
 // TestConfig returns a Config object for testing.
 func TestConfig(buildDir string, env map[string]string, bp string, fs map[string][]byte) Config {
+	config := newTestConfig(buildDir, env, productVariables{
+		DeviceName:                          stringPtr("test_device"),
+		DeviceProduct:                       stringPtr("test_product"),
+		Platform_sdk_version:                intPtr(30),
+		Platform_sdk_codename:               stringPtr("S"),
+		Platform_base_sdk_extension_version: intPtr(1),
+		Platform_version_active_codenames:   []string{"S", "Tiramisu"},
+		DeviceSystemSdkVersions:             []string{"14", "15"},
+		Platform_systemsdk_versions:         []string{"29", "30"},
+		AAPTConfig:                          []string{"normal", "large", "xlarge", "hdpi", "xhdpi", "xxhdpi"},
+		AAPTPreferredConfig:                 stringPtr("xhdpi"),
+		AAPTCharacteristics:                 stringPtr("nosdcard"),
+		AAPTPrebuiltDPI:                     []string{"xhdpi", "xxhdpi"},
+		UncompressPrivAppDex:                boolPtr(true),
+		ShippingApiLevel:                    stringPtr("30"),
+	})
+
+	config.mockFileSystem(bp, fs)
+
+	determineBuildOS(config)
+
+	return Config{config}
+}
+
+// newTestConfig returns a config for testing with the given environment and product variables.
+func newTestConfig(buildDir string, env map[string]string, variables productVariables) *config {
 	envCopy := make(map[string]string)
 	for k, v := range env {
 		envCopy[k] = v
@@ -35,27 +62,13 @@ func TestConfig(buildDir string, env map[string]string, bp string, fs map[string
 	envCopy["PATH"] = os.Getenv("PATH")
 
 	config := &config{
-		productVariables: productVariables{
-			DeviceName:                          stringPtr("test_device"),
-			DeviceProduct:                       stringPtr("test_product"),
-			Platform_sdk_version:                intPtr(30),
-			Platform_sdk_codename:               stringPtr("S"),
-			Platform_base_sdk_extension_version: intPtr(1),
-			Platform_version_active_codenames:   []string{"S", "Tiramisu"},
-			DeviceSystemSdkVersions:             []string{"14", "15"},
-			Platform_systemsdk_versions:         []string{"29", "30"},
-			AAPTConfig:                          []string{"normal", "large", "xlarge", "hdpi", "xhdpi", "xxhdpi"},
-			AAPTPreferredConfig:                 stringPtr("xhdpi"),
-			AAPTCharacteristics:                 stringPtr("nosdcard"),
-			AAPTPrebuiltDPI:                     []string{"xhdpi", "xxhdpi"},
-			UncompressPrivAppDex:                boolPtr(true),
-			ShippingApiLevel:                    stringPtr("30"),
-		},
+		productVariables: variables,
 
-		outDir:       buildDir,
-		soongOutDir:  filepath.Join(buildDir, "soong"),
-		captureBuild: true,
-		env:          envCopy,
+		outDir:                  buildDir,
+		soongOutDir:             filepath.Join(buildDir, "soong"),
+		captureBuild:            true,
+		recordDependencyOrigins: true,
+		env:                     envCopy,
 
 		// Set testAllowNonExistentPaths so that test contexts don't need to specify every path
 		// passed to PathForSource or PathForModuleSrc.
@@ -72,11 +85,7 @@ func TestConfig(buildDir string, env map[string]string, bp string, fs map[string
 	}
 	config.TestProductVariables = &config.productVariables
 
-	config.mockFileSystem(bp, fs)
-
-	determineBuildOS(config)
-
-	return Config{config}
+	return config
 }
 
 func modifyTestConfigToSupportArchMutator(testConfig Config) {
@@ -129,6 +138,14 @@ func modifyTestConfigForMuslArm64HostCross(config Config) {
 		Target{config.BuildOS, Arch{ArchType: Arm64}, NativeBridgeDisabled, "", "", true})
 }
 
+// ModifyTestConfigForWasi adds a WebAssembly target to the test config, like setting WasiArch
+// in the product variables does.
+func ModifyTestConfigForWasi(config Config) {
+	config.Targets[Wasi] = []Target{
+		{Wasi, Arch{ArchType: Wasm32}, NativeBridgeDisabled, "", "", false},
+	}
+}
+
 // TestArchConfig returns a Config object suitable for using for tests that
 // need to run the arch mutator.
 func TestArchConfig(buildDir string, env map[string]string, bp string, fs map[string][]byte) Config {
@@ -137,6 +154,106 @@ func TestArchConfig(buildDir string, env map[string]string, bp string, fs map[st
 	return testConfig
 }
 
+// testConfigSnapshot is the serialized form of a test Config, see SnapshotTestConfig.
+type testConfigSnapshot struct {
+	ProductVariables productVariables
+	Env              map[string]string
+	Targets          []testConfigSnapshotTarget
+}
+
+// testConfigSnapshotTarget is the serialized form of a Target, with the OsType stored by name.
+type testConfigSnapshotTarget struct {
+	Os                       string
+	Arch                     Arch
+	NativeBridge             NativeBridgeSupport
+	NativeBridgeHostArchName string
+	NativeBridgeRelativePath string
+	HostCross                bool
+}
+
+// SnapshotTestConfig serializes the product variables, targets and environment of a test Config,
+// e.g. one that has been set up by the preparers shared by all the tests of a package. A Config can
+// be built from the snapshot with TestConfigFromSnapshot or FixtureRestoreConfigSnapshot,
+// including in other test packages, without repeating that setup.
+func SnapshotTestConfig(config Config) ([]byte, error) {
+	snapshot := testConfigSnapshot{
+		ProductVariables: config.productVariables,
+		Env:              config.env,
+	}
+	for _, os := range osTypeList {
+		for _, target := range config.Targets[os] {
+			snapshot.Targets = append(snapshot.Targets, testConfigSnapshotTarget{
+				Os:                       os.Name,
+				Arch:                     target.Arch,
+				NativeBridge:             target.NativeBridge,
+				NativeBridgeHostArchName: target.NativeBridgeHostArchName,
+				NativeBridgeRelativePath: target.NativeBridgeRelativePath,
+				HostCross:                target.HostCross,
+			})
+		}
+	}
+	return json.Marshal(snapshot)
+}
+
+// TestConfigFromSnapshot returns a Config object for testing with the product variables, targets
+// and environment of a snapshot created by SnapshotTestConfig, instead of the defaults of
+// TestConfig.
+func TestConfigFromSnapshot(buildDir string, data []byte, bp string, fs map[string][]byte) (Config, error) {
+	var snapshot testConfigSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Config{}, fmt.Errorf("invalid test config snapshot: %w", err)
+	}
+
+	// Keep the real PATH value, see newTestConfig.
+	delete(snapshot.Env, "PATH")
+	config := newTestConfig(buildDir, snapshot.Env, snapshot.ProductVariables)
+
+	config.Targets = make(map[OsType][]Target)
+	for _, target := range snapshot.Targets {
+		os := osByName(target.Os)
+		if os == NoOsType {
+			return Config{}, fmt.Errorf("invalid test config snapshot: unknown os %q", target.Os)
+		}
+		config.Targets[os] = append(config.Targets[os], Target{
+			Os:                       os,
+			Arch:                     target.Arch,
+			NativeBridge:             target.NativeBridge,
+			NativeBridgeHostArchName: target.NativeBridgeHostArchName,
+			NativeBridgeRelativePath: target.NativeBridgeRelativePath,
+			HostCross:                target.HostCross,
+		})
+	}
+
+	config.mockFileSystem(bp, fs)
+
+	determineBuildOS(config)
+
+	if targets := config.Targets[config.BuildOS]; len(targets) > 0 {
+		config.BuildOSTarget = targets[0]
+		config.BuildOSCommonTarget = getCommonTargets(targets)[0]
+	}
+	if targets := config.Targets[Android]; len(targets) > 0 {
+		config.AndroidCommonTarget = getCommonTargets(targets)[0]
+		config.AndroidFirstDeviceTarget = FirstTarget(targets, "lib64", "lib32")[0]
+	}
+
+	return Config{config}, nil
+}
+
+// FixtureRestoreConfigSnapshot returns a preparer that replaces the config of the fixture with one
+// built by TestConfigFromSnapshot. It replaces the changes made to the config by the preparers
+// before it, so it should come first.
+func FixtureRestoreConfigSnapshot(snapshot []byte) FixturePreparer {
+	return newSimpleFixturePreparer(func(f *fixture) {
+		config, err := TestConfigFromSnapshot(f.config.outDir, snapshot, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		f.config = config
+		f.ctx.config = config
+	})
+}
+
 // CreateTestConfiguredJarList is a function to create ConfiguredJarList for tests.
 func CreateTestConfiguredJarList(list []string) ConfiguredJarList {
 	// Create the ConfiguredJarList in as similar way as it is created at runtime by marshalling to
@@ -0,0 +1,198 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// This file implements test_suite_package, which packages a compatibility test suite such as cts
+// into android-<suite>.zip. The zip has the layout expected by the suite tooling:
+//
+//   android-<suite>/testcases/<module>/<module>.config
+//   android-<suite>/testcases/<module>/<arch>/...
+//   android-<suite>/tools/...
+//
+// The tests are the modules that list the suite in test_suites, along with the modules listed in
+// tests. Every test module is zipped on its own and the zips are merged into the suite zip, so a
+// change to one test only repacks that test.
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/google/blueprint"
+)
+
+func init() {
+	RegisterTestSuitePackageBuildComponents(InitRegistrationContext)
+}
+
+func RegisterTestSuitePackageBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterModuleType("test_suite_package", TestSuitePackageFactory)
+}
+
+var PrepareForTestWithTestSuitePackage = GroupFixturePreparers(
+	FixtureRegisterWithContext(RegisterTestSuitePackageBuildComponents),
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterSingletonType("testsuites", testSuiteFilesFactory)
+	}),
+)
+
+type testSuitePackageProperties struct {
+	// Name of the top level directory of the zip. Defaults to android-<name>.
+	Suite_dir *string
+
+	// Test modules to include in the suite in addition to the modules that list it in
+	// test_suites.
+	Tests []string
+
+	// Host modules whose outputs are installed in the tools directory of the suite, for example
+	// the tradefed harness of the suite.
+	Tools []string
+}
+
+// testSuitePackage is the test_suite_package module. The name of the module is the name of the
+// suite; the testsuites singleton builds the zip, as it is the only place that sees all the
+// modules of the suite.
+type testSuitePackage struct {
+	ModuleBase
+
+	properties testSuitePackageProperties
+
+	tools Paths
+}
+
+type testSuitePackageToolDependencyTag struct {
+	blueprint.BaseDependencyTag
+}
+
+var testSuitePackageToolTag = testSuitePackageToolDependencyTag{}
+
+// TestSuitePackageFactory returns a test_suite_package module, which packages the compatibility
+// test suite named after the module into android-<name>.zip.
+func TestSuitePackageFactory() Module {
+	module := &testSuitePackage{}
+	module.AddProperties(&module.properties)
+	InitAndroidModule(module)
+	return module
+}
+
+func (p *testSuitePackage) suiteDir() string {
+	return StringDefault(p.properties.Suite_dir, "android-"+p.Name())
+}
+
+func (p *testSuitePackage) DepsMutator(ctx BottomUpMutatorContext) {
+	ctx.AddFarVariationDependencies(ctx.Config().BuildOSCommonTarget.Variations(),
+		testSuitePackageToolTag, p.properties.Tools...)
+}
+
+func (p *testSuitePackage) GenerateAndroidBuildActions(ctx ModuleContext) {
+	if dir := p.properties.Suite_dir; dir != nil && (*dir == "" || strings.Contains(*dir, "/")) {
+		ctx.PropertyErrorf("suite_dir", "must be a single directory name, got %q", *dir)
+	}
+
+	p.tools = nil
+	ctx.VisitDirectDepsWithTag(testSuitePackageToolTag, func(dep Module) {
+		p.tools = append(p.tools, OutputFilesForModule(ctx, dep, "")...)
+	})
+}
+
+// testSuiteVariantFiles are the installed files of one variant of a test module.
+type testSuiteVariantFiles struct {
+	arch  ArchType
+	files InstallPaths
+}
+
+// testSuitePackageZip builds the zip of the suite packaged by p from the installed files of its
+// test modules, keyed by module name.
+func testSuitePackageZip(ctx SingletonContext, p *testSuitePackage,
+	tests map[string][]testSuiteVariantFiles) WritablePath {
+
+	name := p.Name()
+	suiteDir := p.suiteDir()
+	packagingDir := PathForOutput(ctx, "packaging", name)
+
+	var zips Paths
+	if len(p.tools) > 0 {
+		toolsZip := packagingDir.Join(ctx, "tools.zip")
+		rule := NewRuleBuilder(pctx, ctx)
+		rule.Command().BuiltTool("soong_zip").
+			FlagWithOutput("-o ", toolsZip).
+			FlagWithArg("-P ", suiteDir+"/tools").
+			Flag("-j").
+			FlagForEachInput("-f ", p.tools)
+		rule.Build("test_suite_package_tools_"+name, name+" tools")
+		zips = append(zips, toolsZip)
+	}
+
+	for _, module := range SortedKeys(tests) {
+		moduleZip := packagingDir.Join(ctx, "testcases", module+".zip")
+		rule := NewRuleBuilder(pctx, ctx)
+		cmd := rule.Command().BuiltTool("soong_zip").FlagWithOutput("-o ", moduleZip)
+		testSuitePackageModuleArgs(cmd, suiteDir+"/testcases/"+module, tests[module])
+		rule.Build("test_suite_package_"+name+"_"+module, name+" "+module)
+		zips = append(zips, moduleZip)
+	}
+
+	outputFile := PathForOutput(ctx, "packaging", suiteDir+".zip")
+	rule := NewRuleBuilder(pctx, ctx)
+	rule.Command().BuiltTool("merge_zips").
+		Output(outputFile).
+		Inputs(zips)
+	rule.Build("test_suite_package_"+name, suiteDir+".zip")
+
+	return outputFile
+}
+
+// testSuitePackageModuleArgs adds the soong_zip arguments that place the files of the variants of
+// a test module under prefix. Test configs go directly under prefix, and only once, as the suite
+// tooling finds a test by its config. The other files of a variant go in a directory named after
+// its architecture, unless the variant is arch independent, keeping their paths relative to the
+// install directory of the variant.
+func testSuitePackageModuleArgs(cmd *RuleBuilderCommand, prefix string, variants []testSuiteVariantFiles) {
+	configs := make(map[string]bool)
+	for _, variant := range variants {
+		var files Paths
+		for _, file := range variant.files {
+			if file.Ext() != ".config" {
+				files = append(files, file)
+			} else if !configs[file.Base()] {
+				configs[file.Base()] = true
+				cmd.FlagWithArg("-P ", prefix).
+					FlagWithArg("-C ", filepath.Dir(file.String())).
+					FlagWithInput("-f ", file)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		dir := prefix
+		if variant.arch != Common {
+			dir += "/" + variant.arch.String()
+		}
+		cmd.FlagWithArg("-P ", dir).
+			FlagWithArg("-C ", commonDir(files.Strings())).
+			FlagForEachInput("-f ", files)
+	}
+}
+
+// commonDir returns the deepest directory that contains all the paths.
+func commonDir(paths []string) string {
+	dir := filepath.Dir(paths[0])
+	for _, path := range paths[1:] {
+		for dir != "." && dir != "/" && !strings.HasPrefix(path, dir+"/") {
+			dir = filepath.Dir(dir)
+		}
+	}
+	return dir
+}
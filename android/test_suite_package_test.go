@@ -0,0 +1,181 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"testing"
+)
+
+type testSuiteTestModule struct {
+	ModuleBase
+	properties struct {
+		Test_suites []string
+	}
+}
+
+func (m *testSuiteTestModule) TestSuites() []string {
+	return m.properties.Test_suites
+}
+
+func (m *testSuiteTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	dir := "nativetest"
+	if ctx.Arch().ArchType.Multilib == "lib64" {
+		dir = "nativetest64"
+	}
+	installDir := PathForModuleInstall(ctx, dir, ctx.ModuleName())
+	ctx.InstallFile(installDir, ctx.ModuleName(), PathForSource(ctx, "test"))
+	ctx.InstallFile(installDir.Join(ctx, "data"), "input.txt", PathForSource(ctx, "input.txt"))
+	ctx.InstallFile(installDir, ctx.ModuleName()+".config", PathForSource(ctx, "AndroidTest.xml"))
+}
+
+func testSuiteTestModuleFactory() Module {
+	m := &testSuiteTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidArchModule(m, DeviceSupported, MultilibBoth)
+	return m
+}
+
+type testSuiteToolModule struct {
+	ModuleBase
+	output WritablePath
+}
+
+func (m *testSuiteToolModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	m.output = PathForModuleOut(ctx, ctx.ModuleName()+".jar")
+	ctx.Build(pctx, BuildParams{
+		Rule:   Touch,
+		Output: m.output,
+	})
+}
+
+func (m *testSuiteToolModule) OutputFiles(tag string) (Paths, error) {
+	return Paths{m.output}, nil
+}
+
+func testSuiteToolModuleFactory() Module {
+	m := &testSuiteToolModule{}
+	InitAndroidArchModule(m, HostSupported, MultilibCommon)
+	return m
+}
+
+var prepareForTestSuitePackageTest = GroupFixturePreparers(
+	PrepareForTestWithArchMutator,
+	PrepareForTestWithTestSuitePackage,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("test_suite_test", testSuiteTestModuleFactory)
+		ctx.RegisterModuleType("test_suite_tool", testSuiteToolModuleFactory)
+	}),
+	FixtureMergeMockFs(MockFS{
+		"test":            nil,
+		"input.txt":       nil,
+		"AndroidTest.xml": nil,
+	}),
+)
+
+func TestTestSuitePackage(t *testing.T) {
+	result := prepareForTestSuitePackageTest.RunTestWithBp(t, `
+		test_suite_package {
+			name: "cts",
+			tests: ["extra"],
+			tools: ["cts-tradefed"],
+		}
+
+		test_suite_test {
+			name: "foo",
+			test_suites: ["cts", "general-tests"],
+		}
+
+		test_suite_test {
+			name: "extra",
+		}
+
+		test_suite_test {
+			name: "other",
+			test_suites: ["vts"],
+		}
+
+		test_suite_tool {
+			name: "cts-tradefed",
+		}
+	`)
+
+	singleton := result.SingletonForTests("testsuites")
+
+	foo := singleton.Output("packaging/cts/testcases/foo.zip")
+	AssertStringDoesContain(t, "config", foo.RuleParams.Command,
+		"-P android-cts/testcases/foo -C out/soong/target/product/test_device/system/nativetest64/foo"+
+			" -f out/soong/target/product/test_device/system/nativetest64/foo/foo.config")
+	AssertStringDoesContain(t, "arm64", foo.RuleParams.Command,
+		"-P android-cts/testcases/foo/arm64 -C out/soong/target/product/test_device/system/nativetest64/foo"+
+			" -f out/soong/target/product/test_device/system/nativetest64/foo/foo"+
+			" -f out/soong/target/product/test_device/system/nativetest64/foo/data/input.txt")
+	AssertStringDoesContain(t, "arm", foo.RuleParams.Command,
+		"-P android-cts/testcases/foo/arm -C out/soong/target/product/test_device/system/nativetest/foo"+
+			" -f out/soong/target/product/test_device/system/nativetest/foo/foo"+
+			" -f out/soong/target/product/test_device/system/nativetest/foo/data/input.txt")
+	AssertStringDoesNotContain(t, "second config", foo.RuleParams.Command,
+		"nativetest/foo/foo.config")
+
+	tools := singleton.Output("packaging/cts/tools.zip")
+	AssertStringDoesContain(t, "tools", tools.RuleParams.Command,
+		"-P android-cts/tools -j -f out/soong/.intermediates/cts-tradefed/linux_glibc_common/cts-tradefed.jar")
+
+	zip := singleton.Output("packaging/android-cts.zip")
+	AssertPathsRelativeToTopEquals(t, "zips", []string{
+		"out/soong/packaging/cts/tools.zip",
+		"out/soong/packaging/cts/testcases/extra.zip",
+		"out/soong/packaging/cts/testcases/foo.zip",
+	}, zip.Implicits)
+
+	if singleton.MaybeOutput("packaging/cts/testcases/other.zip").Rule != nil {
+		t.Errorf("expected other to not be packaged in cts")
+	}
+}
+
+func TestTestSuitePackageErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		bp    string
+		error string
+	}{
+		{
+			name: "suite_dir",
+			bp: `
+				test_suite_package {
+					name: "vts",
+					suite_dir: "a/b",
+				}
+			`,
+			error: `suite_dir: must be a single directory name, got "a/b"`,
+		},
+		{
+			name: "tests",
+			bp: `
+				test_suite_package {
+					name: "vts",
+					tests: ["missing"],
+				}
+			`,
+			error: `tests: "missing" is not a test module with installed files`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prepareForTestSuitePackageTest.
+				ExtendWithErrorHandler(FixtureExpectsAtLeastOneErrorMatchingPattern(tc.error)).
+				RunTestWithBp(t, tc.bp)
+		})
+	}
+}
@@ -24,6 +24,12 @@ func testSuiteFilesFactory() Singleton {
 
 type testSuiteFiles struct {
 	robolectric WritablePath
+	packages    []testSuitePackageOutput
+}
+
+type testSuitePackageOutput struct {
+	goal string
+	zip  WritablePath
 }
 
 type TestSuiteModule interface {
@@ -33,26 +39,64 @@ type TestSuiteModule interface {
 
 func (t *testSuiteFiles) GenerateBuildActions(ctx SingletonContext) {
 	files := make(map[string]map[string]InstallPaths)
+	variants := make(map[string][]testSuiteVariantFiles)
+	var packages []*testSuitePackage
 
 	ctx.VisitAllModules(func(m Module) {
-		if tsm, ok := m.(TestSuiteModule); ok {
-			for _, testSuite := range tsm.TestSuites() {
-				if files[testSuite] == nil {
-					files[testSuite] = make(map[string]InstallPaths)
-				}
-				name := ctx.ModuleName(m)
-				files[testSuite][name] = append(files[testSuite][name], tsm.FilesToInstall()...)
+		if p, ok := m.(*testSuitePackage); ok {
+			packages = append(packages, p)
+		}
+		tsm, ok := m.(TestSuiteModule)
+		if !ok {
+			return
+		}
+		name := ctx.ModuleName(m)
+		for _, testSuite := range tsm.TestSuites() {
+			if files[testSuite] == nil {
+				files[testSuite] = make(map[string]InstallPaths)
 			}
+			files[testSuite][name] = append(files[testSuite][name], tsm.FilesToInstall()...)
+		}
+		if m.Enabled() && len(tsm.FilesToInstall()) > 0 {
+			variants[name] = append(variants[name], testSuiteVariantFiles{
+				arch:  m.Target().Arch.ArchType,
+				files: tsm.FilesToInstall(),
+			})
 		}
 	})
 
 	t.robolectric = robolectricTestSuite(ctx, files["robolectric-tests"])
 
 	ctx.Phony("robolectric-tests", t.robolectric)
+
+	t.packages = nil
+	for _, p := range packages {
+		suite := p.Name()
+		tests := make(map[string][]testSuiteVariantFiles)
+		for name := range files[suite] {
+			if len(variants[name]) > 0 {
+				tests[name] = variants[name]
+			}
+		}
+		for _, name := range p.properties.Tests {
+			if _, ok := variants[name]; !ok {
+				ctx.ModuleErrorf(p, "tests: %q is not a test module with installed files", name)
+				continue
+			}
+			tests[name] = variants[name]
+		}
+
+		zip := testSuitePackageZip(ctx, p, tests)
+		ctx.Phony(suite, zip)
+		t.packages = append(t.packages, testSuitePackageOutput{goal: suite, zip: zip})
+	}
 }
 
 func (t *testSuiteFiles) MakeVars(ctx MakeVarsContext) {
 	ctx.DistForGoal("robolectric-tests", t.robolectric)
+	for _, p := range t.packages {
+		ctx.DistForGoal(p.goal, p.zip)
+	}
 }
 
 func robolectricTestSuite(ctx SingletonContext, files map[string]InstallPaths) WritablePath {
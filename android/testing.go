@@ -32,7 +32,7 @@ import (
 
 func newTestContextForFixture(config Config) *TestContext {
 	ctx := &TestContext{
-		Context: &Context{blueprint.NewContext(), config},
+		Context: &Context{Context: blueprint.NewContext(), config: config},
 	}
 
 	ctx.postDeps = append(ctx.postDeps, registerPathDepsMutator)
@@ -141,6 +141,7 @@ var PrepareForTestWithMakevars = FixtureRegisterWithContext(func(ctx Registratio
 var PrepareForTestWithAndroidBuildComponents = GroupFixturePreparers(
 	// Sorted alphabetically as the actual order does not matter as tests automatically enforce the
 	// correct order.
+	PrepareForTestWithApiSurfaceConsistency,
 	PrepareForTestWithArchMutator,
 	PrepareForTestWithComponentsMutator,
 	PrepareForTestWithDefaults,
@@ -0,0 +1,70 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// ToolchainVersions is the matrix of the versions of a toolchain, like the JDK or clang, that
+// modules may be built with. Modules select a version with a property, products select the
+// default version of their modules with a product variable, and the build has a default for the
+// products that don't. Several versions can be used in the same build, so that modules can move
+// to a new toolchain one at a time instead of in lockstep.
+type ToolchainVersions struct {
+	// The name of the toolchain in error messages, e.g. "JDK".
+	Name string
+
+	// The property of the modules that selects the version, e.g. "jdk_version".
+	Property string
+
+	// The product variable that selects the default version of the product, e.g.
+	// "DefaultJdkVersion".
+	ProductVariable string
+
+	// The versions that modules may be built with.
+	Allowed []string
+
+	// The version of the modules when neither the module nor the product select one.
+	Default string
+}
+
+type toolchainVersionsOnceKey struct {
+	productVariable string
+}
+
+// Select returns the version of the toolchain that the module is built with: requested, the
+// value of the property of the module, if it is set, or else productDefault, the value of the
+// product variable, if it is set, or else the default version. It reports an error and returns
+// the default version if the selected version is not allowed.
+func (v ToolchainVersions) Select(ctx BaseModuleContext, requested *string, productDefault string) string {
+	if requested != nil {
+		if !InList(*requested, v.Allowed) {
+			ctx.PropertyErrorf(v.Property, "%s version %q is not one of the allowed versions %q",
+				v.Name, *requested, v.Allowed)
+			return v.Default
+		}
+		return *requested
+	}
+	if productDefault != "" {
+		if !InList(productDefault, v.Allowed) {
+			// The product variable is the same for every module, report it once.
+			ctx.Config().Once(NewCustomOnceKey(toolchainVersionsOnceKey{v.ProductVariable}), func() interface{} {
+				ctx.ModuleErrorf("product variable %s: %s version %q is not one of the allowed versions %q",
+					v.ProductVariable, v.Name, productDefault, v.Allowed)
+				return true
+			})
+			return v.Default
+		}
+		return productDefault
+	}
+	return v.Default
+}
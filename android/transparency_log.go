@@ -0,0 +1,173 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// When the product sets Transparency_log, the build writes a binary transparency log entry for
+// every signed APK and APEX that it installs: the hash of the artifact and the certificates, signing
+// certificate lineage and APEX payload key it is signed with. The entries are written to
+// $OUT/soong/transparency_log.json, with the schema described in the README, which is built with
+// `m transparency_log` and is in the "transparency_log" dist group.
+
+func init() {
+	RegisterTransparencyLogBuildComponents(InitRegistrationContext)
+}
+
+func RegisterTransparencyLogBuildComponents(ctx RegistrationContext) {
+	ctx.RegisterSingletonType("transparency_log", transparencyLogSingletonFactory)
+}
+
+var PrepareForTestWithTransparencyLog = FixtureRegisterWithContext(RegisterTransparencyLogBuildComponents)
+
+// SignedArtifact is a signed artifact that a module installs.
+type SignedArtifact struct {
+	// The kind of the artifact, "apk" or "apex".
+	Kind string
+
+	// The signed artifact.
+	File Path
+
+	// The path of the installed artifact on the device.
+	OnDevicePath string
+
+	// The x509 certificates in PEM format that the artifact is signed with, empty if the artifact
+	// is presigned.
+	Certificates Paths
+
+	// The signing certificate lineage, or nil if the signing certificate was not rotated.
+	Lineage Path
+
+	// The public key of the APEX payload, or nil if the artifact is not an APEX.
+	PayloadPublicKey Path
+
+	// Whether the artifact was signed outside of the build.
+	Presigned bool
+}
+
+// RecordSignedArtifact records a signed artifact that the module installs for the transparency
+// log. It does nothing when the product doesn't set Transparency_log.
+func RecordSignedArtifact(ctx ModuleContext, artifact SignedArtifact) {
+	if !ctx.Config().TransparencyLog() {
+		return
+	}
+	m := ctx.Module().base()
+	m.signedArtifacts = append(m.signedArtifacts, artifact)
+}
+
+// TransparencyLogManifestEntry is a signed artifact in the manifest passed to transparency_log,
+// which hashes the files.
+type TransparencyLogManifestEntry struct {
+	Kind             string   `json:"kind"`
+	Module           string   `json:"module"`
+	ModuleDir        string   `json:"module_dir"`
+	Path             string   `json:"path"`
+	File             string   `json:"file"`
+	Certificates     []string `json:"certificates"`
+	Lineage          string   `json:"lineage,omitempty"`
+	PayloadPublicKey string   `json:"payload_public_key,omitempty"`
+	Presigned        bool     `json:"presigned,omitempty"`
+}
+
+// TransparencyLogManifest is the manifest of the signed artifacts passed to transparency_log.
+type TransparencyLogManifest struct {
+	Product string                         `json:"product"`
+	BuildId string                         `json:"build_id"`
+	Entries []TransparencyLogManifestEntry `json:"entries"`
+}
+
+// TransparencyLogPath returns the path of the transparency log.
+func TransparencyLogPath(ctx PathContext) OutputPath {
+	return PathForOutput(ctx, "transparency_log.json")
+}
+
+func transparencyLogSingletonFactory() Singleton {
+	return &transparencyLogSingleton{}
+}
+
+type transparencyLogSingleton struct {
+	log Path
+}
+
+func (s *transparencyLogSingleton) GenerateBuildActions(ctx SingletonContext) {
+	if !ctx.Config().TransparencyLog() {
+		return
+	}
+
+	manifest := TransparencyLogManifest{
+		Product: ctx.Config().DeviceProduct(),
+		BuildId: ctx.Config().BuildId(),
+		Entries: []TransparencyLogManifestEntry{},
+	}
+	var inputs Paths
+	ctx.VisitAllModules(func(module Module) {
+		if !module.Enabled() {
+			return
+		}
+		for _, artifact := range module.base().signedArtifacts {
+			entry := TransparencyLogManifestEntry{
+				Kind:         artifact.Kind,
+				Module:       ctx.ModuleName(module),
+				ModuleDir:    ctx.ModuleDir(module),
+				Path:         artifact.OnDevicePath,
+				File:         artifact.File.String(),
+				Certificates: artifact.Certificates.Strings(),
+				Presigned:    artifact.Presigned,
+			}
+			inputs = append(inputs, artifact.File)
+			inputs = append(inputs, artifact.Certificates...)
+			if artifact.Lineage != nil {
+				entry.Lineage = artifact.Lineage.String()
+				inputs = append(inputs, artifact.Lineage)
+			}
+			if artifact.PayloadPublicKey != nil {
+				entry.PayloadPublicKey = artifact.PayloadPublicKey.String()
+				inputs = append(inputs, artifact.PayloadPublicKey)
+			}
+			manifest.Entries = append(manifest.Entries, entry)
+		}
+	})
+	sort.SliceStable(manifest.Entries, func(i, j int) bool {
+		return manifest.Entries[i].Path < manifest.Entries[j].Path
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	manifestFile := PathForOutput(ctx, "transparency_log", "manifest.json")
+	WriteFileRule(ctx, manifestFile, string(data))
+
+	s.log = TransparencyLogPath(ctx)
+	rule := NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		BuiltTool("transparency_log").
+		FlagWithInput("--manifest ", manifestFile).
+		FlagWithOutput("--output ", s.log).
+		Implicits(FirstUniquePaths(inputs))
+	rule.Build("transparency_log", "transparency log")
+
+	ctx.Phony("transparency_log", s.log)
+}
+
+func (s *transparencyLogSingleton) MakeVars(ctx MakeVarsContext) {
+	if s.log != nil {
+		ctx.DistForGroup("transparency_log", s.log)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+)
+
+type signedArtifactTestModule struct {
+	ModuleBase
+	properties struct {
+		Src         *string `android:"path"`
+		Certificate *string `android:"path"`
+	}
+}
+
+func signedArtifactTestModuleFactory() Module {
+	m := &signedArtifactTestModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidArchModule(m, DeviceSupported, MultilibCommon)
+	return m
+}
+
+func (m *signedArtifactTestModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	signed := PathForModuleOut(ctx, ctx.ModuleName()+".apk")
+	ctx.Build(pctx, BuildParams{
+		Rule:   Cp,
+		Input:  PathForModuleSrc(ctx, String(m.properties.Src)),
+		Output: signed,
+	})
+	installDir := PathForModuleInstall(ctx, "app", ctx.ModuleName())
+	ctx.InstallFile(installDir, signed.Base(), signed)
+	RecordSignedArtifact(ctx, SignedArtifact{
+		Kind:         "apk",
+		File:         signed,
+		OnDevicePath: InstallPathToOnDevicePath(ctx, installDir.Join(ctx, signed.Base())),
+		Certificates: Paths{PathForModuleSrc(ctx, String(m.properties.Certificate))},
+	})
+}
+
+var prepareForTransparencyLogTest = GroupFixturePreparers(
+	PrepareForTestWithArchMutator,
+	PrepareForTestWithTransparencyLog,
+	FixtureRegisterWithContext(func(ctx RegistrationContext) {
+		ctx.RegisterModuleType("signed_artifact", signedArtifactTestModuleFactory)
+	}),
+	FixtureAddTextFile("foo/Android.bp", `
+		signed_artifact {
+			name: "Foo",
+			src: "Foo.unsigned.apk",
+			certificate: "foo.x509.pem",
+		}
+	`),
+	FixtureAddFile("foo/Foo.unsigned.apk", nil),
+	FixtureAddFile("foo/foo.x509.pem", nil),
+)
+
+func TestTransparencyLog(t *testing.T) {
+	result := GroupFixturePreparers(
+		prepareForTransparencyLogTest,
+		FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+			variables.Transparency_log = proptools.BoolPtr(true)
+		}),
+	).RunTest(t)
+
+	singleton := result.SingletonForTests("transparency_log")
+	var manifest TransparencyLogManifest
+	content := ContentFromFileRuleForTests(t, singleton.Output("transparency_log/manifest.json"))
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Entries) != 1 {
+		t.Fatalf("expected one signed artifact, got %+v", manifest.Entries)
+	}
+	entry := manifest.Entries[0]
+	AssertStringEquals(t, "kind", "apk", entry.Kind)
+	AssertStringEquals(t, "module", "Foo", entry.Module)
+	AssertStringEquals(t, "path", "/system/app/Foo/Foo.apk", entry.Path)
+	AssertDeepEquals(t, "certificates", []string{"foo/foo.x509.pem"}, entry.Certificates)
+
+	log := singleton.Rule("transparency_log")
+	AssertStringDoesContain(t, "command", log.RuleParams.Command, "transparency_log --manifest")
+	implicits := log.Implicits.RelativeToTop().Strings()
+	AssertStringListContains(t, "implicits", implicits, "out/soong/.intermediates/foo/Foo/android_common/Foo.apk")
+	AssertStringListContains(t, "implicits", implicits, "foo/foo.x509.pem")
+}
+
+func TestTransparencyLogDisabled(t *testing.T) {
+	result := prepareForTransparencyLogTest.RunTest(t)
+	if result.SingletonForTests("transparency_log").MaybeRule("transparency_log").Rule != nil {
+		t.Errorf("expected no transparency log without Transparency_log")
+	}
+}
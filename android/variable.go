@@ -255,6 +255,11 @@ type productVariables struct {
 	CrossHostArch          *string `json:",omitempty"`
 	CrossHostSecondaryArch *string `json:",omitempty"`
 
+	WasiArch *string `json:",omitempty"`
+
+	LinuxEmbeddedArch *string `json:",omitempty"`
+	QnxArch           *string `json:",omitempty"`
+
 	DeviceResourceOverlays     []string `json:",omitempty"`
 	ProductResourceOverlays    []string `json:",omitempty"`
 	EnforceRROTargets          []string `json:",omitempty"`
@@ -270,11 +275,20 @@ type productVariables struct {
 
 	AppsDefaultVersionName *string `json:",omitempty"`
 
+	DefaultJdkVersion   *string `json:",omitempty"`
+	DefaultClangVersion *string `json:",omitempty"`
+
 	Allow_missing_dependencies   *bool    `json:",omitempty"`
 	Unbundled_build              *bool    `json:",omitempty"`
 	Unbundled_build_apps         []string `json:",omitempty"`
 	Unbundled_build_image        *bool    `json:",omitempty"`
 	Always_use_prebuilt_sdks     *bool    `json:",omitempty"`
+	Strict_java_deps             *bool    `json:",omitempty"`
+	Transparency_log             *bool    `json:",omitempty"`
+	Module_type_census           *bool    `json:",omitempty"`
+	Max_command_line_length      *int     `json:",omitempty"`
+	Max_rspfile_length           *int     `json:",omitempty"`
+	Windows_max_path_length      *int     `json:",omitempty"`
 	Skip_boot_jars_check         *bool    `json:",omitempty"`
 	Malloc_not_svelte            *bool    `json:",omitempty"`
 	Malloc_not_svelte_libc32     *bool    `json:",omitempty"`
@@ -318,6 +332,19 @@ type productVariables struct {
 
 	HWASanIncludePaths []string `json:",omitempty"`
 
+	GlobalThinLto *bool          `json:",omitempty"`
+	LtoExemptions []LtoExemption `json:",omitempty"`
+
+	CcFlagPolicySeverity  *string                 `json:",omitempty"`
+	CcFlagPolicyAllowlist []CcFlagPolicyException `json:",omitempty"`
+
+	HardeningConfigs []HardeningConfig `json:",omitempty"`
+
+	LicensePolicyFile *string `json:",omitempty"`
+
+	SplitDebugInfo  *bool `json:",omitempty"`
+	BreakpadSymbols *bool `json:",omitempty"`
+
 	VendorPath    *string `json:",omitempty"`
 	OdmPath       *string `json:",omitempty"`
 	ProductPath   *string `json:",omitempty"`
@@ -399,6 +426,15 @@ type productVariables struct {
 
 	VendorVars map[string]map[string]string `json:",omitempty"`
 
+	// The files that declare the build flags, and the values of the build flags set by the release
+	// configuration, see build_flags.go.
+	BuildFlagDeclarations []string          `json:",omitempty"`
+	BuildFlagValues       map[string]string `json:",omitempty"`
+
+	// The aconfig_value_set modules that set the values of the aconfig flags of the release
+	// configuration.
+	AconfigValueSets []string `json:",omitempty"`
+
 	Ndk_abis *bool `json:",omitempty"`
 
 	TrimmedApex                  *bool `json:",omitempty"`
@@ -438,6 +474,8 @@ type productVariables struct {
 	EnforceInterPartitionJavaSdkLibrary *bool    `json:",omitempty"`
 	InterPartitionJavaLibraryAllowList  []string `json:",omitempty"`
 
+	EnforceGeneratedHeaderIncludeDirs *bool `json:",omitempty"`
+
 	InstallExtraFlattenedApexes *bool `json:",omitempty"`
 
 	BoardUsesRecoveryAsBoot *bool `json:",omitempty"`
@@ -460,6 +498,8 @@ type productVariables struct {
 	BuildBrokenUsesSoongPython2Modules bool     `json:",omitempty"`
 	BuildBrokenVendorPropertyNamespace bool     `json:",omitempty"`
 	BuildBrokenInputDirModules         []string `json:",omitempty"`
+	BuildBrokenSourceVisibility        bool     `json:",omitempty"`
+	BuildBrokenMinSdkVersionDirs       []string `json:",omitempty"`
 
 	BuildDebugfsRestrictionsEnabled bool `json:",omitempty"`
 
@@ -484,6 +524,121 @@ type productVariables struct {
 	ProductManufacturer string   `json:",omitempty"`
 	ProductBrand        string   `json:",omitempty"`
 	BuildVersionTags    []string `json:",omitempty"`
+
+	AllowedDuplicateInstalls []string `json:",omitempty"`
+
+	InstallOverrides map[string]InstallOverride `json:",omitempty"`
+
+	AppResourceOverlays map[string]AppResourceOverlay `json:",omitempty"`
+
+	ShellcheckEnabled     *bool    `json:",omitempty"`
+	ShellcheckExcludeDirs []string `json:",omitempty"`
+
+	ConfigFileOverlays map[string][]string `json:",omitempty"`
+
+	ProvenanceAttestation *ProvenanceAttestation `json:",omitempty"`
+
+	CompilerCache *string `json:",omitempty"`
+
+	ActionCacheDir *string `json:",omitempty"`
+}
+
+// InstallOverride changes how a module is installed for a product, keyed by module name in the
+// InstallOverrides product variable. The module must set `relocatable: true`.
+type InstallOverride struct {
+	// Directory to install the module's files to, relative to the root of its partition.
+	Dir *string `json:",omitempty"`
+
+	// Whether to install the module's files as executables.
+	Executable *bool `json:",omitempty"`
+
+	// Names of symlinks to create next to the installed file. Only supported for modules that
+	// install a single file.
+	Symlinks []string `json:",omitempty"`
+}
+
+// AppResourceOverlay overlays the resources of an android_app for a product, keyed by app module
+// name in the AppResourceOverlays product variable.
+type AppResourceOverlay struct {
+	// Resource directories, relative to the root of the source tree, that are overlaid onto the
+	// resources of the app when it is built. Directories earlier in the list take precedence. Every
+	// resource in the directories must override a resource of the app or of its static libraries.
+	ResourceDirs []string `json:",omitempty"`
+
+	// Names of runtime_resource_overlay modules to install with the app.
+	RuntimeResourceOverlays []string `json:",omitempty"`
+}
+
+// ProvenanceAttestation configures the signed SLSA provenance attestation of the prebuilt
+// artifacts that are installed by the build, in the ProvenanceAttestation product variable.
+type ProvenanceAttestation struct {
+	// PEM private key, RSA or EC, that signs the attestation. No attestation is generated without
+	// a key.
+	SigningKey *string `json:",omitempty"`
+
+	// Identifier of the signing key recorded in the signature, e.g. the URI of its public key.
+	KeyId *string `json:",omitempty"`
+
+	// Identifier of the build platform recorded as the builder of the artifacts.
+	BuilderId *string `json:",omitempty"`
+
+	// Snapshot of the source manifest of the checkout, e.g. from `repo manifest -r`, relative to
+	// the root of the source tree.
+	Manifest *string `json:",omitempty"`
+
+	// Environment variables to record in addition to the default ones.
+	Env []string `json:",omitempty"`
+}
+
+// LtoExemption exempts the cc modules in a directory, or a single cc module, from ThinLTO by
+// default, listed in the LtoExemptions product variable. Exactly one of Path and Module must be set.
+type LtoExemption struct {
+	// Directory, relative to the root of the source tree, of the modules that are exempted.
+	Path *string `json:",omitempty"`
+
+	// Name of the module that is exempted.
+	Module *string `json:",omitempty"`
+
+	// Why the modules are exempted, e.g. a bug number.
+	Reason *string `json:",omitempty"`
+}
+
+// CcFlagPolicyException allows the cc modules in a directory, or a single cc module, to use
+// restricted compiler and linker flags, listed in the CcFlagPolicyAllowlist product variable.
+// Exactly one of Path and Module must be set.
+type CcFlagPolicyException struct {
+	// Directory, relative to the root of the source tree, of the modules that are allowed.
+	Path *string `json:",omitempty"`
+
+	// Name of the module that is allowed.
+	Module *string `json:",omitempty"`
+
+	// The restricted flags that are allowed, as listed in restrictedCompilerFlags and
+	// restrictedLinkerFlags in cc/flag_policy.go, e.g. "-fno-stack-protector" or "-std=*". All the
+	// restricted flags are allowed if empty.
+	Flags []string `json:",omitempty"`
+
+	// Why the modules are allowed, e.g. a bug number.
+	Reason *string `json:",omitempty"`
+}
+
+// HardeningConfig enables memory tagging and branch protection for the native modules of a
+// partition, or of a directory, listed in the HardeningConfigs product variable. Exactly one of
+// Partition and Path must be set. Modules override the config with their own properties.
+type HardeningConfig struct {
+	// Partition of the modules, e.g. "system" or "vendor".
+	Partition *string `json:",omitempty"`
+
+	// Directory of the modules, relative to the root of the source tree. The config of a
+	// directory takes precedence over the config of a partition or of a parent directory.
+	Path *string `json:",omitempty"`
+
+	// Heap memory tagging mode of the binaries: "sync", "async" or "off".
+	MemtagHeap *string `json:",omitempty"`
+
+	// Branch protection of the code: "standard" for both pointer authentication and BTI,
+	// "pac-ret" for pointer authentication only, "bti" for BTI only, or "none".
+	BranchProtection *string `json:",omitempty"`
 }
 
 func boolPtr(v bool) *bool {
@@ -984,6 +1139,7 @@ func VariableMutator(mctx BottomUpMutatorContext) {
 			continue
 		}
 		a.setVariableProperties(mctx, property, variableValue, val.Interface())
+		a.appliedProductVariables = append(a.appliedProductVariables, property)
 	}
 }
 
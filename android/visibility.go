@@ -16,12 +16,14 @@ package android
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
 
 	"github.com/google/blueprint"
+	"github.com/google/blueprint/pathtools"
 )
 
 // Enforces visibility rules between modules.
@@ -44,7 +46,9 @@ import (
 //   the same package then it is automatically visible. Otherwise, for each dep it first extracts
 //   its visibilityRule from the config map. If one could not be found then it assumes that it is
 //   publicly visible. Otherwise, it calls the visibility rule to check that the module can see
-//   the dependency. If it cannot then an error is reported.
+//   the dependency. If it cannot then an error is reported. Modules that reference sources by path,
+//   see SourceVisibilityReferencer, have the directories they reference checked against the
+//   default_visibility of the package containing them.
 //
 // TODO(b/130631145) - Make visibility work properly with prebuilts.
 
@@ -205,6 +209,21 @@ type ExcludeFromVisibilityEnforcementTag interface {
 	ExcludeFromVisibilityEnforcement()
 }
 
+// SourceVisibilityReferencer is implemented by modules that reference sources by path rather than
+// through a module dependency, e.g. the srcs of a filegroup or the include_dirs of a cc module.
+//
+// Files have no visibility of their own, so the referenced directories are checked against the
+// default_visibility of the package that contains them.
+type SourceVisibilityReferencer interface {
+	// SourceVisibilityDirs returns the directories, relative to the root of the source tree, that
+	// contain the sources referenced by path.
+	SourceVisibilityDirs(ctx BaseModuleContext) []string
+}
+
+func init() {
+	RegisterSingletonType("source_visibility_violations", sourceVisibilityViolationsSingletonFactory)
+}
+
 // The visibility mutators.
 var PrepareForTestWithVisibility = FixtureRegisterWithContext(registerVisibilityMutators)
 
@@ -212,6 +231,7 @@ func registerVisibilityMutators(ctx RegistrationContext) {
 	ctx.PreArchMutators(RegisterVisibilityRuleChecker)
 	ctx.PreArchMutators(RegisterVisibilityRuleGatherer)
 	ctx.PostDepsMutators(RegisterVisibilityRuleEnforcer)
+	ctx.RegisterSingletonType("source_visibility_violations", sourceVisibilityViolationsSingletonFactory)
 }
 
 // The rule checker needs to be registered before defaults expansion to correctly check that
@@ -462,6 +482,82 @@ func visibilityRuleEnforcer(ctx TopDownMutatorContext) {
 			ctx.ModuleErrorf("depends on %s which is not visible to this module\nYou may need to add %q to its visibility", depQualified, "//"+ctx.ModuleDir())
 		}
 	})
+
+	// Check that the sources referenced by path are visible to this module.
+	if s, ok := ctx.Module().(SourceVisibilityReferencer); ok {
+		for _, dir := range s.SourceVisibilityDirs(ctx) {
+			checkSourceVisibility(ctx, qualified, dir)
+		}
+	}
+}
+
+// checkSourceVisibility checks that the sources in dir are visible to the module, based on the
+// default_visibility of the package containing dir.
+func checkSourceVisibility(ctx TopDownMutatorContext, qualified qualifiedModuleName, dir string) {
+	dir = filepath.Clean(dir)
+	for pathtools.IsGlob(dir) {
+		dir = filepath.Dir(dir)
+	}
+
+	// Sources are always visible to modules in the package containing them, and in its ancestors.
+	if isAncestor(qualified.pkg, dir) {
+		return
+	}
+
+	rule := packageDefaultVisibility(ctx.Config(), qualifiedModuleName{pkg: dir, name: "sources"})
+	if rule == nil || rule.matches(qualified) {
+		return
+	}
+
+	if ctx.DeviceConfig().BuildBrokenSourceVisibility() {
+		// In migration mode report the violation instead of failing the build.
+		violations := sourceVisibilityViolations(ctx.Config())
+		violations.Lock()
+		defer violations.Unlock()
+		violations.entries = append(violations.entries,
+			fmt.Sprintf("%s references %s which is not visible to this module", qualified, "//"+dir))
+		return
+	}
+
+	ctx.ModuleErrorf("references sources in //%s which are not visible to this module\n"+
+		"You may need to add %q to the default_visibility of the package containing them, "+
+		"or depend on a filegroup that is visible to this module", dir, "//"+ctx.ModuleDir())
+}
+
+var sourceVisibilityViolationsKey = NewOnceKey("sourceVisibilityViolations")
+
+// sourceVisibilityViolationsList holds the source visibility violations that were reported
+// instead of failing the build because BUILD_BROKEN_SOURCE_VISIBILITY is set.
+type sourceVisibilityViolationsList struct {
+	sync.Mutex
+	entries []string
+}
+
+func sourceVisibilityViolations(config Config) *sourceVisibilityViolationsList {
+	return config.Once(sourceVisibilityViolationsKey, func() interface{} {
+		return &sourceVisibilityViolationsList{}
+	}).(*sourceVisibilityViolationsList)
+}
+
+func sourceVisibilityViolationsSingletonFactory() Singleton {
+	return &sourceVisibilityViolationsSingleton{}
+}
+
+// sourceVisibilityViolationsSingleton writes the source visibility violations reported in
+// migration mode to $OUT/soong/source_visibility_violations.txt.
+type sourceVisibilityViolationsSingleton struct{}
+
+func (s *sourceVisibilityViolationsSingleton) GenerateBuildActions(ctx SingletonContext) {
+	if !ctx.DeviceConfig().BuildBrokenSourceVisibility() {
+		return
+	}
+
+	violations := sourceVisibilityViolations(ctx.Config())
+	entries := SortedUniqueStrings(violations.entries)
+
+	output := PathForOutput(ctx, "source_visibility_violations.txt")
+	WriteFileRule(ctx, output, strings.Join(entries, "\n"))
+	ctx.Phony("source_visibility_violations", output)
 }
 
 // Default visibility is public.
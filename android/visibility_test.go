@@ -2073,3 +2073,59 @@ func TestVisibilityRuleSet(t *testing.T) {
 		}
 	})
 }
+
+func TestSourceVisibility(t *testing.T) {
+	bp := map[string][]byte{
+		"top/Android.bp": []byte(`
+			package {
+				default_visibility: ["//visibility:private"],
+			}`),
+		"top/shared/Android.bp": []byte(`
+			package {
+				default_visibility: ["//friends"],
+			}`),
+		"friends/Android.bp": []byte(`
+			filegroup {
+				name: "friends-srcs",
+				srcs: ["../top/shared/a.c"],
+			}`),
+		"other/Android.bp": []byte(`
+			filegroup {
+				name: "other-srcs",
+				srcs: [
+					"../top/private/*.c",
+					":friends-srcs",
+				],
+			}`),
+	}
+
+	preparers := GroupFixturePreparers(
+		PrepareForTestWithArchMutator,
+		PrepareForTestWithFilegroup,
+		PrepareForTestWithPackageModule,
+		PrepareForTestWithVisibility,
+		MockFS(bp).AddToFixture(),
+		FixtureAddTextFile("top/shared/a.c", ""),
+		FixtureAddTextFile("top/private/b.c", ""),
+	)
+
+	t.Run("enforced", func(t *testing.T) {
+		preparers.ExtendWithErrorHandler(FixtureExpectsAllErrorsToMatchAPattern([]string{
+			`module "other-srcs": references sources in //top/private which are not visible to this module`,
+		})).RunTest(t)
+	})
+
+	t.Run("migration mode", func(t *testing.T) {
+		result := GroupFixturePreparers(
+			preparers,
+			FixtureModifyProductVariables(func(variables FixtureProductVariables) {
+				variables.BuildBrokenSourceVisibility = true
+			}),
+		).RunTest(t)
+
+		violations := result.SingletonForTests("source_visibility_violations").Output("source_visibility_violations.txt")
+		AssertStringEquals(t, "source visibility violations",
+			"//other:other-srcs references //top/private which is not visible to this module",
+			ContentFromFileRuleForTests(t, violations))
+	})
+}
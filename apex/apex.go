@@ -103,6 +103,17 @@ type apexBundleProperties struct {
 	// to avoid mistakes. When set as true, no force-labelling.
 	Use_file_contexts_as_is *bool
 
+	// When set to true, file_contexts is generated from the files in this APEX bundle instead of
+	// being read from file_contexts. Each file is labeled according to file_contexts_labels, or
+	// as system_file if no entry matches. Cannot be used together with file_contexts.
+	Generate_file_contexts *bool
+
+	// List of "<path>=<label>" entries used when generate_file_contexts is true. <path> is a file
+	// or a directory relative to the root of this APEX bundle and <label> is the SELinux context
+	// for it, e.g. "bin/foo=u:object_r:foo_exec:s0". A directory entry applies to all files under
+	// it; the longest matching entry wins. Every entry must match at least one file.
+	File_contexts_labels []string
+
 	// Path to the canned fs config file for customizing file's
 	// uid/gid/mod/capabilities. The content of this file is appended to the
 	// default config, so that the custom entries are preferred. The format is
@@ -201,6 +212,10 @@ type apexBundleProperties struct {
 	// in a special way that include the digest of the lib file under /lib(64)?
 	Dynamic_common_lib_apex *bool
 
+	// Budget for the estimated boot-time initialization cost of this APEX bundle. The estimate
+	// is checked at build time to give early feedback before regressions show up on devices.
+	Init_cost_budget apexInitCostBudgetProperties
+
 	// Canonical name of this APEX bundle. Used to determine the path to the
 	// activated APEX on device (i.e. /apex/<apexVariationName>), and used for the
 	// apex mutator variations. For override_apex modules, this is the name of the
@@ -294,6 +309,30 @@ type apexMultilibProperties struct {
 	Lib64 ApexNativeDependencies
 }
 
+type apexInitCostBudgetProperties struct {
+	// Maximum total size in bytes of the files in the payload.
+	Max_payload_size *int64
+
+	// Maximum number of native shared libraries in the payload, including JNI libraries.
+	Max_native_libs *int64
+
+	// Maximum number of JNI libraries in the payload.
+	Max_jni_libs *int64
+
+	// Maximum number of java libraries in the payload, which are candidates for the
+	// bootclasspath or the systemserverclasspath.
+	Max_classpath_entries *int64
+
+	// When set to true, exceeding the budget prints a warning instead of failing the build.
+	// Default is false.
+	Warn_only *bool
+}
+
+func (p *apexInitCostBudgetProperties) isSet() bool {
+	return p.Max_payload_size != nil || p.Max_native_libs != nil || p.Max_jni_libs != nil ||
+		p.Max_classpath_entries != nil
+}
+
 type apexTargetBundleProperties struct {
 	Target struct {
 		// Multilib properties only for android.
@@ -395,11 +434,12 @@ type apexBundle struct {
 	multitree.ExportableModuleBase
 
 	// Properties
-	properties            apexBundleProperties
-	targetProperties      apexTargetBundleProperties
-	archProperties        apexArchBundleProperties
-	overridableProperties overridableProperties
-	vndkProperties        apexVndkProperties // only for apex_vndk modules
+	properties             apexBundleProperties
+	targetProperties       apexTargetBundleProperties
+	archProperties         apexArchBundleProperties
+	overridableProperties  overridableProperties
+	vndkProperties         apexVndkProperties // only for apex_vndk modules
+	sizeBaselineProperties android.SizeBaselineProperties
 
 	///////////////////////////////////////////////////////////////////////////////////////////
 	// Inputs
@@ -2732,6 +2772,7 @@ func newApexBundle() *apexBundle {
 	module.AddProperties(&module.targetProperties)
 	module.AddProperties(&module.archProperties)
 	module.AddProperties(&module.overridableProperties)
+	module.AddProperties(&module.sizeBaselineProperties)
 
 	android.InitAndroidMultiTargetsArchModule(module, android.HostAndDeviceSupported, android.MultilibCommon)
 	android.InitDefaultableModule(module)
@@ -2775,6 +2816,7 @@ func DefaultsFactory() android.Module {
 		&apexTargetBundleProperties{},
 		&apexArchBundleProperties{},
 		&overridableProperties{},
+		&android.SizeBaselineProperties{},
 	)
 
 	android.InitDefaultsModule(module)
@@ -2938,6 +2980,18 @@ func (a *apexBundle) minSdkVersionValue(ctx android.EarlyModuleContext) string {
 	return minApiLevel.String()
 }
 
+// Implements android.MinSdkVersionExplainer
+func (a *apexBundle) ExplainMinSdkVersion(ctx android.EarlyModuleContext) (string, string) {
+	value := proptools.String(a.properties.Min_sdk_version)
+	if value == "" {
+		return "", ""
+	}
+	if effective := a.minSdkVersionValue(ctx); effective != minSdkVersionFromValue(ctx, value).String() {
+		return effective, "APEX_GLOBAL_MIN_SDK_VERSION_OVERRIDE"
+	}
+	return value, "min_sdk_version"
+}
+
 // Returns apex's min_sdk_version SdkSpec, honoring overrides
 func (a *apexBundle) MinSdkVersion(ctx android.EarlyModuleContext) android.ApiLevel {
 	return a.minSdkVersion(ctx)
@@ -2534,6 +2534,84 @@ func TestApexMinSdkVersion_ErrorIfDepIsNewer_Java(t *testing.T) {
 	`)
 }
 
+func TestApexMinSdkVersion_ErrorExplainsDependencyPath(t *testing.T) {
+	bp := `
+		apex {
+			name: "myapex",
+			key: "myapex.key",
+			native_shared_libs: ["mylib"],
+			min_sdk_version: "29",
+		}
+
+		apex_key {
+			name: "myapex.key",
+			public_key: "testkey.avbpubkey",
+			private_key: "testkey.pem",
+		}
+
+		cc_library {
+			name: "mylib",
+			srcs: ["mylib.cpp"],
+			shared_libs: ["mylib2"],
+			system_shared_libs: [],
+			stl: "none",
+			apex_available: ["myapex"],
+			min_sdk_version: "29",
+		}
+
+		cc_library {
+			name: "mylib2",
+			srcs: ["mylib.cpp"],
+			system_shared_libs: [],
+			stl: "none",
+			apex_available: ["myapex"],
+			min_sdk_version: "30",
+		}
+	`
+
+	testApexError(t, `Dependency path: myapex\{[^}]*\} \(min_sdk_version 29 set by min_sdk_version\)`+
+		`\n.*\n    -> mylib\{[^}]*\} \(min_sdk_version 29 set by min_sdk_version\)`+
+		`\n.*\n    -> mylib2\{[^}]*\} \(min_sdk_version 30 set by min_sdk_version\)`, bp)
+
+	testApexError(t, `Dependency path: myapex\{[^}]*\} \(min_sdk_version 30 set by APEX_GLOBAL_MIN_SDK_VERSION_OVERRIDE\)`, `
+		apex {
+			name: "myapex",
+			key: "myapex.key",
+			native_shared_libs: ["mylib"],
+			min_sdk_version: "29",
+		}
+
+		apex_key {
+			name: "myapex.key",
+			public_key: "testkey.avbpubkey",
+			private_key: "testkey.pem",
+		}
+
+		cc_library {
+			name: "mylib",
+			srcs: ["mylib.cpp"],
+			system_shared_libs: [],
+			stl: "none",
+			apex_available: ["myapex"],
+			min_sdk_version: "31",
+		}
+	`, withApexGlobalMinSdkVersionOverride(proptools.StringPtr("30")))
+
+	// In migration mode the violations are written to a report instead of failing the build.
+	result := android.GroupFixturePreparers(
+		prepareForApexTest,
+		android.PrepareForTestWithMinSdkVersionCheck,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.BuildBrokenMinSdkVersionDirs = []string{"."}
+		}),
+	).RunTestWithBp(t, bp)
+
+	report := result.SingletonForTests("min_sdk_version_violations").Output("min_sdk_version_violations.txt")
+	content := android.ContentFromFileRuleForTests(t, report)
+	android.AssertStringDoesContain(t, "report", content, `: should support min_sdk_version(29) for "myapex"`)
+	android.AssertStringDoesContain(t, "report", content, "(min_sdk_version 30 set by min_sdk_version)")
+}
+
 func TestApexMinSdkVersion_OkayEvenWhenDepIsNewer_IfItSatisfiesApexMinSdkVersion(t *testing.T) {
 	ctx := testApex(t, `
 		apex {
@@ -4929,6 +5007,89 @@ func TestFileContexts_SetViaFileGroup(t *testing.T) {
 	ensureContains(t, rule.RuleParams.Command, "cat product_specific_file_contexts")
 }
 
+func TestFileContexts_Generated(t *testing.T) {
+	bp := `
+		apex {
+			name: "myapex",
+			key: "myapex.key",
+			binaries: ["mybin"],
+			generate_file_contexts: true,
+			file_contexts_labels: ["bin/mybin=u:object_r:mybin_exec:s0"],
+			updatable: false,
+		}
+
+		apex_key {
+			name: "myapex.key",
+			public_key: "testkey.avbpubkey",
+			private_key: "testkey.pem",
+		}
+
+		cc_binary {
+			name: "mybin",
+			srcs: ["mylib.cpp"],
+			system_shared_libs: [],
+			stl: "none",
+			apex_available: [ "myapex" ],
+		}
+	`
+	ctx := testApex(t, bp)
+	module := ctx.ModuleForTests("myapex", "android_common_myapex_image")
+	generated := android.ContentFromFileRuleForTests(t, module.Output("file_contexts.generated"))
+	ensureContains(t, generated, "(/.*)? u:object_r:system_file:s0\n")
+	ensureContains(t, generated, "/bin/mybin u:object_r:mybin_exec:s0\n")
+
+	rule := module.Output("file_contexts")
+	ensureContains(t, rule.RuleParams.Command, "cat "+module.Output("file_contexts.generated").Output.String())
+	ensureListContains(t, rule.Validations.Strings(), module.Output("file_contexts_sepolicy_tests.timestamp").Output.String())
+
+	testApexError(t, `file_contexts_labels: "bin/other" does not match any file`,
+		strings.Replace(bp, `["bin/mybin=`, `["bin/other=`, 1))
+	testApexError(t, `file_contexts_labels: invalid entry "bin/mybin"`,
+		strings.Replace(bp, `["bin/mybin=u:object_r:mybin_exec:s0"]`, `["bin/mybin"]`, 1))
+	testApexError(t, `generate_file_contexts: cannot be used together with file_contexts`,
+		strings.Replace(bp, `generate_file_contexts: true,`, `generate_file_contexts: true, file_contexts: "file_contexts",`, 1))
+}
+
+func TestInitCostBudget(t *testing.T) {
+	ctx := testApex(t, `
+		apex {
+			name: "myapex",
+			key: "myapex.key",
+			native_shared_libs: ["mylib"],
+			init_cost_budget: {
+				max_payload_size: 1048576,
+				max_native_libs: 2,
+				warn_only: true,
+			},
+			updatable: false,
+		}
+
+		apex_key {
+			name: "myapex.key",
+			public_key: "testkey.avbpubkey",
+			private_key: "testkey.pem",
+		}
+
+		cc_library {
+			name: "mylib",
+			srcs: ["mylib.cpp"],
+			system_shared_libs: [],
+			stl: "none",
+			apex_available: [ "myapex" ],
+		}
+	`)
+	module := ctx.ModuleForTests("myapex", "android_common_myapex_image")
+	check := module.Rule("apexInitCostCheckRule")
+	android.AssertStringEquals(t, "warn_only", "true", check.Args["warn_only"])
+	android.AssertStringEquals(t, "metrics",
+		"1048576 native_libs 1 2 jni_libs 0 -1 classpath_entries 0 -1", check.Args["metrics"])
+	android.AssertIntEquals(t, "number of payload files", 1, len(check.Inputs))
+	android.AssertStringEquals(t, "payload file", "mylib.so", check.Inputs[0].Base())
+
+	signed := module.Output("myapex.apex")
+	ensureListContains(t, signed.Validations.Strings(), check.Output.String())
+}
+
 func TestApexKeyFromOtherModule(t *testing.T) {
 	ctx := testApex(t, `
 		apex_key {
@@ -4996,6 +5157,40 @@ func TestPrebuilt(t *testing.T) {
 	android.AssertStringEquals(t, "unexpected LOCAL_SOONG_MODULE_TYPE", "prebuilt_apex", entries.EntryMap["LOCAL_SOONG_MODULE_TYPE"][0])
 }
 
+func TestPrebuiltArtifactContents(t *testing.T) {
+	ctx := testApex(t, `
+		prebuilt_apex {
+			name: "myapex",
+			src: "myapex-arm.apex",
+			artifact_contents: {
+				min_sdk_version: "30",
+				native_libs: [],
+			},
+		}
+	`)
+
+	testingModule := ctx.ModuleForTests("myapex", "android_common_myapex")
+	check := testingModule.Output("check_prebuilt_artifact.timestamp")
+	for _, flag := range []string{
+		"--min-sdk-version 30",
+		"--no-native-libs",
+		"--deapexer",
+		"myapex-arm.apex",
+	} {
+		android.AssertStringDoesContain(t, "check command", check.RuleParams.Command, flag)
+	}
+
+	testApexError(t, `artifact_contents.has_code: is only supported for APKs`, `
+		prebuilt_apex {
+			name: "myapex",
+			src: "myapex-arm.apex",
+			artifact_contents: {
+				has_code: true,
+			},
+		}
+	`)
+}
+
 func TestPrebuiltMissingSrc(t *testing.T) {
 	testApexError(t, `module "myapex" variant "android_common_myapex".*: prebuilt_apex does not support "arm64_armv8-a"`, `
 		prebuilt_apex {
@@ -10421,3 +10616,31 @@ func TestCannedFsConfig_HasCustomConfig(t *testing.T) {
 	// Ensure that canned_fs_config has "cat my_config" at the end
 	ensureContains(t, cmd, `( echo '/ 1000 1000 0755'; echo '/apex_manifest.json 1000 1000 0644'; echo '/apex_manifest.pb 1000 1000 0644'; cat my_config ) >`)
 }
+
+func TestApexSizeBaseline(t *testing.T) {
+	ctx := testApex(t, `
+		apex {
+			name: "myapex",
+			key: "myapex.key",
+			updatable: false,
+			size_baseline: {
+				file: "myapex.size",
+				max_increase_bytes: 4096,
+			},
+		}
+
+		apex_key {
+			name: "myapex.key",
+			public_key: "testkey.avbpubkey",
+			private_key: "testkey.pem",
+		}
+	`, withFiles(android.MockFS{
+		"myapex.size": []byte("1000000\n"),
+	}))
+
+	module := ctx.ModuleForTests("myapex", "android_common_myapex_image")
+	check := module.Rule("sizeBaselineCheck")
+	android.AssertStringEquals(t, "checked apex", "myapex.apex", check.Input.Base())
+	android.AssertPathRelativeToTopEquals(t, "baseline dependency", "myapex.size", check.Implicit)
+	android.AssertStringEquals(t, "flags", "--max-increase-bytes 4096", check.Args["flags"])
+}
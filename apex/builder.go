@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"sort"
 	"strconv"
@@ -75,6 +76,7 @@ func init() {
 	pctx.HostBinToolVariable("deapexer", "deapexer")
 	pctx.HostBinToolVariable("debugfs_static", "debugfs_static")
 	pctx.SourcePathVariable("genNdkUsedbyApexPath", "build/soong/scripts/gen_ndk_usedby_apex.sh")
+	pctx.SourcePathVariable("checkApexInitCostPath", "build/soong/scripts/check_apex_init_cost.sh")
 }
 
 var (
@@ -108,7 +110,7 @@ var (
 	apexRule = pctx.StaticRule("apexRule", blueprint.RuleParams{
 		Command: `rm -rf ${image_dir} && mkdir -p ${image_dir} && ` +
 			`(. ${out}.copy_commands) && ` +
-			`APEXER_TOOL_PATH=${tool_path} ` +
+			`APEXER_TOOL_PATH=${tool_path} ${android.SourceDateEpochEnv} ` +
 			`${apexer} --force --manifest ${manifest} ` +
 			`--file_contexts ${file_contexts} ` +
 			`--canned_fs_config ${canned_fs_config} ` +
@@ -127,7 +129,7 @@ var (
 	DCLAApexRule = pctx.StaticRule("DCLAApexRule", blueprint.RuleParams{
 		Command: `rm -rf ${image_dir} && mkdir -p ${image_dir} && ` +
 			`(. ${out}.copy_commands) && ` +
-			`APEXER_TOOL_PATH=${tool_path} ` +
+			`APEXER_TOOL_PATH=${tool_path} ${android.SourceDateEpochEnv} ` +
 			`${apexer_with_DCLA_preprocessing} ` +
 			`--apexer ${apexer} ` +
 			`--canned_fs_config ${canned_fs_config} ` +
@@ -154,7 +156,7 @@ var (
 	TrimmedApexRule = pctx.StaticRule("TrimmedApexRule", blueprint.RuleParams{
 		Command: `rm -rf ${image_dir} && mkdir -p ${image_dir} && ` +
 			`(. ${out}.copy_commands) && ` +
-			`APEXER_TOOL_PATH=${tool_path} ` +
+			`APEXER_TOOL_PATH=${tool_path} ${android.SourceDateEpochEnv} ` +
 			`${apexer_with_trim_preprocessing} ` +
 			`--apexer ${apexer} ` +
 			`--canned_fs_config ${canned_fs_config} ` +
@@ -182,7 +184,7 @@ var (
 	zipApexRule = pctx.StaticRule("zipApexRule", blueprint.RuleParams{
 		Command: `rm -rf ${image_dir} && mkdir -p ${image_dir} && ` +
 			`(. ${out}.copy_commands) && ` +
-			`APEXER_TOOL_PATH=${tool_path} ` +
+			`APEXER_TOOL_PATH=${tool_path} ${android.SourceDateEpochEnv} ` +
 			`${apexer} --force --manifest ${manifest} ` +
 			`--payload_type zip ` +
 			`${image_dir} ${out} `,
@@ -235,8 +237,25 @@ var (
 		CommandDeps: []string{"${apex_sepolicy_tests}", "${deapexer}", "${debugfs_static}"},
 		Description: "run apex_sepolicy_tests",
 	})
+
+	apexInitCostCheckRule = pctx.StaticRule("apexInitCostCheckRule", blueprint.RuleParams{
+		Command:     `${checkApexInitCostPath} ${apex_module_name} ${warn_only} ${out} ${metrics} -- ${in}`,
+		CommandDeps: []string{"${checkApexInitCostPath}"},
+		Description: "Check boot-time initialization cost of ${apex_module_name}",
+	}, "apex_module_name", "warn_only", "metrics")
+
+	fileContextsSepolicyTestsRule = pctx.StaticRule("fileContextsSepolicyTestsRule", blueprint.RuleParams{
+		Command:     `${apex_sepolicy_tests} -f ${in} && touch ${out}`,
+		CommandDeps: []string{"${apex_sepolicy_tests}"},
+		Description: "run apex_sepolicy_tests on generated file_contexts",
+	})
+
+	// SELinux context in the form of user:role:type:level
+	fileContextsLabelRegexp = regexp.MustCompile(`^\w+:\w+:\w+:\S+$`)
 )
 
+const systemFileLabel = "u:object_r:system_file:s0"
+
 // buildManifest creates buile rules to modify the input apex_manifest.json to add information
 // gathered by the build system such as provided/required native libraries. Two output files having
 // different formats are generated. a.manifestJsonOut is JSON format for Q devices, and
@@ -317,28 +336,38 @@ func (a *apexBundle) buildManifest(ctx android.ModuleContext, provideNativeLibs,
 // labeled as system_file.
 func (a *apexBundle) buildFileContexts(ctx android.ModuleContext) android.OutputPath {
 	var fileContexts android.Path
-	var fileContextsDir string
-	if a.properties.File_contexts == nil {
-		fileContexts = android.PathForSource(ctx, "system/sepolicy/apex", ctx.ModuleName()+"-file_contexts")
+	var validations android.Paths
+	if proptools.Bool(a.properties.Generate_file_contexts) {
+		if a.properties.File_contexts != nil {
+			ctx.PropertyErrorf("generate_file_contexts", "cannot be used together with file_contexts")
+		}
+		var validation android.Path
+		fileContexts, validation = a.generateFileContexts(ctx)
+		validations = append(validations, validation)
 	} else {
-		if m, t := android.SrcIsModuleWithTag(*a.properties.File_contexts); m != "" {
-			otherModule := android.GetModuleFromPathDep(ctx, m, t)
-			fileContextsDir = ctx.OtherModuleDir(otherModule)
+		var fileContextsDir string
+		if a.properties.File_contexts == nil {
+			fileContexts = android.PathForSource(ctx, "system/sepolicy/apex", ctx.ModuleName()+"-file_contexts")
+		} else {
+			if m, t := android.SrcIsModuleWithTag(*a.properties.File_contexts); m != "" {
+				otherModule := android.GetModuleFromPathDep(ctx, m, t)
+				fileContextsDir = ctx.OtherModuleDir(otherModule)
+			}
+			fileContexts = android.PathForModuleSrc(ctx, *a.properties.File_contexts)
 		}
-		fileContexts = android.PathForModuleSrc(ctx, *a.properties.File_contexts)
-	}
-	if fileContextsDir == "" {
-		fileContextsDir = filepath.Dir(fileContexts.String())
-	}
-	fileContextsDir += string(filepath.Separator)
+		if fileContextsDir == "" {
+			fileContextsDir = filepath.Dir(fileContexts.String())
+		}
+		fileContextsDir += string(filepath.Separator)
 
-	if a.Platform() {
-		if !strings.HasPrefix(fileContextsDir, "system/sepolicy/") {
-			ctx.PropertyErrorf("file_contexts", "should be under system/sepolicy, but found in  %q", fileContextsDir)
+		if a.Platform() {
+			if !strings.HasPrefix(fileContextsDir, "system/sepolicy/") {
+				ctx.PropertyErrorf("file_contexts", "should be under system/sepolicy, but found in  %q", fileContextsDir)
+			}
+		}
+		if !android.ExistentPathForSource(ctx, fileContexts.String()).Valid() {
+			ctx.PropertyErrorf("file_contexts", "cannot find file_contexts file: %q", fileContexts.String())
 		}
-	}
-	if !android.ExistentPathForSource(ctx, fileContexts.String()).Valid() {
-		ctx.PropertyErrorf("file_contexts", "cannot find file_contexts file: %q", fileContexts.String())
 	}
 
 	useFileContextsAsIs := proptools.Bool(a.properties.Use_file_contexts_as_is)
@@ -349,7 +378,7 @@ func (a *apexBundle) buildFileContexts(ctx android.ModuleContext) android.Output
 	switch a.properties.ApexType {
 	case imageApex:
 		// remove old file
-		rule.Command().Text("rm").FlagWithOutput("-f ", output)
+		rule.Command().Text("rm").FlagWithOutput("-f ", output).Validations(validations)
 		// copy file_contexts
 		rule.Command().Text("cat").Input(fileContexts).Text(">>").Output(output)
 		// new line
@@ -366,7 +395,7 @@ func (a *apexBundle) buildFileContexts(ctx android.ModuleContext) android.Output
 		apexPath := android.InstallPathToOnDevicePath(ctx, a.installDir.Join(ctx, a.Name()))
 		apexPath = strings.ReplaceAll(apexPath, ".", `\\.`)
 		// remove old file
-		rule.Command().Text("rm").FlagWithOutput("-f ", output)
+		rule.Command().Text("rm").FlagWithOutput("-f ", output).Validations(validations)
 		// copy file_contexts
 		rule.Command().Text("awk").Text(`'/object_r/{printf("` + apexPath + `%s\n", $0)}'`).Input(fileContexts).Text(">").Output(output)
 		// new line
@@ -384,6 +413,82 @@ func (a *apexBundle) buildFileContexts(ctx android.ModuleContext) android.Output
 	return output.OutputPath
 }
 
+// generateFileContexts creates a file_contexts file for this APEX from the files in it and the
+// file_contexts_labels property, so that the labels don't go stale as the payload changes. It
+// also returns a validation which checks the resulting labels against sepolicy.
+func (a *apexBundle) generateFileContexts(ctx android.ModuleContext) (android.Path, android.Path) {
+	type labelEntry struct {
+		path  string
+		label string
+		used  bool
+	}
+	var entries []*labelEntry
+	for _, e := range a.properties.File_contexts_labels {
+		path, label, found := strings.Cut(e, "=")
+		path = strings.Trim(filepath.Clean(strings.TrimSpace(path)), "/")
+		label = strings.TrimSpace(label)
+		if !found || path == "" || path == "." || !fileContextsLabelRegexp.MatchString(label) {
+			ctx.PropertyErrorf("file_contexts_labels", "invalid entry %q, expected <path>=<label>", e)
+			continue
+		}
+		entries = append(entries, &labelEntry{path: path, label: label})
+	}
+
+	labelFor := func(pathInApex string) string {
+		var match *labelEntry
+		for _, e := range entries {
+			if pathInApex == e.path || strings.HasPrefix(pathInApex, e.path+"/") {
+				if match == nil || len(e.path) > len(match.path) {
+					match = e
+				}
+			}
+		}
+		if match == nil {
+			return systemFileLabel
+		}
+		match.used = true
+		return match.label
+	}
+
+	var paths []string
+	for _, f := range a.filesInfo {
+		paths = append(paths, f.path())
+		paths = append(paths, f.symlinkPaths()...)
+	}
+	paths = android.SortedUniqueStrings(paths)
+
+	fcLines := []string{"(/.*)? " + systemFileLabel}
+	var listLines []string
+	for _, p := range paths {
+		label := labelFor(p)
+		listLines = append(listLines, "/"+p+" "+label)
+		if label != systemFileLabel {
+			fcLines = append(fcLines, regexp.QuoteMeta("/"+p)+" "+label)
+		}
+	}
+
+	for _, e := range entries {
+		if !e.used {
+			ctx.PropertyErrorf("file_contexts_labels", "%q does not match any file in this APEX", e.path)
+		}
+	}
+
+	fileContexts := android.PathForModuleOut(ctx, "file_contexts.generated")
+	android.WriteFileRule(ctx, fileContexts, strings.Join(fcLines, "\n"))
+
+	// apex_sepolicy_tests takes the labels of the files in the same format as `deapexer list -Z`.
+	labels := android.PathForModuleOut(ctx, "file_contexts.labels")
+	android.WriteFileRule(ctx, labels, strings.Join(listLines, "\n"))
+	timestamp := android.PathForModuleOut(ctx, "file_contexts_sepolicy_tests.timestamp")
+	ctx.Build(pctx, android.BuildParams{
+		Rule:   fileContextsSepolicyTestsRule,
+		Input:  labels,
+		Output: timestamp,
+	})
+
+	return fileContexts, timestamp
+}
+
 // buildInstalledFilesFile creates a build rule for the installed-files.txt file where the list of
 // files included in this APEX is shown. The text file is dist'ed so that people can see what's
 // included in the APEX without actually downloading and extracting it.
@@ -879,6 +984,9 @@ func (a *apexBundle) buildUnflattenedApex(ctx android.ModuleContext) {
 	if suffix == imageApexSuffix {
 		validations = append(validations, runApexSepolicyTests(ctx, unsignedOutputFile.OutputPath))
 	}
+	if a.properties.Init_cost_budget.isSet() {
+		validations = append(validations, a.checkInitCost(ctx))
+	}
 	ctx.Build(pctx, android.BuildParams{
 		Rule:        rule,
 		Description: "signapk",
@@ -935,9 +1043,25 @@ func (a *apexBundle) buildUnflattenedApex(ctx android.ModuleContext) {
 		a.SkipInstall()
 	}
 
+	// Check the size of the APEX against its size baseline, when installing or checkbuilding it.
+	installDeps := a.compatSymlinks.Paths()
+	if sizeBaselineCheck := android.CheckSizeBaseline(ctx, &a.sizeBaselineProperties, a.outputFile); sizeBaselineCheck != nil {
+		installDeps = append(installDeps, sizeBaselineCheck)
+		ctx.CheckbuildFile(sizeBaselineCheck)
+	}
+
 	// Install to $OUT/soong/{target,host}/.../apex.
 	a.installedFile = ctx.InstallFile(a.installDir, a.Name()+installSuffix, a.outputFile,
-		a.compatSymlinks.Paths()...)
+		installDeps...)
+	if a.installable() {
+		android.RecordSignedArtifact(ctx, android.SignedArtifact{
+			Kind:             "apex",
+			File:             a.outputFile,
+			OnDevicePath:     android.InstallPathToOnDevicePath(ctx, a.installDir.Join(ctx, a.Name()+installSuffix)),
+			Certificates:     android.Paths{pem},
+			PayloadPublicKey: a.publicKeyFile,
+		})
+	}
 
 	// installed-files.txt is dist'ed
 	a.installedFilesFile = a.buildInstalledFilesFile(ctx, a.outputFile, imageDir)
@@ -1181,6 +1305,51 @@ func (a *apexBundle) buildCannedFsConfig(ctx android.ModuleContext) android.Outp
 	return cannedFsConfig.OutputPath
 }
 
+// checkInitCost creates a build rule to estimate the boot-time initialization cost of this APEX
+// from its payload and to check it against the init_cost_budget property. The returned report
+// is meant to be used as a validation of the APEX.
+func (a *apexBundle) checkInitCost(ctx android.ModuleContext) android.Path {
+	budget := a.properties.Init_cost_budget
+	limit := func(l *int64) string {
+		return strconv.Itoa(proptools.IntDefault(l, -1))
+	}
+
+	var payloadFiles android.Paths
+	nativeLibs, jniLibs, classpathEntries := 0, 0, 0
+	for _, f := range a.filesInfo {
+		payloadFiles = append(payloadFiles, f.builtFile)
+		switch f.class {
+		case nativeSharedLib:
+			nativeLibs++
+			if f.isJniLib {
+				jniLibs++
+			}
+		case javaSharedLib:
+			classpathEntries++
+		}
+	}
+
+	metrics := []string{
+		limit(budget.Max_payload_size),
+		"native_libs", strconv.Itoa(nativeLibs), limit(budget.Max_native_libs),
+		"jni_libs", strconv.Itoa(jniLibs), limit(budget.Max_jni_libs),
+		"classpath_entries", strconv.Itoa(classpathEntries), limit(budget.Max_classpath_entries),
+	}
+
+	report := android.PathForModuleOut(ctx, "init_cost_report.txt")
+	ctx.Build(pctx, android.BuildParams{
+		Rule:   apexInitCostCheckRule,
+		Inputs: payloadFiles,
+		Output: report,
+		Args: map[string]string{
+			"apex_module_name": a.Name(),
+			"warn_only":        strconv.FormatBool(proptools.Bool(budget.Warn_only)),
+			"metrics":          strings.Join(metrics, " "),
+		},
+	})
+	return report
+}
+
 // Runs apex_sepolicy_tests
 //
 // $ deapexer list -Z {apex_file} > {file_contexts}
@@ -477,6 +477,7 @@ type Prebuilt struct {
 	prebuiltCommon
 
 	properties PrebuiltProperties
+	artifact   android.PrebuiltArtifactProperties
 
 	inputApex android.Path
 
@@ -578,6 +579,7 @@ func (p *Prebuilt) OutputFiles(tag string) (android.Paths, error) {
 func PrebuiltFactory() android.Module {
 	module := &Prebuilt{}
 	module.AddProperties(&module.properties)
+	module.AddProperties(&module.artifact)
 	module.initPrebuiltCommon(module, &module.properties.PrebuiltCommonProperties)
 
 	return module
@@ -789,8 +791,15 @@ func (p *Prebuilt) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 		p.compatSymlinks = append(p.compatSymlinks, makeCompatSymlinks(overridden, ctx, true)...)
 	}
 
+	// Check the contents of the apex against what the Android.bp declares about them.
+	installDeps := p.compatSymlinks.Paths()
+	if check := android.CheckPrebuiltArtifact(ctx, &p.artifact, p.inputApex); check != nil {
+		installDeps = append(installDeps, check)
+		ctx.CheckbuildFile(check)
+	}
+
 	if p.installable() {
-		p.installedFile = ctx.InstallFile(p.installDir, p.installFilename, p.inputApex, p.compatSymlinks.Paths()...)
+		p.installedFile = ctx.InstallFile(p.installDir, p.installFilename, p.inputApex, installDeps...)
 		p.provenanceMetaDataFile = provenance.GenerateArtifactProvenanceMetaData(ctx, p.inputApex, p.installedFile)
 	}
 }
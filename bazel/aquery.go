@@ -564,8 +564,12 @@ func (a *aqueryArtifactHandler) symlinkActionBuildStatement(actionEntry *analysi
 	out := outputPaths[0]
 	outDir := proptools.ShellEscapeIncludingSpaces(filepath.Dir(out))
 	out = proptools.ShellEscapeIncludingSpaces(out)
-	in := filepath.Join("$PWD", proptools.ShellEscapeIncludingSpaces(inputPaths[0]))
 	// Use absolute paths, because some soong actions don't play well with relative paths (for example, `cp -d`).
+	// The input is already absolute when it is in an OUT_DIR outside of the source tree.
+	in := proptools.ShellEscapeIncludingSpaces(inputPaths[0])
+	if !filepath.IsAbs(inputPaths[0]) {
+		in = filepath.Join("$PWD", in)
+	}
 	command := fmt.Sprintf("mkdir -p %[1]s && rm -f %[2]s && ln -sf %[3]s %[2]s", outDir, out, in)
 	symlinkPaths := outputPaths[:]
 
@@ -875,6 +875,54 @@ func TestSimpleSymlink(t *testing.T) {
 	assertBuildStatements(t, actual, expectedBuildStatements)
 }
 
+func TestSymlinkAbsoluteInput(t *testing.T) {
+	const inputString = `
+{
+ "artifacts": [
+   { "id": 1, "path_fragment_id": 3 },
+   { "id": 2, "path_fragment_id": 5 }],
+ "actions": [{
+   "target_id": 1,
+   "action_key": "x",
+   "mnemonic": "Symlink",
+   "input_dep_set_ids": [1],
+   "output_ids": [2],
+   "primary_output_id": 2
+ }],
+ "dep_set_of_files": [
+   { "id": 1, "direct_artifact_ids": [1] }],
+ "path_fragments": [
+   { "id": 1, "label": "/scratch/out" },
+   { "id": 2, "label": "file_subdir", "parent_id": 1 },
+   { "id": 3, "label": "file", "parent_id": 2 },
+   { "id": 4, "label": "symlink_subdir", "parent_id": 1 },
+   { "id": 5, "label": "symlink", "parent_id": 4 }]
+}`
+	data, err := JsonToActionGraphContainer(inputString)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	actual, _, err := AqueryBuildStatements(data, &metrics.EventHandler{})
+
+	if err != nil {
+		t.Errorf("Unexpected error %q", err)
+	}
+
+	expectedBuildStatements := []*BuildStatement{
+		&BuildStatement{
+			Command: "mkdir -p /scratch/out/symlink_subdir && " +
+				"rm -f /scratch/out/symlink_subdir/symlink && " +
+				"ln -sf /scratch/out/file_subdir/file /scratch/out/symlink_subdir/symlink",
+			InputPaths:   []string{"/scratch/out/file_subdir/file"},
+			OutputPaths:  []string{"/scratch/out/symlink_subdir/symlink"},
+			SymlinkPaths: []string{"/scratch/out/symlink_subdir/symlink"},
+			Mnemonic:     "Symlink",
+		},
+	}
+	assertBuildStatements(t, actual, expectedBuildStatements)
+}
+
 func TestSymlinkQuotesPaths(t *testing.T) {
 	const inputString = `
 {
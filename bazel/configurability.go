@@ -28,33 +28,42 @@ const (
 	archRiscv64 = "riscv64"
 	archX86     = "x86"
 	archX86_64  = "x86_64"
+	archWasm32  = "wasm32"
 
 	// OsType names in arch.go
-	OsAndroid     = "android"
-	osDarwin      = "darwin"
-	osLinux       = "linux_glibc"
-	osLinuxMusl   = "linux_musl"
-	osLinuxBionic = "linux_bionic"
-	osWindows     = "windows"
+	OsAndroid       = "android"
+	osDarwin        = "darwin"
+	osLinux         = "linux_glibc"
+	osLinuxMusl     = "linux_musl"
+	osLinuxBionic   = "linux_bionic"
+	osWindows       = "windows"
+	osWasi          = "wasi"
+	osLinuxEmbedded = "linux_embedded"
+	osQnx           = "qnx"
 
 	// Targets in arch.go
-	osArchAndroidArm        = "android_arm"
-	osArchAndroidArm64      = "android_arm64"
-	osArchAndroidRiscv64    = "android_riscv64"
-	osArchAndroidX86        = "android_x86"
-	osArchAndroidX86_64     = "android_x86_64"
-	osArchDarwinArm64       = "darwin_arm64"
-	osArchDarwinX86_64      = "darwin_x86_64"
-	osArchLinuxX86          = "linux_glibc_x86"
-	osArchLinuxX86_64       = "linux_glibc_x86_64"
-	osArchLinuxMuslArm      = "linux_musl_arm"
-	osArchLinuxMuslArm64    = "linux_musl_arm64"
-	osArchLinuxMuslX86      = "linux_musl_x86"
-	osArchLinuxMuslX86_64   = "linux_musl_x86_64"
-	osArchLinuxBionicArm64  = "linux_bionic_arm64"
-	osArchLinuxBionicX86_64 = "linux_bionic_x86_64"
-	osArchWindowsX86        = "windows_x86"
-	osArchWindowsX86_64     = "windows_x86_64"
+	osArchAndroidArm          = "android_arm"
+	osArchAndroidArm64        = "android_arm64"
+	osArchAndroidRiscv64      = "android_riscv64"
+	osArchAndroidX86          = "android_x86"
+	osArchAndroidX86_64       = "android_x86_64"
+	osArchDarwinArm64         = "darwin_arm64"
+	osArchDarwinX86_64        = "darwin_x86_64"
+	osArchLinuxX86            = "linux_glibc_x86"
+	osArchLinuxX86_64         = "linux_glibc_x86_64"
+	osArchLinuxMuslArm        = "linux_musl_arm"
+	osArchLinuxMuslArm64      = "linux_musl_arm64"
+	osArchLinuxMuslX86        = "linux_musl_x86"
+	osArchLinuxMuslX86_64     = "linux_musl_x86_64"
+	osArchLinuxBionicArm64    = "linux_bionic_arm64"
+	osArchLinuxBionicX86_64   = "linux_bionic_x86_64"
+	osArchWindowsX86          = "windows_x86"
+	osArchWindowsX86_64       = "windows_x86_64"
+	osArchWasiWasm32          = "wasi_wasm32"
+	osArchLinuxEmbeddedArm64  = "linux_embedded_arm64"
+	osArchLinuxEmbeddedX86_64 = "linux_embedded_x86_64"
+	osArchQnxArm64            = "qnx_arm64"
+	osArchQnxX86_64           = "qnx_x86_64"
 
 	// This is the string representation of the default condition wherever a
 	// configurable attribute is used in a select statement, i.e.
@@ -125,6 +134,7 @@ func createPlatformArchMap() map[string]string {
 			"avx512",
 			"popcnt",
 		},
+		"wasm32": {},
 	}
 	result := make(map[string]string)
 	for arch, allFeatures := range archFeatures {
@@ -161,6 +171,9 @@ var (
 		osLinuxMusl:                "//build/bazel/platforms/os:linux_musl",
 		osLinuxBionic:              "//build/bazel/platforms/os:linux_bionic",
 		osWindows:                  "//build/bazel/platforms/os:windows",
+		osWasi:                     "//build/bazel/platforms/os:wasi",
+		osLinuxEmbedded:            "//build/bazel/platforms/os:linux_embedded",
+		osQnx:                      "//build/bazel/platforms/os:qnx",
 		ConditionsDefaultConfigKey: ConditionsDefaultSelectKey, // The default condition of an os select map.
 	}
 
@@ -182,6 +195,11 @@ var (
 		osArchLinuxBionicX86_64:    "//build/bazel/platforms/os_arch:linux_bionic_x86_64",
 		osArchWindowsX86:           "//build/bazel/platforms/os_arch:windows_x86",
 		osArchWindowsX86_64:        "//build/bazel/platforms/os_arch:windows_x86_64",
+		osArchWasiWasm32:           "//build/bazel/platforms/os_arch:wasi_wasm32",
+		osArchLinuxEmbeddedArm64:   "//build/bazel/platforms/os_arch:linux_embedded_arm64",
+		osArchLinuxEmbeddedX86_64:  "//build/bazel/platforms/os_arch:linux_embedded_x86_64",
+		osArchQnxArm64:             "//build/bazel/platforms/os_arch:qnx_arm64",
+		osArchQnxX86_64:            "//build/bazel/platforms/os_arch:qnx_x86_64",
 		ConditionsDefaultConfigKey: ConditionsDefaultSelectKey, // The default condition of an os select map.
 	}
 
@@ -197,7 +215,10 @@ var (
 		osDarwin:      {archArm64, archX86_64},
 		osLinuxBionic: {archArm64, archX86_64},
 		// TODO(cparsons): According to arch.go, this should contain archArm, archArm64, as well.
-		osWindows: {archX86, archX86_64},
+		osWindows:       {archX86, archX86_64},
+		osWasi:          {archWasm32},
+		osLinuxEmbedded: {archArm64, archX86_64},
+		osQnx:           {archArm64, archX86_64},
 	}
 
 	osAndInApexMap = map[string]string{
@@ -208,6 +229,9 @@ var (
 		osLinuxMusl:                "//build/bazel/platforms/os:linux_musl",
 		osLinuxBionic:              "//build/bazel/platforms/os:linux_bionic",
 		osWindows:                  "//build/bazel/platforms/os:windows",
+		osWasi:                     "//build/bazel/platforms/os:wasi",
+		osLinuxEmbedded:            "//build/bazel/platforms/os:linux_embedded",
+		osQnx:                      "//build/bazel/platforms/os:qnx",
 		ConditionsDefaultConfigKey: ConditionsDefaultSelectKey,
 	}
 
@@ -38,6 +38,10 @@ load("//build/bazel/queryview_rules:soong_module.bzl", "soong_module")
 	unnamedRuleTargetTemplate = `%s(
 %s)`
 
+	// Bazel's legacy licenses() declaration of a package takes the license types as a positional
+	// argument.
+	licensesTemplate = `licenses(%s)`
+
 	// A simple provider to mark and differentiate Soong module rule shims from
 	// regular Bazel rules. Every Soong module rule shim returns a
 	// SoongModuleInfo provider, and can only depend on rules returning
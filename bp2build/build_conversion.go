@@ -72,15 +72,30 @@ func (t BazelTarget) PackageName() string {
 // BazelTargets is a typedef for a slice of BazelTarget objects.
 type BazelTargets []BazelTarget
 
-func (targets BazelTargets) packageRule() *BazelTarget {
+// packageLevelRuleClasses are the rule classes of the package-level declarations, which are
+// emitted at the top of the BUILD file instead of with the targets.
+var packageLevelRuleClasses = map[string]bool{
+	"package":  true,
+	"licenses": true,
+}
+
+func (targets BazelTargets) packageLevelRule(ruleClass string) *BazelTarget {
 	for _, target := range targets {
-		if target.ruleClass == "package" {
+		if target.ruleClass == ruleClass {
 			return &target
 		}
 	}
 	return nil
 }
 
+func (targets BazelTargets) packageRule() *BazelTarget {
+	return targets.packageLevelRule("package")
+}
+
+func (targets BazelTargets) licensesRule() *BazelTarget {
+	return targets.packageLevelRule("licenses")
+}
+
 // sort a list of BazelTargets in-place, by name, and by generated/handcrafted types.
 func (targets BazelTargets) sort() {
 	sort.Slice(targets, func(i, j int) bool {
@@ -94,7 +109,7 @@ func (targets BazelTargets) sort() {
 func (targets BazelTargets) String() string {
 	var res string
 	for i, target := range targets {
-		if target.ruleClass != "package" {
+		if !packageLevelRuleClasses[target.ruleClass] {
 			res += target.content
 		}
 		if i != len(targets)-1 {
@@ -150,6 +165,11 @@ type CodegenContext struct {
 	additionalDeps     []string
 	unconvertedDepMode unconvertedDepsMode
 	topDir             string
+	formattingPolicy   formattingPolicy
+	// The error parsing BP2BUILD_FORMAT_POLICY, reported by GenerateBazelTargets.
+	formattingPolicyErr error
+	// The variants that are materialized in QueryView mode, or nil for all of them.
+	queryViewFilter *QueryViewFilter
 }
 
 func (ctx *CodegenContext) Mode() CodegenMode {
@@ -223,20 +243,23 @@ func NewCodegenContext(config android.Config, context *android.Context, mode Cod
 	if config.IsEnvTrue("BP2BUILD_ERROR_UNCONVERTED") {
 		unconvertedDeps = errorModulesUnconvertedDeps
 	}
+	policy, policyErr := parseFormattingPolicy(config.Getenv("BP2BUILD_FORMAT_POLICY"))
 	return &CodegenContext{
-		context:            context,
-		config:             config,
-		mode:               mode,
-		unconvertedDepMode: unconvertedDeps,
-		topDir:             topDir,
+		context:             context,
+		config:              config,
+		mode:                mode,
+		unconvertedDepMode:  unconvertedDeps,
+		topDir:              topDir,
+		formattingPolicy:    policy,
+		formattingPolicyErr: policyErr,
 	}
 }
 
 // props is an unsorted map. This function ensures that
 // the generated attributes are sorted to ensure determinism.
-func propsToAttributes(props map[string]string) string {
+func propsToAttributes(props map[string]string, policy formattingPolicy) string {
 	var attributes string
-	for _, propName := range android.SortedKeys(props) {
+	for _, propName := range policy.sortedAttributeNames(props) {
 		attributes += fmt.Sprintf("    %s = %s,\n", propName, props[propName])
 	}
 	return attributes
@@ -260,6 +283,9 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 	dirs := make(map[string]bool)
 
 	var errs []error
+	if ctx.formattingPolicyErr != nil {
+		return conversionResults{}, []error{ctx.formattingPolicyErr}
+	}
 
 	bpCtx := ctx.Context()
 	bpCtx.VisitAllModules(func(m blueprint.Module) {
@@ -284,11 +310,13 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 
 				// Log the module.
 				metrics.AddConvertedModule(m, moduleType, dir, Handcrafted)
+				metrics.reportHandcraftedModule(m, moduleType, dir)
 			} else if aModule, ok := m.(android.Module); ok && aModule.IsConvertedByBp2build() {
 				// Handle modules converted to generated targets.
 
 				// Log the module.
 				metrics.AddConvertedModule(aModule, moduleType, dir, Generated)
+				metrics.reportGeneratedModule(aModule, moduleType, dir)
 
 				// Handle modules with unconverted deps. By default, emit a warning.
 				if unconvertedDeps := aModule.GetUnconvertedBp2buildDeps(); len(unconvertedDeps) > 0 {
@@ -314,7 +342,7 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 					}
 				}
 				var targetErrs []error
-				targets, targetErrs = generateBazelTargets(bpCtx, aModule)
+				targets, targetErrs = generateBazelTargets(bpCtx, aModule, ctx.formattingPolicy)
 				errs = append(errs, targetErrs...)
 				for _, t := range targets {
 					// A module can potentially generate more than 1 Bazel
@@ -326,6 +354,7 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 				errs = append(errs, err)
 			} else {
 				metrics.AddUnconvertedModule(moduleType)
+				metrics.reportUnconvertedModule(m, moduleType, dir)
 				return
 			}
 		case QueryView:
@@ -335,14 +364,19 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 				// be mapped cleanly to a bazel label.
 				return
 			}
-			t, err := generateSoongModuleTarget(bpCtx, m)
+			if !ctx.queryViewFilter.matches(ctx.Config(), m) {
+				return
+			}
+			t, err := generateSoongModuleTarget(bpCtx, m, func(dep blueprint.Module) bool {
+				return ctx.queryViewFilter.matches(ctx.Config(), dep)
+			})
 			if err != nil {
 				errs = append(errs, err)
 			}
 			targets = append(targets, t)
 		case ApiBp2build:
 			if aModule, ok := m.(android.Module); ok && aModule.IsConvertedByBp2build() {
-				targets, errs = generateBazelTargets(bpCtx, aModule)
+				targets, errs = generateBazelTargets(bpCtx, aModule, ctx.formattingPolicy)
 			}
 		default:
 			errs = append(errs, fmt.Errorf("Unknown code-generation mode: %s", ctx.Mode()))
@@ -386,11 +420,11 @@ func GenerateBazelTargets(ctx *CodegenContext, generateFilegroups bool) (convers
 	}, errs
 }
 
-func generateBazelTargets(ctx bpToBuildContext, m android.Module) ([]BazelTarget, []error) {
+func generateBazelTargets(ctx bpToBuildContext, m android.Module, policy formattingPolicy) ([]BazelTarget, []error) {
 	var targets []BazelTarget
 	var errs []error
 	for _, m := range m.Bp2buildTargets() {
-		target, err := generateBazelTarget(ctx, m)
+		target, err := generateBazelTarget(ctx, m, policy)
 		if err != nil {
 			errs = append(errs, err)
 			return targets, errs
@@ -408,13 +442,13 @@ type bp2buildModule interface {
 	BazelAttributes() []interface{}
 }
 
-func generateBazelTarget(ctx bpToBuildContext, m bp2buildModule) (BazelTarget, error) {
+func generateBazelTarget(ctx bpToBuildContext, m bp2buildModule, policy formattingPolicy) (BazelTarget, error) {
 	ruleClass := m.BazelRuleClass()
 	bzlLoadLocation := m.BazelRuleLoadLocation()
 
 	// extract the bazel attributes from the module.
 	attrs := m.BazelAttributes()
-	props, err := extractModuleProperties(attrs, true)
+	props, err := extractModuleProperties(attrs, true, policy)
 	if err != nil {
 		return BazelTarget{}, err
 	}
@@ -424,10 +458,12 @@ func generateBazelTarget(ctx bpToBuildContext, m bp2buildModule) (BazelTarget, e
 
 	// Return the Bazel target with rule class and attributes, ready to be
 	// code-generated.
-	attributes := propsToAttributes(props.Attrs)
+	attributes := propsToAttributes(props.Attrs, policy)
 	var content string
 	targetName := m.TargetName()
-	if targetName != "" {
+	if ruleClass == "licenses" {
+		content = fmt.Sprintf(licensesTemplate, props.Attrs["license_types"])
+	} else if targetName != "" {
 		content = fmt.Sprintf(ruleTargetTemplate, ruleClass, targetName, attributes)
 	} else {
 		content = fmt.Sprintf(unnamedRuleTargetTemplate, ruleClass, attributes)
@@ -442,8 +478,8 @@ func generateBazelTarget(ctx bpToBuildContext, m bp2buildModule) (BazelTarget, e
 }
 
 // Convert a module and its deps and props into a Bazel macro/rule
-// representation in the BUILD file.
-func generateSoongModuleTarget(ctx bpToBuildContext, m blueprint.Module) (BazelTarget, error) {
+// representation in the BUILD file. Only the deps for which keepDep returns true are listed.
+func generateSoongModuleTarget(ctx bpToBuildContext, m blueprint.Module, keepDep func(blueprint.Module) bool) (BazelTarget, error) {
 	props, err := getBuildProperties(ctx, m)
 
 	// TODO(b/163018919): DirectDeps can have duplicate (module, variant)
@@ -452,6 +488,9 @@ func generateSoongModuleTarget(ctx bpToBuildContext, m blueprint.Module) (BazelT
 	depLabels := map[string]bool{}
 	if aModule, ok := m.(android.Module); ok {
 		ctx.VisitDirectDeps(aModule, func(depModule blueprint.Module) {
+			if !keepDep(depModule) {
+				return
+			}
 			depLabels[qualifiedTargetLabel(ctx, depModule)] = true
 		})
 	}
@@ -459,10 +498,10 @@ func generateSoongModuleTarget(ctx bpToBuildContext, m blueprint.Module) (BazelT
 	for p := range ignoredPropNames {
 		delete(props.Attrs, p)
 	}
-	attributes := propsToAttributes(props.Attrs)
+	attributes := propsToAttributes(props.Attrs, defaultFormattingPolicy)
 
 	depLabelList := "[\n"
-	for depLabel := range depLabels {
+	for _, depLabel := range android.SortedKeys(depLabels) {
 		depLabelList += fmt.Sprintf("        %q,\n", depLabel)
 	}
 	depLabelList += "    ]"
@@ -486,14 +525,14 @@ func getBuildProperties(ctx bpToBuildContext, m blueprint.Module) (BazelAttribut
 	// TODO: this omits properties for blueprint modules (blueprint_go_binary,
 	// bootstrap_go_binary, bootstrap_go_package), which will have to be handled separately.
 	if aModule, ok := m.(android.Module); ok {
-		return extractModuleProperties(aModule.GetProperties(), false)
+		return extractModuleProperties(aModule.GetProperties(), false, defaultFormattingPolicy)
 	}
 
 	return BazelAttributes{}, nil
 }
 
 // Generically extract module properties and types into a map, keyed by the module property name.
-func extractModuleProperties(props []interface{}, checkForDuplicateProperties bool) (BazelAttributes, error) {
+func extractModuleProperties(props []interface{}, checkForDuplicateProperties bool, policy formattingPolicy) (BazelAttributes, error) {
 	ret := map[string]string{}
 
 	// Iterate over this android.Module's property structs.
@@ -506,7 +545,7 @@ func extractModuleProperties(props []interface{}, checkForDuplicateProperties bo
 		// manipulate internal props, if needed.
 		if isStructPtr(propertiesValue.Type()) {
 			structValue := propertiesValue.Elem()
-			ok, err := extractStructProperties(structValue, 0)
+			ok, err := extractStructProperties(structValue, 0, policy)
 			if err != nil {
 				return BazelAttributes{}, err
 			}
@@ -537,7 +576,7 @@ func isStructPtr(t reflect.Type) bool {
 
 // prettyPrint a property value into the equivalent Starlark representation
 // recursively.
-func prettyPrint(propertyValue reflect.Value, indent int, emitZeroValues bool) (string, error) {
+func prettyPrint(propertyValue reflect.Value, indent int, emitZeroValues bool, policy formattingPolicy) (string, error) {
 	if !emitZeroValues && isZero(propertyValue) {
 		// A property value being set or unset actually matters -- Soong does set default
 		// values for unset properties, like system_shared_libs = ["libc", "libm", "libdl"] at
@@ -560,11 +599,11 @@ func prettyPrint(propertyValue reflect.Value, indent int, emitZeroValues bool) (
 	case reflect.Int, reflect.Uint, reflect.Int64:
 		return fmt.Sprintf("%v", propertyValue.Interface()), nil
 	case reflect.Ptr:
-		return prettyPrint(propertyValue.Elem(), indent, emitZeroValues)
+		return prettyPrint(propertyValue.Elem(), indent, emitZeroValues, policy)
 	case reflect.Slice:
 		elements := make([]string, 0, propertyValue.Len())
 		for i := 0; i < propertyValue.Len(); i++ {
-			val, err := prettyPrint(propertyValue.Index(i), indent, emitZeroValues)
+			val, err := prettyPrint(propertyValue.Index(i), indent, emitZeroValues, policy)
 			if err != nil {
 				return "", err
 			}
@@ -580,13 +619,13 @@ func prettyPrint(propertyValue reflect.Value, indent int, emitZeroValues bool) (
 		// Special cases where the bp2build sends additional information to the codegenerator
 		// by wrapping the attributes in a custom struct type.
 		if attr, ok := propertyValue.Interface().(bazel.Attribute); ok {
-			return prettyPrintAttribute(attr, indent)
+			return prettyPrintAttribute(attr, indent, policy)
 		} else if label, ok := propertyValue.Interface().(bazel.Label); ok {
 			return fmt.Sprintf("%q", label.Label), nil
 		}
 
 		// Sort and print the struct props by the key.
-		structProps, err := extractStructProperties(propertyValue, indent)
+		structProps, err := extractStructProperties(propertyValue, indent, policy)
 
 		if err != nil {
 			return "", err
@@ -610,7 +649,7 @@ func prettyPrint(propertyValue reflect.Value, indent int, emitZeroValues bool) (
 // which each property value correctly pretty-printed and indented at the right nest level,
 // since property structs can be nested. In Starlark, nested structs are represented as nested
 // dicts: https://docs.bazel.build/skylark/lib/dict.html
-func extractStructProperties(structValue reflect.Value, indent int) (map[string]string, error) {
+func extractStructProperties(structValue reflect.Value, indent int, policy formattingPolicy) (map[string]string, error) {
 	if structValue.Kind() != reflect.Struct {
 		return map[string]string{}, fmt.Errorf("Expected a reflect.Struct type, but got %s", structValue.Kind())
 	}
@@ -637,7 +676,7 @@ func extractStructProperties(structValue reflect.Value, indent int) (map[string]
 				fieldValue = fieldValue.Elem()
 			}
 			if fieldValue.Type().Kind() == reflect.Struct {
-				propsToMerge, err := extractStructProperties(fieldValue, indent)
+				propsToMerge, err := extractStructProperties(fieldValue, indent, policy)
 				if err != nil {
 					return map[string]string{}, err
 				}
@@ -650,7 +689,7 @@ func extractStructProperties(structValue reflect.Value, indent int) (map[string]
 
 		propertyName := proptools.PropertyNameForField(field.Name)
 		var prettyPrintedValue string
-		prettyPrintedValue, err = prettyPrint(fieldValue, indent+1, false)
+		prettyPrintedValue, err = prettyPrint(fieldValue, indent+1, false, policy)
 		if err != nil {
 			return map[string]string{}, fmt.Errorf(
 				"Error while parsing property: %q. %s",
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"reflect"
 
-	"android/soong/android"
 	"android/soong/bazel"
 	"android/soong/starlark_fmt"
 )
@@ -158,7 +157,7 @@ var (
 
 // prettyPrintAttribute converts an Attribute to its Bazel syntax. May contain
 // select statements.
-func prettyPrintAttribute(v bazel.Attribute, indent int) (string, error) {
+func prettyPrintAttribute(v bazel.Attribute, indent int, policy formattingPolicy) (string, error) {
 	var value reflect.Value
 	// configurableAttrs is the list of individual select statements to be
 	// concatenated together. These select statements should be along different
@@ -184,6 +183,9 @@ func prettyPrintAttribute(v bazel.Attribute, indent int) (string, error) {
 		defaultSelectValue = &emptyBazelList
 	case bazel.LabelListAttribute:
 		value, configurableAttrs, prepend = getLabelListValues(list)
+		if policy.sortLabelLists {
+			value, configurableAttrs = sortLabelListValues(value, configurableAttrs)
+		}
 		emitZeroValues = list.EmitEmptyList
 		defaultSelectValue = &emptyBazelList
 		if list.ForceSpecifyEmptyList && (!value.IsNil() || list.HasConfigurableValues()) {
@@ -208,7 +210,7 @@ func prettyPrintAttribute(v bazel.Attribute, indent int) (string, error) {
 	var err error
 	ret := ""
 	if value.Kind() != reflect.Invalid {
-		s, err := prettyPrint(value, indent, false) // never emit zero values for the base value
+		s, err := prettyPrint(value, indent, false, policy) // never emit zero values for the base value
 		if err != nil {
 			return ret, err
 		}
@@ -217,7 +219,7 @@ func prettyPrintAttribute(v bazel.Attribute, indent int) (string, error) {
 	}
 	// Convenience function to prepend/append selects components to an attribute value.
 	concatenateSelects := func(selectsData selects, defaultValue *string, s string, prepend bool) (string, error) {
-		selectMap, err := prettyPrintSelectMap(selectsData, defaultValue, indent, emitZeroValues)
+		selectMap, err := prettyPrintSelectMap(selectsData, defaultValue, indent, emitZeroValues, policy)
 		if err != nil {
 			return "", err
 		}
@@ -250,13 +252,13 @@ func prettyPrintAttribute(v bazel.Attribute, indent int) (string, error) {
 
 // prettyPrintSelectMap converts a map of select keys to reflected Values as a generic way
 // to construct a select map for any kind of attribute type.
-func prettyPrintSelectMap(selectMap map[string]reflect.Value, defaultValue *string, indent int, emitZeroValues bool) (string, error) {
+func prettyPrintSelectMap(selectMap map[string]reflect.Value, defaultValue *string, indent int, emitZeroValues bool, policy formattingPolicy) (string, error) {
 	if selectMap == nil {
 		return "", nil
 	}
 
 	var selects string
-	for _, selectKey := range android.SortedKeys(selectMap) {
+	for _, selectKey := range policy.sortedSelectKeys(selectMap) {
 		if selectKey == bazel.ConditionsDefaultSelectKey {
 			// Handle default condition later.
 			continue
@@ -267,7 +269,7 @@ func prettyPrintSelectMap(selectMap map[string]reflect.Value, defaultValue *stri
 			// the default value is non-zero.
 			continue
 		}
-		s, err := prettyPrintSelectEntry(value, selectKey, indent, true)
+		s, err := prettyPrintSelectEntry(value, selectKey, indent, true, policy)
 		if err != nil {
 			return "", err
 		}
@@ -288,7 +290,7 @@ func prettyPrintSelectMap(selectMap map[string]reflect.Value, defaultValue *stri
 	ret += selects
 
 	// Handle the default condition
-	s, err := prettyPrintSelectEntry(selectMap[bazel.ConditionsDefaultSelectKey], bazel.ConditionsDefaultSelectKey, indent, emitZeroValues)
+	s, err := prettyPrintSelectEntry(selectMap[bazel.ConditionsDefaultSelectKey], bazel.ConditionsDefaultSelectKey, indent, emitZeroValues, policy)
 	if err != nil {
 		return "", err
 	}
@@ -310,9 +312,9 @@ func prettyPrintSelectMap(selectMap map[string]reflect.Value, defaultValue *stri
 
 // prettyPrintSelectEntry converts a reflect.Value into an entry in a select map
 // with a provided key.
-func prettyPrintSelectEntry(value reflect.Value, key string, indent int, emitZeroValues bool) (string, error) {
+func prettyPrintSelectEntry(value reflect.Value, key string, indent int, emitZeroValues bool, policy formattingPolicy) (string, error) {
 	s := starlark_fmt.Indention(indent + 1)
-	v, err := prettyPrint(value, indent+1, emitZeroValues)
+	v, err := prettyPrint(value, indent+1, emitZeroValues, policy)
 	if err != nil {
 		return "", err
 	}
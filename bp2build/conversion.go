@@ -154,6 +154,9 @@ func createBuildFiles(buildToTargets map[string]BazelTargets, mode CodegenMode)
 				prText = pr.content
 			}
 			content += prText
+			if lr := targets.licensesRule(); lr != nil {
+				content += "\n" + lr.content
+			}
 		} else if mode == QueryView {
 			content = soongModuleLoad
 		}
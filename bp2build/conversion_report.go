@@ -0,0 +1,139 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint"
+)
+
+// The conversion report lists every module that bp2build saw, whether it was converted, the labels
+// of its Bazel targets and why it wasn't converted if it wasn't, so that the progress of the
+// migration to Bazel can be tracked per directory or per team by tools.
+const bp2buildConversionReportFilename = "bp2build_conversion_report.json"
+
+// ConversionReport is the machine-readable report of a bp2build run.
+type ConversionReport struct {
+	Modules []ConversionReportModule
+}
+
+// ConversionReportModule is a module in the bp2build conversion report.
+type ConversionReportModule struct {
+	Name      string
+	Type      string
+	Dir       string
+	Converted bool
+
+	// Whether the module is converted to a handcrafted Bazel target instead of a generated one.
+	Handcrafted bool `json:",omitempty"`
+
+	// The labels of the Bazel targets of a converted module.
+	Labels []string `json:",omitempty"`
+
+	// Why a module wasn't converted.
+	Reason *android.UnconvertedReason `json:",omitempty"`
+
+	// The direct dependencies of a converted module that weren't converted or weren't found.
+	UnconvertedDeps []string `json:",omitempty"`
+	MissingDeps     []string `json:",omitempty"`
+}
+
+func conversionReportModule(m blueprint.Module, moduleType string, dir string) ConversionReportModule {
+	return ConversionReportModule{
+		// Undo prebuilt_ module name prefix modifications
+		Name: android.RemoveOptionalPrebuiltPrefix(m.Name()),
+		Type: moduleType,
+		Dir:  dir,
+	}
+}
+
+func (metrics *CodegenMetrics) addConversionReportModule(module ConversionReportModule) {
+	// a package module has empty name
+	if module.Type == "package" {
+		return
+	}
+	metrics.conversionReport.Modules = append(metrics.conversionReport.Modules, module)
+}
+
+// reportHandcraftedModule adds a module converted to a handcrafted target to the conversion report.
+func (metrics *CodegenMetrics) reportHandcraftedModule(m blueprint.Module, moduleType string, dir string) {
+	module := conversionReportModule(m, moduleType, dir)
+	module.Converted = true
+	module.Handcrafted = true
+	if b, ok := m.(android.Bazelable); ok {
+		module.Labels = []string{b.HandcraftedLabel()}
+	}
+	metrics.addConversionReportModule(module)
+}
+
+// reportGeneratedModule adds a module converted to generated targets to the conversion report.
+func (metrics *CodegenMetrics) reportGeneratedModule(m android.Module, moduleType string, dir string) {
+	module := conversionReportModule(m, moduleType, dir)
+	module.Converted = true
+	for _, target := range m.Bp2buildTargets() {
+		module.Labels = append(module.Labels, "//"+target.TargetPackage()+":"+target.TargetName())
+	}
+	module.UnconvertedDeps = m.GetUnconvertedBp2buildDeps()
+	module.MissingDeps = m.GetMissingBp2buildDeps()
+	metrics.addConversionReportModule(module)
+}
+
+// reportUnconvertedModule adds a module that wasn't converted to the conversion report, with the
+// reason that the bp2build_conversion mutator or the converter of its module type recorded.
+func (metrics *CodegenMetrics) reportUnconvertedModule(m blueprint.Module, moduleType string, dir string) {
+	module := conversionReportModule(m, moduleType, dir)
+	if aModule, ok := m.(android.Module); ok {
+		module.Reason = aModule.GetUnconvertedReason()
+	} else {
+		module.Reason = &android.UnconvertedReason{Type: android.UnconvertedReasonTypeUnsupported}
+	}
+	if module.Reason == nil {
+		module.Reason = &android.UnconvertedReason{Type: android.UnconvertedReasonNoTargets}
+	}
+	metrics.addConversionReportModule(module)
+}
+
+// ConversionReport returns the conversion report, with the modules sorted by directory and name.
+func (metrics *CodegenMetrics) ConversionReport() ConversionReport {
+	modules := metrics.conversionReport.Modules
+	sort.SliceStable(modules, func(i, j int) bool {
+		if modules[i].Dir != modules[j].Dir {
+			return modules[i].Dir < modules[j].Dir
+		}
+		return modules[i].Name < modules[j].Name
+	})
+	return metrics.conversionReport
+}
+
+// WriteConversionReport writes the conversion report as JSON into the given directory.
+func (metrics *CodegenMetrics) WriteConversionReport(dir string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fail(err, "Failed to `mkdir -p` %s", dir)
+	}
+	data, err := json.MarshalIndent(metrics.ConversionReport(), "", "  ")
+	if err != nil {
+		fail(err, "Error serializing the bp2build conversion report")
+	}
+	reportFile := filepath.Join(dir, bp2buildConversionReportFilename)
+	if err := os.WriteFile(reportFile, append(data, '\n'), 0644); err != nil {
+		fail(err, "Error outputting %s", reportFile)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"testing"
+
+	"android/soong/android"
+	"android/soong/android/allowlists"
+)
+
+func TestConversionReport(t *testing.T) {
+	fs := map[string][]byte{
+		"migrated/Android.bp": []byte(`
+filegroup { name: "a", srcs: ["a.txt"] }
+filegroup { name: "b", bazel_module: { bp2build_available: false } }
+filegroup { name: "c" }
+`),
+		"not_migrated/Android.bp": []byte(`filegroup { name: "d" }`),
+	}
+	config := android.TestConfig(buildDir, nil, "", fs)
+	ctx := android.NewTestContext(config)
+	ctx.RegisterModuleType("filegroup", android.FileGroupFactory)
+	allowlist := android.NewBp2BuildAllowlist().
+		SetDefaultConfig(allowlists.Bp2BuildConfig{
+			"migrated": allowlists.Bp2BuildDefaultTrueRecursively,
+		}).
+		SetModuleDoNotConvertList([]string{"c"})
+	ctx.RegisterBp2BuildConfig(allowlist)
+	ctx.RegisterForBazelConversion()
+
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp", "migrated/Android.bp", "not_migrated/Android.bp"})
+	android.FailIfErrored(t, errs)
+	_, errs = ctx.ResolveDependencies(config)
+	android.FailIfErrored(t, errs)
+
+	codegenCtx := NewCodegenContext(config, ctx.Context, Bp2Build, "")
+	res, errs := GenerateBazelTargets(codegenCtx, false)
+	android.FailIfErrored(t, errs)
+
+	android.AssertDeepEquals(t, "conversion report", ConversionReport{
+		Modules: []ConversionReportModule{
+			{
+				Name:      "a",
+				Type:      "filegroup",
+				Dir:       "migrated",
+				Converted: true,
+				Labels:    []string{"//migrated:a"},
+			},
+			{
+				Name:   "b",
+				Type:   "filegroup",
+				Dir:    "migrated",
+				Reason: &android.UnconvertedReason{Type: android.UnconvertedReasonOptedOut},
+			},
+			{
+				Name:   "c",
+				Type:   "filegroup",
+				Dir:    "migrated",
+				Reason: &android.UnconvertedReason{Type: android.UnconvertedReasonDenylisted},
+			},
+			{
+				Name:   "d",
+				Type:   "filegroup",
+				Dir:    "not_migrated",
+				Reason: &android.UnconvertedReason{Type: android.UnconvertedReasonNotAllowlisted},
+			},
+		},
+	}, res.metrics.ConversionReport())
+}
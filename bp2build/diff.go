@@ -0,0 +1,160 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/android"
+	"android/soong/shared"
+)
+
+// CodegenDiff generates the bp2build workspace into a temporary directory and writes a unified
+// diff of it against the workspace of the previous bp2build run to w, instead of overwriting the
+// previous workspace. It returns whether the workspaces differ.
+func CodegenDiff(ctx *CodegenContext, w io.Writer) (bool, error) {
+	res, errs := GenerateBazelTargets(ctx, true)
+	if len(errs) > 0 {
+		errMsgs := make([]string, len(errs))
+		for i, err := range errs {
+			errMsgs[i] = fmt.Sprintf("%q", err)
+		}
+		return false, fmt.Errorf("encountered %d error(s): \n%s", len(errs), strings.Join(errMsgs, "\n"))
+	}
+	bp2buildFiles := CreateBazelFiles(ctx.Config(), nil, res.buildFileToTargets, ctx.mode)
+
+	newDir, err := os.MkdirTemp("", "bp2build_diff")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(newDir)
+	for _, f := range bp2buildFiles {
+		dir := filepath.Join(newDir, f.Dir)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, f.Basename), []byte(f.Contents), 0644); err != nil {
+			return false, err
+		}
+	}
+
+	oldDir := shared.JoinPath(ctx.topDir, android.PathForOutput(ctx, "bp2build").String())
+	return diffWorkspaces(oldDir, newDir, w)
+}
+
+// diffWorkspaces writes a unified diff of every file that differs between the oldDir and newDir
+// trees to w, with the paths of the files relative to the trees. A file that is only in one of
+// the trees is diffed against /dev/null. It returns whether the trees differ.
+func diffWorkspaces(oldDir, newDir string, w io.Writer) (bool, error) {
+	oldFiles, err := workspaceFiles(oldDir)
+	if err != nil {
+		return false, err
+	}
+	newFiles, err := workspaceFiles(newDir)
+	if err != nil {
+		return false, err
+	}
+
+	var paths []string
+	for path := range oldFiles {
+		paths = append(paths, path)
+	}
+	for path := range newFiles {
+		if !oldFiles[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	differ := false
+	for _, path := range paths {
+		oldFile, newFile := os.DevNull, os.DevNull
+		if oldFiles[path] {
+			oldFile = filepath.Join(oldDir, path)
+		}
+		if newFiles[path] {
+			newFile = filepath.Join(newDir, path)
+		}
+		same, err := sameContents(oldFile, newFile)
+		if err != nil {
+			return false, err
+		}
+		if same {
+			continue
+		}
+		differ = true
+		if err := diffFile(w, oldFile, newFile, "a/"+path, "b/"+path); err != nil {
+			return false, err
+		}
+	}
+	return differ, nil
+}
+
+// workspaceFiles returns the paths of the files of a workspace, relative to it. A workspace that
+// doesn't exist has no files.
+func workspaceFiles(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = true
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return files, nil
+}
+
+func sameContents(a, b string) (bool, error) {
+	aContents, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	bContents, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aContents, bContents), nil
+}
+
+func diffFile(w io.Writer, oldFile, newFile, oldLabel, newLabel string) error {
+	cmd := exec.Command("diff", "-u", "--label", oldLabel, "--label", newLabel, oldFile, newFile)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	// diff exits with 1 when the files differ.
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"android/soong/android"
+)
+
+func writeWorkspace(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for path, contents := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiffWorkspaces(t *testing.T) {
+	oldDir := writeWorkspace(t, map[string]string{
+		"foo/BUILD.bazel":  "cc_library(\n    name = \"foo\",\n)\n",
+		"bar/BUILD.bazel":  "filegroup(\n    name = \"bar\",\n)\n",
+		"same/BUILD.bazel": "# same\n",
+	})
+	newDir := writeWorkspace(t, map[string]string{
+		"foo/BUILD.bazel":  "cc_library(\n    name = \"foo\",\n    srcs = [\"foo.c\"],\n)\n",
+		"baz/BUILD.bazel":  "filegroup(\n    name = \"baz\",\n)\n",
+		"same/BUILD.bazel": "# same\n",
+	})
+
+	var out strings.Builder
+	differ, err := diffWorkspaces(oldDir, newDir, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	android.AssertBoolEquals(t, "differ", true, differ)
+
+	diff := out.String()
+	android.AssertStringDoesContain(t, "changed file", diff, "--- a/foo/BUILD.bazel\n+++ b/foo/BUILD.bazel\n")
+	android.AssertStringDoesContain(t, "changed line", diff, "+    srcs = [\"foo.c\"],\n")
+	android.AssertStringDoesContain(t, "removed file", diff, "--- a/bar/BUILD.bazel\n+++ b/bar/BUILD.bazel\n")
+	android.AssertStringDoesContain(t, "removed line", diff, "-    name = \"bar\",\n")
+	android.AssertStringDoesContain(t, "added line", diff, "+    name = \"baz\",\n")
+	android.AssertStringDoesNotContain(t, "unchanged file", diff, "same/BUILD.bazel")
+
+	// The files are diffed in the order of their paths.
+	if strings.Index(diff, "bar/BUILD.bazel") > strings.Index(diff, "baz/BUILD.bazel") ||
+		strings.Index(diff, "baz/BUILD.bazel") > strings.Index(diff, "foo/BUILD.bazel") {
+		t.Errorf("expected the files to be diffed in order, got:\n%s", diff)
+	}
+}
+
+func TestDiffWorkspacesWithoutPreviousWorkspace(t *testing.T) {
+	newDir := writeWorkspace(t, map[string]string{
+		"foo/BUILD.bazel": "# foo\n",
+	})
+
+	var out strings.Builder
+	differ, err := diffWorkspaces(filepath.Join(t.TempDir(), "bp2build"), newDir, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	android.AssertBoolEquals(t, "differ", true, differ)
+	android.AssertStringDoesContain(t, "added file", out.String(), "+++ b/foo/BUILD.bazel\n+# foo\n")
+}
+
+func TestDiffWorkspacesUnchanged(t *testing.T) {
+	files := map[string]string{"foo/BUILD.bazel": "# foo\n"}
+
+	var out strings.Builder
+	differ, err := diffWorkspaces(writeWorkspace(t, files), writeWorkspace(t, files), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	android.AssertBoolEquals(t, "differ", false, differ)
+	android.AssertStringEquals(t, "diff", "", out.String())
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"android/soong/android"
+	"android/soong/bazel"
+)
+
+// The generated BUILD files are always deterministic: targets are sorted by name and the
+// //conditions:default arm of a select() is always last. The formatting policy additionally
+// controls the order of the attributes of a target, of the labels of label lists and of the arms
+// of a select(), so that checked-in generated BUILD files produce minimal diffs when they are
+// regenerated.
+//
+// The policy is set with BP2BUILD_FORMAT_POLICY, a comma-separated list of options:
+//   attribute_order=alphabetical|buildifier
+//     alphabetical (the default) sorts the attributes by name. buildifier uses the order of
+//     buildifier, e.g. name first and deps after srcs, so that running buildifier on a checked-in
+//     file doesn't reorder it.
+//   label_lists=preserved|sorted
+//     preserved (the default) keeps the labels in the order of the Android.bp file. sorted sorts
+//     the labels of every label list and of every arm of its selects, so that reordering a list
+//     in Android.bp doesn't change the BUILD file.
+//   select_arms=lexicographic|natural
+//     lexicographic (the default) sorts the arms of a select() by their condition. natural sorts
+//     the numbers in the conditions by value, e.g. api levels.
+
+type attributeOrder int
+
+const (
+	alphabeticalAttributeOrder attributeOrder = iota
+	buildifierAttributeOrder
+)
+
+type selectArmOrder int
+
+const (
+	lexicographicSelectArmOrder selectArmOrder = iota
+	naturalSelectArmOrder
+)
+
+type formattingPolicy struct {
+	attributeOrder attributeOrder
+	sortLabelLists bool
+	selectArmOrder selectArmOrder
+}
+
+var defaultFormattingPolicy = formattingPolicy{}
+
+// parseFormattingPolicy parses the value of BP2BUILD_FORMAT_POLICY.
+func parseFormattingPolicy(s string) (formattingPolicy, error) {
+	policy := defaultFormattingPolicy
+	for _, option := range strings.Split(s, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(option, "=")
+		switch {
+		case key == "attribute_order" && value == "alphabetical":
+			policy.attributeOrder = alphabeticalAttributeOrder
+		case key == "attribute_order" && value == "buildifier":
+			policy.attributeOrder = buildifierAttributeOrder
+		case key == "label_lists" && value == "preserved":
+			policy.sortLabelLists = false
+		case key == "label_lists" && value == "sorted":
+			policy.sortLabelLists = true
+		case key == "select_arms" && value == "lexicographic":
+			policy.selectArmOrder = lexicographicSelectArmOrder
+		case key == "select_arms" && value == "natural":
+			policy.selectArmOrder = naturalSelectArmOrder
+		default:
+			return defaultFormattingPolicy, fmt.Errorf("invalid BP2BUILD_FORMAT_POLICY option %q", option)
+		}
+	}
+	return policy, nil
+}
+
+// buildifierAttributePriority is the order of the attributes in buildifier, see NamePriority in
+// buildifier's tables.go. The other attributes sort at 0, by name.
+var buildifierAttributePriority = map[string]int{
+	"name":              -99,
+	"gwt_name":          -98,
+	"package_name":      -97,
+	"visible_node_name": -96,
+	"size":              -95,
+	"timeout":           -94,
+	"testonly":          -93,
+	"src":               -92,
+	"srcdir":            -91,
+	"srcs":              -90,
+	"out":               -89,
+	"outs":              -88,
+	"hdrs":              -87,
+	"has_services":      -86,
+	"include":           -85,
+	"of":                -84,
+	"baseline":          -83,
+	"destdir":           1,
+	"exports":           2,
+	"runtime_deps":      3,
+	"deps":              4,
+	"implementation":    5,
+	"implements":        6,
+	"alwayslink":        7,
+}
+
+// sortedAttributeNames returns the names of the attributes of a target in the order they are
+// written in.
+func (p formattingPolicy) sortedAttributeNames(attrs map[string]string) []string {
+	names := android.SortedKeys(attrs)
+	if p.attributeOrder == buildifierAttributeOrder {
+		sort.SliceStable(names, func(i, j int) bool {
+			return buildifierAttributePriority[names[i]] < buildifierAttributePriority[names[j]]
+		})
+	}
+	return names
+}
+
+// sortedSelectKeys returns the conditions of the arms of a select() in the order they are written
+// in. The //conditions:default arm is written last regardless.
+func (p formattingPolicy) sortedSelectKeys(selectMap map[string]reflect.Value) []string {
+	keys := android.SortedKeys(selectMap)
+	if p.selectArmOrder == naturalSelectArmOrder {
+		sort.SliceStable(keys, func(i, j int) bool {
+			return naturalLess(keys[i], keys[j])
+		})
+	}
+	return keys
+}
+
+// naturalLess compares two strings with the runs of digits compared by their numeric value, so
+// that "api_9" sorts before "api_10".
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		aDigits, bDigits := leadingDigits(a), leadingDigits(b)
+		if aDigits != "" && bDigits != "" {
+			aNum, bNum := strings.TrimLeft(aDigits, "0"), strings.TrimLeft(bDigits, "0")
+			if len(aNum) != len(bNum) {
+				return len(aNum) < len(bNum)
+			}
+			if aNum != bNum {
+				return aNum < bNum
+			}
+			a, b = a[len(aDigits):], b[len(bDigits):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// sortLabelListValues sorts the labels of the base value and of every arm of the selects of a
+// label list attribute, see getLabelListValues.
+func sortLabelListValues(value reflect.Value, configurableAttrs []selects) (reflect.Value, []selects) {
+	sortedConfigurableAttrs := make([]selects, 0, len(configurableAttrs))
+	for _, s := range configurableAttrs {
+		sorted := make(selects, len(s))
+		for key, v := range s {
+			sorted[key] = sortedLabels(v)
+		}
+		sortedConfigurableAttrs = append(sortedConfigurableAttrs, sorted)
+	}
+	return sortedLabels(value), sortedConfigurableAttrs
+}
+
+// sortedLabels returns a sorted copy of a []bazel.Label value.
+func sortedLabels(value reflect.Value) reflect.Value {
+	if !value.IsValid() {
+		return value
+	}
+	labels, ok := value.Interface().([]bazel.Label)
+	if !ok || labels == nil {
+		return value
+	}
+	sorted := append([]bazel.Label(nil), labels...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Label < sorted[j].Label
+	})
+	return reflect.ValueOf(sorted)
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"android/soong/bazel"
+	"android/soong/genrule"
+)
+
+func TestParseFormattingPolicy(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected formattingPolicy
+		err      bool
+	}{
+		{
+			value:    "",
+			expected: defaultFormattingPolicy,
+		},
+		{
+			value: "attribute_order=buildifier, label_lists=sorted,select_arms=natural",
+			expected: formattingPolicy{
+				attributeOrder: buildifierAttributeOrder,
+				sortLabelLists: true,
+				selectArmOrder: naturalSelectArmOrder,
+			},
+		},
+		{
+			value:    "label_lists=sorted,label_lists=preserved",
+			expected: defaultFormattingPolicy,
+		},
+		{
+			value: "label_lists=shuffled",
+			err:   true,
+		},
+		{
+			value: "sorted",
+			err:   true,
+		},
+	}
+	for _, tc := range testCases {
+		policy, err := parseFormattingPolicy(tc.value)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", tc.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tc.value, err)
+		} else if policy != tc.expected {
+			t.Errorf("%q: expected %+v, got %+v", tc.value, tc.expected, policy)
+		}
+	}
+}
+
+func TestSortedSelectKeys(t *testing.T) {
+	selectMap := map[string]reflect.Value{
+		"//api:10":                          {},
+		"//api:9":                           {},
+		"//api:current":                     {},
+		bazel.ConditionsDefaultSelectKey:    {},
+		"//build/bazel/platforms/arch:x86":  {},
+		"//build/bazel/platforms/arch:arm6": {},
+	}
+
+	lexicographic := []string{
+		"//api:10",
+		"//api:9",
+		"//api:current",
+		"//build/bazel/platforms/arch:arm6",
+		"//build/bazel/platforms/arch:x86",
+		bazel.ConditionsDefaultSelectKey,
+	}
+	if got := defaultFormattingPolicy.sortedSelectKeys(selectMap); !reflect.DeepEqual(got, lexicographic) {
+		t.Errorf("expected %q, got %q", lexicographic, got)
+	}
+
+	natural := []string{
+		"//api:9",
+		"//api:10",
+		"//api:current",
+		"//build/bazel/platforms/arch:arm6",
+		"//build/bazel/platforms/arch:x86",
+		bazel.ConditionsDefaultSelectKey,
+	}
+	policy := formattingPolicy{selectArmOrder: naturalSelectArmOrder}
+	if got := policy.sortedSelectKeys(selectMap); !reflect.DeepEqual(got, natural) {
+		t.Errorf("expected %q, got %q", natural, got)
+	}
+}
+
+func TestSortLabelLists(t *testing.T) {
+	makeAttr := func(base, arm []string) bazel.LabelListAttribute {
+		attr := bazel.MakeLabelListAttribute(bazel.MakeLabelList(labelsForTest(base)))
+		attr.SetSelectValue(bazel.ArchConfigurationAxis, "arm", bazel.MakeLabelList(labelsForTest(arm)))
+		return attr
+	}
+
+	expected, err := prettyPrintAttribute(makeAttr([]string{"a", "b", "c"}, []string{"d", "e"}), 0, defaultFormattingPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := formattingPolicy{sortLabelLists: true}
+	got, err := prettyPrintAttribute(makeAttr([]string{"c", "a", "b"}, []string{"e", "d"}), 0, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expected {
+		t.Errorf("expected the labels to be sorted:\n%s\ngot:\n%s", expected, got)
+	}
+
+	got, err = prettyPrintAttribute(makeAttr([]string{"c", "a", "b"}, []string{"e", "d"}), 0, defaultFormattingPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == expected {
+		t.Errorf("expected the order of the labels to be preserved, got:\n%s", got)
+	}
+}
+
+func labelsForTest(labels []string) []bazel.Label {
+	ret := make([]bazel.Label, 0, len(labels))
+	for _, l := range labels {
+		ret = append(ret, bazel.Label{Label: l})
+	}
+	return ret
+}
+
+func TestFormattingPolicyBuildifierAttributeOrder(t *testing.T) {
+	RunBp2BuildTestCase(t, registerGenruleModuleTypes, Bp2buildTestCase{
+		Description:                "buildifier attribute order",
+		ModuleTypeUnderTest:        "genrule",
+		ModuleTypeUnderTestFactory: genrule.GenRuleFactory,
+		FormattingPolicy:           "attribute_order=buildifier",
+		Blueprint: `genrule {
+    name: "foo",
+    out: ["foo.out"],
+    srcs: ["foo.in"],
+    tools: [":foo.tool"],
+    cmd: "$(location :foo.tool) $(in) $(out)",
+    bazel_module: { bp2build_available: true },
+}
+
+genrule {
+    name: "foo.tool",
+    out: ["foo_tool.out"],
+    cmd: "touch $(out)",
+    bazel_module: { bp2build_available: false },
+}`,
+		ExpectedBazelTargets: []string{`genrule(
+    name = "foo",
+    srcs = ["foo.in"],
+    outs = ["foo.out"],
+    cmd = "$(location :foo.tool) $(SRCS) $(OUTS)",
+    tools = [":foo.tool"],
+)`},
+	})
+}
+
+// TestFormattingPolicyStability checks that reordering a list in an Android.bp file doesn't change
+// the generated BUILD file when the label lists are sorted.
+func TestFormattingPolicyStability(t *testing.T) {
+	bp := `genrule {
+    name: "foo",
+    out: ["foo.out"],
+    srcs: [%s],
+    cmd: "cat $(in) > $(out)",
+    bazel_module: { bp2build_available: true },
+}`
+	expected := MakeBazelTargetNoRestrictions("genrule", "foo", AttrNameToString{
+		"cmd":  `"cat $(SRCS) > $(OUTS)"`,
+		"outs": `["foo.out"]`,
+		"srcs": `[
+        "a.in",
+        "b.in",
+        "c.in",
+    ]`,
+	})
+
+	for _, srcs := range []string{
+		`"a.in", "b.in", "c.in"`,
+		`"c.in", "a.in", "b.in"`,
+		`"b.in", "c.in", "a.in"`,
+	} {
+		RunBp2BuildTestCase(t, registerGenruleModuleTypes, Bp2buildTestCase{
+			Description:                "srcs: " + srcs,
+			ModuleTypeUnderTest:        "genrule",
+			ModuleTypeUnderTestFactory: genrule.GenRuleFactory,
+			FormattingPolicy:           "label_lists=sorted",
+			Blueprint:                  fmt.Sprintf(bp, srcs),
+			ExpectedBazelTargets:       []string{expected},
+		})
+	}
+}
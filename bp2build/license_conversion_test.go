@@ -79,3 +79,44 @@ license {
 			})
 	}
 }
+
+func TestLicenseWithMultipleLicenseTextsBp2Build(t *testing.T) {
+	RunBp2BuildTestCase(t,
+		registerLicenseModuleTypes,
+		Bp2buildTestCase{
+			Description:                "license texts are concatenated",
+			ModuleTypeUnderTest:        "license",
+			ModuleTypeUnderTestFactory: android.LicenseFactory,
+			Blueprint: `
+license {
+    name: "my_license",
+    license_kinds: [ "SPDX-license-identifier-Apache-2.0"],
+    license_text: [ "NOTICE", "NOTICE.extra"],
+}`,
+			ExpectedBazelTargets: []string{
+				ExpectedRuleTarget{
+					"android_license",
+					"my_license",
+					AttrNameToString{
+						"license_kinds": `["SPDX-license-identifier-Apache-2.0"]`,
+						"license_text":  `":my_license_license_text"`,
+					},
+					android.HostAndDeviceDefault,
+				}.String(),
+				ExpectedRuleTarget{
+					"genrule",
+					"my_license_license_text",
+					AttrNameToString{
+						"applicable_licenses": `[]`,
+						"cmd":                 `"cat $(SRCS) > $(OUTS)"`,
+						"outs":                `["my_license_license_text.txt"]`,
+						"srcs": `[
+        "NOTICE",
+        "NOTICE.extra",
+    ]`,
+					},
+					android.HostAndDeviceDefault,
+				}.String(),
+			},
+		})
+}
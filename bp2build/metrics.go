@@ -30,6 +30,10 @@ type CodegenMetrics struct {
 	// Map of converted modules and paths to call
 	// NOTE: NOT in the .proto
 	convertedModulePathMap map[string]string
+
+	// The conversion report of every module
+	// NOTE: NOT in the .proto
+	conversionReport ConversionReport
 }
 
 func CreateCodegenMetrics() CodegenMetrics {
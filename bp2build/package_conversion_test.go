@@ -23,6 +23,7 @@ import (
 
 func registerDependentModules(ctx android.RegistrationContext) {
 	ctx.RegisterModuleType("license", android.LicenseFactory)
+	ctx.RegisterModuleType("license_kind", android.LicenseKindFactory)
 	ctx.RegisterModuleType("genrule", genrule.GenRuleFactory)
 }
 
@@ -137,3 +138,73 @@ package {
 			})
 	}
 }
+
+func TestPackageLicenses(t *testing.T) {
+	RunBp2BuildTestCase(t, registerDependentModules, Bp2buildTestCase{
+		Description:                "licenses from the conditions of the license kinds",
+		ModuleTypeUnderTest:        "package",
+		ModuleTypeUnderTestFactory: android.PackageFactory,
+		Blueprint: `
+license_kind {
+  name: "my_notice",
+  conditions: ["notice"],
+}
+
+license_kind {
+  name: "my_restricted",
+  conditions: ["restricted_if_statically_linked"],
+}
+
+license {
+  name: "my_license",
+  license_kinds: ["my_notice", "my_restricted"],
+}
+
+package {
+  default_applicable_licenses: ["my_license"],
+}
+`,
+		ExpectedBazelTargets: []string{
+			ExpectedRuleTarget{
+				"package",
+				"",
+				AttrNameToString{
+					"default_package_metadata": `[":my_license"]`,
+					"default_visibility":       `["//visibility:public"]`,
+				},
+				android.HostAndDeviceDefault,
+			}.String(),
+			`licenses([
+        "notice",
+        "restricted",
+    ])`,
+			ExpectedRuleTarget{
+				"android_license",
+				"my_license",
+				AttrNameToString{
+					"license_kinds": `[
+        "my_notice",
+        "my_restricted",
+    ]`,
+				},
+				android.HostAndDeviceDefault,
+			}.String(),
+			ExpectedRuleTarget{
+				"license_kind",
+				"my_notice",
+				AttrNameToString{
+					"conditions": `["notice"]`,
+				},
+				android.HostAndDeviceDefault,
+			}.String(),
+			ExpectedRuleTarget{
+				"license_kind",
+				"my_restricted",
+				AttrNameToString{
+					"conditions": `["restricted_if_statically_linked"]`,
+				},
+				android.HostAndDeviceDefault,
+			}.String(),
+		},
+	})
+}
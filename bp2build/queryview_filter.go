@@ -0,0 +1,129 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"fmt"
+	"strings"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint"
+)
+
+// QueryViewFilter selects the variants of the modules that are materialized in the queryview, so
+// that a queryview for day-to-day dependency queries only contains the variants of the active
+// product. Variants that are not tied to an OS or an architecture, like the common variants of
+// Java modules and modules that are not android.Modules, are always kept.
+//
+// The filter is a comma-separated list of terms:
+//
+//	product
+//	  keep only the variants for the device and the build host of the product, with the
+//	  architectures of the product.
+//	arch=<arch>
+//	  keep only the variants for <arch>, e.g. arm64. The term can be repeated to keep several
+//	  architectures.
+type QueryViewFilter struct {
+	product bool
+	arches  map[android.ArchType]bool
+}
+
+// ParseQueryViewFilter parses the value of --bazel_queryview_filter. An empty value returns a nil
+// filter, which keeps all the variants.
+func ParseQueryViewFilter(s string) (*QueryViewFilter, error) {
+	var filter *QueryViewFilter
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		if filter == nil {
+			filter = &QueryViewFilter{}
+		}
+		key, value, hasValue := strings.Cut(term, "=")
+		switch {
+		case key == "product" && !hasValue:
+			filter.product = true
+		case key == "arch" && hasValue:
+			archType, ok := archTypeByName(value)
+			if !ok {
+				return nil, fmt.Errorf("invalid queryview filter %q: unknown arch %q", term, value)
+			}
+			if filter.arches == nil {
+				filter.arches = make(map[android.ArchType]bool)
+			}
+			filter.arches[archType] = true
+		default:
+			return nil, fmt.Errorf("invalid queryview filter %q", term)
+		}
+	}
+	return filter, nil
+}
+
+func archTypeByName(name string) (android.ArchType, bool) {
+	for _, archType := range android.ArchTypeList() {
+		if archType.Name == name {
+			return archType, true
+		}
+	}
+	return android.ArchType{}, false
+}
+
+// SetQueryViewFilter sets the filter of the variants that are materialized in QueryView mode.
+func (ctx *CodegenContext) SetQueryViewFilter(filter *QueryViewFilter) {
+	ctx.queryViewFilter = filter
+}
+
+// matches returns whether a module variant is kept by the filter.
+func (f *QueryViewFilter) matches(config android.Config, m blueprint.Module) bool {
+	if f == nil {
+		return true
+	}
+	if aModule, ok := m.(android.Module); ok {
+		return f.matchesTarget(config, aModule.Target())
+	}
+	return true
+}
+
+func (f *QueryViewFilter) matchesTarget(config android.Config, target android.Target) bool {
+	if target.Os == android.NoOsType || target.Os == android.CommonOS {
+		return true
+	}
+	if f.product && target.Os != android.Android && target.Os != config.BuildOS {
+		return false
+	}
+
+	archType := target.Arch.ArchType
+	if archType == android.Common || archType.Name == "" {
+		return true
+	}
+	if f.product {
+		found := false
+		for _, productTarget := range config.Targets[target.Os] {
+			if productTarget.Arch.ArchType == archType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.arches != nil && !f.arches[archType] {
+		return false
+	}
+	return true
+}
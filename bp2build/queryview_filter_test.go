@@ -0,0 +1,114 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"reflect"
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestParseQueryViewFilter(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected *QueryViewFilter
+		err      bool
+	}{
+		{
+			value:    "",
+			expected: nil,
+		},
+		{
+			value:    "product",
+			expected: &QueryViewFilter{product: true},
+		},
+		{
+			value: "product, arch=arm64,arch=x86_64",
+			expected: &QueryViewFilter{
+				product: true,
+				arches:  map[android.ArchType]bool{android.Arm64: true, android.X86_64: true},
+			},
+		},
+		{
+			value: "arch=mips",
+			err:   true,
+		},
+		{
+			value: "product=true",
+			err:   true,
+		},
+		{
+			value: "device",
+			err:   true,
+		},
+	}
+	for _, tc := range testCases {
+		filter, err := ParseQueryViewFilter(tc.value)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", tc.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tc.value, err)
+		} else if !reflect.DeepEqual(filter, tc.expected) {
+			t.Errorf("%q: expected %+v, got %+v", tc.value, tc.expected, filter)
+		}
+	}
+}
+
+func TestQueryViewFilterMatchesTarget(t *testing.T) {
+	config := android.TestArchConfig(buildDir, nil, "", nil)
+
+	androidArm64 := android.Target{Os: android.Android, Arch: android.Arch{ArchType: android.Arm64}}
+	androidX86 := android.Target{Os: android.Android, Arch: android.Arch{ArchType: android.X86}}
+	androidCommon := android.Target{Os: android.Android, Arch: android.Arch{ArchType: android.Common}}
+	hostX86_64 := android.Target{Os: config.BuildOS, Arch: android.Arch{ArchType: android.X86_64}}
+	windowsX86 := android.Target{Os: android.Windows, Arch: android.Arch{ArchType: android.X86}}
+	windowsCommon := android.Target{Os: android.Windows, Arch: android.Arch{ArchType: android.Common}}
+	commonOS := android.Target{Os: android.CommonOS}
+
+	testCases := []struct {
+		filter   string
+		target   android.Target
+		expected bool
+	}{
+		{"product", androidArm64, true},
+		{"product", androidX86, false},
+		{"product", androidCommon, true},
+		{"product", hostX86_64, true},
+		{"product", windowsX86, false},
+		{"product", windowsCommon, false},
+		{"product", commonOS, true},
+		{"arch=arm64", androidArm64, true},
+		{"arch=arm64", androidX86, false},
+		{"arch=arm64", hostX86_64, false},
+		{"arch=arm64", androidCommon, true},
+		{"arch=x86", windowsX86, true},
+		{"product,arch=x86", windowsX86, false},
+		{"arch=arm64,arch=x86_64", hostX86_64, true},
+	}
+	for _, tc := range testCases {
+		filter, err := ParseQueryViewFilter(tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filter.matchesTarget(config, tc.target); got != tc.expected {
+			t.Errorf("%q: expected %t for %s, got %t", tc.filter, tc.expected, tc.target, got)
+		}
+	}
+}
@@ -15,11 +15,14 @@
 package bp2build
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -45,6 +48,66 @@ import (
 // on machines that may still have the bug present in their forest.
 const symlinkForestVersion = 1
 
+// ForestMode is how the files of the source tree and the build files tree are placed in the
+// workspace.
+type ForestMode int
+
+const (
+	// SymlinkForest symlinks the largest directories of the source and build files trees that
+	// don't need to be merged into the workspace. This is the default.
+	SymlinkForest ForestMode = iota
+
+	// HardlinkForest mirrors the directories of the trees and hardlinks every file into the
+	// workspace, for filesystems where dense symlinks are slow or unsupported. Files that can't be
+	// hardlinked, e.g. because $OUT_DIR is on another filesystem, are copied.
+	HardlinkForest
+
+	// CopyForest mirrors the directories of the trees and copies every file into the workspace.
+	// The files are recorded in a manifest, so that only the files that changed since the
+	// previous run are copied again.
+	CopyForest
+)
+
+// forestManifestFile is the name of the file at the root of the workspace that records the source
+// of every file in the workspace in the hardlink and copy modes.
+const forestManifestFile = "workspace_manifest.json"
+
+func (m ForestMode) String() string {
+	switch m {
+	case SymlinkForest:
+		return "symlink"
+	case HardlinkForest:
+		return "hardlink"
+	case CopyForest:
+		return "copy"
+	default:
+		panic(fmt.Errorf("unknown forest mode %d", int(m)))
+	}
+}
+
+// ParseForestMode returns the ForestMode with the given name, as set in
+// BP2BUILD_WORKSPACE_MODE. An empty name is the default symlink mode.
+func ParseForestMode(name string) (ForestMode, error) {
+	switch name {
+	case "", "symlink":
+		return SymlinkForest, nil
+	case "hardlink":
+		return HardlinkForest, nil
+	case "copy":
+		return CopyForest, nil
+	default:
+		return SymlinkForest, fmt.Errorf("unknown workspace mode %q, expected symlink, hardlink or copy", name)
+	}
+}
+
+// forestManifestEntry is the source of a file in the workspace. A copied file is up to date if
+// its source still has the recorded size and modification time.
+type forestManifestEntry struct {
+	Src     string `json:"src"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+}
+
 type instructionsNode struct {
 	name     string
 	excluded bool // If false, this is just an intermediate node
@@ -53,13 +116,22 @@ type instructionsNode struct {
 
 type symlinkForestContext struct {
 	verbose bool
+	mode    ForestMode
 	topdir  string // $TOPDIR
+	forest  string
+
+	// The manifest of the previous run, in the hardlink and copy modes.
+	prevManifest map[string]forestManifestEntry
 
 	// State
 	wg           sync.WaitGroup
+	jobs         chan struct{} // Limits the number of directories that are planted concurrently
 	depCh        chan string
 	mkdirCount   atomic.Uint64
 	symlinkCount atomic.Uint64
+
+	manifestLock sync.Mutex
+	manifest     map[string]forestManifestEntry
 }
 
 // Ensures that the node for the given path exists in the tree and returns it.
@@ -201,15 +273,17 @@ func symlinkIntoForest(topdir, dst, src string) uint64 {
 		}
 	} else {
 		if dstInfo.Mode()&os.ModeSymlink != 0 {
-			// Assume that the link's target is correct, i.e. no manual tampering.
-			// E.g. OUT_DIR could have been previously used with a different source tree check-out!
-			return 0
-		} else {
-			if err := os.RemoveAll(dstPath); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to remove '%s': %s", dst, err)
-				os.Exit(1)
+			// The link is up to date unless its target moved, e.g. OUT_DIR could have been
+			// previously used with a different source tree check-out, or the source tree could be
+			// mounted at a different path.
+			if target, err := os.Readlink(dstPath); err == nil && target == srcPath {
+				return 0
 			}
 		}
+		if err := os.RemoveAll(dstPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove '%s': %s", dst, err)
+			os.Exit(1)
+		}
 	}
 
 	// Create symlink.
@@ -220,6 +294,183 @@ func symlinkIntoForest(topdir, dst, src string) uint64 {
 	return 1
 }
 
+// Places the file or directory at src into the forest at dst: as a symlink in the symlink mode, and
+// as a directory tree of hardlinked or copied files otherwise. fromSrcDir is true if src is in the
+// source tree as opposed to the build files tree.
+func placeIntoForest(context *symlinkForestContext, dst, src string, fromSrcDir bool) {
+	if context.mode == SymlinkForest {
+		context.symlinkCount.Add(symlinkIntoForest(context.topdir, dst, src))
+		return
+	}
+
+	fi, err := os.Lstat(shared.JoinPath(context.topdir, src))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to lstat '%s': %s", src, err)
+		os.Exit(1)
+	}
+	if isDir(shared.JoinPath(context.topdir, src), fi) {
+		context.wg.Add(1)
+		go plantTreeRecursive(context, dst, src, fromSrcDir)
+	} else {
+		if fromSrcDir {
+			// The contents of the file are in the workspace, so it has to be recreated when it
+			// changes.
+			context.depCh <- src
+		}
+		context.symlinkCount.Add(linkOrCopyIntoForest(context, dst, src, fi))
+	}
+}
+
+// Recursively mirrors the directory tree at src into the forest at forestDir, hardlinking or
+// copying every file.
+func plantTreeRecursive(context *symlinkForestContext, forestDir, src string, fromSrcDir bool) {
+	defer context.wg.Done()
+	context.acquireJob()
+	defer context.releaseJob()
+
+	if fromSrcDir {
+		context.depCh <- src
+	}
+	srcMap := readdirToMap(shared.JoinPath(context.topdir, src))
+	ensureForestDir(context, forestDir)
+	forestMapForDeletion := readdirToMap(shared.JoinPath(context.topdir, forestDir))
+
+	for f := range srcMap {
+		if f[0] == '.' {
+			continue // Ignore dotfiles
+		}
+		delete(forestMapForDeletion, f)
+		placeIntoForest(context, shared.JoinPath(forestDir, f), shared.JoinPath(src, f), fromSrcDir)
+	}
+
+	for f := range forestMapForDeletion {
+		if err := os.RemoveAll(shared.JoinPath(context.topdir, forestDir, f)); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove '%s/%s': %s", forestDir, f, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// Creates a hardlink at dst to the file at src in the hardlink mode, and a copy of it in the copy
+// mode or if the hardlink can't be created. Returns 1 if dst was (re)created, 0 if it was already
+// up to date.
+func linkOrCopyIntoForest(context *symlinkForestContext, dst, src string, srcLinfo os.FileInfo) uint64 {
+	srcPath := shared.JoinPath(context.topdir, src)
+	dstPath := shared.JoinPath(context.topdir, dst)
+
+	srcInfo, err := os.Stat(srcPath)
+	if err != nil {
+		// A dangling symlink, there is nothing to link or copy.
+		if context.verbose {
+			fmt.Fprintf(os.Stderr, "Skipping dangling symlink '%s'\n", src)
+		}
+		return 0
+	}
+	entry := forestManifestEntry{
+		Src:     src,
+		Size:    srcInfo.Size(),
+		ModTime: srcInfo.ModTime().UnixNano(),
+	}
+	manifestKey, err := filepath.Rel(context.forest, dst)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "'%s' is not in the workspace '%s': %s", dst, context.forest, err)
+		os.Exit(1)
+	}
+	defer func() {
+		context.manifestLock.Lock()
+		defer context.manifestLock.Unlock()
+		context.manifest[manifestKey] = entry
+	}()
+
+	if dstInfo, err := os.Lstat(dstPath); err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Failed to lstat '%s': %s", dst, err)
+			os.Exit(1)
+		}
+	} else {
+		if dstInfo.Mode().IsRegular() {
+			if os.SameFile(dstInfo, srcInfo) {
+				return 0
+			}
+			if prev, ok := context.prevManifest[manifestKey]; ok && prev == entry && dstInfo.Size() == entry.Size {
+				return 0
+			}
+		}
+		if err := os.RemoveAll(dstPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove '%s': %s", dst, err)
+			os.Exit(1)
+		}
+	}
+
+	if context.mode == HardlinkForest {
+		target := srcPath
+		if srcLinfo.Mode()&os.ModeSymlink != 0 {
+			// link() doesn't follow symlinks, link the file the symlink points to instead.
+			if target, err = filepath.EvalSymlinks(srcPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to resolve '%s': %s", src, err)
+				os.Exit(1)
+			}
+		}
+		err := os.Link(target, dstPath)
+		if err == nil {
+			return 1
+		}
+		if context.verbose {
+			fmt.Fprintf(os.Stderr, "Cannot create hardlink at '%s' pointing to '%s', copying instead: %s\n", dst, src, err)
+		}
+	}
+
+	if err := copyFileIntoForest(dstPath, srcPath, srcInfo.Mode().Perm()); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot copy '%s' to '%s': %s", src, dst, err)
+		os.Exit(1)
+	}
+	return 1
+}
+
+func copyFileIntoForest(dst, src string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Creates the directory forestDir in the forest, replacing a file or a symlink that was there.
+func ensureForestDir(context *symlinkForestContext, forestDir string) {
+	fullForestPath := shared.JoinPath(context.topdir, forestDir)
+	createForestDir := false
+	if fi, err := os.Lstat(fullForestPath); err != nil {
+		if os.IsNotExist(err) {
+			createForestDir = true
+		} else {
+			fmt.Fprintf(os.Stderr, "Could not read info for '%s': %s\n", forestDir, err)
+		}
+	} else if fi.Mode()&os.ModeDir == 0 {
+		if err := os.RemoveAll(fullForestPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove '%s': %s", forestDir, err)
+			os.Exit(1)
+		}
+		createForestDir = true
+	}
+	if createForestDir {
+		if err := os.MkdirAll(fullForestPath, 0777); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not mkdir '%s': %s\n", forestDir, err)
+			os.Exit(1)
+		}
+		context.mkdirCount.Add(1)
+	}
+}
+
 func isDir(path string, fi os.FileInfo) bool {
 	if (fi.Mode() & os.ModeSymlink) != os.ModeSymlink {
 		return fi.IsDir()
@@ -240,16 +491,27 @@ func isDir(path string, fi os.FileInfo) bool {
 	return false
 }
 
+// symlinkForestVersionString returns the contents of the symlink_forest_version file. The mode
+// is part of it, so that the forest is recreated when switching between modes.
+func symlinkForestVersionString(mode ForestMode) string {
+	version := strconv.Itoa(symlinkForestVersion)
+	if mode != SymlinkForest {
+		version += " " + mode.String()
+	}
+	return version
+}
+
 // maybeCleanSymlinkForest will remove the whole symlink forest directory if the version recorded
-// in the symlink_forest_version file is not equal to symlinkForestVersion.
-func maybeCleanSymlinkForest(topdir, forest string, verbose bool) error {
+// in the symlink_forest_version file is not equal to symlinkForestVersion, or if the forest was
+// planted in another mode.
+func maybeCleanSymlinkForest(topdir, forest string, mode ForestMode, verbose bool) error {
 	versionFilePath := shared.JoinPath(topdir, forest, "symlink_forest_version")
 	versionFileContents, err := os.ReadFile(versionFilePath)
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
 	versionFileString := strings.TrimSpace(string(versionFileContents))
-	symlinkForestVersionString := strconv.Itoa(symlinkForestVersion)
+	symlinkForestVersionString := symlinkForestVersionString(mode)
 	if err != nil || versionFileString != symlinkForestVersionString {
 		if verbose {
 			fmt.Fprintf(os.Stderr, "Old symlink_forest_version was %q, current is %q. Cleaning symlink forest before recreating...\n", versionFileString, symlinkForestVersionString)
@@ -265,14 +527,14 @@ func maybeCleanSymlinkForest(topdir, forest string, verbose bool) error {
 // maybeWriteVersionFile will write the symlink_forest_version file containing symlinkForestVersion
 // if it doesn't exist already. If it exists we know it must contain symlinkForestVersion because
 // we checked for that already in maybeCleanSymlinkForest
-func maybeWriteVersionFile(topdir, forest string) error {
+func maybeWriteVersionFile(topdir, forest string, mode ForestMode) error {
 	versionFilePath := shared.JoinPath(topdir, forest, "symlink_forest_version")
 	_, err := os.Stat(versionFilePath)
 	if err != nil {
 		if !os.IsNotExist(err) {
 			return err
 		}
-		err = os.WriteFile(versionFilePath, []byte(strconv.Itoa(symlinkForestVersion)+"\n"), 0666)
+		err = os.WriteFile(versionFilePath, []byte(symlinkForestVersionString(mode)+"\n"), 0666)
 		if err != nil {
 			return err
 		}
@@ -286,6 +548,8 @@ func maybeWriteVersionFile(topdir, forest string) error {
 // srcDir .
 func plantSymlinkForestRecursive(context *symlinkForestContext, instructions *instructionsNode, forestDir string, buildFilesDir string, srcDir string) {
 	defer context.wg.Done()
+	context.acquireJob()
+	defer context.releaseJob()
 
 	if instructions != nil && instructions.excluded {
 		// Excluded paths are skipped at the level of the non-excluded parent.
@@ -330,28 +594,7 @@ func plantSymlinkForestRecursive(context *symlinkForestContext, instructions *in
 	// Tests read the error messages generated, so ensure their order is deterministic
 	sort.Strings(allEntries)
 
-	fullForestPath := shared.JoinPath(context.topdir, forestDir)
-	createForestDir := false
-	if fi, err := os.Lstat(fullForestPath); err != nil {
-		if os.IsNotExist(err) {
-			createForestDir = true
-		} else {
-			fmt.Fprintf(os.Stderr, "Could not read info for '%s': %s\n", forestDir, err)
-		}
-	} else if fi.Mode()&os.ModeDir == 0 {
-		if err := os.RemoveAll(fullForestPath); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to remove '%s': %s", forestDir, err)
-			os.Exit(1)
-		}
-		createForestDir = true
-	}
-	if createForestDir {
-		if err := os.MkdirAll(fullForestPath, 0777); err != nil {
-			fmt.Fprintf(os.Stderr, "Could not mkdir '%s': %s\n", forestDir, err)
-			os.Exit(1)
-		}
-		context.mkdirCount.Add(1)
-	}
+	ensureForestDir(context, forestDir)
 
 	// Start with a list of items that already exist in the forest, and remove
 	// each element as it is processed in allEntries. Any remaining items in
@@ -385,7 +628,7 @@ func plantSymlinkForestRecursive(context *symlinkForestContext, instructions *in
 
 		if instructionsChild != nil && instructionsChild.excluded {
 			if bExists {
-				context.symlinkCount.Add(symlinkIntoForest(context.topdir, forestChild, buildFilesChild))
+				placeIntoForest(context, forestChild, buildFilesChild, false)
 			}
 			continue
 		}
@@ -401,7 +644,7 @@ func plantSymlinkForestRecursive(context *symlinkForestContext, instructions *in
 				go plantSymlinkForestRecursive(context, instructionsChild, forestChild, buildFilesChild, srcChild)
 			} else {
 				// Not in the source tree, symlink BUILD file
-				context.symlinkCount.Add(symlinkIntoForest(context.topdir, forestChild, buildFilesChild))
+				placeIntoForest(context, forestChild, buildFilesChild, false)
 			}
 		} else if !bExists {
 			if sDir && instructionsChild != nil {
@@ -411,7 +654,7 @@ func plantSymlinkForestRecursive(context *symlinkForestContext, instructions *in
 				go plantSymlinkForestRecursive(context, instructionsChild, forestChild, buildFilesChild, srcChild)
 			} else {
 				// Not in the build file tree, symlink source tree, carry on
-				context.symlinkCount.Add(symlinkIntoForest(context.topdir, forestChild, srcChild))
+				placeIntoForest(context, forestChild, srcChild, true)
 			}
 		} else if sDir && bDir {
 			// Both are directories. Descend.
@@ -460,24 +703,77 @@ func plantSymlinkForestRecursive(context *symlinkForestContext, instructions *in
 	}
 }
 
+// acquireJob blocks until fewer than the maximum number of directories are being planted. A
+// directory only holds its job while it is planted, the goroutines of its subdirectories wait for
+// their own jobs, so the jobs can't deadlock.
+func (context *symlinkForestContext) acquireJob() {
+	context.jobs <- struct{}{}
+}
+
+func (context *symlinkForestContext) releaseJob() {
+	<-context.jobs
+}
+
+// DefaultSymlinkForestJobs is the number of directories that are planted concurrently if the
+// number isn't set. Planting is dominated by the latency of the syscalls rather than by the CPU,
+// so it uses more jobs than there are CPUs.
+func DefaultSymlinkForestJobs() int {
+	return 4 * runtime.NumCPU()
+}
+
+// readForestManifest returns the manifest written by the previous run in the hardlink or copy
+// mode, or an empty manifest if there is none.
+func readForestManifest(topdir, forest string) map[string]forestManifestEntry {
+	manifest := make(map[string]forestManifestEntry)
+	data, err := os.ReadFile(shared.JoinPath(topdir, forest, forestManifestFile))
+	if err != nil {
+		return manifest
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		// The files are copied again.
+		return make(map[string]forestManifestEntry)
+	}
+	return manifest
+}
+
+func writeForestManifest(topdir, forest string, manifest map[string]forestManifestEntry) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return pathtools.WriteFileIfChanged(shared.JoinPath(topdir, forest, forestManifestFile), data, 0666)
+}
+
 // PlantSymlinkForest Creates a symlink forest by merging the directory tree at "buildFiles" and
-// "srcDir" while excluding paths listed in "exclude". Returns the set of paths
+// "srcDir" while excluding paths listed in "exclude". Returns the sorted set of paths
 // under srcDir on which readdir() had to be called to produce the symlink
-// forest.
-func PlantSymlinkForest(verbose bool, topdir string, forest string, buildFiles string, exclude []string) (deps []string, mkdirCount, symlinkCount uint64) {
+// forest. In the hardlink and copy modes, the files that were hardlinked or copied
+// from srcDir are returned, too. Up to jobs directories are planted concurrently, or
+// DefaultSymlinkForestJobs() if jobs is not positive.
+func PlantSymlinkForest(verbose bool, mode ForestMode, jobs int, topdir string, forest string, buildFiles string, exclude []string) (deps []string, mkdirCount, symlinkCount uint64) {
+	if jobs <= 0 {
+		jobs = DefaultSymlinkForestJobs()
+	}
 	context := &symlinkForestContext{
 		verbose:      verbose,
+		mode:         mode,
 		topdir:       topdir,
+		forest:       forest,
+		jobs:         make(chan struct{}, jobs),
 		depCh:        make(chan string),
 		mkdirCount:   atomic.Uint64{},
 		symlinkCount: atomic.Uint64{},
+		manifest:     make(map[string]forestManifestEntry),
 	}
 
-	err := maybeCleanSymlinkForest(topdir, forest, verbose)
+	err := maybeCleanSymlinkForest(topdir, forest, mode, verbose)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	if mode != SymlinkForest {
+		context.prevManifest = readForestManifest(topdir, forest)
+	}
 
 	instructions := instructionsFromExcludePathList(exclude)
 	go func() {
@@ -490,12 +786,21 @@ func PlantSymlinkForest(verbose bool, topdir string, forest string, buildFiles s
 	for dep := range context.depCh {
 		deps = append(deps, dep)
 	}
+	// The directories are planted concurrently, sort the deps so that the depfile is the same
+	// between runs.
+	sort.Strings(deps)
 
-	err = maybeWriteVersionFile(topdir, forest)
+	err = maybeWriteVersionFile(topdir, forest, mode)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	if mode != SymlinkForest {
+		if err := writeForestManifest(topdir, forest, context.manifest); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
 
 	return deps, context.mkdirCount.Load(), context.symlinkCount.Load()
 }
@@ -0,0 +1,240 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bp2build
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSymlinkIntoForest(t *testing.T) {
+	topDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(topDir, "src", "a"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(topDir, "forest"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := symlinkIntoForest(topDir, "forest/a", "src/a"); n != 1 {
+		t.Errorf("expected the symlink to be created, got %d", n)
+	}
+	if n := symlinkIntoForest(topDir, "forest/a", "src/a"); n != 0 {
+		t.Errorf("expected the existing symlink to be kept, got %d", n)
+	}
+
+	// The source moved, for example because the out directory is used with another checkout.
+	if err := os.MkdirAll(filepath.Join(topDir, "src2", "a"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if n := symlinkIntoForest(topDir, "forest/a", "src2/a"); n != 1 {
+		t.Errorf("expected the stale symlink to be replaced, got %d", n)
+	}
+	if target, err := os.Readlink(filepath.Join(topDir, "forest", "a")); err != nil {
+		t.Fatal(err)
+	} else if expected := filepath.Join(topDir, "src2", "a"); target != expected {
+		t.Errorf("expected the symlink to point to %q, got %q", expected, target)
+	}
+}
+
+func TestPlantSymlinkForestAbsoluteOutDir(t *testing.T) {
+	tempDir := t.TempDir()
+	topDir := filepath.Join(tempDir, "src")
+	// An OUT_DIR outside of the source tree, as an absolute path.
+	outDir := filepath.Join(tempDir, "scratch", "out")
+	forest := filepath.Join(outDir, "soong", "workspace")
+	buildFiles := filepath.Join(outDir, "soong", "bp2build")
+
+	for _, dir := range []string{filepath.Join(topDir, "a"), buildFiles} {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(topDir, "a", "a.txt"), []byte("a"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	PlantSymlinkForest(false, SymlinkForest, 0, topDir, forest, buildFiles, nil)
+
+	data, err := os.ReadFile(filepath.Join(forest, "a", "a.txt"))
+	if err != nil {
+		t.Fatalf("expected a.txt in the symlink forest: %s", err)
+	}
+	if string(data) != "a" {
+		t.Errorf("expected contents %q, got %q", "a", string(data))
+	}
+	if target, err := os.Readlink(filepath.Join(forest, "a")); err != nil {
+		t.Fatal(err)
+	} else if expected := filepath.Join(topDir, "a"); target != expected {
+		t.Errorf("expected the symlink to point to %q, got %q", expected, target)
+	}
+}
+
+func TestParseForestMode(t *testing.T) {
+	for name, expected := range map[string]ForestMode{
+		"":         SymlinkForest,
+		"symlink":  SymlinkForest,
+		"hardlink": HardlinkForest,
+		"copy":     CopyForest,
+	} {
+		if mode, err := ParseForestMode(name); err != nil {
+			t.Errorf("unexpected error for %q: %s", name, err)
+		} else if mode != expected {
+			t.Errorf("expected %s for %q, got %s", expected, name, mode)
+		}
+	}
+	if _, err := ParseForestMode("junction"); err == nil {
+		t.Errorf("expected an error for an unknown mode")
+	}
+}
+
+// setUpForestTest creates a source tree with a directory that is merged with the build files tree
+// and one that is not, and returns the top directory.
+func setUpForestTest(t *testing.T) string {
+	topDir := t.TempDir()
+	for _, dir := range []string{"src/a/b", "src/c", "bp2build/src/c"} {
+		if err := os.MkdirAll(filepath.Join(topDir, dir), 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for file, contents := range map[string]string{
+		"src/a/b/b.txt":        "b",
+		"src/a/.hidden":        "hidden",
+		"src/c/c.txt":          "c",
+		"bp2build/src/c/BUILD": "generated",
+		"bp2build/WORKSPACE":   "workspace",
+	} {
+		if err := os.WriteFile(filepath.Join(topDir, file), []byte(contents), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return topDir
+}
+
+func plantForestForTest(mode ForestMode, topDir string) ([]string, uint64) {
+	deps, _, count := PlantSymlinkForest(false, mode, 0, topDir, "workspace", "bp2build",
+		[]string{"bp2build", "workspace"})
+	return deps, count
+}
+
+func assertForestFile(t *testing.T, topDir, path, expected string) os.FileInfo {
+	t.Helper()
+	fullPath := filepath.Join(topDir, "workspace", path)
+	fi, err := os.Lstat(fullPath)
+	if err != nil {
+		t.Fatalf("expected %s in the workspace: %s", path, err)
+	}
+	if !fi.Mode().IsRegular() {
+		t.Errorf("expected %s to be a regular file, got %s", path, fi.Mode())
+	}
+	if data, err := os.ReadFile(fullPath); err != nil {
+		t.Fatal(err)
+	} else if string(data) != expected {
+		t.Errorf("expected %s to contain %q, got %q", path, expected, string(data))
+	}
+	return fi
+}
+
+func TestPlantHardlinkForest(t *testing.T) {
+	topDir := setUpForestTest(t)
+
+	deps, count := plantForestForTest(HardlinkForest, topDir)
+	if count != 4 {
+		t.Errorf("expected 4 files to be hardlinked, got %d", count)
+	}
+	sort.Strings(deps)
+	expectedDeps := []string{".", "src", "src/a", "src/a/b", "src/a/b/b.txt", "src/c", "src/c/c.txt"}
+	if !reflect.DeepEqual(deps, expectedDeps) {
+		t.Errorf("expected deps %q, got %q", expectedDeps, deps)
+	}
+
+	for _, dir := range []string{"src", "src/a", "src/a/b"} {
+		if fi, err := os.Lstat(filepath.Join(topDir, "workspace", dir)); err != nil {
+			t.Fatal(err)
+		} else if !fi.IsDir() {
+			t.Errorf("expected %s to be a directory, got %s", dir, fi.Mode())
+		}
+	}
+	fi := assertForestFile(t, topDir, "src/a/b/b.txt", "b")
+	if srcInfo, err := os.Stat(filepath.Join(topDir, "src/a/b/b.txt")); err != nil {
+		t.Fatal(err)
+	} else if !os.SameFile(fi, srcInfo) {
+		t.Errorf("expected src/a/b/b.txt to be hardlinked")
+	}
+	assertForestFile(t, topDir, "src/c/c.txt", "c")
+	assertForestFile(t, topDir, "src/c/BUILD", "generated")
+	assertForestFile(t, topDir, "WORKSPACE", "workspace")
+	if _, err := os.Lstat(filepath.Join(topDir, "workspace", "src/a/.hidden")); !os.IsNotExist(err) {
+		t.Errorf("expected dotfiles to be left out of the workspace")
+	}
+
+	if _, count := plantForestForTest(HardlinkForest, topDir); count != 0 {
+		t.Errorf("expected the hardlinks to be kept, got %d", count)
+	}
+}
+
+func TestPlantCopyForest(t *testing.T) {
+	topDir := setUpForestTest(t)
+
+	if _, count := plantForestForTest(CopyForest, topDir); count != 4 {
+		t.Errorf("expected 4 files to be copied, got %d", count)
+	}
+	fi := assertForestFile(t, topDir, "src/a/b/b.txt", "b")
+	if srcInfo, err := os.Stat(filepath.Join(topDir, "src/a/b/b.txt")); err != nil {
+		t.Fatal(err)
+	} else if os.SameFile(fi, srcInfo) {
+		t.Errorf("expected src/a/b/b.txt to be copied")
+	}
+
+	manifest := readForestManifest(topDir, "workspace")
+	if entry := manifest["src/a/b/b.txt"]; entry.Src != "src/a/b/b.txt" || entry.Size != 1 {
+		t.Errorf("unexpected manifest entry for src/a/b/b.txt: %+v", entry)
+	}
+
+	// Only the file that changed is copied again.
+	bPath := filepath.Join(topDir, "src/a/b/b.txt")
+	if err := os.WriteFile(bPath, []byte("bb"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(bPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(topDir, "src/c/c.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if _, count := plantForestForTest(CopyForest, topDir); count != 1 {
+		t.Errorf("expected 1 file to be copied again, got %d", count)
+	}
+	assertForestFile(t, topDir, "src/a/b/b.txt", "bb")
+	if _, err := os.Lstat(filepath.Join(topDir, "workspace", "src/c/c.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected the deleted file to be removed from the workspace")
+	}
+
+	// Switching modes recreates the workspace.
+	plantForestForTest(SymlinkForest, topDir)
+	if fi, err := os.Lstat(filepath.Join(topDir, "workspace", "src/a")); err != nil {
+		t.Fatal(err)
+	} else if fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected src/a to be a symlink, got %s", fi.Mode())
+	}
+	if _, err := os.Lstat(filepath.Join(topDir, "workspace", forestManifestFile)); !os.IsNotExist(err) {
+		t.Errorf("expected the manifest to be removed with the workspace")
+	}
+}
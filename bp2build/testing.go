@@ -83,6 +83,8 @@ type Bp2buildTestCase struct {
 	// An error with a string contained within the string of the expected error
 	ExpectedErr         error
 	UnconvertedDepsMode unconvertedDepsMode
+	// The value of BP2BUILD_FORMAT_POLICY, see formattingPolicy.
+	FormattingPolicy string
 
 	// For every directory listed here, the BUILD file for that directory will
 	// be merged with the generated BUILD file. This allows custom BUILD targets
@@ -142,6 +144,9 @@ func runBp2BuildTestCaseWithSetup(t *testing.T, extraPreparer android.FixturePre
 			if tc.UnconvertedDepsMode == errorModulesUnconvertedDeps {
 				env["BP2BUILD_ERROR_UNCONVERTED"] = "true"
 			}
+			if tc.FormattingPolicy != "" {
+				env["BP2BUILD_FORMAT_POLICY"] = tc.FormattingPolicy
+			}
 		}),
 	}
 
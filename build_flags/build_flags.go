@@ -0,0 +1,218 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package build_flags generates the values of build flags into constants that Java and C++ code
+// can use, see android/build_flags.go for the build flags themselves.
+package build_flags
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint/proptools"
+)
+
+func init() {
+	registerBuildFlagsBuildComponents(android.InitRegistrationContext)
+}
+
+func registerBuildFlagsBuildComponents(ctx android.RegistrationContext) {
+	ctx.RegisterModuleType("java_build_flag_constants", JavaBuildFlagConstantsFactory)
+	ctx.RegisterModuleType("cc_build_flag_constants", CcBuildFlagConstantsFactory)
+}
+
+var PrepareForTestWithBuildFlagConstants = android.FixtureRegisterWithContext(registerBuildFlagsBuildComponents)
+
+type buildFlagConstantsProperties struct {
+	// The build flags to generate constants for.
+	Flags []string
+
+	// java_build_flag_constants only: the package of the generated class.
+	Package *string
+
+	// java_build_flag_constants only: the name of the generated class. Defaults to BuildFlags.
+	Class *string
+
+	// cc_build_flag_constants only: the path of the generated header, relative to the include
+	// directory it is exported from. Defaults to <name>.h.
+	Header *string
+}
+
+type buildFlagConstantsLanguage int
+
+const (
+	javaConstants buildFlagConstantsLanguage = iota
+	ccConstants
+)
+
+// buildFlagConstants generates a Java class or a C++ header with constants for the values of build
+// flags in the current build. Java modules use it in their srcs, C++ modules in their
+// generated_headers.
+type buildFlagConstants struct {
+	android.ModuleBase
+
+	properties buildFlagConstantsProperties
+	language   buildFlagConstantsLanguage
+
+	outputFile android.WritablePath
+	headerDir  android.Path
+}
+
+// java_build_flag_constants generates a Java class with a constant for each of the build flags of
+// its flags property, with the value of the flag in the current build.
+func JavaBuildFlagConstantsFactory() android.Module {
+	return newBuildFlagConstants(javaConstants)
+}
+
+// cc_build_flag_constants generates a C++ header with a macro for each of the build flags of its
+// flags property, with the value of the flag in the current build. Bool flags are 1 or 0.
+func CcBuildFlagConstantsFactory() android.Module {
+	return newBuildFlagConstants(ccConstants)
+}
+
+func newBuildFlagConstants(language buildFlagConstantsLanguage) android.Module {
+	module := &buildFlagConstants{language: language}
+	module.AddProperties(&module.properties)
+	android.InitAndroidModule(module)
+	return module
+}
+
+// flags returns the build flags of the flags property, or reports errors for the ones that can't
+// be generated into code.
+func (m *buildFlagConstants) flags(ctx android.ModuleContext) []*android.BuildFlag {
+	var flags []*android.BuildFlag
+	for _, name := range android.SortedUniqueStrings(m.properties.Flags) {
+		flag, ok := ctx.Config().BuildFlag(name)
+		if !ok {
+			ctx.PropertyErrorf("flags", "build flag %s is not declared", name)
+			continue
+		}
+		if flag.Lifecycle == android.BuildFlagObsolete {
+			ctx.PropertyErrorf("flags", "build flag %s is obsolete, remove its uses", name)
+			continue
+		}
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+func (m *buildFlagConstants) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	flags := m.flags(ctx)
+
+	switch m.language {
+	case javaConstants:
+		pkg := proptools.String(m.properties.Package)
+		if pkg == "" {
+			ctx.PropertyErrorf("package", "missing package of the generated class")
+			return
+		}
+		class := proptools.StringDefault(m.properties.Class, "BuildFlags")
+		m.outputFile = android.PathForModuleGen(ctx, strings.ReplaceAll(pkg, ".", "/"), class+".java")
+		android.WriteFileRule(ctx, m.outputFile, javaConstantsContent(pkg, class, flags))
+	case ccConstants:
+		header := proptools.StringDefault(m.properties.Header, ctx.ModuleName()+".h")
+		if filepath.IsAbs(header) || strings.HasPrefix(filepath.Clean(header), "..") {
+			ctx.PropertyErrorf("header", "must be a relative path in the include directory, got %q", header)
+			return
+		}
+		m.headerDir = android.PathForModuleGen(ctx, "include")
+		m.outputFile = android.PathForModuleGen(ctx, "include", header)
+		android.WriteFileRule(ctx, m.outputFile, ccConstantsContent(flags))
+	}
+}
+
+func javaConstantsContent(pkg, class string, flags []*android.BuildFlag) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "// Generated by java_build_flag_constants, do not edit.\n")
+	fmt.Fprintf(sb, "package %s;\n\n", pkg)
+	fmt.Fprintf(sb, "public final class %s {\n", class)
+	fmt.Fprintf(sb, "    private %s() {}\n", class)
+	for _, flag := range flags {
+		sb.WriteString("\n")
+		if flag.Description != "" {
+			fmt.Fprintf(sb, "    /** %s */\n", strings.ReplaceAll(flag.Description, "*/", "* /"))
+		}
+		if flag.Type == android.BuildFlagBool {
+			fmt.Fprintf(sb, "    public static final boolean %s = %t;\n", flag.Name, flag.Bool())
+		} else {
+			fmt.Fprintf(sb, "    public static final String %s = %s;\n", flag.Name, quote(flag.Value))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func ccConstantsContent(flags []*android.BuildFlag) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "// Generated by cc_build_flag_constants, do not edit.\n")
+	fmt.Fprintf(sb, "#pragma once\n")
+	for _, flag := range flags {
+		sb.WriteString("\n")
+		if flag.Description != "" {
+			fmt.Fprintf(sb, "// %s\n", strings.ReplaceAll(flag.Description, "\n", " "))
+		}
+		if flag.Type == android.BuildFlagBool {
+			value := 0
+			if flag.Bool() {
+				value = 1
+			}
+			fmt.Fprintf(sb, "#define %s %d\n", flag.Name, value)
+		} else {
+			fmt.Fprintf(sb, "#define %s %s\n", flag.Name, quote(flag.Value))
+		}
+	}
+	return sb.String()
+}
+
+// quote returns a string literal that is valid in both Java and C++.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s) + `"`
+}
+
+func (m *buildFlagConstants) outputFiles() android.Paths {
+	if m.outputFile == nil {
+		return nil
+	}
+	return android.Paths{m.outputFile}
+}
+
+func (m *buildFlagConstants) OutputFiles(tag string) (android.Paths, error) {
+	if tag != "" {
+		return nil, fmt.Errorf("unsupported module reference tag %q", tag)
+	}
+	return m.outputFiles(), nil
+}
+
+// GeneratedSourceFiles, GeneratedHeaderDirs and GeneratedDeps implement
+// genrule.SourceFileGenerator for the generated_headers of C++ modules.
+
+func (m *buildFlagConstants) GeneratedSourceFiles() android.Paths {
+	return m.outputFiles()
+}
+
+func (m *buildFlagConstants) GeneratedHeaderDirs() android.Paths {
+	if m.headerDir == nil {
+		return nil
+	}
+	return android.Paths{m.headerDir}
+}
+
+func (m *buildFlagConstants) GeneratedDeps() android.Paths {
+	return m.outputFiles()
+}
+
+var _ android.OutputFileProducer = (*buildFlagConstants)(nil)
@@ -0,0 +1,99 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build_flags
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+const testDeclarations = `{
+	"flags": [
+		{"name": "RELEASE_FOO", "type": "bool", "default": "false", "lifecycle": "development", "description": "Enables foo."},
+		{"name": "RELEASE_BAR", "type": "string", "default": "bar", "lifecycle": "launched"},
+		{"name": "RELEASE_OLD", "type": "bool", "default": "true", "lifecycle": "obsolete"}
+	]
+}`
+
+var prepareForTest = android.GroupFixturePreparers(
+	PrepareForTestWithBuildFlagConstants,
+	android.FixtureDeclareBuildFlags("build/flags.json", testDeclarations, map[string]string{
+		"RELEASE_FOO": "true",
+	}),
+)
+
+func TestJavaBuildFlagConstants(t *testing.T) {
+	result := prepareForTest.RunTestWithBp(t, `
+		java_build_flag_constants {
+			name: "flags",
+			flags: ["RELEASE_FOO", "RELEASE_BAR"],
+			package: "com.android.flags",
+		}
+	`)
+
+	output := result.ModuleForTests("flags", "").Output("com/android/flags/BuildFlags.java")
+	android.AssertStringEquals(t, "generated class", `// Generated by java_build_flag_constants, do not edit.
+package com.android.flags;
+
+public final class BuildFlags {
+    private BuildFlags() {}
+
+    public static final String RELEASE_BAR = "bar";
+
+    /** Enables foo. */
+    public static final boolean RELEASE_FOO = true;
+}
+`, android.ContentFromFileRuleForTests(t, output))
+}
+
+func TestCcBuildFlagConstants(t *testing.T) {
+	result := prepareForTest.RunTestWithBp(t, `
+		cc_build_flag_constants {
+			name: "flags",
+			flags: ["RELEASE_FOO", "RELEASE_BAR"],
+			header: "android/flags.h",
+		}
+	`)
+
+	module := result.ModuleForTests("flags", "")
+	output := module.Output("include/android/flags.h")
+	android.AssertStringEquals(t, "generated header", `// Generated by cc_build_flag_constants, do not edit.
+#pragma once
+
+#define RELEASE_BAR "bar"
+
+// Enables foo.
+#define RELEASE_FOO 1
+`, android.ContentFromFileRuleForTests(t, output))
+
+	headerDirs := module.Module().(*buildFlagConstants).GeneratedHeaderDirs()
+	android.AssertPathsRelativeToTopEquals(t, "header dirs",
+		[]string{"out/soong/.intermediates/flags/gen/include"}, headerDirs)
+}
+
+func TestBuildFlagConstantsErrors(t *testing.T) {
+	prepareForTest.
+		ExtendWithErrorHandler(android.FixtureExpectsAllErrorsToMatchAPattern([]string{
+			`module "flags": flags: build flag RELEASE_OLD is obsolete, remove its uses`,
+			`module "flags": flags: build flag RELEASE_MISSING is not declared`,
+		})).
+		RunTestWithBp(t, `
+			cc_build_flag_constants {
+				name: "flags",
+				flags: ["RELEASE_OLD", "RELEASE_MISSING"],
+			}
+		`)
+}
@@ -259,6 +259,9 @@ func (library *libraryDecorator) AndroidMkEntries(ctx AndroidMkContext, entries
 			if len(library.postInstallCmds) > 0 {
 				entries.SetString("LOCAL_POST_INSTALL_CMD", strings.Join(library.postInstallCmds, "&& "))
 			}
+			if library.abiVersionSymlink != "" {
+				entries.AddStrings("LOCAL_MODULE_SYMLINKS", library.abiVersionSymlink)
+			}
 		})
 	} else if library.header() {
 		entries.Class = "HEADER_LIBRARIES"
@@ -58,6 +58,11 @@ type BinaryLinkerProperties struct {
 
 	// Inject boringssl hash into the shared library.  This is only intended for use by external/boringssl.
 	Inject_bssl_hash *bool `android:"arch_variant"`
+
+	// Split the debug info of the unstripped device binary into a .debug file named after its
+	// build id, and list the binary in the symbol upload manifest. Defaults to the
+	// SplitDebugInfo product variable.
+	Split_debug_info *bool `android:"arch_variant"`
 }
 
 func init() {
@@ -99,6 +104,11 @@ type binaryDecorator struct {
 	// Location of the linked, unstripped binary
 	unstrippedOutputFile android.Path
 
+	// Zip of the debug info of the binary named after its build id, and the file that maps the
+	// build id to the unstripped binary, if the debug info is split
+	debugInfoZip android.WritablePath
+	buildIdFile  android.WritablePath
+
 	// Names of symlinks to be installed for use in LOCAL_MODULE_SYMLINKS
 	symlinks []string
 
@@ -372,6 +382,12 @@ func (binary *binaryDecorator) link(ctx ModuleContext,
 
 	binary.unstrippedOutputFile = outputFile
 
+	if binary.splitDebugInfo(ctx) {
+		binary.debugInfoZip = android.PathForModuleOut(ctx, "debug", fileName+".debug.zip")
+		binary.buildIdFile = android.PathForModuleOut(ctx, "debug", fileName+".build_id")
+		transformSplitDebugInfo(ctx, outputFile, binary.debugInfoZip, binary.buildIdFile)
+	}
+
 	if String(binary.Properties.Prefix_symbols) != "" {
 		afterPrefixSymbols := outputFile
 		outputFile = android.PathForModuleOut(ctx, "unprefixed", fileName)
@@ -448,6 +464,15 @@ func (binary *binaryDecorator) link(ctx ModuleContext,
 	return ret
 }
 
+// splitDebugInfo returns true if the debug info of the binary is split out of the unstripped
+// binary. Only stripped device binaries, whose installed files lack the debug info, are split.
+func (binary *binaryDecorator) splitDebugInfo(ctx ModuleContext) bool {
+	if !ctx.Device() || !binary.stripper.NeedsStrip(ctx) {
+		return false
+	}
+	return proptools.BoolDefault(binary.Properties.Split_debug_info, ctx.Config().SplitDebugInfo())
+}
+
 func (binary *binaryDecorator) unstrippedOutputFilePath() android.Path {
 	return binary.unstrippedOutputFile
 }
@@ -17,6 +17,8 @@ package cc
 import (
 	"testing"
 
+	"github.com/google/blueprint/proptools"
+
 	"android/soong/bazel/cquery"
 
 	"android/soong/android"
@@ -100,6 +102,26 @@ cc_binary {
 	android.AssertStringEquals(t, "Unstripped output file", expectedUnStrippedFile, unStrippedFilePath.String())
 }
 
+func TestBinaryHighmemLink(t *testing.T) {
+	t.Parallel()
+	bp := `
+		cc_binary {
+			name: "foo",
+			srcs: ["foo.cc"],
+		}`
+
+	result := PrepareForIntegrationTestWithCc.RunTestWithBp(t, bp)
+	if result.ModuleForTests("foo", "android_arm64_armv8-a").MaybeRule("ldHighmem").Rule != nil {
+		t.Errorf("expected foo not to be linked in the highmem pool")
+	}
+
+	result = android.GroupFixturePreparers(
+		PrepareForIntegrationTestWithCc,
+		android.FixtureMergeEnv(map[string]string{"SOONG_HIGHMEM_ACTION_MB": "256"}),
+	).RunTestWithBp(t, bp)
+	result.ModuleForTests("foo", "android_arm64_armv8-a").Rule("ldHighmem")
+}
+
 func TestBinaryLinkerScripts(t *testing.T) {
 	t.Parallel()
 	result := PrepareForIntegrationTestWithCc.RunTestWithBp(t, `
@@ -120,3 +142,45 @@ func TestBinaryLinkerScripts(t *testing.T) {
 	android.AssertStringDoesContain(t, "missing flag for linker_scripts",
 		binFoo.Args["ldFlags"], "-Wl,--script,bar.ld")
 }
+
+func TestBinarySplitDebugInfo(t *testing.T) {
+	t.Parallel()
+	result := android.GroupFixturePreparers(
+		PrepareForIntegrationTestWithCc,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.SplitDebugInfo = proptools.BoolPtr(true)
+		}),
+	).RunTestWithBp(t, `
+		cc_binary {
+			name: "foo",
+			srcs: ["foo.cc"],
+			host_supported: true,
+		}
+		cc_binary {
+			name: "bar",
+			srcs: ["foo.cc"],
+			split_debug_info: false,
+		}`)
+
+	foo := result.ModuleForTests("foo", "android_arm64_armv8-a")
+	split := foo.Rule("splitDebugInfo")
+	android.AssertPathRelativeToTopEquals(t, "split debug info output",
+		"out/soong/.intermediates/foo/android_arm64_armv8-a/debug/foo.debug.zip", split.Output)
+	android.AssertPathRelativeToTopEquals(t, "split debug info input",
+		"out/soong/.intermediates/foo/android_arm64_armv8-a/unstripped/foo", split.Input)
+	android.AssertPathsRelativeToTopEquals(t, "split debug info build id file",
+		[]string{"out/soong/.intermediates/foo/android_arm64_armv8-a/debug/foo.build_id"}, split.ImplicitOutputs)
+
+	if m := result.ModuleForTests("foo", result.Config.BuildOSTarget.String()).MaybeOutput("debug/foo.debug.zip"); m.Rule != nil {
+		t.Errorf("expected the debug info of host binaries not to be split")
+	}
+	if m := result.ModuleForTests("bar", "android_arm64_armv8-a").MaybeOutput("debug/bar.debug.zip"); m.Rule != nil {
+		t.Errorf("expected the debug info of bar not to be split with split_debug_info: false")
+	}
+
+	manifest := result.SingletonForTests("symbol_upload_manifest").Rule("symbol_upload_manifest")
+	android.AssertPathsRelativeToTopEquals(t, "symbol upload manifest inputs", []string{
+		"out/soong/.intermediates/foo/android_arm64_armv8-a/debug/foo.build_id",
+		"out/soong/.intermediates/foo/android_arm64_armv8-a/debug/foo.debug.zip",
+	}, manifest.Implicits)
+}
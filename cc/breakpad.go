@@ -0,0 +1,97 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"github.com/google/blueprint/proptools"
+
+	"android/soong/android"
+)
+
+// This file generates Breakpad symbols for cc binaries and shared libraries that set
+// breakpad_symbols, or for all of them when the product sets BreakpadSymbols. The .sym files of
+// all modules are collected in $OUT/soong/breakpad_symbols.zip, laid out as a Breakpad symbol
+// store (<name>/<module id>/<name>.sym). It is built by the breakpad_symbols phony target and
+// dist'ed with droidcore.
+
+// breakpadSymbolsLinker is implemented by linkers that embed baseLinker.
+type breakpadSymbolsLinker interface {
+	breakpadSymbols() *bool
+}
+
+func (linker *baseLinker) breakpadSymbols() *bool {
+	return linker.Properties.Breakpad_symbols
+}
+
+// maybeGenerateBreakpadSymbols generates the Breakpad symbols of the module from its unstripped
+// output file if they are enabled for the module.
+func (c *Module) maybeGenerateBreakpadSymbols(ctx ModuleContext) {
+	linker, ok := c.linker.(breakpadSymbolsLinker)
+	if !ok || !proptools.BoolDefault(linker.breakpadSymbols(), ctx.Config().BreakpadSymbols()) {
+		return
+	}
+	// dump_syms only supports ELF files.
+	if ctx.Darwin() || ctx.Windows() {
+		return
+	}
+	library, isLibrary := c.linker.(libraryInterface)
+	if !c.Binary() && !(isLibrary && library.shared() && !c.IsStubs()) {
+		return
+	}
+	unstripped := c.UnstrippedOutputFile()
+	if unstripped == nil {
+		return
+	}
+	c.breakpadSymbolsZip = android.PathForModuleOut(ctx, "breakpad", unstripped.Base()+".sym.zip")
+	transformBreakpadSymbols(ctx, unstripped, c.breakpadSymbolsZip)
+}
+
+func breakpadSymbolsSingletonFactory() android.Singleton {
+	return &breakpadSymbolsSingleton{}
+}
+
+type breakpadSymbolsSingleton struct {
+	zip android.WritablePath
+}
+
+func (s *breakpadSymbolsSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	var symbolZips android.Paths
+	ctx.VisitAllModules(func(module android.Module) {
+		if ccModule, ok := module.(*Module); ok && ccModule.Enabled() && ccModule.breakpadSymbolsZip != nil {
+			symbolZips = append(symbolZips, ccModule.breakpadSymbolsZip)
+		}
+	})
+	if len(symbolZips) == 0 {
+		return
+	}
+
+	s.zip = android.PathForOutput(ctx, "breakpad_symbols.zip")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		BuiltTool("merge_zips").
+		Flag("--ignore-duplicates").
+		Output(s.zip).
+		Inputs(android.SortedUniquePaths(symbolZips))
+	rule.Build("breakpad_symbols", "breakpad symbols zip")
+
+	ctx.Phony("breakpad_symbols", s.zip)
+}
+
+func (s *breakpadSymbolsSingleton) MakeVars(ctx android.MakeVarsContext) {
+	if s.zip == nil {
+		return
+	}
+	ctx.DistForGoal("droidcore", s.zip)
+}
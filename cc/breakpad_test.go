@@ -0,0 +1,80 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+
+	"android/soong/android"
+)
+
+func TestBreakpadSymbols(t *testing.T) {
+	t.Parallel()
+	bp := `
+		cc_binary {
+			name: "bin",
+			srcs: ["foo.cc"],
+			breakpad_symbols: true,
+		}
+		cc_library {
+			name: "libfoo",
+			srcs: ["foo.cc"],
+			breakpad_symbols: true,
+		}
+		cc_library_shared {
+			name: "libbar",
+			srcs: ["foo.cc"],
+		}`
+
+	t.Run("per module", func(t *testing.T) {
+		t.Parallel()
+		result := PrepareForIntegrationTestWithCc.RunTestWithBp(t, bp)
+
+		bin := result.ModuleForTests("bin", "android_arm64_armv8-a").Rule("breakpadSymbols")
+		android.AssertPathRelativeToTopEquals(t, "breakpad symbols input",
+			"out/soong/.intermediates/bin/android_arm64_armv8-a/unstripped/bin", bin.Input)
+		android.AssertPathRelativeToTopEquals(t, "breakpad symbols output",
+			"out/soong/.intermediates/bin/android_arm64_armv8-a/breakpad/bin.sym.zip", bin.Output)
+
+		libShared := result.ModuleForTests("libfoo", "android_arm64_armv8-a_shared").Rule("breakpadSymbols")
+		android.AssertPathRelativeToTopEquals(t, "breakpad symbols input",
+			"out/soong/.intermediates/libfoo/android_arm64_armv8-a_shared/unstripped/libfoo.so", libShared.Input)
+
+		if m := result.ModuleForTests("libfoo", "android_arm64_armv8-a_static").MaybeRule("breakpadSymbols"); m.Rule != nil {
+			t.Errorf("expected no breakpad symbols for static libraries")
+		}
+		if m := result.ModuleForTests("libbar", "android_arm64_armv8-a_shared").MaybeRule("breakpadSymbols"); m.Rule != nil {
+			t.Errorf("expected no breakpad symbols for libbar without breakpad_symbols")
+		}
+
+		zip := result.SingletonForTests("breakpad_symbols").Rule("breakpad_symbols")
+		android.AssertStringListContains(t, "breakpad symbols zip inputs", zip.Implicits.RelativeToTop().Strings(),
+			"out/soong/.intermediates/bin/android_arm64_armv8-a/breakpad/bin.sym.zip")
+	})
+
+	t.Run("global", func(t *testing.T) {
+		t.Parallel()
+		result := android.GroupFixturePreparers(
+			PrepareForIntegrationTestWithCc,
+			android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+				variables.BreakpadSymbols = proptools.BoolPtr(true)
+			}),
+		).RunTestWithBp(t, bp)
+
+		result.ModuleForTests("libbar", "android_arm64_armv8-a_shared").Rule("breakpadSymbols")
+	})
+}
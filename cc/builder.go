@@ -45,10 +45,10 @@ var (
 		blueprint.RuleParams{
 			Depfile:     "${out}.d",
 			Deps:        blueprint.DepsGCC,
-			Command:     "$relPwd ${config.CcWrapper}$ccCmd -c $cFlags -MD -MF ${out}.d -o $out $in",
+			Command:     "$relPwd ${config.CcWrapper}$compilerCache$ccCmd -c $cFlags -MD -MF ${out}.d -o $out $in",
 			CommandDeps: []string{"$ccCmd"},
 		},
-		"ccCmd", "cFlags")
+		"ccCmd", "cFlags", "compilerCache")
 
 	// Rule to invoke gcc with given command and flags, but no dependencies.
 	ccNoDeps = pctx.AndroidStaticRule("ccNoDeps",
@@ -58,17 +58,50 @@ var (
 		},
 		"ccCmd", "cFlags")
 
+	ldParams = blueprint.RuleParams{
+		Command: "$reTemplate$ldCmd ${crtBegin} @${out}.rsp " +
+			"${crtEnd} -o ${out} ${ldFlags} ${extraLibFlags}",
+		CommandDeps:    []string{"$ldCmd"},
+		Rspfile:        "${out}.rsp",
+		RspfileContent: "${in} ${libFlags}",
+		// clang -Wl,--out-implib doesn't update its output file if it hasn't changed.
+		Restat: true,
+	}
+
 	// Rules to invoke ld to link binaries. Uses a .rsp file to list dependencies, as there may
 	// be many.
-	ld, ldRE = pctx.RemoteStaticRules("ld",
+	ld, ldRE = pctx.RemoteStaticRules("ld", ldParams,
+		&remoteexec.REParams{
+			Labels:          map[string]string{"type": "link", "tool": "clang"},
+			ExecStrategy:    "${config.RECXXLinksExecStrategy}",
+			Inputs:          []string{"${out}.rsp", "$implicitInputs"},
+			RSPFiles:        []string{"${out}.rsp"},
+			OutputFiles:     []string{"${out}", "$implicitOutputs"},
+			ToolchainInputs: []string{"$ldCmd"},
+			Platform:        map[string]string{remoteexec.PoolKey: "${config.RECXXLinksPool}"},
+		}, []string{"ldCmd", "crtBegin", "libFlags", "crtEnd", "ldFlags", "extraLibFlags"}, []string{"implicitInputs", "implicitOutputs"})
+
+	// Rule to invoke ld locally in the highmem pool, for the links that are estimated to need a lot
+	// of memory.
+	ldHighmem = pctx.HighmemStaticRule("ldHighmem", ldParams,
+		"ldCmd", "crtBegin", "libFlags", "crtEnd", "ldFlags", "extraLibFlags")
+
+	// Rules to run the thin link of a distributed ThinLTO link, which only writes the ThinLTO index
+	// of each object to ${indexDir}/<object>.thinlto.bc and the list of objects that it imports from
+	// to ${indexDir}/<object>.imports. Empty files are written for objects that are not bitcode.
+	thinLink, thinLinkRE = pctx.RemoteStaticRules("thinLink",
 		blueprint.RuleParams{
-			Command: "$reTemplate$ldCmd ${crtBegin} @${out}.rsp " +
-				"${crtEnd} -o ${out} ${ldFlags} ${extraLibFlags}",
+			Command: "rm -rf ${indexDir} && " +
+				"$reTemplate$ldCmd ${crtBegin} @${out}.rsp ${crtEnd} -o ${out}.unused ${ldFlags} ${extraLibFlags} " +
+				"-Wl,--thinlto-index-only=${out} -Wl,--thinlto-emit-imports-files " +
+				"'-Wl,--thinlto-prefix-replace=;${indexDir}/' && " +
+				"for f in $$(cat ${out}.rsp); do case $$f in *.o) " +
+				"[ -e ${indexDir}/$$f.thinlto.bc ] || " +
+				"{ mkdir -p $$(dirname ${indexDir}/$$f) && touch ${indexDir}/$$f.thinlto.bc ${indexDir}/$$f.imports; } ;; " +
+				"esac; done",
 			CommandDeps:    []string{"$ldCmd"},
 			Rspfile:        "${out}.rsp",
 			RspfileContent: "${in} ${libFlags}",
-			// clang -Wl,--out-implib doesn't update its output file if it hasn't changed.
-			Restat: true,
 		},
 		&remoteexec.REParams{
 			Labels:          map[string]string{"type": "link", "tool": "clang"},
@@ -78,7 +111,27 @@ var (
 			OutputFiles:     []string{"${out}", "$implicitOutputs"},
 			ToolchainInputs: []string{"$ldCmd"},
 			Platform:        map[string]string{remoteexec.PoolKey: "${config.RECXXLinksPool}"},
-		}, []string{"ldCmd", "crtBegin", "libFlags", "crtEnd", "ldFlags", "extraLibFlags"}, []string{"implicitInputs", "implicitOutputs"})
+		}, []string{"ldCmd", "crtBegin", "libFlags", "crtEnd", "ldFlags", "extraLibFlags", "indexDir"},
+		[]string{"implicitInputs", "implicitOutputs"})
+
+	// Rules to generate the native object of a bitcode object with its ThinLTO index, the backend of a
+	// distributed ThinLTO link. Objects that are not bitcode, whose index is empty, are copied.
+	thinLTOBackend, thinLTOBackendRE = pctx.RemoteStaticRules("thinLTOBackend",
+		blueprint.RuleParams{
+			Command: "if [ -s ${index} ]; then " +
+				"$reTemplate$ccCmd -c -x ir ${in} -fthinlto-index=${index} -o ${out} ${backendFlags}; " +
+				"else cp -f ${in} ${out}; fi",
+			CommandDeps: []string{"$ccCmd"},
+		},
+		&remoteexec.REParams{
+			Labels:          map[string]string{"type": "tool", "name": "thinlto-backend"},
+			ExecStrategy:    "${config.REThinLTOBackendExecStrategy}",
+			Inputs:          []string{"$in", "$index"},
+			RSPFiles:        []string{"$imports"},
+			OutputFiles:     []string{"$out"},
+			ToolchainInputs: []string{"$ccCmd"},
+			Platform:        map[string]string{remoteexec.PoolKey: "${config.RECXXPool}"},
+		}, []string{"ccCmd", "index", "backendFlags"}, []string{"imports"})
 
 	// Rules for .o files to combine to other .o files, using ld partial linking.
 	partialLd, partialLdRE = pctx.RemoteStaticRules("partialLd",
@@ -202,14 +255,17 @@ var (
 		},
 		"clangBin", "format")
 
-	// Rules for invoking clang-tidy (a clang-based linter).
+	// Rules for invoking clang-tidy (a clang-based linter). tidy_wrapper caches the results of
+	// clang-tidy, suppresses the findings in the baselines and writes all the findings to
+	// ${out}.findings for the tidy report.
 	clangTidy, clangTidyRE = pctx.RemoteStaticRules("clangTidy",
 		blueprint.RuleParams{
 			Depfile: "${out}.d",
 			Deps:    blueprint.DepsGCC,
 			Command: "CLANG_CMD=$clangCmd TIDY_FILE=$out " +
-				"$tidyVars$reTemplate${config.ClangBin}/clang-tidy.sh $in $tidyFlags -- $cFlags",
-			CommandDeps: []string{"${config.ClangBin}/clang-tidy.sh", "$ccCmd", "$tidyCmd"},
+				"$tidyVars${tidyWrapperCmd} --out $out --src $in --findings ${out}.findings $tidyWrapperFlags -- " +
+				"$reTemplate${config.ClangBin}/clang-tidy.sh $in $tidyFlags -- $cFlags",
+			CommandDeps: []string{"${config.ClangBin}/clang-tidy.sh", "$ccCmd", "$tidyCmd", "${tidyWrapperCmd}"},
 		},
 		&remoteexec.REParams{
 			Labels:               map[string]string{"type": "lint", "tool": "clang-tidy", "lang": "cpp"},
@@ -225,7 +281,7 @@ var (
 			// (1) New timestamps trigger clang and clang-tidy compilations again.
 			// (2) Changing source files caused concurrent clang or clang-tidy jobs to crash.
 			Platform: map[string]string{remoteexec.PoolKey: "${config.REClangTidyPool}"},
-		}, []string{"cFlags", "ccCmd", "clangCmd", "tidyCmd", "tidyFlags", "tidyVars"}, []string{})
+		}, []string{"cFlags", "ccCmd", "clangCmd", "tidyCmd", "tidyFlags", "tidyVars", "tidyWrapperFlags"}, []string{})
 
 	_ = pctx.SourcePathVariable("yasmCmd", "prebuilts/misc/${config.HostPrebuiltTag}/yasm/yasm")
 
@@ -292,6 +348,36 @@ var (
 		},
 		"extraFlags", "referenceDump", "libName", "arch", "errorMessage")
 
+	// Rule to extract the debug info of an unstripped file into a zip containing
+	// .build-id/<first two digits of the build id>/<rest of the build id>.debug, and to
+	// write "<build id> <unstripped file>" to a file for the symbol upload manifest.
+	splitDebugInfo = pctx.AndroidStaticRule("splitDebugInfo",
+		blueprint.RuleParams{
+			Command: `rm -rf ${outDir} && ` +
+				`BUILD_ID=$$(${config.ClangBin}/llvm-readelf -n ${in} | sed -n 's/^ *Build ID: //p') && ` +
+				`if [ -z "$$BUILD_ID" ]; then echo "${in} has no build id" >&2; exit 1; fi && ` +
+				`DEBUG_DIR=${outDir}/.build-id/$$(echo $$BUILD_ID | cut -c1-2) && mkdir -p $$DEBUG_DIR && ` +
+				`${config.ClangBin}/llvm-objcopy --only-keep-debug ${in} $$DEBUG_DIR/$$(echo $$BUILD_ID | cut -c3-).debug && ` +
+				`${SoongZipCmd} -o ${out} -C ${outDir} -D ${outDir} && ` +
+				`echo "$$BUILD_ID ${in}" > ${buildId}`,
+			CommandDeps: []string{"${SoongZipCmd}"},
+		},
+		"outDir", "buildId")
+
+	// Rule to generate the Breakpad symbols of an unstripped file into a zip containing
+	// <name>/<Breakpad module id>/<name>.sym, the layout of Breakpad symbol stores.
+	breakpadSymbols = pctx.AndroidStaticRule("breakpadSymbols",
+		blueprint.RuleParams{
+			Command: `rm -rf ${outDir} && mkdir -p ${outDir} && ` +
+				`${dumpSymsCmd} ${in} > ${outDir}/${name}.sym && ` +
+				`ID=$$(head -n1 ${outDir}/${name}.sym | cut -d' ' -f4) && ` +
+				`if [ -z "$$ID" ]; then echo "dump_syms found no module id in ${in}" >&2; exit 1; fi && ` +
+				`mkdir -p ${outDir}/${name}/$$ID && mv ${outDir}/${name}.sym ${outDir}/${name}/$$ID/ && ` +
+				`${SoongZipCmd} -o ${out} -C ${outDir} -D ${outDir}/${name}`,
+			CommandDeps: []string{"${dumpSymsCmd}", "${SoongZipCmd}"},
+		},
+		"outDir", "name")
+
 	// Rule to zip files.
 	zip = pctx.AndroidStaticRule("zip",
 		blueprint.RuleParams{
@@ -339,6 +425,8 @@ func init() {
 	pctx.StaticVariable("relPwd", PwdPrefix())
 
 	pctx.HostBinToolVariable("SoongZipCmd", "soong_zip")
+	pctx.HostBinToolVariable("tidyWrapperCmd", "tidy_wrapper")
+	pctx.HostBinToolVariable("dumpSymsCmd", "dump_syms")
 }
 
 // builderFlags contains various types of command line flags (and settings) for use in building
@@ -374,6 +462,10 @@ type builderFlags struct {
 	aidlFlags     string // Flags that apply to aidl source files
 	rsFlags       string // Flags that apply to renderscript source files
 	toolchain     config.Toolchain
+	clangBin      string // The bin directory of clang, ${config.ClangBin} if it is empty
+
+	// The baseline of the clang-tidy findings to suppress, if any.
+	tidyBaseline android.OptionalPath
 
 	// True if these extra features are enabled.
 	tidy          bool
@@ -382,6 +474,8 @@ type builderFlags struct {
 	sAbiDump      bool
 	emitXrefs     bool
 
+	distributedThinLTO bool // True if the ThinLTO backend runs in separate actions.
+
 	assemblerWithCpp bool // True if .s files should be processed with the c preprocessor.
 
 	systemIncludeFlags string
@@ -394,6 +488,14 @@ type builderFlags struct {
 	lex  *LexProperties
 }
 
+// clangBinDir returns the bin directory of the clang release that compiles and links the module.
+func (flags builderFlags) clangBinDir() string {
+	if flags.clangBin != "" {
+		return flags.clangBin
+	}
+	return "${config.ClangBin}"
+}
+
 // StripFlags represents flags related to stripping. This is separate from builderFlags, as these
 // flags are useful outside of this package (such as for Rust).
 type StripFlags struct {
@@ -411,6 +513,7 @@ type Objects struct {
 	objFiles      android.Paths
 	tidyFiles     android.Paths
 	tidyDepFiles  android.Paths // link dependent .tidy files
+	tidyFindings  android.Paths // .tidy.findings files for the tidy report
 	coverageFiles android.Paths
 	sAbiDumpFiles android.Paths
 	kytheFiles    android.Paths
@@ -421,6 +524,7 @@ func (a Objects) Copy() Objects {
 		objFiles:      append(android.Paths{}, a.objFiles...),
 		tidyFiles:     append(android.Paths{}, a.tidyFiles...),
 		tidyDepFiles:  append(android.Paths{}, a.tidyDepFiles...),
+		tidyFindings:  append(android.Paths{}, a.tidyFindings...),
 		coverageFiles: append(android.Paths{}, a.coverageFiles...),
 		sAbiDumpFiles: append(android.Paths{}, a.sAbiDumpFiles...),
 		kytheFiles:    append(android.Paths{}, a.kytheFiles...),
@@ -432,6 +536,7 @@ func (a Objects) Append(b Objects) Objects {
 		objFiles:      append(a.objFiles, b.objFiles...),
 		tidyFiles:     append(a.tidyFiles, b.tidyFiles...),
 		tidyDepFiles:  append(a.tidyDepFiles, b.tidyDepFiles...),
+		tidyFindings:  append(a.tidyFindings, b.tidyFindings...),
 		coverageFiles: append(a.coverageFiles, b.coverageFiles...),
 		sAbiDumpFiles: append(a.sAbiDumpFiles, b.sAbiDumpFiles...),
 		kytheFiles:    append(a.kytheFiles, b.kytheFiles...),
@@ -443,11 +548,14 @@ func transformSourceToObj(ctx ModuleContext, subdir string, srcFiles, noTidySrcs
 	flags builderFlags, pathDeps android.Paths, cFlagsDeps android.Paths) Objects {
 	// Source files are one-to-one with tidy, coverage, or kythe files, if enabled.
 	objFiles := make(android.Paths, len(srcFiles))
-	var tidyFiles android.Paths
+	var tidyFiles, tidyFindings android.Paths
 	noTidySrcsMap := make(map[string]bool)
 	var tidyVars string
+	var tidyWrapperFlags []string
+	var tidyImplicits android.Paths
 	if flags.tidy {
 		tidyFiles = make(android.Paths, 0, len(srcFiles))
+		tidyFindings = make(android.Paths, 0, len(srcFiles))
 		for _, path := range noTidySrcs {
 			noTidySrcsMap[path.String()] = true
 		}
@@ -459,6 +567,15 @@ func transformSourceToObj(ctx ModuleContext, subdir string, srcFiles, noTidySrcs
 				noTidySrcsMap[path.String()] = true
 			}
 		}
+		// The results of clang-tidy are cached outside of the output directory, so that they
+		// survive clean builds and can be shared between checkouts.
+		if tidyCacheDir := ctx.Config().Getenv("TIDY_CACHE_DIR"); tidyCacheDir != "" {
+			tidyWrapperFlags = append(tidyWrapperFlags, "--cache_dir "+tidyCacheDir)
+		}
+		if flags.tidyBaseline.Valid() {
+			tidyWrapperFlags = append(tidyWrapperFlags, "--baseline "+flags.tidyBaseline.String())
+			tidyImplicits = append(tidyImplicits, flags.tidyBaseline.Path())
+		}
 	}
 	var coverageFiles android.Paths
 	if flags.gcovCoverage {
@@ -626,7 +743,7 @@ func transformSourceToObj(ctx ModuleContext, subdir string, srcFiles, noTidySrcs
 		// ccCmd is "clang" or "clang++"
 		ccDesc := ccCmd
 
-		ccCmd = "${config.ClangBin}/" + ccCmd
+		ccCmd = flags.clangBinDir() + "/" + ccCmd
 
 		var implicitOutputs android.WritablePaths
 		if coverage {
@@ -635,6 +752,17 @@ func transformSourceToObj(ctx ModuleContext, subdir string, srcFiles, noTidySrcs
 			coverageFiles = append(coverageFiles, gcnoFile)
 		}
 
+		args := map[string]string{
+			"cFlags": shareFlags("cFlags", moduleFlags),
+			"ccCmd":  ccCmd, // short and not shared
+		}
+		// The compiler cache runs after $relPwd, so it maps the working directory with
+		// -fdebug-prefix-map=/proc/self/cwd= and shares cache entries across checkouts. Compiles
+		// with coverage also write a .gcno file, which the compiler caches don't restore.
+		if cache, ok := ctx.Config().CompilerCache(); ok && rule == cc && !coverage {
+			args["compilerCache"] = cache.Exec + " "
+		}
+
 		ctx.Build(pctx, android.BuildParams{
 			Rule:            rule,
 			Description:     ccDesc + " " + srcFile.Rel(),
@@ -643,10 +771,7 @@ func transformSourceToObj(ctx ModuleContext, subdir string, srcFiles, noTidySrcs
 			Input:           srcFile,
 			Implicits:       cFlagsDeps,
 			OrderOnly:       pathDeps,
-			Args: map[string]string{
-				"cFlags": shareFlags("cFlags", moduleFlags),
-				"ccCmd":  ccCmd, // short and not shared
-			},
+			Args:            args,
 		})
 
 		// Register post-process build statements (such as for tidy or kythe).
@@ -670,6 +795,8 @@ func transformSourceToObj(ctx ModuleContext, subdir string, srcFiles, noTidySrcs
 		if tidy && !noTidySrcsMap[srcFile.String()] {
 			tidyFile := android.ObjPathWithExt(ctx, subdir, srcFile, "tidy")
 			tidyFiles = append(tidyFiles, tidyFile)
+			tidyFindingsFile := android.ObjPathWithExt(ctx, subdir, srcFile, "tidy.findings")
+			tidyFindings = append(tidyFindings, tidyFindingsFile)
 			tidyCmd := "${config.ClangBin}/clang-tidy"
 
 			rule := clangTidy
@@ -687,19 +814,21 @@ func transformSourceToObj(ctx ModuleContext, subdir string, srcFiles, noTidySrcs
 
 			// Add the .tidy rule
 			ctx.Build(pctx, android.BuildParams{
-				Rule:        rule,
-				Description: "clang-tidy " + srcRelPath,
-				Output:      tidyFile,
-				Input:       srcFile,
-				Implicits:   cFlagsDeps,
-				OrderOnly:   pathDeps,
+				Rule:           rule,
+				Description:    "clang-tidy " + srcRelPath,
+				Output:         tidyFile,
+				ImplicitOutput: tidyFindingsFile,
+				Input:          srcFile,
+				Implicits:      append(tidyImplicits, cFlagsDeps...),
+				OrderOnly:      pathDeps,
 				Args: map[string]string{
-					"cFlags":    sharedCFlags,
-					"ccCmd":     ccCmd,
-					"clangCmd":  ccDesc,
-					"tidyCmd":   tidyCmd,
-					"tidyFlags": shareFlags("tidyFlags", config.TidyFlagsForSrcFile(srcFile, flags.tidyFlags)),
-					"tidyVars":  tidyVars, // short and not shared
+					"cFlags":           sharedCFlags,
+					"ccCmd":            ccCmd,
+					"clangCmd":         ccDesc,
+					"tidyCmd":          tidyCmd,
+					"tidyFlags":        shareFlags("tidyFlags", config.TidyFlagsForSrcFile(srcFile, flags.tidyFlags)),
+					"tidyVars":         tidyVars,                            // short and not shared
+					"tidyWrapperFlags": strings.Join(tidyWrapperFlags, " "), // short and not shared
 				},
 			})
 		}
@@ -737,6 +866,7 @@ func transformSourceToObj(ctx ModuleContext, subdir string, srcFiles, noTidySrcs
 		objFiles:      objFiles,
 		tidyFiles:     tidyFiles,
 		tidyDepFiles:  tidyDepFiles,
+		tidyFindings:  tidyFindings,
 		coverageFiles: coverageFiles,
 		sAbiDumpFiles: sAbiDumpFiles,
 		kytheFiles:    kytheFiles,
@@ -748,7 +878,7 @@ func transformObjToStaticLib(ctx android.ModuleContext,
 	objFiles android.Paths, wholeStaticLibs android.Paths,
 	flags builderFlags, outputFile android.ModuleOutPath, deps android.Paths, validations android.Paths) {
 
-	arCmd := "${config.ClangBin}/llvm-ar"
+	arCmd := flags.clangBinDir() + "/llvm-ar"
 	arFlags := ""
 	if !ctx.Darwin() {
 		arFlags += " --format=gnu"
@@ -786,6 +916,29 @@ func transformObjToStaticLib(ctx android.ModuleContext,
 	}
 }
 
+var (
+	// The estimated peak memory of links from their number of inputs, see android.UseHighmemPool.
+	linkMemory    = android.ActionMemoryModel{Name: "link", BaseMB: 256, PerInputKB: 256}
+	ltoLinkMemory = android.ActionMemoryModel{Name: "lto_link", BaseMB: 1024, PerInputKB: 2048}
+)
+
+// linkMemoryModel returns the memory model of a link with the given flags.
+func linkMemoryModel(ldFlags string) android.ActionMemoryModel {
+	if isLtoLink(ldFlags) {
+		return ltoLinkMemory
+	}
+	return linkMemory
+}
+
+func isLtoLink(ldFlags string) bool {
+	for _, flag := range strings.Fields(ldFlags) {
+		if flag == "-flto" || strings.HasPrefix(flag, "-flto=") {
+			return true
+		}
+	}
+	return false
+}
+
 // Generate a rule for compiling multiple .o files, plus static libraries, whole static libraries,
 // and shared libraries, to a shared library (.so) or dynamic executable
 func transformObjToDynamicBinary(ctx android.ModuleContext,
@@ -793,7 +946,13 @@ func transformObjToDynamicBinary(ctx android.ModuleContext,
 	groupLate bool, flags builderFlags, outputFile android.WritablePath,
 	implicitOutputs android.WritablePaths, validations android.Paths) {
 
-	ldCmd := "${config.ClangBin}/clang++"
+	if flags.distributedThinLTO && transformObjToDynamicBinaryWithThinLTOBackend(ctx, objFiles, sharedLibs,
+		staticLibs, lateStaticLibs, wholeStaticLibs, deps, crtBegin, crtEnd, groupLate, flags, outputFile,
+		implicitOutputs, validations) {
+		return
+	}
+
+	ldCmd := flags.clangBinDir() + "/clang++"
 
 	var libFlagsList []string
 
@@ -848,6 +1007,8 @@ func transformObjToDynamicBinary(ctx android.ModuleContext,
 		rule = ldRE
 		args["implicitOutputs"] = strings.Join(implicitOutputs.Strings(), ",")
 		args["implicitInputs"] = strings.Join(deps.Strings(), ",")
+	} else if android.UseHighmemPool(ctx, linkMemoryModel(args["ldFlags"]), outputFile, len(objFiles)+len(deps)) {
+		rule = ldHighmem
 	}
 
 	ctx.Build(pctx, android.BuildParams{
@@ -863,6 +1024,180 @@ func transformObjToDynamicBinary(ctx android.ModuleContext,
 	})
 }
 
+// transformObjToDynamicBinaryWithThinLTOBackend generates the rules of a distributed ThinLTO link: a
+// thin link of the objects and of the objects of the static libraries, a backend action for each of
+// them and the final link of the native objects. The objects of static libraries are linked with
+// --start-lib and --end-lib instead of the static libraries, so that the rules are known before the
+// thin link runs. Static libraries whose objects are unknown, like prebuilts, are linked as they are
+// and must not contain bitcode. It returns false if the module can't use distributed ThinLTO, in
+// which case it is linked with transformObjToDynamicBinary.
+func transformObjToDynamicBinaryWithThinLTOBackend(ctx android.ModuleContext,
+	objFiles, sharedLibs, staticLibs, lateStaticLibs, wholeStaticLibs, deps, crtBegin, crtEnd android.Paths,
+	groupLate bool, flags builderFlags, outputFile android.WritablePath,
+	implicitOutputs android.WritablePaths, validations android.Paths) bool {
+
+	ldFlags := flags.globalLdFlags + " " + flags.localLdFlags
+	excludedLibs := excludedLibsFromLdFlags(ldFlags)
+
+	archiveObjects := thinLTOArchiveObjects(ctx.Config())
+	expand := func(archives android.Paths) (android.Paths, android.Paths, bool) {
+		var objs, kept android.Paths
+		for _, archive := range archives {
+			v, ok := archiveObjects.Load(archive.String())
+			if !ok {
+				kept = append(kept, archive)
+				continue
+			}
+			if excludedLibs["ALL"] || excludedLibs[archive.Base()] {
+				// --exclude-libs only applies to the symbols of archives.
+				return nil, nil, false
+			}
+			objs = append(objs, v.(android.Paths)...)
+		}
+		return objs, kept, true
+	}
+	wholeObjs, keptWholeStaticLibs, ok := expand(wholeStaticLibs)
+	if !ok {
+		return false
+	}
+	lazyObjs, keptStaticLibs, ok := expand(staticLibs)
+	if !ok {
+		return false
+	}
+	lateLazyObjs, keptLateStaticLibs, ok := expand(lateStaticLibs)
+	if !ok {
+		return false
+	}
+	lazyObjs = append(lazyObjs, lateLazyObjs...)
+
+	objFiles = append(android.CopyOfPaths(objFiles), wholeObjs...)
+	objFiles = android.FirstUniquePaths(objFiles)
+	lazyObjs, _ = android.FilterPathList(android.FirstUniquePaths(lazyObjs), objFiles)
+
+	startLib := func(objs android.Paths) string {
+		if len(objs) == 0 {
+			return ""
+		}
+		return "-Wl,--start-lib " + strings.Join(objs.Strings(), " ") + " -Wl,--end-lib"
+	}
+	joinFlags := func(list ...string) string {
+		return strings.Join(android.RemoveListFromList(list, []string{""}), " ")
+	}
+
+	// The thin link.
+	ldCmd := flags.clangBinDir() + "/clang++"
+	thinLinkOutput := android.PathForModuleOut(ctx, "thinlto", outputFile.Base()+".thinlink")
+	indexDir := android.PathForModuleOut(ctx, "thinlto", "index")
+	var libFlagsList []string
+	if len(keptWholeStaticLibs) > 0 {
+		libFlagsList = append(libFlagsList, "-Wl,--whole-archive", strings.Join(keptWholeStaticLibs.Strings(), " "),
+			"-Wl,--no-whole-archive")
+	}
+	libFlagsList = append(libFlagsList, strings.Join(keptStaticLibs.Strings(), " "))
+	if groupLate && len(keptLateStaticLibs) > 0 {
+		libFlagsList = append(libFlagsList, "-Wl,--start-group", strings.Join(keptLateStaticLibs.Strings(), " "),
+			"-Wl,--end-group")
+	} else {
+		libFlagsList = append(libFlagsList, strings.Join(keptLateStaticLibs.Strings(), " "))
+	}
+	libFlagsList = append(libFlagsList, strings.Join(sharedLibs.Strings(), " "))
+
+	var indexFiles android.WritablePaths
+	var backendObjs, backendLazyObjs android.Paths
+	backendFlags := thinLTOBackendFlags(flags.toolchain, ldFlags)
+	backend := func(obj android.Path) android.Path {
+		// The thin link writes the index files of an object to its path prefixed with the index
+		// directory, which is the same path for absolute paths.
+		rel := strings.TrimPrefix(obj.String(), "/")
+		index := android.PathForModuleOut(ctx, "thinlto", "index", rel+".thinlto.bc")
+		imports := android.PathForModuleOut(ctx, "thinlto", "index", rel+".imports")
+		indexFiles = append(indexFiles, index, imports)
+		native := android.PathForModuleOut(ctx, "thinlto", "obj", rel)
+
+		rule := thinLTOBackend
+		args := map[string]string{
+			"ccCmd":        flags.clangBinDir() + "/clang",
+			"index":        index.String(),
+			"backendFlags": backendFlags,
+		}
+		if ctx.Config().UseRBE() && ctx.Config().IsEnvTrue("RBE_THINLTO_BACKEND") {
+			rule = thinLTOBackendRE
+			args["imports"] = imports.String()
+		}
+		ctx.Build(pctx, android.BuildParams{
+			Rule:        rule,
+			Description: "thinlto backend " + obj.Base(),
+			Output:      native,
+			Input:       obj,
+			Implicits:   android.Paths{index, imports},
+			// The imported bitcode objects are read with the index.
+			OrderOnly: append(android.CopyOfPaths(objFiles), lazyObjs...),
+			Args:      args,
+		})
+		return native
+	}
+	for _, obj := range objFiles {
+		backendObjs = append(backendObjs, backend(obj))
+	}
+	for _, obj := range lazyObjs {
+		backendLazyObjs = append(backendLazyObjs, backend(obj))
+	}
+
+	thinLinkDeps := append(android.CopyOfPaths(deps), lazyObjs...)
+	thinLinkDeps = append(thinLinkDeps, keptWholeStaticLibs...)
+	thinLinkDeps = append(thinLinkDeps, keptStaticLibs...)
+	thinLinkDeps = append(thinLinkDeps, keptLateStaticLibs...)
+	thinLinkDeps = append(thinLinkDeps, crtBegin...)
+	thinLinkDeps = append(thinLinkDeps, crtEnd...)
+
+	rule := thinLink
+	args := map[string]string{
+		"ldCmd":         ldCmd,
+		"crtBegin":      strings.Join(crtBegin.Strings(), " "),
+		"libFlags":      joinFlags(flags.libFlags, startLib(lazyObjs), joinFlags(libFlagsList...)),
+		"extraLibFlags": flags.extraLibFlags,
+		"ldFlags":       ldFlags,
+		"crtEnd":        strings.Join(crtEnd.Strings(), " "),
+		"indexDir":      indexDir.String(),
+	}
+	if ctx.Config().UseRBE() && ctx.Config().IsEnvTrue("RBE_CXX_LINKS") {
+		rule = thinLinkRE
+		args["implicitOutputs"] = strings.Join(indexFiles.Strings(), ",")
+		args["implicitInputs"] = strings.Join(thinLinkDeps.Strings(), ",")
+	}
+	ctx.Build(pctx, android.BuildParams{
+		Rule:            rule,
+		Description:     "thin link " + outputFile.Base(),
+		Output:          thinLinkOutput,
+		ImplicitOutputs: indexFiles,
+		Inputs:          objFiles,
+		Implicits:       thinLinkDeps,
+		OrderOnly:       sharedLibs,
+		Args:            args,
+	})
+
+	// The final link of the native objects.
+	nativeFlags := flags
+	nativeFlags.distributedThinLTO = false
+	nativeFlags.libFlags = joinFlags(flags.libFlags, startLib(backendLazyObjs))
+	// The final link doesn't run LTO.
+	removeLto := func(ldFlags string) string {
+		var ret []string
+		for _, flag := range strings.Fields(ldFlags) {
+			if !isLtoLink(flag) {
+				ret = append(ret, flag)
+			}
+		}
+		return strings.Join(ret, " ")
+	}
+	nativeFlags.globalLdFlags = removeLto(flags.globalLdFlags)
+	nativeFlags.localLdFlags = removeLto(flags.localLdFlags)
+	transformObjToDynamicBinary(ctx, backendObjs, sharedLibs, keptStaticLibs, keptLateStaticLibs,
+		keptWholeStaticLibs, append(android.CopyOfPaths(deps), backendLazyObjs...), crtBegin, crtEnd, groupLate,
+		nativeFlags, outputFile, implicitOutputs, validations)
+	return true
+}
+
 // Generate a rule to combine .dump sAbi dump files from multiple source files
 // into a single .ldump sAbi dump file
 func transformDumpToLinkedDump(ctx android.ModuleContext, sAbiDumps android.Paths, soFile android.Path,
@@ -968,7 +1303,7 @@ func TransformSharedObjectToToc(ctx android.ModuleContext, inputFile android.Pat
 func transformObjsToObj(ctx android.ModuleContext, objFiles android.Paths,
 	flags builderFlags, outputFile android.WritablePath, deps android.Paths) {
 
-	ldCmd := "${config.ClangBin}/clang++"
+	ldCmd := flags.clangBinDir() + "/clang++"
 
 	rule := partialLd
 	args := map[string]string{
@@ -1008,9 +1343,10 @@ func transformBinaryPrefixSymbols(ctx android.ModuleContext, prefix string, inpu
 	})
 }
 
-// Registers a build statement to invoke `strip` (to discard symbols and data from object files).
+// Registers a build statement to invoke `strip` (to discard symbols and data from object files),
+// and returns the path of the stripped file.
 func transformStrip(ctx android.ModuleContext, inputFile android.Path,
-	outputFile android.WritablePath, flags StripFlags) {
+	outputFile android.WritablePath, flags StripFlags, contentKeyed bool) android.Path {
 
 	args := ""
 	if flags.StripAddGnuDebuglink {
@@ -1029,7 +1365,7 @@ func transformStrip(ctx android.ModuleContext, inputFile android.Path,
 		args += " --keep-symbols-and-debug-frame"
 	}
 
-	ctx.Build(pctx, android.BuildParams{
+	params := android.BuildParams{
 		Rule:        strip,
 		Description: "strip " + outputFile.Base(),
 		Output:      outputFile,
@@ -1037,6 +1373,45 @@ func transformStrip(ctx android.ModuleContext, inputFile android.Path,
 		Args: map[string]string{
 			"args": args,
 		},
+	}
+	if contentKeyed {
+		return android.BuildContentKeyed(ctx, pctx, params)
+	}
+	ctx.Build(pctx, params)
+	return outputFile
+}
+
+// Registers a build statement to split the debug info of an unstripped file into a zip of a
+// .debug file named after its build id, and to write its build id to buildIdFile.
+func transformSplitDebugInfo(ctx android.ModuleContext, inputFile android.Path,
+	outputFile android.WritablePath, buildIdFile android.WritablePath) {
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:           splitDebugInfo,
+		Description:    "split debug info " + inputFile.Base(),
+		Output:         outputFile,
+		ImplicitOutput: buildIdFile,
+		Input:          inputFile,
+		Args: map[string]string{
+			"outDir":  android.PathForModuleOut(ctx, "debug", "build-id").String(),
+			"buildId": buildIdFile.String(),
+		},
+	})
+}
+
+// Registers a build statement to generate the Breakpad symbols of an unstripped file into a zip.
+func transformBreakpadSymbols(ctx android.ModuleContext, inputFile android.Path,
+	outputFile android.WritablePath) {
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        breakpadSymbols,
+		Description: "dump_syms " + inputFile.Base(),
+		Output:      outputFile,
+		Input:       inputFile,
+		Args: map[string]string{
+			"outDir": android.PathForModuleOut(ctx, "breakpad", "symbols").String(),
+			"name":   inputFile.Base(),
+		},
 	})
 }
 
@@ -21,6 +21,7 @@ package cc
 import (
 	"fmt"
 	"io"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -44,6 +45,7 @@ func init() {
 
 func RegisterCCBuildComponents(ctx android.RegistrationContext) {
 	ctx.RegisterModuleType("cc_defaults", defaultsFactory)
+	ctx.RegisterModuleType("cc_cross_sysroot", crossSysrootFactory)
 
 	ctx.PreDepsMutators(func(ctx android.RegisterMutatorsContext) {
 		ctx.BottomUp("sdk", sdkMutator).Parallel()
@@ -83,6 +85,15 @@ func RegisterCCBuildComponents(ctx android.RegistrationContext) {
 	})
 
 	ctx.RegisterSingletonType("kythe_extract_all", kytheExtractAllFactory)
+	ctx.RegisterSingletonType("generated_header_include_dirs", generatedHeaderIncludeDirsSingletonFactory)
+	ctx.RegisterSingletonType("lto_report", ltoReportSingletonFactory)
+	ctx.RegisterSingletonType("cc_flag_policy", flagPolicySingletonFactory)
+	ctx.RegisterSingletonType("header_libraries_report", headerLibrariesReportSingletonFactory)
+	ctx.RegisterSingletonType("hardening_report", hardeningReportSingletonFactory)
+	ctx.RegisterSingletonType("symbol_upload_manifest", symbolUploadManifestSingletonFactory)
+	ctx.RegisterSingletonType("breakpad_symbols", breakpadSymbolsSingletonFactory)
+	ctx.RegisterSingletonType("host_pkgconfig", hostPkgConfigSingletonFactory)
+	ctx.RegisterSingletonType("tidy_report", tidyReportSingletonFactory)
 }
 
 // Deps is a struct containing module names of dependencies, separated by the kind of dependency.
@@ -225,6 +236,15 @@ type Flags struct {
 	SAbiDump      bool // True if header abi dumps should be generated.
 	EmitXrefs     bool // If true, generate Ninja rules to generate emitXrefs input files for Kythe
 
+	DistributedThinLTO bool // True if the ThinLTO backend runs in separate actions instead of in the linker.
+
+	// The bin directory of the clang release that compiles and links the module.
+	ClangBin string
+
+	// The baseline of the clang-tidy findings to suppress, the tidy-baseline.txt file of the
+	// directory of the module if it exists.
+	TidyBaseline android.OptionalPath
+
 	// The instruction set required for clang ("arm" or "thumb").
 	RequiredInstructionSet string
 	// The target-device system path to the dynamic linker.
@@ -249,6 +269,11 @@ type BaseProperties struct {
 	// Deprecated. true is the default, false is invalid.
 	Clang *bool `android:"arch_variant"`
 
+	// The clang release in prebuilts/clang/host that compiles and links the module, one of the
+	// releases that the build allows. Defaults to the DefaultClangVersion of the product, or to the
+	// clang of the build.
+	Clang_version *string
+
 	// The API level that this module is built against. The APIs of this API level will be
 	// visible at build time, but use of any APIs newer than min_sdk_version will render the
 	// module unloadable on older devices.  In the future it will be possible to weakly-link new
@@ -558,6 +583,12 @@ type feature interface {
 	props() []interface{}
 }
 
+// featureWithDeps is a feature that adds dependencies of its own in the DepsMutator.
+type featureWithDeps interface {
+	feature
+	deps(ctx DepsContext)
+}
+
 // compiler is the interface for a compiler helper object. Different module decorators may implement
 // this helper differently.
 type compiler interface {
@@ -722,6 +753,22 @@ func (d libraryDependencyTag) LicenseAnnotations() []android.LicenseAnnotation {
 
 var _ android.LicenseAnnotationsDependencyTag = libraryDependencyTag{}
 
+// DependencyCycleHint suggests how to break a dependency cycle through a library dependency.
+func (d libraryDependencyTag) DependencyCycleHint() string {
+	switch {
+	case d.shared():
+		return "if only the API of the library is needed, depend on its stubs or move the " +
+			"declarations to a cc_library_headers module in header_libs"
+	case d.static():
+		return "if only the headers of the library are needed, use header_libs instead of static_libs"
+	case d.header():
+		return "move the headers that both modules need to a separate cc_library_headers module"
+	}
+	return ""
+}
+
+var _ android.DependencyCycleHint = libraryDependencyTag{}
+
 // InstallDepNeeded returns true for shared libraries so that shared library dependencies of
 // binaries or other shared libraries are installed as dependencies.
 func (d libraryDependencyTag) InstallDepNeeded() bool {
@@ -879,6 +926,14 @@ type Module struct {
 	objFiles android.Paths
 	// Tidy .tidy file output paths for this compilation module
 	tidyFiles android.Paths
+	// Tidy .tidy.findings file output paths for this compilation module, for the tidy report
+	tidyFindings android.Paths
+	// Include directories in the output directory exported by dependencies of this module
+	outputIncludeDirs []outputIncludeDir
+	// Zip of the Breakpad symbols of this module, if they are generated
+	breakpadSymbolsZip android.WritablePath
+	// Zip of the library, its exported headers and its pkg-config file, if they are generated
+	pkgConfigZip android.WritablePath
 
 	// For apex variants, this is set as apex.min_sdk_version
 	apexSdkVersion android.ApiLevel
@@ -1034,6 +1089,14 @@ func (c *Module) MinSdkVersion() string {
 	return String(c.Properties.Min_sdk_version)
 }
 
+// Implements android.MinSdkVersionExplainer
+func (c *Module) ExplainMinSdkVersion(ctx android.EarlyModuleContext) (string, string) {
+	if c.MinSdkVersion() != "" {
+		return c.MinSdkVersion(), "min_sdk_version"
+	}
+	return c.SdkVersion(), "sdk_version"
+}
+
 func (c *Module) isCrt() bool {
 	if linker, ok := c.linker.(*objectLinker); ok {
 		return linker.isCrt()
@@ -1743,6 +1806,8 @@ func newModule(hod android.HostOrDeviceSupported, multilib android.Multilib) *Mo
 	module := newBaseModule(hod, multilib)
 	module.features = []feature{
 		&tidyFeature{},
+		&hardeningFeature{},
+		&crossSysrootFeature{},
 	}
 	module.stl = &stl{}
 	module.sanitize = &sanitize{}
@@ -1791,6 +1856,18 @@ func (c *Module) IsTestPerSrcAllTestsVariation() bool {
 	return ok && test.isAllTestsVariation()
 }
 
+// TestSuites returns the compatibility test suites of a test or benchmark module.
+func (c *Module) TestSuites() []string {
+	if test, ok := c.linker.(interface {
+		testSuites() []string
+	}); ok {
+		return test.testSuites()
+	}
+	return nil
+}
+
+var _ android.TestSuiteModule = (*Module)(nil)
+
 func (c *Module) DataPaths() []android.DataPath {
 	if p, ok := c.installer.(interface {
 		dataPaths() []android.DataPath
@@ -2009,6 +2086,8 @@ func (c *Module) GenerateAndroidBuildActions(actx android.ModuleContext) {
 
 	flags := Flags{
 		Toolchain: c.toolchain(ctx),
+		ClangBin: config.ClangBinForVersion(
+			config.ClangVersions.Select(ctx, c.Properties.Clang_version, ctx.Config().DefaultClangVersion())),
 		EmitXrefs: ctx.Config().EmitXrefRules(),
 	}
 	if c.compiler != nil {
@@ -2077,6 +2156,7 @@ func (c *Module) GenerateAndroidBuildActions(actx android.ModuleContext) {
 		c.kytheFiles = objs.kytheFiles
 		c.objFiles = objs.objFiles
 		c.tidyFiles = objs.tidyFiles
+		c.tidyFindings = objs.tidyFindings
 	}
 
 	if c.linker != nil {
@@ -2086,6 +2166,9 @@ func (c *Module) GenerateAndroidBuildActions(actx android.ModuleContext) {
 		}
 		c.outputFile = android.OptionalPathForPath(outputFile)
 
+		c.maybeGenerateBreakpadSymbols(ctx)
+		c.maybeGeneratePkgConfig(ctx)
+
 		c.maybeUnhideFromMake()
 
 		// glob exported headers for snapshot, if BOARD_VNDK_VERSION is current or
@@ -2669,6 +2752,12 @@ func (c *Module) DepsMutator(actx android.BottomUpMutatorContext) {
 		actx.AddDependency(c, dynamicLinkerDepTag, deps.DynamicLinker)
 	}
 
+	for _, feature := range c.features {
+		if f, ok := feature.(featureWithDeps); ok {
+			f.deps(ctx)
+		}
+	}
+
 	version := ctx.sdkVersion()
 
 	ndkStubDepTag := libraryDependencyTag{Kind: sharedLibraryDependency, ndk: true, makeSuffix: "." + version}
@@ -3024,6 +3113,7 @@ func (c *Module) depsToPaths(ctx android.ModuleContext) PathDeps {
 						genRule.GeneratedDeps()...)
 					dirs := genRule.GeneratedHeaderDirs()
 					depPaths.IncludeDirs = append(depPaths.IncludeDirs, dirs...)
+					c.recordOutputIncludeDirs(ctx, depName, dirs)
 					if depTag == genHeaderExportDepTag {
 						depPaths.ReexportedDirs = append(depPaths.ReexportedDirs, dirs...)
 						depPaths.ReexportedGeneratedHeaders = append(depPaths.ReexportedGeneratedHeaders,
@@ -3149,6 +3239,10 @@ func (c *Module) depsToPaths(ctx android.ModuleContext) PathDeps {
 					return
 				}
 
+				if sanitizeableDep, ok := ccDep.(PlatformSanitizeable); ok {
+					CheckLinkUnitSanitizers(ctx, c, sanitizeableDep, depName)
+				}
+
 				// Stubs lib doesn't link to the static lib dependencies. Don't set
 				// linkFile, depFile, and ptr.
 				if c.IsStubs() {
@@ -3241,6 +3335,8 @@ func (c *Module) depsToPaths(ctx android.ModuleContext) PathDeps {
 
 			depPaths.IncludeDirs = append(depPaths.IncludeDirs, depExporterInfo.IncludeDirs...)
 			depPaths.SystemIncludeDirs = append(depPaths.SystemIncludeDirs, depExporterInfo.SystemIncludeDirs...)
+			c.recordOutputIncludeDirs(ctx, depName, depExporterInfo.IncludeDirs)
+			c.recordOutputIncludeDirs(ctx, depName, depExporterInfo.SystemIncludeDirs)
 			depPaths.GeneratedDeps = append(depPaths.GeneratedDeps, depExporterInfo.Deps...)
 			depPaths.Flags = append(depPaths.Flags, depExporterInfo.Flags...)
 
@@ -3588,6 +3684,35 @@ func (c *Module) OutputFiles(tag string) (android.Paths, error) {
 	}
 }
 
+var _ android.SourceVisibilityReferencer = (*Module)(nil)
+
+// SourceVisibilityDirs returns the include directories of the module, so that headers from other
+// packages can't be used without them being visible to this module.
+func (c *Module) SourceVisibilityDirs(ctx android.BaseModuleContext) []string {
+	var dirs []string
+	var props []interface{}
+	if c.compiler != nil {
+		props = append(props, c.compiler.compilerProps()...)
+	}
+	if c.linker != nil {
+		props = append(props, c.linker.linkerProps()...)
+	}
+	for _, p := range props {
+		switch p := p.(type) {
+		case *BaseCompilerProperties:
+			dirs = append(dirs, p.Include_dirs...)
+			for _, dir := range p.Local_include_dirs {
+				dirs = append(dirs, filepath.Join(ctx.ModuleDir(), dir))
+			}
+		case *FlagExporterProperties:
+			for _, dir := range append(p.Export_include_dirs, p.Export_system_include_dirs...) {
+				dirs = append(dirs, filepath.Join(ctx.ModuleDir(), dir))
+			}
+		}
+	}
+	return android.FirstUniqueStrings(dirs)
+}
+
 func (c *Module) static() bool {
 	if static, ok := c.linker.(interface {
 		static() bool
@@ -3677,6 +3802,51 @@ func (c *Module) Object() bool {
 	return false
 }
 
+var _ android.ApiLockContributor = (*Module)(nil)
+
+// ApiLockLinkType returns how consumers link against this variant of the module, for API lock
+// files.
+func (c *Module) ApiLockLinkType() string {
+	if c.Header() {
+		return "header"
+	}
+	if library := moduleLibraryInterface(c); library != nil {
+		if library.static() {
+			return "static"
+		} else if library.shared() {
+			return "shared"
+		}
+	}
+	if c.Object() {
+		return "object"
+	}
+	if c.Binary() {
+		return "executable"
+	}
+	return ""
+}
+
+// ApiLockStability returns the stability guarantees of this variant of the module, for API lock
+// files.
+func (c *Module) ApiLockStability() []string {
+	var stability []string
+	if c.IsLlndk() {
+		stability = append(stability, "llndk")
+	}
+	if c.IsVndkSp() {
+		stability = append(stability, "vndk-sp")
+	} else if c.IsVndk() {
+		stability = append(stability, "vndk")
+	}
+	if Bool(c.VendorProperties.Vendor_available) {
+		stability = append(stability, "vendor_available")
+	}
+	if Bool(c.VendorProperties.Product_available) {
+		stability = append(stability, "product_available")
+	}
+	return stability
+}
+
 func GetMakeLinkType(actx android.ModuleContext, c LinkableInterface) string {
 	if c.UseVndk() {
 		if c.IsLlndk() {
@@ -3977,6 +4147,8 @@ func (c *Module) ConvertWithBp2build(ctx android.TopDownMutatorContext) {
 	case testBin:
 		if !prebuilt {
 			testBinaryBp2build(ctx, c)
+		} else {
+			ctx.MarkBp2buildUnconvertible(android.UnconvertedReasonTypeUnsupported, "")
 		}
 	case object:
 		if prebuilt {
@@ -4004,6 +4176,8 @@ func (c *Module) ConvertWithBp2build(ctx android.TopDownMutatorContext) {
 		} else {
 			sharedOrStaticLibraryBp2Build(ctx, c, false)
 		}
+	default:
+		ctx.MarkBp2buildUnconvertible(android.UnconvertedReasonTypeUnsupported, "")
 	}
 }
 
@@ -4074,6 +4248,7 @@ func DefaultsFactory(props ...interface{}) android.Module {
 		&LTOProperties{},
 		&AfdoProperties{},
 		&PgoProperties{},
+		&CrossSysrootProperties{},
 		&android.ProtoProperties{},
 		// RustBindgenProperties is included here so that cc_defaults can be used for rust_bindgen modules.
 		&RustBindgenClangProperties{},
@@ -4110,6 +4285,8 @@ func (ks *kytheExtractAllSingleton) GenerateBuildActions(ctx android.SingletonCo
 	})
 	// TODO(asmundak): Perhaps emit a rule to output a warning if there were no xrefTargets
 	if len(xrefTargets) > 0 {
+		// Link the generated sources in the compilation units to their origin.
+		xrefTargets = append(xrefTargets, android.GeneratedSourcesXrefPath(ctx))
 		ctx.Phony("xref_cxx", xrefTargets...)
 	}
 }
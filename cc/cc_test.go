@@ -5228,3 +5228,133 @@ func TestDclaLibraryInApex(t *testing.T) {
 	expectedOutputFiles := []string{"outputbase/execroot/__main__/foo.so"}
 	android.AssertDeepEquals(t, "output files", expectedOutputFiles, outputFiles.Strings())
 }
+
+func TestCompilerCache(t *testing.T) {
+	t.Parallel()
+	bp := `
+		cc_library_static {
+			name: "libfoo",
+			srcs: ["foo.c"],
+		}
+	`
+
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:     "sccache",
+			env:      map[string]string{"SOONG_COMPILER_CACHE": "sccache", "SOONG_COMPILER_CACHE_EXEC": "/usr/bin/sccache"},
+			expected: "/usr/bin/sccache ",
+		},
+		{
+			name:     "USE_CCACHE",
+			env:      map[string]string{"USE_CCACHE": "true", "CCACHE_EXEC": "/usr/bin/ccache"},
+			expected: "/usr/bin/ccache ",
+		},
+		{
+			name: "CC_WRAPPER",
+			env: map[string]string{
+				"SOONG_COMPILER_CACHE":      "sccache",
+				"SOONG_COMPILER_CACHE_EXEC": "/usr/bin/sccache",
+				"CC_WRAPPER":                "/usr/bin/wrapper",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := android.GroupFixturePreparers(
+				prepareForCcTest,
+				android.FixtureMergeEnv(tc.env),
+			).RunTestWithBp(t, bp)
+
+			rule := result.ModuleForTests("libfoo", "android_arm64_armv8-a_static").Rule("cc")
+			android.AssertStringEquals(t, "compiler cache", tc.expected, rule.Args["compilerCache"])
+		})
+	}
+}
+
+func TestClangVersion(t *testing.T) {
+	t.Parallel()
+	bp := `
+		cc_library_shared {
+			name: "libfoo",
+			srcs: ["foo.c"],
+		}
+
+		cc_library_shared {
+			name: "libbar",
+			srcs: ["bar.c"],
+			clang_version: "clang-r498229b",
+		}
+	`
+
+	testCases := []struct {
+		name           string
+		productDefault *string
+		foo, bar       string
+	}{
+		{
+			name: "build default",
+			foo:  "${config.ClangBin}",
+			bar:  "${config.ClangBin_clang_r498229b}",
+		},
+		{
+			name:           "product default",
+			productDefault: StringPtr("clang-r498229b"),
+			foo:            "${config.ClangBin_clang_r498229b}",
+			bar:            "${config.ClangBin_clang_r498229b}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := android.GroupFixturePreparers(
+				prepareForCcTest,
+				android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+					variables.DefaultClangVersion = tc.productDefault
+				}),
+			).RunTestWithBp(t, bp)
+
+			for module, clangBin := range map[string]string{"libfoo": tc.foo, "libbar": tc.bar} {
+				m := result.ModuleForTests(module, "android_arm64_armv8-a_shared")
+				android.AssertStringEquals(t, module+" ccCmd", clangBin+"/clang", m.Rule("cc").Args["ccCmd"])
+				android.AssertStringEquals(t, module+" ldCmd", clangBin+"/clang++", m.Rule("ld").Args["ldCmd"])
+			}
+		})
+	}
+}
+
+func TestClangVersionNotAllowed(t *testing.T) {
+	t.Parallel()
+	prepareForCcTest.
+		ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+			`clang_version: clang version "clang-r1" is not one of the allowed versions`)).
+		RunTestWithBp(t, `
+			cc_library_shared {
+				name: "libfoo",
+				srcs: ["foo.c"],
+				clang_version: "clang-r1",
+			}
+		`)
+
+	android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.DefaultClangVersion = StringPtr("clang-r1")
+		}),
+	).
+		ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+			`product variable DefaultClangVersion: clang version "clang-r1" is not one of the allowed versions`)).
+		RunTestWithBp(t, `
+			cc_library_shared {
+				name: "libfoo",
+				srcs: ["foo.c"],
+			}
+		`)
+}
@@ -360,6 +360,16 @@ func (compiler *baseCompiler) compilerFlags(ctx ModuleContext, flags Flags, deps
 	CheckBadCompilerFlags(ctx, "vendor_ramdisk.cflags", compiler.Properties.Target.Vendor_ramdisk.Cflags)
 	CheckBadCompilerFlags(ctx, "platform.cflags", compiler.Properties.Target.Platform.Cflags)
 
+	checkFlagPolicy(ctx, "cflags", compiler.Properties.Cflags, restrictedCompilerFlags)
+	checkFlagPolicy(ctx, "cppflags", compiler.Properties.Cppflags, restrictedCompilerFlags)
+	checkFlagPolicy(ctx, "conlyflags", compiler.Properties.Conlyflags, restrictedCompilerFlags)
+	checkFlagPolicy(ctx, "asflags", compiler.Properties.Asflags, restrictedCompilerFlags)
+	checkFlagPolicy(ctx, "vendor.cflags", compiler.Properties.Target.Vendor.Cflags, restrictedCompilerFlags)
+	checkFlagPolicy(ctx, "product.cflags", compiler.Properties.Target.Product.Cflags, restrictedCompilerFlags)
+	checkFlagPolicy(ctx, "recovery.cflags", compiler.Properties.Target.Recovery.Cflags, restrictedCompilerFlags)
+	checkFlagPolicy(ctx, "vendor_ramdisk.cflags", compiler.Properties.Target.Vendor_ramdisk.Cflags, restrictedCompilerFlags)
+	checkFlagPolicy(ctx, "platform.cflags", compiler.Properties.Target.Platform.Cflags, restrictedCompilerFlags)
+
 	esc := proptools.NinjaAndShellEscapeList
 
 	flags.Local.CFlags = append(flags.Local.CFlags, esc(compiler.Properties.Cflags)...)
@@ -390,7 +400,9 @@ func (compiler *baseCompiler) compilerFlags(ctx ModuleContext, flags Flags, deps
 		flags.Local.YasmFlags = append(flags.Local.YasmFlags, "-I"+modulePath)
 	}
 
-	if !(ctx.useSdk() || ctx.useVndk()) || ctx.Host() {
+	// The global includes are Android headers, modules built against a cc_cross_sysroot use the
+	// headers of the sysroot instead.
+	if (!(ctx.useSdk() || ctx.useVndk()) || ctx.Host()) && !config.CrossSysrootOs(ctx.Os()) {
 		flags.SystemIncludeFlags = append(flags.SystemIncludeFlags,
 			"${config.CommonGlobalIncludes}",
 			tc.IncludeFlags())
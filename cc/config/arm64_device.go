@@ -122,6 +122,17 @@ func init() {
 	exportedVars.ExportStringListStaticVariable("Arm64FixCortexA53Ldflags", []string{"-Wl,--fix-cortex-a53-843419"})
 }
 
+// Arm64BranchProtection returns the branch protection, as passed to -mbranch-protection, that
+// code is compiled with by default for the arm64 architecture variant, or "none".
+func Arm64BranchProtection(archVariant string) string {
+	for _, flag := range arm64ArchVariantCflags[archVariant] {
+		if strings.HasPrefix(flag, "-mbranch-protection=") {
+			return strings.TrimPrefix(flag, "-mbranch-protection=")
+		}
+	}
+	return "none"
+}
+
 var (
 	arm64ArchVariantCflagsVar = map[string]string{
 		"armv8-a":            "${config.Arm64Armv8ACflags}",
@@ -0,0 +1,123 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+
+	"android/soong/android"
+)
+
+// The toolchains of the OSes that are built against a cc_cross_sysroot module. The libc, the crt
+// objects and the C++ library all come from the sysroot, which the module passes with --sysroot,
+// so the toolchains only select the target triple.
+
+var (
+	crossSysrootCflags = []string{
+		"-fPIC",
+	}
+
+	crossSysrootLdflags = []string{}
+
+	crossSysrootLldflags = append(crossSysrootLdflags, "-fuse-ld=lld")
+
+	crossSysrootTriples = map[android.OsType]map[android.ArchType]string{
+		android.LinuxEmbedded: {
+			android.Arm64:  "aarch64-linux-gnu",
+			android.X86_64: "x86_64-linux-gnu",
+		},
+		android.Qnx: {
+			android.Arm64:  "aarch64-unknown-nto-qnx",
+			android.X86_64: "x86_64-pc-nto-qnx",
+		},
+	}
+)
+
+func init() {
+	pctx.StaticVariable("CrossSysrootCflags", strings.Join(crossSysrootCflags, " "))
+	pctx.StaticVariable("CrossSysrootLdflags", strings.Join(crossSysrootLdflags, " "))
+	pctx.StaticVariable("CrossSysrootLldflags", strings.Join(crossSysrootLldflags, " "))
+
+	for os, triples := range crossSysrootTriples {
+		for arch, triple := range triples {
+			toolchain := &toolchainCrossSysroot{arch: arch, triple: triple}
+			registerToolchainFactory(os, arch, func(android.Arch) Toolchain {
+				return toolchain
+			})
+		}
+	}
+}
+
+// CrossSysrootOs returns true if the modules of the OS are built against a cc_cross_sysroot
+// module.
+func CrossSysrootOs(os android.OsType) bool {
+	_, ok := crossSysrootTriples[os]
+	return ok
+}
+
+// CrossSysrootTriple returns the target triple of an architecture of an OS that is built against a
+// cc_cross_sysroot module, or "" if the architecture isn't supported.
+func CrossSysrootTriple(os android.OsType, arch android.ArchType) string {
+	return crossSysrootTriples[os][arch]
+}
+
+type toolchainCrossSysroot struct {
+	toolchain64Bit
+	toolchainBase
+	toolchainNoCrt
+
+	arch   android.ArchType
+	triple string
+}
+
+func (t *toolchainCrossSysroot) Name() string {
+	return t.arch.Name
+}
+
+func (t *toolchainCrossSysroot) IncludeFlags() string {
+	return ""
+}
+
+func (t *toolchainCrossSysroot) ClangTriple() string {
+	return t.triple
+}
+
+func (t *toolchainCrossSysroot) Cflags() string {
+	return "${config.CrossSysrootCflags}"
+}
+
+func (t *toolchainCrossSysroot) Cppflags() string {
+	return ""
+}
+
+func (t *toolchainCrossSysroot) Ldflags() string {
+	return "${config.CrossSysrootLdflags}"
+}
+
+func (t *toolchainCrossSysroot) Lldflags() string {
+	return "${config.CrossSysrootLldflags}"
+}
+
+func (t *toolchainCrossSysroot) ShlibSuffix() string {
+	return ".so"
+}
+
+func (t *toolchainCrossSysroot) ExecutableSuffix() string {
+	return ""
+}
+
+func (t *toolchainCrossSysroot) AvailableLibraries() []string {
+	return nil
+}
@@ -309,12 +309,36 @@ var (
 	ClangDefaultVersion      = "clang-r487747c"
 	ClangDefaultShortVersion = "17"
 
+	// ClangVersions are the clang releases in prebuilts/clang/host that cc modules may be built
+	// with. The default is the release of ${ClangBin}, which can be overridden with
+	// LLVM_PREBUILTS_VERSION.
+	ClangVersions = android.ToolchainVersions{
+		Name:            "clang",
+		Property:        "clang_version",
+		ProductVariable: "DefaultClangVersion",
+		Allowed:         []string{ClangDefaultVersion, "clang-r498229b"},
+		Default:         ClangDefaultVersion,
+	}
+
 	// Directories with warnings from Android.bp files.
 	WarningAllowedProjects = []string{
 		"device/",
 		"vendor/",
 	}
 
+	// Directories whose modules may use the restricted flags of cc/flag_policy.go.
+	FlagPolicyAllowedProjects = []string{
+		"bionic/",
+		"external/",
+	}
+
+	// Directories whose modules may define cc_cross_sysroot modules and be built for the OSes of
+	// cc/config/cross_sysroot.go.
+	CrossSysrootAllowedProjects = []string{
+		"device/",
+		"vendor/",
+	}
+
 	VersionScriptFlagPrefix = "-Wl,--version-script,"
 
 	VisibilityHiddenFlag  = "-fvisibility=hidden"
@@ -432,6 +456,9 @@ func init() {
 	exportedVars.ExportStringStaticVariableWithEnvOverride("ClangVersion", "LLVM_PREBUILTS_VERSION", ClangDefaultVersion)
 	pctx.StaticVariable("ClangPath", "${ClangBase}/${HostPrebuiltTag}/${ClangVersion}")
 	pctx.StaticVariable("ClangBin", "${ClangPath}/bin")
+	for _, version := range ClangVersions.Allowed {
+		pctx.StaticVariable(clangBinVariable(version), "${ClangBase}/${HostPrebuiltTag}/"+version+"/bin")
+	}
 
 	exportedVars.ExportStringStaticVariableWithEnvOverride("ClangShortVersion", "LLVM_RELEASE_VERSION", ClangDefaultShortVersion)
 	pctx.StaticVariable("ClangAsanLibDir", "${ClangBase}/linux-x86/${ClangVersion}/lib/clang/${ClangShortVersion}/lib/linux")
@@ -464,6 +491,7 @@ func init() {
 	pctx.StaticVariableWithEnvOverride("REClangTidyExecStrategy", "RBE_CLANG_TIDY_EXEC_STRATEGY", remoteexec.LocalExecStrategy)
 	pctx.StaticVariableWithEnvOverride("REAbiDumperExecStrategy", "RBE_ABI_DUMPER_EXEC_STRATEGY", remoteexec.LocalExecStrategy)
 	pctx.StaticVariableWithEnvOverride("REAbiLinkerExecStrategy", "RBE_ABI_LINKER_EXEC_STRATEGY", remoteexec.LocalExecStrategy)
+	pctx.StaticVariableWithEnvOverride("REThinLTOBackendExecStrategy", "RBE_THINLTO_BACKEND_EXEC_STRATEGY", remoteexec.LocalExecStrategy)
 }
 
 var HostPrebuiltTag = exportedVars.ExportVariableConfigMethod("HostPrebuiltTag", android.Config.PrebuiltOS)
@@ -478,6 +506,18 @@ func ClangPath(ctx android.PathContext, file string) android.SourcePath {
 	})
 }
 
+func clangBinVariable(version string) string {
+	return "ClangBin_" + strings.NewReplacer("-", "_", ".", "_").Replace(version)
+}
+
+// ClangBinForVersion returns the bin directory of a release of ClangVersions.
+func ClangBinForVersion(version string) string {
+	if version == ClangVersions.Default {
+		return "${config.ClangBin}"
+	}
+	return "${config." + clangBinVariable(version) + "}"
+}
+
 var clangPathKey = android.NewOnceKey("clangPath")
 
 func clangPath(ctx android.PathContext) android.SourcePath {
@@ -0,0 +1,109 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+
+	"android/soong/android"
+)
+
+var (
+	wasiCflags = []string{
+		"--sysroot ${WasiSysroot}",
+
+		// WebAssembly has no support for exceptions or threads in the wasi-sdk
+		// sysroot.
+		"-fno-exceptions",
+		"-D_WASI_EMULATED_SIGNAL",
+		"-D_WASI_EMULATED_PROCESS_CLOCKS",
+	}
+
+	wasiCppflags = []string{}
+
+	wasiLdflags = []string{
+		"--sysroot ${WasiSysroot}",
+		"-lwasi-emulated-signal",
+		"-lwasi-emulated-process-clocks",
+	}
+
+	// wasm-ld doesn't support the lld flags shared by the other host toolchains, e.g.
+	// --icf=safe, so wasi links use their own global lld flags.
+	wasiLldflags = append(wasiLdflags, "-fuse-ld=lld")
+)
+
+func init() {
+	pctx.SourcePathVariable("WasiSdkRoot", "prebuilts/wasi-sdk/${HostPrebuiltTag}")
+	pctx.StaticVariable("WasiSysroot", "${WasiSdkRoot}/share/wasi-sysroot")
+
+	pctx.StaticVariable("WasiCflags", strings.Join(wasiCflags, " "))
+	pctx.StaticVariable("WasiCppflags", strings.Join(wasiCppflags, " "))
+	pctx.StaticVariable("WasiLdflags", strings.Join(wasiLdflags, " "))
+	pctx.StaticVariable("WasiLldflags", strings.Join(wasiLldflags, " "))
+
+	registerToolchainFactory(android.Wasi, android.Wasm32, wasiWasm32ToolchainFactory)
+}
+
+type toolchainWasiWasm32 struct {
+	toolchain32Bit
+	toolchainBase
+	toolchainNoCrt
+}
+
+func (t *toolchainWasiWasm32) Name() string {
+	return "wasm32"
+}
+
+func (t *toolchainWasiWasm32) IncludeFlags() string {
+	return ""
+}
+
+func (t *toolchainWasiWasm32) ClangTriple() string {
+	return "wasm32-wasi"
+}
+
+func (t *toolchainWasiWasm32) Cflags() string {
+	return "${config.WasiCflags}"
+}
+
+func (t *toolchainWasiWasm32) Cppflags() string {
+	return "${config.WasiCppflags}"
+}
+
+func (t *toolchainWasiWasm32) Ldflags() string {
+	return "${config.WasiLdflags}"
+}
+
+func (t *toolchainWasiWasm32) Lldflags() string {
+	return "${config.WasiLldflags}"
+}
+
+func (t *toolchainWasiWasm32) ShlibSuffix() string {
+	return ".so"
+}
+
+func (t *toolchainWasiWasm32) ExecutableSuffix() string {
+	return ".wasm"
+}
+
+func (t *toolchainWasiWasm32) AvailableLibraries() []string {
+	return nil
+}
+
+var toolchainWasiWasm32Singleton Toolchain = &toolchainWasiWasm32{}
+
+func wasiWasm32ToolchainFactory(arch android.Arch) Toolchain {
+	return toolchainWasiWasm32Singleton
+}
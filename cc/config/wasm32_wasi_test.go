@@ -0,0 +1,70 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestWasiWasm32Toolchain(t *testing.T) {
+	toolchain, err := findToolchain(android.Wasi, android.Arch{ArchType: android.Wasm32})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"Name", toolchain.Name(), "wasm32"},
+		{"ClangTriple", toolchain.ClangTriple(), "wasm32-wasi"},
+		{"ExecutableSuffix", toolchain.ExecutableSuffix(), ".wasm"},
+		{"Cflags", toolchain.Cflags(), "${config.WasiCflags}"},
+		{"Lldflags", toolchain.Lldflags(), "${config.WasiLldflags}"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if testCase.output != testCase.expected {
+				t.Error("Output doesn't match expected", testCase.output, testCase.expected)
+			}
+		})
+	}
+
+	if toolchain.Is64Bit() {
+		t.Error("wasm32 toolchain is 64-bit")
+	}
+	if toolchain.Bionic() {
+		t.Error("wasm32 toolchain uses Bionic")
+	}
+}
+
+func TestWasiWasm32ToolchainOtherArch(t *testing.T) {
+	if _, err := findToolchain(android.Wasi, android.Arch{ArchType: android.X86_64}); err == nil {
+		t.Error("expected no wasi toolchain for x86_64")
+	}
+}
+
+func TestWasiLldflags(t *testing.T) {
+	if android.InList("-fuse-ld=lld", wasiLdflags) {
+		t.Errorf("wasiLdflags was modified by appending to it: %q", wasiLdflags)
+	}
+	if !android.InList("-fuse-ld=lld", wasiLldflags) {
+		t.Errorf("wasiLldflags doesn't use lld: %q", wasiLldflags)
+	}
+}
@@ -288,3 +288,19 @@ func parseSymbolFileForAPICoverage(ctx ModuleContext, symbolFile string) android
 	rule.Build("native_library_api_list", "Generate native API list based on symbol files for coverage measurement")
 	return parsedApiCoveragePath
 }
+
+// CoverageObjectFile returns the unstripped binary or shared library of a module that is linked
+// with coverage instrumentation, which llvm-cov reads the coverage mapping of to report the
+// coverage of the module and of the static libraries it links.
+func (c *Module) CoverageObjectFile() android.OptionalPath {
+	if c.coverage == nil || !c.coverage.linkCoverage {
+		return android.OptionalPath{}
+	}
+	if library, ok := c.linker.(libraryInterface); ok && !library.shared() {
+		return android.OptionalPath{}
+	}
+	if unstripped := c.UnstrippedOutputFile(); unstripped != nil {
+		return android.OptionalPathForPath(unstripped)
+	}
+	return android.OptionalPath{}
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+// This file builds cc modules for non-Android targets, e.g. embedded Linux systems or QNX for
+// automotive tooling. The libc and the libraries of such a target aren't built in the tree, a
+// cc_cross_sysroot module packages the prebuilt headers and libraries of the target into a sysroot,
+// and the linux_embedded and qnx variants of cc modules are built against it with the
+// cross_sysroot property. Both the sysroots and the modules built against them are limited to the
+// directories of config.CrossSysrootAllowedProjects.
+
+import (
+	"path/filepath"
+	"strings"
+
+	"android/soong/android"
+	"android/soong/cc/config"
+
+	"github.com/google/blueprint"
+)
+
+var crossSysrootDepTag = dependencyTag{name: "cross sysroot"}
+
+// CrossSysrootInfo is provided by cc_cross_sysroot modules.
+type CrossSysrootInfo struct {
+	// The OS and the architecture of the sysroot.
+	Os   android.OsType
+	Arch android.ArchType
+
+	// The root of the sysroot, usable with --sysroot.
+	Dir android.Path
+
+	// A timestamp that depends on all the files of the sysroot.
+	Timestamp android.Path
+}
+
+var CrossSysrootInfoProvider = blueprint.NewProvider(CrossSysrootInfo{})
+
+type crossSysrootProperties struct {
+	// The OS of the sysroot, "linux_embedded" or "qnx".
+	Target_os *string
+
+	// The architecture of the sysroot, "arm64" or "x86_64".
+	Target_arch *string
+
+	// Base directory of the headers. The headers are installed to usr/include of the sysroot
+	// with this directory stripped, e.g. include/sys/types.h is installed to
+	// usr/include/sys/types.h if headers_from is "include".
+	Headers_from *string
+
+	// List of headers to install. Glob compatible.
+	Headers []string `android:"path"`
+
+	// List of libraries, crt objects and linker scripts to install to usr/lib of the sysroot.
+	Libs []string `android:"path"`
+}
+
+type crossSysrootModule struct {
+	android.ModuleBase
+
+	properties crossSysrootProperties
+}
+
+// cc_cross_sysroot packages the prebuilt headers and libraries of a non-Android target into a
+// sysroot that cc modules are built against with the cross_sysroot property.
+func crossSysrootFactory() android.Module {
+	module := &crossSysrootModule{}
+	module.AddProperties(&module.properties)
+	android.InitAndroidModule(module)
+	return module
+}
+
+func crossSysrootAllowed(ctx android.BaseModuleContext) bool {
+	return android.HasAnyPrefix(ctx.ModuleDir()+"/", config.CrossSysrootAllowedProjects)
+}
+
+func (m *crossSysrootModule) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	if !crossSysrootAllowed(ctx) {
+		ctx.ModuleErrorf("cc_cross_sysroot modules are only allowed in %s",
+			strings.Join(config.CrossSysrootAllowedProjects, ", "))
+		return
+	}
+
+	os := android.NoOsType
+	for _, o := range android.OsTypeList() {
+		if o.Name == String(m.properties.Target_os) && config.CrossSysrootOs(o) {
+			os = o
+		}
+	}
+	if os == android.NoOsType {
+		ctx.PropertyErrorf("target_os", "must be linux_embedded or qnx, got %q", String(m.properties.Target_os))
+		return
+	}
+
+	var arch android.ArchType
+	for _, a := range android.ArchTypeList() {
+		if a.Name == String(m.properties.Target_arch) {
+			arch = a
+		}
+	}
+	if config.CrossSysrootTriple(os, arch) == "" {
+		ctx.PropertyErrorf("target_arch", "%q is not a supported architecture of %s",
+			String(m.properties.Target_arch), os)
+		return
+	}
+
+	sysrootDir := android.PathForModuleOut(ctx, "sysroot")
+	var files android.Paths
+	install := func(src android.Path, dst android.WritablePath) {
+		ctx.Build(pctx, android.BuildParams{
+			Rule:   android.Cp,
+			Input:  src,
+			Output: dst,
+		})
+		files = append(files, dst)
+	}
+
+	from := android.PathForModuleSrc(ctx, String(m.properties.Headers_from))
+	for _, header := range android.PathsForModuleSrc(ctx, m.properties.Headers) {
+		rel, err := filepath.Rel(from.String(), header.String())
+		if err != nil || strings.HasPrefix(rel, "../") {
+			ctx.PropertyErrorf("headers", "%q is not in headers_from %q", header, from)
+			continue
+		}
+		install(header, sysrootDir.Join(ctx, "usr/include", rel))
+	}
+	for _, lib := range android.PathsForModuleSrc(ctx, m.properties.Libs) {
+		install(lib, sysrootDir.Join(ctx, "usr/lib", lib.Base()))
+	}
+
+	timestamp := android.PathForModuleOut(ctx, "sysroot.timestamp")
+	ctx.Build(pctx, android.BuildParams{
+		Rule:      android.Touch,
+		Output:    timestamp,
+		Implicits: files,
+	})
+
+	ctx.SetProvider(CrossSysrootInfoProvider, CrossSysrootInfo{
+		Os:        os,
+		Arch:      arch,
+		Dir:       sysrootDir,
+		Timestamp: timestamp,
+	})
+}
+
+type CrossSysrootProperties struct {
+	// The cc_cross_sysroot module to build the module against. Required for the linux_embedded
+	// and qnx variants, and not supported for the other variants.
+	Cross_sysroot *string `android:"arch_variant"`
+}
+
+// crossSysrootFeature builds the linux_embedded and qnx variants of a module against their
+// cc_cross_sysroot module.
+type crossSysrootFeature struct {
+	Properties CrossSysrootProperties
+}
+
+func (sysroot *crossSysrootFeature) props() []interface{} {
+	return []interface{}{&sysroot.Properties}
+}
+
+func (sysroot *crossSysrootFeature) deps(ctx DepsContext) {
+	if !config.CrossSysrootOs(ctx.Os()) {
+		if sysroot.Properties.Cross_sysroot != nil {
+			ctx.PropertyErrorf("cross_sysroot", "is only supported for linux_embedded and qnx, not %s", ctx.Os())
+		}
+		return
+	}
+	if !crossSysrootAllowed(ctx) {
+		ctx.ModuleErrorf("%s modules are only allowed in %s", ctx.Os(),
+			strings.Join(config.CrossSysrootAllowedProjects, ", "))
+		return
+	}
+	if String(sysroot.Properties.Cross_sysroot) == "" {
+		ctx.PropertyErrorf("cross_sysroot", "must be set for %s", ctx.Os())
+		return
+	}
+	// cc_cross_sysroot modules do not have any variations
+	ctx.AddFarVariationDependencies([]blueprint.Variation{}, crossSysrootDepTag,
+		String(sysroot.Properties.Cross_sysroot))
+}
+
+func (sysroot *crossSysrootFeature) flags(ctx ModuleContext, flags Flags) Flags {
+	if !config.CrossSysrootOs(ctx.Os()) {
+		return flags
+	}
+	ctx.VisitDirectDepsWithTag(crossSysrootDepTag, func(dep android.Module) {
+		if !ctx.OtherModuleHasProvider(dep, CrossSysrootInfoProvider) {
+			ctx.PropertyErrorf("cross_sysroot", "%q is not a cc_cross_sysroot module", ctx.OtherModuleName(dep))
+			return
+		}
+		info := ctx.OtherModuleProvider(dep, CrossSysrootInfoProvider).(CrossSysrootInfo)
+		if info.Os != ctx.Os() || info.Arch != ctx.Arch().ArchType {
+			ctx.PropertyErrorf("cross_sysroot", "%q is a sysroot for %s_%s, not %s_%s",
+				ctx.OtherModuleName(dep), info.Os, info.Arch, ctx.Os(), ctx.Arch().ArchType)
+			return
+		}
+		sysrootFlag := "--sysroot " + info.Dir.String()
+		flags.Global.CommonFlags = append(flags.Global.CommonFlags, sysrootFlag)
+		flags.Global.LdFlags = append(flags.Global.LdFlags, sysrootFlag)
+		flags.CFlagsDeps = append(flags.CFlagsDeps, info.Timestamp)
+		flags.LdFlagsDeps = append(flags.LdFlagsDeps, info.Timestamp)
+	})
+	return flags
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+var prepareForCrossSysrootTest = android.GroupFixturePreparers(
+	prepareForCcTest,
+	android.FixtureModifyConfig(func(config android.Config) {
+		config.Targets[android.Qnx] = []android.Target{
+			{android.Qnx, android.Arch{ArchType: android.Arm64}, android.NativeBridgeDisabled, "", "", true},
+		}
+	}),
+	android.FixtureAddTextFile("vendor/qnx/Android.bp", `
+		cc_cross_sysroot {
+			name: "qnx_sysroot",
+			target_os: "qnx",
+			target_arch: "arm64",
+			headers_from: "include",
+			headers: ["include/**/*.h"],
+			libs: ["lib/libc.so"],
+		}
+		cc_cross_sysroot {
+			name: "qnx_x86_64_sysroot",
+			target_os: "qnx",
+			target_arch: "x86_64",
+		}`),
+	android.FixtureAddFile("vendor/qnx/include/sys/types.h", nil),
+	android.FixtureAddFile("vendor/qnx/lib/libc.so", nil),
+	android.FixtureAddFile("vendor/tool/foo.c", nil),
+)
+
+func TestCrossSysroot(t *testing.T) {
+	t.Parallel()
+	result := android.GroupFixturePreparers(
+		prepareForCrossSysrootTest,
+		android.FixtureAddTextFile("vendor/tool/Android.bp", `
+			cc_binary {
+				name: "tool",
+				srcs: ["foo.c"],
+				device_supported: false,
+				host_supported: true,
+				target: {
+					host: {
+						enabled: false,
+					},
+					qnx: {
+						enabled: true,
+						cross_sysroot: "qnx_sysroot",
+					},
+				},
+			}`),
+	).RunTest(t)
+
+	sysroot := result.ModuleForTests("qnx_sysroot", "")
+	sysroot.Output("sysroot/usr/include/sys/types.h")
+	sysroot.Output("sysroot/usr/lib/libc.so")
+	timestamp := sysroot.Output("sysroot.timestamp")
+	android.AssertPathsRelativeToTopEquals(t, "sysroot.timestamp implicits", []string{
+		"out/soong/.intermediates/vendor/qnx/qnx_sysroot/sysroot/usr/include/sys/types.h",
+		"out/soong/.intermediates/vendor/qnx/qnx_sysroot/sysroot/usr/lib/libc.so",
+	}, timestamp.Implicits)
+
+	tool := result.ModuleForTests("tool", "qnx_arm64")
+	sysrootFlag := "--sysroot out/soong/.intermediates/vendor/qnx/qnx_sysroot/sysroot"
+	cc := tool.Rule("cc").RelativeToTop()
+	android.AssertStringDoesContain(t, "cflags", cc.Args["cFlags"], sysrootFlag)
+	android.AssertStringDoesContain(t, "cflags", cc.Args["cFlags"], "-target aarch64-unknown-nto-qnx")
+	android.AssertStringListContains(t, "cc implicits", cc.Implicits.Strings(),
+		"out/soong/.intermediates/vendor/qnx/qnx_sysroot/sysroot.timestamp")
+	ld := tool.Rule("ld").RelativeToTop()
+	android.AssertStringDoesContain(t, "ldflags", ld.Args["ldFlags"], sysrootFlag)
+}
+
+func TestCrossSysrootErrors(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		bp   string
+		err  string
+	}{
+		{
+			name: "arch mismatch",
+			bp: `
+				cc_binary {
+					name: "tool",
+					srcs: ["foo.c"],
+					host_supported: true,
+					target: {
+						qnx: {
+							enabled: true,
+							cross_sysroot: "qnx_x86_64_sysroot",
+						},
+					},
+				}`,
+			err: `"qnx_x86_64_sysroot" is a sysroot for qnx_x86_64, not qnx_arm64`,
+		},
+		{
+			name: "missing cross_sysroot",
+			bp: `
+				cc_binary {
+					name: "tool",
+					srcs: ["foo.c"],
+					host_supported: true,
+					target: {
+						qnx: {
+							enabled: true,
+						},
+					},
+				}`,
+			err: `cross_sysroot: must be set for qnx`,
+		},
+		{
+			name: "cross_sysroot for android",
+			bp: `
+				cc_binary {
+					name: "tool",
+					srcs: ["foo.c"],
+					cross_sysroot: "qnx_sysroot",
+				}`,
+			err: `cross_sysroot: is only supported for linux_embedded and qnx, not android`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			android.GroupFixturePreparers(
+				prepareForCrossSysrootTest,
+				android.FixtureAddTextFile("vendor/tool/Android.bp", tc.bp),
+			).ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(tc.err)).
+				RunTest(t)
+		})
+	}
+
+	t.Run("not allowed", func(t *testing.T) {
+		t.Parallel()
+		prepareForCrossSysrootTest.
+			ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+				`cc_cross_sysroot modules are only allowed in device/, vendor/`)).
+			RunTestWithBp(t, `
+				cc_cross_sysroot {
+					name: "other_sysroot",
+					target_os: "linux_embedded",
+					target_arch: "arm64",
+				}`)
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+// This file restricts the compiler and linker flags that modules may pass in their properties to
+// the modules of an allowlist. Modules in config.FlagPolicyAllowedProjects and the modules allowed
+// by the CcFlagPolicyAllowlist product variable may use the restricted flags. The uses by other
+// modules are listed in $OUT/soong/cc_flag_policy.json, and fail the build if the product sets
+// CcFlagPolicySeverity to "error".
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"android/soong/android"
+	"android/soong/cc/config"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// restrictedFlag is a flag, or flags with a prefix if the flag ends with "*", that modules outside
+// of the allowlist may not use.
+type restrictedFlag struct {
+	flag   string
+	reason string
+}
+
+func (r restrictedFlag) matches(flag string) bool {
+	if strings.HasSuffix(r.flag, "*") {
+		return strings.HasPrefix(flag, strings.TrimSuffix(r.flag, "*"))
+	}
+	return flag == r.flag
+}
+
+var restrictedCompilerFlags = []restrictedFlag{
+	{"-fno-stack-protector", "disables the stack protector"},
+	{"-fno-stack-clash-protection", "disables the stack clash protection"},
+	{"-U_FORTIFY_SOURCE", "disables FORTIFY"},
+	{"-D_FORTIFY_SOURCE=*", "overrides the FORTIFY level"},
+	{"-std=*", "use c_std or cpp_std instead"},
+}
+
+var restrictedLinkerFlags = []restrictedFlag{
+	{"-Wl,--allow-shlib-undefined", "defers missing symbols to runtime"},
+	{"-Wl,-z,execstack", "makes the stack executable"},
+	{"-Wl,-z,norelro", "disables RELRO"},
+}
+
+var flagPolicyViolationsKey = android.NewOnceKey("CcFlagPolicyViolations")
+
+// flagPolicyViolation is a use of a restricted flag, and an entry of cc_flag_policy.json.
+type flagPolicyViolation struct {
+	Module   string `json:"module"`
+	Variant  string `json:"variant"`
+	Dir      string `json:"dir"`
+	Property string `json:"property"`
+	Flag     string `json:"flag"`
+	Reason   string `json:"reason"`
+}
+
+// checkFlagPolicy checks the flags of a property of the module against the restricted flags.
+func checkFlagPolicy(ctx ModuleContext, prop string, flags []string, restricted []restrictedFlag) {
+	if android.HasAnyPrefix(ctx.ModuleDir()+"/", config.FlagPolicyAllowedProjects) {
+		return
+	}
+	for _, flag := range flags {
+		flag = strings.TrimSpace(flag)
+		for _, r := range restricted {
+			if !r.matches(flag) {
+				continue
+			}
+			if _, allowed := ctx.Config().CcFlagPolicyExceptionFor(ctx.ModuleName(), ctx.ModuleDir(), r.flag); allowed {
+				break
+			}
+			getNamedMapForConfig(ctx.Config(), flagPolicyViolationsKey).Store(flagPolicyViolation{
+				Module:   ctx.ModuleName(),
+				Variant:  ctx.ModuleSubDir(),
+				Dir:      ctx.ModuleDir(),
+				Property: prop,
+				Flag:     flag,
+				Reason:   r.reason,
+			}, true)
+			if ctx.Config().CcFlagPolicySeverity() == "error" {
+				ctx.PropertyErrorf(prop, "Restricted flag `%s`: %s. Add an exception to CcFlagPolicyAllowlist if the module needs it", flag, r.reason)
+			}
+			break
+		}
+	}
+}
+
+func flagPolicySingletonFactory() android.Singleton {
+	return &flagPolicySingleton{}
+}
+
+// flagPolicySingleton checks the cc flag policy product variables and writes the uses of
+// restricted flags to cc_flag_policy.json.
+type flagPolicySingleton struct{}
+
+func (s *flagPolicySingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	switch severity := ctx.Config().CcFlagPolicySeverity(); severity {
+	case "error", "warning":
+	default:
+		ctx.Errorf(`CcFlagPolicySeverity: must be "error" or "warning", got %q`, severity)
+	}
+	for i, exception := range ctx.Config().CcFlagPolicyAllowlist() {
+		if (exception.Path == nil) == (exception.Module == nil) {
+			ctx.Errorf("CcFlagPolicyAllowlist[%d]: exactly one of Path and Module must be set", i)
+		}
+		if proptools.String(exception.Reason) == "" {
+			ctx.Errorf("CcFlagPolicyAllowlist[%d]: Reason must be set", i)
+		}
+		for _, flag := range exception.Flags {
+			if !isRestrictedFlag(flag) {
+				ctx.Errorf("CcFlagPolicyAllowlist[%d]: %q is not a restricted flag", i, flag)
+			}
+		}
+	}
+
+	violations := []flagPolicyViolation{}
+	getNamedMapForConfig(ctx.Config(), flagPolicyViolationsKey).Range(func(key, value interface{}) bool {
+		violations = append(violations, key.(flagPolicyViolation))
+		return true
+	})
+	sort.Slice(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Module != b.Module {
+			return a.Module < b.Module
+		}
+		if a.Variant != b.Variant {
+			return a.Variant < b.Variant
+		}
+		if a.Property != b.Property {
+			return a.Property < b.Property
+		}
+		return a.Flag < b.Flag
+	})
+
+	jsonStr, err := json.MarshalIndent(violations, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	android.WriteFileRule(ctx, flagPolicyReportPath(ctx), string(jsonStr))
+}
+
+func isRestrictedFlag(flag string) bool {
+	for _, r := range append(restrictedCompilerFlags, restrictedLinkerFlags...) {
+		if r.flag == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func flagPolicyReportPath(ctx android.PathContext) android.WritablePath {
+	return android.PathForOutput(ctx, "cc_flag_policy.json")
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint/proptools"
+)
+
+func TestFlagPolicyReport(t *testing.T) {
+	t.Parallel()
+	bp := `
+	cc_library_shared {
+		name: "libfoo",
+		srcs: ["foo.c"],
+		cflags: ["-fno-stack-protector", "-std=gnu99", "-O2"],
+		ldflags: ["-Wl,--allow-shlib-undefined"],
+	}
+	cc_library_shared {
+		name: "liballowed",
+		srcs: ["foo.c"],
+		cflags: ["-fno-stack-protector", "-std=gnu99"],
+	}`
+
+	result := android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureAddTextFile("external/bar/Android.bp", `
+			cc_library_shared {
+				name: "libexternal",
+				srcs: ["foo.c"],
+				cflags: ["-fno-stack-protector"],
+			}`),
+		android.FixtureAddFile("external/bar/foo.c", nil),
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.CcFlagPolicyAllowlist = []android.CcFlagPolicyException{
+				{
+					Module: proptools.StringPtr("liballowed"),
+					Flags:  []string{"-std=*"},
+					Reason: proptools.StringPtr("b/1"),
+				},
+			}
+		}),
+	).RunTestWithBp(t, bp)
+
+	report := result.SingletonForTests("cc_flag_policy").Output("cc_flag_policy.json")
+	var violations []flagPolicyViolation
+	if err := json.Unmarshal([]byte(android.ContentFromFileRuleForTests(t, report)), &violations); err != nil {
+		t.Fatal(err)
+	}
+
+	variant := "android_arm64_armv8-a_shared"
+	var got []flagPolicyViolation
+	for _, v := range violations {
+		if v.Variant == variant {
+			got = append(got, v)
+		}
+	}
+	android.AssertDeepEquals(t, "cc_flag_policy.json", []flagPolicyViolation{
+		{"liballowed", variant, ".", "cflags", "-fno-stack-protector", "disables the stack protector"},
+		{"libfoo", variant, ".", "cflags", "-fno-stack-protector", "disables the stack protector"},
+		{"libfoo", variant, ".", "cflags", "-std=gnu99", "use c_std or cpp_std instead"},
+		{"libfoo", variant, ".", "ldflags", "-Wl,--allow-shlib-undefined", "defers missing symbols to runtime"},
+	}, got)
+}
+
+func TestFlagPolicyErrors(t *testing.T) {
+	t.Parallel()
+	bp := `
+	cc_library_shared {
+		name: "libfoo",
+		srcs: ["foo.c"],
+		cflags: ["-fno-stack-protector"],
+	}`
+
+	android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.CcFlagPolicySeverity = proptools.StringPtr("error")
+		}),
+	).ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+		"cflags: Restricted flag `-fno-stack-protector`: disables the stack protector",
+	)).RunTestWithBp(t, bp)
+}
+
+func TestFlagPolicyAllowlistMustBeWellFormed(t *testing.T) {
+	t.Parallel()
+	android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.CcFlagPolicySeverity = proptools.StringPtr("fatal")
+			variables.CcFlagPolicyAllowlist = []android.CcFlagPolicyException{
+				{Path: proptools.StringPtr("foo"), Module: proptools.StringPtr("libfoo"), Reason: proptools.StringPtr("b/1")},
+				{Module: proptools.StringPtr("libfoo"), Flags: []string{"-O2"}},
+			}
+		}),
+	).ExtendWithErrorHandler(android.FixtureExpectsAllErrorsToMatchAPattern([]string{
+		`CcFlagPolicySeverity: must be "error" or "warning", got "fatal"`,
+		`CcFlagPolicyAllowlist\[0\]: exactly one of Path and Module must be set`,
+		`CcFlagPolicyAllowlist\[1\]: Reason must be set`,
+		`CcFlagPolicyAllowlist\[1\]: "-O2" is not a restricted flag`,
+	})).RunTest(t)
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"encoding/json"
+	"sort"
+
+	"android/soong/android"
+)
+
+// The libraries linked into fuzz targets, by cc_fuzz directly or as the JNI libraries of java_fuzz,
+// are built in their fuzzer variation by the sanitizer mutators without their owners enabling the
+// fuzzer sanitizer. The fuzz_coverage singleton reports which libraries are fuzzed that way, which
+// are linked into fuzz targets without instrumentation because they disable the fuzzer sanitizer,
+// and which are not linked into any fuzz target, in $OUT/soong/fuzz_coverage.json.
+
+func init() {
+	RegisterFuzzCoverageBuildComponents(android.InitRegistrationContext)
+}
+
+func RegisterFuzzCoverageBuildComponents(ctx android.RegistrationContext) {
+	ctx.RegisterSingletonType("fuzz_coverage", fuzzCoverageSingletonFactory)
+}
+
+var PrepareForTestWithFuzzCoverage = android.FixtureRegisterWithContext(RegisterFuzzCoverageBuildComponents)
+
+// FuzzCoverageLibrary is a library in fuzz_coverage.json.
+type FuzzCoverageLibrary struct {
+	Name string `json:"name"`
+	Dir  string `json:"dir"`
+
+	// The fuzz targets that link the library.
+	FuzzTargets []string `json:"fuzz_targets,omitempty"`
+}
+
+// FuzzCoverage is the content of fuzz_coverage.json.
+type FuzzCoverage struct {
+	// The libraries that are linked into fuzz targets with fuzzer instrumentation.
+	Fuzzed []FuzzCoverageLibrary `json:"fuzzed"`
+
+	// The libraries that are linked into fuzz targets without fuzzer instrumentation.
+	Uninstrumented []FuzzCoverageLibrary `json:"uninstrumented"`
+
+	// The libraries that are not linked into any fuzz target.
+	Unfuzzed []FuzzCoverageLibrary `json:"unfuzzed"`
+}
+
+func fuzzCoverageSingletonFactory() android.Singleton {
+	return &fuzzCoverageSingleton{}
+}
+
+type fuzzCoverageSingleton struct{}
+
+// isFuzzCoverageCandidate returns true for the libraries that could be built with fuzzer
+// instrumentation.
+func isFuzzCoverageCandidate(c *Module) bool {
+	if !c.Enabled() || c.library == nil || c.Header() || c.IsStubs() || c.testLibrary() {
+		return false
+	}
+	if android.IsModulePrebuilt(c) || c.sanitize == nil || c.SanitizeNever() {
+		return false
+	}
+	return true
+}
+
+// isFuzzTarget returns true for the modules that fuzz the libraries that they link.
+func isFuzzTarget(module android.Module) bool {
+	if l, ok := module.(LinkableInterface); ok && l.IsFuzzModule() {
+		return true
+	}
+	if j, ok := module.(JniSanitizeable); ok && j.IsSanitizerEnabledForJni(Fuzzer.name()) {
+		return true
+	}
+	return false
+}
+
+func (s *fuzzCoverageSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	type library struct {
+		FuzzCoverageLibrary
+		instrumented bool
+	}
+	libraries := make(map[string]*library)
+
+	ctx.VisitAllModules(func(module android.Module) {
+		if c, ok := module.(*Module); ok && isFuzzCoverageCandidate(c) {
+			name := ctx.ModuleName(c)
+			if libraries[name] == nil {
+				libraries[name] = &library{FuzzCoverageLibrary: FuzzCoverageLibrary{Name: name, Dir: ctx.ModuleDir(c)}}
+			}
+		}
+	})
+
+	ctx.VisitAllModules(func(module android.Module) {
+		if !module.Enabled() || !isFuzzTarget(module) {
+			return
+		}
+		target := ctx.ModuleName(module)
+		ctx.VisitDepsDepthFirst(module, func(dep android.Module) {
+			c, ok := dep.(*Module)
+			if !ok || !isFuzzCoverageCandidate(c) {
+				return
+			}
+			lib := libraries[ctx.ModuleName(c)]
+			lib.FuzzTargets = append(lib.FuzzTargets, target)
+			if c.sanitize.isSanitizerEnabled(Fuzzer) {
+				lib.instrumented = true
+			}
+		})
+	})
+
+	coverage := FuzzCoverage{
+		Fuzzed:         []FuzzCoverageLibrary{},
+		Uninstrumented: []FuzzCoverageLibrary{},
+		Unfuzzed:       []FuzzCoverageLibrary{},
+	}
+	names := make([]string, 0, len(libraries))
+	for name := range libraries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lib := libraries[name]
+		lib.FuzzTargets = android.SortedUniqueStrings(lib.FuzzTargets)
+		switch {
+		case len(lib.FuzzTargets) == 0:
+			coverage.Unfuzzed = append(coverage.Unfuzzed, lib.FuzzCoverageLibrary)
+		case lib.instrumented:
+			coverage.Fuzzed = append(coverage.Fuzzed, lib.FuzzCoverageLibrary)
+		default:
+			coverage.Uninstrumented = append(coverage.Uninstrumented, lib.FuzzCoverageLibrary)
+		}
+	}
+
+	data, err := json.MarshalIndent(coverage, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	android.WriteFileRule(ctx, android.PathForOutput(ctx, "fuzz_coverage.json"), string(data))
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestFuzzCoverage(t *testing.T) {
+	t.Parallel()
+	result := android.GroupFixturePreparers(
+		prepareForCcTest,
+		PrepareForTestWithFuzzCoverage,
+	).RunTestWithBp(t, `
+		cc_fuzz {
+			name: "foo_fuzzer",
+			srcs: ["foo_fuzzer.cpp"],
+			static_libs: ["libfoo_static"],
+			shared_libs: ["libfoo_shared", "libno_fuzzer"],
+		}
+
+		cc_library_static {
+			name: "libfoo_static",
+			srcs: ["foo.cpp"],
+		}
+
+		cc_library_shared {
+			name: "libfoo_shared",
+			srcs: ["foo.cpp"],
+			static_libs: ["libfoo_transitive"],
+		}
+
+		cc_library_static {
+			name: "libfoo_transitive",
+			srcs: ["foo.cpp"],
+		}
+
+		cc_library_shared {
+			name: "libno_fuzzer",
+			srcs: ["foo.cpp"],
+			sanitize: {
+				fuzzer: false,
+			},
+		}
+
+		cc_library {
+			name: "libunfuzzed",
+			srcs: ["foo.cpp"],
+		}
+	`)
+
+	// The libraries linked into the fuzz target are built in their fuzzer variation.
+	fuzzerCflags := result.ModuleForTests("libfoo_shared", "android_arm64_armv8-a_shared_fuzzer").Rule("cc").Args["cFlags"]
+	android.AssertStringDoesContain(t, "libfoo_shared cflags", fuzzerCflags, "-fsanitize=fuzzer-no-link")
+	result.ModuleForTests("libfoo_transitive", "android_arm64_armv8-a_static_fuzzer").Rule("cc")
+
+	var coverage FuzzCoverage
+	out := result.SingletonForTests("fuzz_coverage").Output("fuzz_coverage.json")
+	if err := json.Unmarshal([]byte(android.ContentFromFileRuleForTests(t, out)), &coverage); err != nil {
+		t.Fatal(err)
+	}
+
+	find := func(libs []FuzzCoverageLibrary, name string) *FuzzCoverageLibrary {
+		for i := range libs {
+			if libs[i].Name == name {
+				return &libs[i]
+			}
+		}
+		return nil
+	}
+
+	for _, name := range []string{"libfoo_static", "libfoo_shared", "libfoo_transitive"} {
+		lib := find(coverage.Fuzzed, name)
+		if lib == nil {
+			t.Errorf("expected %s to be fuzzed, got %+v", name, coverage)
+			continue
+		}
+		android.AssertDeepEquals(t, name+" fuzz targets", []string{"foo_fuzzer"}, lib.FuzzTargets)
+	}
+	if find(coverage.Uninstrumented, "libno_fuzzer") == nil {
+		t.Errorf("expected libno_fuzzer to be uninstrumented, got %+v", coverage.Uninstrumented)
+	}
+	if find(coverage.Unfuzzed, "libunfuzzed") == nil {
+		t.Errorf("expected libunfuzzed to be unfuzzed, got %+v", coverage.Unfuzzed)
+	}
+}
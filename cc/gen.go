@@ -322,6 +322,7 @@ func genSources(ctx android.ModuleContext, srcFiles android.Paths,
 		case ".proto":
 			ccFile, headerFile := genProto(ctx, srcFile, buildFlags)
 			srcFiles[i] = ccFile
+			android.RecordGeneratedSources(ctx, "proto", android.Paths{ccFile, headerFile}, android.Paths{srcFile})
 			info.protoHeaders = append(info.protoHeaders, headerFile)
 			// Use the generated header as an order only dep to ensure that it is up to date when needed.
 			info.protoOrderOnlyDeps = append(info.protoOrderOnlyDeps, headerFile)
@@ -332,6 +333,8 @@ func genSources(ctx android.ModuleContext, srcFiles android.Paths,
 			}
 			cppFile, aidlHeaders := genAidl(ctx, aidlRule, srcFile, buildFlags.aidlFlags)
 			srcFiles[i] = cppFile
+			android.RecordGeneratedSources(ctx, "aidl", append(android.Paths{cppFile}, aidlHeaders...),
+				android.Paths{srcFile})
 
 			info.aidlHeaders = append(info.aidlHeaders, aidlHeaders...)
 			// Use the generated headers as order only deps to ensure that they are up to date when
@@ -345,6 +348,8 @@ func genSources(ctx android.ModuleContext, srcFiles android.Paths,
 		case ".sysprop":
 			cppFile, headerFiles := genSysprop(ctx, srcFile)
 			srcFiles[i] = cppFile
+			android.RecordGeneratedSources(ctx, "sysprop", append(android.Paths{cppFile}, headerFiles...),
+				android.Paths{srcFile})
 			info.syspropHeaders = append(info.syspropHeaders, headerFiles...)
 			// Use the generated headers as order only deps to ensure that they are up to date when
 			// needed.
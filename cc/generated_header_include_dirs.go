@@ -0,0 +1,191 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+// This file checks that generated headers are only reachable through include directories that
+// are scoped to the module that generates them. An include directory in the output directory that
+// is not inside the intermediates directory of a single module variant, e.g. -I out/soong or
+// -I out/soong/.intermediates/external, makes the generated headers of every module below it
+// visible. Modules then silently depend on headers of modules they don't depend on, which breaks
+// when the headers are not generated first or in builds of a subset of the tree.
+//
+// The include directories that violate this are listed in
+// $OUT/soong/generated_header_include_dirs.json, and are errors if the product sets
+// ENFORCE_GENERATED_HEADER_INCLUDE_DIRS. The roots that Soong generates on purpose for every module
+// to include, like the NDK sysroot, are allowed.
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/android"
+)
+
+// outputIncludeDir is an include directory in the output directory that a module compiles with.
+type outputIncludeDir struct {
+	// The directory, relative to the Soong output directory.
+	dir string
+
+	// The name of the module that exported the directory.
+	exportedBy string
+}
+
+// recordOutputIncludeDirs records the include directories exported by a dependency that are in
+// the output directory, so that generatedHeaderIncludeDirsSingleton can check them.
+func (c *Module) recordOutputIncludeDirs(ctx android.ModuleContext, exportedBy string, dirs android.Paths) {
+	for _, dir := range dirs {
+		if _, ok := dir.(android.WritablePath); !ok {
+			continue
+		}
+		if rel, ok := relToSoongOutDir(ctx.Config(), dir.String()); ok {
+			c.outputIncludeDirs = append(c.outputIncludeDirs, outputIncludeDir{rel, exportedBy})
+		}
+	}
+}
+
+// relToSoongOutDir returns the path relative to the Soong output directory, if it is inside it.
+func relToSoongOutDir(config android.Config, path string) (string, bool) {
+	rel, err := filepath.Rel(config.SoongOutDir(), filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// generatedHeaderRoots returns the directories, relative to the Soong output directory, that are
+// generated to be included by any module, and are allowed as include directories.
+func generatedHeaderRoots(ctx android.PathContext) []string {
+	var roots []string
+	if rel, ok := relToSoongOutDir(ctx.Config(), getNdkSysrootBase(ctx).String()); ok {
+		roots = append(roots, rel)
+	}
+	return roots
+}
+
+// isAllowedDir returns true if the directory, relative to the Soong output directory, is inside
+// one of the given module variant intermediates directories or generated header roots.
+func isAllowedDir(dir string, moduleDirs map[string]bool, roots []string) bool {
+	for _, root := range roots {
+		if dir == root || strings.HasPrefix(dir, root+"/") {
+			return true
+		}
+	}
+	return isModuleScopedDir(dir, moduleDirs)
+}
+
+// isModuleScopedDir returns true if the directory, relative to the Soong output directory, is
+// inside one of the given module variant intermediates directories.
+func isModuleScopedDir(dir string, moduleDirs map[string]bool) bool {
+	for d := dir; d != "." && d != "/"; d = filepath.Dir(d) {
+		if moduleDirs[d] {
+			return true
+		}
+	}
+	return false
+}
+
+func generatedHeaderIncludeDirsSingletonFactory() android.Singleton {
+	return &generatedHeaderIncludeDirsSingleton{}
+}
+
+type generatedHeaderIncludeDirsSingleton struct{}
+
+// generatedHeaderIncludeDirViolation is an entry of generated_header_include_dirs.json.
+type generatedHeaderIncludeDirViolation struct {
+	Module     string `json:"module,omitempty"`
+	IncludeDir string `json:"include_dir"`
+	ExportedBy string `json:"exported_by"`
+}
+
+func (s *generatedHeaderIncludeDirsSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	// The intermediates directories of all module variants, see android.PathForModuleOut.
+	moduleDirs := make(map[string]bool)
+	ctx.VisitAllModules(func(module android.Module) {
+		moduleDirs[filepath.Join(".intermediates", ctx.ModuleDir(module), ctx.ModuleName(module),
+			ctx.ModuleSubDir(module))] = true
+	})
+
+	roots := generatedHeaderRoots(ctx)
+	enforce := ctx.Config().EnforceGeneratedHeaderIncludeDirs()
+	fix := "export the headers from the module that generates them with export_include_dirs " +
+		"or generated_headers instead"
+
+	violations := []generatedHeaderIncludeDirViolation{}
+	seen := make(map[generatedHeaderIncludeDirViolation]bool)
+	ctx.VisitAllModules(func(module android.Module) {
+		ccModule, ok := module.(*Module)
+		if !ok || !ccModule.Enabled() {
+			return
+		}
+		for _, include := range ccModule.outputIncludeDirs {
+			if isAllowedDir(include.dir, moduleDirs, roots) {
+				continue
+			}
+			violation := generatedHeaderIncludeDirViolation{
+				Module:     ctx.ModuleName(module),
+				IncludeDir: include.dir,
+				ExportedBy: include.exportedBy,
+			}
+			if seen[violation] {
+				continue
+			}
+			seen[violation] = true
+			violations = append(violations, violation)
+			if enforce {
+				ctx.ModuleErrorf(module, "include directory %q exported by %q is not inside the "+
+					"intermediates directory of a module, %s", include.dir, include.exportedBy, fix)
+			}
+		}
+	})
+
+	for _, dir := range strings.Fields(ctx.DeviceConfig().TargetSpecificHeaderPath()) {
+		rel, ok := relToSoongOutDir(ctx.Config(), dir)
+		if !ok || isAllowedDir(rel, moduleDirs, roots) {
+			continue
+		}
+		violations = append(violations, generatedHeaderIncludeDirViolation{
+			IncludeDir: rel,
+			ExportedBy: "TARGET_SPECIFIC_HEADER_PATH",
+		})
+		if enforce {
+			ctx.Errorf("TARGET_SPECIFIC_HEADER_PATH contains %q, which is not inside the "+
+				"intermediates directory of a module, %s", dir, fix)
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Module != b.Module {
+			return a.Module < b.Module
+		}
+		if a.IncludeDir != b.IncludeDir {
+			return a.IncludeDir < b.IncludeDir
+		}
+		return a.ExportedBy < b.ExportedBy
+	})
+
+	jsonStr, err := json.MarshalIndent(violations, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	android.WriteFileRule(ctx, generatedHeaderIncludeDirsReportPath(ctx), string(jsonStr))
+}
+
+func generatedHeaderIncludeDirsReportPath(ctx android.PathContext) android.WritablePath {
+	return android.PathForOutput(ctx, "generated_header_include_dirs.json")
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint/proptools"
+)
+
+func TestIsAllowedDir(t *testing.T) {
+	moduleDirs := map[string]bool{
+		".intermediates/external/foo/libfoo/android_arm64_armv8-a_shared": true,
+	}
+
+	testCases := []struct {
+		dir      string
+		expected bool
+	}{
+		{".intermediates/external/foo/libfoo/android_arm64_armv8-a_shared", true},
+		{".intermediates/external/foo/libfoo/android_arm64_armv8-a_shared/gen/include", true},
+		{".intermediates/external/foo/libfoo", false},
+		{".intermediates/external", false},
+		{".intermediates", false},
+		{"ndk/sysroot/usr/include", true},
+		{"ndk/sysroot", true},
+		{"ndk/sysroot2", false},
+		{"ndk", false},
+	}
+
+	for _, tc := range testCases {
+		android.AssertBoolEquals(t, tc.dir, tc.expected, isAllowedDir(tc.dir, moduleDirs, []string{"ndk/sysroot"}))
+	}
+}
+
+func TestGeneratedHeaderIncludeDirs(t *testing.T) {
+	bp := `
+		cc_library_shared {
+			name: "libfoo",
+			srcs: ["foo.c"],
+		}
+	`
+
+	targetSpecificHeaderPath := func(dirs ...string) android.FixturePreparer {
+		return android.FixtureModifyConfig(func(config android.Config) {
+			var paths []string
+			for _, dir := range dirs {
+				paths = append(paths, filepath.Join(config.SoongOutDir(), dir))
+			}
+			config.TestProductVariables.TargetSpecificHeaderPath = proptools.StringPtr(strings.Join(paths, " "))
+		})
+	}
+
+	t.Run("module scoped", func(t *testing.T) {
+		result := android.GroupFixturePreparers(
+			prepareForCcTest,
+			targetSpecificHeaderPath(".intermediates/libfoo/android_arm64_armv8-a_shared/gen"),
+		).RunTestWithBp(t, bp)
+
+		report := result.SingletonForTests("generated_header_include_dirs").Output("generated_header_include_dirs.json")
+		android.AssertStringEquals(t, "report", "[]", android.ContentFromFileRuleForTests(t, report))
+	})
+
+	t.Run("generated header root", func(t *testing.T) {
+		result := android.GroupFixturePreparers(
+			prepareForCcTest,
+			targetSpecificHeaderPath("ndk/sysroot/usr/include"),
+		).RunTestWithBp(t, bp)
+
+		report := result.SingletonForTests("generated_header_include_dirs").Output("generated_header_include_dirs.json")
+		android.AssertStringEquals(t, "report", "[]", android.ContentFromFileRuleForTests(t, report))
+	})
+
+	t.Run("enforced", func(t *testing.T) {
+		android.GroupFixturePreparers(
+			prepareForCcTest,
+			targetSpecificHeaderPath(".intermediates"),
+			android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+				variables.EnforceGeneratedHeaderIncludeDirs = proptools.BoolPtr(true)
+			}),
+		).
+			ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+				`TARGET_SPECIFIC_HEADER_PATH contains ".*/soong/.intermediates", which is not inside the intermediates directory of a module`)).
+			RunTestWithBp(t, bp)
+	})
+
+	t.Run("not module scoped", func(t *testing.T) {
+		result := android.GroupFixturePreparers(
+			prepareForCcTest,
+			targetSpecificHeaderPath(".intermediates"),
+		).RunTestWithBp(t, bp)
+
+		report := result.SingletonForTests("generated_header_include_dirs").Output("generated_header_include_dirs.json")
+		android.AssertStringEquals(t, "report", `[
+  {
+    "include_dir": ".intermediates",
+    "exported_by": "TARGET_SPECIFIC_HEADER_PATH"
+  }
+]`, android.ContentFromFileRuleForTests(t, report))
+	})
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+// This file applies the HardeningConfigs product variable, which enables heap memory tagging
+// (MTE) and branch protection (PAC and BTI) for the native modules of a partition or a directory.
+// Modules override the configs with sanitize.memtag_heap and branch_protection. The effective
+// memory tagging and branch protection of each arm64 device module variant, and where they were
+// decided, are listed in $OUT/soong/hardening.json for security audits.
+
+import (
+	"encoding/json"
+	"sort"
+
+	"android/soong/android"
+	"android/soong/cc/config"
+)
+
+var (
+	memtagHeapModes         = []string{"sync", "async", "off"}
+	branchProtectionModes   = []string{"standard", "pac-ret", "bti", "none"}
+	memtagHeapSetting       = func(h android.HardeningConfig) *string { return h.MemtagHeap }
+	branchProtectionSetting = func(h android.HardeningConfig) *string { return h.BranchProtection }
+)
+
+// hardeningSetting returns a setting of the product's hardening configs for the partition and
+// directory of the module, and a description of the config that it came from.
+func hardeningSetting(ctx android.BaseModuleContext, setting func(android.HardeningConfig) *string) (string, string, bool) {
+	return ctx.Config().HardeningSetting(ctx.Module().PartitionTag(ctx.DeviceConfig()), ctx.ModuleDir(), setting)
+}
+
+type HardeningProperties struct {
+	// Branch protection to compile the module with on arm64 devices: "standard" for both pointer
+	// authentication and BTI, "pac-ret" for pointer authentication only, "bti" for BTI only, or
+	// "none". Overrides the product's hardening config and the default of the architecture
+	// variant.
+	Branch_protection *string `android:"arch_variant"`
+}
+
+type hardeningFeature struct {
+	Properties HardeningProperties
+
+	// The effective hardening of the module, computed in flags() for the report.
+	decision *hardeningDecision
+}
+
+// hardeningDecision is an entry of hardening.json.
+type hardeningDecision struct {
+	Module                 string `json:"module"`
+	Variant                string `json:"variant"`
+	Partition              string `json:"partition"`
+	MemtagHeap             string `json:"memtag_heap"`
+	MemtagHeapSource       string `json:"memtag_heap_source"`
+	BranchProtection       string `json:"branch_protection"`
+	BranchProtectionSource string `json:"branch_protection_source"`
+}
+
+func (hardening *hardeningFeature) props() []interface{} {
+	return []interface{}{&hardening.Properties}
+}
+
+func (hardening *hardeningFeature) flags(ctx ModuleContext, flags Flags) Flags {
+	if ctx.Arch().ArchType != android.Arm64 || !ctx.toolchain().Bionic() {
+		return flags
+	}
+
+	branchProtection, source := "", ""
+	if prop := hardening.Properties.Branch_protection; prop != nil {
+		if !inList(*prop, branchProtectionModes) {
+			ctx.PropertyErrorf("branch_protection", "must be one of %q, got %q", branchProtectionModes, *prop)
+			return flags
+		}
+		branchProtection, source = *prop, "module"
+	} else if value, configSource, ok := hardeningSetting(ctx, branchProtectionSetting); ok && inList(value, branchProtectionModes) {
+		branchProtection, source = value, configSource
+	}
+
+	if branchProtection != "" {
+		// The flag follows the flags of the architecture variant, so it takes precedence.
+		flags.Local.CFlags = append(flags.Local.CFlags, "-mbranch-protection="+branchProtection)
+	} else {
+		branchProtection = config.Arm64BranchProtection(ctx.Arch().ArchVariant)
+		source = "arch variant " + ctx.Arch().ArchVariant
+	}
+
+	c := ctx.Module().(*Module)
+	memtagHeap, memtagHeapSource := "off", "default"
+	if c.sanitize != nil {
+		sanitizeProps := &c.sanitize.Properties
+		if Bool(sanitizeProps.SanitizeMutated.Memtag_heap) {
+			memtagHeap = "async"
+			if Bool(sanitizeProps.SanitizeMutated.Diag.Memtag_heap) {
+				memtagHeap = "sync"
+			}
+		}
+		if sanitizeProps.Sanitize.Memtag_heap != nil {
+			memtagHeapSource = "module"
+		} else if sanitizeProps.MemtagHeapHardeningConfig != "" {
+			memtagHeapSource = sanitizeProps.MemtagHeapHardeningConfig
+		}
+	}
+
+	hardening.decision = &hardeningDecision{
+		Module:                 ctx.ModuleName(),
+		Variant:                ctx.ModuleSubDir(),
+		Partition:              ctx.Module().PartitionTag(ctx.DeviceConfig()),
+		MemtagHeap:             memtagHeap,
+		MemtagHeapSource:       memtagHeapSource,
+		BranchProtection:       branchProtection,
+		BranchProtectionSource: source,
+	}
+	return flags
+}
+
+func hardeningReportSingletonFactory() android.Singleton {
+	return &hardeningReportSingleton{}
+}
+
+// hardeningReportSingleton checks the HardeningConfigs product variable and writes the effective
+// hardening of each arm64 device cc module variant to hardening.json.
+type hardeningReportSingleton struct{}
+
+func (s *hardeningReportSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	for i, hardening := range ctx.Config().HardeningConfigs() {
+		if (hardening.Partition == nil) == (hardening.Path == nil) {
+			ctx.Errorf("HardeningConfigs[%d]: exactly one of Partition and Path must be set", i)
+		}
+		if v := hardening.MemtagHeap; v != nil && !android.InList(*v, memtagHeapModes) {
+			ctx.Errorf("HardeningConfigs[%d]: MemtagHeap must be one of %q, got %q", i, memtagHeapModes, *v)
+		}
+		if v := hardening.BranchProtection; v != nil && !android.InList(*v, branchProtectionModes) {
+			ctx.Errorf("HardeningConfigs[%d]: BranchProtection must be one of %q, got %q", i, branchProtectionModes, *v)
+		}
+	}
+
+	decisions := []hardeningDecision{}
+	ctx.VisitAllModules(func(module android.Module) {
+		ccModule, ok := module.(*Module)
+		if !ok || !ccModule.Enabled() {
+			return
+		}
+		for _, feature := range ccModule.features {
+			if hardening, ok := feature.(*hardeningFeature); ok && hardening.decision != nil {
+				decisions = append(decisions, *hardening.decision)
+			}
+		}
+	})
+	sort.Slice(decisions, func(i, j int) bool {
+		if decisions[i].Module != decisions[j].Module {
+			return decisions[i].Module < decisions[j].Module
+		}
+		return decisions[i].Variant < decisions[j].Variant
+	})
+
+	jsonStr, err := json.MarshalIndent(decisions, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	android.WriteFileRule(ctx, hardeningReportPath(ctx), string(jsonStr))
+}
+
+func hardeningReportPath(ctx android.PathContext) android.WritablePath {
+	return android.PathForOutput(ctx, "hardening.json")
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+
+	"android/soong/android"
+)
+
+func TestHardeningConfigs(t *testing.T) {
+	t.Parallel()
+	bp := `
+	cc_binary {
+		name: "bin_system",
+		srcs: ["foo.c"],
+	}
+	cc_binary {
+		name: "bin_override",
+		srcs: ["foo.c"],
+		branch_protection: "none",
+		sanitize: {
+			memtag_heap: false,
+		},
+	}`
+
+	result := android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureAddTextFile("secure/Android.bp", `
+			cc_binary {
+				name: "bin_secure",
+				srcs: ["foo.c"],
+			}`),
+		android.FixtureAddFile("secure/foo.c", nil),
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.HardeningConfigs = []android.HardeningConfig{
+				{
+					Partition:        proptools.StringPtr("system"),
+					MemtagHeap:       proptools.StringPtr("async"),
+					BranchProtection: proptools.StringPtr("pac-ret"),
+				},
+				{
+					Path:             proptools.StringPtr("secure"),
+					MemtagHeap:       proptools.StringPtr("sync"),
+					BranchProtection: proptools.StringPtr("standard"),
+				},
+			}
+		}),
+	).RunTestWithBp(t, bp)
+
+	variant := "android_arm64_armv8-a"
+	cFlags := func(name string) string {
+		return result.ModuleForTests(name, variant).Rule("cc").Args["cFlags"]
+	}
+	android.AssertStringDoesContain(t, "branch protection of the partition",
+		cFlags("bin_system"), "-mbranch-protection=pac-ret")
+	android.AssertStringDoesContain(t, "branch protection of the directory",
+		cFlags("bin_secure"), "-mbranch-protection=standard")
+	android.AssertStringDoesContain(t, "branch protection of the module",
+		cFlags("bin_override"), "-mbranch-protection=none")
+
+	report := result.SingletonForTests("hardening_report").Output("hardening.json")
+	var decisions []hardeningDecision
+	if err := json.Unmarshal([]byte(android.ContentFromFileRuleForTests(t, report)), &decisions); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]hardeningDecision{}
+	for _, decision := range decisions {
+		if decision.Variant == variant {
+			got[decision.Module] = decision
+		}
+	}
+	for _, want := range []hardeningDecision{
+		{"bin_override", variant, "system", "off", "module", "none", "module"},
+		{"bin_secure", variant, "system", "sync", "path secure", "standard", "path secure"},
+		{"bin_system", variant, "system", "async", "partition system", "pac-ret", "partition system"},
+	} {
+		android.AssertDeepEquals(t, "hardening.json entry for "+want.Module, want, got[want.Module])
+	}
+}
+
+func TestHardeningConfigsMustBeWellFormed(t *testing.T) {
+	t.Parallel()
+	android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.HardeningConfigs = []android.HardeningConfig{
+				{MemtagHeap: proptools.StringPtr("sync")},
+				{Partition: proptools.StringPtr("vendor"), MemtagHeap: proptools.StringPtr("always")},
+				{Path: proptools.StringPtr("secure"), BranchProtection: proptools.StringPtr("pac")},
+			}
+		}),
+	).ExtendWithErrorHandler(android.FixtureExpectsAllErrorsToMatchAPattern([]string{
+		`HardeningConfigs\[0\]: exactly one of Partition and Path must be set`,
+		`HardeningConfigs\[1\]: MemtagHeap must be one of`,
+		`HardeningConfigs\[2\]: BranchProtection must be one of`,
+	})).RunTest(t)
+}
+
+func TestBranchProtectionProperty(t *testing.T) {
+	t.Parallel()
+	testCcError(t, `branch_protection: must be one of`, `
+	cc_binary {
+		name: "bin",
+		srcs: ["foo.c"],
+		branch_protection: "pac",
+	}`)
+}
@@ -26,6 +26,7 @@ import (
 	"android/soong/android"
 	"android/soong/bazel"
 	"android/soong/bazel/cquery"
+	"android/soong/cc/config"
 
 	"github.com/google/blueprint"
 	"github.com/google/blueprint/pathtools"
@@ -44,6 +45,12 @@ type LibraryProperties struct {
 	// rename host libraries to prevent overlap with system installed libraries
 	Unique_host_soname *bool
 
+	// ABI version of the shared library, e.g. "1". On Linux and Android, the SONAME and the file
+	// name of the shared library become lib<name>.so.<abi_version>, and lib<name>.so is installed
+	// as a symlink to it for tools and images that expect traditionally versioned libraries.
+	// Not supported for libraries with stubs, or for VNDK and LLNDK libraries.
+	Abi_version *string
+
 	Aidl struct {
 		// export headers generated from .aidl sources
 		Export_aidl_headers *bool
@@ -81,6 +88,9 @@ type LibraryProperties struct {
 	// set the name of the output
 	Stem *string `android:"arch_variant"`
 
+	// Properties for the pkg-config file of the host variants of the library.
+	Pkg_config pkgConfigProperties
+
 	// set suffix of the name of the output
 	Suffix *string `android:"arch_variant"`
 
@@ -836,6 +846,9 @@ type libraryDecorator struct {
 
 	postInstallCmds []string
 
+	// The unversioned name that is installed as a symlink to a library with abi_version.
+	abiVersionSymlink string
+
 	// If useCoreVariant is true, the vendor variant of a VNDK library is
 	// not installed.
 	useCoreVariant       bool
@@ -1153,6 +1166,7 @@ func (library *libraryDecorator) linkerFlags(ctx ModuleContext, flags Flags) Fla
 	}
 
 	if library.shared() {
+		library.checkAbiVersion(ctx)
 		libName := library.getLibName(ctx)
 		var f []string
 		if ctx.toolchain().Bionic() {
@@ -1174,8 +1188,11 @@ func (library *libraryDecorator) linkerFlags(ctx ModuleContext, flags Flags) Fla
 			}
 		} else {
 			f = append(f, "-shared")
-			if !ctx.Windows() {
-				f = append(f, "-Wl,-soname,"+libName+flags.Toolchain.ShlibSuffix())
+			if ctx.Os() == android.Wasi {
+				// Dynamic linking of WebAssembly modules is still experimental in wasm-ld.
+				f = append(f, "-Wl,--experimental-pic")
+			} else if !ctx.Windows() {
+				f = append(f, "-Wl,-soname,"+library.sharedLibFileName(ctx, flags.Toolchain))
 			}
 		}
 
@@ -1323,6 +1340,7 @@ func (library *libraryDecorator) compile(ctx ModuleContext, flags Flags, deps Pa
 		if len(library.SharedProperties.Shared.Srcs) > 0 {
 			ctx.PropertyErrorf("shared.srcs", "cc_library_headers must not have any srcs")
 		}
+		library.checkHeaderOnly(ctx)
 		return Objects{}
 	}
 	if library.sabi.shouldCreateSourceAbiDump() {
@@ -1457,6 +1475,41 @@ func (library *libraryDecorator) getLibName(ctx BaseModuleContext) string {
 	return name
 }
 
+var abiVersionPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// abiVersion returns the ABI version that the shared library is versioned with, or "" if the
+// library is not versioned.
+func (library *libraryDecorator) abiVersion(ctx BaseModuleContext) string {
+	if !library.shared() || library.buildStubs() || !ctx.Os().Linux() {
+		return ""
+	}
+	return String(library.Properties.Abi_version)
+}
+
+// checkAbiVersion reports an error if the abi_version property is invalid for the library.
+func (library *libraryDecorator) checkAbiVersion(ctx ModuleContext) {
+	version := library.abiVersion(ctx)
+	if version == "" {
+		return
+	}
+	if !abiVersionPattern.MatchString(version) {
+		ctx.PropertyErrorf("abi_version", "must be a dot-separated list of numbers, got %q", version)
+	} else if library.hasStubsVariants() {
+		ctx.PropertyErrorf("abi_version", "is not supported for libraries with stubs")
+	} else if ctx.isVndk() || ctx.IsLlndk() {
+		ctx.PropertyErrorf("abi_version", "is not supported for VNDK and LLNDK libraries")
+	}
+}
+
+// sharedLibFileName returns the file name of the shared library, which is also its SONAME.
+func (library *libraryDecorator) sharedLibFileName(ctx BaseModuleContext, toolchain config.Toolchain) string {
+	fileName := library.getLibName(ctx) + toolchain.ShlibSuffix()
+	if version := library.abiVersion(ctx); version != "" {
+		fileName += "." + version
+	}
+	return fileName
+}
+
 var versioningMacroNamesListMutex sync.Mutex
 
 func (library *libraryDecorator) linkerInit(ctx BaseModuleContext) {
@@ -1636,6 +1689,11 @@ func (library *libraryDecorator) linkStatic(ctx ModuleContext,
 
 	transformObjToStaticLib(ctx, library.objects.objFiles, deps.WholeStaticLibsFromPrebuilts, builderFlags, outputFile, nil, objs.tidyDepFiles)
 
+	if builderFlags.distributedThinLTO && len(deps.WholeStaticLibsFromPrebuilts) == 0 {
+		// Links with distributed ThinLTO use the objects instead of the static library.
+		thinLTOArchiveObjects(ctx.Config()).Store(outputFile.String(), library.objects.objFiles)
+	}
+
 	library.coverageOutputFile = transformCoverageFilesToZip(ctx, library.objects, ctx.ModuleName())
 
 	ctx.CheckbuildFile(outputFile)
@@ -1710,9 +1768,12 @@ func (library *libraryDecorator) linkShared(ctx ModuleContext,
 		linkerDeps = append(linkerDeps, library.versionScriptPath.Path())
 	}
 
-	fileName := library.getLibName(ctx) + flags.Toolchain.ShlibSuffix()
+	fileName := library.sharedLibFileName(ctx, flags.Toolchain)
 	outputFile := android.PathForModuleOut(ctx, fileName)
 	unstrippedOutputFile := outputFile
+	if library.abiVersion(ctx) != "" {
+		library.abiVersionSymlink = library.getLibName(ctx) + flags.Toolchain.ShlibSuffix()
+	}
 
 	var implicitOutputs android.WritablePaths
 	if ctx.Windows() {
@@ -1732,7 +1793,7 @@ func (library *libraryDecorator) linkShared(ctx ModuleContext,
 
 	// Optimize out relinking against shared libraries whose interface hasn't changed by
 	// depending on a table of contents file instead of the library itself.
-	tocFile := outputFile.ReplaceExtension(ctx, flags.Toolchain.ShlibSuffix()[1:]+".toc")
+	tocFile := outputFile.InSameDir(ctx, library.getLibName(ctx)+flags.Toolchain.ShlibSuffix()+".toc")
 	library.tocFile = android.OptionalPathForPath(tocFile)
 	TransformSharedObjectToToc(ctx, outputFile, tocFile)
 
@@ -2285,6 +2346,10 @@ func (library *libraryDecorator) install(ctx ModuleContext, file android.Path) {
 		}
 
 		library.baseInstaller.install(ctx, file)
+		if library.abiVersionSymlink != "" {
+			ctx.InstallSymlink(library.baseInstaller.installDir(ctx), library.abiVersionSymlink,
+				library.baseInstaller.path)
+		}
 	}
 
 	if Bool(library.Properties.Static_ndk_lib) && library.static() &&
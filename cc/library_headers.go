@@ -15,6 +15,8 @@
 package cc
 
 import (
+	"encoding/json"
+
 	"github.com/google/blueprint/proptools"
 
 	"android/soong/android"
@@ -118,6 +120,123 @@ func prebuiltLibraryHeaderFactory() android.Module {
 	return module.Init()
 }
 
+// checkHeaderOnly reports the properties of a cc_library_headers module that would add objects or
+// link-time dependencies, which the modules that use it don't get.
+func (library *libraryDecorator) checkHeaderOnly(ctx ModuleContext) {
+	if len(library.baseCompiler.Properties.Generated_sources) > 0 {
+		ctx.PropertyErrorf("generated_sources", "cc_library_headers must not have any generated_sources, use generated_headers instead")
+	}
+	props := &library.baseLinker.Properties
+	if len(props.Whole_static_libs) > 0 {
+		ctx.PropertyErrorf("whole_static_libs", "cc_library_headers has no objects to include the libraries in")
+	}
+	for _, lib := range props.Static_libs {
+		if !inList(lib, props.Export_static_lib_headers) {
+			ctx.PropertyErrorf("static_libs", "%q is not linked into the modules that use cc_library_headers, "+
+				"use header_libs for its headers, or re-export them with export_static_lib_headers", lib)
+		}
+	}
+	for _, lib := range props.Shared_libs {
+		if !inList(lib, props.Export_shared_lib_headers) {
+			ctx.PropertyErrorf("shared_libs", "%q is not linked into the modules that use cc_library_headers, "+
+				"use header_libs for its headers, or re-export them with export_shared_lib_headers", lib)
+		}
+	}
+}
+
+// hasObjects returns true if the library compiles or includes any objects, false if its users
+// only get its headers.
+func (library *libraryDecorator) hasObjects() bool {
+	return len(library.baseCompiler.Properties.Srcs) > 0 ||
+		len(library.baseCompiler.Properties.Generated_sources) > 0 ||
+		len(library.StaticProperties.Static.Srcs) > 0 ||
+		len(library.SharedProperties.Shared.Srcs) > 0 ||
+		len(library.baseLinker.Properties.Whole_static_libs) > 0 ||
+		len(library.StaticProperties.Static.Whole_static_libs) > 0 ||
+		len(library.SharedProperties.Shared.Whole_static_libs) > 0
+}
+
+func headerLibrariesReportSingletonFactory() android.Singleton {
+	return &headerLibrariesReportSingleton{}
+}
+
+// headerLibrariesReportSingleton writes header_libraries.json, which lists the libraries that
+// could be converted to cc_library_headers, and the header libraries whose users must link
+// against the libraries whose headers they re-export.
+type headerLibrariesReportSingleton struct{}
+
+type headerLibrariesReport struct {
+	// Static and shared libraries without any objects, which could be cc_library_headers.
+	ConvertibleLibraries []headerLibrariesReportEntry `json:"convertible_libraries"`
+
+	// Header libraries that re-export the headers of static or shared libraries.
+	HeaderLibrariesWithLinkDeps []headerLibrariesReportEntry `json:"header_libraries_with_link_deps"`
+}
+
+type headerLibrariesReportEntry struct {
+	Module string   `json:"module"`
+	Dir    string   `json:"dir"`
+	Libs   []string `json:"libs,omitempty"`
+}
+
+func (s *headerLibrariesReportSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	// A library is convertible if none of its variants has objects.
+	convertible := make(map[string]bool)
+	dirs := make(map[string]string)
+	linkDeps := make(map[string][]string)
+	ctx.VisitAllModules(func(module android.Module) {
+		ccModule, ok := module.(*Module)
+		if !ok || !ccModule.Enabled() {
+			return
+		}
+		library, ok := ccModule.linker.(*libraryDecorator)
+		if !ok {
+			return
+		}
+		name := ctx.ModuleName(module)
+		dirs[name] = ctx.ModuleDir(module)
+		if library.header() {
+			props := &library.baseLinker.Properties
+			libs := append(android.CopyOf(props.Export_static_lib_headers), props.Export_shared_lib_headers...)
+			linkDeps[name] = android.SortedUniqueStrings(append(linkDeps[name], libs...))
+			return
+		}
+		if ccModule.IsStubs() || library.hasStubsVariants() || library.hasLLNDKStubs() || library.hasVendorPublicLibrary() {
+			convertible[name] = false
+			return
+		}
+		if old, seen := convertible[name]; !seen || old {
+			convertible[name] = !library.hasObjects()
+		}
+	})
+
+	report := headerLibrariesReport{
+		ConvertibleLibraries:        []headerLibrariesReportEntry{},
+		HeaderLibrariesWithLinkDeps: []headerLibrariesReportEntry{},
+	}
+	for _, name := range android.SortedKeys(convertible) {
+		if convertible[name] {
+			report.ConvertibleLibraries = append(report.ConvertibleLibraries,
+				headerLibrariesReportEntry{Module: name, Dir: dirs[name]})
+		}
+	}
+	for _, name := range android.SortedKeys(linkDeps) {
+		if len(linkDeps[name]) > 0 {
+			report.HeaderLibrariesWithLinkDeps = append(report.HeaderLibrariesWithLinkDeps,
+				headerLibrariesReportEntry{Module: name, Dir: dirs[name], Libs: linkDeps[name]})
+		}
+	}
+
+	jsonStr, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	output := android.PathForOutput(ctx, "header_libraries.json")
+	android.WriteFileRule(ctx, output, string(jsonStr))
+	ctx.Phony("header_libraries_report", output)
+}
+
 type bazelCcLibraryHeadersAttributes struct {
 	Hdrs                     bazel.LabelListAttribute
 	Export_includes          bazel.StringListAttribute
@@ -15,6 +15,7 @@
 package cc
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -98,3 +99,124 @@ func TestPrebuiltLibraryHeadersPreferred(t *testing.T) {
 		})
 	}
 }
+
+func TestLibraryHeadersOnlyExportHeaders(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name  string
+		props string
+		err   string
+	}{
+		{
+			name:  "generated_sources",
+			props: `generated_sources: ["gen"],`,
+			err:   `generated_sources: cc_library_headers must not have any generated_sources`,
+		},
+		{
+			name:  "whole_static_libs",
+			props: `whole_static_libs: ["libstatic"],`,
+			err:   `whole_static_libs: cc_library_headers has no objects to include the libraries in`,
+		},
+		{
+			name:  "static_libs",
+			props: `static_libs: ["libstatic"],`,
+			err:   `static_libs: "libstatic" is not linked into the modules that use cc_library_headers`,
+		},
+		{
+			name:  "shared_libs",
+			props: `shared_libs: ["libshared"],`,
+			err:   `shared_libs: "libshared" is not linked into the modules that use cc_library_headers`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testCcError(t, tc.err, fmt.Sprintf(`
+				cc_library_headers {
+					name: "headers",
+					%s
+				}
+				cc_library_static {
+					name: "libstatic",
+				}
+				cc_library_shared {
+					name: "libshared",
+				}
+				genrule {
+					name: "gen",
+					cmd: "touch $(out)",
+					out: ["gen.c"],
+				}
+			`, tc.props))
+		})
+	}
+}
+
+func TestHeaderLibrariesReport(t *testing.T) {
+	t.Parallel()
+	ctx := testCc(t, `
+		cc_library_headers {
+			name: "headers",
+			export_include_dirs: ["include"],
+		}
+		cc_library_headers {
+			name: "headers_with_link_deps",
+			static_libs: ["libstatic"],
+			export_static_lib_headers: ["libstatic"],
+			shared_libs: ["libshared"],
+			export_shared_lib_headers: ["libshared"],
+		}
+		cc_library {
+			name: "libheaders_only",
+			export_include_dirs: ["include"],
+		}
+		cc_library_static {
+			name: "libstatic",
+			srcs: ["foo.c"],
+		}
+		cc_library_shared {
+			name: "libshared",
+			srcs: ["foo.c"],
+		}
+		cc_library_static {
+			name: "libwhole",
+			whole_static_libs: ["libstatic"],
+		}
+		cc_library_static {
+			name: "libarch_srcs",
+			arch: {
+				arm64: {
+					srcs: ["foo.c"],
+				},
+			},
+		}
+	`)
+
+	report := ctx.SingletonForTests("header_libraries_report").Output("header_libraries.json")
+	var got headerLibrariesReport
+	if err := json.Unmarshal([]byte(android.ContentFromFileRuleForTests(t, report)), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	// The test fixture has libraries without objects as well, only check the ones of the test.
+	convertible := map[string]headerLibrariesReportEntry{}
+	for _, entry := range got.ConvertibleLibraries {
+		convertible[entry.Module] = entry
+	}
+	android.AssertDeepEquals(t, "convertible libheaders_only",
+		headerLibrariesReportEntry{Module: "libheaders_only", Dir: "."}, convertible["libheaders_only"])
+	for _, name := range []string{"headers", "libstatic", "libshared", "libwhole", "libarch_srcs"} {
+		if _, ok := convertible[name]; ok {
+			t.Errorf("%s is not expected to be convertible to cc_library_headers", name)
+		}
+	}
+
+	linkDeps := map[string]headerLibrariesReportEntry{}
+	for _, entry := range got.HeaderLibrariesWithLinkDeps {
+		linkDeps[entry.Module] = entry
+	}
+	android.AssertDeepEquals(t, "link deps of headers_with_link_deps",
+		headerLibrariesReportEntry{Module: "headers_with_link_deps", Dir: ".", Libs: []string{"libshared", "libstatic"}},
+		linkDeps["headers_with_link_deps"])
+	if _, ok := linkDeps["headers"]; ok {
+		t.Errorf("headers is not expected to have link deps")
+	}
+}
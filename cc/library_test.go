@@ -413,6 +413,57 @@ func TestLibraryDynamicList(t *testing.T) {
 
 }
 
+func TestLibraryAbiVersion(t *testing.T) {
+	t.Parallel()
+	result := PrepareForIntegrationTestWithCc.RunTestWithBp(t, `
+		cc_library {
+			name: "libfoo",
+			srcs: ["foo.c"],
+			abi_version: "1",
+		}
+
+		cc_binary {
+			name: "bar",
+			srcs: ["bar.c"],
+			shared_libs: ["libfoo"],
+		}`)
+
+	libfoo := result.ModuleForTests("libfoo", "android_arm64_armv8-a_shared")
+	ld := libfoo.Rule("ld")
+	android.AssertStringEquals(t, "versioned output file", "libfoo.so.1", ld.Output.Base())
+	android.AssertStringDoesContain(t, "missing versioned soname",
+		ld.Args["ldFlags"], "-Wl,-soname,libfoo.so.1")
+
+	installDir := "out/soong/target/product/test_device/system/lib64/"
+	libfoo.Output(installDir + "libfoo.so.1")
+	symlink := libfoo.Output(installDir + "libfoo.so")
+	android.AssertStringEquals(t, "compatibility symlink target", "libfoo.so.1", symlink.Args["fromPath"])
+
+	barLink := result.ModuleForTests("bar", "android_arm64_armv8-a").Rule("ld")
+	android.AssertStringDoesContain(t, "binary not linked against the versioned library",
+		barLink.Args["libFlags"], "/libfoo.so.1")
+
+	// The static variant is not versioned.
+	result.ModuleForTests("libfoo", "android_arm64_armv8-a_static").Output("libfoo.a")
+}
+
+func TestLibraryAbiVersionErrors(t *testing.T) {
+	t.Parallel()
+	testCcError(t, `module "libfoo".*: abi_version: must be a dot-separated list of numbers, got "v1"`, `
+		cc_library {
+			name: "libfoo",
+			abi_version: "v1",
+		}`)
+	testCcError(t, `module "libbar".*: abi_version: is not supported for libraries with stubs`, `
+		cc_library {
+			name: "libbar",
+			abi_version: "1",
+			stubs: {
+				versions: ["29"],
+			},
+		}`)
+}
+
 func TestCcLibrarySharedWithBazelValidations(t *testing.T) {
 	t.Parallel()
 	bp := `
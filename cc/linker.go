@@ -236,6 +236,11 @@ type BaseLinkerProperties struct {
 
 	// list of shared libs that should not be used to build this module
 	Exclude_shared_libs []string `android:"arch_variant"`
+
+	// Generate Breakpad symbols for the binary or shared library with dump_syms, and add
+	// them to the breakpad_symbols.zip dist artifact.  Defaults to the BreakpadSymbols
+	// product variable.
+	Breakpad_symbols *bool `android:"arch_variant"`
 }
 
 func (blp *BaseLinkerProperties) crt() bool {
@@ -450,7 +455,10 @@ func (linker *baseLinker) linkerFlags(ctx ModuleContext, flags Flags) Flags {
 	}
 
 	if linker.useClangLld(ctx) {
-		flags.Global.LdFlags = append(flags.Global.LdFlags, fmt.Sprintf("${config.%sGlobalLldflags}", hod))
+		// wasm-ld doesn't support the global lld flags, the wasi toolchain provides its own.
+		if ctx.Os() != android.Wasi {
+			flags.Global.LdFlags = append(flags.Global.LdFlags, fmt.Sprintf("${config.%sGlobalLldflags}", hod))
+		}
 		if !BoolDefault(linker.Properties.Pack_relocations, packRelocationsDefault) {
 			flags.Global.LdFlags = append(flags.Global.LdFlags, "-Wl,--pack-dyn-relocs=none")
 		} else if ctx.Device() {
@@ -476,7 +484,7 @@ func (linker *baseLinker) linkerFlags(ctx ModuleContext, flags Flags) Flags {
 			// darwin defaults to treating undefined symbols as errors
 			flags.Global.LdFlags = append(flags.Global.LdFlags, "-Wl,-undefined,dynamic_lookup")
 		}
-	} else if !ctx.Darwin() && !ctx.Windows() {
+	} else if !ctx.Darwin() && !ctx.Windows() && ctx.Os() != android.Wasi {
 		flags.Global.LdFlags = append(flags.Global.LdFlags, "-Wl,--no-undefined")
 	}
 
@@ -491,7 +499,7 @@ func (linker *baseLinker) linkerFlags(ctx ModuleContext, flags Flags) Flags {
 
 		flags.Local.LdFlags = append(flags.Local.LdFlags, linker.Properties.Host_ldlibs...)
 
-		if !ctx.Windows() {
+		if !ctx.Windows() && ctx.Os() != android.Wasi && !config.CrossSysrootOs(ctx.Os()) {
 			// Add -ldl, -lpthread, -lm and -lrt to host builds to match the default behavior of device
 			// builds
 			flags.Global.LdFlags = append(flags.Global.LdFlags,
@@ -506,10 +514,11 @@ func (linker *baseLinker) linkerFlags(ctx ModuleContext, flags Flags) Flags {
 	}
 
 	CheckBadLinkerFlags(ctx, "ldflags", linker.Properties.Ldflags)
+	checkFlagPolicy(ctx, "ldflags", linker.Properties.Ldflags, restrictedLinkerFlags)
 
 	flags.Local.LdFlags = append(flags.Local.LdFlags, proptools.NinjaAndShellEscapeList(linker.Properties.Ldflags)...)
 
-	if ctx.Host() && !ctx.Windows() && !ctx.static() {
+	if ctx.Host() && !ctx.Windows() && ctx.Os() != android.Wasi && !config.CrossSysrootOs(ctx.Os()) && !ctx.static() {
 		flags.Global.LdFlags = append(flags.Global.LdFlags, RpathFlags(ctx)...)
 	}
 
@@ -15,7 +15,13 @@
 package cc
 
 import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
 	"android/soong/android"
+	"android/soong/cc/config"
 
 	"github.com/google/blueprint/proptools"
 )
@@ -37,6 +43,16 @@ import (
 //
 // This file adds support to soong to automatically propogate LTO options to a
 // new variant of all static dependencies for each module with LTO enabled.
+//
+// Products enable ThinLTO by default with the GlobalThinLto product variable,
+// and exempt modules from it by directory or by name with LtoExemptions, which
+// behave like `lto: { never: true }` for the exempted modules. The effective
+// LTO mode of each module variant, and why, is listed in $OUT/soong/lto.json.
+//
+// With USE_DISTRIBUTED_THINLTO=true, ThinLTO links of device modules are split into a thin link
+// that only writes the ThinLTO indexes, a backend action per bitcode object that generates its
+// native object, and a final link of the native objects. The backend actions can run remotely with
+// RBE_THINLTO_BACKEND=true and are cached like compiles, instead of running in the linker process.
 
 type LTOProperties struct {
 	// Lto must violate capitialization style for acronyms so that it can be
@@ -65,6 +81,10 @@ type LTOProperties struct {
 
 type lto struct {
 	Properties LTOProperties
+
+	// The effective LTO mode of the module and why, computed in flags() for the report.
+	mode   string
+	reason string
 }
 
 func (lto *lto) props() []interface{} {
@@ -75,6 +95,9 @@ func (lto *lto) begin(ctx BaseModuleContext) {
 	if ctx.Config().IsEnvTrue("DISABLE_LTO") {
 		lto.Properties.NoLtoEnabled = true
 	}
+	if _, exempt := ctx.Config().LtoExemptionFor(ctx.ModuleName(), ctx.ModuleDir()); exempt {
+		lto.Properties.NoLtoEnabled = true
+	}
 }
 
 func (lto *lto) useClangLld(ctx BaseModuleContext) bool {
@@ -88,14 +111,18 @@ func (lto *lto) flags(ctx BaseModuleContext, flags Flags) Flags {
 	// TODO(b/131771163): Disable LTO when using explicit fuzzing configurations.
 	// LTO breaks fuzzer builds.
 	if inList("-fsanitize=fuzzer-no-link", flags.Local.CFlags) {
+		lto.mode, lto.reason = "none", "built with the fuzzer sanitizer"
 		return flags
 	}
 
 	// TODO(b/254713216): LTO doesn't work on riscv64 yet.
 	if ctx.Arch().ArchType == android.Riscv64 {
+		lto.mode, lto.reason = "none", "not supported on riscv64"
 		return flags
 	}
 
+	lto.mode, lto.reason = lto.effectiveMode(ctx)
+
 	if lto.LTO(ctx) {
 		var ltoCFlag string
 		var ltoLdFlag string
@@ -117,7 +144,9 @@ func (lto *lto) flags(ctx BaseModuleContext, flags Flags) Flags {
 			flags.Local.CFlags = append(flags.Local.CFlags, "-fwhole-program-vtables")
 		}
 
-		if (lto.DefaultThinLTO(ctx) || lto.ThinLTO()) && ctx.Config().IsEnvTrue("USE_THINLTO_CACHE") && lto.useClangLld(ctx) {
+		if lto.distributedThinLTO(ctx) {
+			flags.DistributedThinLTO = true
+		} else if (lto.DefaultThinLTO(ctx) || lto.ThinLTO()) && ctx.Config().IsEnvTrue("USE_THINLTO_CACHE") && lto.useClangLld(ctx) {
 			// Set appropriate ThinLTO cache policy
 			cacheDirFormat := "-Wl,--thinlto-cache-dir="
 			cacheDir := android.PathForOutput(ctx, "thinlto-cache").String()
@@ -144,6 +173,67 @@ func (lto *lto) LTO(ctx BaseModuleContext) bool {
 	return lto.ThinLTO() || lto.FullLTO() || lto.DefaultThinLTO(ctx)
 }
 
+// distributedThinLTO returns true if the ThinLTO backend of the module runs in separate actions
+// instead of in the linker.
+func (lto *lto) distributedThinLTO(ctx BaseModuleContext) bool {
+	if !ctx.Config().IsEnvTrue("USE_DISTRIBUTED_THINLTO") {
+		return false
+	}
+	thin := lto.ThinLTO() || (lto.DefaultThinLTO(ctx) && !lto.FullLTO())
+	return thin && ctx.Device() && lto.useClangLld(ctx)
+}
+
+var thinLTOArchiveObjectsKey = android.NewOnceKey("ThinLTOArchiveObjects")
+
+// thinLTOArchiveObjects returns the map from the paths of the static libraries built with
+// distributed ThinLTO to their objects, which the links with distributed ThinLTO use instead.
+func thinLTOArchiveObjects(config android.Config) *sync.Map {
+	return getNamedMapForConfig(config, thinLTOArchiveObjectsKey)
+}
+
+// thinLTOBackendFlags returns the flags of the ThinLTO backend actions of a link, the code
+// generation flags that the linker would otherwise pass to the LTO backend.
+func thinLTOBackendFlags(toolchain config.Toolchain, ldFlags string) string {
+	optLevel := "-O2"
+	flags := []string{"-target", toolchain.ClangTriple(), "-ffunction-sections", "-fdata-sections"}
+	for _, flag := range strings.Fields(ldFlags) {
+		switch {
+		case strings.HasPrefix(flag, "-Wl,--lto-O"):
+			optLevel = "-O" + strings.TrimPrefix(flag, "-Wl,--lto-O")
+		case strings.HasPrefix(flag, "-Wl,-plugin-opt,O"):
+			optLevel = "-O" + strings.TrimPrefix(flag, "-Wl,-plugin-opt,O")
+		case strings.HasPrefix(flag, "-Wl,-plugin-opt,-"):
+			flags = append(flags, "-mllvm", strings.TrimPrefix(flag, "-Wl,-plugin-opt,"))
+		case strings.HasPrefix(flag, "-Wl,-mllvm,"):
+			flags = append(flags, "-mllvm", strings.TrimPrefix(flag, "-Wl,-mllvm,"))
+		case strings.HasPrefix(flag, "-Wl,-mllvm="):
+			flags = append(flags, "-mllvm", strings.TrimPrefix(flag, "-Wl,-mllvm="))
+		case strings.HasPrefix(flag, "-fprofile-sample-use="):
+			flags = append(flags, flag)
+		}
+	}
+	return strings.Join(append([]string{optLevel}, flags...), " ")
+}
+
+// excludedLibsFromLdFlags returns the names of the static libraries passed to --exclude-libs.
+func excludedLibsFromLdFlags(ldFlags string) map[string]bool {
+	excluded := make(map[string]bool)
+	for _, flag := range strings.Fields(ldFlags) {
+		var libs string
+		if strings.HasPrefix(flag, "-Wl,--exclude-libs,") {
+			libs = strings.TrimPrefix(flag, "-Wl,--exclude-libs,")
+		} else if strings.HasPrefix(flag, "-Wl,--exclude-libs=") {
+			libs = strings.TrimPrefix(flag, "-Wl,--exclude-libs=")
+		} else {
+			continue
+		}
+		for _, lib := range strings.FieldsFunc(libs, func(r rune) bool { return r == ',' || r == ':' }) {
+			excluded[lib] = true
+		}
+	}
+	return excluded
+}
+
 func (lto *lto) DefaultThinLTO(ctx BaseModuleContext) bool {
 	// LP32 has many subtle issues and less test coverage.
 	lib32 := ctx.Arch().ArchType.Multilib == "lib32"
@@ -158,6 +248,51 @@ func (lto *lto) DefaultThinLTO(ctx BaseModuleContext) bool {
 	return GlobalThinLTO(ctx) && !lto.Never() && !lib32 && !cfi && !host && !test && !vndk
 }
 
+// effectiveMode returns the LTO mode that the module is built with, "full", "thin" or "none", and
+// why.
+func (lto *lto) effectiveMode(ctx BaseModuleContext) (string, string) {
+	switch {
+	case proptools.Bool(lto.Properties.Lto.Full):
+		return "full", "lto.full is set"
+	case lto.Properties.FullEnabled:
+		return "full", "static dependency of a module with full LTO"
+	case proptools.Bool(lto.Properties.Lto.Thin):
+		return "thin", "lto.thin is set"
+	case lto.Properties.ThinEnabled:
+		return "thin", "static dependency of a module with ThinLTO"
+	case lto.DefaultThinLTO(ctx):
+		return "thin", "ThinLTO by default"
+	case !lto.Never() && GlobalThinLTO(ctx):
+		// The module is not eligible for ThinLTO by default.
+		switch {
+		case ctx.Arch().ArchType.Multilib == "lib32":
+			return "none", "ThinLTO by default is not supported for 32-bit variants"
+		case ctx.isCfi():
+			return "none", "CFI requires full LTO"
+		case ctx.Host():
+			return "none", "ThinLTO by default is not supported for host variants"
+		case ctx.testBinary() || ctx.testLibrary():
+			return "none", "ThinLTO by default is not supported for tests"
+		case ctx.isVndk():
+			return "none", "ThinLTO by default is not supported for VNDK libraries"
+		}
+	}
+
+	switch {
+	case proptools.Bool(lto.Properties.Lto.Never):
+		return "none", "lto.never is set"
+	case ctx.Config().IsEnvTrue("DISABLE_LTO"):
+		return "none", "DISABLE_LTO is set"
+	}
+	if exemption, exempt := ctx.Config().LtoExemptionFor(ctx.ModuleName(), ctx.ModuleDir()); exempt {
+		return "none", "exempted by LtoExemptions: " + proptools.String(exemption.Reason)
+	}
+	if lto.Properties.NoLtoEnabled {
+		return "none", "static dependency of a module without LTO"
+	}
+	return "none", "ThinLTO by default is not enabled"
+}
+
 func (lto *lto) FullLTO() bool {
 	return lto != nil && (proptools.Bool(lto.Properties.Lto.Full) || lto.Properties.FullEnabled)
 }
@@ -171,7 +306,7 @@ func (lto *lto) Never() bool {
 }
 
 func GlobalThinLTO(ctx android.BaseModuleContext) bool {
-	return ctx.Config().IsEnvTrue("GLOBAL_THINLTO")
+	return ctx.Config().GlobalThinLto()
 }
 
 // Propagate lto requirements down from binaries
@@ -280,3 +415,61 @@ func ltoMutator(mctx android.BottomUpMutatorContext) {
 		}
 	}
 }
+
+func ltoReportSingletonFactory() android.Singleton {
+	return &ltoReportSingleton{}
+}
+
+// ltoReportSingleton checks the LtoExemptions product variable and writes the effective LTO mode
+// of each cc module variant to lto.json.
+type ltoReportSingleton struct{}
+
+// ltoReportEntry is an entry of lto.json.
+type ltoReportEntry struct {
+	Module  string `json:"module"`
+	Variant string `json:"variant"`
+	Lto     string `json:"lto"`
+	Reason  string `json:"reason"`
+}
+
+func (s *ltoReportSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	for i, exemption := range ctx.Config().LtoExemptions() {
+		if (exemption.Path == nil) == (exemption.Module == nil) {
+			ctx.Errorf("LtoExemptions[%d]: exactly one of Path and Module must be set", i)
+		}
+		if proptools.String(exemption.Reason) == "" {
+			ctx.Errorf("LtoExemptions[%d]: Reason must be set", i)
+		}
+	}
+
+	entries := []ltoReportEntry{}
+	ctx.VisitAllModules(func(module android.Module) {
+		ccModule, ok := module.(*Module)
+		if !ok || !ccModule.Enabled() || ccModule.lto == nil || ccModule.lto.mode == "" {
+			return
+		}
+		entries = append(entries, ltoReportEntry{
+			Module:  ctx.ModuleName(module),
+			Variant: ctx.ModuleSubDir(module),
+			Lto:     ccModule.lto.mode,
+			Reason:  ccModule.lto.reason,
+		})
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Module != entries[j].Module {
+			return entries[i].Module < entries[j].Module
+		}
+		return entries[i].Variant < entries[j].Variant
+	})
+
+	jsonStr, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	android.WriteFileRule(ctx, ltoReportPath(ctx), string(jsonStr))
+}
+
+func ltoReportPath(ctx android.PathContext) android.WritablePath {
+	return android.PathForOutput(ctx, "lto.json")
+}
@@ -15,12 +15,15 @@
 package cc
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
 	"android/soong/android"
+	"android/soong/cc/config"
 
 	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
 )
 
 func TestThinLtoDeps(t *testing.T) {
@@ -239,3 +242,166 @@ func TestLtoDoesNotPropagateToRuntimeLibs(t *testing.T) {
 	android.AssertStringDoesNotContain(t, "got flag for LTO in runtime_lib",
 		libBar.Args["ldFlags"], "-flto=thin")
 }
+
+func TestGlobalThinLtoExemptions(t *testing.T) {
+	t.Parallel()
+	bp := `
+	cc_library_shared {
+		name: "libdefault",
+		srcs: ["foo.c"],
+	}
+	cc_library_shared {
+		name: "libexempt_by_module",
+		srcs: ["foo.c"],
+	}
+	cc_library_shared {
+		name: "libthin",
+		srcs: ["foo.c"],
+		lto: {
+			thin: true,
+		},
+	}`
+
+	result := android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureAddTextFile("exempt/Android.bp", `
+			cc_library_shared {
+				name: "libexempt_by_path",
+				srcs: ["foo.c"],
+			}`),
+		android.FixtureAddFile("exempt/foo.c", nil),
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.GlobalThinLto = proptools.BoolPtr(true)
+			variables.LtoExemptions = []android.LtoExemption{
+				{Path: proptools.StringPtr("exempt/"), Reason: proptools.StringPtr("b/1")},
+				{Module: proptools.StringPtr("libexempt_by_module"), Reason: proptools.StringPtr("b/2")},
+			}
+		}),
+	).RunTestWithBp(t, bp)
+
+	variant := "android_arm64_armv8-a_shared"
+	ldFlags := func(name string) string {
+		return result.ModuleForTests(name, variant).Rule("ld").Args["ldFlags"]
+	}
+	android.AssertStringDoesContain(t, "missing flag for ThinLTO by default",
+		ldFlags("libdefault"), "-flto=thin")
+	android.AssertStringDoesNotContain(t, "got flag for LTO in module exempted by path",
+		ldFlags("libexempt_by_path"), "-flto=thin")
+	android.AssertStringDoesNotContain(t, "got flag for LTO in module exempted by name",
+		ldFlags("libexempt_by_module"), "-flto=thin")
+	android.AssertStringDoesContain(t, "missing flag for LTO in module with lto.thin",
+		ldFlags("libthin"), "-flto=thin")
+
+	report := result.SingletonForTests("lto_report").Output("lto.json")
+	var entries []ltoReportEntry
+	if err := json.Unmarshal([]byte(android.ContentFromFileRuleForTests(t, report)), &entries); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]ltoReportEntry{}
+	for _, entry := range entries {
+		if entry.Variant == variant {
+			got[entry.Module] = entry
+		}
+	}
+	for _, want := range []ltoReportEntry{
+		{"libdefault", variant, "thin", "ThinLTO by default"},
+		{"libexempt_by_path", variant, "none", "exempted by LtoExemptions: b/1"},
+		{"libexempt_by_module", variant, "none", "exempted by LtoExemptions: b/2"},
+		{"libthin", variant, "thin", "lto.thin is set"},
+	} {
+		android.AssertDeepEquals(t, "lto.json entry for "+want.Module, want, got[want.Module])
+	}
+}
+
+func TestLtoExemptionsMustBeWellFormed(t *testing.T) {
+	t.Parallel()
+	android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.LtoExemptions = []android.LtoExemption{
+				{Path: proptools.StringPtr("exempt"), Module: proptools.StringPtr("libfoo"), Reason: proptools.StringPtr("b/1")},
+				{Module: proptools.StringPtr("libbar")},
+			}
+		}),
+	).ExtendWithErrorHandler(android.FixtureExpectsAllErrorsToMatchAPattern([]string{
+		`LtoExemptions\[0\]: exactly one of Path and Module must be set`,
+		`LtoExemptions\[1\]: Reason must be set`,
+	})).RunTest(t)
+}
+
+func TestDistributedThinLto(t *testing.T) {
+	t.Parallel()
+	bp := `
+	cc_binary {
+		name: "foo",
+		srcs: ["foo.c"],
+		static_libs: ["bar"],
+		lto: {
+			thin: true,
+		},
+	}
+	cc_library_static {
+		name: "bar",
+		srcs: ["bar.c"],
+	}
+`
+
+	result := android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureMergeEnv(map[string]string{"USE_DISTRIBUTED_THINLTO": "true"}),
+	).RunTestWithBp(t, bp)
+
+	foo := result.ModuleForTests("foo", "android_arm64_armv8-a")
+	fooObj := "out/soong/.intermediates/foo/android_arm64_armv8-a/obj/foo.o"
+	barObj := "out/soong/.intermediates/bar/android_arm64_armv8-a_static_lto-thin/obj/bar.o"
+
+	thinLink := foo.Rule("thinLink").RelativeToTop()
+	android.AssertPathsRelativeToTopEquals(t, "thin link inputs", []string{fooObj}, thinLink.Inputs)
+	android.AssertStringDoesContain(t, "thin link libFlags", thinLink.Args["libFlags"],
+		"-Wl,--start-lib "+barObj+" -Wl,--end-lib")
+	android.AssertStringDoesNotContain(t, "thin link libFlags", thinLink.Args["libFlags"], "bar.a")
+
+	fooBackend := foo.Description("thinlto backend foo.o")
+	android.AssertPathRelativeToTopEquals(t, "foo.o backend input", fooObj, fooBackend.Input)
+	android.AssertStringDoesContain(t, "foo.o backend flags", fooBackend.Args["backendFlags"], "-target aarch64-linux-android")
+	barBackend := foo.Description("thinlto backend bar.o")
+	android.AssertPathRelativeToTopEquals(t, "bar.o backend input", barObj, barBackend.Input)
+
+	ld := foo.Rule("ld")
+	android.AssertPathsRelativeToTopEquals(t, "link inputs",
+		[]string{fooBackend.RelativeToTop().Output.String()}, ld.Inputs)
+	android.AssertStringDoesContain(t, "link libFlags", ld.Args["libFlags"],
+		"-Wl,--start-lib "+barBackend.Output.String()+" -Wl,--end-lib")
+	android.AssertStringDoesNotContain(t, "link libFlags", ld.Args["libFlags"], "bar.a")
+}
+
+func TestThinLtoBackendFlags(t *testing.T) {
+	t.Parallel()
+	toolchain := config.FindToolchain(android.Android, android.Arch{ArchType: android.Arm64})
+	testCases := []struct {
+		name    string
+		ldFlags string
+		want    string
+	}{
+		{
+			name: "default",
+			want: "-O2 -target aarch64-linux-android -ffunction-sections -fdata-sections",
+		},
+		{
+			name:    "lto-O0",
+			ldFlags: "-Wl,--gc-sections -flto=thin -Wl,--lto-O0 -Wl,-plugin-opt,-import-instr-limit=5",
+			want:    "-O0 -target aarch64-linux-android -ffunction-sections -fdata-sections -mllvm -import-instr-limit=5",
+		},
+		{
+			name:    "profile",
+			ldFlags: "-fprofile-sample-use=afdo.prof -Wl,-mllvm,-no-warn-sample-unused=true -Wl,-plugin-opt,O1",
+			want: "-O1 -target aarch64-linux-android -ffunction-sections -fdata-sections " +
+				"-fprofile-sample-use=afdo.prof -mllvm -no-warn-sample-unused=true",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			android.AssertStringEquals(t, "backend flags", tc.want, thinLTOBackendFlags(toolchain, tc.ldFlags))
+		})
+	}
+}
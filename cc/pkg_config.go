@@ -0,0 +1,165 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"android/soong/android"
+)
+
+// This file generates pkg-config files for the host variants of cc libraries that set
+// pkg_config.enabled, for build systems outside of the tree that use the host libraries built by
+// Soong. Each variant packages its library, the headers of its export_include_dirs and
+// export_system_include_dirs and a <name>.pc file into a zip laid out as an install tree:
+//
+//	include/<name>/...
+//	lib64/libfoo.so
+//	lib64/pkgconfig/<name>.pc
+//
+// The .pc files locate the tree with ${pcfiledir}, so it can be extracted anywhere. The zips of
+// all the libraries are merged into $OUT/soong/host_pkgconfig.zip, which is built by the
+// host_pkgconfig phony target and is in the "host_pkgconfig" dist group.
+
+type pkgConfigProperties struct {
+	// Whether to generate a pkg-config file for the host variants of the library.
+	Enabled *bool
+
+	// The Description of the pkg-config file. Defaults to the name of the module.
+	Description *string
+
+	// The Version of the pkg-config file. Defaults to "1.0".
+	Version *string
+
+	// The pkg-config packages that the users of the library also need, e.g. the packages of its
+	// exported shared libraries.
+	Requires []string
+}
+
+// pkgConfigFile returns the content of the .pc file of a library, which is installed to
+// <libDir>/pkgconfig of the tree, with its headers in includeDir of the tree.
+func pkgConfigFile(name string, props pkgConfigProperties, libDir, includeDir string, lib android.Path,
+	cflags []string) string {
+
+	var sb strings.Builder
+	fmt.Fprintln(&sb, "prefix=${pcfiledir}/../..")
+	fmt.Fprintf(&sb, "libdir=${prefix}/%s\n", libDir)
+	fmt.Fprintf(&sb, "includedir=${prefix}/%s\n", includeDir)
+	fmt.Fprintln(&sb)
+	fmt.Fprintf(&sb, "Name: %s\n", name)
+	fmt.Fprintf(&sb, "Description: %s\n", android.StringDefault(props.Description, name))
+	fmt.Fprintf(&sb, "Version: %s\n", android.StringDefault(props.Version, "1.0"))
+	if len(props.Requires) > 0 {
+		fmt.Fprintf(&sb, "Requires: %s\n", strings.Join(props.Requires, " "))
+	}
+	fmt.Fprintf(&sb, "Cflags: %s\n", strings.Join(append([]string{"-I${includedir}"}, cflags...), " "))
+
+	// -l only finds libraries called lib<name>.so or lib<name>.a.
+	stem := strings.TrimSuffix(lib.Base(), lib.Ext())
+	if strings.HasPrefix(stem, "lib") && (lib.Ext() == ".so" || lib.Ext() == ".a") {
+		fmt.Fprintf(&sb, "Libs: -L${libdir} -l%s", strings.TrimPrefix(stem, "lib"))
+	} else {
+		fmt.Fprintf(&sb, "Libs: ${libdir}/%s", lib.Base())
+	}
+	return sb.String()
+}
+
+// maybeGeneratePkgConfig packages the library, its exported headers and its pkg-config file if the
+// module is the host variant of a library that sets pkg_config.enabled.
+func (c *Module) maybeGeneratePkgConfig(ctx ModuleContext) {
+	library, ok := c.linker.(*libraryDecorator)
+	if !ok || !Bool(library.Properties.Pkg_config.Enabled) {
+		return
+	}
+	if !ctx.Host() || ctx.Windows() || library.header() || c.IsStubs() || !c.outputFile.Valid() {
+		return
+	}
+
+	name := ctx.baseModuleName()
+	lib := c.outputFile.Path()
+	libDir := ctx.Arch().ArchType.Multilib
+	if libDir == "lib32" {
+		libDir = "lib"
+	}
+	includeDir := filepath.Join("include", name)
+
+	pcFile := android.PathForModuleOut(ctx, "pkgconfig", name+".pc")
+	android.WriteFileRule(ctx, pcFile, pkgConfigFile(name, library.Properties.Pkg_config, libDir,
+		includeDir, lib, library.flagExporter.Properties.Export_cflags))
+
+	c.pkgConfigZip = android.PathForModuleOut(ctx, "pkgconfig", name+".zip")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	cmd := rule.Command().
+		BuiltTool("soong_zip").
+		FlagWithOutput("-o ", c.pkgConfigZip).
+		FlagWithArg("-P ", filepath.Join(libDir, "pkgconfig")).
+		FlagWithArg("-C ", filepath.Dir(pcFile.String())).
+		FlagWithInput("-f ", pcFile).
+		FlagWithArg("-P ", libDir).
+		FlagWithArg("-C ", filepath.Dir(lib.String())).
+		FlagWithInput("-f ", lib).
+		FlagWithArg("-P ", includeDir)
+	includeDirs := append(library.flagExporter.exportedIncludes(ctx),
+		android.PathsForModuleSrc(ctx, library.flagExporter.Properties.Export_system_include_dirs)...)
+	for _, dir := range android.FirstUniquePaths(includeDirs) {
+		headers := ctx.GlobFiles(headerGlobPattern(dir.String()), nil)
+		if len(headers) == 0 {
+			continue
+		}
+		cmd.FlagWithArg("-C ", dir.String()).FlagForEachInput("-f ", headers)
+	}
+	rule.Build("pkg_config", "pkg-config package "+name)
+}
+
+func hostPkgConfigSingletonFactory() android.Singleton {
+	return &hostPkgConfigSingleton{}
+}
+
+type hostPkgConfigSingleton struct {
+	zip android.WritablePath
+}
+
+func (s *hostPkgConfigSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	var zips android.Paths
+	ctx.VisitAllModules(func(module android.Module) {
+		if ccModule, ok := module.(*Module); ok && ccModule.Enabled() && ccModule.pkgConfigZip != nil {
+			zips = append(zips, ccModule.pkgConfigZip)
+		}
+	})
+	if len(zips) == 0 {
+		return
+	}
+
+	s.zip = android.PathForOutput(ctx, "host_pkgconfig.zip")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		BuiltTool("merge_zips").
+		// The shared and static variants of a library have the same headers and .pc file.
+		Flag("--ignore-duplicates").
+		Output(s.zip).
+		Inputs(android.SortedUniquePaths(zips))
+	rule.Build("host_pkgconfig", "host pkg-config zip")
+
+	ctx.Phony("host_pkgconfig", s.zip)
+}
+
+func (s *hostPkgConfigSingleton) MakeVars(ctx android.MakeVarsContext) {
+	if s.zip == nil {
+		return
+	}
+	ctx.DistForGroup("host_pkgconfig", s.zip)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestPkgConfig(t *testing.T) {
+	t.Parallel()
+	result := android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureAddFile("include/foo/foo.h", nil),
+	).RunTestWithBp(t, `
+		cc_library {
+			name: "libfoo",
+			srcs: ["foo.c"],
+			host_supported: true,
+			export_include_dirs: ["include"],
+			export_cflags: ["-DFOO"],
+			pkg_config: {
+				enabled: true,
+				version: "2.1",
+				requires: ["zlib"],
+			},
+		}
+		cc_library {
+			name: "libbar",
+			srcs: ["foo.c"],
+			host_supported: true,
+		}`)
+
+	shared := result.ModuleForTests("libfoo", "linux_glibc_x86_64_shared")
+	android.AssertStringEquals(t, "libfoo.pc", `prefix=${pcfiledir}/../..
+libdir=${prefix}/lib64
+includedir=${prefix}/include/libfoo
+
+Name: libfoo
+Description: libfoo
+Version: 2.1
+Requires: zlib
+Cflags: -I${includedir} -DFOO
+Libs: -L${libdir} -lfoo
+`, android.ContentFromFileRuleForTests(t, shared.Output("pkgconfig/libfoo.pc")))
+
+	zip := shared.Rule("pkg_config").RelativeToTop()
+	android.AssertStringDoesContain(t, "pkg-config zip command", zip.RuleParams.Command,
+		"-P lib64/pkgconfig -C out/soong/.intermediates/libfoo/linux_glibc_x86_64_shared/pkgconfig "+
+			"-f out/soong/.intermediates/libfoo/linux_glibc_x86_64_shared/pkgconfig/libfoo.pc")
+	android.AssertStringDoesContain(t, "pkg-config zip command", zip.RuleParams.Command,
+		"-P lib64 -C out/soong/.intermediates/libfoo/linux_glibc_x86_64_shared "+
+			"-f out/soong/.intermediates/libfoo/linux_glibc_x86_64_shared/libfoo.so")
+	android.AssertStringDoesContain(t, "pkg-config zip command", zip.RuleParams.Command,
+		"-P include/libfoo -C include -f include/foo/foo.h")
+
+	libDir := result.ModuleForTests("libfoo", "linux_glibc_x86_shared").Output("pkgconfig/libfoo.pc")
+	android.AssertStringDoesContain(t, "32-bit libfoo.pc",
+		android.ContentFromFileRuleForTests(t, libDir), "libdir=${prefix}/lib\n")
+
+	if m := result.ModuleForTests("libfoo", "android_arm64_armv8-a_shared").MaybeOutput("pkgconfig/libfoo.zip"); m.Rule != nil {
+		t.Errorf("expected no pkg-config zip for the device variants")
+	}
+	if m := result.ModuleForTests("libbar", "linux_glibc_x86_64_shared").MaybeOutput("pkgconfig/libbar.zip"); m.Rule != nil {
+		t.Errorf("expected no pkg-config zip for libbar without pkg_config")
+	}
+
+	merged := result.SingletonForTests("host_pkgconfig").Rule("host_pkgconfig")
+	android.AssertPathsRelativeToTopEquals(t, "host_pkgconfig.zip inputs", []string{
+		"out/soong/.intermediates/libfoo/linux_glibc_x86_64_shared/pkgconfig/libfoo.zip",
+		"out/soong/.intermediates/libfoo/linux_glibc_x86_64_static/pkgconfig/libfoo.zip",
+		"out/soong/.intermediates/libfoo/linux_glibc_x86_shared/pkgconfig/libfoo.zip",
+		"out/soong/.intermediates/libfoo/linux_glibc_x86_static/pkgconfig/libfoo.zip",
+	}, merged.Implicits)
+}
@@ -158,8 +158,7 @@ func (p *prebuiltLibraryLinker) link(ctx ModuleContext,
 			if p.stripper.NeedsStrip(ctx) {
 				stripFlags := flagsToStripFlags(flags)
 				stripped := android.PathForModuleOut(ctx, "stripped", libName)
-				p.stripper.StripExecutableOrSharedLib(ctx, in, stripped, stripFlags)
-				in = stripped
+				in = p.stripper.StripPrebuilt(ctx, in, stripped, stripFlags)
 			}
 
 			// Optimize out relinking against shared libraries whose interface hasn't changed by
@@ -717,8 +716,7 @@ func (p *prebuiltBinaryLinker) link(ctx ModuleContext,
 		} else {
 			if p.stripper.NeedsStrip(ctx) {
 				stripped := android.PathForModuleOut(ctx, "stripped", fileName)
-				p.stripper.StripExecutableOrSharedLib(ctx, in, stripped, flagsToStripFlags(flags))
-				in = stripped
+				in = p.stripper.StripPrebuilt(ctx, in, stripped, flagsToStripFlags(flags))
 			}
 
 			// Copy binaries to a name matching the final installed name
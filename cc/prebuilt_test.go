@@ -193,6 +193,30 @@ func TestPrebuiltLibraryShared(t *testing.T) {
 	assertString(t, shared.OutputFile().Path().Base(), "libtest.so")
 }
 
+func TestPrebuiltLibrarySharedContentKeyedStrip(t *testing.T) {
+	ctx := testPrebuilt(t, `
+	cc_prebuilt_library_shared {
+		name: "libtest",
+		srcs: ["libf.so"],
+		compile_multilib: "both",
+	}
+	`, map[string][]byte{
+		"libf.so": nil,
+	}, android.PrepareForTestWithContentKeyedActions,
+		android.FixtureMergeEnv(map[string]string{"SOONG_CONTENT_KEYED_INTERMEDIATES": "true"}))
+
+	// Both variants strip the same file the same way, so they share the stripped file.
+	arm64 := ctx.ModuleForTests("libtest", "android_arm64_armv8-a_shared").Output("libtest.so").Input
+	arm := ctx.ModuleForTests("libtest", "android_arm_armv7-a-neon_shared").Output("libtest.so").Input
+	android.AssertStringDoesContain(t, "stripped file", android.PathRelativeToTop(arm64),
+		"out/soong/.intermediates/.content/")
+	android.AssertPathRelativeToTopEquals(t, "stripped file", android.PathRelativeToTop(arm64), arm)
+
+	strip := ctx.SingletonForTests("content_keyed_actions").Output(android.PathRelativeToTop(arm64))
+	android.AssertStringEquals(t, "strip rule", "strip", strip.Rule.String())
+	android.AssertPathRelativeToTopEquals(t, "strip input", "libf.so", strip.Input)
+}
+
 func TestPrebuiltLibraryStatic(t *testing.T) {
 	ctx := testPrebuilt(t, `
 	cc_prebuilt_library_static {
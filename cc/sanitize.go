@@ -374,6 +374,9 @@ type SanitizeProperties struct {
 	InSanitizerDir    bool     `blueprint:"mutated"`
 	Sanitizers        []string `blueprint:"mutated"`
 	DiagSanitizers    []string `blueprint:"mutated"`
+
+	// The product hardening config that decided whether Memtag is enabled for this module, if any
+	MemtagHeapHardeningConfig string `blueprint:"mutated"`
 }
 
 type sanitize struct {
@@ -577,6 +580,18 @@ func (sanitize *sanitize) begin(ctx BaseModuleContext) {
 		}
 	}
 
+	// Enable or disable Memtag as configured by the product for the partition or directory of
+	// the module (for Aarch64 only)
+	if ctx.Arch().ArchType == android.Arm64 && ctx.toolchain().Bionic() && s.Memtag_heap == nil {
+		if mode, source, ok := hardeningSetting(ctx, memtagHeapSetting); ok && inList(mode, memtagHeapModes) {
+			s.Memtag_heap = proptools.BoolPtr(mode != "off")
+			if s.Diag.Memtag_heap == nil && mode != "off" {
+				s.Diag.Memtag_heap = proptools.BoolPtr(mode == "sync")
+			}
+			sanitize.Properties.MemtagHeapHardeningConfig = source
+		}
+	}
+
 	// Enable Memtag for all components in the include paths (for Aarch64 only)
 	if ctx.Arch().ArchType == android.Arm64 && ctx.toolchain().Bionic() {
 		if ctx.Config().MemtagHeapSyncEnabledForPath(ctx.ModuleDir()) {
@@ -1185,8 +1200,8 @@ func (s *sanitizerSplitMutator) Split(ctx android.BaseModuleContext) []string {
 	}
 
 	if _, ok := ctx.Module().(JniSanitizeable); ok {
-		// TODO: this should call into JniSanitizable.IsSanitizerEnabledForJni but
-		// that is short-circuited for now
+		// Modules with JNI libraries are not sanitized themselves, they only pick the
+		// variation of their JNI libraries in OutgoingTransition.
 		return []string{""}
 	}
 
@@ -1226,9 +1241,13 @@ func (s *sanitizerSplitMutator) OutgoingTransition(ctx android.OutgoingTransitio
 		}
 
 		return sourceVariation
-	} else if _, ok := ctx.Module().(JniSanitizeable); ok {
-		// TODO: this should call into JniSanitizable.IsSanitizerEnabledForJni but
-		// that is short-circuited for now
+	} else if j, ok := ctx.Module().(JniSanitizeable); ok {
+		// The JNI libraries of a module are built with the sanitizers that it enables
+		// for them, e.g. the fuzzer instrumentation for the JNI libraries of a java_fuzz
+		// module, without their owners enabling the sanitizers.
+		if j.IsSanitizerEnabledForJni(s.sanitizer.name()) {
+			return s.sanitizer.variationName()
+		}
 		return ""
 	} else {
 		// Otherwise, do not rock the boat.
@@ -1571,46 +1590,25 @@ func sanitizerRuntimeMutator(mctx android.BottomUpMutatorContext) {
 			mctx.AddFarVariationDependencies(variations, depTag, dep)
 		}
 
-		// Determine the runtime library required
-		runtimeSharedLibrary := ""
+		// Determine the runtime libraries required from sanitizerRuntimeMatrix.
 		toolchain := c.toolchain(mctx)
-		if Bool(sanProps.Address) {
-			if toolchain.Musl() || (c.staticBinary() && toolchain.Bionic()) {
-				// Use a static runtime for musl to match what clang does for glibc.
-				addStaticDeps(config.AddressSanitizerStaticRuntimeLibrary(toolchain), false)
-				addStaticDeps(config.AddressSanitizerCXXStaticRuntimeLibrary(toolchain), false)
-			} else {
-				runtimeSharedLibrary = config.AddressSanitizerRuntimeLibrary(toolchain)
-			}
-		} else if Bool(sanProps.Hwaddress) {
-			if c.staticBinary() {
-				addStaticDeps(config.HWAddressSanitizerStaticLibrary(toolchain), true)
-				addStaticDeps("libdl", false)
-			} else {
-				runtimeSharedLibrary = config.HWAddressSanitizerRuntimeLibrary(toolchain)
-			}
-		} else if Bool(sanProps.Thread) {
-			runtimeSharedLibrary = config.ThreadSanitizerRuntimeLibrary(toolchain)
-		} else if Bool(sanProps.Scudo) {
-			if len(diagSanitizers) == 0 && !c.sanitize.Properties.UbsanRuntimeDep {
-				runtimeSharedLibrary = config.ScudoMinimalRuntimeLibrary(toolchain)
-			} else {
-				runtimeSharedLibrary = config.ScudoRuntimeLibrary(toolchain)
+		var runtimeSharedLibraries []string
+		runtime := sanitizerRuntimeFor(c, diagSanitizers)
+		linkage := sanitizerRuntimeLinkageFor(c, toolchain)
+		if entry, ok := lookupSanitizerRuntime(runtime, linkage); ok {
+			if !entry.supportsArch(c.Arch().ArchType) {
+				mctx.ModuleErrorf("the %s sanitizer runtime is not available for %s", runtime, c.Arch().ArchType)
+				return
 			}
-		} else if len(diagSanitizers) > 0 || c.sanitize.Properties.UbsanRuntimeDep ||
-			Bool(sanProps.Fuzzer) ||
-			Bool(sanProps.Undefined) ||
-			Bool(sanProps.All_undefined) {
-			if toolchain.Musl() || (c.staticBinary() && toolchain.Bionic()) {
-				// Use a static runtime for static binaries.
-				// Also use a static runtime for musl to match
-				// what clang does for glibc.  Otherwise dlopening
-				// libraries that depend on libclang_rt.ubsan_standalone.so
-				// fails with:
-				// Error relocating ...: initial-exec TLS resolves to dynamic definition
-				addStaticDeps(config.UndefinedBehaviorSanitizerRuntimeLibrary(toolchain)+".static", true)
-			} else {
-				runtimeSharedLibrary = config.UndefinedBehaviorSanitizerRuntimeLibrary(toolchain)
+			for _, lib := range entry.libs {
+				if !checkSanitizerRuntimeLib(mctx, runtime, linkage, lib.name) {
+					continue
+				}
+				if lib.static {
+					addStaticDeps(lib.name, lib.hideSymbols)
+				} else {
+					runtimeSharedLibraries = append(runtimeSharedLibraries, lib.name)
+				}
 			}
 		}
 
@@ -1621,9 +1619,10 @@ func sanitizerRuntimeMutator(mctx android.BottomUpMutatorContext) {
 			addStaticDeps(config.BuiltinsRuntimeLibrary(toolchain), true)
 		}
 
-		if runtimeSharedLibrary != "" && (toolchain.Bionic() || toolchain.Musl() || c.sanitize.Properties.UbsanRuntimeDep) {
-			// UBSan is supported on non-bionic linux host builds as well
-
+		// static lib does not have dependency to the runtime library. The
+		// dependency will be added to the executables or shared libs using
+		// the static lib.
+		if len(runtimeSharedLibraries) > 0 && !c.static() && !c.Header() {
 			// Adding dependency to the runtime library. We are using *FarVariation*
 			// because the runtime libraries themselves are not mutated by sanitizer
 			// mutators and thus don't have sanitizer variants whereas this module
@@ -1631,13 +1630,7 @@ func sanitizerRuntimeMutator(mctx android.BottomUpMutatorContext) {
 			//
 			// Note that by adding dependency with {static|shared}DepTag, the lib is
 			// added to libFlags and LOCAL_SHARED_LIBRARIES by cc.Module
-			if c.staticBinary() {
-				// Most sanitizers are either disabled for static binaries or have already
-				// handled the static binary case above through a direct call to addStaticDeps.
-				// If not, treat the runtime shared library as a static library and hope for
-				// the best.
-				addStaticDeps(runtimeSharedLibrary, true)
-			} else if !c.static() && !c.Header() {
+			for _, runtimeSharedLibrary := range runtimeSharedLibraries {
 				// If we're using snapshots, redirect to snapshot whenever possible
 				snapshot := mctx.Provider(SnapshotInfoProvider).(SnapshotInfo)
 				if lib, ok := snapshot.SharedLibs[runtimeSharedLibrary]; ok {
@@ -1667,9 +1660,6 @@ func sanitizerRuntimeMutator(mctx android.BottomUpMutatorContext) {
 				}
 				AddSharedLibDependenciesWithVersions(mctx, c, variations, depTag, runtimeSharedLibrary, "", true)
 			}
-			// static lib does not have dependency to the runtime library. The
-			// dependency will be added to the executables or shared libs using
-			// the static lib.
 		}
 	}
 }
@@ -1683,7 +1673,7 @@ type Sanitizeable interface {
 
 type JniSanitizeable interface {
 	android.Module
-	IsSanitizerEnabledForJni(ctx android.BaseModuleContext, sanitizerName string) bool
+	IsSanitizerEnabledForJni(sanitizerName string) bool
 }
 
 func (c *Module) MinimalRuntimeDep() bool {
@@ -1706,6 +1696,31 @@ func (c *Module) StaticallyLinked() bool {
 	return c.static()
 }
 
+// linkUnitSanitizers are the sanitizers whose runtime is linked into the module that links the
+// instrumented code, so a static library built with one of them can only be linked into a module
+// that is built with it too.
+var linkUnitSanitizers = []SanitizerType{Asan, Hwasan, Fuzzer}
+
+// CheckLinkUnitSanitizers reports an error if a static library dependency that is a rust module
+// linked into a cc module, or a cc module linked into a rust module, is built with a sanitizer
+// that the module linking it is not. The sanitizer mutators keep the sanitizers of cc modules
+// consistent with each other, but rust and cc modules decide their sanitizers separately, which
+// would otherwise show up as undefined runtime symbols at link time.
+func CheckLinkUnitSanitizers(ctx android.ModuleContext, module PlatformSanitizeable, dep PlatformSanitizeable, depName string) {
+	_, moduleIsCc := module.(*Module)
+	_, depIsCc := dep.(*Module)
+	if moduleIsCc == depIsCc || !module.SanitizePropDefined() || !dep.SanitizePropDefined() {
+		return
+	}
+	for _, t := range linkUnitSanitizers {
+		if dep.IsSanitizerEnabled(t) && !module.IsSanitizerEnabled(t) {
+			ctx.ModuleErrorf("static library %q is built with the %s sanitizer but %q is not, "+
+				"set sanitize.%s to the same value in both modules", depName, t.name(), ctx.ModuleName(),
+				t.name())
+		}
+	}
+}
+
 func (c *Module) SetInSanitizerDir() {
 	if c.sanitize != nil {
 		c.sanitize.Properties.InSanitizerDir = true
@@ -0,0 +1,245 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"strings"
+
+	"android/soong/android"
+	"android/soong/cc/config"
+)
+
+// This file contains the matrix of the runtime libraries that modules depend on for their
+// sanitizers. sanitizerRuntimeMutator looks up the runtime of a module's sanitizers and the way the
+// module links it in sanitizerRuntimeMatrix, and checks that the clang prebuilts provide each
+// runtime library before adding the dependencies. That way a clang update that drops or renames a
+// runtime library fails analysis with an error that names the missing library, instead of
+// failing to link, or linking without the runtime, for some sanitizer and architecture.
+
+// sanitizerRuntime is the runtime that a module needs for its sanitizers.
+type sanitizerRuntime string
+
+const (
+	asanRuntime         sanitizerRuntime = "asan"
+	hwasanRuntime       sanitizerRuntime = "hwasan"
+	tsanRuntime         sanitizerRuntime = "tsan"
+	scudoRuntime        sanitizerRuntime = "scudo"
+	scudoMinimalRuntime sanitizerRuntime = "scudo_minimal"
+	ubsanRuntime        sanitizerRuntime = "ubsan"
+)
+
+// sanitizerRuntimeLinkage is how a module links the runtime of its sanitizers.
+type sanitizerRuntimeLinkage string
+
+const (
+	// Dynamic executables and shared libraries on bionic or glibc link the shared runtime.
+	sharedRuntimeLinkage sanitizerRuntimeLinkage = "shared"
+	// Static executables link the static runtime.
+	staticBinaryRuntimeLinkage sanitizerRuntimeLinkage = "static_binary"
+	// Dynamic executables and shared libraries on musl, which link the static runtime for some
+	// sanitizers to match what clang does for glibc.
+	muslRuntimeLinkage sanitizerRuntimeLinkage = "musl"
+)
+
+// sanitizerRuntimeLib is a library that a module depends on for the runtime of its sanitizers.
+type sanitizerRuntimeLib struct {
+	// The name of the library.
+	name string
+
+	// Whether the library is linked statically.
+	static bool
+
+	// Whether the symbols of a statically linked library are hidden from the module's exports.
+	hideSymbols bool
+}
+
+func sharedRuntimeLib(name string) sanitizerRuntimeLib {
+	return sanitizerRuntimeLib{name: name}
+}
+
+func staticRuntimeLib(name string, hideSymbols bool) sanitizerRuntimeLib {
+	return sanitizerRuntimeLib{name: name, static: true, hideSymbols: hideSymbols}
+}
+
+// sanitizerRuntimeMatrixEntry lists the runtime libraries for a runtime and linkage.
+type sanitizerRuntimeMatrixEntry struct {
+	runtime sanitizerRuntime
+	linkage sanitizerRuntimeLinkage
+
+	// The architectures that the runtime is available for, or nil if it is available for all
+	// of them.
+	arches []android.ArchType
+
+	libs []sanitizerRuntimeLib
+}
+
+func libclangRt(library string) string {
+	return config.LibclangRuntimeLibrary(nil, library)
+}
+
+// sanitizerRuntimeMatrix is the runtime libraries of each runtime and linkage. Runtimes or
+// linkages that are missing from the matrix don't need a runtime library.
+var sanitizerRuntimeMatrix = []sanitizerRuntimeMatrixEntry{
+	{asanRuntime, sharedRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		sharedRuntimeLib(libclangRt("asan")),
+	}},
+	{asanRuntime, staticBinaryRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		staticRuntimeLib(libclangRt("asan.static"), false),
+		staticRuntimeLib(libclangRt("asan_cxx.static"), false),
+	}},
+	{asanRuntime, muslRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		staticRuntimeLib(libclangRt("asan.static"), false),
+		staticRuntimeLib(libclangRt("asan_cxx.static"), false),
+	}},
+
+	// HWASan requires the top-byte-ignore feature of AArch64.
+	{hwasanRuntime, sharedRuntimeLinkage, []android.ArchType{android.Arm64}, []sanitizerRuntimeLib{
+		sharedRuntimeLib(libclangRt("hwasan")),
+	}},
+	{hwasanRuntime, staticBinaryRuntimeLinkage, []android.ArchType{android.Arm64}, []sanitizerRuntimeLib{
+		staticRuntimeLib(libclangRt("hwasan_static"), true),
+		staticRuntimeLib("libdl", false),
+	}},
+
+	{tsanRuntime, sharedRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		sharedRuntimeLib(libclangRt("tsan")),
+	}},
+	{tsanRuntime, staticBinaryRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		staticRuntimeLib(libclangRt("tsan"), true),
+	}},
+	{tsanRuntime, muslRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		sharedRuntimeLib(libclangRt("tsan")),
+	}},
+
+	{scudoRuntime, sharedRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		sharedRuntimeLib(libclangRt("scudo")),
+	}},
+	{scudoRuntime, staticBinaryRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		staticRuntimeLib(libclangRt("scudo"), true),
+	}},
+	{scudoRuntime, muslRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		sharedRuntimeLib(libclangRt("scudo")),
+	}},
+
+	{scudoMinimalRuntime, sharedRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		sharedRuntimeLib(libclangRt("scudo_minimal")),
+	}},
+	{scudoMinimalRuntime, staticBinaryRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		staticRuntimeLib(libclangRt("scudo_minimal"), true),
+	}},
+	{scudoMinimalRuntime, muslRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		sharedRuntimeLib(libclangRt("scudo_minimal")),
+	}},
+
+	// Static binaries and musl use the static UBSan runtime. Dlopening libraries that depend on
+	// libclang_rt.ubsan_standalone.so from musl binaries fails with:
+	// Error relocating ...: initial-exec TLS resolves to dynamic definition
+	{ubsanRuntime, sharedRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		sharedRuntimeLib(libclangRt("ubsan_standalone")),
+	}},
+	{ubsanRuntime, staticBinaryRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		staticRuntimeLib(libclangRt("ubsan_standalone.static"), true),
+	}},
+	{ubsanRuntime, muslRuntimeLinkage, nil, []sanitizerRuntimeLib{
+		staticRuntimeLib(libclangRt("ubsan_standalone.static"), true),
+	}},
+}
+
+// lookupSanitizerRuntime returns the entry of sanitizerRuntimeMatrix for the runtime and linkage,
+// if there is one.
+func lookupSanitizerRuntime(runtime sanitizerRuntime, linkage sanitizerRuntimeLinkage) (sanitizerRuntimeMatrixEntry, bool) {
+	for _, entry := range sanitizerRuntimeMatrix {
+		if entry.runtime == runtime && entry.linkage == linkage {
+			return entry, true
+		}
+	}
+	return sanitizerRuntimeMatrixEntry{}, false
+}
+
+// supportsArch returns true if the runtime is available for the architecture.
+func (e sanitizerRuntimeMatrixEntry) supportsArch(arch android.ArchType) bool {
+	if e.arches == nil {
+		return true
+	}
+	for _, a := range e.arches {
+		if a == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizerRuntimeFor returns the runtime that the module needs for its sanitizers, or "" if it
+// doesn't need one.
+func sanitizerRuntimeFor(c *Module, diagSanitizers []string) sanitizerRuntime {
+	sanProps := &c.sanitize.Properties.SanitizeMutated
+	switch {
+	case Bool(sanProps.Address):
+		return asanRuntime
+	case Bool(sanProps.Hwaddress):
+		return hwasanRuntime
+	case Bool(sanProps.Thread):
+		return tsanRuntime
+	case Bool(sanProps.Scudo):
+		if len(diagSanitizers) == 0 && !c.sanitize.Properties.UbsanRuntimeDep {
+			return scudoMinimalRuntime
+		}
+		return scudoRuntime
+	case len(diagSanitizers) > 0 || c.sanitize.Properties.UbsanRuntimeDep ||
+		Bool(sanProps.Fuzzer) ||
+		Bool(sanProps.Undefined) ||
+		Bool(sanProps.All_undefined):
+		return ubsanRuntime
+	}
+	return ""
+}
+
+// sanitizerRuntimeLinkageFor returns how the module links the runtime of its sanitizers, or "" if
+// the runtime is linked by the compiler driver.
+func sanitizerRuntimeLinkageFor(c *Module, toolchain config.Toolchain) sanitizerRuntimeLinkage {
+	switch {
+	case c.staticBinary() && (toolchain.Bionic() || toolchain.Musl()):
+		return staticBinaryRuntimeLinkage
+	case toolchain.Musl():
+		return muslRuntimeLinkage
+	case toolchain.Bionic():
+		return sharedRuntimeLinkage
+	case c.sanitize.Properties.UbsanRuntimeDep:
+		// UBSan is supported on non-bionic linux host builds as well.
+		if c.staticBinary() {
+			return staticBinaryRuntimeLinkage
+		}
+		return sharedRuntimeLinkage
+	}
+	return ""
+}
+
+// checkSanitizerRuntimeLib reports an error and returns false if the clang prebuilts don't
+// provide a runtime library.
+func checkSanitizerRuntimeLib(mctx android.BottomUpMutatorContext, runtime sanitizerRuntime,
+	linkage sanitizerRuntimeLinkage, lib string) bool {
+
+	if mctx.OtherModuleExists(lib) || !strings.HasPrefix(lib, libclangRt("")) {
+		return true
+	}
+	if mctx.Config().AllowMissingDependencies() {
+		// Let the dependency be reported as missing when the module is built.
+		return true
+	}
+	mctx.ModuleErrorf("the %s sanitizer runtime for %s linkage on %s requires %q, which is not "+
+		"provided by the clang prebuilts (%s), update sanitizerRuntimeMatrix for the new clang",
+		runtime, linkage, mctx.Arch().ArchType, lib, config.ClangDefaultVersion)
+	return false
+}
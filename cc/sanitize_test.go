@@ -1246,3 +1246,50 @@ func TestCfi(t *testing.T) {
 		t.Errorf("non-CFI variant of baz not expected to contain CFI flags ")
 	}
 }
+
+func TestSanitizerRuntimeMatrix(t *testing.T) {
+	t.Parallel()
+	for _, runtime := range []sanitizerRuntime{asanRuntime, hwasanRuntime, tsanRuntime, scudoRuntime, scudoMinimalRuntime, ubsanRuntime} {
+		for _, linkage := range []sanitizerRuntimeLinkage{sharedRuntimeLinkage, staticBinaryRuntimeLinkage} {
+			entry, ok := lookupSanitizerRuntime(runtime, linkage)
+			if !ok {
+				t.Errorf("missing %s runtime for %s linkage", runtime, linkage)
+				continue
+			}
+			if len(entry.libs) == 0 {
+				t.Errorf("%s runtime for %s linkage has no libraries", runtime, linkage)
+			}
+			for _, lib := range entry.libs {
+				if linkage == staticBinaryRuntimeLinkage && !lib.static {
+					t.Errorf("%s runtime for static binaries links %q dynamically", runtime, lib.name)
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range sanitizerRuntimeMatrix {
+		key := string(entry.runtime) + " " + string(entry.linkage)
+		if seen[key] {
+			t.Errorf("duplicate entry for %s runtime and %s linkage", entry.runtime, entry.linkage)
+		}
+		seen[key] = true
+	}
+}
+
+func TestSanitizerRuntimeMissingFromClangPrebuilts(t *testing.T) {
+	t.Parallel()
+	bp := `
+	cc_binary {
+		name: "bin_with_tsan",
+		sanitize: {
+			thread: true,
+		},
+	}
+	`
+
+	prepareForCcTest.
+		ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+			`the tsan sanitizer runtime for shared linkage on arm64 requires "libclang_rt.tsan", which is not provided by the clang prebuilts`)).
+		RunTestWithBp(t, bp)
+}
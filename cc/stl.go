@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"android/soong/android"
+	"android/soong/cc/config"
 )
 
 func getNdkStlFamily(m LinkableInterface) string {
@@ -93,6 +94,24 @@ func (stl *stl) begin(ctx BaseModuleContext) {
 				ctx.ModuleErrorf("stl: %q is not a supported STL with sdk_version set", s)
 				return ""
 			}
+		} else if ctx.Os() == android.Wasi {
+			switch s {
+			case "libc++", "libc++_static", "":
+				// The wasi toolchain uses the libc++ from the wasi-sdk sysroot.
+				return ""
+			default:
+				ctx.ModuleErrorf("stl: %q is not a supported STL for wasi", s)
+				return ""
+			}
+		} else if config.CrossSysrootOs(ctx.Os()) {
+			switch s {
+			case "libc++", "libc++_static", "":
+				// Modules built against a cc_cross_sysroot use the C++ library of the sysroot.
+				return ""
+			default:
+				ctx.ModuleErrorf("stl: %q is not a supported STL for %s", s, ctx.Os())
+				return ""
+			}
 		} else if ctx.Windows() {
 			switch s {
 			case "libc++", "libc++_static", "":
@@ -239,7 +258,7 @@ func (stl *stl) flags(ctx ModuleContext, flags Flags) Flags {
 		}
 	case "":
 		// None or error.
-		if !ctx.toolchain().Bionic() {
+		if !ctx.toolchain().Bionic() && ctx.Os() != android.Wasi && !config.CrossSysrootOs(ctx.Os()) {
 			flags.Local.CppFlags = append(flags.Local.CppFlags, "-nostdinc++")
 			flags.extraLibFlags = append(flags.extraLibFlags, "-nostdlib++")
 		}
@@ -61,9 +61,10 @@ func (stripper *Stripper) NeedsStrip(actx android.ModuleContext) bool {
 
 // Keep this consistent with //build/bazel/rules/stripped_shared_library.bzl.
 func (stripper *Stripper) strip(actx android.ModuleContext, in android.Path, out android.ModuleOutPath,
-	flags StripFlags, isStaticLib bool) {
+	flags StripFlags, isStaticLib bool, contentKeyed bool) android.Path {
 	if actx.Darwin() {
 		transformDarwinStrip(actx, in, out)
+		return out
 	} else {
 		if Bool(stripper.StripProperties.Strip.Keep_symbols) {
 			flags.StripKeepSymbols = true
@@ -77,7 +78,7 @@ func (stripper *Stripper) strip(actx android.ModuleContext, in android.Path, out
 		if actx.Config().Debuggable() && !flags.StripKeepMiniDebugInfo && !isStaticLib {
 			flags.StripAddGnuDebuglink = true
 		}
-		transformStrip(actx, in, out, flags)
+		return transformStrip(actx, in, out, flags, contentKeyed)
 	}
 }
 
@@ -86,7 +87,16 @@ func (stripper *Stripper) strip(actx android.ModuleContext, in android.Path, out
 // flagsToStripFlags may be used to generate the flags argument.
 func (stripper *Stripper) StripExecutableOrSharedLib(actx android.ModuleContext, in android.Path,
 	out android.ModuleOutPath, flags StripFlags) {
-	stripper.strip(actx, in, out, flags, false)
+	stripper.strip(actx, in, out, flags, false, false)
+}
+
+// StripPrebuilt strips a prebuilt binary or shared library like StripExecutableOrSharedLib, and
+// returns the path of the stripped file. The same prebuilt stripped by different variants,
+// products or branches is the same action, so the stripped file is placed at a content keyed
+// path with SOONG_CONTENT_KEYED_INTERMEDIATES=true, see android.BuildContentKeyed.
+func (stripper *Stripper) StripPrebuilt(actx android.ModuleContext, in android.Path,
+	out android.ModuleOutPath, flags StripFlags) android.Path {
+	return stripper.strip(actx, in, out, flags, false, true)
 }
 
 // StripStaticLib strips a static library from its debug symbols and other
@@ -94,5 +104,5 @@ func (stripper *Stripper) StripExecutableOrSharedLib(actx android.ModuleContext,
 // generate the flags argument.
 func (stripper *Stripper) StripStaticLib(actx android.ModuleContext, in android.Path, out android.ModuleOutPath,
 	flags StripFlags) {
-	stripper.strip(actx, in, out, flags, true)
+	stripper.strip(actx, in, out, flags, true, false)
 }
@@ -0,0 +1,77 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"android/soong/android"
+)
+
+// This file collects the debug info that is split out of device binaries, see
+// binaryDecorator.splitDebugInfo, for crash servers. $OUT/soong/symbol_upload_manifest.txt has a
+// "<build id> <unstripped binary>" line for each binary, and $OUT/soong/debug_info.zip has the
+// .build-id/xx/yyyy.debug files of all of them. Both are built by the symbol_upload_manifest
+// phony target and dist'ed with droidcore.
+
+func symbolUploadManifestSingletonFactory() android.Singleton {
+	return &symbolUploadManifestSingleton{}
+}
+
+type symbolUploadManifestSingleton struct {
+	manifest     android.WritablePath
+	debugInfoZip android.WritablePath
+}
+
+func (s *symbolUploadManifestSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	var buildIdFiles, debugInfoZips android.Paths
+	ctx.VisitAllModules(func(module android.Module) {
+		ccModule, ok := module.(*Module)
+		if !ok || !ccModule.Enabled() || ccModule.IsSkipInstall() {
+			return
+		}
+		if binary, ok := ccModule.linker.(*binaryDecorator); ok && binary.buildIdFile != nil {
+			buildIdFiles = append(buildIdFiles, binary.buildIdFile)
+			debugInfoZips = append(debugInfoZips, binary.debugInfoZip)
+		}
+	})
+	if len(buildIdFiles) == 0 {
+		return
+	}
+
+	s.manifest = android.PathForOutput(ctx, "symbol_upload_manifest.txt")
+	s.debugInfoZip = android.PathForOutput(ctx, "debug_info.zip")
+
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		Text("xargs cat <").
+		FlagWithRspFileInputList("", android.PathForOutput(ctx, "symbol_upload_manifest.rsp"), buildIdFiles).
+		Text("| sort >").
+		Output(s.manifest)
+	rule.Command().
+		BuiltTool("merge_zips").
+		// Variants of a binary that link to identical files have the same build id.
+		Flag("--ignore-duplicates").
+		Output(s.debugInfoZip).
+		Inputs(android.SortedUniquePaths(debugInfoZips))
+	rule.Build("symbol_upload_manifest", "symbol upload manifest")
+
+	ctx.Phony("symbol_upload_manifest", s.manifest, s.debugInfoZip)
+}
+
+func (s *symbolUploadManifestSingleton) MakeVars(ctx android.MakeVarsContext) {
+	if s.manifest == nil {
+		return
+	}
+	ctx.DistForGoal("droidcore", s.manifest, s.debugInfoZip)
+}
@@ -319,6 +319,10 @@ func (test *testDecorator) installerProps() []interface{} {
 	return []interface{}{&test.InstallerProperties}
 }
 
+func (test *testDecorator) testSuites() []string {
+	return test.InstallerProperties.Test_suites
+}
+
 func NewTestInstaller() *baseInstaller {
 	return NewBaseInstaller("nativetest", "nativetest64", InstallInData)
 }
@@ -583,6 +587,10 @@ func (benchmark *benchmarkDecorator) benchmarkBinary() bool {
 	return true
 }
 
+func (benchmark *benchmarkDecorator) testSuites() []string {
+	return benchmark.Properties.Test_suites
+}
+
 func (benchmark *benchmarkDecorator) linkerProps() []interface{} {
 	props := benchmark.binaryDecorator.linkerProps()
 	props = append(props, &benchmark.Properties)
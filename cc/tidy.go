@@ -174,6 +174,9 @@ func (tidy *tidyFeature) flags(ctx ModuleContext, flags Flags) Flags {
 
 	flags.TidyFlags = append(flags.TidyFlags, tidyChecks)
 
+	// The findings in the baseline of the directory don't fail the build.
+	flags.TidyBaseline = android.ExistentPathForSource(ctx, ctx.ModuleDir(), tidyBaselineFile)
+
 	// Embedding -warnings-as-errors in tidy_flags is error-prone.
 	// It should be replaced with the tidy_checks_as_errors list.
 	for i, s := range flags.TidyFlags {
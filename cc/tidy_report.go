@@ -0,0 +1,69 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"android/soong/android"
+)
+
+// This file collects the clang-tidy findings of all the source files that clang-tidy checks into
+// $OUT/soong/tidy_report.tsv, which has a <path>\t<line>\t<check>\t<message>\t<new|baselined> line
+// for each finding. It is built by the tidy_report phony target, and dist'ed with it.
+//
+// The findings of a directory that are in its tidy-baseline.txt file, a
+// <path>\t<check>\t<message> line for each finding, don't fail the build. The lines of the
+// findings of the report are turned into lines of a baseline by dropping their line and status
+// columns.
+
+// tidyBaselineFile is the name of the baseline of the clang-tidy findings of a directory.
+const tidyBaselineFile = "tidy-baseline.txt"
+
+func tidyReportSingletonFactory() android.Singleton {
+	return &tidyReportSingleton{}
+}
+
+type tidyReportSingleton struct {
+	report android.WritablePath
+}
+
+func (s *tidyReportSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	var findings android.Paths
+	ctx.VisitAllModules(func(module android.Module) {
+		if ccModule, ok := module.(*Module); ok && ccModule.Enabled() {
+			findings = append(findings, ccModule.tidyFindings...)
+		}
+	})
+	if len(findings) == 0 {
+		return
+	}
+
+	s.report = android.PathForOutput(ctx, "tidy_report.tsv")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		Text("xargs cat <").
+		FlagWithRspFileInputList("", android.PathForOutput(ctx, "tidy_report.rsp"), android.SortedUniquePaths(findings)).
+		Text("| sort -u >").
+		Output(s.report)
+	rule.Build("tidy_report", "tidy report")
+
+	ctx.Phony("tidy_report", s.report)
+}
+
+func (s *tidyReportSingleton) MakeVars(ctx android.MakeVarsContext) {
+	if s.report == nil {
+		return
+	}
+	ctx.DistForGoal("tidy_report", s.report)
+}
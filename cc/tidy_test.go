@@ -244,3 +244,38 @@ func TestWithTidy(t *testing.T) {
 		})
 	}
 }
+
+func TestTidyBaselineCacheAndReport(t *testing.T) {
+	bp := `
+		cc_library_shared {
+			name: "libfoo",
+			srcs: ["foo.c"],
+		}`
+	result := android.GroupFixturePreparers(
+		prepareForCcTest,
+		android.FixtureMergeEnv(map[string]string{"TIDY_CACHE_DIR": "/tmp/tidy_cache"}),
+		android.FixtureAddFile("tidy-baseline.txt", nil),
+	).RunTestWithBp(t, bp)
+
+	tidy := result.ModuleForTests("libfoo", "android_arm64_armv8-a_shared").Rule("clangTidy")
+	android.AssertStringEquals(t, "tidyWrapperFlags", "--cache_dir /tmp/tidy_cache --baseline tidy-baseline.txt",
+		tidy.Args["tidyWrapperFlags"])
+	android.AssertStringListContains(t, "baseline is an input", tidy.Implicits.Strings(), "tidy-baseline.txt")
+	findings := "out/soong/.intermediates/libfoo/android_arm64_armv8-a_shared/obj/foo.tidy.findings"
+	android.AssertPathRelativeToTopEquals(t, "findings", findings, tidy.ImplicitOutput)
+
+	report := result.SingletonForTests("tidy_report").Rule("tidy_report")
+	android.AssertStringListContains(t, "tidy report inputs", android.PathsRelativeToTop(report.Inputs), findings)
+	android.AssertPathRelativeToTopEquals(t, "tidy report", "out/soong/tidy_report.tsv", report.Output)
+}
+
+func TestTidyWithoutBaselineOrCache(t *testing.T) {
+	bp := `
+		cc_library_shared {
+			name: "libfoo",
+			srcs: ["foo.c"],
+		}`
+	ctx := testCc(t, bp)
+	tidy := ctx.ModuleForTests("libfoo", "android_arm64_armv8-a_shared").Rule("clangTidy")
+	android.AssertStringEquals(t, "tidyWrapperFlags", "", tidy.Args["tidyWrapperFlags"])
+}
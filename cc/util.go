@@ -63,12 +63,16 @@ func flagsToBuilderFlags(in Flags) builderFlags {
 		tidyFlags:     strings.Join(in.TidyFlags, " "),
 		sAbiFlags:     strings.Join(in.SAbiFlags, " "),
 		toolchain:     in.Toolchain,
+		clangBin:      in.ClangBin,
+		tidyBaseline:  in.TidyBaseline,
 		gcovCoverage:  in.GcovCoverage,
 		tidy:          in.Tidy,
 		needTidyFiles: in.NeedTidyFiles,
 		sAbiDump:      in.SAbiDump,
 		emitXrefs:     in.EmitXrefs,
 
+		distributedThinLTO: in.DistributedThinLTO,
+
 		systemIncludeFlags: strings.Join(in.SystemIncludeFlags, " "),
 
 		assemblerWithCpp: in.AssemblerWithCpp,
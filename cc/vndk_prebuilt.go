@@ -148,8 +148,7 @@ func (p *vndkPrebuiltLibraryDecorator) link(ctx ModuleContext,
 		if p.stripper.NeedsStrip(ctx) {
 			stripFlags := flagsToStripFlags(flags)
 			stripped := android.PathForModuleOut(ctx, "stripped", libName)
-			p.stripper.StripExecutableOrSharedLib(ctx, in, stripped, stripFlags)
-			in = stripped
+			in = p.stripper.StripPrebuilt(ctx, in, stripped, stripFlags)
 		}
 
 		// Optimize out relinking against shared libraries whose interface hasn't changed by
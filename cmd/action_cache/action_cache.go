@@ -0,0 +1,400 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// action_cache runs the command of a build action, or restores its outputs from a local cache of
+// the results of earlier runs of the same action.
+//
+// The results are cached in --cache_dir by the digest of the command line, of the paths and
+// contents of the files listed in the --inputs response files and of the paths of the --output
+// files. The paths in the output directory, --out_dir, are replaced with a placeholder in the
+// digest and in the cached --depfile, so the same action of a build in another output directory
+// or checkout uses the same cached result. The contents of the --normalized_input files, e.g. sbox
+// manifests, have their paths in the output directory replaced as well.
+//
+// Only the runs that succeed and write all their outputs are cached. An action whose inputs can't
+// be read, e.g. because one of them is a directory, always runs.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"android/soong/response"
+)
+
+// cacheVersion is part of the cache keys, to invalidate the cached results when their format or
+// the way they are computed changes.
+const cacheVersion = "1"
+
+// outDirPlaceholder replaces the output directory in the cache keys and the cached depfiles.
+const outDirPlaceholder = "__ACTION_CACHE_OUT_DIR__"
+
+// The names of the files of a cache entry, besides the outputs, which are named by their index.
+const (
+	consoleFile = "console"
+	depFileName = "depfile"
+)
+
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+// options are the arguments of action_cache.
+type options struct {
+	cacheDir         string
+	outDir           string
+	inputs           []string
+	normalizedInputs []string
+	outputs          []string
+	depFile          string
+	command          []string
+}
+
+func isPathByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '.' || c == '/'
+}
+
+// normalize replaces the output directory at the start of the paths in s with the placeholder.
+func normalize(s, outDir string) string {
+	if outDir == "" {
+		return s
+	}
+	prefix := strings.TrimSuffix(outDir, "/") + "/"
+	var b strings.Builder
+	for {
+		i := strings.Index(s, prefix)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		if i == 0 || !isPathByte(s[i-1]) {
+			b.WriteString(s[:i])
+			b.WriteString(outDirPlaceholder + "/")
+		} else {
+			// The output directory is part of a longer path, e.g. "layout/" for "out".
+			b.WriteString(s[:i+len(prefix)])
+		}
+		s = s[i+len(prefix):]
+	}
+}
+
+// denormalize replaces the placeholder in s with the output directory.
+func denormalize(s, outDir string) string {
+	return strings.ReplaceAll(s, outDirPlaceholder+"/", strings.TrimSuffix(outDir, "/")+"/")
+}
+
+// readInputs returns the files listed in the response files.
+func readInputs(rspFiles []string) ([]string, error) {
+	var inputs []string
+	for _, rspFile := range rspFiles {
+		f, err := os.Open(rspFile)
+		if err != nil {
+			return nil, err
+		}
+		files, err := response.ReadRspFile(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", rspFile, err)
+		}
+		inputs = append(inputs, files...)
+	}
+	return inputs, nil
+}
+
+func fileDigest(file string, normalizeOutDir string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	if normalizeOutDir != "" {
+		data = []byte(normalize(string(data), normalizeOutDir))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func cacheKey(opts options) (string, error) {
+	inputs, err := readInputs(opts.inputs)
+	if err != nil {
+		return "", err
+	}
+	normalized := make(map[string]bool)
+	for _, input := range opts.normalizedInputs {
+		normalized[input] = true
+		inputs = append(inputs, input)
+	}
+
+	// The inputs are hashed in the order of their normalized paths, which is the same in every
+	// output directory.
+	type input struct{ path, key string }
+	var sorted []input
+	seen := make(map[string]bool)
+	for _, path := range inputs {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		sorted = append(sorted, input{path, normalize(path, opts.outDir)})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].key < sorted[j].key })
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00", cacheVersion)
+	for _, arg := range opts.command {
+		fmt.Fprintf(h, "%s\x00", normalize(arg, opts.outDir))
+	}
+	fmt.Fprintf(h, "inputs\x00")
+	for _, in := range sorted {
+		normalizeOutDir := ""
+		if normalized[in.path] {
+			normalizeOutDir = opts.outDir
+		}
+		digest, err := fileDigest(in.path, normalizeOutDir)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00", in.key, digest)
+	}
+	fmt.Fprintf(h, "outputs\x00")
+	for _, output := range opts.outputs {
+		fmt.Fprintf(h, "%s\x00", normalize(output, opts.outDir))
+	}
+	fmt.Fprintf(h, "depfile\x00%s\x00", normalize(opts.depFile, opts.outDir))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func cachePath(cacheDir, key string) string {
+	return filepath.Join(cacheDir, key[:2], key)
+}
+
+// writeFileIfChanged writes data to file with the given mode, unless file already has the same
+// contents, so that restat rules see an unchanged output.
+func writeFileIfChanged(file string, data []byte, mode os.FileMode) error {
+	if existing, err := os.ReadFile(file); err == nil && bytes.Equal(existing, data) {
+		return os.Chmod(file, mode)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file))
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// restore writes the outputs of the cached result in entryDir and returns the console output of
+// the command.
+func restore(opts options, entryDir string) ([]byte, error) {
+	for i, output := range opts.outputs {
+		cached := filepath.Join(entryDir, strconv.Itoa(i))
+		info, err := os.Stat(cached)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(cached)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFileIfChanged(output, data, info.Mode().Perm()); err != nil {
+			return nil, err
+		}
+	}
+	if opts.depFile != "" {
+		data, err := os.ReadFile(filepath.Join(entryDir, depFileName))
+		if err != nil {
+			return nil, err
+		}
+		data = []byte(denormalize(string(data), opts.outDir))
+		if err := writeFileIfChanged(opts.depFile, data, 0666); err != nil {
+			return nil, err
+		}
+	}
+	console, err := os.ReadFile(filepath.Join(entryDir, consoleFile))
+	if err != nil {
+		return nil, err
+	}
+	// Mark the entry as used, so that the entries that no build uses can be trimmed by their
+	// modification times.
+	now := time.Now()
+	os.Chtimes(entryDir, now, now)
+	return console, nil
+}
+
+func copyFile(from, to string) error {
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(to, data, info.Mode().Perm())
+}
+
+// store caches the outputs and the console output of a successful run of the command. The entry
+// is written to a temporary directory that is renamed into place, as concurrent builds may share
+// the cache.
+func store(opts options, key string, console []byte) error {
+	for _, output := range opts.outputs {
+		if _, err := os.Stat(output); err != nil {
+			// The command didn't write all its outputs, the result can't be reused.
+			return nil
+		}
+	}
+	entryDir := cachePath(opts.cacheDir, key)
+	if err := os.MkdirAll(filepath.Dir(entryDir), 0777); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(entryDir), "."+key)
+	if err != nil {
+		return err
+	}
+	write := func() error {
+		for i, output := range opts.outputs {
+			if err := copyFile(output, filepath.Join(tmp, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		if opts.depFile != "" {
+			data, err := os.ReadFile(opts.depFile)
+			if err != nil {
+				return err
+			}
+			data = []byte(normalize(string(data), opts.outDir))
+			if err := os.WriteFile(filepath.Join(tmp, depFileName), data, 0666); err != nil {
+				return err
+			}
+		}
+		return os.WriteFile(filepath.Join(tmp, consoleFile), console, 0666)
+	}
+	if err := write(); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, entryDir); err != nil {
+		os.RemoveAll(tmp)
+		if _, statErr := os.Stat(entryDir); statErr == nil {
+			// Another build cached the same result first.
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// runCommand runs the command and returns its console output and exit code.
+func runCommand(opts options) ([]byte, int, error) {
+	var console bytes.Buffer
+	cmd := exec.Command(opts.command[0], opts.command[1:]...)
+	cmd.Stdout = &console
+	cmd.Stderr = &console
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, 0, err
+		}
+		return console.Bytes(), exitErr.ExitCode(), nil
+	}
+	return console.Bytes(), 0, nil
+}
+
+// run runs the command, or restores its cached result, writes its console output to w and returns
+// the exit code of the command.
+func run(opts options, w io.Writer) (int, error) {
+	key, keyErr := cacheKey(opts)
+	if keyErr == nil {
+		entryDir := cachePath(opts.cacheDir, key)
+		if _, err := os.Stat(entryDir); err == nil {
+			console, err := restore(opts, entryDir)
+			if err == nil {
+				w.Write(console)
+				return 0, nil
+			}
+			fmt.Fprintf(os.Stderr, "action_cache: failed to restore the cached result %s: %s\n", entryDir, err)
+		}
+	}
+
+	console, exitCode, err := runCommand(opts)
+	if err != nil {
+		return 0, err
+	}
+	w.Write(console)
+	if exitCode == 0 && keyErr == nil {
+		if err := store(opts, key, console); err != nil {
+			fmt.Fprintf(os.Stderr, "action_cache: failed to cache the result of %s: %s\n", opts.outputs[0], err)
+		}
+	}
+	return exitCode, nil
+}
+
+func main() {
+	var opts options
+	var inputs, normalizedInputs, outputs multiString
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s --cache_dir <dir> --out_dir <dir> [--inputs <rsp file>]... [--normalized_input <file>]... --output <file> [--output <file>]... [--depfile <file>] -- <command>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&opts.cacheDir, "cache_dir", "", "directory to cache the results of the command in")
+	flag.StringVar(&opts.outDir, "out_dir", "", "the output directory, which is replaced with a placeholder in the cache keys")
+	flag.Var(&inputs, "inputs", "response file listing the inputs of the command, can be repeated")
+	flag.Var(&normalizedInputs, "normalized_input", "input whose paths in the output directory are normalized, can be repeated")
+	flag.Var(&outputs, "output", "output of the command, can be repeated")
+	flag.StringVar(&opts.depFile, "depfile", "", "the depfile that the command writes")
+	flag.Parse()
+	opts.inputs = inputs
+	opts.normalizedInputs = normalizedInputs
+	opts.outputs = outputs
+	opts.command = flag.Args()
+
+	if opts.cacheDir == "" || opts.outDir == "" || len(opts.outputs) == 0 || len(opts.command) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	exitCode, err := run(opts, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "action_cache:", err)
+		os.Exit(2)
+	}
+	os.Exit(exitCode)
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		s, outDir, expected string
+	}{
+		{"out/soong/foo", "out", outDirPlaceholder + "/soong/foo"},
+		{"-d out/soong/foo:out/bar", "out/", "-d " + outDirPlaceholder + "/soong/foo:" + outDirPlaceholder + "/bar"},
+		{"layout/foo", "out", "layout/foo"},
+		{"--flag=/abs/out/foo", "/abs/out", "--flag=" + outDirPlaceholder + "/foo"},
+		{"out", "out", "out"},
+	}
+	for _, tc := range testCases {
+		if got := normalize(tc.s, tc.outDir); got != tc.expected {
+			t.Errorf("normalize(%q, %q): expected %q, got %q", tc.s, tc.outDir, tc.expected, got)
+		}
+	}
+	if got := denormalize(outDirPlaceholder+"/soong/foo.d: a", "out2"); got != "out2/soong/foo.d: a" {
+		t.Errorf("expected the placeholder to be replaced, got %q", got)
+	}
+}
+
+func writeFile(t *testing.T, file, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(contents), 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	src := filepath.Join(dir, "src", "foo.txt")
+	writeFile(t, src, "foo")
+
+	// actionIn returns the options of the same action in the output directory outDir, which
+	// counts its runs in a file and copies its input to its output.
+	actionIn := func(outDir string) options {
+		out := filepath.Join(outDir, "soong", "foo.out")
+		inputs := filepath.Join(outDir, "soong", "foo.inputs.rsp")
+		writeFile(t, inputs, src)
+		script := "echo run >> " + filepath.Join(dir, "runs") + "; " +
+			"mkdir -p " + filepath.Dir(out) + "; " +
+			"cp " + src + " " + out + "; " +
+			"echo '" + out + ": " + src + "' > " + out + ".d; " +
+			"echo building"
+		return options{
+			cacheDir: cacheDir,
+			outDir:   outDir,
+			inputs:   []string{inputs},
+			outputs:  []string{out},
+			depFile:  out + ".d",
+			command:  []string{"sh", "-c", script},
+		}
+	}
+	runs := func() int {
+		data, _ := os.ReadFile(filepath.Join(dir, "runs"))
+		return strings.Count(string(data), "run")
+	}
+
+	opts := actionIn(filepath.Join(dir, "out"))
+	var console strings.Builder
+	exitCode, err := run(opts, &console)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+	if runs() != 1 {
+		t.Errorf("expected 1 run, got %d", runs())
+	}
+
+	// The same action in another output directory uses the cached result.
+	other := actionIn(filepath.Join(dir, "out2"))
+	console.Reset()
+	if _, err := run(other, &console); err != nil {
+		t.Fatal(err)
+	}
+	if runs() != 1 {
+		t.Errorf("expected the cached result to be used, got %d runs", runs())
+	}
+	if console.String() != "building\n" {
+		t.Errorf("expected the cached console output, got %q", console.String())
+	}
+	if data, err := os.ReadFile(other.outputs[0]); err != nil || string(data) != "foo" {
+		t.Errorf("expected the cached output to be restored, got %q, %v", data, err)
+	}
+	expectedDepFile := other.outputs[0] + ": " + src + "\n"
+	if data, err := os.ReadFile(other.depFile); err != nil || string(data) != expectedDepFile {
+		t.Errorf("expected depfile %q, got %q, %v", expectedDepFile, data, err)
+	}
+
+	// A change to an input invalidates the cached result.
+	writeFile(t, src, "bar")
+	if _, err := run(opts, &console); err != nil {
+		t.Fatal(err)
+	}
+	if runs() != 2 {
+		t.Errorf("expected the command to run again, got %d runs", runs())
+	}
+
+	// Failed runs aren't cached.
+	failing := actionIn(filepath.Join(dir, "out"))
+	failing.command = []string{"sh", "-c", "echo run >> " + filepath.Join(dir, "runs") + "; exit 3"}
+	for i := 0; i < 2; i++ {
+		exitCode, err := run(failing, &console)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exitCode != 3 {
+			t.Errorf("expected exit code 3, got %d", exitCode)
+		}
+	}
+	if runs() != 4 {
+		t.Errorf("expected the failing command to run every time, got %d runs", runs())
+	}
+}
@@ -0,0 +1,525 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// build_oci_image packages the contents of zip files into a tarball of an OCI image layout, with
+// one layer per zip file. The output only depends on the contents of the zip files and on the
+// flags: entries are sorted, and timestamps and owners are reset, so that the digests of the
+// layers and of the image are stable across builds.
+//
+// The base layers are extracted at the root of the image, and usually provide the C library and
+// the dynamic loader that the host tools of the other layers need. The image is only built if the
+// dynamic loader, or the script interpreter, of its entrypoint is in one of its layers.
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"debug/elf"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	mediaTypeIndex    = "application/vnd.oci.image.index.v1+json"
+	mediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeConfig   = "application/vnd.oci.image.config.v1+json"
+	mediaTypeLayer    = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+// epoch is the timestamp of all the entries of the layers and of the layout.
+var epoch = time.Unix(0, 0).UTC()
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// Descriptor is an OCI content descriptor.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Index is the index.json of an OCI image layout.
+type Index struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Manifests     []Descriptor `json:"manifests"`
+}
+
+// Manifest is an OCI image manifest.
+type Manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Config        Descriptor   `json:"config"`
+	Layers        []Descriptor `json:"layers"`
+}
+
+// ImageConfig is the execution configuration of an OCI image.
+type ImageConfig struct {
+	Env        []string `json:"Env,omitempty"`
+	Entrypoint []string `json:"Entrypoint,omitempty"`
+}
+
+// RootFS lists the digests of the uncompressed layers of an OCI image.
+type RootFS struct {
+	Type    string   `json:"type"`
+	DiffIDs []string `json:"diff_ids"`
+}
+
+// Config is an OCI image configuration. It has no creation time to keep the image deterministic.
+type Config struct {
+	Architecture string      `json:"architecture"`
+	OS           string      `json:"os"`
+	Config       ImageConfig `json:"config"`
+	RootFS       RootFS      `json:"rootfs"`
+}
+
+// blob is a content addressed file of the image layout.
+type blob struct {
+	descriptor Descriptor
+	data       []byte
+}
+
+func newBlob(mediaType string, data []byte) blob {
+	return blob{
+		descriptor: Descriptor{
+			MediaType: mediaType,
+			Digest:    digest(data),
+			Size:      int64(len(data)),
+		},
+		data: data,
+	}
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// buildLayer returns the uncompressed tar of a layer with the files of a zip file under root.
+func buildLayer(r *zip.Reader, root string) ([]byte, error) {
+	files := make(map[string]*zip.File)
+	dirs := make(map[string]bool)
+	addParents := func(name string) {
+		for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	for _, f := range r.File {
+		name := path.Join(root, strings.TrimSuffix(f.Name, "/"))
+		name = strings.TrimPrefix(name, "/")
+		if name == "" || name == "." {
+			continue
+		}
+		addParents(name)
+		if f.FileInfo().IsDir() {
+			dirs[name] = true
+		} else {
+			files[name] = f
+		}
+	}
+
+	var names []string
+	for dir := range dirs {
+		if _, ok := files[dir]; ok {
+			return nil, fmt.Errorf("%q is both a file and a directory", dir)
+		}
+		names = append(names, dir)
+	}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, name := range names {
+		hdr := &tar.Header{
+			Name:    name,
+			ModTime: epoch,
+			Format:  tar.FormatPAX,
+		}
+		f := files[name]
+		switch {
+		case f == nil:
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+			hdr.Mode = 0755
+		case f.Mode()&os.ModeSymlink != 0:
+			target, err := readZipFile(f)
+			if err != nil {
+				return nil, err
+			}
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = string(target)
+			hdr.Mode = 0777
+		default:
+			hdr.Typeflag = tar.TypeReg
+			hdr.Mode = 0644
+			if f.Mode()&0111 != 0 {
+				hdr.Mode = 0755
+			}
+			hdr.Size = int64(f.UncompressedSize64)
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			data, err := readZipFile(f)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// compress gzips a layer. The gzip header has no name and no timestamp.
+func compress(data []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	gz, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type imageArgs struct {
+	arch, os, tag, root string
+	env, entrypoint     []string
+}
+
+// imageFiles are the files of an image by their path, without a leading "/". The files of later
+// layers replace the ones of earlier layers.
+type imageFiles map[string]*zip.File
+
+func (files imageFiles) add(r *zip.Reader, root string) {
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		files[strings.TrimPrefix(path.Join(root, f.Name), "/")] = f
+	}
+}
+
+// maxSymlinks is the maximum number of symlinks followed to resolve a path, like the kernel's.
+const maxSymlinks = 40
+
+// resolve returns the file of the image at name, following the symlinks of the image, including
+// the ones of its directories.
+func (files imageFiles) resolve(name string) (*zip.File, error) {
+	for hops := 0; hops <= maxSymlinks; hops++ {
+		parts := strings.Split(strings.TrimPrefix(path.Clean("/"+name), "/"), "/")
+		followed := false
+		for i := range parts {
+			prefix := strings.Join(parts[:i+1], "/")
+			f, ok := files[prefix]
+			if !ok {
+				if i == len(parts)-1 {
+					return nil, fmt.Errorf("%s is not in the image", "/"+prefix)
+				}
+				// A directory of the image.
+				continue
+			}
+			if f.Mode()&os.ModeSymlink == 0 {
+				if i == len(parts)-1 {
+					return f, nil
+				}
+				return nil, fmt.Errorf("%s is not a directory", "/"+prefix)
+			}
+			target, err := readZipFile(f)
+			if err != nil {
+				return nil, err
+			}
+			link := string(target)
+			if !path.IsAbs(link) {
+				link = path.Join(path.Dir("/"+prefix), link)
+			}
+			name = path.Join(link, strings.Join(parts[i+1:], "/"))
+			followed = true
+			break
+		}
+		if !followed {
+			break
+		}
+	}
+	return nil, fmt.Errorf("too many levels of symlinks resolving %s", name)
+}
+
+// entrypointInterpreter returns the dynamic loader of an ELF entrypoint, or the interpreter of a
+// script, or an empty string if the entrypoint runs on its own, e.g. a static binary.
+func entrypointInterpreter(data []byte) (string, error) {
+	if bytes.HasPrefix(data, []byte("#!")) {
+		line, _, _ := bytes.Cut(data[2:], []byte("\n"))
+		fields := strings.Fields(string(line))
+		if len(fields) == 0 {
+			return "", fmt.Errorf("empty interpreter line")
+		}
+		return fields[0], nil
+	}
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return "", nil
+	}
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		interp, err := io.ReadAll(prog.Open())
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(interp), "\x00"), nil
+	}
+	return "", nil
+}
+
+// checkEntrypoint returns an error if the entrypoint of the image, or its dynamic loader or
+// interpreter, is not in the image.
+func checkEntrypoint(files imageFiles, entrypoint []string) error {
+	if len(entrypoint) == 0 {
+		return nil
+	}
+	f, err := files.resolve(entrypoint[0])
+	if err != nil {
+		return fmt.Errorf("entrypoint: %s", err)
+	}
+	data, err := readZipFile(f)
+	if err != nil {
+		return err
+	}
+	interp, err := entrypointInterpreter(data)
+	if err != nil {
+		return fmt.Errorf("entrypoint %s: %s", entrypoint[0], err)
+	}
+	if interp == "" {
+		return nil
+	}
+	if _, err := files.resolve(interp); err != nil {
+		return fmt.Errorf("entrypoint %s needs %s: %s, add a base layer that has it",
+			entrypoint[0], interp, err)
+	}
+	return nil
+}
+
+// buildImage writes the tarball of the OCI image layout with the given layers. The base layers are
+// extracted at the root of the image and the other layers under args.root.
+func buildImage(w io.Writer, baseLayers, layers []*zip.Reader, args imageArgs) error {
+	image := make(imageFiles)
+	for _, layer := range baseLayers {
+		image.add(layer, "")
+	}
+	for _, layer := range layers {
+		image.add(layer, args.root)
+	}
+	if err := checkEntrypoint(image, args.entrypoint); err != nil {
+		return err
+	}
+
+	var blobs []blob
+	config := Config{
+		Architecture: args.arch,
+		OS:           args.os,
+		Config: ImageConfig{
+			Env:        args.env,
+			Entrypoint: args.entrypoint,
+		},
+		RootFS: RootFS{Type: "layers", DiffIDs: []string{}},
+	}
+	manifest := Manifest{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeManifest,
+		Layers:        []Descriptor{},
+	}
+
+	type rootedLayer struct {
+		layer *zip.Reader
+		root  string
+	}
+	var rootedLayers []rootedLayer
+	for _, layer := range baseLayers {
+		rootedLayers = append(rootedLayers, rootedLayer{layer, ""})
+	}
+	for _, layer := range layers {
+		rootedLayers = append(rootedLayers, rootedLayer{layer, args.root})
+	}
+	for _, l := range rootedLayers {
+		data, err := buildLayer(l.layer, l.root)
+		if err != nil {
+			return err
+		}
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, digest(data))
+		compressed, err := compress(data)
+		if err != nil {
+			return err
+		}
+		b := newBlob(mediaTypeLayer, compressed)
+		manifest.Layers = append(manifest.Layers, b.descriptor)
+		blobs = append(blobs, b)
+	}
+
+	configData, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	configBlob := newBlob(mediaTypeConfig, configData)
+	manifest.Config = configBlob.descriptor
+	blobs = append(blobs, configBlob)
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	manifestBlob := newBlob(mediaTypeManifest, manifestData)
+	blobs = append(blobs, manifestBlob)
+
+	index := Index{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeIndex,
+		Manifests:     []Descriptor{manifestBlob.descriptor},
+	}
+	if args.tag != "" {
+		index.Manifests[0].Annotations = map[string]string{
+			"org.opencontainers.image.ref.name": args.tag,
+		}
+	}
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		"oci-layout": []byte(`{"imageLayoutVersion":"1.0.0"}`),
+		"index.json": indexData,
+	}
+	for _, b := range blobs {
+		files["blobs/sha256/"+strings.TrimPrefix(b.descriptor.Digest, "sha256:")] = b.data
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tar.NewWriter(w)
+	for _, dir := range []string{"blobs/", "blobs/sha256/"} {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir, Mode: 0755, ModTime: epoch}); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		data := files[name]
+		hdr := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(data)), ModTime: epoch}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func main() {
+	var baseLayerFiles, layerFiles, env, entrypoint stringList
+	out := flag.String("o", "", "output tarball of the OCI image layout")
+	arch := flag.String("arch", "amd64", "architecture of the image")
+	osName := flag.String("os", "linux", "operating system of the image")
+	tag := flag.String("tag", "latest", "reference name of the image in the layout")
+	root := flag.String("root", "", "directory of the image that the layers are extracted to")
+	flag.Var(&baseLayerFiles, "base_layer", "zip file with the contents of a layer extracted at the root of the image, can be repeated")
+	flag.Var(&layerFiles, "layer", "zip file with the contents of a layer, can be repeated")
+	flag.Var(&env, "env", "KEY=VALUE environment variable of the image, can be repeated")
+	flag.Var(&entrypoint, "entrypoint", "argument of the entrypoint of the image, can be repeated")
+	flag.Parse()
+
+	if *out == "" || len(layerFiles) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: build_oci_image -o <file> -layer <zip> [-layer <zip>...]")
+		os.Exit(1)
+	}
+
+	openLayers := func(files []string) []*zip.Reader {
+		var layers []*zip.Reader
+		for _, file := range files {
+			r, err := zip.OpenReader(file)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			layers = append(layers, &r.Reader)
+		}
+		return layers
+	}
+
+	buf := &bytes.Buffer{}
+	err := buildImage(buf, openLayers(baseLayerFiles), openLayers(layerFiles), imageArgs{
+		arch:       *arch,
+		os:         *osName,
+		tag:        *tag,
+		root:       *root,
+		env:        env,
+		entrypoint: entrypoint,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0666); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testFile struct {
+	name, contents string
+	mode           os.FileMode
+}
+
+func testZip(t *testing.T, files []testFile) *zip.Reader {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, f := range files {
+		hdr := &zip.FileHeader{Name: f.name, Method: zip.Store}
+		hdr.SetMode(f.mode)
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(f.contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// readTar returns the headers and the contents of the regular files of a tarball.
+func readTar(t *testing.T, r io.Reader) ([]*tar.Header, map[string][]byte) {
+	var headers []*tar.Header
+	contents := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return headers, contents
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		headers = append(headers, hdr)
+		if hdr.Typeflag == tar.TypeReg {
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			contents[hdr.Name] = data
+		}
+	}
+}
+
+func blobPath(digest string) string {
+	return "blobs/sha256/" + strings.TrimPrefix(digest, "sha256:")
+}
+
+func TestBuildImage(t *testing.T) {
+	layer := func() *zip.Reader {
+		return testZip(t, []testFile{
+			{name: "lib64/libc++.so", contents: "libc++", mode: 0644},
+			{name: "bin/aapt2", contents: "aapt2", mode: 0755},
+			{name: "bin/aapt", contents: "aapt2", mode: os.ModeSymlink | 0777},
+		})
+	}
+	args := imageArgs{
+		arch:       "amd64",
+		os:         "linux",
+		tag:        "latest",
+		root:       "opt/tools",
+		env:        []string{"PATH=/opt/tools/bin"},
+		entrypoint: []string{"/opt/tools/bin/aapt2"},
+	}
+
+	image := &bytes.Buffer{}
+	if err := buildImage(image, nil, []*zip.Reader{layer()}, args); err != nil {
+		t.Fatal(err)
+	}
+	again := &bytes.Buffer{}
+	if err := buildImage(again, nil, []*zip.Reader{layer()}, args); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(image.Bytes(), again.Bytes()) {
+		t.Errorf("expected the image to be deterministic")
+	}
+
+	_, files := readTar(t, image)
+	if got := string(files["oci-layout"]); got != `{"imageLayoutVersion":"1.0.0"}` {
+		t.Errorf("unexpected oci-layout %q", got)
+	}
+
+	var index Index
+	if err := json.Unmarshal(files["index.json"], &index); err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Manifests) != 1 || index.Manifests[0].Annotations["org.opencontainers.image.ref.name"] != "latest" {
+		t.Fatalf("unexpected index %+v", index)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(files[blobPath(index.Manifests[0].Digest)], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	var config Config
+	if err := json.Unmarshal(files[blobPath(manifest.Config.Digest)], &config); err != nil {
+		t.Fatal(err)
+	}
+	expectedConfig := Config{
+		Architecture: "amd64",
+		OS:           "linux",
+		Config: ImageConfig{
+			Env:        []string{"PATH=/opt/tools/bin"},
+			Entrypoint: []string{"/opt/tools/bin/aapt2"},
+		},
+		RootFS: RootFS{Type: "layers", DiffIDs: config.RootFS.DiffIDs},
+	}
+	if !reflect.DeepEqual(expectedConfig, config) {
+		t.Errorf("expected config %+v, got %+v", expectedConfig, config)
+	}
+	if len(manifest.Layers) != 1 || len(config.RootFS.DiffIDs) != 1 {
+		t.Fatalf("expected one layer, got %+v", manifest)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(files[blobPath(manifest.Layers[0].Digest)]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers, contents := readTar(t, gz)
+
+	type entry struct {
+		name     string
+		typeflag byte
+		mode     int64
+		linkname string
+	}
+	var entries []entry
+	for _, hdr := range headers {
+		entries = append(entries, entry{hdr.Name, hdr.Typeflag, hdr.Mode, hdr.Linkname})
+	}
+	expectedEntries := []entry{
+		{"opt/", tar.TypeDir, 0755, ""},
+		{"opt/tools/", tar.TypeDir, 0755, ""},
+		{"opt/tools/bin/", tar.TypeDir, 0755, ""},
+		{"opt/tools/bin/aapt", tar.TypeSymlink, 0777, "aapt2"},
+		{"opt/tools/bin/aapt2", tar.TypeReg, 0755, ""},
+		{"opt/tools/lib64/", tar.TypeDir, 0755, ""},
+		{"opt/tools/lib64/libc++.so", tar.TypeReg, 0644, ""},
+	}
+	if !reflect.DeepEqual(expectedEntries, entries) {
+		t.Errorf("expected layer entries:\n%v\ngot:\n%v", expectedEntries, entries)
+	}
+	if got := string(contents["opt/tools/bin/aapt2"]); got != "aapt2" {
+		t.Errorf("unexpected contents of aapt2 %q", got)
+	}
+}
+
+// testElf returns a minimal x86_64 executable, with the dynamic loader interp if it isn't empty.
+func testElf(t *testing.T, interp string) string {
+	const headerSize, progSize = 64, 56
+	buf := &bytes.Buffer{}
+	hdr := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     headerSize,
+		Ehsize:    headerSize,
+		Phentsize: progSize,
+		Phnum:     1,
+	}
+	copy(hdr.Ident[:], elf.ELFMAG)
+	hdr.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	hdr.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	hdr.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	prog := elf.Prog64{Type: uint32(elf.PT_LOAD), Flags: uint32(elf.PF_R | elf.PF_X), Align: 1}
+	if interp != "" {
+		prog = elf.Prog64{
+			Type:   uint32(elf.PT_INTERP),
+			Flags:  uint32(elf.PF_R),
+			Off:    headerSize + progSize,
+			Filesz: uint64(len(interp) + 1),
+			Memsz:  uint64(len(interp) + 1),
+			Align:  1,
+		}
+	}
+	for _, v := range []interface{}{hdr, prog} {
+		if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if interp != "" {
+		buf.WriteString(interp + "\x00")
+	}
+	return buf.String()
+}
+
+func TestEntrypointInterpreter(t *testing.T) {
+	const loader = "/lib64/ld-linux-x86-64.so.2"
+	base := func() *zip.Reader {
+		// A merged /usr root file system, where the loader is found through symlinks of both the
+		// file and a directory.
+		return testZip(t, []testFile{
+			{name: "lib64/ld-linux-x86-64.so.2", contents: "/lib/x86_64-linux-gnu/ld-linux-x86-64.so.2", mode: os.ModeSymlink | 0777},
+			{name: "lib", contents: "usr/lib", mode: os.ModeSymlink | 0777},
+			{name: "usr/lib/x86_64-linux-gnu/ld-linux-x86-64.so.2", contents: "ld.so", mode: 0755},
+			{name: "bin/sh", contents: "sh", mode: 0755},
+		})
+	}
+	tools := func(entrypoint string) *zip.Reader {
+		return testZip(t, []testFile{{name: "bin/tool", contents: entrypoint, mode: 0755}})
+	}
+	args := imageArgs{root: "opt/tools", entrypoint: []string{"/opt/tools/bin/tool"}}
+
+	testCases := []struct {
+		name          string
+		base          bool
+		entrypoint    string
+		expectedError string
+	}{
+		{name: "dynamic with base layer", base: true, entrypoint: testElf(t, loader)},
+		{
+			name:          "dynamic without base layer",
+			entrypoint:    testElf(t, loader),
+			expectedError: "entrypoint /opt/tools/bin/tool needs " + loader + ": /lib64/ld-linux-x86-64.so.2 is not in the image",
+		},
+		{
+			name:          "missing loader in base layer",
+			base:          true,
+			entrypoint:    testElf(t, "/lib/ld-musl-x86_64.so.1"),
+			expectedError: "/usr/lib/ld-musl-x86_64.so.1 is not in the image",
+		},
+		{name: "static without base layer", entrypoint: testElf(t, "")},
+		{name: "script with base layer", base: true, entrypoint: "#!/bin/sh -e\necho\n"},
+		{
+			name:          "script without base layer",
+			entrypoint:    "#!/bin/sh\n",
+			expectedError: "needs /bin/sh",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var baseLayers []*zip.Reader
+			if tc.base {
+				baseLayers = append(baseLayers, base())
+			}
+			err := buildImage(io.Discard, baseLayers, []*zip.Reader{tools(tc.entrypoint)}, args)
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error %s", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("expected an error containing %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+
+	// The base layer is the first layer, at the root of the image.
+	image := &bytes.Buffer{}
+	if err := buildImage(image, []*zip.Reader{base()}, []*zip.Reader{tools(testElf(t, loader))}, args); err != nil {
+		t.Fatal(err)
+	}
+	_, files := readTar(t, image)
+	var index Index
+	if err := json.Unmarshal(files["index.json"], &index); err != nil {
+		t.Fatal(err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(files[blobPath(index.Manifests[0].Digest)], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Layers) != 2 {
+		t.Fatalf("expected two layers, got %+v", manifest.Layers)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(files[blobPath(manifest.Layers[0].Digest)]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, contents := readTar(t, gz)
+	if _, ok := contents["usr/lib/x86_64-linux-gnu/ld-linux-x86-64.so.2"]; !ok {
+		t.Errorf("expected the dynamic loader in the base layer, got %v", contents)
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// find_duplicate_resources finds the identical resources that the android_library dependencies
+// of an app, and the app itself, define under different names. Unlike resources with the same name,
+// which override each other, each of them ends up in the APK. It reports the resource files with
+// identical contents, and the string, color, dimen, integer and bool values with identical values,
+// with the number of bytes that removing the duplicates would save.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestLibrary is an entry of the manifest that Soong writes for the app: the resource
+// directories of a library and their files.
+type ManifestLibrary struct {
+	Library string
+	Dirs    []ManifestDir
+}
+
+// ManifestDir is a resource directory and its files.
+type ManifestDir struct {
+	Dir   string
+	Files []string
+}
+
+// Copy is a copy of a duplicated resource.
+type Copy struct {
+	Library  string `json:"library"`
+	Resource string `json:"resource"`
+}
+
+// DuplicateFile is a resource file whose contents are duplicated under different names.
+type DuplicateFile struct {
+	Sha256      string `json:"sha256"`
+	Size        int64  `json:"size"`
+	WastedBytes int64  `json:"wasted_bytes"`
+	Copies      []Copy `json:"copies"`
+}
+
+// DuplicateValue is a resource value that is duplicated under different names.
+type DuplicateValue struct {
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	WastedBytes int64  `json:"wasted_bytes"`
+	Copies      []Copy `json:"copies"`
+}
+
+// Report is the output of find_duplicate_resources.
+type Report struct {
+	App         string           `json:"app"`
+	WastedBytes int64            `json:"wasted_bytes"`
+	Libraries   map[string]int64 `json:"wasted_bytes_by_library"`
+	Files       []DuplicateFile  `json:"files"`
+	Values      []DuplicateValue `json:"values"`
+}
+
+// valueTypes are the types of the values that are compared.
+var valueTypes = map[string]bool{
+	"string":  true,
+	"color":   true,
+	"dimen":   true,
+	"integer": true,
+	"bool":    true,
+}
+
+// resource is a copy of a resource file or value, with the key that identical copies share.
+type resource struct {
+	key  string
+	copy Copy
+	size int64
+
+	// The type and value of a value.
+	valueType, value string
+}
+
+// configDir returns the resource type and configuration directory of a file relative to a
+// resource directory, e.g. "values-fr" for "values-fr/strings.xml".
+func configDir(rel string) string {
+	return strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+}
+
+// readValues returns the values of a values XML file.
+func readValues(library, dir string, r io.Reader) ([]resource, error) {
+	var ret []resource
+	decoder := xml.NewDecoder(r)
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return ret, nil
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth != 2 || !valueTypes[t.Name.Local] {
+				continue
+			}
+			var name string
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "name" {
+					name = attr.Value
+				}
+			}
+			var content struct {
+				Inner string `xml:",innerxml"`
+			}
+			if err := decoder.DecodeElement(&content, &t); err != nil {
+				return nil, err
+			}
+			depth--
+			value := strings.TrimSpace(content.Inner)
+			ret = append(ret, resource{
+				key:       t.Name.Local + "\x00" + dir + "\x00" + value,
+				copy:      Copy{Library: library, Resource: dir + "/" + t.Name.Local + "/" + name},
+				size:      int64(len(value)),
+				valueType: t.Name.Local,
+				value:     value,
+			})
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// readResources returns the resource files and values of the libraries of the manifest.
+func readResources(manifest []ManifestLibrary) (files, values []resource, err error) {
+	for _, lib := range manifest {
+		for _, dir := range lib.Dirs {
+			for _, file := range dir.Files {
+				rel, err := filepath.Rel(dir.Dir, file)
+				if err != nil {
+					return nil, nil, err
+				}
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return nil, nil, err
+				}
+				config := configDir(rel)
+				if strings.HasPrefix(config, "values") {
+					v, err := readValues(lib.Library, config, bytes.NewReader(data))
+					if err != nil {
+						return nil, nil, fmt.Errorf("%s: %s", file, err)
+					}
+					values = append(values, v...)
+					continue
+				}
+				sum := sha256.Sum256(data)
+				files = append(files, resource{
+					key:  hex.EncodeToString(sum[:]),
+					copy: Copy{Library: lib.Library, Resource: filepath.ToSlash(rel)},
+					size: int64(len(data)),
+				})
+			}
+		}
+	}
+	return files, values, nil
+}
+
+// duplicates groups the resources by key and returns the groups with more than one distinct
+// resource name. Copies of a resource with the same name override each other, so they are not
+// duplicated in the APK.
+func duplicates(resources []resource) [][]resource {
+	groups := make(map[string][]resource)
+	var keys []string
+	for _, r := range resources {
+		if _, ok := groups[r.key]; !ok {
+			keys = append(keys, r.key)
+		}
+		groups[r.key] = append(groups[r.key], r)
+	}
+
+	var ret [][]resource
+	for _, key := range keys {
+		group := groups[key]
+		names := make(map[string]bool)
+		for _, r := range group {
+			names[r.copy.Resource] = true
+		}
+		if len(names) > 1 {
+			sort.SliceStable(group, func(i, j int) bool {
+				if group[i].copy.Library != group[j].copy.Library {
+					return group[i].copy.Library < group[j].copy.Library
+				}
+				return group[i].copy.Resource < group[j].copy.Resource
+			})
+			ret = append(ret, group)
+		}
+	}
+	return ret
+}
+
+// wasted returns the bytes wasted by a group of duplicates, and attributes them to the libraries of
+// all the distinct names but the first one.
+func wasted(group []resource, libraries map[string]int64) ([]Copy, int64) {
+	var copies []Copy
+	var total int64
+	names := make(map[string]bool)
+	for _, r := range group {
+		copies = append(copies, r.copy)
+		if names[r.copy.Resource] {
+			continue
+		}
+		if len(names) > 0 {
+			total += r.size
+			libraries[r.copy.Library] += r.size
+		}
+		names[r.copy.Resource] = true
+	}
+	return copies, total
+}
+
+func findDuplicates(app string, manifest []ManifestLibrary) (*Report, error) {
+	files, values, err := readResources(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		App:       app,
+		Libraries: make(map[string]int64),
+		Files:     []DuplicateFile{},
+		Values:    []DuplicateValue{},
+	}
+	for _, group := range duplicates(files) {
+		copies, w := wasted(group, report.Libraries)
+		report.Files = append(report.Files, DuplicateFile{
+			Sha256:      group[0].key,
+			Size:        group[0].size,
+			WastedBytes: w,
+			Copies:      copies,
+		})
+		report.WastedBytes += w
+	}
+	for _, group := range duplicates(values) {
+		copies, w := wasted(group, report.Libraries)
+		report.Values = append(report.Values, DuplicateValue{
+			Type:        group[0].valueType,
+			Value:       group[0].value,
+			WastedBytes: w,
+			Copies:      copies,
+		})
+		report.WastedBytes += w
+	}
+
+	sort.SliceStable(report.Files, func(i, j int) bool {
+		return report.Files[i].WastedBytes > report.Files[j].WastedBytes
+	})
+	sort.SliceStable(report.Values, func(i, j int) bool {
+		return report.Values[i].WastedBytes > report.Values[j].WastedBytes
+	})
+	return report, nil
+}
+
+func main() {
+	app := flag.String("app", "", "name of the app")
+	manifestFile := flag.String("manifest", "", "JSON file listing the resources of the libraries of the app")
+	out := flag.String("o", "", "output JSON report")
+	flag.Parse()
+
+	if *manifestFile == "" || *out == "" {
+		fmt.Fprintln(os.Stderr, "usage: find_duplicate_resources -app <name> -manifest <file> -o <file>")
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(*manifestFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var manifest []ManifestLibrary
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", *manifestFile, err)
+		os.Exit(1)
+	}
+
+	report, err := findDuplicates(*app, manifest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	data, err = json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, data, 0666); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
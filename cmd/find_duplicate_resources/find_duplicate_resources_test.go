@@ -0,0 +1,114 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	manifest := []ManifestLibrary{
+		{
+			Library: "App",
+			Dirs: []ManifestDir{{
+				Dir: filepath.Join(dir, "app/res"),
+				Files: []string{
+					writeFile("app/res/drawable/icon.png", "icon"),
+					writeFile("app/res/values/strings.xml", `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="app_ok">OK</string>
+    <string name="app_name">App</string>
+    <string-array name="array"><item>OK</item></string-array>
+</resources>`),
+				},
+			}},
+		},
+		{
+			Library: "liba",
+			Dirs: []ManifestDir{{
+				Dir: filepath.Join(dir, "liba/res"),
+				Files: []string{
+					writeFile("liba/res/drawable/liba_icon.png", "icon"),
+					writeFile("liba/res/drawable/icon.png", "other icon"),
+					writeFile("liba/res/values/strings.xml", `<resources>
+    <string name="liba_ok">OK</string>
+    <color name="liba_color">#ff0000</color>
+</resources>`),
+				},
+			}},
+		},
+		{
+			Library: "libb",
+			Dirs: []ManifestDir{{
+				Dir: filepath.Join(dir, "libb/res"),
+				Files: []string{
+					// Same name as in App, so it overrides it instead of being duplicated.
+					writeFile("libb/res/drawable/icon.png", "icon"),
+					writeFile("libb/res/values-fr/strings.xml", `<resources>
+    <string name="libb_ok">OK</string>
+</resources>`),
+				},
+			}},
+		},
+	}
+
+	report, err := findDuplicates("App", manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Report{
+		App:         "App",
+		WastedBytes: 6,
+		Libraries:   map[string]int64{"liba": 6},
+		Files: []DuplicateFile{{
+			Sha256:      "c2d4b446a44ce54fab8e01150e24dd24f3d850c7c14dcfe31f6321341dd86874",
+			Size:        4,
+			WastedBytes: 4,
+			Copies: []Copy{
+				{Library: "App", Resource: "drawable/icon.png"},
+				{Library: "liba", Resource: "drawable/liba_icon.png"},
+				{Library: "libb", Resource: "drawable/icon.png"},
+			},
+		}},
+		Values: []DuplicateValue{{
+			Type:        "string",
+			Value:       "OK",
+			WastedBytes: 2,
+			Copies: []Copy{
+				{Library: "App", Resource: "values/string/app_ok"},
+				{Library: "liba", Resource: "values/string/liba_ok"},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(expected, report) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, report)
+	}
+}
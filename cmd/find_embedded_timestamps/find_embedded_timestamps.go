@@ -0,0 +1,223 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// find_embedded_timestamps checks build outputs for timestamps of the time of the build, which make
+// the outputs of two builds of the same source differ. It looks for the dates of the last days as
+// written by the __DATE__ macro and in ISO 8601 format, and for zip entries modified in the last
+// days, e.g. in jars, APKs and APEXes. The SOURCE_DATE_EPOCH of the build, if any, is allowed.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"android/soong/response"
+)
+
+// Finding is an embedded timestamp found in a file.
+type Finding struct {
+	File    string
+	Message string
+}
+
+// checker looks for the timestamps between since and until, except for the ones of the epoch.
+type checker struct {
+	since, until time.Time
+	epoch        *time.Time
+
+	// The date strings to look for, with the format they are written in.
+	dates map[string]string
+}
+
+func newChecker(since, until time.Time, epoch *time.Time) *checker {
+	c := &checker{since: since, until: until, epoch: epoch, dates: make(map[string]string)}
+	formats := map[string]string{
+		"Jan _2 2006": "__DATE__",
+		"2006-01-02":  "ISO 8601 date",
+	}
+	for day := since.UTC().Truncate(24 * time.Hour); !day.After(until); day = day.Add(24 * time.Hour) {
+		for layout, name := range formats {
+			c.dates[day.Format(layout)] = name
+		}
+	}
+	if epoch != nil {
+		// The dates of the epoch are expected in the outputs.
+		for layout := range formats {
+			delete(c.dates, epoch.UTC().Format(layout))
+		}
+	}
+	return c
+}
+
+func (c *checker) inBuildWindow(t time.Time) bool {
+	if c.epoch != nil && t.Equal(*c.epoch) {
+		return false
+	}
+	return !t.Before(c.since) && !t.After(c.until)
+}
+
+// checkContents returns the findings in the contents of a file, or of an entry of a zip file. The
+// entries of zip files are checked instead of their raw contents.
+func (c *checker) checkContents(name string, data []byte) []Finding {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		if r, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+			return c.checkZip(name, r)
+		}
+	}
+
+	var findings []Finding
+	for date, format := range c.dates {
+		if bytes.Contains(data, []byte(date)) {
+			findings = append(findings, Finding{name, fmt.Sprintf("contains the %s %q", format, date)})
+		}
+	}
+	return findings
+}
+
+func (c *checker) checkZip(name string, r *zip.Reader) []Finding {
+	var findings []Finding
+	for _, f := range r.File {
+		entryName := name + "!" + f.Name
+		if c.inBuildWindow(f.Modified) {
+			findings = append(findings, Finding{entryName,
+				fmt.Sprintf("modification time %s", f.Modified.UTC().Format(time.RFC3339))})
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			findings = append(findings, Finding{entryName, fmt.Sprintf("failed to read: %s", err)})
+			continue
+		}
+		entryData, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			findings = append(findings, Finding{entryName, fmt.Sprintf("failed to read: %s", err)})
+			continue
+		}
+		findings = append(findings, c.checkContents(entryName, entryData)...)
+	}
+	return findings
+}
+
+// checkFile returns the findings in a file. Symlinks and directories are skipped.
+func (c *checker) checkFile(path string) ([]Finding, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.checkContents(path, data), nil
+}
+
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Message < findings[j].Message
+	})
+}
+
+func readInputs(args []string) ([]string, error) {
+	var inputs []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "@") {
+			inputs = append(inputs, arg)
+			continue
+		}
+		f, err := os.Open(strings.TrimPrefix(arg, "@"))
+		if err != nil {
+			return nil, err
+		}
+		rspInputs, err := response.ReadRspFile(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, rspInputs...)
+	}
+	return inputs, nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-o <report.txt>] [-epoch <seconds>] [-window <duration>] <files or @rspfiles>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	output := flag.String("o", "", "file to write the embedded timestamps to, defaults to stdout")
+	epochFlag := flag.Int64("epoch", -1, "SOURCE_DATE_EPOCH of the build, whose timestamps are allowed")
+	window := flag.Duration("window", 48*time.Hour, "how far back timestamps are considered to be of the time of the build")
+	flag.Parse()
+
+	inputs, err := readInputs(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var epoch *time.Time
+	if *epochFlag >= 0 {
+		t := time.Unix(*epochFlag, 0)
+		epoch = &t
+	}
+	now := time.Now()
+	c := newChecker(now.Add(-*window), now, epoch)
+
+	var findings []Finding
+	for _, input := range inputs {
+		fileFindings, err := c.checkFile(input)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		findings = append(findings, fileFindings...)
+	}
+	sortFindings(findings)
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		defer out.Close()
+	}
+	for _, f := range findings {
+		fmt.Fprintf(out, "%s: %s\n", f.File, f.Message)
+	}
+
+	if len(findings) > 0 {
+		if *output != "" {
+			fmt.Fprintf(os.Stderr, "found %d embedded timestamps of the time of the build, see %s\n", len(findings), *output)
+		}
+		out.Close()
+		os.Exit(1)
+	}
+}
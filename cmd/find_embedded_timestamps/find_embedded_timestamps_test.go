@@ -0,0 +1,99 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testZip(t *testing.T, entries map[string]time.Time, contents string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, modified := range entries {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Modified: modified})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(contents))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheckContents(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	epoch := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	defaultTime := time.Date(2008, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newChecker(now.Add(-48*time.Hour), now, &epoch)
+
+	testCases := []struct {
+		name string
+		data []byte
+		want []Finding
+	}{
+		{
+			name: "no timestamps",
+			data: []byte("built on Jan  1 2008"),
+		},
+		{
+			name: "__DATE__",
+			data: []byte("built on Oct 16 2026"),
+			want: []Finding{{"file", `contains the __DATE__ "Oct 16 2026"`}},
+		},
+		{
+			name: "ISO date",
+			data: []byte("date=2026-10-14"),
+			want: []Finding{{"file", `contains the ISO 8601 date "2026-10-14"`}},
+		},
+		{
+			name: "date of the epoch",
+			data: []byte("Oct 15 2026 2026-10-15"),
+		},
+		{
+			name: "zip",
+			data: testZip(t, map[string]time.Time{
+				"default": defaultTime,
+				"epoch":   epoch,
+			}, "clean"),
+		},
+		{
+			name: "zip with build time",
+			data: testZip(t, map[string]time.Time{
+				"a": now.Add(-time.Hour),
+			}, "Oct 16 2026"),
+			want: []Finding{
+				{"file!a", `contains the __DATE__ "Oct 16 2026"`},
+				{"file!a", "modification time 2026-10-16T11:00:00Z"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := c.checkContents("file", tc.data)
+			sortFindings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -18,6 +18,19 @@
 // It also hides the unhelpful and unhideable "warning there is a warning"
 // messages.
 //
+// With --diagnostics_out, the warnings and errors reported by javac are
+// also written to a JSON file.  Without a javac command, an empty
+// diagnostics file is written, for the actions that have nothing to compile.
+//
+// With --stale_sources, the command is incremental_javac, which writes the
+// sources it recompiled or removed to that file for an incremental
+// compilation.  The diagnostics of the other sources are kept from the
+// previous diagnostics file, so that the diagnostics file always covers all
+// the sources of the module.
+//
+// With --check_warning_budget N, the remaining arguments are diagnostics
+// files, and the wrapper fails if they report more than N warnings in total.
+//
 // Each javac build statement has an order-only dependency on the
 // soong_javac_wrapper tool, which means the javac command will not be rerun
 // if soong_javac_wrapper changes.  That means that soong_javac_wrapper must
@@ -26,12 +39,15 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
+	"strings"
 	"syscall"
 )
 
@@ -60,9 +76,45 @@ func main() {
 	os.Exit(exitCode)
 }
 
+// Diagnostics is the content of the file written with --diagnostics_out.
+type Diagnostics struct {
+	Warnings    int          `json:"warnings"`
+	Errors      int          `json:"errors"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Diagnostic is a warning or an error reported by javac.  File and Line are
+// empty for the diagnostics that are not about a source file, like
+// "warning: [options] ...".
+type Diagnostic struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
 func Main(out io.Writer, name string, args []string) (int, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(out)
+	diagnosticsOut := flags.String("diagnostics_out", "", "write the warnings and errors of javac to a JSON file")
+	staleSources := flags.String("stale_sources", "",
+		"keep the diagnostics of the sources that incremental_javac didn't list in this file from the previous diagnostics file")
+	warningBudget := flags.Int("check_warning_budget", -1,
+		"fail if the diagnostics files in the arguments report more warnings than this")
+	if err := flags.Parse(args); err != nil {
+		return 1, err
+	}
+	args = flags.Args()
+
+	if *warningBudget >= 0 {
+		return checkWarningBudget(out, *warningBudget, args)
+	}
+
 	if len(args) < 1 {
-		return 1, fmt.Errorf("usage: %s javac ...", name)
+		if *diagnosticsOut != "" {
+			return 0, writeDiagnostics(*diagnosticsOut, &Diagnostics{})
+		}
+		return 1, fmt.Errorf("usage: %s [--diagnostics_out file] javac ...", name)
 	}
 
 	pr, pw, err := os.Pipe()
@@ -94,6 +146,17 @@ func Main(out io.Writer, name string, args []string) (int, error) {
 	// Wait for asynchronous stdout processing to finish
 	err = <-errCh
 
+	// The diagnostics are written whether javac succeeded or not
+	if *diagnosticsOut != "" {
+		diagnostics := proc.diagnostics
+		if *staleSources != "" {
+			diagnostics = incrementalDiagnostics(*diagnosticsOut, *staleSources, diagnostics)
+		}
+		if writeErr := writeDiagnostics(*diagnosticsOut, &diagnostics); writeErr != nil {
+			return 1, writeErr
+		}
+	}
+
 	// Check for subprocess exit code
 	if cmdErr != nil {
 		if exitErr, ok := cmdErr.(*exec.ExitError); ok {
@@ -119,8 +182,124 @@ func Main(out io.Writer, name string, args []string) (int, error) {
 	return 0, nil
 }
 
+func writeDiagnostics(file string, diagnostics *Diagnostics) error {
+	if diagnostics.Diagnostics == nil {
+		diagnostics.Diagnostics = []Diagnostic{}
+	}
+	data, err := json.MarshalIndent(diagnostics, "", "  ")
+	if err != nil {
+		return fmt.Errorf("writing diagnostics: %s", err)
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0666); err != nil {
+		return fmt.Errorf("writing diagnostics: %s", err)
+	}
+	return nil
+}
+
+// StaleSources is the content of the file that incremental_javac writes for an
+// incremental compilation.
+type StaleSources struct {
+	// The sources that were recompiled or removed.
+	Stale []string `json:"stale"`
+	// Whether javac ran, i.e. whether any source was recompiled.
+	Compiled bool `json:"compiled"`
+}
+
+// incrementalDiagnostics returns the diagnostics of all the sources of the
+// module after an incremental compilation.  Without a stale sources file, when
+// incremental_javac compiled all the sources or failed, or without previous
+// diagnostics, they are the diagnostics of this run.
+func incrementalDiagnostics(previousFile, staleFile string, current Diagnostics) Diagnostics {
+	data, err := os.ReadFile(staleFile)
+	if err != nil {
+		return current
+	}
+	var stale StaleSources
+	if err := json.Unmarshal(data, &stale); err != nil {
+		return current
+	}
+	data, err = os.ReadFile(previousFile)
+	if err != nil {
+		return current
+	}
+	var previous Diagnostics
+	if err := json.Unmarshal(data, &previous); err != nil {
+		return current
+	}
+	return mergeDiagnostics(previous, stale, current)
+}
+
+// mergeDiagnostics returns the diagnostics of this run and the previous
+// diagnostics of the sources that weren't recompiled or removed.  The
+// diagnostics that are not about a source file are reported again by every
+// javac run, so the previous ones are only kept if javac didn't run.
+func mergeDiagnostics(previous Diagnostics, stale StaleSources, current Diagnostics) Diagnostics {
+	isStale := make(map[string]bool)
+	for _, file := range stale.Stale {
+		isStale[file] = true
+	}
+	var merged Diagnostics
+	add := func(d Diagnostic) {
+		if d.Kind == "warning" {
+			merged.Warnings++
+		} else {
+			merged.Errors++
+		}
+		merged.Diagnostics = append(merged.Diagnostics, d)
+	}
+	for _, d := range previous.Diagnostics {
+		if (d.File == "" && stale.Compiled) || (d.File != "" && isStale[d.File]) {
+			continue
+		}
+		add(d)
+	}
+	for _, d := range current.Diagnostics {
+		add(d)
+	}
+	return merged
+}
+
+// checkWarningBudget sums the warnings of the diagnostics files, and lists
+// them on out when there are more than budget.
+func checkWarningBudget(out io.Writer, budget int, files []string) (int, error) {
+	var warnings []Diagnostic
+	total := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return 1, fmt.Errorf("reading diagnostics: %s", err)
+		}
+		var diagnostics Diagnostics
+		if err := json.Unmarshal(data, &diagnostics); err != nil {
+			return 1, fmt.Errorf("parsing diagnostics %s: %s", file, err)
+		}
+		total += diagnostics.Warnings
+		for _, d := range diagnostics.Diagnostics {
+			if d.Kind == "warning" {
+				warnings = append(warnings, d)
+			}
+		}
+	}
+	if total <= budget {
+		return 0, nil
+	}
+
+	fmt.Fprintf(out, "%s%serror:%s%s javac reported %d warnings, the warning budget of the module is %d%s\n",
+		bold, red, reset, bold, total, budget, reset)
+	for _, d := range warnings {
+		if d.File != "" {
+			fmt.Fprintf(out, "%s:%d: %s\n", d.File, d.Line, d.Message)
+		} else {
+			fmt.Fprintln(out, d.Message)
+		}
+	}
+	fmt.Fprintln(out, "Fix the warnings, or raise javac_warning_budget in the Android.bp file of the module.")
+	return 1, nil
+}
+
 type processor struct {
 	silencedWarnings int
+	diagnostics      Diagnostics
 }
 
 func (proc *processor) process(r io.Reader, w io.Writer) error {
@@ -151,6 +330,7 @@ func (proc *processor) processLine(w io.Writer, line string) {
 			return
 		}
 	}
+	proc.recordDiagnostic(line)
 	if match := warningCount.FindStringSubmatch(line); match != nil {
 		c, err := strconv.Atoi(match[1])
 		if err == nil {
@@ -174,6 +354,30 @@ func (proc *processor) processLine(w io.Writer, line string) {
 	fmt.Fprintln(w, line)
 }
 
+// recordDiagnostic adds the line to the diagnostics if it is a warning or an
+// error reported by javac.
+func (proc *processor) recordDiagnostic(line string) {
+	var d Diagnostic
+	if m := diagnosticRe.FindStringSubmatch(line); m != nil {
+		d.File = m[1]
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Kind = m[3]
+		d.Message = m[4]
+	} else if m := globalDiagnosticRe.FindStringSubmatch(line); m != nil {
+		d.Kind = m[1]
+		d.Message = m[2]
+	} else {
+		return
+	}
+	d.Message = strings.TrimSpace(d.Message)
+	if d.Kind == "warning" {
+		proc.diagnostics.Warnings++
+	} else {
+		proc.diagnostics.Errors++
+	}
+	proc.diagnostics.Diagnostics = append(proc.diagnostics.Diagnostics, d)
+}
+
 // If line matches re, make it bold and apply color to the first submatch
 // Returns line, modified if it matched, and true if it matched.
 func applyColor(line, color string, re *regexp.Regexp) (string, bool) {
@@ -196,6 +400,11 @@ var colorPatterns = []struct {
 	{markerRe, green},
 }
 
+var (
+	diagnosticRe       = regexp.MustCompile(`^([-.\w/\\]+.java):([0-9]+): (warning|error): (.*)$`)
+	globalDiagnosticRe = regexp.MustCompile(`^(warning|error): (.*)$`)
+)
+
 var warningCount = regexp.MustCompile(`^([0-9]+) warning(s)?$`)
 
 var warningFilters = []*regexp.Regexp{
@@ -16,8 +16,13 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -141,3 +146,170 @@ func TestSubprocess(t *testing.T) {
 	})
 
 }
+
+func readDiagnostics(t *testing.T, file string) Diagnostics {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diagnostics Diagnostics
+	if err := json.Unmarshal(data, &diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	return diagnostics
+}
+
+func TestDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "diagnostics.json")
+	output := `File.java:40: error: cannot find symbol
+          ^
+dir/Other.java:398: warning: [RectIntersectReturnValueIgnored] Return value must be checked
+warning: [options] bootstrap class path not set in conjunction with -source 1.7
+warning: [options] blah
+2 warnings
+1 error
+`
+	exitCode, err := Main(ioutil.Discard, "test",
+		[]string{"--diagnostics_out", out, "sh", "-c", "printf '%s' \"$0\"; exit 1", output})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if exitCode != 1 {
+		t.Fatal("expected exit code 1, got", exitCode)
+	}
+
+	expected := Diagnostics{
+		Warnings: 2,
+		Errors:   1,
+		Diagnostics: []Diagnostic{
+			{File: "File.java", Line: 40, Kind: "error", Message: "cannot find symbol"},
+			{File: "dir/Other.java", Line: 398, Kind: "warning",
+				Message: "[RectIntersectReturnValueIgnored] Return value must be checked"},
+			{Kind: "warning", Message: "[options] blah"},
+		},
+	}
+	if got := readDiagnostics(t, out); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v got %#v", expected, got)
+	}
+
+	// Without a javac command an empty diagnostics file is written.
+	exitCode, err = Main(ioutil.Discard, "test", []string{"--diagnostics_out", out})
+	if err != nil || exitCode != 0 {
+		t.Fatal("unexpected failure", exitCode, err)
+	}
+	if got := readDiagnostics(t, out); got.Warnings != 0 || len(got.Diagnostics) != 0 {
+		t.Errorf("expected empty diagnostics, got %#v", got)
+	}
+}
+
+func TestCheckWarningBudget(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i, warnings := range []int{2, 1} {
+		diagnostics := Diagnostics{Warnings: warnings}
+		for j := 0; j < warnings; j++ {
+			diagnostics.Diagnostics = append(diagnostics.Diagnostics, Diagnostic{
+				File: "File" + strconv.Itoa(i) + ".java", Line: j + 1, Kind: "warning", Message: "[Foo] foo"})
+		}
+		file := filepath.Join(dir, strconv.Itoa(i)+".json")
+		if err := writeDiagnostics(file, &diagnostics); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	t.Run("within budget", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		exitCode, err := Main(buf, "test", append([]string{"--check_warning_budget", "3"}, files...))
+		if err != nil || exitCode != 0 {
+			t.Fatal("unexpected failure", exitCode, err, buf.String())
+		}
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		exitCode, err := Main(buf, "test", append([]string{"--check_warning_budget", "2"}, files...))
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if exitCode != 1 {
+			t.Fatal("expected exit code 1, got", exitCode)
+		}
+		for _, s := range []string{"javac reported 3 warnings", "budget of the module is 2",
+			"File0.java:2: [Foo] foo", "File1.java:1: [Foo] foo"} {
+			if !strings.Contains(buf.String(), s) {
+				t.Errorf("expected %q in output %q", s, buf.String())
+			}
+		}
+	})
+}
+
+func TestIncrementalDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "diagnostics.json")
+	stale := filepath.Join(dir, "stale.json")
+	previous := Diagnostics{
+		Warnings: 3,
+		Diagnostics: []Diagnostic{
+			{File: "A.java", Line: 1, Kind: "warning", Message: "[Foo] a"},
+			{File: "B.java", Line: 2, Kind: "warning", Message: "[Foo] b"},
+			{Kind: "warning", Message: "[options] blah"},
+		},
+	}
+	writeStale := func(t *testing.T, s StaleSources) {
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(stale, data, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(t *testing.T, output string) Diagnostics {
+		if err := writeDiagnostics(out, &previous); err != nil {
+			t.Fatal(err)
+		}
+		exitCode, err := Main(ioutil.Discard, "test", []string{"--diagnostics_out", out,
+			"--stale_sources", stale, "sh", "-c", "printf '%s' \"$0\"", output})
+		if err != nil || exitCode != 0 {
+			t.Fatal("unexpected failure", exitCode, err)
+		}
+		return readDiagnostics(t, out)
+	}
+
+	t.Run("recompiled", func(t *testing.T) {
+		// B.java was recompiled without its warning, the warning of A.java is kept.
+		writeStale(t, StaleSources{Stale: []string{"B.java"}, Compiled: true})
+		expected := Diagnostics{
+			Warnings: 2,
+			Diagnostics: []Diagnostic{
+				{File: "A.java", Line: 1, Kind: "warning", Message: "[Foo] a"},
+				{Kind: "warning", Message: "[options] blah"},
+			},
+		}
+		if got := run(t, "warning: [options] blah\n"); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %#v got %#v", expected, got)
+		}
+	})
+
+	t.Run("nothing recompiled", func(t *testing.T) {
+		writeStale(t, StaleSources{})
+		if got := run(t, ""); !reflect.DeepEqual(got, previous) {
+			t.Errorf("expected %#v got %#v", previous, got)
+		}
+	})
+
+	t.Run("full compilation", func(t *testing.T) {
+		// incremental_javac doesn't write the stale sources file for a full compilation.
+		os.Remove(stale)
+		expected := Diagnostics{
+			Warnings:    1,
+			Diagnostics: []Diagnostic{{File: "B.java", Line: 3, Kind: "warning", Message: "[Foo] b"}},
+		}
+		if got := run(t, "B.java:3: warning: [Foo] b\n"); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %#v got %#v", expected, got)
+		}
+	})
+}
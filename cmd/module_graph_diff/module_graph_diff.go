@@ -0,0 +1,406 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// module_graph_diff computes the semantic delta between two module graphs written by
+// `m json-module-graph`: the module variants that were added or removed, and the dependencies and
+// properties of the module variants that changed. It is used to annotate changes with their impact
+// on the build graph.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The subset of the module graph written by blueprint's PrintJSONGraphAndActions that is compared.
+
+type jsonVariation struct {
+	Mutator   string
+	Variation string
+}
+
+type jsonModuleName struct {
+	Name       string
+	Variant    string
+	Variations []jsonVariation
+}
+
+type jsonDep struct {
+	jsonModuleName
+	Tag string
+}
+
+type jsonProperty struct {
+	Name   string
+	Value  string
+	Values []string
+}
+
+type jsonModule struct {
+	jsonModuleName
+	Deps      []jsonDep
+	Type      string
+	Blueprint string
+	Module    struct {
+		Android struct {
+			SetProperties []jsonProperty
+		}
+	}
+}
+
+// variant returns the variant of a module, or a variant made of its variations for module graphs
+// that don't have the variant.
+func (n jsonModuleName) variant() string {
+	if n.Variant != "" || len(n.Variations) == 0 {
+		return n.Variant
+	}
+	var parts []string
+	for _, v := range n.Variations {
+		if v.Variation != "" {
+			parts = append(parts, v.Variation)
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// ModuleVariant identifies a variant of a module.
+type ModuleVariant struct {
+	Name    string `json:"name"`
+	Variant string `json:"variant"`
+}
+
+func (m ModuleVariant) String() string {
+	if m.Variant == "" {
+		return m.Name
+	}
+	return m.Name + " (" + m.Variant + ")"
+}
+
+func (m ModuleVariant) less(other ModuleVariant) bool {
+	if m.Name != other.Name {
+		return m.Name < other.Name
+	}
+	return m.Variant < other.Variant
+}
+
+// Module is a module variant that was added or removed.
+type Module struct {
+	ModuleVariant
+	Type      string `json:"type"`
+	Blueprint string `json:"blueprint"`
+}
+
+// Dependency is a dependency of a module variant.
+type Dependency struct {
+	ModuleVariant
+	Tag string `json:"tag,omitempty"`
+}
+
+// PropertyChange is a property of a module variant that was set, unset or changed. The values of
+// list properties are written as comma-separated lists of quoted strings.
+type PropertyChange struct {
+	Name     string  `json:"name"`
+	OldValue *string `json:"old_value,omitempty"`
+	NewValue *string `json:"new_value,omitempty"`
+}
+
+// ModuleChange is a module variant that exists in both module graphs and whose type, dependencies
+// or properties changed.
+type ModuleChange struct {
+	ModuleVariant
+	OldType           string           `json:"old_type,omitempty"`
+	Type              string           `json:"type"`
+	AddedDeps         []Dependency     `json:"added_deps,omitempty"`
+	RemovedDeps       []Dependency     `json:"removed_deps,omitempty"`
+	ChangedProperties []PropertyChange `json:"changed_properties,omitempty"`
+}
+
+// Delta is the difference between two module graphs.
+type Delta struct {
+	AddedModules   []Module       `json:"added_modules"`
+	RemovedModules []Module       `json:"removed_modules"`
+	ChangedModules []ModuleChange `json:"changed_modules"`
+}
+
+// Empty returns true if the module graphs are the same.
+func (d *Delta) Empty() bool {
+	return len(d.AddedModules) == 0 && len(d.RemovedModules) == 0 && len(d.ChangedModules) == 0
+}
+
+func readModuleGraph(r io.Reader) (map[ModuleVariant]*jsonModule, error) {
+	var modules []*jsonModule
+	if err := json.NewDecoder(r).Decode(&modules); err != nil {
+		return nil, err
+	}
+	ret := make(map[ModuleVariant]*jsonModule, len(modules))
+	for _, m := range modules {
+		key := ModuleVariant{m.Name, m.variant()}
+		if _, exists := ret[key]; exists {
+			return nil, fmt.Errorf("duplicate module variant %s", key)
+		}
+		ret[key] = m
+	}
+	return ret, nil
+}
+
+func sortedModuleVariants(modules map[ModuleVariant]*jsonModule) []ModuleVariant {
+	ret := make([]ModuleVariant, 0, len(modules))
+	for key := range modules {
+		ret = append(ret, key)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].less(ret[j]) })
+	return ret
+}
+
+func moduleOf(key ModuleVariant, m *jsonModule) Module {
+	return Module{ModuleVariant: key, Type: m.Type, Blueprint: m.Blueprint}
+}
+
+// computeDelta returns the module variants that were added to or removed from the old module graph,
+// and the changes to the module variants that are in both.
+func computeDelta(oldModules, newModules map[ModuleVariant]*jsonModule) *Delta {
+	delta := &Delta{
+		AddedModules:   []Module{},
+		RemovedModules: []Module{},
+		ChangedModules: []ModuleChange{},
+	}
+	for _, key := range sortedModuleVariants(oldModules) {
+		if _, ok := newModules[key]; !ok {
+			delta.RemovedModules = append(delta.RemovedModules, moduleOf(key, oldModules[key]))
+		}
+	}
+	for _, key := range sortedModuleVariants(newModules) {
+		newModule := newModules[key]
+		oldModule, ok := oldModules[key]
+		if !ok {
+			delta.AddedModules = append(delta.AddedModules, moduleOf(key, newModule))
+			continue
+		}
+		if change, changed := compareModules(key, oldModule, newModule); changed {
+			delta.ChangedModules = append(delta.ChangedModules, change)
+		}
+	}
+	return delta
+}
+
+func compareModules(key ModuleVariant, oldModule, newModule *jsonModule) (ModuleChange, bool) {
+	change := ModuleChange{ModuleVariant: key, Type: newModule.Type}
+	if oldModule.Type != newModule.Type {
+		change.OldType = oldModule.Type
+	}
+	change.AddedDeps, change.RemovedDeps = compareDeps(oldModule.Deps, newModule.Deps)
+	change.ChangedProperties = compareProperties(
+		oldModule.Module.Android.SetProperties, newModule.Module.Android.SetProperties)
+
+	changed := change.OldType != "" || len(change.AddedDeps) > 0 || len(change.RemovedDeps) > 0 ||
+		len(change.ChangedProperties) > 0
+	return change, changed
+}
+
+func dependencySet(deps []jsonDep) map[Dependency]bool {
+	ret := make(map[Dependency]bool, len(deps))
+	for _, dep := range deps {
+		ret[Dependency{ModuleVariant{dep.Name, dep.variant()}, dep.Tag}] = true
+	}
+	return ret
+}
+
+func sortedDependencies(deps []Dependency) []Dependency {
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].ModuleVariant != deps[j].ModuleVariant {
+			return deps[i].less(deps[j].ModuleVariant)
+		}
+		return deps[i].Tag < deps[j].Tag
+	})
+	return deps
+}
+
+func compareDeps(oldDeps, newDeps []jsonDep) (added, removed []Dependency) {
+	oldSet, newSet := dependencySet(oldDeps), dependencySet(newDeps)
+	for dep := range newSet {
+		if !oldSet[dep] {
+			added = append(added, dep)
+		}
+	}
+	for dep := range oldSet {
+		if !newSet[dep] {
+			removed = append(removed, dep)
+		}
+	}
+	return sortedDependencies(added), sortedDependencies(removed)
+}
+
+func propertyValue(p jsonProperty) string {
+	if p.Values == nil {
+		return p.Value
+	}
+	quoted := make([]string, len(p.Values))
+	for i, v := range p.Values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func compareProperties(oldProps, newProps []jsonProperty) []PropertyChange {
+	oldValues := make(map[string]string, len(oldProps))
+	for _, p := range oldProps {
+		oldValues[p.Name] = propertyValue(p)
+	}
+	newValues := make(map[string]string, len(newProps))
+	for _, p := range newProps {
+		newValues[p.Name] = propertyValue(p)
+	}
+
+	names := make(map[string]bool)
+	for name := range oldValues {
+		names[name] = true
+	}
+	for name := range newValues {
+		names[name] = true
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	var changes []PropertyChange
+	for _, name := range sortedNames {
+		oldValue, oldOk := oldValues[name]
+		newValue, newOk := newValues[name]
+		if oldOk && newOk && oldValue == newValue {
+			continue
+		}
+		change := PropertyChange{Name: name}
+		if oldOk {
+			change.OldValue = &oldValue
+		}
+		if newOk {
+			change.NewValue = &newValue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// writeSummary writes a human readable summary of the delta, for annotating a change.
+func writeSummary(w io.Writer, delta *Delta) {
+	if delta.Empty() {
+		fmt.Fprintln(w, "The module graph didn't change.")
+		return
+	}
+	fmt.Fprintf(w, "%d module variants added, %d removed, %d changed\n",
+		len(delta.AddedModules), len(delta.RemovedModules), len(delta.ChangedModules))
+	for _, m := range delta.AddedModules {
+		fmt.Fprintf(w, "+ %s %s\n", m.Type, m)
+	}
+	for _, m := range delta.RemovedModules {
+		fmt.Fprintf(w, "- %s %s\n", m.Type, m)
+	}
+	for _, m := range delta.ChangedModules {
+		fmt.Fprintf(w, "~ %s %s\n", m.Type, m.ModuleVariant)
+		if m.OldType != "" {
+			fmt.Fprintf(w, "    type: %s -> %s\n", m.OldType, m.Type)
+		}
+		for _, dep := range m.AddedDeps {
+			fmt.Fprintf(w, "    + dep %s\n", dep.ModuleVariant)
+		}
+		for _, dep := range m.RemovedDeps {
+			fmt.Fprintf(w, "    - dep %s\n", dep.ModuleVariant)
+		}
+		for _, p := range m.ChangedProperties {
+			fmt.Fprintf(w, "    %s: %s -> %s\n", p.Name, optionalValue(p.OldValue), optionalValue(p.NewValue))
+		}
+	}
+}
+
+func optionalValue(v *string) string {
+	if v == nil {
+		return "(unset)"
+	}
+	return *v
+}
+
+func readModuleGraphFile(path string) (map[ModuleVariant]*jsonModule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	modules, err := readModuleGraph(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return modules, nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-o <output.json>] [-summary] <old module-graph.json> <new module-graph.json>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	output := flag.String("o", "", "JSON file to write the delta to, defaults to stdout")
+	summary := flag.Bool("summary", false, "write a human readable summary instead of JSON")
+	exitCode := flag.Bool("exit_code", false, "exit with status 1 if the module graphs differ")
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	oldModules, err := readModuleGraphFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	newModules, err := readModuleGraphFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	delta := computeDelta(oldModules, newModules)
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		defer out.Close()
+	}
+
+	if *summary {
+		writeSummary(out, delta)
+	} else {
+		data, err := json.MarshalIndent(delta, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		out.Write(append(data, '\n'))
+	}
+
+	if *exitCode && !delta.Empty() {
+		out.Close()
+		os.Exit(1)
+	}
+}
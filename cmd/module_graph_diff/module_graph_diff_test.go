@@ -0,0 +1,158 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const oldGraph = `[
+  {
+    "Name": "libfoo",
+    "Variant": "android_arm64_armv8-a_shared",
+    "Type": "cc_library",
+    "Blueprint": "foo/Android.bp",
+    "Deps": [
+      {"Name": "libbar", "Variant": "android_arm64_armv8-a_shared", "Tag": "cc.libraryDependencyTag"},
+      {"Name": "libbaz", "Variant": "android_arm64_armv8-a_shared", "Tag": "cc.libraryDependencyTag"}
+    ],
+    "Module": {"Android": {"SetProperties": [
+      {"Name": "Name", "Value": "libfoo"},
+      {"Name": "Srcs", "Values": ["foo.cpp"]},
+      {"Name": "Cflags", "Values": ["-Wall"]}
+    ]}}
+  },
+  {
+    "Name": "libbar",
+    "Variant": "android_arm64_armv8-a_shared",
+    "Type": "cc_library",
+    "Blueprint": "bar/Android.bp"
+  },
+  {
+    "Name": "libbaz",
+    "Variant": "android_arm64_armv8-a_shared",
+    "Type": "cc_library",
+    "Blueprint": "baz/Android.bp"
+  }
+]`
+
+const newGraph = `[
+  {
+    "Name": "libfoo",
+    "Variations": [{"Mutator": "os", "Variation": "android"}, {"Mutator": "arch", "Variation": "arm64_armv8-a"}, {"Mutator": "link", "Variation": "shared"}],
+    "Type": "cc_library",
+    "Blueprint": "foo/Android.bp",
+    "Deps": [
+      {"Name": "libbar", "Variant": "android_arm64_armv8-a_shared", "Tag": "cc.libraryDependencyTag"},
+      {"Name": "libqux", "Variant": "android_arm64_armv8-a_shared", "Tag": "cc.libraryDependencyTag"}
+    ],
+    "Module": {"Android": {"SetProperties": [
+      {"Name": "Name", "Value": "libfoo"},
+      {"Name": "Srcs", "Values": ["foo.cpp", "foo2.cpp"]},
+      {"Name": "Shared_libs", "Values": ["libbar", "libqux"]}
+    ]}}
+  },
+  {
+    "Name": "libbar",
+    "Variant": "android_arm64_armv8-a_shared",
+    "Type": "cc_library",
+    "Blueprint": "bar/Android.bp"
+  },
+  {
+    "Name": "libqux",
+    "Variant": "android_arm64_armv8-a_shared",
+    "Type": "cc_library",
+    "Blueprint": "qux/Android.bp"
+  }
+]`
+
+func stringPtr(s string) *string { return &s }
+
+func TestComputeDelta(t *testing.T) {
+	oldModules, err := readModuleGraph(strings.NewReader(oldGraph))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newModules, err := readModuleGraph(strings.NewReader(newGraph))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	variant := "android_arm64_armv8-a_shared"
+	libDepTag := "cc.libraryDependencyTag"
+	expected := &Delta{
+		AddedModules: []Module{
+			{ModuleVariant{"libqux", variant}, "cc_library", "qux/Android.bp"},
+		},
+		RemovedModules: []Module{
+			{ModuleVariant{"libbaz", variant}, "cc_library", "baz/Android.bp"},
+		},
+		ChangedModules: []ModuleChange{
+			{
+				ModuleVariant: ModuleVariant{"libfoo", variant},
+				Type:          "cc_library",
+				AddedDeps:     []Dependency{{ModuleVariant{"libqux", variant}, libDepTag}},
+				RemovedDeps:   []Dependency{{ModuleVariant{"libbaz", variant}, libDepTag}},
+				ChangedProperties: []PropertyChange{
+					{Name: "Cflags", OldValue: stringPtr(`"-Wall"`)},
+					{Name: "Shared_libs", NewValue: stringPtr(`"libbar", "libqux"`)},
+					{Name: "Srcs", OldValue: stringPtr(`"foo.cpp"`), NewValue: stringPtr(`"foo.cpp", "foo2.cpp"`)},
+				},
+			},
+		},
+	}
+	if delta := computeDelta(oldModules, newModules); !reflect.DeepEqual(delta, expected) {
+		t.Errorf("expected %+v, got %+v", expected, delta)
+	}
+
+	if delta := computeDelta(oldModules, oldModules); !delta.Empty() {
+		t.Errorf("expected no delta between a module graph and itself, got %+v", delta)
+	}
+}
+
+func TestReadModuleGraphDuplicateVariant(t *testing.T) {
+	graph := `[{"Name": "foo", "Variant": "a"}, {"Name": "foo", "Variant": "a"}]`
+	if _, err := readModuleGraph(strings.NewReader(graph)); err == nil {
+		t.Errorf("expected an error for duplicate module variants")
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	delta := &Delta{
+		AddedModules: []Module{{ModuleVariant{"foo", "android_common"}, "java_library", "foo/Android.bp"}},
+		ChangedModules: []ModuleChange{{
+			ModuleVariant:     ModuleVariant{"bar", ""},
+			OldType:           "filegroup",
+			Type:              "genrule",
+			AddedDeps:         []Dependency{{ModuleVariant: ModuleVariant{"baz", ""}}},
+			ChangedProperties: []PropertyChange{{Name: "Out", NewValue: stringPtr(`"bar.txt"`)}},
+		}},
+	}
+	expected := `1 module variants added, 0 removed, 1 changed
++ java_library foo (android_common)
+~ genrule bar
+    type: filegroup -> genrule
+    + dep baz
+    Out: (unset) -> "bar.txt"
+`
+	buf := &bytes.Buffer{}
+	writeSummary(buf, delta)
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -248,7 +248,7 @@ func main() {
 	stdio := terminal.StdioImpl{}
 
 	output := terminal.NewStatusOutput(stdio.Stdout(), "", false, false,
-		forceAnsiOutput())
+		forceAnsiOutput(), false)
 	log := logger.New(output)
 	defer log.Cleanup()
 
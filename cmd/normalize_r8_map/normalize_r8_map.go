@@ -0,0 +1,233 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// normalize_r8_map rewrites an R8 mapping file in a canonical order, so that two runs of R8 that
+// write the same mappings in a different order give the same file. The classes are sorted by their
+// original name, and the members of each class by their obfuscated name, minified line range and
+// original signature. The lines of a member that belong together, i.e. the frames of an inlined
+// call, which share their minified line range, and the metadata comments that follow a line, are
+// kept together and in order.
+//
+// The line numbers themselves are kept as they are, they are the line numbers of the dex code and
+// retracing depends on them.
+//
+// If the mapping file has a pg_map_hash header, it is updated to the hash of the normalized
+// mappings. A mapping file whose hash doesn't match its mappings is written unchanged, as its hash
+// can't be kept consistent. The pg_map_id, which is also written into the dex code, is unchanged.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const mapHashPrefix = "# pg_map_hash: SHA-256 "
+
+// mapUnit is a group of lines of the mapping file that is moved as a whole: a class line or the
+// lines of a member, with the comments that follow them.
+type mapUnit struct {
+	lines []string
+
+	// The obfuscated name and the start of the minified line range of a member, or -1 if it has
+	// none.
+	obfuscatedName string
+	minifiedStart  int
+	minifiedRange  string
+}
+
+type mapClass struct {
+	mapUnit
+	members []*mapUnit
+}
+
+// memberRange returns the minified line range of a member line, e.g. "1:3" for
+// "    1:3:void foo():10:12 -> a", the start of the range, or -1, and its obfuscated name.
+func memberRange(line string) (string, int, string) {
+	line = strings.TrimSpace(line)
+	name := ""
+	if i := strings.LastIndex(line, " -> "); i >= 0 {
+		name = line[i+len(" -> "):]
+		line = line[:i]
+	}
+	fields := strings.SplitN(line, ":", 3)
+	if len(fields) == 3 {
+		start, err1 := strconv.Atoi(fields[0])
+		_, err2 := strconv.Atoi(fields[1])
+		if err1 == nil && err2 == nil {
+			return fields[0] + ":" + fields[1], start, name
+		}
+	}
+	return "", -1, name
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+// headerLen returns the number of lines of the header of a mapping file, its leading comments.
+func headerLen(lines []string) int {
+	i := 0
+	for i < len(lines) && isComment(lines[i]) {
+		i++
+	}
+	return i
+}
+
+// parseMap splits the lines of a mapping file into its header and its classes. Lines before the
+// first class are kept with the header.
+func parseMap(lines []string) ([]string, []*mapClass) {
+	i := headerLen(lines)
+	header := append([]string(nil), lines[:i]...)
+
+	var classes []*mapClass
+	var last *mapUnit
+	for _, line := range lines[i:] {
+		switch {
+		case isComment(line) || line == "":
+			if last != nil {
+				last.lines = append(last.lines, line)
+			} else {
+				header = append(header, line)
+			}
+		case line[0] == ' ' || line[0] == '\t':
+			if len(classes) == 0 {
+				// A member without a class, keep it with the header.
+				header = append(header, line)
+				continue
+			}
+			class := classes[len(classes)-1]
+			rng, start, name := memberRange(line)
+			if last != &class.mapUnit && rng != "" && last.minifiedRange == rng && last.obfuscatedName == name {
+				// Another frame of the same inlined call.
+				last.lines = append(last.lines, line)
+				continue
+			}
+			member := &mapUnit{lines: []string{line}, obfuscatedName: name, minifiedStart: start, minifiedRange: rng}
+			class.members = append(class.members, member)
+			last = member
+		default:
+			class := &mapClass{mapUnit: mapUnit{lines: []string{line}}}
+			classes = append(classes, class)
+			last = &class.mapUnit
+		}
+	}
+	return header, classes
+}
+
+// normalizeMappings returns the classes of a mapping file in canonical order.
+func normalizeMappings(classes []*mapClass) []string {
+	sort.SliceStable(classes, func(i, j int) bool {
+		return classes[i].lines[0] < classes[j].lines[0]
+	})
+	var lines []string
+	for _, class := range classes {
+		members := class.members
+		sort.SliceStable(members, func(i, j int) bool {
+			a, b := members[i], members[j]
+			if a.obfuscatedName != b.obfuscatedName {
+				return a.obfuscatedName < b.obfuscatedName
+			}
+			if a.minifiedStart != b.minifiedStart {
+				return a.minifiedStart < b.minifiedStart
+			}
+			return strings.Join(a.lines, "\n") < strings.Join(b.lines, "\n")
+		})
+		lines = append(lines, class.lines...)
+		for _, member := range members {
+			lines = append(lines, member.lines...)
+		}
+	}
+	return lines
+}
+
+func mapHash(mappings []string) string {
+	h := sha256.New()
+	for _, line := range mappings {
+		h.Write([]byte(line))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeMap returns the normalized contents of a mapping file.
+func normalizeMap(data []byte) []byte {
+	if len(data) == 0 {
+		return data
+	}
+	trailingNewline := bytes.HasSuffix(data, []byte("\n"))
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	n := headerLen(lines)
+
+	hashLine := -1
+	for i, line := range lines[:n] {
+		if strings.HasPrefix(line, mapHashPrefix) {
+			hashLine = i
+		}
+	}
+	if hashLine >= 0 {
+		// The hash covers the lines after the header.
+		if !trailingNewline || mapHash(lines[n:]) != strings.TrimPrefix(lines[hashLine], mapHashPrefix) {
+			return data
+		}
+	}
+
+	header, classes := parseMap(lines)
+	mappings := normalizeMappings(classes)
+	if hashLine >= 0 {
+		// The lines before the first class are part of the hash too.
+		header[hashLine] = mapHashPrefix + mapHash(append(append([]string(nil), header[n:]...), mappings...))
+	}
+
+	var buf bytes.Buffer
+	for _, line := range append(header, mappings...) {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	if !trailingNewline {
+		buf.Truncate(buf.Len() - 1)
+	}
+	return buf.Bytes()
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -i <mapping file> -o <normalized mapping file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	input := flag.String("i", "", "R8 mapping file to read")
+	output := flag.String("o", "", "file to write the normalized mapping file to")
+	flag.Parse()
+
+	if *input == "" || *output == "" || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, normalizeMap(data), 0666); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
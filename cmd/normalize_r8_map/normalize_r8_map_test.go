@@ -0,0 +1,86 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func mapFile(header []string, mappings ...string) string {
+	var lines []string
+	for _, line := range header {
+		if line == mapHashPrefix {
+			line += mapHash(mappings)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(append(lines, mappings...), "\n") + "\n"
+}
+
+func TestNormalizeMap(t *testing.T) {
+	header := []string{
+		"# compiler: R8",
+		"# pg_map_id: 1234567",
+		mapHashPrefix,
+	}
+	// The classes and the members of b.Foo in the order of another run of R8. The frames of the
+	// inlined call at 3:4 and the comments stay together.
+	input := mapFile(header,
+		"b.Foo -> b:",
+		`# {"id":"sourceFile","fileName":"Foo.java"}`,
+		"    3:4:void inlined():20:21 -> a",
+		"    3:4:void caller():10 -> a",
+		"    1:2:void other():5:6 -> a",
+		`      # {"id":"com.android.tools.r8.synthesized"}`,
+		"    int field -> c",
+		"a.Bar -> a:",
+		"    void bar() -> a",
+	)
+	expected := mapFile(header,
+		"a.Bar -> a:",
+		"    void bar() -> a",
+		"b.Foo -> b:",
+		`# {"id":"sourceFile","fileName":"Foo.java"}`,
+		"    1:2:void other():5:6 -> a",
+		`      # {"id":"com.android.tools.r8.synthesized"}`,
+		"    3:4:void inlined():20:21 -> a",
+		"    3:4:void caller():10 -> a",
+		"    int field -> c",
+	)
+
+	if got := string(normalizeMap([]byte(input))); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+	if got := string(normalizeMap([]byte(expected))); got != expected {
+		t.Errorf("expected a normalized map to be unchanged, got:\n%s", got)
+	}
+}
+
+func TestNormalizeMapWithoutHash(t *testing.T) {
+	input := "b.Foo -> b:\na.Bar -> a:\n"
+	expected := "a.Bar -> a:\nb.Foo -> b:\n"
+	if got := string(normalizeMap([]byte(input))); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestNormalizeMapWithMismatchedHash(t *testing.T) {
+	// A hash that doesn't match the mappings can't be updated, so the map is left as is.
+	input := mapHashPrefix + "0000\nb.Foo -> b:\na.Bar -> a:\n"
+	if got := string(normalizeMap([]byte(input))); got != input {
+		t.Errorf("expected the map to be unchanged, got:\n%s", got)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"android/soong/cmd/sbox/sbox_proto"
@@ -45,6 +46,9 @@ var (
 	writeIfChanged bool
 )
 
+// rename is os.Rename, replaced in tests to simulate moving files between filesystems.
+var rename = os.Rename
+
 const (
 	depFilePlaceholder    = "__SBOX_DEPFILE__"
 	sandboxDirPlaceholder = "__SBOX_SANDBOX_DIR__"
@@ -478,6 +482,13 @@ func copyFiles(copies []*sbox_proto.Copy, fromDir, toDir string, exists existsTy
 // if it is the same, avoiding updating the timestamp.
 func copyOneFile(from string, to string, forceExecutable bool, exists existsType,
 	write writeType) error {
+	// An absolute path that doesn't match a path mapping, for example an input in an OUT_DIR
+	// outside of the source tree that isn't in the Soong output directory, is used in place.
+	// Copying it onto itself would replace it and update its timestamp.
+	if filepath.Clean(from) == filepath.Clean(to) {
+		return nil
+	}
+
 	err := os.MkdirAll(filepath.Dir(to), 0777)
 	if err != nil {
 		return err
@@ -618,11 +629,12 @@ func applyPathMappings(pathMappings []*sbox_proto.PathMapping, path string) stri
 	return path
 }
 
-// moveFiles moves files specified by a set of copy rules.  It uses os.Rename, so it is restricted
-// to moving files where the source and destination are in the same filesystem.  This is OK for
-// sbox because the temporary directory is inside the out directory.  If write is onlyWriteIfChanged
-// then the output file is compared to the input file and not written to if it is the same, avoiding
-// updating the timestamp.  Otherwise it always updates the timestamp of the new file.
+// moveFiles moves files specified by a set of copy rules.  It uses os.Rename, and falls back to
+// copying the file if the source and destination are on different filesystems, which can happen
+// when part of the out directory is a mount point or a symlink to another filesystem.  If write is
+// onlyWriteIfChanged then the output file is compared to the input file and not written to if it is
+// the same, avoiding updating the timestamp.  Otherwise it always updates the timestamp of the new
+// file.
 func moveFiles(copies []*sbox_proto.Copy, fromDir, toDir string, write writeType) error {
 	for _, copyPair := range copies {
 		fromPath := joinPath(fromDir, copyPair.GetFrom())
@@ -636,7 +648,13 @@ func moveFiles(copies []*sbox_proto.Copy, fromDir, toDir string, write writeType
 			continue
 		}
 
-		err = os.Rename(fromPath, toPath)
+		err = rename(fromPath, toPath)
+		if errors.Is(err, syscall.EXDEV) {
+			err = copyOneFile(fromPath, toPath, false, requireFromExists, alwaysWrite)
+			if err == nil {
+				err = os.Remove(fromPath)
+			}
+		}
 		if err != nil {
 			return err
 		}
@@ -18,8 +18,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"syscall"
 	"testing"
+
+	"android/soong/cmd/sbox/sbox_proto"
+	"android/soong/response"
+
+	"google.golang.org/protobuf/proto"
 )
 
 func Test_filesHaveSameContents(t *testing.T) {
@@ -125,3 +132,120 @@ func Test_filesHaveSameContents(t *testing.T) {
 		})
 	}
 }
+
+func Test_moveFilesAcrossFilesystems(t *testing.T) {
+	tempDir := t.TempDir()
+	sandboxDir := filepath.Join(tempDir, "sandbox")
+	outDir := filepath.Join(tempDir, "out")
+
+	if err := os.MkdirAll(sandboxDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(sandboxDir, "foo"), []byte("foo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(r func(string, string) error) { rename = r }(rename)
+	rename = func(from, to string) error {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EXDEV}
+	}
+
+	copies := []*sbox_proto.Copy{
+		{
+			From: proto.String("foo"),
+			To:   proto.String(filepath.Join(outDir, "foo")),
+		},
+	}
+	if err := moveFiles(copies, sandboxDir, "", alwaysWrite); err != nil {
+		t.Fatalf("moveFiles failed: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(outDir, "foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "foo" {
+		t.Errorf("expected contents %q, got %q", "foo", string(data))
+	}
+	if info, err := os.Stat(filepath.Join(outDir, "foo")); err != nil {
+		t.Fatal(err)
+	} else if info.Mode()&0100 == 0 {
+		t.Errorf("expected the executable bit to be preserved, got %s", info.Mode())
+	}
+	if _, err := os.Stat(filepath.Join(sandboxDir, "foo")); !os.IsNotExist(err) {
+		t.Errorf("expected the sandbox file to be removed, got %v", err)
+	}
+}
+
+func Test_copyOneRspFileAbsoluteOutDir(t *testing.T) {
+	tempDir := t.TempDir()
+	// An OUT_DIR outside of the source tree, as an absolute path.
+	outDir := filepath.Join(tempDir, "scratch", "out")
+	soongOutDir := filepath.Join(outDir, "soong")
+	sandboxDir := filepath.Join(tempDir, "sandbox")
+
+	inSoongOutDir := filepath.Join(soongOutDir, ".intermediates", "foo", "foo.jar")
+	inOutDir := filepath.Join(outDir, "target", "common", "bar.jar")
+	rspFile := filepath.Join(soongOutDir, ".intermediates", "foo", "foo.rsp")
+	for _, file := range []string{inSoongOutDir, inOutDir} {
+		if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(filepath.Base(file)), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Create(rspFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := response.WriteRspFile(f, []string{inSoongOutDir, inOutDir}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	before, err := os.Stat(inOutDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp := &sbox_proto.RspFile{
+		File: proto.String(rspFile),
+		PathMappings: []*sbox_proto.PathMapping{
+			{
+				From: proto.String(soongOutDir),
+				To:   proto.String("out"),
+			},
+		},
+	}
+	if err := copyOneRspFile(rsp, sandboxDir, "."); err != nil {
+		t.Fatalf("copyOneRspFile failed: %s", err)
+	}
+
+	if data, err := ioutil.ReadFile(filepath.Join(sandboxDir, "out", ".intermediates", "foo", "foo.jar")); err != nil {
+		t.Errorf("expected foo.jar to be copied into the sandbox: %s", err)
+	} else if string(data) != "foo.jar" {
+		t.Errorf("expected contents %q, got %q", "foo.jar", string(data))
+	}
+
+	// The input that isn't mapped is used in place, and isn't rewritten.
+	if after, err := os.Stat(inOutDir); err != nil {
+		t.Fatal(err)
+	} else if !os.SameFile(before, after) || !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("expected %s to be unmodified", inOutDir)
+	}
+
+	in, err := os.Open(filepath.Join(sandboxDir, "out", ".intermediates", "foo", "foo.rsp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	files, err := response.ReadRspFile(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"out/.intermediates/foo/foo.jar", inOutDir}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected rsp file contents %q, got %q", expected, files)
+	}
+}
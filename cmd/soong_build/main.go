@@ -33,6 +33,7 @@ import (
 	"github.com/google/blueprint/bootstrap"
 	"github.com/google/blueprint/deptools"
 	"github.com/google/blueprint/metrics"
+	"github.com/google/blueprint/pathtools"
 	androidProtobuf "google.golang.org/protobuf/android"
 )
 
@@ -46,7 +47,13 @@ var (
 	delveListen string
 	delvePath   string
 
+	symlinkForestJobs int
+
 	cmdlineArgs android.CmdArgs
+
+	// bpIncludes expands the fragments that the Android.bp files include, and records them as
+	// dependencies of soong_build.
+	bpIncludes = android.NewBpIncludeFs(pathtools.OsFs)
 )
 
 func init() {
@@ -67,14 +74,33 @@ func init() {
 	flag.StringVar(&cmdlineArgs.TraceFile, "trace", "", "write trace to file")
 	flag.StringVar(&cmdlineArgs.Memprofile, "memprofile", "", "write memory profile to file")
 	flag.BoolVar(&cmdlineArgs.NoGC, "nogc", false, "turn off GC for debugging")
+	flag.StringVar(&cmdlineArgs.LogModules, "log_modules", "", "comma-separated patterns of the modules whose debug logs to write to the module log")
 
 	// Flags representing various modes soong_build can run in
 	flag.StringVar(&cmdlineArgs.ModuleGraphFile, "module_graph_file", "", "JSON module graph file to output")
 	flag.StringVar(&cmdlineArgs.ModuleActionsFile, "module_actions_file", "", "JSON file to output inputs/outputs of actions of modules")
 	flag.StringVar(&cmdlineArgs.DocFile, "soong_docs", "", "build documentation file to output")
+	flag.StringVar(&cmdlineArgs.ImpactOf, "impact_of", "", "source file, relative to --top, whose affected modules and actions to output")
+	flag.StringVar(&cmdlineArgs.ImpactFile, "impact_file", "", "JSON file to output the impact of --impact_of to, or stdout if not set")
+	flag.StringVar(&cmdlineArgs.Explain, "explain", "", "output file, relative to --top, whose generating action to output")
+	flag.StringVar(&cmdlineArgs.ExplainFile, "explain_file", "", "file to output the action of --explain to, or stdout if not set")
+	flag.StringVar(&cmdlineArgs.Query, "query", "", "query of the module graph whose matching modules to output, e.g. 'deps(libfoo)'")
+	flag.StringVar(&cmdlineArgs.QueryFile, "query_file", "", "file to output the modules matching --query to, or stdout if not set")
+	flag.BoolVar(&cmdlineArgs.ListModules, "list_modules", false, "output the names, types and Android.bp files of the modules without generating their build actions")
+	flag.StringVar(&cmdlineArgs.ListModulesFilter, "list_modules_filter", "", "comma-separated filter of the modules of --list_modules, e.g. type=cc_library,path=external,variant=^android_")
+	flag.StringVar(&cmdlineArgs.ListModulesFile, "list_modules_file", "", "file to output the modules of --list_modules to, or stdout if not set")
+	flag.StringVar(&cmdlineArgs.ServeDocs, "serve_docs", "", "port or host:port to serve the documentation of the module types and an index of the modules on")
+	flag.StringVar(&cmdlineArgs.MutatorPipelineFile, "mutator_pipeline_file", "", "JSON file to output the mutator pipeline and the variants and dependencies created by each mutator to")
+	flag.StringVar(&cmdlineArgs.ConfigDumpFile, "dump_config", "", "JSON file to output the fully resolved configuration of the product to")
+	flag.StringVar(&cmdlineArgs.ModuleDepsFile, "module_deps_file", "", "JSON file to output the direct dependents of each module variant to")
+	flag.StringVar(&cmdlineArgs.DependencyCyclesFile, "dependency_cycles_file", "", "file to touch if the modules have no dependency cycles, which are explained otherwise")
+	flag.StringVar(&cmdlineArgs.NinjaWeightFile, "ninja_weight_file", "", "ninja weight list file to output the estimated duration of every action to, relative to --top")
 	flag.StringVar(&cmdlineArgs.BazelQueryViewDir, "bazel_queryview_dir", "", "path to the bazel queryview directory relative to --top")
+	flag.StringVar(&cmdlineArgs.BazelQueryViewFilter, "bazel_queryview_filter", "", "comma-separated filter of the variants in the bazel queryview, e.g. product or arch=arm64")
 	flag.StringVar(&cmdlineArgs.BazelApiBp2buildDir, "bazel_api_bp2build_dir", "", "path to the bazel api_bp2build directory relative to --top")
 	flag.StringVar(&cmdlineArgs.Bp2buildMarker, "bp2build_marker", "", "If set, run bp2build, touch the specified marker file then exit")
+	flag.BoolVar(&cmdlineArgs.Bp2buildDiff, "bp2build_diff", false, "run bp2build and print a diff of its BUILD files against the previously generated ones instead of writing them")
+	flag.IntVar(&symlinkForestJobs, "symlink_forest_jobs", 0, "number of directories of the bp2build symlink forest to plant concurrently, or a multiple of the number of CPUs if not set")
 	flag.StringVar(&cmdlineArgs.SymlinkForestMarker, "symlink_forest_marker", "", "If set, create the bp2build symlink forest, touch the specified marker file, then exit")
 	flag.StringVar(&cmdlineArgs.OutFile, "o", "build.ninja", "the Ninja file to output")
 	flag.StringVar(&cmdlineArgs.BazelForceEnabledModules, "bazel-force-enabled-modules", "", "additional modules to build with Bazel. Comma-delimited")
@@ -84,6 +110,7 @@ func init() {
 	flag.BoolVar(&cmdlineArgs.BazelModeStaging, "bazel-mode-staging", false, "use bazel for analysis of certain near-ready modules")
 	flag.BoolVar(&cmdlineArgs.BazelModeDev, "bazel-mode-dev", false, "use bazel for analysis of a large number of modules (less stable)")
 	flag.BoolVar(&cmdlineArgs.UseBazelProxy, "use-bazel-proxy", false, "communicate with bazel using unix socket proxy instead of spawning subprocesses")
+	flag.BoolVar(&cmdlineArgs.ForceBazelRequery, "force_bazel_requery", false, "run the bazel queries of mixed builds even if their outputs are cached")
 	flag.BoolVar(&cmdlineArgs.BuildFromTextStub, "build-from-text-stub", false, "build Java stubs from API text files instead of source files")
 
 	// Flags that probably shouldn't be flags of soong_build, but we haven't found
@@ -103,6 +130,8 @@ func newNameResolver(config android.Config) *android.NameResolver {
 
 func newContext(configuration android.Config) *android.Context {
 	ctx := android.NewContext(configuration)
+	// The globs of the analysis go through the checkpoint of interrupted runs.
+	ctx.SetFs(android.NewAnalysisGlobFs(configuration, bpIncludes))
 	ctx.SetNameInterface(newNameResolver(configuration))
 	ctx.SetAllowMissingDependencies(configuration.AllowMissingDependencies())
 	ctx.AddIncludeTags(configuration.IncludeTags()...)
@@ -118,11 +147,18 @@ func runMixedModeBuild(ctx *android.Context, extraNinjaDeps []string) string {
 	defer ctx.EventHandler.End("mixed_build")
 
 	bazelHook := func() error {
-		return ctx.Config().BazelContext.InvokeBazel(ctx.Config(), ctx)
+		err := ctx.Config().BazelContext.InvokeBazel(ctx.Config(), ctx)
+		// The results of the cquery are in the checkpoint, stop here if soong_build was interrupted
+		// while Bazel ran.
+		android.CheckAnalysisInterrupt(ctx.Config())
+		return err
 	}
 	ctx.SetBeforePrepareBuildActionsHook(bazelHook)
-	ninjaDeps := bootstrap.RunBlueprint(cmdlineArgs.Args, bootstrap.DoEverything, ctx.Context, ctx.Config())
+	ninjaDeps := bootstrap.RunBlueprint(cmdlineArgs.Args, ninjaFileStopBefore(ctx), ctx.Context, ctx.Config())
+	android.CheckAnalysisInterrupt(ctx.Config())
 	ninjaDeps = append(ninjaDeps, extraNinjaDeps...)
+	ninjaDeps = append(ninjaDeps, bpIncludes.Fragments()...)
+	ninjaDeps = append(ninjaDeps, maybeWriteShardedNinjaFile(ctx)...)
 
 	bazelPaths, err := readFileLines(ctx.Config().Getenv("BAZEL_DEPS_FILE"))
 	if err != nil {
@@ -140,7 +176,10 @@ func runQueryView(queryviewDir, queryviewMarker string, ctx *android.Context) {
 	ctx.EventHandler.Begin("queryview")
 	defer ctx.EventHandler.End("queryview")
 	codegenContext := bp2build.NewCodegenContext(ctx.Config(), ctx, bp2build.QueryView, topDir)
-	err := createBazelWorkspace(codegenContext, shared.JoinPath(topDir, queryviewDir), false)
+	filter, err := bp2build.ParseQueryViewFilter(cmdlineArgs.BazelQueryViewFilter)
+	maybeQuit(err, "")
+	codegenContext.SetQueryViewFilter(filter)
+	err = createBazelWorkspace(codegenContext, shared.JoinPath(topDir, queryviewDir), false)
 	maybeQuit(err, "")
 	touch(shared.JoinPath(topDir, queryviewMarker))
 }
@@ -220,6 +259,8 @@ func runApiBp2build(ctx *android.Context, extraNinjaDeps []string) string {
 	// Create the symlink forest
 	symlinkDeps, _, _ := bp2build.PlantSymlinkForest(
 		ctx.Config().IsEnvTrue("BP2BUILD_VERBOSE"),
+		symlinkForestMode(ctx),
+		symlinkForestJobs,
 		topDir,
 		workspace,
 		cmdlineArgs.BazelApiBp2buildDir,
@@ -286,6 +327,12 @@ func writeMetrics(configuration android.Config, eventHandler *metrics.EventHandl
 	metricsFile := filepath.Join(metricsDir, "soong_build_metrics.pb")
 	err := android.WriteMetrics(configuration, eventHandler, metricsFile)
 	maybeQuit(err, "error writing soong_build metrics %s", metricsFile)
+
+	hint, err := android.AppendMetricsHistory(configuration, eventHandler)
+	maybeQuit(err, "error writing soong_build metrics history")
+	if hint != "" {
+		fmt.Fprintln(os.Stderr, hint)
+	}
 }
 
 func writeJsonModuleGraphAndActions(ctx *android.Context, cmdArgs android.CmdArgs) {
@@ -298,6 +345,179 @@ func writeJsonModuleGraphAndActions(ctx *android.Context, cmdArgs android.CmdArg
 	ctx.Context.PrintJSONGraphAndActions(graphFile, actionsFile)
 }
 
+// writeImpact writes the modules and actions that are affected by a change to the --impact_of
+// file.
+func writeImpact(ctx *android.Context, cmdArgs android.CmdArgs) {
+	path := cmdArgs.ImpactOf
+	if filepath.IsAbs(path) {
+		absTop, err := filepath.Abs(topDir)
+		maybeQuit(err, "")
+		path, err = filepath.Rel(absTop, path)
+		maybeQuit(err, "--impact_of %q is not in the source tree", cmdArgs.ImpactOf)
+	}
+	impact := android.ImpactOf(ctx, path)
+
+	out := os.Stdout
+	if cmdArgs.ImpactFile != "" {
+		f, err := os.Create(shared.JoinPath(topDir, cmdArgs.ImpactFile))
+		maybeQuit(err, "error creating impact file %s", cmdArgs.ImpactFile)
+		defer f.Close()
+		out = f
+	}
+	err := android.WriteImpact(out, impact)
+	maybeQuit(err, "error writing impact of %s", cmdArgs.ImpactOf)
+}
+
+// writeExplanation writes the action that generates the --explain file.
+func writeExplanation(ctx *android.Context, cmdArgs android.CmdArgs) {
+	path := cmdArgs.Explain
+	if filepath.IsAbs(path) {
+		absTop, err := filepath.Abs(topDir)
+		maybeQuit(err, "")
+		path, err = filepath.Rel(absTop, path)
+		maybeQuit(err, "--explain %q is not in the source tree", cmdArgs.Explain)
+	}
+	explanation, err := android.Explain(ctx, path)
+	maybeQuit(err, "error explaining %s", cmdArgs.Explain)
+
+	out := os.Stdout
+	if cmdArgs.ExplainFile != "" {
+		f, err := os.Create(shared.JoinPath(topDir, cmdArgs.ExplainFile))
+		maybeQuit(err, "error creating explain file %s", cmdArgs.ExplainFile)
+		defer f.Close()
+		out = f
+	}
+	err = android.WriteExplanation(out, explanation)
+	maybeQuit(err, "error writing the action of %s", cmdArgs.Explain)
+}
+
+// writeQueryResult writes the modules that match the --query.
+func writeQueryResult(ctx *android.Context, cmdArgs android.CmdArgs) {
+	modules, err := android.Query(ctx, cmdArgs.Query)
+	maybeQuit(err, "error evaluating query")
+
+	out := os.Stdout
+	if cmdArgs.QueryFile != "" {
+		f, err := os.Create(shared.JoinPath(topDir, cmdArgs.QueryFile))
+		maybeQuit(err, "error creating query file %s", cmdArgs.QueryFile)
+		defer f.Close()
+		out = f
+	}
+	err = android.WriteQueryResult(out, modules)
+	maybeQuit(err, "error writing the result of %s", cmdArgs.Query)
+}
+
+// listModules lists the modules that match the --list_modules_filter. It only parses the Android.bp
+// files, and only runs the mutators when the filter selects variants.
+func listModules(ctx *android.Context, extraNinjaDeps []string) string {
+	ctx.EventHandler.Begin("list_modules")
+	defer ctx.EventHandler.End("list_modules")
+
+	filter, err := android.ParseModuleListFilter(cmdlineArgs.ListModulesFilter)
+	maybeQuit(err, "")
+
+	ninjaDeps := parseBlueprintFiles(ctx)
+	if filter.NeedsVariants() {
+		deps, errs := ctx.ResolveDependencies(ctx.Config())
+		if len(errs) > 0 {
+			quitWithErrors(ctx.ExplainDependencyCycles(errs))
+		}
+		ninjaDeps = append(ninjaDeps, deps...)
+	}
+	writeModuleList(ctx, cmdlineArgs, filter)
+	if cmdlineArgs.ListModulesFile == "" {
+		return ""
+	}
+	ninjaDeps = append(ninjaDeps, extraNinjaDeps...)
+	ninjaDeps = append(ninjaDeps, bpIncludes.Fragments()...)
+	ninjaDeps = append(ninjaDeps, writeBuildGlobsNinjaFile(ctx)...)
+	writeDepFile(cmdlineArgs.ListModulesFile, ctx.EventHandler, ninjaDeps)
+	return cmdlineArgs.ListModulesFile
+}
+
+// writeModuleList writes the modules that match the filter.
+func writeModuleList(ctx *android.Context, cmdArgs android.CmdArgs, filter *android.ModuleListFilter) {
+	modules := android.ListModules(ctx, filter)
+
+	out := os.Stdout
+	if cmdArgs.ListModulesFile != "" {
+		f, err := os.Create(shared.JoinPath(topDir, cmdArgs.ListModulesFile))
+		maybeQuit(err, "error creating module list %s", cmdArgs.ListModulesFile)
+		defer f.Close()
+		out = f
+	}
+	err = android.WriteModuleList(out, modules)
+	maybeQuit(err, "error writing the module list")
+}
+
+// writeMutatorPipeline writes the mutators in the order they ran, with the variants and
+// dependencies created by each of them.
+func writeMutatorPipeline(ctx *android.Context, cmdArgs android.CmdArgs) {
+	f, err := os.Create(shared.JoinPath(topDir, cmdArgs.MutatorPipelineFile))
+	maybeQuit(err, "error creating mutator pipeline file %s", cmdArgs.MutatorPipelineFile)
+	defer f.Close()
+	err = android.WriteMutatorPipeline(f, ctx.Config())
+	maybeQuit(err, "error writing mutator pipeline file %s", cmdArgs.MutatorPipelineFile)
+}
+
+// writeConfigDump writes the fully resolved configuration of the product.
+func writeConfigDump(ctx *android.Context, cmdArgs android.CmdArgs) {
+	f, err := os.Create(shared.JoinPath(topDir, cmdArgs.ConfigDumpFile))
+	maybeQuit(err, "error creating config dump %s", cmdArgs.ConfigDumpFile)
+	defer f.Close()
+	err = android.WriteConfigDump(f, ctx.Config())
+	maybeQuit(err, "error writing config dump %s", cmdArgs.ConfigDumpFile)
+}
+
+// writeModuleDeps writes the reverse dependencies of the modules.
+func writeModuleDeps(ctx *android.Context, cmdArgs android.CmdArgs) {
+	f, err := os.Create(shared.JoinPath(topDir, cmdArgs.ModuleDepsFile))
+	maybeQuit(err, "error creating module deps file %s", cmdArgs.ModuleDepsFile)
+	defer f.Close()
+	err = android.WriteModuleDeps(f, ctx)
+	maybeQuit(err, "error writing module deps file %s", cmdArgs.ModuleDepsFile)
+}
+
+// parseBlueprintFiles parses the Android.bp files like bootstrap.RunBlueprint, for the modes that
+// don't need all of its steps, and returns the files that were parsed.
+func parseBlueprintFiles(ctx *android.Context) []string {
+	ctx.EventHandler.Begin("parse_bp")
+	defer ctx.EventHandler.End("parse_bp")
+
+	ctx.SetModuleListFile(cmdlineArgs.ModuleListFile)
+	files, err := ctx.ListModulePaths(".")
+	maybeQuit(err, "could not enumerate files")
+	// The module types of bootstrap, e.g. bootstrap_go_package, are only registered by
+	// bootstrap.RunBlueprint.
+	ctx.SetIgnoreUnknownModuleTypes(true)
+	blueprintFiles, errs := ctx.ParseFileList(".", files, ctx.Config())
+	if len(errs) > 0 {
+		quitWithErrors(errs)
+	}
+	return append([]string{cmdlineArgs.ModuleListFile}, blueprintFiles...)
+}
+
+// explainDependencyCycles resolves the dependencies of the modules and explains the dependency
+// cycles between them in the errors. bootstrap.RunBlueprint prints blueprint's errors and exits, so
+// soong_ui runs this mode when the main soong_build invocation fails with a dependency cycle.
+func explainDependencyCycles(ctx *android.Context, extraNinjaDeps []string) string {
+	ctx.EventHandler.Begin("explain_dependency_cycles")
+	defer ctx.EventHandler.End("explain_dependency_cycles")
+
+	ninjaDeps := parseBlueprintFiles(ctx)
+	deps, errs := ctx.ResolveDependencies(ctx.Config())
+	if len(errs) > 0 {
+		quitWithErrors(ctx.ExplainDependencyCycles(errs))
+	}
+	ninjaDeps = append(ninjaDeps, deps...)
+	ninjaDeps = append(ninjaDeps, extraNinjaDeps...)
+	ninjaDeps = append(ninjaDeps, bpIncludes.Fragments()...)
+	ninjaDeps = append(ninjaDeps, writeBuildGlobsNinjaFile(ctx)...)
+
+	writeDepFile(cmdlineArgs.DependencyCyclesFile, ctx.EventHandler, ninjaDeps)
+	return cmdlineArgs.DependencyCyclesFile
+}
+
 func writeBuildGlobsNinjaFile(ctx *android.Context) []string {
 	ctx.EventHandler.Begin("globs_ninja_file")
 	defer ctx.EventHandler.End("globs_ninja_file")
@@ -327,16 +547,19 @@ func runSoongOnlyBuild(ctx *android.Context, extraNinjaDeps []string) string {
 
 	var stopBefore bootstrap.StopBefore
 	switch ctx.Config().BuildMode {
-	case android.GenerateModuleGraph:
+	case android.GenerateModuleGraph, android.GenerateImpact, android.GenerateExplain, android.GenerateConfigDump:
 		stopBefore = bootstrap.StopBeforeWriteNinja
-	case android.GenerateQueryView, android.GenerateDocFile:
+	case android.GenerateQueryView, android.GenerateDocFile, android.GenerateMutatorPipeline, android.GenerateModuleDeps,
+		android.GenerateQuery, android.ServeDocs:
 		stopBefore = bootstrap.StopBeforePrepareBuildActions
 	default:
-		stopBefore = bootstrap.DoEverything
+		stopBefore = ninjaFileStopBefore(ctx)
 	}
 
 	ninjaDeps := bootstrap.RunBlueprint(cmdlineArgs.Args, stopBefore, ctx.Context, ctx.Config())
+	android.CheckAnalysisInterrupt(ctx.Config())
 	ninjaDeps = append(ninjaDeps, extraNinjaDeps...)
+	ninjaDeps = append(ninjaDeps, bpIncludes.Fragments()...)
 
 	globListFiles := writeBuildGlobsNinjaFile(ctx)
 	ninjaDeps = append(ninjaDeps, globListFiles...)
@@ -352,6 +575,39 @@ func runSoongOnlyBuild(ctx *android.Context, extraNinjaDeps []string) string {
 		writeJsonModuleGraphAndActions(ctx, cmdlineArgs)
 		writeDepFile(cmdlineArgs.ModuleGraphFile, ctx.EventHandler, ninjaDeps)
 		return cmdlineArgs.ModuleGraphFile
+	case android.GenerateImpact:
+		writeImpact(ctx, cmdlineArgs)
+		if cmdlineArgs.ImpactFile == "" {
+			return ""
+		}
+		writeDepFile(cmdlineArgs.ImpactFile, ctx.EventHandler, ninjaDeps)
+		return cmdlineArgs.ImpactFile
+	case android.GenerateExplain:
+		writeExplanation(ctx, cmdlineArgs)
+		if cmdlineArgs.ExplainFile == "" {
+			return ""
+		}
+		writeDepFile(cmdlineArgs.ExplainFile, ctx.EventHandler, ninjaDeps)
+		return cmdlineArgs.ExplainFile
+	case android.GenerateQuery:
+		writeQueryResult(ctx, cmdlineArgs)
+		if cmdlineArgs.QueryFile == "" {
+			return ""
+		}
+		writeDepFile(cmdlineArgs.QueryFile, ctx.EventHandler, ninjaDeps)
+		return cmdlineArgs.QueryFile
+	case android.GenerateMutatorPipeline:
+		writeMutatorPipeline(ctx, cmdlineArgs)
+		writeDepFile(cmdlineArgs.MutatorPipelineFile, ctx.EventHandler, ninjaDeps)
+		return cmdlineArgs.MutatorPipelineFile
+	case android.GenerateConfigDump:
+		writeConfigDump(ctx, cmdlineArgs)
+		writeDepFile(cmdlineArgs.ConfigDumpFile, ctx.EventHandler, ninjaDeps)
+		return cmdlineArgs.ConfigDumpFile
+	case android.GenerateModuleDeps:
+		writeModuleDeps(ctx, cmdlineArgs)
+		writeDepFile(cmdlineArgs.ModuleDepsFile, ctx.EventHandler, ninjaDeps)
+		return cmdlineArgs.ModuleDepsFile
 	case android.GenerateDocFile:
 		// TODO: we could make writeDocs() return the list of documentation files
 		// written and add them to the .d file. Then soong_docs would be re-run
@@ -360,14 +616,45 @@ func runSoongOnlyBuild(ctx *android.Context, extraNinjaDeps []string) string {
 		maybeQuit(err, "error building Soong documentation")
 		writeDepFile(cmdlineArgs.DocFile, ctx.EventHandler, ninjaDeps)
 		return cmdlineArgs.DocFile
+	case android.ServeDocs:
+		serveDocs(ctx, cmdlineArgs.ServeDocs, ninjaDeps)
+		return ""
 	default:
-		// The actual output (build.ninja) was written in the RunBlueprint() call
-		// above
+		// Unless it is sharded, the actual output (build.ninja) was written in the
+		// RunBlueprint() call above
+		ninjaDeps = append(ninjaDeps, maybeWriteShardedNinjaFile(ctx)...)
 		writeDepFile(cmdlineArgs.OutFile, ctx.EventHandler, ninjaDeps)
 		return cmdlineArgs.OutFile
 	}
 }
 
+// shardNinjaFile returns true if soong_build writes the ninja file in shards instead of
+// RunBlueprint.
+func shardNinjaFile(ctx *android.Context) bool {
+	return ctx.Config().NinjaShards() > 1 && !cmdlineArgs.EmptyNinjaFile
+}
+
+// ninjaFileStopBefore returns where RunBlueprint stops in the modes that write the ninja file.
+func ninjaFileStopBefore(ctx *android.Context) bootstrap.StopBefore {
+	if shardNinjaFile(ctx) {
+		return bootstrap.StopBeforeWriteNinja
+	}
+	return bootstrap.DoEverything
+}
+
+// maybeWriteShardedNinjaFile writes the ninja file in shards if SOONG_NINJA_SHARDS is set, and
+// returns the shards, which the ninja file depends on.
+func maybeWriteShardedNinjaFile(ctx *android.Context) []string {
+	if !shardNinjaFile(ctx) {
+		return nil
+	}
+	ctx.EventHandler.Begin("write_sharded_ninja")
+	defer ctx.EventHandler.End("write_sharded_ninja")
+	shardFiles, err := android.WriteShardedNinjaFile(ctx, topDir, cmdlineArgs.OutFile, ctx.Config().NinjaShards())
+	maybeQuit(err, "error writing the ninja file")
+	return shardFiles
+}
+
 // soong_ui dumps the available environment variables to
 // soong.environment.available . Then soong_build itself is run with an empty
 // environment so that the only way environment variables can be accessed is
@@ -415,6 +702,15 @@ func main() {
 	// change between every CI build, so tracking it would require re-running Soong for every build.
 	metricsDir := availableEnv["LOG_DIR"]
 
+	moduleLogDir := metricsDir
+	if moduleLogDir == "" {
+		moduleLogDir = configuration.SoongOutDir()
+	}
+	moduleLogFile := filepath.Join(moduleLogDir, "soong_build_module_log.jsonl")
+	err = android.OpenModuleLog(configuration, cmdlineArgs.LogModules, moduleLogFile)
+	maybeQuit(err, "error creating module log %s", moduleLogFile)
+
+	bpIncludes.SetCacheDir(shared.JoinPath(topDir, configuration.SoongOutDir(), "bp_cache"))
 	ctx := newContext(configuration)
 
 	var finalOutputFile string
@@ -428,11 +724,27 @@ func main() {
 		// Run the alternate pipeline of bp2build mutators and singleton to convert
 		// Blueprint to BUILD files before everything else.
 		finalOutputFile = runBp2Build(ctx, extraNinjaDeps, metricsDir)
+	case android.Bp2buildDiff:
+		runBp2BuildDiff(ctx)
 	case android.ApiBp2build:
 		finalOutputFile = runApiBp2build(ctx, extraNinjaDeps)
 		writeMetrics(configuration, ctx.EventHandler, metricsDir)
+	case android.ExplainDependencyCycles:
+		ctx.Register()
+		finalOutputFile = explainDependencyCycles(ctx, extraNinjaDeps)
+	case android.GenerateModuleList:
+		ctx.Register()
+		finalOutputFile = listModules(ctx, extraNinjaDeps)
 	default:
 		ctx.Register()
+		// Resume from the checkpoint of an interrupted run, and write one if this run is interrupted.
+		err := android.ResumeAnalysis(configuration)
+		maybeQuit(err, "error resuming analysis")
+		var metricsFile string
+		if metricsDir != "" {
+			metricsFile = filepath.Join(metricsDir, "soong_build_metrics.pb")
+		}
+		android.HandleAnalysisInterrupts(configuration, ctx.EventHandler, metricsFile)
 		if configuration.IsMixedBuildsEnabled() {
 			finalOutputFile = runMixedModeBuild(ctx, extraNinjaDeps)
 		} else {
@@ -441,6 +753,13 @@ func main() {
 		if ctx.Config().IsEnvTrue("SOONG_GENERATES_NINJA_HINT") {
 			writeNinjaHint(ctx)
 		}
+		// Every Android.bp file was parsed, so the cached expansions that weren't used are stale.
+		err = bpIncludes.PruneCache()
+		maybeQuit(err, "error pruning the Android.bp cache")
+		err = android.WriteActionMetadata(configuration)
+		maybeQuit(err, "error writing soong action metadata")
+		err = android.WriteNinjaWeightFile(configuration)
+		maybeQuit(err, "error writing ninja weight file")
 		writeMetrics(configuration, ctx.EventHandler, metricsDir)
 	}
 	writeUsedEnvironmentFile(configuration)
@@ -449,7 +768,9 @@ func main() {
 	// This is necessary because, if soong_build generated any files which
 	// are ninja inputs to the main output file, then ninja would superfluously
 	// rebuild this output file on the next build invocation.
-	touch(shared.JoinPath(topDir, finalOutputFile))
+	if finalOutputFile != "" {
+		touch(shared.JoinPath(topDir, finalOutputFile))
+	}
 }
 
 func writeUsedEnvironmentFile(configuration android.Config) {
@@ -531,7 +852,7 @@ func runSymlinkForestCreation(ctx *android.Context, extraNinjaDeps []string, met
 		var symlinkForestDeps []string
 		ctx.EventHandler.Do("plant", func() {
 			symlinkForestDeps, mkdirCount, symlinkCount = bp2build.PlantSymlinkForest(
-				verbose, topDir, workspaceRoot, generatedRoot, excludedFromSymlinkForest(ctx, verbose))
+				verbose, symlinkForestMode(ctx), symlinkForestJobs, topDir, workspaceRoot, generatedRoot, excludedFromSymlinkForest(ctx, verbose))
 		})
 		ninjaDeps = append(ninjaDeps, symlinkForestDeps...)
 	})
@@ -552,6 +873,15 @@ func runSymlinkForestCreation(ctx *android.Context, extraNinjaDeps []string, met
 	return cmdlineArgs.SymlinkForestMarker
 }
 
+// symlinkForestMode returns how the Bazel workspaces are planted, see bp2build.ForestMode.
+// BP2BUILD_WORKSPACE_MODE=hardlink or copy avoids the symlinks of the default mode on filesystems
+// where they are slow or unsupported.
+func symlinkForestMode(ctx *android.Context) bp2build.ForestMode {
+	mode, err := bp2build.ParseForestMode(ctx.Config().Getenv("BP2BUILD_WORKSPACE_MODE"))
+	maybeQuit(err, "Invalid BP2BUILD_WORKSPACE_MODE")
+	return mode
+}
+
 func excludedFromSymlinkForest(ctx *android.Context, verbose bool) []string {
 	excluded := bazelArtifacts()
 	if cmdlineArgs.OutDir[0] != '/' {
@@ -666,9 +996,30 @@ func runBp2Build(ctx *android.Context, extraNinjaDeps []string, metricsDir strin
 		codegenMetrics.Print()
 	}
 	writeBp2BuildMetrics(codegenMetrics, ctx.EventHandler, metricsDir)
+	codegenMetrics.WriteConversionReport(metricsDir)
 	return cmdlineArgs.Bp2buildMarker
 }
 
+// Run bp2build and print a diff of the BUILD files that it generates against the ones of the
+// previous bp2build run to stdout, without writing them.
+func runBp2BuildDiff(ctx *android.Context) {
+	ctx.EventHandler.Begin("bp2build_diff")
+	defer ctx.EventHandler.End("bp2build_diff")
+
+	ctx.SetAllowMissingDependencies(ctx.Config().AllowMissingDependencies())
+	ctx.SetNameInterface(newNameResolver(ctx.Config()))
+	ctx.RegisterForBazelConversion()
+	ctx.SetModuleListFile(cmdlineArgs.ModuleListFile)
+	bootstrap.RunBlueprint(cmdlineArgs.Args, bootstrap.StopBeforePrepareBuildActions, ctx.Context, ctx.Config())
+
+	codegenContext := bp2build.NewCodegenContext(ctx.Config(), ctx, bp2build.Bp2Build, topDir)
+	differ, err := bp2build.CodegenDiff(codegenContext, os.Stdout)
+	maybeQuit(err, "error diffing bp2build output")
+	if !differ {
+		fmt.Fprintln(os.Stderr, "bp2build output is unchanged")
+	}
+}
+
 // Write Bp2Build metrics into $LOG_DIR
 func writeBp2BuildMetrics(codegenMetrics *bp2build.CodegenMetrics, eventHandler *metrics.EventHandler, metricsDir string) {
 	for _, event := range eventHandler.CompletedEvents() {
@@ -693,6 +1044,15 @@ func readFileLines(path string) ([]string, error) {
 	return nil, err
 
 }
+
+// quitWithErrors prints the errors like bootstrap.RunBlueprint does and exits.
+func quitWithErrors(errs []error) {
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, "error:", err)
+	}
+	os.Exit(1)
+}
+
 func maybeQuit(err error, format string, args ...interface{}) {
 	if err == nil {
 		return
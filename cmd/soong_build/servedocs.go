@@ -0,0 +1,241 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"android/soong/android"
+	"android/soong/shared"
+)
+
+// How often the files that the analysis depends on are checked for changes.
+const serveDocsPollInterval = 2 * time.Second
+
+// docsServer serves the Soong docs and the index of the modules of the product.
+type docsServer struct {
+	docs  map[string][]byte
+	index []android.ModuleIndexEntry
+
+	// The documentation page of each module type, e.g. "cc.html#cc_library".
+	typeDocs map[string]string
+}
+
+func newDocsServer(ctx *android.Context) (*docsServer, error) {
+	docs, err := renderDocs(ctx, "index.html")
+	if err != nil {
+		return nil, err
+	}
+	packages, err := getPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	typeDocs := make(map[string]string)
+	for _, pkg := range packages {
+		for _, moduleType := range pkg.ModuleTypes {
+			typeDocs[moduleType.Name] = pkg.Name + ".html#" + moduleType.Name
+		}
+	}
+	return &docsServer{
+		docs:     docs,
+		index:    android.ModuleIndex(ctx),
+		typeDocs: typeDocs,
+	}, nil
+}
+
+func (s *docsServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveDoc)
+	mux.HandleFunc("/modules", s.serveSearch)
+	mux.HandleFunc("/modules.json", s.serveSearchJson)
+	mux.HandleFunc("/modules/", s.serveModule)
+	return mux
+}
+
+func (s *docsServer) serveDoc(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if name == "" {
+		name = "index.html"
+	}
+	doc, ok := s.docs[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if strings.HasSuffix(name, ".txt") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	w.Write(doc)
+}
+
+func (s *docsServer) serveSearch(w http.ResponseWriter, r *http.Request) {
+	search := r.URL.Query().Get("q")
+	var modules []android.ModuleIndexEntry
+	if search != "" {
+		modules = android.SearchModuleIndex(s.index, search)
+	}
+	s.execute(w, searchTemplate, struct {
+		Search  string
+		Modules []android.ModuleIndexEntry
+		Total   int
+	}{search, modules, len(s.index)})
+}
+
+func (s *docsServer) serveSearchJson(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	modules := android.SearchModuleIndex(s.index, r.URL.Query().Get("q"))
+	if modules == nil {
+		modules = []android.ModuleIndexEntry{}
+	}
+	json.NewEncoder(w).Encode(modules)
+}
+
+func (s *docsServer) serveModule(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/modules/")
+	for _, module := range s.index {
+		if module.Name == name {
+			s.execute(w, moduleTemplate, struct {
+				Module  android.ModuleIndexEntry
+				TypeDoc string
+			}{module, s.typeDocs[module.Type]})
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (s *docsServer) execute(w http.ResponseWriter, tmpl *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// serveDocs serves the Soong docs and a searchable index of the modules of the product on addr, a
+// port or a host:port, until soong_build is killed. The module graph can't be analyzed again in
+// the same process, so when one of the files that the analysis depends on changes, e.g. an
+// Android.bp file, soong_build runs itself again to serve the new module graph.
+func serveDocs(ctx *android.Context, addr string, ninjaDeps []string) {
+	server, err := newDocsServer(ctx)
+	maybeQuit(err, "error building Soong docs")
+
+	if !strings.Contains(addr, ":") {
+		addr = "localhost:" + addr
+	}
+	listener, err := net.Listen("tcp", addr)
+	maybeQuit(err, "error listening on %s", addr)
+	fmt.Fprintf(os.Stderr, "Serving Soong docs at http://%s/ and the modules at http://%s/modules\n",
+		listener.Addr(), listener.Addr())
+
+	go func() {
+		watchNinjaDeps(ninjaDeps)
+		fmt.Fprintln(os.Stderr, "Android.bp files changed, analyzing the module graph again")
+		listener.Close()
+		executable, err := os.Executable()
+		maybeQuit(err, "error finding soong_build")
+		err = syscall.Exec(executable, os.Args, os.Environ())
+		maybeQuit(err, "error running soong_build again")
+	}()
+
+	// http.Serve returns when the listener is closed, which only happens right before soong_build
+	// replaces itself.
+	http.Serve(listener, server.handler())
+	select {}
+}
+
+// watchNinjaDeps returns when the modification time of one of the files changes, or a file is
+// created or removed.
+func watchNinjaDeps(ninjaDeps []string) {
+	modTimes := func() map[string]time.Time {
+		times := make(map[string]time.Time, len(ninjaDeps))
+		for _, dep := range ninjaDeps {
+			if !filepath.IsAbs(dep) {
+				dep = shared.JoinPath(topDir, dep)
+			}
+			if info, err := os.Stat(dep); err == nil {
+				times[dep] = info.ModTime()
+			}
+		}
+		return times
+	}
+	initial := modTimes()
+	for {
+		time.Sleep(serveDocsPollInterval)
+		current := modTimes()
+		if len(current) != len(initial) {
+			return
+		}
+		for dep, modTime := range current {
+			if !initial[dep].Equal(modTime) {
+				return
+			}
+		}
+	}
+}
+
+var searchTemplate = template.Must(template.New("search").Parse(`<html>
+<head>
+<title>Modules</title>
+<link rel="stylesheet" href="https://maxcdn.bootstrapcdn.com/bootstrap/4.2.1/css/bootstrap.min.css">
+</head>
+<body>
+<div class="container">
+<h1>Modules</h1>
+<p><a href="/">Module types</a></p>
+<form action="/modules">
+<input type="text" name="q" value="{{.Search}}" placeholder="Search {{.Total}} modules by name, type or directory" class="form-control">
+</form>
+{{if .Search}}
+<p>{{len .Modules}} modules</p>
+<table class="table">
+<tr><th>Name</th><th>Type</th><th>Directory</th><th>Dependencies</th></tr>
+{{range .Modules}}<tr><td><a href="/modules/{{.Name}}">{{.Name}}</a></td><td>{{.Type}}</td><td>{{.Dir}}</td><td>{{len .Deps}}</td></tr>
+{{end}}</table>
+{{end}}
+</div>
+</body>
+</html>
+`))
+
+var moduleTemplate = template.Must(template.New("module").Parse(`<html>
+<head>
+<title>{{.Module.Name}}</title>
+<link rel="stylesheet" href="https://maxcdn.bootstrapcdn.com/bootstrap/4.2.1/css/bootstrap.min.css">
+</head>
+<body>
+<div class="container">
+<h1>{{.Module.Name}}</h1>
+<p><a href="/modules">Modules</a></p>
+<table class="table">
+<tr><th>Type</th><td>{{if .TypeDoc}}<a href="/{{.TypeDoc}}">{{.Module.Type}}</a>{{else}}{{.Module.Type}}{{end}}</td></tr>
+<tr><th>Directory</th><td>{{.Module.Dir}}</td></tr>
+<tr><th>Dependencies</th><td>{{range $i, $dep := .Module.Deps}}{{if $i}}, {{end}}<a href="/modules/{{$dep}}">{{$dep}}</a>{{end}}</td></tr>
+<tr><th>Dependents</th><td>{{range $i, $dep := .Module.Rdeps}}{{if $i}}, {{end}}<a href="/modules/{{$dep}}">{{$dep}}</a>{{end}}</td></tr>
+</table>
+</div>
+</body>
+</html>
+`))
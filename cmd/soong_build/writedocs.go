@@ -102,18 +102,36 @@ func getPackages(ctx *android.Context) ([]*bpdoc.Package, error) {
 }
 
 func writeDocs(ctx *android.Context, filename string) error {
-	packages, err := getPackages(ctx)
+	docs, err := renderDocs(ctx, filepath.Base(filename))
 	if err != nil {
 		return err
 	}
+	for _, name := range android.SortedKeys(docs) {
+		err = ioutil.WriteFile(filepath.Join(filepath.Dir(filename), name), docs[name], 0666)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderDocs returns the files of the Soong docs by name: the package list page, a page with the
+// module types of each package, and keywords.txt.
+func renderDocs(ctx *android.Context, packageListName string) (map[string][]byte, error) {
+	packages, err := getPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs := make(map[string][]byte)
 
 	// Produce the top-level, package list page first.
 	tmpl := template.Must(template.Must(template.New("file").Parse(packageListTemplate)).Parse(copyBaseUrl))
 	buf := &bytes.Buffer{}
 	err = tmpl.Execute(buf, packages)
-	if err == nil {
-		err = ioutil.WriteFile(filename, buf.Bytes(), 0666)
+	if err != nil {
+		return nil, err
 	}
+	docs[packageListName] = buf.Bytes()
 
 	// Now, produce per-package module lists with detailed information, and a list
 	// of keywords.
@@ -138,25 +156,20 @@ func writeDocs(ctx *android.Context, filename string) error {
 		data := perPackageTemplateData{Name: pkg.Name, Modules: modules}
 		err = tmpl.Execute(buf, data)
 		if err != nil {
-			return err
-		}
-		pkgFileName := filepath.Join(filepath.Dir(filename), pkg.Name+".html")
-		err = ioutil.WriteFile(pkgFileName, buf.Bytes(), 0666)
-		if err != nil {
-			return err
+			return nil, err
 		}
+		docs[pkg.Name+".html"] = buf.Bytes()
 		err = keywordsTmpl.Execute(keywordsBuf, data)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	// Write out list of keywords. This includes all module and property names, which is useful for
 	// building syntax highlighters.
-	keywordsFilename := filepath.Join(filepath.Dir(filename), "keywords.txt")
-	err = ioutil.WriteFile(keywordsFilename, keywordsBuf.Bytes(), 0666)
+	docs["keywords.txt"] = keywordsBuf.Bytes()
 
-	return err
+	return docs, nil
 }
 
 // TODO(jungjw): Consider ordering by name.
@@ -0,0 +1,248 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// soong_config_diff compares two product configurations written by `m dump_config`: the settings
+// that were added, removed or changed, e.g. product variables, targets and environment variables.
+// It is used to compare two products, or the same product on two branches.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Setting is a setting of a configuration, e.g. "product_variables.Platform_sdk_version", with its
+// value as JSON.
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// SettingChange is a setting whose value changed. The added and removed elements are set if both
+// values are lists.
+type SettingChange struct {
+	Name     string
+	OldValue string
+	NewValue string
+
+	AddedElements   []string `json:",omitempty"`
+	RemovedElements []string `json:",omitempty"`
+}
+
+// Delta is the difference between two configurations. The settings are sorted by name.
+type Delta struct {
+	AddedSettings   []Setting
+	RemovedSettings []Setting
+	ChangedSettings []SettingChange
+}
+
+func (d *Delta) Empty() bool {
+	return len(d.AddedSettings) == 0 && len(d.RemovedSettings) == 0 && len(d.ChangedSettings) == 0
+}
+
+// readConfigDump reads a configuration and returns its settings by name.
+func readConfigDump(r io.Reader) (map[string]interface{}, error) {
+	var dump map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&dump); err != nil {
+		return nil, err
+	}
+	settings := make(map[string]interface{})
+	flatten("", dump, settings)
+	return settings, nil
+}
+
+// flatten adds the settings of a JSON object to settings, with the names of the nested objects
+// joined with dots. Lists and scalars are settings.
+func flatten(prefix string, object map[string]interface{}, settings map[string]interface{}) {
+	for name, value := range object {
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(name, nested, settings)
+		} else {
+			settings[name] = value
+		}
+	}
+}
+
+func jsonValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func sortedNames(settings map[string]interface{}) []string {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func computeDelta(oldSettings, newSettings map[string]interface{}) *Delta {
+	delta := &Delta{}
+	for _, name := range sortedNames(newSettings) {
+		if _, ok := oldSettings[name]; !ok {
+			delta.AddedSettings = append(delta.AddedSettings, Setting{name, jsonValue(newSettings[name])})
+		}
+	}
+	for _, name := range sortedNames(oldSettings) {
+		newValue, ok := newSettings[name]
+		if !ok {
+			delta.RemovedSettings = append(delta.RemovedSettings, Setting{name, jsonValue(oldSettings[name])})
+			continue
+		}
+		oldJson, newJson := jsonValue(oldSettings[name]), jsonValue(newValue)
+		if oldJson == newJson {
+			continue
+		}
+		change := SettingChange{Name: name, OldValue: oldJson, NewValue: newJson}
+		oldList, oldIsList := oldSettings[name].([]interface{})
+		newList, newIsList := newValue.([]interface{})
+		if oldIsList && newIsList {
+			change.AddedElements, change.RemovedElements = compareLists(oldList, newList)
+		}
+		delta.ChangedSettings = append(delta.ChangedSettings, change)
+	}
+	return delta
+}
+
+// compareLists returns the elements of the new list that are not in the old one and the elements
+// of the old list that are not in the new one, in the order of the lists.
+func compareLists(oldList, newList []interface{}) (added, removed []string) {
+	set := func(list []interface{}) map[string]bool {
+		s := make(map[string]bool)
+		for _, e := range list {
+			s[jsonValue(e)] = true
+		}
+		return s
+	}
+	oldSet, newSet := set(oldList), set(newList)
+	for _, e := range newList {
+		if v := jsonValue(e); !oldSet[v] {
+			added = append(added, v)
+		}
+	}
+	for _, e := range oldList {
+		if v := jsonValue(e); !newSet[v] {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
+
+// writeSummary writes a human readable summary of the delta.
+func writeSummary(w io.Writer, delta *Delta) {
+	if delta.Empty() {
+		fmt.Fprintln(w, "The configurations are the same.")
+		return
+	}
+	fmt.Fprintf(w, "%d settings added, %d removed, %d changed\n",
+		len(delta.AddedSettings), len(delta.RemovedSettings), len(delta.ChangedSettings))
+	for _, s := range delta.AddedSettings {
+		fmt.Fprintf(w, "+ %s: %s\n", s.Name, s.Value)
+	}
+	for _, s := range delta.RemovedSettings {
+		fmt.Fprintf(w, "- %s: %s\n", s.Name, s.Value)
+	}
+	for _, c := range delta.ChangedSettings {
+		if c.AddedElements == nil && c.RemovedElements == nil {
+			// Lists whose elements were only reordered are shown like scalars.
+			fmt.Fprintf(w, "~ %s: %s -> %s\n", c.Name, c.OldValue, c.NewValue)
+			continue
+		}
+		fmt.Fprintf(w, "~ %s\n", c.Name)
+		for _, e := range c.AddedElements {
+			fmt.Fprintf(w, "    + %s\n", e)
+		}
+		for _, e := range c.RemovedElements {
+			fmt.Fprintf(w, "    - %s\n", e)
+		}
+	}
+}
+
+func readConfigDumpFile(path string) (map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	settings, err := readConfigDump(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-o <output.json>] [-summary] <old config_dump.json> <new config_dump.json>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	output := flag.String("o", "", "JSON file to write the delta to, defaults to stdout")
+	summary := flag.Bool("summary", false, "write a human readable summary instead of JSON")
+	exitCode := flag.Bool("exit_code", false, "exit with status 1 if the configurations differ")
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	oldSettings, err := readConfigDumpFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	newSettings, err := readConfigDumpFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	delta := computeDelta(oldSettings, newSettings)
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		defer out.Close()
+	}
+
+	if *summary {
+		writeSummary(out, delta)
+	} else {
+		data, err := json.MarshalIndent(delta, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		out.Write(append(data, '\n'))
+	}
+
+	if *exitCode && !delta.Empty() {
+		out.Close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const oldConfig = `{
+  "product_variables": {
+    "DeviceName": "foo",
+    "Platform_sdk_version": 33,
+    "DeviceAbi": ["arm64-v8a"],
+    "Unbundled_build": true
+  },
+  "targets": {
+    "android": ["android_arm64_armv8-a", "android_arm_armv7-a-neon"]
+  },
+  "env_deps": {
+    "USE_FOO": "true"
+  }
+}`
+
+const newConfig = `{
+  "product_variables": {
+    "DeviceName": "bar",
+    "Platform_sdk_version": 33,
+    "DeviceAbi": ["arm64-v8a"],
+    "Eng": true
+  },
+  "targets": {
+    "android": ["android_arm64_armv8-a", "android_x86_64"]
+  },
+  "env_deps": {
+    "USE_FOO": "true"
+  }
+}`
+
+func TestComputeDelta(t *testing.T) {
+	oldSettings, err := readConfigDump(strings.NewReader(oldConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newSettings, err := readConfigDump(strings.NewReader(newConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Delta{
+		AddedSettings:   []Setting{{"product_variables.Eng", "true"}},
+		RemovedSettings: []Setting{{"product_variables.Unbundled_build", "true"}},
+		ChangedSettings: []SettingChange{
+			{Name: "product_variables.DeviceName", OldValue: `"foo"`, NewValue: `"bar"`},
+			{
+				Name:            "targets.android",
+				OldValue:        `["android_arm64_armv8-a","android_arm_armv7-a-neon"]`,
+				NewValue:        `["android_arm64_armv8-a","android_x86_64"]`,
+				AddedElements:   []string{`"android_x86_64"`},
+				RemovedElements: []string{`"android_arm_armv7-a-neon"`},
+			},
+		},
+	}
+	if delta := computeDelta(oldSettings, newSettings); !reflect.DeepEqual(delta, expected) {
+		t.Errorf("expected %+v, got %+v", expected, delta)
+	}
+
+	if delta := computeDelta(oldSettings, oldSettings); !delta.Empty() {
+		t.Errorf("expected no delta between a configuration and itself, got %+v", delta)
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	delta := &Delta{
+		AddedSettings: []Setting{{"env_deps.USE_BAR", `"true"`}},
+		ChangedSettings: []SettingChange{
+			{Name: "product_variables.DeviceName", OldValue: `"foo"`, NewValue: `"bar"`},
+			{Name: "targets.android", AddedElements: []string{`"android_x86_64"`}},
+		},
+	}
+	expected := `1 settings added, 0 removed, 2 changed
++ env_deps.USE_BAR: "true"
+~ product_variables.DeviceName: "foo" -> "bar"
+~ targets.android
+    + "android_x86_64"
+`
+	buf := &bytes.Buffer{}
+	writeSummary(buf, delta)
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -148,7 +148,8 @@ func main() {
 	// Create a terminal output that mimics Ninja's.
 	output := terminal.NewStatusOutput(c.stdio().Stdout(), os.Getenv("NINJA_STATUS"), c.simpleOutput,
 		build.OsEnvironment().IsEnvTrue("ANDROID_QUIET_BUILD"),
-		build.OsEnvironment().IsEnvTrue("SOONG_UI_ANSI_OUTPUT"))
+		build.OsEnvironment().IsEnvTrue("SOONG_UI_ANSI_OUTPUT"),
+		build.OsEnvironment().IsEnvTrue("SOONG_UI_TUI"))
 
 	// Create and start a new metric record.
 	met := metrics.New()
@@ -202,6 +203,7 @@ func main() {
 	bp2buildMetricsFile := filepath.Join(logsDir, c.logsPrefix+"bp2build_metrics.pb")
 	bazelMetricsFile := filepath.Join(logsDir, c.logsPrefix+"bazel_metrics.pb")
 	soongBuildMetricsFile := filepath.Join(logsDir, c.logsPrefix+"soong_build_metrics.pb")
+	compilerCacheMetricsFile := filepath.Join(logsDir, c.logsPrefix+"compiler_cache_metrics.json")
 
 	//the profile file generated by Bazel"
 	bazelProfileFile := filepath.Join(logsDir, c.logsPrefix+"analyzed_bazel_profile.txt")
@@ -212,6 +214,7 @@ func main() {
 		soongMetricsFile,         // high level metrics related to this build system.
 		bazelMetricsFile,         // high level metrics related to bazel execution
 		soongBuildMetricsFile,    // high level metrics related to soong build(except bp2build)
+		compilerCacheMetricsFile, // statistics of the compiler cache
 		config.BazelMetricsDir(), // directory that contains a set of bazel metrics.
 	}
 
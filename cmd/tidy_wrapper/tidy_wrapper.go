@@ -0,0 +1,433 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// tidy_wrapper runs the clang-tidy command of a source file, caches its results and suppresses the
+// findings that are in the baselines of the source tree.
+//
+// With --cache_dir, the results of the command are cached by the digest of its command line and
+// of the contents of the source file. A cached result is used when the files that its depfile
+// lists still have the same contents, so a source file that clang-tidy already checked, e.g. in
+// another checkout or before a clean build, isn't checked again.
+//
+// The findings that are in one of the --baseline files don't fail the command and aren't printed.
+// A baseline has a line of <path>\t<check>\t<message> for every suppressed finding, without its
+// line number so that editing the file doesn't invalidate the baseline. Lines starting with # are
+// comments. All the findings are written to the --findings file as lines of
+// <path>\t<line>\t<check>\t<message>\t<new|baselined>, for the tree-wide tidy report.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// cacheVersion is part of the cache keys, to invalidate the cached results when their format or
+// the way they are computed changes.
+const cacheVersion = "1"
+
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+// options are the arguments of tidy_wrapper.
+type options struct {
+	out      string
+	src      string
+	findings string
+	cacheDir string
+	baseline []string
+	command  []string
+}
+
+// finding is a clang-tidy warning or error of a check.
+type finding struct {
+	path    string
+	line    string
+	check   string
+	message string
+
+	// Whether the finding fails clang-tidy, either because it's an error or because its check is
+	// in -warnings-as-errors.
+	isError bool
+
+	// Whether the finding is in a baseline.
+	baselined bool
+}
+
+// baselineKey is the key of a finding in a baseline.
+type baselineKey struct {
+	path, check, message string
+}
+
+func (f finding) baselineKey() baselineKey {
+	return baselineKey{f.path, f.check, f.message}
+}
+
+// diagnosticRe matches the first line of a clang-tidy diagnostic of a check, e.g.
+// foo.cpp:12:3: warning: use of a moved-from object [bugprone-use-after-move,-warnings-as-errors]
+var diagnosticRe = regexp.MustCompile(`^(.+?):(\d+):\d+: (warning|error): (.*) \[([^\]]+)\]$`)
+
+// summaryRe matches the summary lines of clang-tidy, which aren't part of a diagnostic.
+var summaryRe = regexp.MustCompile(`^(\d+ (warnings?|errors?) (generated|treated as errors)|Suppressed \d+ warnings)`)
+
+// readBaselines returns the findings in the baseline files.
+func readBaselines(files []string) (map[baselineKey]bool, error) {
+	baseline := make(map[baselineKey]bool)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			fields := strings.SplitN(line, "\t", 3)
+			if len(fields) != 3 {
+				f.Close()
+				return nil, fmt.Errorf("%s: expected <path>\\t<check>\\t<message>, got %q", file, line)
+			}
+			baseline[baselineKey{fields[0], fields[1], fields[2]}] = true
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return baseline, nil
+}
+
+// filterOutput parses the findings in the output of clang-tidy and removes the ones that are in
+// the baseline from it, with the notes and source lines that follow them. It returns the filtered
+// output, all the findings and whether any finding that isn't in the baseline fails clang-tidy.
+func filterOutput(output string, baseline map[baselineKey]bool) (string, []finding, bool) {
+	var filtered strings.Builder
+	var findings []finding
+	failing := false
+	skipping := false
+	for _, line := range strings.SplitAfter(output, "\n") {
+		trimmed := strings.TrimSuffix(line, "\n")
+		if m := diagnosticRe.FindStringSubmatch(trimmed); m != nil {
+			checks := strings.Split(m[5], ",")
+			f := finding{
+				path:    m[1],
+				line:    m[2],
+				check:   checks[0],
+				message: m[4],
+				isError: m[3] == "error",
+			}
+			for _, c := range checks[1:] {
+				if c == "-warnings-as-errors" {
+					f.isError = true
+				}
+			}
+			f.baselined = baseline[f.baselineKey()]
+			findings = append(findings, f)
+			skipping = f.baselined
+			if f.isError && !f.baselined {
+				failing = true
+			}
+		} else if summaryRe.MatchString(trimmed) {
+			skipping = false
+		}
+		if !skipping {
+			filtered.WriteString(line)
+		}
+	}
+	return filtered.String(), findings, failing
+}
+
+func writeFindings(file string, findings []finding) error {
+	var buf bytes.Buffer
+	for _, f := range findings {
+		status := "new"
+		if f.baselined {
+			status = "baselined"
+		}
+		fmt.Fprintf(&buf, "%s\t%s\t%s\t%s\t%s\n", f.path, f.line, f.check, f.message, status)
+	}
+	return os.WriteFile(file, buf.Bytes(), 0666)
+}
+
+// parseDepFile returns the prerequisites of a Makefile depfile with a single rule.
+func parseDepFile(data []byte) []string {
+	s := strings.ReplaceAll(string(data), "\\\n", " ")
+	i := strings.Index(s, ": ")
+	if i < 0 {
+		return nil
+	}
+	s = s[i+2:]
+	var deps []string
+	var dep strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && s[i+1] == ' ':
+			dep.WriteByte(' ')
+			i++
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if dep.Len() > 0 {
+				deps = append(deps, dep.String())
+				dep.Reset()
+			}
+		default:
+			dep.WriteByte(c)
+		}
+	}
+	if dep.Len() > 0 {
+		deps = append(deps, dep.String())
+	}
+	return deps
+}
+
+func fileDigest(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cacheEntry is the cached result of a clang-tidy command.
+type cacheEntry struct {
+	ExitCode int
+	Output   string
+	TidyFile []byte
+	DepFile  []byte
+
+	// The digests of the contents of the files in the depfile.
+	Deps map[string]string
+}
+
+func cacheKey(opts options) (string, error) {
+	srcDigest, err := fileDigest(opts.src)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", cacheVersion, srcDigest)
+	// clang-tidy.sh reads the compiler that writes the depfile and its timeout from the environment.
+	for _, env := range []string{"CLANG_CMD", "TIDY_TIMEOUT"} {
+		fmt.Fprintf(h, "%s=%s\x00", env, os.Getenv(env))
+	}
+	for _, arg := range opts.command {
+		fmt.Fprintf(h, "%s\x00", arg)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func cachePath(cacheDir, key string) string {
+	return filepath.Join(cacheDir, key[:2], key)
+}
+
+// lookup returns the cached result of the command, if the files that it depends on didn't change.
+func lookup(cacheDir, key string) *cacheEntry {
+	data, err := os.ReadFile(cachePath(cacheDir, key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	for dep, digest := range entry.Deps {
+		if d, err := fileDigest(dep); err != nil || d != digest {
+			return nil
+		}
+	}
+	return &entry
+}
+
+// store caches the result of the command, unless it failed without findings, e.g. because it
+// timed out.
+func store(cacheDir, key string, entry *cacheEntry, findings []finding) error {
+	if (entry.ExitCode != 0 && len(findings) == 0) || entry.DepFile == nil {
+		return nil
+	}
+	entry.Deps = make(map[string]string)
+	for _, dep := range parseDepFile(entry.DepFile) {
+		digest, err := fileDigest(dep)
+		if err != nil {
+			// The dependency is gone already, the result can't be reused.
+			return nil
+		}
+		entry.Deps[dep] = digest
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Write the entry atomically, concurrent builds may share the cache.
+	path := cachePath(cacheDir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+key)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// runCommand runs the clang-tidy command and returns its result.
+func runCommand(opts options) (*cacheEntry, error) {
+	var output bytes.Buffer
+	cmd := exec.Command(opts.command[0], opts.command[1:]...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	entry := &cacheEntry{}
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, err
+		}
+		entry.ExitCode = exitErr.ExitCode()
+	}
+	entry.Output = output.String()
+	entry.TidyFile = readFileIfExists(opts.out)
+	entry.DepFile = readFileIfExists(opts.out + ".d")
+	return entry, nil
+}
+
+func readFileIfExists(file string) []byte {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// restore writes the outputs of a cached result of the command.
+func restore(opts options, entry *cacheEntry) error {
+	for file, data := range map[string][]byte{opts.out: entry.TidyFile, opts.out + ".d": entry.DepFile} {
+		if data == nil {
+			os.Remove(file)
+			continue
+		}
+		if err := os.WriteFile(file, data, 0666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run runs the clang-tidy command, or uses its cached result, writes the output that isn't
+// suppressed by the baselines to w and returns the exit code of the wrapper.
+func run(opts options, w io.Writer) (int, error) {
+	baseline, err := readBaselines(opts.baseline)
+	if err != nil {
+		return 0, err
+	}
+
+	var key string
+	var entry *cacheEntry
+	if opts.cacheDir != "" {
+		if key, err = cacheKey(opts); err != nil {
+			return 0, err
+		}
+		entry = lookup(opts.cacheDir, key)
+	}
+	cached := entry != nil
+	if cached {
+		if err := restore(opts, entry); err != nil {
+			return 0, err
+		}
+	} else if entry, err = runCommand(opts); err != nil {
+		return 0, err
+	}
+
+	output, findings, failing := filterOutput(entry.Output, baseline)
+	if opts.cacheDir != "" && !cached {
+		if err := store(opts.cacheDir, key, entry, findings); err != nil {
+			fmt.Fprintf(os.Stderr, "tidy_wrapper: failed to cache the result of %s: %s\n", opts.src, err)
+		}
+	}
+	if err := writeFindings(opts.findings, findings); err != nil {
+		return 0, err
+	}
+	io.WriteString(w, output)
+
+	exitCode := entry.ExitCode
+	if exitCode != 0 && !failing && len(findings) > 0 {
+		// clang-tidy only failed because of findings in the baseline. Write the outputs that it
+		// doesn't write when it fails.
+		exitCode = 0
+		if _, err := os.Stat(opts.out); err != nil {
+			if err := os.WriteFile(opts.out, nil, 0666); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := os.Stat(opts.out + ".d"); err != nil {
+			depFile := fmt.Sprintf("%s: %s\n", opts.out, opts.src)
+			if err := os.WriteFile(opts.out+".d", []byte(depFile), 0666); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return exitCode, nil
+}
+
+func main() {
+	var opts options
+	var baselines multiString
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s --out <file.tidy> --src <source> --findings <file> [--cache_dir <dir>] [--baseline <file>]... -- <clang-tidy command>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&opts.out, "out", "", "the .tidy file that the command writes, its depfile is <out>.d")
+	flag.StringVar(&opts.src, "src", "", "the source file that the command checks")
+	flag.StringVar(&opts.findings, "findings", "", "file to write the findings to")
+	flag.StringVar(&opts.cacheDir, "cache_dir", "", "directory to cache the results of the command in")
+	flag.Var(&baselines, "baseline", "file of findings to suppress, can be repeated")
+	flag.Parse()
+	opts.baseline = baselines
+	opts.command = flag.Args()
+
+	if opts.out == "" || opts.src == "" || opts.findings == "" || len(opts.command) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	exitCode, err := run(opts, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tidy_wrapper:", err)
+		os.Exit(2)
+	}
+	os.Exit(exitCode)
+}
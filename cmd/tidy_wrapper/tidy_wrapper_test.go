@@ -0,0 +1,188 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const tidyOutput = `foo.cpp:12:3: warning: use of a moved-from object [bugprone-use-after-move,-warnings-as-errors]
+  bar(x);
+  ^
+foo.cpp:10:3: note: move occurred here
+foo.h:4:1: warning: function is not const [readability-make-member-function-const]
+2 warnings treated as errors
+`
+
+func TestFilterOutput(t *testing.T) {
+	baseline := map[baselineKey]bool{
+		{"foo.cpp", "bugprone-use-after-move", "use of a moved-from object"}: true,
+	}
+	output, findings, failing := filterOutput(tidyOutput, baseline)
+
+	expectedOutput := `foo.h:4:1: warning: function is not const [readability-make-member-function-const]
+2 warnings treated as errors
+`
+	if output != expectedOutput {
+		t.Errorf("expected output:\n%s\ngot:\n%s", expectedOutput, output)
+	}
+	expectedFindings := []finding{
+		{"foo.cpp", "12", "bugprone-use-after-move", "use of a moved-from object", true, true},
+		{"foo.h", "4", "readability-make-member-function-const", "function is not const", false, false},
+	}
+	if !reflect.DeepEqual(findings, expectedFindings) {
+		t.Errorf("expected findings %v, got %v", expectedFindings, findings)
+	}
+	if failing {
+		t.Errorf("expected the baselined error not to fail")
+	}
+
+	if _, _, failing := filterOutput(tidyOutput, nil); !failing {
+		t.Errorf("expected the error to fail without a baseline")
+	}
+}
+
+func TestParseDepFile(t *testing.T) {
+	deps := parseDepFile([]byte("out/foo.tidy: foo.cpp \\\n  include/foo.h dir\\ with\\ spaces/bar.h\n"))
+	expected := []string{"foo.cpp", "include/foo.h", "dir with spaces/bar.h"}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("expected %q, got %q", expected, deps)
+	}
+}
+
+func TestReadBaselines(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tidy-baseline.txt")
+	contents := "# Pre-existing findings.\n\nfoo.cpp\tbugprone-use-after-move\tuse of a moved-from object\n"
+	if err := os.WriteFile(file, []byte(contents), 0666); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := readBaselines([]string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[baselineKey]bool{
+		{"foo.cpp", "bugprone-use-after-move", "use of a moved-from object"}: true,
+	}
+	if !reflect.DeepEqual(baseline, expected) {
+		t.Errorf("expected %v, got %v", expected, baseline)
+	}
+
+	if err := os.WriteFile(file, []byte("foo.cpp bugprone-use-after-move\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readBaselines([]string{file}); err == nil {
+		t.Errorf("expected an error for a malformed baseline")
+	}
+}
+
+// fakeTidy returns a command that writes out, its depfile listing src and header, and the
+// findings of tidyOutput, and counts its runs in a file.
+func fakeTidy(dir, out, src, header string) []string {
+	script := "echo run >> " + filepath.Join(dir, "runs") + "; " +
+		"echo > " + out + "; " +
+		"echo '" + out + ": " + src + " " + header + "' > " + out + ".d; " +
+		"printf '%s' \"$TIDY_OUTPUT\"; exit 1"
+	return []string{"sh", "-c", script}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "foo.cpp")
+	header := filepath.Join(dir, "foo.h")
+	for _, f := range []string{src, header} {
+		if err := os.WriteFile(f, []byte("// "+f), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	baselineFile := filepath.Join(dir, "tidy-baseline.txt")
+	if err := os.WriteFile(baselineFile, []byte("foo.cpp\tbugprone-use-after-move\tuse of a moved-from object\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TIDY_OUTPUT", tidyOutput)
+
+	out := filepath.Join(dir, "foo.tidy")
+	opts := options{
+		out:      out,
+		src:      src,
+		findings: out + ".findings",
+		cacheDir: filepath.Join(dir, "cache"),
+		baseline: []string{baselineFile},
+		command:  fakeTidy(dir, out, src, header),
+	}
+	runs := func() int {
+		data, _ := os.ReadFile(filepath.Join(dir, "runs"))
+		return strings.Count(string(data), "run")
+	}
+
+	// The only error is in the baseline.
+	var output strings.Builder
+	exitCode, err := run(opts, &output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+	if strings.Contains(output.String(), "bugprone-use-after-move") {
+		t.Errorf("expected the baselined finding not to be printed, got:\n%s", output.String())
+	}
+	findings, err := os.ReadFile(opts.findings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedFindings := "foo.cpp\t12\tbugprone-use-after-move\tuse of a moved-from object\tbaselined\n" +
+		"foo.h\t4\treadability-make-member-function-const\tfunction is not const\tnew\n"
+	if string(findings) != expectedFindings {
+		t.Errorf("expected findings:\n%s\ngot:\n%s", expectedFindings, findings)
+	}
+
+	// The result is cached.
+	os.Remove(out)
+	if _, err := run(opts, &output); err != nil {
+		t.Fatal(err)
+	}
+	if runs() != 1 {
+		t.Errorf("expected the cached result to be used, got %d runs", runs())
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("expected the cached result to restore %s: %s", out, err)
+	}
+
+	// A change to a header invalidates the cached result.
+	if err := os.WriteFile(header, []byte("// changed"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(opts, &output); err != nil {
+		t.Fatal(err)
+	}
+	if runs() != 2 {
+		t.Errorf("expected the command to run again, got %d runs", runs())
+	}
+
+	// Without the baseline the error fails the command.
+	opts.baseline = nil
+	exitCode, err = run(opts, &output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exitCode)
+	}
+}
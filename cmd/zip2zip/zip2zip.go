@@ -15,6 +15,7 @@
 package main
 
 import (
+	"encoding/binary"
 	"flag"
 	"fmt"
 	"io"
@@ -37,6 +38,7 @@ var (
 	sortGlobs = flag.Bool("s", false, "sort matches from each glob (defaults to the order from the input zip file)")
 	sortJava  = flag.Bool("j", false, "sort using jar ordering within each glob (META-INF/MANIFEST.MF first)")
 	setTime   = flag.Bool("t", false, "set timestamps to 2009-01-01 00:00:00")
+	setExtras = flag.Bool("e", false, "drop the extra fields with timestamps and sort the other extra fields by ID")
 
 	staticTime = time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC)
 
@@ -53,7 +55,7 @@ func init() {
 
 func main() {
 	flag.Usage = func() {
-		fmt.Fprintln(os.Stderr, "usage: zip2zip -i zipfile -o zipfile [-s|-j] [-t] [filespec]...")
+		fmt.Fprintln(os.Stderr, "usage: zip2zip -i zipfile -o zipfile [-s|-j] [-t] [-e] [filespec]...")
 		flag.PrintDefaults()
 		fmt.Fprintln(os.Stderr, "  filespec:")
 		fmt.Fprintln(os.Stderr, "    <name>")
@@ -97,7 +99,7 @@ func main() {
 		}
 	}()
 
-	if err := zip2zip(&reader.Reader, writer, *sortGlobs, *sortJava, *setTime,
+	if err := zip2zip(&reader.Reader, writer, *sortGlobs, *sortJava, *setTime, *setExtras,
 		flag.Args(), excludes, includes, uncompress); err != nil {
 
 		log.Fatal(err)
@@ -110,7 +112,7 @@ type pair struct {
 	uncompress bool
 }
 
-func zip2zip(reader *zip.Reader, writer *zip.Writer, sortOutput, sortJava, setTime bool,
+func zip2zip(reader *zip.Reader, writer *zip.Writer, sortOutput, sortJava, setTime, setExtras bool,
 	args []string, excludes, includes multiFlag, uncompresses []string) error {
 
 	matches := []pair{}
@@ -217,6 +219,9 @@ func zip2zip(reader *zip.Reader, writer *zip.Writer, sortOutput, sortJava, setTi
 		if setTime {
 			match.File.SetModTime(staticTime)
 		}
+		if setExtras {
+			match.File.Extra = normalizeExtras(match.File.Extra)
+		}
 		if match.uncompress && match.File.FileHeader.Method != zip.Store {
 			fh := match.File.FileHeader
 			fh.Name = match.newName
@@ -249,6 +254,44 @@ func zip2zip(reader *zip.Reader, writer *zip.Writer, sortOutput, sortJava, setTi
 	return nil
 }
 
+// The IDs of the extra fields that hold timestamps: the extended timestamp, NTFS and Info-ZIP Unix
+// extra fields.
+var timestampExtraIDs = map[uint16]bool{
+	0x5455: true,
+	0x000a: true,
+	0x5855: true,
+}
+
+// normalizeExtras returns the extra fields of a zip entry without the ones that hold timestamps,
+// and with the others sorted by ID, so that they don't depend on the time and the order they were
+// written in. A malformed field and the data after it are kept as they are, after the sorted fields.
+func normalizeExtras(extra []byte) []byte {
+	type field struct {
+		id   uint16
+		data []byte
+	}
+	var fields []field
+	rest := extra
+	for len(rest) >= 4 {
+		id := binary.LittleEndian.Uint16(rest)
+		size := int(binary.LittleEndian.Uint16(rest[2:]))
+		if len(rest) < 4+size {
+			break
+		}
+		if !timestampExtraIDs[id] {
+			fields = append(fields, field{id, rest[:4+size]})
+		}
+		rest = rest[4+size:]
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].id < fields[j].id })
+
+	var ret []byte
+	for _, f := range fields {
+		ret = append(ret, f.data...)
+	}
+	return append(ret, rest...)
+}
+
 func includeSplit(s string) (string, string) {
 	split := strings.SplitN(s, ":", 2)
 	if len(split) == 2 {
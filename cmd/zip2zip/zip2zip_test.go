@@ -437,7 +437,7 @@ func TestZip2Zip(t *testing.T) {
 			}
 
 			outputWriter := zip.NewWriter(outputBuf)
-			err = zip2zip(inputReader, outputWriter, testCase.sortGlobs, testCase.sortJava, false,
+			err = zip2zip(inputReader, outputWriter, testCase.sortGlobs, testCase.sortJava, false, false,
 				testCase.args, testCase.excludes, testCase.includes, testCase.uncompresses)
 			if errorString(testCase.err) != errorString(err) {
 				t.Fatalf("Unexpected error:\n got: %q\nwant: %q", errorString(err), errorString(testCase.err))
@@ -512,3 +512,38 @@ func TestConstantPartOfPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeExtras(t *testing.T) {
+	field := func(id uint16, data ...byte) []byte {
+		return append([]byte{byte(id), byte(id >> 8), byte(len(data)), 0}, data...)
+	}
+	join := func(fields ...[]byte) []byte {
+		return bytes.Join(fields, nil)
+	}
+
+	testCases := []struct {
+		name     string
+		in, want []byte
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "sorted and without timestamps",
+			in:   join(field(0xd935, 0, 0), field(0x5455, 1, 2, 3, 4, 5), field(0xcafe), field(0x000a, 1)),
+			want: join(field(0xcafe), field(0xd935, 0, 0)),
+		},
+		{
+			name: "malformed",
+			in:   join(field(0xd935), field(0xcafe), []byte{1, 2, 3}),
+			want: join(field(0xcafe), field(0xd935), []byte{1, 2, 3}),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := normalizeExtras(testCase.in); !bytes.Equal(got, testCase.want) {
+				t.Errorf("want %v, got %v", testCase.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etc
+
+// This file implements config_file, which installs a JSON, XML or text proto configuration file
+// into <partition>/etc/<relative_install_path> after validating it against a schema. Overlays,
+// from the module or from the ConfigFileOverlays product variable, are merged onto the file before
+// it is validated: JSON objects are merged recursively and a null value removes a key, and text
+// protos are merged with proto MergeFrom semantics.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/blueprint/proptools"
+
+	"android/soong/android"
+)
+
+const (
+	configFileJson      = "json"
+	configFileXml       = "xml"
+	configFileTextproto = "textproto"
+)
+
+var configFileFormats = map[string]string{
+	".json":      configFileJson,
+	".xml":       configFileXml,
+	".textproto": configFileTextproto,
+	".txtpb":     configFileTextproto,
+}
+
+type configFileProperties struct {
+	// Source file of the configuration file.
+	Src *string `android:"path"`
+
+	// Name of the installed file. Defaults to the file name of src.
+	Filename *string
+
+	// Subdirectory of <partition>/etc to install the file into.
+	Relative_install_path *string
+
+	// Format of the file: "json", "xml" or "textproto". Defaults to the format of the extension of
+	// src.
+	Format *string
+
+	// Schema the file is validated against: a JSON schema for json, an XSD or DTD for xml, or a
+	// proto descriptor set for textproto. Without a schema, json and xml files are only checked to
+	// be well-formed. Required for textproto.
+	Schema *string `android:"path"`
+
+	// Full name of the proto message of a textproto file, e.g. "android.foo.Config".
+	Message_type *string
+
+	// Files merged onto src in order, before the overlays of the product. Not supported for xml.
+	Overlays []string `android:"path"`
+
+	// Whether the file is installed. Default: true.
+	Installable *bool
+}
+
+type ConfigFile struct {
+	android.ModuleBase
+	android.DefaultableModuleBase
+
+	properties configFileProperties
+
+	outputFilePath android.OutputPath
+	installDirPath android.InstallPath
+}
+
+var _ PrebuiltEtcModule = (*ConfigFile)(nil)
+var _ android.OutputFileProducer = (*ConfigFile)(nil)
+
+func (c *ConfigFile) BaseDir() string {
+	return "etc"
+}
+
+func (c *ConfigFile) SubDir() string {
+	return proptools.String(c.properties.Relative_install_path)
+}
+
+func (c *ConfigFile) OutputFile() android.OutputPath {
+	return c.outputFilePath
+}
+
+func (c *ConfigFile) OutputFiles(tag string) (android.Paths, error) {
+	switch tag {
+	case "":
+		return android.Paths{c.outputFilePath}, nil
+	default:
+		return nil, fmt.Errorf("unsupported module reference tag %q", tag)
+	}
+}
+
+func (c *ConfigFile) Installable() bool {
+	return proptools.BoolDefault(c.properties.Installable, true)
+}
+
+// format returns the format of the file, from the format property or the extension of src.
+func (c *ConfigFile) format(ctx android.ModuleContext, src android.Path) string {
+	if c.properties.Format != nil {
+		format := *c.properties.Format
+		if format != configFileJson && format != configFileXml && format != configFileTextproto {
+			ctx.PropertyErrorf("format", `must be "json", "xml" or "textproto", got %q`, format)
+		}
+		return format
+	}
+	if format, ok := configFileFormats[src.Ext()]; ok {
+		return format
+	}
+	ctx.PropertyErrorf("format", "must be set for a src with extension %q", src.Ext())
+	return ""
+}
+
+func (c *ConfigFile) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	if c.properties.Src == nil {
+		ctx.PropertyErrorf("src", "missing source file")
+		return
+	}
+	src := android.PathForModuleSrc(ctx, *c.properties.Src)
+	format := c.format(ctx, src)
+	if format == "" {
+		return
+	}
+
+	var schema android.Path
+	if c.properties.Schema != nil {
+		schema = android.PathForModuleSrc(ctx, *c.properties.Schema)
+	}
+	switch format {
+	case configFileXml:
+		if schema != nil && schema.Ext() != ".xsd" && schema.Ext() != ".dtd" {
+			ctx.PropertyErrorf("schema", "must be an .xsd or .dtd file for xml, got %q", schema.Ext())
+		}
+	case configFileTextproto:
+		if schema == nil {
+			ctx.PropertyErrorf("schema", "a proto descriptor set is required for textproto")
+		}
+		if c.properties.Message_type == nil {
+			ctx.PropertyErrorf("message_type", "must be set for textproto")
+		}
+	}
+	if format != configFileTextproto && c.properties.Message_type != nil {
+		ctx.PropertyErrorf("message_type", "can only be set for textproto")
+	}
+
+	overlays := android.PathsForModuleSrc(ctx, c.properties.Overlays)
+	for _, overlay := range ctx.Config().ConfigFileOverlays(ctx.ModuleName()) {
+		overlays = append(overlays, android.PathForSource(ctx, overlay))
+	}
+	if len(overlays) > 0 && format == configFileXml {
+		ctx.ModuleErrorf("overlays are not supported for xml")
+	}
+	if ctx.Failed() {
+		return
+	}
+
+	if len(overlays) > 0 {
+		merged := android.PathForModuleOut(ctx, "merged", src.Base())
+		rule := android.NewRuleBuilder(pctx, ctx)
+		cmd := rule.Command().BuiltTool("config_file").
+			Text("merge").
+			FlagWithArg("--format ", format)
+		c.protoFlags(cmd, format, schema)
+		cmd.FlagWithOutput("--out ", merged).
+			Input(src).
+			Inputs(overlays)
+		rule.Build("config_file_merge", "merge config file "+ctx.ModuleName())
+		src = merged
+	}
+
+	filename := proptools.StringDefault(c.properties.Filename, src.Base())
+	if strings.Contains(filename, "/") {
+		ctx.PropertyErrorf("filename", "filename cannot contain separator '/'")
+		return
+	}
+	c.outputFilePath = android.PathForModuleOut(ctx, filename).OutputPath
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:       android.Cp,
+		Output:     c.outputFilePath,
+		Input:      src,
+		Validation: c.validate(ctx, format, src, schema),
+	})
+
+	if !c.Installable() {
+		c.SkipInstall()
+	}
+	c.installDirPath = android.PathForModuleInstall(ctx, c.BaseDir(), c.SubDir())
+	ctx.InstallFile(c.installDirPath, c.outputFilePath.Base(), c.outputFilePath)
+}
+
+// protoFlags adds the flags that describe the message of a textproto file.
+func (c *ConfigFile) protoFlags(cmd *android.RuleBuilderCommand, format string, schema android.Path) {
+	if format == configFileTextproto {
+		cmd.FlagWithInput("--descriptor_set ", schema).
+			FlagWithArg("--message_type ", proptools.String(c.properties.Message_type))
+	}
+}
+
+// validate returns a file created by a rule that checks the file against its schema.
+func (c *ConfigFile) validate(ctx android.ModuleContext, format string, src, schema android.Path) android.Path {
+	stamp := android.PathForModuleOut(ctx, "config_file", "validate.stamp")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	if format == configFileXml {
+		cmd := rule.Command().BuiltTool("xmllint").Flag("--noout")
+		if schema != nil && schema.Ext() == ".dtd" {
+			cmd.FlagWithInput("--dtdvalid ", schema)
+		} else if schema != nil {
+			cmd.FlagWithInput("--schema ", schema)
+		}
+		cmd.Input(src)
+		rule.Command().Text("touch").Output(stamp)
+	} else {
+		cmd := rule.Command().BuiltTool("config_file").
+			Text("validate").
+			FlagWithArg("--format ", format)
+		if format == configFileJson && schema != nil {
+			cmd.FlagWithInput("--schema ", schema)
+		}
+		c.protoFlags(cmd, format, schema)
+		cmd.FlagWithOutput("--stamp ", stamp).Input(src)
+	}
+	rule.Build("config_file_validate", "validate config file "+ctx.ModuleName())
+	return stamp
+}
+
+func (c *ConfigFile) AndroidMkEntries() []android.AndroidMkEntries {
+	return []android.AndroidMkEntries{{
+		Class:      "ETC",
+		OutputFile: android.OptionalPathForPath(c.outputFilePath),
+		ExtraEntries: []android.AndroidMkExtraEntriesFunc{
+			func(ctx android.AndroidMkExtraEntriesContext, entries *android.AndroidMkEntries) {
+				entries.SetString("LOCAL_MODULE_TAGS", "optional")
+				entries.SetString("LOCAL_MODULE_PATH", c.installDirPath.String())
+				entries.SetString("LOCAL_INSTALLED_MODULE_STEM", c.outputFilePath.Base())
+				entries.SetBoolIfTrue("LOCAL_UNINSTALLABLE_MODULE", !c.Installable())
+			},
+		},
+	}}
+}
+
+// config_file installs a JSON, XML or text proto configuration file in
+// <partition>/etc/<relative_install_path>, after merging its overlays onto it and validating it
+// against its schema.
+func ConfigFileFactory() android.Module {
+	module := &ConfigFile{}
+	module.AddProperties(&module.properties)
+	// This module is device-only
+	android.InitAndroidArchModule(module, android.DeviceSupported, android.MultilibFirst)
+	android.InitDefaultableModule(module)
+	return module
+}
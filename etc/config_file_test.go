@@ -0,0 +1,190 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etc
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+var prepareForConfigFileTest = android.GroupFixturePreparers(
+	prepareForPrebuiltEtcTest,
+	android.FixtureMergeMockFs(android.MockFS{
+		"config.json":         nil,
+		"config.schema.json":  nil,
+		"overlay.json":        nil,
+		"config.xml":          nil,
+		"config.xsd":          nil,
+		"config.textproto":    nil,
+		"config.desc":         nil,
+		"device/overlay.json": nil,
+	}),
+)
+
+func TestConfigFileJson(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		prepareForConfigFileTest,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.ConfigFileOverlays = map[string][]string{
+				"foo": {"device/overlay.json"},
+			}
+		}),
+	).RunTestWithBp(t, `
+		config_file {
+			name: "foo",
+			src: "config.json",
+			schema: "config.schema.json",
+			overlays: ["overlay.json"],
+			relative_install_path: "foo",
+		}
+	`)
+
+	foo := result.ModuleForTests("foo", "android_arm64_armv8-a")
+
+	merge := foo.Rule("config_file_merge")
+	android.AssertStringDoesContain(t, "merge command", merge.RuleParams.Command,
+		"merge --format json --out out/soong/.intermediates/foo/android_arm64_armv8-a/merged/config.json config.json overlay.json device/overlay.json")
+
+	validate := foo.Rule("config_file_validate")
+	android.AssertStringDoesContain(t, "validate command", validate.RuleParams.Command,
+		"validate --format json --schema config.schema.json --stamp out/soong/.intermediates/foo/android_arm64_armv8-a/config_file/validate.stamp out/soong/.intermediates/foo/android_arm64_armv8-a/merged/config.json")
+
+	output := foo.Output("config.json")
+	android.AssertPathRelativeToTopEquals(t, "input",
+		"out/soong/.intermediates/foo/android_arm64_armv8-a/merged/config.json", output.Input)
+	android.AssertPathRelativeToTopEquals(t, "validation",
+		"out/soong/.intermediates/foo/android_arm64_armv8-a/config_file/validate.stamp", output.Validation)
+
+	c := foo.Module().(*ConfigFile)
+	android.AssertPathRelativeToTopEquals(t, "install dir", "out/soong/target/product/test_device/system/etc/foo", c.installDirPath)
+}
+
+func TestConfigFileXml(t *testing.T) {
+	result := prepareForConfigFileTest.RunTestWithBp(t, `
+		config_file {
+			name: "foo",
+			src: "config.xml",
+			schema: "config.xsd",
+			filename: "foo.xml",
+		}
+	`)
+
+	foo := result.ModuleForTests("foo", "android_arm64_armv8-a")
+
+	validate := foo.Rule("config_file_validate")
+	android.AssertStringDoesContain(t, "validate command", validate.RuleParams.Command,
+		"xmllint --noout --schema config.xsd config.xml")
+
+	output := foo.Output("foo.xml")
+	android.AssertPathRelativeToTopEquals(t, "input", "config.xml", output.Input)
+	if foo.MaybeRule("config_file_merge").Rule != nil {
+		t.Errorf("expected no merge without overlays")
+	}
+}
+
+func TestConfigFileTextproto(t *testing.T) {
+	result := prepareForConfigFileTest.RunTestWithBp(t, `
+		config_file {
+			name: "foo",
+			src: "config.textproto",
+			schema: "config.desc",
+			message_type: "android.test.Config",
+		}
+	`)
+
+	foo := result.ModuleForTests("foo", "android_arm64_armv8-a")
+
+	validate := foo.Rule("config_file_validate")
+	android.AssertStringDoesContain(t, "validate command", validate.RuleParams.Command,
+		"validate --format textproto --descriptor_set config.desc --message_type android.test.Config")
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		bp   string
+		err  string
+	}{
+		{
+			name: "unknown format",
+			bp: `
+				src: "config.json",
+				format: "yaml",
+			`,
+			err: `format: must be "json", "xml" or "textproto", got "yaml"`,
+		},
+		{
+			name: "unknown extension",
+			bp: `
+				src: "config.desc",
+			`,
+			err: `format: must be set for a src with extension ".desc"`,
+		},
+		{
+			name: "xml schema",
+			bp: `
+				src: "config.xml",
+				schema: "config.schema.json",
+			`,
+			err: `schema: must be an .xsd or .dtd file for xml, got ".json"`,
+		},
+		{
+			name: "xml overlays",
+			bp: `
+				src: "config.xml",
+				overlays: ["config.xml"],
+			`,
+			err: `overlays are not supported for xml`,
+		},
+		{
+			name: "textproto without schema",
+			bp: `
+				src: "config.textproto",
+				message_type: "android.test.Config",
+			`,
+			err: `schema: a proto descriptor set is required for textproto`,
+		},
+		{
+			name: "textproto without message type",
+			bp: `
+				src: "config.textproto",
+				schema: "config.desc",
+			`,
+			err: `message_type: must be set for textproto`,
+		},
+		{
+			name: "message type for json",
+			bp: `
+				src: "config.json",
+				message_type: "android.test.Config",
+			`,
+			err: `message_type: can only be set for textproto`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			prepareForConfigFileTest.
+				ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(test.err)).
+				RunTestWithBp(t, `
+					config_file {
+						name: "foo",
+						`+test.bp+`
+					}
+				`)
+		})
+	}
+}
@@ -63,6 +63,7 @@ func RegisterPrebuiltEtcBuildComponents(ctx android.RegistrationContext) {
 	ctx.RegisterModuleType("prebuilt_firmware", PrebuiltFirmwareFactory)
 	ctx.RegisterModuleType("prebuilt_dsp", PrebuiltDSPFactory)
 	ctx.RegisterModuleType("prebuilt_rfsa", PrebuiltRFSAFactory)
+	ctx.RegisterModuleType("config_file", ConfigFileFactory)
 
 	ctx.RegisterModuleType("prebuilt_defaults", defaultsFactory)
 
@@ -437,6 +438,7 @@ func DefaultsFactory(props ...interface{}) android.Module {
 	module.AddProperties(
 		&prebuiltEtcProperties{},
 		&prebuiltSubdirProperties{},
+		&configFileProperties{},
 	)
 
 	android.InitDefaultsModule(module)
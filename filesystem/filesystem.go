@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"android/soong/android"
@@ -37,6 +38,8 @@ func registerBuildComponents(ctx android.RegistrationContext) {
 	ctx.RegisterModuleType("android_system_image", systemImageFactory)
 	ctx.RegisterModuleType("avb_add_hash_footer", avbAddHashFooterFactory)
 	ctx.RegisterModuleType("avb_gen_vbmeta_image", avbGenVbmetaImageFactory)
+	ctx.RegisterModuleType("host_tools_oci_image", hostToolsOciImageFactory)
+	ctx.RegisterModuleType("microdroid_payload", microdroidPayloadFactory)
 }
 
 type filesystem struct {
@@ -51,6 +54,9 @@ type filesystem struct {
 	// Function that filters PackagingSpecs returned by PackagingBase.GatherPackagingSpecs()
 	filterPackagingSpecs func(specs map[string]android.PackagingSpec)
 
+	// Function that builds validations of the image, which the installed image depends on
+	buildValidations func(ctx android.ModuleContext, image android.Path) android.Paths
+
 	output     android.OutputPath
 	installDir android.InstallPath
 
@@ -174,8 +180,58 @@ func (f *filesystem) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 		return
 	}
 
+	f.checkDlopenDeps(ctx)
+
+	var validations android.Paths
+	if f.buildValidations != nil {
+		validations = f.buildValidations(ctx, f.output)
+	}
+
 	f.installDir = android.PathForModuleInstall(ctx, "etc")
-	ctx.InstallFile(f.installDir, f.installFileName(), f.output)
+	ctx.InstallFile(f.installDir, f.installFileName(), f.output, validations...)
+}
+
+// checkDlopenDeps reports an error for each module in the image whose dlopen_deps aren't in the
+// image for the same architecture. The modules in the image are the deps of the filesystem and
+// their transitive install dependencies.
+func (f *filesystem) checkDlopenDeps(ctx android.ModuleContext) {
+	installed := make(map[string]map[android.ArchType]bool)
+	var modules []android.Module
+	ctx.WalkDeps(func(child, parent android.Module) bool {
+		tag := ctx.OtherModuleDependencyTag(child)
+		if parent == ctx.Module() {
+			if pi, ok := tag.(android.PackagingItem); !ok || !pi.IsPackagingItem() {
+				return false
+			}
+		} else if !android.IsInstallDepNeeded(tag) || child.IsHideFromMake() || child.IsSkipInstall() {
+			return false
+		}
+		name := android.RemoveOptionalPrebuiltPrefix(ctx.OtherModuleName(child))
+		if installed[name] == nil {
+			installed[name] = make(map[android.ArchType]bool)
+		}
+		installed[name][child.Target().Arch.ArchType] = true
+		modules = append(modules, child)
+		return true
+	})
+
+	reported := make(map[string]bool)
+	for _, m := range modules {
+		arch := m.Target().Arch.ArchType
+		for _, dep := range m.DlopenDepNames() {
+			if installed[dep][arch] || installed[dep][android.Common] {
+				continue
+			}
+			name := ctx.OtherModuleName(m)
+			key := name + "/" + arch.String() + "/" + dep
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			ctx.ModuleErrorf("%q (%s) loads %q at runtime, which is not in the image for %s",
+				name, arch, dep, arch)
+		}
+	}
 }
 
 // root zip will contain extra files/dirs that are not from the `deps` property.
@@ -362,6 +418,8 @@ func (f *filesystem) buildPropFile(ctx android.ModuleContext) (propFile android.
 	}
 	if timestamp := proptools.String(f.properties.Fake_timestamp); timestamp != "" {
 		addStr("timestamp", timestamp)
+	} else if epoch, ok := ctx.Config().SourceDateEpoch(); ok {
+		addStr("timestamp", strconv.FormatInt(epoch, 10))
 	}
 	if uuid := proptools.String(f.properties.Uuid); uuid != "" {
 		addStr("uuid", uuid)
@@ -282,3 +282,74 @@ func TestFileSystemWithCoverageVariants(t *testing.T) {
 		t.Error("prebuilt should use cov variant of filesystem")
 	}
 }
+
+func TestFileSystemChecksDlopenDeps(t *testing.T) {
+	fixture.RunTestWithBp(t, `
+		android_filesystem {
+			name: "myfilesystem",
+			deps: [
+				"bin",
+				"libplugin",
+			],
+		}
+
+		cc_binary {
+			name: "bin",
+			shared_libs: ["libfoo"],
+			dlopen_deps: ["libplugin"],
+			stl: "none",
+		}
+
+		cc_library {
+			name: "libfoo",
+			dlopen_deps: ["libbar"],
+			runtime_libs: ["libbar"],
+			stl: "none",
+		}
+
+		cc_library {
+			name: "libbar",
+			stl: "none",
+		}
+
+		cc_library {
+			name: "libplugin",
+			stl: "none",
+		}
+	`)
+}
+
+func TestFileSystemMissingDlopenDeps(t *testing.T) {
+	fixture.ExtendWithErrorHandler(android.FixtureExpectsAllErrorsToMatchAPattern([]string{
+		`module "myfilesystem".*: "bin" \(arm64\) loads "libplugin" at runtime, which is not in the image for arm64`,
+		`module "myfilesystem".*: "libfoo" \(arm64\) loads "libbar" at runtime, which is not in the image for arm64`,
+	})).RunTestWithBp(t, `
+		android_filesystem {
+			name: "myfilesystem",
+			deps: ["bin"],
+		}
+
+		cc_binary {
+			name: "bin",
+			shared_libs: ["libfoo"],
+			dlopen_deps: ["libplugin"],
+			stl: "none",
+		}
+
+		cc_library {
+			name: "libfoo",
+			dlopen_deps: ["libbar"],
+			stl: "none",
+		}
+
+		cc_library {
+			name: "libbar",
+			stl: "none",
+		}
+
+		cc_library {
+			name: "libplugin",
+			stl: "none",
+		}
+	`)
+}
@@ -0,0 +1,163 @@
+// Copyright (C) 2026 The Android Open Source Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filesystem
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+type microdroidPayload struct {
+	filesystem
+
+	properties microdroidPayloadProperties
+}
+
+type microdroidPayloadProperties struct {
+	// Path to the VM payload config, which is placed at etc/vm_config.json. It is validated at
+	// build time: it must have a task to run, and list exactly the apexes of the payload.
+	Vm_config *string `android:"path"`
+
+	// Apex modules of the payload, which are placed at apex/<module name>.apex. Their signatures
+	// are verified with apksigner at build time.
+	Apexes []string
+
+	// android_filesystem module of the initial ramdisk of the VM, which is placed at
+	// boot/initrd.img.
+	Ramdisk *string
+}
+
+type microdroidPayloadDep struct {
+	blueprint.BaseDependencyTag
+	kind string
+}
+
+var (
+	microdroidPayloadApexDep    = microdroidPayloadDep{kind: "apex"}
+	microdroidPayloadRamdiskDep = microdroidPayloadDep{kind: "ramdisk"}
+)
+
+// microdroid_payload is a specialization of android_filesystem for the payload of a Microdroid
+// virtual machine. In addition to the deps of the filesystem, the payload has apexes, the VM
+// payload config and an initial ramdisk. The config and the signatures of the apexes are
+// validated when the payload is built, and so is the signature of the image when use_avb is set.
+func microdroidPayloadFactory() android.Module {
+	module := &microdroidPayload{}
+	module.AddProperties(&module.properties)
+	module.filesystem.buildExtraFiles = module.buildExtraFiles
+	module.filesystem.buildValidations = module.buildValidations
+	initFilesystemModule(&module.filesystem)
+	return module
+}
+
+func (m *microdroidPayload) DepsMutator(ctx android.BottomUpMutatorContext) {
+	m.filesystem.DepsMutator(ctx)
+	// Apexes have an apex variation in addition to the os and arch variations of the payload.
+	ctx.AddFarVariationDependencies(ctx.Config().AndroidCommonTarget.Variations(),
+		microdroidPayloadApexDep, m.properties.Apexes...)
+	if ramdisk := proptools.String(m.properties.Ramdisk); ramdisk != "" {
+		ctx.AddDependency(ctx.Module(), microdroidPayloadRamdiskDep, ramdisk)
+	}
+}
+
+func (m *microdroidPayload) buildExtraFiles(ctx android.ModuleContext, root android.OutputPath) android.OutputPaths {
+	var extraFiles android.OutputPaths
+	apexes := make(map[string]android.Path)
+	ctx.VisitDirectDepsWithTag(microdroidPayloadApexDep, func(dep android.Module) {
+		name := ctx.OtherModuleName(dep)
+		producer, ok := dep.(android.OutputFileProducer)
+		if !ok {
+			ctx.PropertyErrorf("apexes", "%q is not an apex module", name)
+			return
+		}
+		outputs, err := producer.OutputFiles("")
+		if err != nil || len(outputs) != 1 {
+			ctx.PropertyErrorf("apexes", "%q must have exactly one output file", name)
+			return
+		}
+		apexes[name] = outputs[0]
+	})
+	if ctx.Failed() {
+		return nil
+	}
+
+	builder := android.NewRuleBuilder(pctx, ctx)
+	for _, name := range android.SortedStringKeys(apexes) {
+		out := root.Join(ctx, "apex", name+".apex")
+		builder.Command().Text("mkdir -p").Text(filepath.Dir(out.String()))
+		builder.Command().Text("cp -f").Input(apexes[name]).Output(out)
+		extraFiles = append(extraFiles, out)
+	}
+
+	if ramdiskName := proptools.String(m.properties.Ramdisk); ramdiskName != "" {
+		ramdisk := ctx.GetDirectDepWithTag(ramdiskName, microdroidPayloadRamdiskDep)
+		if fs, ok := ramdisk.(Filesystem); ok {
+			out := root.Join(ctx, "boot", "initrd.img")
+			builder.Command().Text("mkdir -p").Text(filepath.Dir(out.String()))
+			builder.Command().Text("cp -f").Input(fs.OutputPath()).Output(out)
+			extraFiles = append(extraFiles, out)
+		} else {
+			ctx.PropertyErrorf("ramdisk", "%q is not android_filesystem module", ramdiskName)
+		}
+	}
+	builder.Build("microdroid_payload_files", fmt.Sprintf("Copying payload files of %s", ctx.ModuleName()))
+
+	if m.properties.Vm_config == nil {
+		ctx.PropertyErrorf("vm_config", "is required")
+		return extraFiles
+	}
+	extraFiles = append(extraFiles, m.checkVmConfig(ctx, root, apexes))
+	return extraFiles
+}
+
+// checkVmConfig validates the VM payload config and the signatures of the apexes of the payload,
+// and returns the config placed under root. The validation fails the build of the image.
+func (m *microdroidPayload) checkVmConfig(ctx android.ModuleContext, root android.OutputPath, apexes map[string]android.Path) android.OutputPath {
+	out := root.Join(ctx, "etc", "vm_config.json")
+	builder := android.NewRuleBuilder(pctx, ctx)
+	cmd := builder.Command().
+		BuiltTool("check_microdroid_payload").
+		FlagWithInput("--vm-config ", android.PathForModuleSrc(ctx, proptools.String(m.properties.Vm_config))).
+		FlagWithInput("--apksigner ", ctx.Config().HostToolPath(ctx, "apksigner")).
+		ImplicitTool(ctx.Config().HostJavaToolPath(ctx, "apksigner.jar"))
+	for _, name := range android.SortedStringKeys(apexes) {
+		cmd.Flag("--apex").Text(name + "=" + apexes[name].String()).Implicit(apexes[name])
+	}
+	cmd.FlagWithOutput("-o ", out)
+	builder.Build("check_microdroid_payload", fmt.Sprintf("Checking payload of %s", ctx.ModuleName()))
+	return out
+}
+
+// buildValidations verifies the avb signature of the image when it is signed.
+func (m *microdroidPayload) buildValidations(ctx android.ModuleContext, image android.Path) android.Paths {
+	if !proptools.Bool(m.filesystem.properties.Use_avb) {
+		return nil
+	}
+	timestamp := android.PathForModuleOut(ctx, "verify_image.timestamp")
+	builder := android.NewRuleBuilder(pctx, ctx)
+	builder.Command().
+		BuiltTool("avbtool").
+		Text("verify_image").
+		FlagWithInput("--image ", image).
+		FlagWithInput("--key ", android.PathForModuleSrc(ctx, proptools.String(m.filesystem.properties.Avb_private_key)))
+	builder.Command().Text("touch").Output(timestamp)
+	builder.Build("verify_microdroid_payload", fmt.Sprintf("Verifying signature of %s", ctx.ModuleName()))
+	return android.Paths{timestamp}
+}
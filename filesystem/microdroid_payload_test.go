@@ -0,0 +1,102 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filesystem
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+// testApex stands in for an apex module, as the apex package depends on this one.
+type testApex struct {
+	android.ModuleBase
+}
+
+func testApexFactory() android.Module {
+	module := &testApex{}
+	android.InitAndroidMultiTargetsArchModule(module, android.DeviceSupported, android.MultilibCommon)
+	return module
+}
+
+func (a *testApex) GenerateAndroidBuildActions(ctx android.ModuleContext) {}
+
+func (a *testApex) OutputFiles(tag string) (android.Paths, error) {
+	return android.Paths{android.PathForTesting(a.Name() + ".apex")}, nil
+}
+
+var prepareForTestWithMicrodroidPayload = android.GroupFixturePreparers(
+	fixture,
+	android.FixtureRegisterWithContext(func(ctx android.RegistrationContext) {
+		ctx.RegisterModuleType("test_apex", testApexFactory)
+	}),
+	android.FixtureMergeMockFs(android.MockFS{
+		"vm_config.json": nil,
+		"payload.pem":    nil,
+	}),
+)
+
+func TestMicrodroidPayload(t *testing.T) {
+	result := prepareForTestWithMicrodroidPayload.RunTestWithBp(t, `
+		microdroid_payload {
+			name: "payload",
+			apexes: ["com.android.foo"],
+			vm_config: "vm_config.json",
+			ramdisk: "ramdisk",
+			use_avb: true,
+			avb_private_key: "payload.pem",
+		}
+
+		test_apex {
+			name: "com.android.foo",
+		}
+
+		android_filesystem {
+			name: "ramdisk",
+			type: "compressed_cpio",
+		}
+	`)
+
+	module := result.ModuleForTests("payload", "android_common")
+
+	// The apexes and the ramdisk are copied by a single rule.
+	apex := module.Output("root-extra/apex/com.android.foo.apex")
+	android.AssertStringListContains(t, "apex", apex.RelativeToTop().Implicits.Strings(), "com.android.foo.apex")
+	ramdisk := module.Output("root-extra/boot/initrd.img")
+	android.AssertStringDoesContain(t, "ramdisk", ramdisk.RuleParams.Command, "ramdisk.img")
+
+	check := module.Output("root-extra/etc/vm_config.json").RuleParams.Command
+	android.AssertStringDoesContain(t, "check_microdroid_payload command", check,
+		"--apex com.android.foo=com.android.foo.apex")
+	android.AssertStringDoesContain(t, "check_microdroid_payload command", check, "--apksigner ")
+
+	// The installed image depends on the verification of its signature.
+	verify := module.Output("verify_image.timestamp")
+	android.AssertStringDoesContain(t, "avbtool command", verify.RuleParams.Command, "verify_image")
+	payload := module.Module().(*microdroidPayload)
+	install := module.Output(payload.installDir.String() + "/" + payload.installFileName())
+	android.AssertStringListContains(t, "install implicits", install.Implicits.Strings(), verify.Output.String())
+}
+
+func TestMicrodroidPayloadRequiresVmConfig(t *testing.T) {
+	prepareForTestWithMicrodroidPayload.
+		ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+			`vm_config: is required`)).
+		RunTestWithBp(t, `
+			microdroid_payload {
+				name: "payload",
+			}
+		`)
+}
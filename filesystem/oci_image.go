@@ -0,0 +1,180 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filesystem
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+type hostToolsOciImage struct {
+	android.ModuleBase
+	android.PackagingBase
+
+	properties hostToolsOciImageProperties
+
+	output     android.OutputPath
+	installDir android.InstallPath
+}
+
+type hostToolsOciImageProperties struct {
+	// Directory of the image under which the host tools are placed, like they are installed
+	// under out/host/linux-x86. Default is "opt/<module name>".
+	Root *string
+
+	// Environment variables of the image, as KEY=VALUE. By default, PATH is set with the bin
+	// directory under root first.
+	Env []string
+
+	// Path relative to root of the binary that runs when a container is started from the image,
+	// e.g. "bin/aapt2".
+	Entrypoint *string
+
+	// Reference name of the image in the layout. Default is "latest".
+	Tag *string
+
+	// Zip file with a root file system, e.g. of a distroless base image, that is extracted at the
+	// root of the image as its first layer. It provides the C library and the dynamic loader of the
+	// host tools, which can't run without them. The image is only built if the dynamic loader of
+	// the entrypoint is in one of its layers.
+	Base_layer *string `android:"path"`
+}
+
+// host_tools_oci_image packages host binaries and their runtime dependencies, e.g. shared
+// libraries, into a tarball of an OCI image layout that container tools can load directly. The
+// host binaries are dynamically linked against the C library of the host, which comes from the
+// base_layer of the image. The image is deterministic: its digest only changes when the packaged
+// files change.
+func hostToolsOciImageFactory() android.Module {
+	module := &hostToolsOciImage{}
+	module.AddProperties(&module.properties)
+	android.InitPackageModule(module)
+	android.InitAndroidMultiTargetsArchModule(module, android.HostSupported, android.MultilibCommon)
+	return module
+}
+
+var hostToolsDependencyTag = struct {
+	blueprint.BaseDependencyTag
+	android.InstallAlwaysNeededDependencyTag
+	android.PackagingItemAlwaysDepTag
+}{}
+
+func (i *hostToolsOciImage) DepsMutator(ctx android.BottomUpMutatorContext) {
+	i.AddDeps(ctx, hostToolsDependencyTag)
+}
+
+func (i *hostToolsOciImage) installFileName() string {
+	return i.BaseModuleName() + ".tar"
+}
+
+// ociArch returns the OCI name of an architecture.
+func ociArch(arch android.ArchType) string {
+	switch arch {
+	case android.X86_64:
+		return "amd64"
+	case android.X86:
+		return "386"
+	case android.Arm64:
+		return "arm64"
+	case android.Arm:
+		return "arm"
+	case android.Riscv64:
+		return "riscv64"
+	}
+	return arch.String()
+}
+
+func (i *hostToolsOciImage) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	// OCI images run on Linux only.
+	if !ctx.Os().Linux() {
+		return
+	}
+	if len(ctx.MultiTargets()) == 0 {
+		ctx.ModuleErrorf("no host target")
+		return
+	}
+
+	root := proptools.StringDefault(i.properties.Root, filepath.Join("opt", ctx.ModuleName()))
+	if filepath.IsAbs(root) || filepath.Clean(root) != root || root == ".." || strings.HasPrefix(root, "../") {
+		ctx.PropertyErrorf("root", "must be a clean relative path, got %q", root)
+		return
+	}
+
+	layer := android.PathForModuleOut(ctx, "layer.zip").OutputPath
+	i.CopyDepsToZip(ctx, i.GatherPackagingSpecs(ctx), layer)
+
+	env := i.properties.Env
+	if i.properties.Env == nil {
+		env = []string{fmt.Sprintf("PATH=/%s/bin:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin", root)}
+	}
+
+	i.output = android.PathForModuleOut(ctx, i.installFileName()).OutputPath
+	builder := android.NewRuleBuilder(pctx, ctx)
+	cmd := builder.Command().
+		BuiltTool("build_oci_image").
+		FlagWithArg("-arch ", ociArch(ctx.MultiTargets()[0].Arch.ArchType)).
+		FlagWithArg("-os ", "linux").
+		FlagWithArg("-tag ", proptools.StringDefault(i.properties.Tag, "latest")).
+		FlagWithArg("-root ", root).
+		FlagForEachArg("-env ", proptools.ShellEscapeList(env))
+	if baseLayer := proptools.String(i.properties.Base_layer); baseLayer != "" {
+		cmd.FlagWithInput("-base_layer ", android.PathForModuleSrc(ctx, baseLayer))
+	}
+	if entrypoint := proptools.String(i.properties.Entrypoint); entrypoint != "" {
+		cmd.FlagWithArg("-entrypoint ", "/"+filepath.Join(root, entrypoint))
+	}
+	cmd.FlagWithInput("-layer ", layer).
+		FlagWithOutput("-o ", i.output)
+	builder.Build("build_oci_image", fmt.Sprintf("Building OCI image %s", i.installFileName()))
+
+	i.installDir = android.PathForModuleInstall(ctx, "oci")
+	ctx.InstallFile(i.installDir, i.installFileName(), i.output)
+}
+
+var _ android.AndroidMkEntriesProvider = (*hostToolsOciImage)(nil)
+
+// Implements android.AndroidMkEntriesProvider
+func (i *hostToolsOciImage) AndroidMkEntries() []android.AndroidMkEntries {
+	if i.output.String() == "" {
+		return []android.AndroidMkEntries{{Disabled: true}}
+	}
+	return []android.AndroidMkEntries{android.AndroidMkEntries{
+		Class:      "ETC",
+		OutputFile: android.OptionalPathForPath(i.output),
+		DistFiles:  android.MakeDefaultDistFiles(i.output),
+		ExtraEntries: []android.AndroidMkExtraEntriesFunc{
+			func(ctx android.AndroidMkExtraEntriesContext, entries *android.AndroidMkEntries) {
+				entries.SetString("LOCAL_MODULE_PATH", i.installDir.String())
+				entries.SetString("LOCAL_INSTALLED_MODULE_STEM", i.installFileName())
+			},
+		},
+	}}
+}
+
+var _ android.OutputFileProducer = (*hostToolsOciImage)(nil)
+
+// Implements android.OutputFileProducer
+func (i *hostToolsOciImage) OutputFiles(tag string) (android.Paths, error) {
+	if tag == "" {
+		return []android.Path{i.output}, nil
+	}
+	return nil, fmt.Errorf("unsupported module reference tag %q", tag)
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filesystem
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestHostToolsOciImage(t *testing.T) {
+	result := fixture.RunTestWithBp(t, `
+		host_tools_oci_image {
+			name: "tools",
+			deps: ["foo"],
+			entrypoint: "bin/foo",
+		}
+
+		cc_binary_host {
+			name: "foo",
+			shared_libs: ["libbar"],
+		}
+
+		cc_library_host_shared {
+			name: "libbar",
+		}
+	`)
+
+	image := result.ModuleForTests("tools", result.Config.BuildOS.String()+"_common")
+
+	// The binary is packaged with its shared libraries.
+	layer := image.Output("layer.zip")
+	android.AssertStringDoesContain(t, "layer", layer.RuleParams.Command, "bin/foo")
+	android.AssertStringDoesContain(t, "layer", layer.RuleParams.Command, "lib64/libbar.so")
+
+	command := image.Output("tools.tar").RuleParams.Command
+	for _, flag := range []string{
+		"-arch amd64",
+		"-root opt/tools",
+		"-env 'PATH=/opt/tools/bin:",
+		"-entrypoint /opt/tools/bin/foo",
+		"-tag latest",
+	} {
+		android.AssertStringDoesContain(t, "build_oci_image command", command, flag)
+	}
+}
+
+func TestHostToolsOciImageBaseLayer(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		fixture,
+		android.FixtureAddFile("base/rootfs.zip", nil),
+	).RunTestWithBp(t, `
+		host_tools_oci_image {
+			name: "tools",
+			deps: ["foo"],
+			entrypoint: "bin/foo",
+			base_layer: "base/rootfs.zip",
+		}
+
+		cc_binary_host {
+			name: "foo",
+		}
+	`)
+
+	image := result.ModuleForTests("tools", result.Config.BuildOS.String()+"_common").Output("tools.tar")
+	android.AssertStringDoesContain(t, "build_oci_image command", image.RuleParams.Command,
+		"-base_layer base/rootfs.zip")
+	android.AssertStringListContains(t, "inputs", image.Implicits.Strings(), "base/rootfs.zip")
+}
+
+func TestHostToolsOciImageRoot(t *testing.T) {
+	fixture.ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(
+		`root: must be a clean relative path, got "/usr"`)).
+		RunTestWithBp(t, `
+			host_tools_oci_image {
+				name: "tools",
+				root: "/usr",
+			}
+		`)
+}
@@ -451,8 +451,10 @@ func (g *Module) generateCommonBuildActions(ctx android.ModuleContext) {
 
 		manifestPath := android.PathForModuleOut(ctx, manifestName)
 
-		// Use a RuleBuilder to create a rule that runs the command inside an sbox sandbox.
-		rule := android.NewRuleBuilder(pctx, ctx).Sbox(task.genDir, manifestPath).SandboxTools()
+		// Use a RuleBuilder to create a rule that runs the command inside an sbox sandbox. The
+		// command can only use the declared tools and inputs of the genrule, so its outputs can
+		// come from the action cache.
+		rule := android.NewRuleBuilder(pctx, ctx).Sbox(task.genDir, manifestPath).SandboxTools().ActionCache()
 		cmd := rule.Command()
 
 		for _, out := range task.out {
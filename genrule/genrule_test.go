@@ -754,6 +754,27 @@ func TestGenruleAllowMissingDependencies(t *testing.T) {
 	}
 }
 
+func TestGenruleActionCache(t *testing.T) {
+	bp := `
+		genrule {
+			name: "gen",
+			srcs: ["in1"],
+			out: ["out"],
+			cmd: "cp $(in) $(out)",
+		}
+	`
+	result := android.GroupFixturePreparers(
+		prepareForGenRuleTest,
+		android.FixtureMergeEnv(map[string]string{"SOONG_ACTION_CACHE_DIR": "/tmp/action_cache"}),
+	).RunTestWithBp(t, testGenruleBp()+bp)
+
+	command := result.ModuleForTests("gen", "").Output("out").RuleParams.Command
+	android.AssertStringDoesContain(t, "action cache", command, "bin/action_cache --cache_dir /tmp/action_cache ")
+	android.AssertStringDoesContain(t, "sbox manifest", command,
+		"--normalized_input out/soong/.intermediates/gen/genrule.sbox.textproto ")
+	android.AssertStringDoesContain(t, "output", command, "--output out/soong/.intermediates/gen/gen/out ")
+}
+
 func TestGenruleOutputFiles(t *testing.T) {
 	bp := `
 				genrule {
@@ -35,6 +35,7 @@ type AndroidLibraryDependency interface {
 	ExportedStaticPackages() android.Paths
 	ExportedManifests() android.Paths
 	ExportedAssets() android.OptionalPath
+	ExportedRTxt() android.Path
 	SetRROEnforcedForDependent(enforce bool)
 	IsRROEnforced(ctx android.BaseModuleContext) bool
 }
@@ -113,10 +114,14 @@ type aapt struct {
 	hasNoCode               bool
 	LoggingParent           string
 	resourceFiles           android.Paths
+	resourceDirs            []globbedResourceDir
 
 	splitNames []string
 	splits     []split
 
+	// Resource directories of the product's resource overlay of the app.
+	appResourceOverlayDirs android.Paths
+
 	aaptProperties aaptProperties
 }
 
@@ -154,6 +159,10 @@ func (a *aapt) ExportedAssets() android.OptionalPath {
 	return a.assetPackage
 }
 
+func (a *aapt) ExportedRTxt() android.Path {
+	return a.rTxt
+}
+
 func (a *aapt) SetRROEnforcedForDependent(enforce bool) {
 	a.aaptProperties.RROEnforcedForDependent = enforce
 }
@@ -193,6 +202,12 @@ func (a *aapt) aapt2Flags(ctx android.ModuleContext, sdkContext android.SdkConte
 		rroDirs = append(rroDirs, resRRODirs...)
 	}
 
+	if len(a.appResourceOverlayDirs) > 0 {
+		appOverlayDirs, appOverlayCheckFile := a.appResourceOverlay(ctx, resDirs)
+		overlayDirs = append(overlayDirs, appOverlayDirs...)
+		linkDeps = append(linkDeps, appOverlayCheckFile)
+	}
+
 	var assetDeps android.Paths
 	for i, dir := range assetDirs {
 		// Add a dependency on every file in the asset directory.  This ensures the aapt2
@@ -349,6 +364,7 @@ func (a *aapt) buildActions(ctx android.ModuleContext, sdkContext android.SdkCon
 	// This file isn't used by Soong, but is generated for exporting
 	extraPackages := android.PathForModuleOut(ctx, "extra_packages")
 
+	a.resourceDirs = resDirs
 	var compiledResDirs []android.Paths
 	for _, dir := range resDirs {
 		a.resourceFiles = append(a.resourceFiles, dir.files...)
@@ -622,6 +638,7 @@ func AndroidLibraryFactory() android.Module {
 
 	module.androidLibraryProperties.BuildAAR = true
 	module.Module.linter.library = true
+	module.Module.initBaselineProfiles(false)
 
 	android.InitApexModule(module)
 	InitJavaModule(module, android.DeviceSupported)
@@ -678,6 +695,7 @@ type AARImport struct {
 	proguardFlags         android.WritablePath
 	exportPackage         android.WritablePath
 	extraAaptPackagesFile android.WritablePath
+	rTxt                  android.WritablePath
 	manifest              android.WritablePath
 	assetsPackage         android.WritablePath
 
@@ -755,6 +773,10 @@ func (a *AARImport) ExportedManifests() android.Paths {
 	return android.Paths{a.manifest}
 }
 
+func (a *AARImport) ExportedRTxt() android.Path {
+	return a.rTxt
+}
+
 func (a *AARImport) ExportedAssets() android.OptionalPath {
 	return android.OptionalPathForPath(a.assetsPackage)
 }
@@ -877,7 +899,7 @@ func (a *AARImport) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 	// the subdir "android" is required to be filtered by package names
 	srcJar := android.PathForModuleGen(ctx, "android", "R.srcjar")
 	proguardOptionsFile := android.PathForModuleGen(ctx, "proguard.options")
-	rTxt := android.PathForModuleOut(ctx, "R.txt")
+	a.rTxt = android.PathForModuleOut(ctx, "R.txt")
 	a.extraAaptPackagesFile = android.PathForModuleOut(ctx, "extra_packages")
 
 	var linkDeps android.Paths
@@ -902,7 +924,7 @@ func (a *AARImport) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 
 	overlayRes := append(android.Paths{flata}, transitiveStaticLibs...)
 
-	aapt2Link(ctx, a.exportPackage, srcJar, proguardOptionsFile, rTxt, a.extraAaptPackagesFile,
+	aapt2Link(ctx, a.exportPackage, srcJar, proguardOptionsFile, a.rTxt, a.extraAaptPackagesFile,
 		linkFlags, linkDeps, nil, overlayRes, transitiveAssets, nil)
 
 	// Merge this import's assets with its dependencies' assets (if there are any).
@@ -129,6 +129,17 @@ type overridableAppProperties struct {
 	// For overriding the --rotation-min-sdk-version property of apksig
 	RotationMinSdkVersion *string
 
+	// Certificates the app was previously signed with, oldest first, as names of certificates in
+	// the default certificate directory or android_app_certificate modules in the form ":module".
+	// A signing certificate lineage that rotates through them to the app's certificate is
+	// generated for APK Signature Scheme v3. Can't be set together with lineage.
+	Previous_certificates []string
+
+	// If true, the rotated signing certificate is also used on development releases of
+	// rotationMinSdkVersion, using APK Signature Scheme v3.1. Requires lineage or
+	// previous_certificates.
+	Rotation_targets_dev_release *bool
+
 	// the package name of this app. The package name in the manifest file is used if one was not given.
 	Package_name *string
 
@@ -158,6 +169,10 @@ type AndroidApp struct {
 
 	overridableAppProperties overridableAppProperties
 
+	sizeBaselineProperties android.SizeBaselineProperties
+
+	appcompat
+
 	jniLibs                  []jniLib
 	installPathForJNISymbols android.Path
 	embeddedJniLibs          bool
@@ -179,6 +194,9 @@ type AndroidApp struct {
 	android.ApexBundleDepsInfo
 
 	javaApiUsedByOutputFile android.ModuleOutPath
+
+	// Names of the runtime resource overlays of the product's resource overlay of the app.
+	appRuntimeResourceOverlays []string
 }
 
 func (a *AndroidApp) IsInstallable() bool {
@@ -261,6 +279,8 @@ func (a *AndroidApp) DepsMutator(ctx android.BottomUpMutatorContext) {
 	}
 
 	a.usesLibrary.deps(ctx, sdkDep.hasFrameworkLibs())
+
+	a.appResourceOverlayDeps(ctx)
 }
 
 func (a *AndroidApp) OverridablePropertiesDepsMutator(ctx android.BottomUpMutatorContext) {
@@ -278,6 +298,8 @@ func (a *AndroidApp) OverridablePropertiesDepsMutator(ctx android.BottomUpMutato
 				`must be names of android_app_certificate modules in the form ":module"`)
 		}
 	}
+
+	a.previousCertificateDeps(ctx)
 }
 
 func (a *AndroidTestHelperApp) GenerateAndroidBuildActions(ctx android.ModuleContext) {
@@ -443,6 +465,7 @@ func (a *AndroidApp) aaptBuildActions(ctx android.ModuleContext) {
 	if a.Updatable() {
 		a.aapt.defaultManifestVersion = android.DefaultUpdatableModuleVersion
 	}
+	a.setAppResourceOverlay(ctx)
 	a.aapt.buildActions(ctx, android.SdkContext(a), a.classLoaderContexts,
 		a.usesLibraryProperties.Exclude_uses_libs, a.enforceDefaultTargetSdkVersion(), aaptLinkFlags...)
 
@@ -643,6 +666,7 @@ func (a *AndroidApp) generateAndroidBuildActions(ctx android.ModuleContext) {
 
 	// Process all building blocks, from AAPT to certificates.
 	a.aaptBuildActions(ctx)
+	resourceDuplicatesBuildActions(ctx, &a.aapt)
 
 	// The decision to enforce <uses-library> checks is made before adding implicit SDK libraries.
 	a.usesLibrary.freezeEnforceUsesLibraries()
@@ -687,14 +711,12 @@ func (a *AndroidApp) generateAndroidBuildActions(ctx android.ModuleContext) {
 	if v4SigningRequested {
 		v4SignatureFile = android.PathForModuleOut(ctx, a.installApkName+".apk.idsig")
 	}
-	var lineageFile android.Path
-	if lineage := String(a.overridableAppProperties.Lineage); lineage != "" {
-		lineageFile = android.PathForModuleSrc(ctx, lineage)
-	}
-	rotationMinSdkVersion := String(a.overridableAppProperties.RotationMinSdkVersion)
+	signingLineage := a.signingLineage(ctx)
 
-	CreateAndSignAppPackage(ctx, packageFile, a.exportPackage, jniJarFile, dexJarFile, certificates, apkDeps, v4SignatureFile, lineageFile, rotationMinSdkVersion, Bool(a.dexProperties.Optimize.Shrink_resources))
+	apkDexJarFile := a.embedBaselineProfile(ctx, dexJarFile)
+	CreateAndSignAppPackage(ctx, packageFile, a.exportPackage, jniJarFile, apkDexJarFile, certificates, apkDeps, v4SignatureFile, signingLineage, Bool(a.dexProperties.Optimize.Shrink_resources))
 	a.outputFile = packageFile
+	sizeBaselineCheck := android.CheckSizeBaseline(ctx, &a.sizeBaselineProperties, packageFile)
 	if v4SigningRequested {
 		a.extraOutputFiles = append(a.extraOutputFiles, v4SignatureFile)
 	}
@@ -722,7 +744,7 @@ func (a *AndroidApp) generateAndroidBuildActions(ctx android.ModuleContext) {
 		if v4SigningRequested {
 			v4SignatureFile = android.PathForModuleOut(ctx, a.installApkName+"_"+split.suffix+".apk.idsig")
 		}
-		CreateAndSignAppPackage(ctx, packageFile, split.path, nil, nil, certificates, apkDeps, v4SignatureFile, lineageFile, rotationMinSdkVersion, false)
+		CreateAndSignAppPackage(ctx, packageFile, split.path, nil, nil, certificates, apkDeps, v4SignatureFile, signingLineage, false)
 		a.extraOutputFiles = append(a.extraOutputFiles, packageFile)
 		if v4SigningRequested {
 			a.extraOutputFiles = append(a.extraOutputFiles, v4SignatureFile)
@@ -745,7 +767,27 @@ func (a *AndroidApp) generateAndroidBuildActions(ctx android.ModuleContext) {
 			installed := ctx.InstallFile(a.installDir, extra.Base(), extra)
 			extraInstalledPaths = append(extraInstalledPaths, installed)
 		}
+		if sizeBaselineCheck != nil {
+			extraInstalledPaths = append(extraInstalledPaths, sizeBaselineCheck)
+		}
+		if appcompatCheck := a.scanAppcompat(ctx, a.outputFile); appcompatCheck != nil {
+			extraInstalledPaths = append(extraInstalledPaths, appcompatCheck)
+			ctx.CheckbuildFile(appcompatCheck)
+		}
 		ctx.InstallFile(a.installDir, a.outputFile.Base(), a.outputFile, extraInstalledPaths...)
+
+		if ctx.Device() {
+			recordSignedApk(ctx, a.installDir, a.outputFile.Base(), a.outputFile, certificates, signingLineage)
+			// The split APKs are signed like the main APK.
+			for _, extra := range a.extraOutputFiles {
+				if extra.Ext() == ".apk" {
+					recordSignedApk(ctx, a.installDir, extra.Base(), extra, certificates, signingLineage)
+				}
+			}
+		}
+	}
+	if sizeBaselineCheck != nil {
+		ctx.CheckbuildFile(sizeBaselineCheck)
 	}
 
 	a.buildAppDependencyInfo(ctx)
@@ -771,6 +813,14 @@ func collectAppDeps(ctx android.ModuleContext, app appDepsInterface,
 			app.SdkVersion(ctx).Kind != android.SdkCorePlatform && !app.RequiresStableAPIs(ctx)
 	}
 
+	// The API surfaces of the JNI dependencies are checked against the API surface of the app by
+	// the api_surface_consistency singleton. Apps that are allowed to use platform APIs in their
+	// JNI libraries are considered to be built against the platform surface.
+	apiSurface := android.ApiSurfaceInfo{Surface: android.ApiSurfacePlatform}
+	if checkNativeSdkVersion {
+		apiSurface.Surface = android.ApiSurfaceForSdkKind(app.SdkVersion(ctx).Kind)
+	}
+
 	ctx.WalkDeps(func(module android.Module, parent android.Module) bool {
 		otherName := ctx.OtherModuleName(module)
 		tag := ctx.OtherModuleDependencyTag(module)
@@ -789,10 +839,16 @@ func collectAppDeps(ctx android.ModuleContext, app appDepsInterface,
 					}
 					seenModulePaths[path.String()] = true
 
-					if checkNativeSdkVersion && dep.SdkVersion() == "" {
-						ctx.PropertyErrorf("jni_libs", "JNI dependency %q uses platform APIs, but this module does not",
-							otherName)
+					depSurface := android.ApiSurfacePlatform
+					if dep.SdkVersion() != "" {
+						depSurface = android.ApiSurfacePublic
 					}
+					apiSurface.Deps = append(apiSurface.Deps, android.ApiSurfaceDep{
+						Module:   otherName,
+						Kind:     "jni",
+						Surface:  depSurface,
+						Property: "jni_libs",
+					})
 
 					jniLibs = append(jniLibs, jniLib{
 						name:           ctx.OtherModuleName(module),
@@ -829,6 +885,10 @@ func collectAppDeps(ctx android.ModuleContext, app appDepsInterface,
 		return false
 	})
 
+	if len(apiSurface.Deps) > 0 {
+		ctx.SetProvider(android.ApiSurfaceInfoProvider, apiSurface)
+	}
+
 	return jniLibs, prebuiltJniPackages, certificates
 }
 
@@ -968,7 +1028,11 @@ func AndroidAppFactory() android.Module {
 	module.AddProperties(
 		&module.aaptProperties,
 		&module.appProperties,
-		&module.overridableAppProperties)
+		&module.overridableAppProperties,
+		&module.sizeBaselineProperties,
+		&module.appcompatProperties)
+	module.initRuntimeClasspathCheck(true)
+	module.initBaselineProfiles(true)
 
 	module.usesLibrary.enforce = true
 
@@ -1020,6 +1084,17 @@ func (a *AndroidTestHelperApp) includedInTestSuite(searchPrefix string) bool {
 	return android.PrefixInList(a.appTestHelperAppProperties.Test_suites, searchPrefix)
 }
 
+func (a *AndroidTest) TestSuites() []string {
+	return a.testProperties.Test_suites
+}
+
+func (a *AndroidTestHelperApp) TestSuites() []string {
+	return a.appTestHelperAppProperties.Test_suites
+}
+
+var _ android.TestSuiteModule = (*AndroidTest)(nil)
+var _ android.TestSuiteModule = (*AndroidTestHelperApp)(nil)
+
 func (a *AndroidTest) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 	var configs []tradefed.Config
 	if a.appTestProperties.Instrumentation_target_package != nil {
@@ -52,7 +52,7 @@ var combineApk = pctx.AndroidStaticRule("combineApk",
 	})
 
 func CreateAndSignAppPackage(ctx android.ModuleContext, outputFile android.WritablePath,
-	packageFile, jniJarFile, dexJarFile android.Path, certificates []Certificate, deps android.Paths, v4SignatureFile android.WritablePath, lineageFile android.Path, rotationMinSdkVersion string, shrinkResources bool) {
+	packageFile, jniJarFile, dexJarFile android.Path, certificates []Certificate, deps android.Paths, v4SignatureFile android.WritablePath, signingLineage SigningLineage, shrinkResources bool) {
 
 	unsignedApkName := strings.TrimSuffix(outputFile.Base(), ".apk") + "-unsigned.apk"
 	unsignedApk := android.PathForModuleOut(ctx, unsignedApkName)
@@ -77,10 +77,10 @@ func CreateAndSignAppPackage(ctx android.ModuleContext, outputFile android.Writa
 		ShrinkResources(ctx, unsignedApk, shrunkenApk)
 		unsignedApk = shrunkenApk
 	}
-	SignAppPackage(ctx, outputFile, unsignedApk, certificates, v4SignatureFile, lineageFile, rotationMinSdkVersion)
+	SignAppPackage(ctx, outputFile, unsignedApk, certificates, v4SignatureFile, signingLineage)
 }
 
-func SignAppPackage(ctx android.ModuleContext, signedApk android.WritablePath, unsignedApk android.Path, certificates []Certificate, v4SignatureFile android.WritablePath, lineageFile android.Path, rotationMinSdkVersion string) {
+func SignAppPackage(ctx android.ModuleContext, signedApk android.WritablePath, unsignedApk android.Path, certificates []Certificate, v4SignatureFile android.WritablePath, signingLineage SigningLineage) {
 
 	var certificateArgs []string
 	var deps android.Paths
@@ -95,14 +95,9 @@ func SignAppPackage(ctx android.ModuleContext, signedApk android.WritablePath, u
 		flags = append(flags, "--enable-v4")
 	}
 
-	if lineageFile != nil {
-		flags = append(flags, "--lineage", lineageFile.String())
-		deps = append(deps, lineageFile)
-	}
-
-	if rotationMinSdkVersion != "" {
-		flags = append(flags, "--rotation-min-sdk-version", rotationMinSdkVersion)
-	}
+	lineageFlags, lineageDeps := signingLineage.signapkFlags()
+	flags = append(flags, lineageFlags...)
+	deps = append(deps, lineageDeps...)
 
 	rule := Signapk
 	args := map[string]string{
@@ -120,6 +115,7 @@ func SignAppPackage(ctx android.ModuleContext, signedApk android.WritablePath, u
 		Outputs:     outputFiles,
 		Input:       unsignedApk,
 		Implicits:   deps,
+		Validation:  signingLineage.Check,
 		Args:        args,
 	})
 }
@@ -63,6 +63,7 @@ type AndroidAppImport struct {
 	prebuilt android.Prebuilt
 
 	properties   AndroidAppImportProperties
+	artifact     android.PrebuiltArtifactProperties
 	dpiVariants  interface{}
 	archVariants interface{}
 
@@ -70,6 +71,7 @@ type AndroidAppImport struct {
 	certificate Certificate
 
 	dexpreopter
+	appcompat
 
 	usesLibrary usesLibrary
 
@@ -270,6 +272,13 @@ func (a *AndroidAppImport) generateAndroidBuildActions(ctx android.ModuleContext
 
 	srcApk := a.prebuilt.SingleSourcePath(ctx)
 
+	// Check the contents of the apk against what the Android.bp declares about them.
+	var installDeps android.Paths
+	if check := android.CheckPrebuiltArtifact(ctx, &a.artifact, srcApk); check != nil {
+		installDeps = append(installDeps, check)
+		ctx.CheckbuildFile(check)
+	}
+
 	// TODO: Install or embed JNI libraries
 
 	// Uncompress JNI libraries in the apk
@@ -317,6 +326,8 @@ func (a *AndroidAppImport) generateAndroidBuildActions(ctx android.ModuleContext
 
 	// Sign or align the package if package has not been preprocessed
 
+	var signedWith []Certificate
+	var signingLineage SigningLineage
 	if a.preprocessed {
 		a.outputFile = srcApk
 		a.certificate = PresignedCertificate
@@ -325,15 +336,14 @@ func (a *AndroidAppImport) generateAndroidBuildActions(ctx android.ModuleContext
 		// Which makes processMainCert's behavior for the empty cert string WAI.
 		a.certificate, certificates = processMainCert(a.ModuleBase, String(a.properties.Certificate), certificates, ctx)
 		signed := android.PathForModuleOut(ctx, "signed", apkFilename)
-		var lineageFile android.Path
+		signingLineage = SigningLineage{RotationMinSdkVersion: String(a.properties.RotationMinSdkVersion)}
 		if lineage := String(a.properties.Lineage); lineage != "" {
-			lineageFile = android.PathForModuleSrc(ctx, lineage)
+			signingLineage.File = android.PathForModuleSrc(ctx, lineage)
 		}
 
-		rotationMinSdkVersion := String(a.properties.RotationMinSdkVersion)
-
-		SignAppPackage(ctx, signed, jnisUncompressed, certificates, nil, lineageFile, rotationMinSdkVersion)
+		SignAppPackage(ctx, signed, jnisUncompressed, certificates, nil, signingLineage)
 		a.outputFile = signed
+		signedWith = certificates
 	} else {
 		alignedApk := android.PathForModuleOut(ctx, "zip-aligned", apkFilename)
 		TransformZipAlign(ctx, alignedApk, jnisUncompressed)
@@ -344,7 +354,12 @@ func (a *AndroidAppImport) generateAndroidBuildActions(ctx android.ModuleContext
 	// TODO: Optionally compress the output apk.
 
 	if apexInfo.IsForPlatform() {
-		a.installPath = ctx.InstallFile(installDir, apkFilename, a.outputFile)
+		if appcompatCheck := a.scanAppcompat(ctx, a.outputFile); appcompatCheck != nil {
+			installDeps = append(installDeps, appcompatCheck)
+			ctx.CheckbuildFile(appcompatCheck)
+		}
+		a.installPath = ctx.InstallFile(installDir, apkFilename, a.outputFile, installDeps...)
+		recordSignedApk(ctx, installDir, apkFilename, a.outputFile, signedWith, signingLineage)
 		artifactPath := android.PathForModuleSrc(ctx, *a.properties.Apk)
 		a.provenanceMetaDataFile = provenance.GenerateArtifactProvenanceMetaData(ctx, artifactPath, a.installPath)
 	}
@@ -470,8 +485,10 @@ func createVariantGroupType(variants []string, variantGroupName string) reflect.
 func AndroidAppImportFactory() android.Module {
 	module := &AndroidAppImport{}
 	module.AddProperties(&module.properties)
+	module.AddProperties(&module.artifact)
 	module.AddProperties(&module.dexpreoptProperties)
 	module.AddProperties(&module.usesLibrary.usesLibraryProperties)
+	module.AddProperties(&module.appcompatProperties)
 	module.populateAllVariantStructs()
 	android.AddLoadHook(module, func(ctx android.LoadHookContext) {
 		module.processVariants(ctx)
@@ -513,6 +530,12 @@ type AndroidTestImport struct {
 	data android.Paths
 }
 
+func (a *AndroidTestImport) TestSuites() []string {
+	return a.testProperties.Test_suites
+}
+
+var _ android.TestSuiteModule = (*AndroidTestImport)(nil)
+
 func (a *AndroidTestImport) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 	a.preprocessed = Bool(a.testImportProperties.Preprocessed)
 
@@ -530,6 +553,7 @@ func (a *AndroidTestImport) InstallInTestcases() bool {
 func AndroidTestImportFactory() android.Module {
 	module := &AndroidTestImport{}
 	module.AddProperties(&module.properties)
+	module.AddProperties(&module.artifact)
 	module.AddProperties(&module.dexpreoptProperties)
 	module.AddProperties(&module.testProperties)
 	module.AddProperties(&module.testImportProperties)
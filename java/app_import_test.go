@@ -86,6 +86,48 @@ func TestAndroidAppImport_NoDexPreopt(t *testing.T) {
 	android.AssertStringEquals(t, "Invalid args", "/system/app/foo/foo.apk", rule.Args["install_path"])
 }
 
+func TestAndroidAppImport_ArtifactContents(t *testing.T) {
+	ctx, _ := testJava(t, `
+		android_app_import {
+			name: "foo",
+			apk: "prebuilts/apk/app.apk",
+			certificate: "platform",
+			artifact_contents: {
+				min_sdk_version: "29",
+				native_libs: ["arm64-v8a/libfoo.so"],
+				has_code: true,
+			},
+		}
+
+		android_app_import {
+			name: "bar",
+			apk: "prebuilts/apk/app.apk",
+			certificate: "platform",
+		}
+		`)
+
+	variant := ctx.ModuleForTests("foo", "android_common")
+	check := variant.Output("check_prebuilt_artifact.timestamp")
+	for _, flag := range []string{
+		"--min-sdk-version 29",
+		"--native-lib arm64-v8a/libfoo.so",
+		"--has-code true",
+		"prebuilts/apk/app.apk",
+	} {
+		android.AssertStringDoesContain(t, "check command", check.RuleParams.Command, flag)
+	}
+
+	// The installed apk depends on the check.
+	install := variant.Output(variant.Module().(*AndroidAppImport).installPath.String())
+	android.AssertStringListContains(t, "install implicits", install.Implicits.Strings(), check.Output.String())
+
+	// Nothing is checked if artifact_contents is not set.
+	bar := ctx.ModuleForTests("bar", "android_common")
+	if bar.MaybeOutput("check_prebuilt_artifact.timestamp").Rule != nil {
+		t.Errorf("expected no check for bar")
+	}
+}
+
 func TestAndroidAppImport_Presigned(t *testing.T) {
 	ctx, _ := testJava(t, `
 		android_app_import {
@@ -0,0 +1,96 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+// This file applies the AppResourceOverlays product variable, which lets a product change the
+// resources of an existing android_app, e.g. for branding, without forking it into an
+// override_android_app. The resource directories of the product's overlay are compiled into the
+// app with a higher priority than the device and product resource overlays, and the build fails if
+// they define a resource that the app and its static libraries don't have, as a misspelled name
+// would otherwise be added silently. The runtime resource overlays of the product's overlay are
+// installed with the app.
+
+import (
+	"android/soong/android"
+)
+
+var appRROInstallTag = installDependencyTag{name: "app rro install"}
+
+// appResourceOverlayDeps adds dependencies on the runtime resource overlays of the product's
+// resource overlay of the app.
+func (a *AndroidApp) appResourceOverlayDeps(ctx android.BottomUpMutatorContext) {
+	if overlay, ok := ctx.Config().AppResourceOverlay(ctx.ModuleName()); ok {
+		ctx.AddVariationDependencies(nil, appRROInstallTag, overlay.RuntimeResourceOverlays...)
+	}
+}
+
+// setAppResourceOverlay resolves the product's resource overlay of the app for the aapt2 rules
+// and for Make.
+func (a *AndroidApp) setAppResourceOverlay(ctx android.ModuleContext) {
+	overlay, ok := ctx.Config().AppResourceOverlay(ctx.ModuleName())
+	if !ok {
+		return
+	}
+
+	for _, dir := range overlay.ResourceDirs {
+		path := android.ExistentPathForSource(ctx, dir)
+		if !path.Valid() {
+			ctx.ModuleErrorf("AppResourceOverlays: resource directory %q does not exist", dir)
+			continue
+		}
+		a.aapt.appResourceOverlayDirs = append(a.aapt.appResourceOverlayDirs, path.Path())
+	}
+
+	ctx.VisitDirectDepsWithTag(appRROInstallTag, func(module android.Module) {
+		if _, ok := module.(*RuntimeResourceOverlay); !ok {
+			ctx.ModuleErrorf("AppResourceOverlays: %q is not a runtime_resource_overlay module",
+				ctx.OtherModuleName(module))
+			return
+		}
+		a.appRuntimeResourceOverlays = append(a.appRuntimeResourceOverlays, ctx.OtherModuleName(module))
+	})
+}
+
+// appResourceOverlay returns the product's resource overlay directories of the app in aapt2 order,
+// and a file created by a rule that checks that they only override existing resources.
+func (a *aapt) appResourceOverlay(ctx android.ModuleContext,
+	resDirs []globbedResourceDir) ([]globbedResourceDir, android.Path) {
+
+	rule := android.NewRuleBuilder(pctx, ctx)
+	cmd := rule.Command().BuiltTool("check_resource_overlay")
+	for _, dir := range resDirs {
+		cmd.FlagWithArg("--resource_dir ", dir.dir.String()).Implicits(dir.files)
+	}
+	ctx.VisitDirectDepsWithTag(staticLibTag, func(module android.Module) {
+		if dep, ok := module.(AndroidLibraryDependency); ok && dep.ExportedRTxt() != nil {
+			cmd.FlagWithInput("--r_txt ", dep.ExportedRTxt())
+		}
+	})
+
+	// Later overlays take precedence in aapt2, so add the directories in reverse order.
+	var overlayDirs []globbedResourceDir
+	for i := len(a.appResourceOverlayDirs) - 1; i >= 0; i-- {
+		dir := a.appResourceOverlayDirs[i]
+		files := androidResourceGlob(ctx, dir)
+		overlayDirs = append(overlayDirs, globbedResourceDir{dir: dir, files: files})
+		cmd.FlagWithArg("--overlay_dir ", dir.String()).Implicits(files)
+	}
+
+	checkFile := android.PathForModuleOut(ctx, "app_resource_overlay.stamp")
+	cmd.FlagWithOutput("--out ", checkFile)
+	rule.Build("app_resource_overlay_check", "check app resource overlay")
+
+	return overlayDirs, checkFile
+}
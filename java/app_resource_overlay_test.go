@@ -0,0 +1,116 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+const appResourceOverlayBp = `
+	android_app {
+		name: "foo",
+		sdk_version: "current",
+		static_libs: ["lib"],
+	}
+
+	android_app {
+		name: "bar",
+		sdk_version: "current",
+	}
+
+	android_library {
+		name: "lib",
+		sdk_version: "current",
+	}
+
+	runtime_resource_overlay {
+		name: "foo_rro",
+		sdk_version: "current",
+	}
+`
+
+func prepareForAppResourceOverlayTest(overlays map[string]android.AppResourceOverlay) android.FixturePreparer {
+	return android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		PrepareForTestWithOverlayBuildComponents,
+		android.FixtureMergeMockFs(android.MockFS{
+			"branding/res/values/strings.xml":       nil,
+			"branding/res/drawable/icon.png":        nil,
+			"branding/extra/res/values/strings.xml": nil,
+		}),
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.AppResourceOverlays = overlays
+		}),
+	)
+}
+
+func TestAppResourceOverlay(t *testing.T) {
+	result := prepareForAppResourceOverlayTest(map[string]android.AppResourceOverlay{
+		"foo": {
+			ResourceDirs:            []string{"branding/res", "branding/extra/res"},
+			RuntimeResourceOverlays: []string{"foo_rro"},
+		},
+	}).RunTestWithBp(t, appResourceOverlayBp)
+
+	foo := result.ModuleForTests("foo", "android_common")
+
+	// The overlay directories are compiled as overlays, with the first one last so that it takes
+	// precedence.
+	var overlayFiles []string
+	for _, o := range foo.Output("aapt2/overlay.list").Inputs {
+		if res := foo.MaybeOutput(o.String()); res.Rule != nil {
+			overlayFiles = append(overlayFiles, res.Inputs.Strings()...)
+		}
+	}
+	android.AssertDeepEquals(t, "overlay files", []string{
+		"branding/extra/res/values/strings.xml",
+		"branding/res/drawable/icon.png",
+		"branding/res/values/strings.xml",
+	}, overlayFiles)
+
+	check := foo.Rule("app_resource_overlay_check")
+	android.AssertStringDoesContain(t, "check overlay dirs", check.RuleParams.Command,
+		"--overlay_dir branding/extra/res --overlay_dir branding/res")
+	android.AssertStringDoesContain(t, "check static lib R.txt", check.RuleParams.Command,
+		"--r_txt out/soong/.intermediates/lib/android_common/R.txt")
+
+	link := foo.Output("package-res.apk")
+	android.AssertStringListContains(t, "link depends on the check",
+		android.PathsRelativeToTop(link.Implicits), android.PathRelativeToTop(check.Output))
+
+	entries := android.AndroidMkEntriesForTest(t, result.TestContext, foo.Module())[0]
+	android.AssertStringListContains(t, "required runtime resource overlay",
+		entries.EntryMap["LOCAL_REQUIRED_MODULES"], "foo_rro")
+
+	// Other apps are not overlaid.
+	bar := result.ModuleForTests("bar", "android_common")
+	if bar.MaybeRule("app_resource_overlay_check").Rule != nil {
+		t.Errorf("expected no resource overlay check for bar")
+	}
+}
+
+func TestAppResourceOverlayErrors(t *testing.T) {
+	prepareForAppResourceOverlayTest(map[string]android.AppResourceOverlay{
+		"foo": {
+			ResourceDirs:            []string{"branding/missing"},
+			RuntimeResourceOverlays: []string{"lib"},
+		},
+	}).ExtendWithErrorHandler(android.FixtureExpectsAllErrorsToMatchAPattern([]string{
+		`AppResourceOverlays: resource directory "branding/missing" does not exist`,
+		`AppResourceOverlays: "lib" is not a runtime_resource_overlay module`,
+	})).RunTestWithBp(t, appResourceOverlayBp)
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+// This file handles the rotation of the signing certificate of apps. An app whose signing
+// certificate was rotated is signed with APK Signature Scheme v3 using a signing certificate
+// lineage, which proves that the new certificate is trusted by the previous ones. The lineage is
+// either a checked-in file, which is checked to rotate to the app's certificate, or generated with
+// apksigner from the previous certificates of the app.
+
+import (
+	"strconv"
+
+	"android/soong/android"
+)
+
+var previousCertificateTag = dependencyTag{name: "previous-certificate"}
+
+// SigningLineage describes the rotation of the signing certificate of an APK.
+type SigningLineage struct {
+	// The signing certificate lineage, or nil if the signing certificate was not rotated.
+	File android.Path
+
+	// The API level from which the rotated signing certificate is used, or empty for the apksig
+	// default.
+	RotationMinSdkVersion string
+
+	// Whether the rotated signing certificate is also used on development releases of
+	// RotationMinSdkVersion, which requires APK Signature Scheme v3.1.
+	RotationTargetsDevRelease bool
+
+	// File created by a rule that checks the lineage against the signing certificate, or nil.
+	Check android.Path
+}
+
+// signapkFlags returns the signapk flags and dependencies for the lineage.
+func (l SigningLineage) signapkFlags() ([]string, android.Paths) {
+	var flags []string
+	var deps android.Paths
+	if l.File != nil {
+		flags = append(flags, "--lineage", l.File.String())
+		deps = append(deps, l.File)
+	}
+	if l.RotationMinSdkVersion != "" {
+		flags = append(flags, "--rotation-min-sdk-version", l.RotationMinSdkVersion)
+	}
+	if l.RotationTargetsDevRelease {
+		flags = append(flags, "--rotation-targets-dev-release")
+	}
+	return flags, deps
+}
+
+// previousCertificateDeps adds dependencies on the android_app_certificate modules in
+// previous_certificates.
+func (a *AndroidApp) previousCertificateDeps(ctx android.BottomUpMutatorContext) {
+	for _, cert := range a.overridableAppProperties.Previous_certificates {
+		if module := android.SrcIsModule(cert); module != "" {
+			ctx.AddDependency(ctx.Module(), previousCertificateTag, module)
+		}
+	}
+}
+
+// signingLineage returns the signing certificate lineage of the app, after validating the rotation
+// properties.
+func (a *AndroidApp) signingLineage(ctx android.ModuleContext) SigningLineage {
+	props := &a.overridableAppProperties
+	lineage := SigningLineage{
+		RotationMinSdkVersion:     String(props.RotationMinSdkVersion),
+		RotationTargetsDevRelease: Bool(props.Rotation_targets_dev_release),
+	}
+
+	if file := String(props.Lineage); file != "" {
+		if len(props.Previous_certificates) > 0 {
+			ctx.PropertyErrorf("previous_certificates", "can't be set together with lineage")
+		}
+		lineage.File = android.PathForModuleSrc(ctx, file)
+		lineage.Check = checkSigningLineage(ctx, lineage.File, a.certificate)
+	} else if len(props.Previous_certificates) > 0 {
+		lineage.File = a.rotateSigningCertificate(ctx)
+	}
+
+	if lineage.RotationMinSdkVersion != "" {
+		if lineage.File == nil {
+			ctx.PropertyErrorf("rotationMinSdkVersion", "requires lineage or previous_certificates")
+		} else if level, err := strconv.Atoi(lineage.RotationMinSdkVersion); err != nil {
+			ctx.PropertyErrorf("rotationMinSdkVersion", "must be an integer API level, got %q",
+				lineage.RotationMinSdkVersion)
+		} else if level < 28 {
+			ctx.PropertyErrorf("rotationMinSdkVersion",
+				"APK Signature Scheme v3 requires an API level of at least 28, got %d", level)
+		}
+	}
+	if lineage.RotationTargetsDevRelease && lineage.File == nil {
+		ctx.PropertyErrorf("rotation_targets_dev_release", "requires lineage or previous_certificates")
+	}
+
+	return lineage
+}
+
+// previousCertificates returns the certificates of previous_certificates, oldest first.
+func (a *AndroidApp) previousCertificates(ctx android.ModuleContext) []Certificate {
+	var certificates []Certificate
+	for _, cert := range a.overridableAppProperties.Previous_certificates {
+		if module := android.SrcIsModule(cert); module != "" {
+			dep := ctx.GetDirectDepWithTag(module, previousCertificateTag)
+			if dep == nil {
+				// The dependency is missing and AllowMissingDependencies is set.
+				continue
+			}
+			if c, ok := dep.(*AndroidAppCertificate); ok {
+				certificates = append(certificates, c.Certificate)
+			} else {
+				ctx.PropertyErrorf("previous_certificates", "%q is not an android_app_certificate module", module)
+			}
+		} else {
+			defaultDir := ctx.Config().DefaultAppCertificateDir(ctx)
+			certificates = append(certificates, Certificate{
+				Pem: defaultDir.Join(ctx, cert+".x509.pem"),
+				Key: defaultDir.Join(ctx, cert+".pk8"),
+			})
+		}
+	}
+	return certificates
+}
+
+// rotateSigningCertificate generates a signing certificate lineage that rotates through the
+// previous certificates of the app to its signing certificate.
+func (a *AndroidApp) rotateSigningCertificate(ctx android.ModuleContext) android.Path {
+	certificates := append(a.previousCertificates(ctx), a.certificate)
+	if len(certificates) < 2 {
+		return nil
+	}
+
+	apksigner := ctx.Config().HostToolPath(ctx, "apksigner")
+	lineage := android.PathForModuleOut(ctx, "signing_lineage", "lineage")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().Text("rm -f").Output(lineage)
+	for i := 1; i < len(certificates); i++ {
+		cmd := rule.Command().Tool(apksigner).
+			ImplicitTool(ctx.Config().HostJavaToolPath(ctx, "apksigner.jar")).
+			Text("rotate")
+		if i > 1 {
+			cmd.FlagWithArg("--in ", lineage.String())
+		}
+		prev, next := certificates[i-1], certificates[i]
+		cmd.FlagWithOutput("--out ", lineage).
+			Flag("--old-signer").
+			FlagWithInput("--key ", prev.Key).
+			FlagWithInput("--cert ", prev.Pem).
+			Flag("--new-signer").
+			FlagWithInput("--key ", next.Key).
+			FlagWithInput("--cert ", next.Pem)
+	}
+	rule.Build("signing_lineage_rotate", "generate signing lineage")
+	return lineage
+}
+
+// checkSigningLineage returns a file created by a rule that checks that the newest signer of a
+// checked-in lineage is the signing certificate.
+func checkSigningLineage(ctx android.ModuleContext, lineage android.Path, certificate Certificate) android.Path {
+	if certificate.Pem == nil {
+		return nil
+	}
+	apksigner := ctx.Config().HostToolPath(ctx, "apksigner")
+	checkFile := android.PathForModuleOut(ctx, "signing_lineage", "check.stamp")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().BuiltTool("check_signing_lineage").
+		FlagWithArg("--apksigner ", apksigner.String()).
+		ImplicitTool(apksigner).
+		ImplicitTool(ctx.Config().HostJavaToolPath(ctx, "apksigner.jar")).
+		FlagWithInput("--lineage ", lineage).
+		FlagWithInput("--certificate ", certificate.Pem).
+		FlagWithOutput("--out ", checkFile)
+	rule.Build("signing_lineage_check", "check signing lineage")
+	return checkFile
+}
+
+// recordSignedApk records an APK signed with certificates that is installed into installDir as
+// name for the transparency log.
+func recordSignedApk(ctx android.ModuleContext, installDir android.InstallPath, name string,
+	apk android.Path, certificates []Certificate, lineage SigningLineage) {
+	artifact := android.SignedArtifact{
+		Kind:         "apk",
+		File:         apk,
+		OnDevicePath: android.InstallPathToOnDevicePath(ctx, installDir.Join(ctx, name)),
+		Lineage:      lineage.File,
+		Presigned:    len(certificates) == 0,
+	}
+	for _, certificate := range certificates {
+		if certificate.presigned {
+			artifact.Presigned = true
+			continue
+		}
+		artifact.Certificates = append(artifact.Certificates, certificate.Pem)
+	}
+	android.RecordSignedArtifact(ctx, artifact)
+}
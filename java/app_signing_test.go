@@ -0,0 +1,176 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+const signingLineageCertificatesBp = `
+	android_app_certificate {
+		name: "old_certificate",
+		certificate: "cert/old_cert",
+	}
+
+	android_app_certificate {
+		name: "older_certificate",
+		certificate: "cert/older_cert",
+	}
+
+	android_app_certificate {
+		name: "new_certificate",
+		certificate: "cert/new_cert",
+	}
+`
+
+func TestAppSigningLineageCheck(t *testing.T) {
+	result := PrepareForTestWithJavaDefaultModules.RunTestWithBp(t, `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+			certificate: ":new_certificate",
+			lineage: "lineage.bin",
+			rotationMinSdkVersion: "33",
+			rotation_targets_dev_release: true,
+			sdk_version: "current",
+		}
+	`+signingLineageCertificatesBp)
+
+	foo := result.ModuleForTests("foo", "android_common")
+
+	check := foo.Rule("signing_lineage_check")
+	android.AssertStringDoesContain(t, "check lineage", check.RuleParams.Command,
+		"--lineage lineage.bin --certificate cert/new_cert.x509.pem")
+
+	signapk := foo.Output("foo.apk")
+	android.AssertStringEquals(t, "signing flags",
+		"--lineage lineage.bin --rotation-min-sdk-version 33 --rotation-targets-dev-release",
+		signapk.Args["flags"])
+	android.AssertPathRelativeToTopEquals(t, "signapk validation",
+		"out/soong/.intermediates/foo/android_common/signing_lineage/check.stamp", signapk.Validation)
+}
+
+func TestAppSigningLineagePreviousCertificates(t *testing.T) {
+	result := PrepareForTestWithJavaDefaultModules.RunTestWithBp(t, `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+			certificate: ":new_certificate",
+			previous_certificates: [":older_certificate", ":old_certificate"],
+			rotationMinSdkVersion: "28",
+			sdk_version: "current",
+		}
+	`+signingLineageCertificatesBp)
+
+	foo := result.ModuleForTests("foo", "android_common")
+	lineage := "out/soong/.intermediates/foo/android_common/signing_lineage/lineage"
+
+	// The lineage rotates through the previous certificates to the signing certificate.
+	rotate := foo.Rule("signing_lineage_rotate")
+	android.AssertStringDoesContain(t, "first rotation", rotate.RuleParams.Command,
+		"rotate --out "+lineage+
+			" --old-signer --key cert/older_cert.pk8 --cert cert/older_cert.x509.pem"+
+			" --new-signer --key cert/old_cert.pk8 --cert cert/old_cert.x509.pem")
+	android.AssertStringDoesContain(t, "second rotation", rotate.RuleParams.Command,
+		"rotate --in "+lineage+" --out "+lineage+
+			" --old-signer --key cert/old_cert.pk8 --cert cert/old_cert.x509.pem"+
+			" --new-signer --key cert/new_cert.pk8 --cert cert/new_cert.x509.pem")
+
+	signapk := foo.Output("foo.apk")
+	android.AssertStringEquals(t, "signing flags",
+		"--lineage "+lineage+" --rotation-min-sdk-version 28", signapk.Args["flags"])
+
+	// A generated lineage doesn't need to be checked.
+	if foo.MaybeRule("signing_lineage_check").Rule != nil {
+		t.Errorf("expected no signing lineage check")
+	}
+}
+
+func TestAppSigningLineageErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		bp   string
+		err  string
+	}{
+		{
+			name: "lineage and previous certificates",
+			bp: `
+				lineage: "lineage.bin",
+				previous_certificates: [":old_certificate"],
+			`,
+			err: `previous_certificates: can't be set together with lineage`,
+		},
+		{
+			name: "rotation without lineage",
+			bp: `
+				rotationMinSdkVersion: "33",
+			`,
+			err: `rotationMinSdkVersion: requires lineage or previous_certificates`,
+		},
+		{
+			name: "rotation codename",
+			bp: `
+				lineage: "lineage.bin",
+				rotationMinSdkVersion: "Tiramisu",
+			`,
+			err: `rotationMinSdkVersion: must be an integer API level, got "Tiramisu"`,
+		},
+		{
+			name: "rotation before v3",
+			bp: `
+				lineage: "lineage.bin",
+				rotationMinSdkVersion: "27",
+			`,
+			err: `rotationMinSdkVersion: APK Signature Scheme v3 requires an API level of at least 28, got 27`,
+		},
+		{
+			name: "dev release without lineage",
+			bp: `
+				rotation_targets_dev_release: true,
+			`,
+			err: `rotation_targets_dev_release: requires lineage or previous_certificates`,
+		},
+		{
+			name: "previous certificate not a certificate",
+			bp: `
+				previous_certificates: [":bar"],
+			`,
+			err: `previous_certificates: "bar" is not an android_app_certificate module`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			PrepareForTestWithJavaDefaultModules.
+				ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(test.err)).
+				RunTestWithBp(t, `
+					android_app {
+						name: "foo",
+						srcs: ["a.java"],
+						certificate: ":new_certificate",
+						sdk_version: "current",
+						`+test.bp+`
+					}
+
+					java_library {
+						name: "bar",
+						srcs: ["b.java"],
+					}
+				`+signingLineageCertificatesBp)
+		})
+	}
+}
@@ -1500,6 +1500,51 @@ func TestJNISDK(t *testing.T) {
 
 }
 
+func TestJNIApiSurface(t *testing.T) {
+	testJavaError(t, `jni dependency "libplatform" in "jni_libs" requires the platform API surface, but the module is built against the system API surface`,
+		cc.GatherRequiredDepsForTest(android.Android)+`
+		cc_library {
+			name: "libndk",
+			system_shared_libs: [],
+			stl: "none",
+			sdk_version: "current",
+		}
+
+		cc_library {
+			name: "libplatform",
+			system_shared_libs: [],
+			stl: "none",
+		}
+
+		android_app {
+			name: "app",
+			jni_libs: ["libndk", "libplatform"],
+			sdk_version: "system_current",
+		}
+	`)
+
+	testJava(t, cc.GatherRequiredDepsForTest(android.Android)+`
+		cc_library {
+			name: "libplatform",
+			system_shared_libs: [],
+			stl: "none",
+		}
+
+		android_app {
+			name: "app",
+			jni_libs: ["libplatform"],
+			sdk_version: "system_current",
+			jni_uses_platform_apis: true,
+		}
+
+		android_app {
+			name: "platform_app",
+			jni_libs: ["libplatform"],
+			platform_apis: true,
+		}
+	`)
+}
+
 func TestCertificates(t *testing.T) {
 	testCases := []struct {
 		name                     string
@@ -3547,3 +3592,102 @@ func TestTargetSdkVersionMtsTests(t *testing.T) {
 		android.AssertStringDoesContain(t, testCase.desc, manifestFixerArgs, "--targetSdkVersion  "+testCase.targetSdkVersionExpected)
 	}
 }
+
+func TestAppSizeBaseline(t *testing.T) {
+	bp := `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+			sdk_version: "current",
+			size_baseline: {
+				file: "foo.size",
+				max_increase_percent: 5,
+				severity: "warning",
+			},
+		}
+
+		android_app {
+			name: "bar",
+			srcs: ["a.java"],
+			sdk_version: "current",
+		}
+	`
+	fixture := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		android.FixtureAddTextFile("foo.size", "1000\n"),
+	)
+
+	t.Run("check", func(t *testing.T) {
+		result := fixture.RunTestWithBp(t, bp)
+		foo := result.ModuleForTests("foo", "android_common")
+		check := foo.Rule("sizeBaselineCheck")
+		android.AssertPathRelativeToTopEquals(t, "checked apk",
+			"out/soong/.intermediates/foo/android_common/foo.apk", check.Input)
+		android.AssertPathRelativeToTopEquals(t, "baseline dependency", "foo.size", check.Implicit)
+		android.AssertStringEquals(t, "flags", "--max-increase-percent 5 --warn", check.Args["flags"])
+
+		bar := result.ModuleForTests("bar", "android_common")
+		if bar.MaybeRule("sizeBaselineCheck").Rule != nil {
+			t.Errorf("expected no size baseline check for bar")
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		result := android.GroupFixturePreparers(
+			fixture,
+			android.FixtureMergeEnv(map[string]string{"UPDATE_SIZE_BASELINES": "true"}),
+		).RunTestWithBp(t, bp)
+		check := result.ModuleForTests("foo", "android_common").Rule("sizeBaselineCheck")
+		android.AssertStringEquals(t, "flags", "--max-increase-percent 5 --warn --update", check.Args["flags"])
+		android.AssertStringEquals(t, "baseline", "foo.size", check.Args["baseline"])
+		if check.Implicit != nil {
+			t.Errorf("expected no dependency on the baseline when updating it, got %s", check.Implicit)
+		}
+	})
+}
+
+func TestAppSizeBaselineErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		prop string
+		err  string
+	}{
+		{
+			name: "no file",
+			prop: `max_increase_bytes: 100`,
+			err:  `size_baseline.file: must be set to use a size baseline`,
+		},
+		{
+			name: "bytes and percent",
+			prop: `file: "foo.size", max_increase_bytes: 100, max_increase_percent: 5`,
+			err:  `size_baseline.max_increase_percent: can't be set together with max_increase_bytes`,
+		},
+		{
+			name: "negative",
+			prop: `file: "foo.size", max_increase_bytes: -1`,
+			err:  `size_baseline.max_increase_bytes: must not be negative, got -1`,
+		},
+		{
+			name: "severity",
+			prop: `file: "foo.size", severity: "fatal"`,
+			err:  `size_baseline.severity: must be "error" or "warning", got "fatal"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			android.GroupFixturePreparers(
+				PrepareForTestWithJavaDefaultModules,
+				android.FixtureAddTextFile("foo.size", "1000\n"),
+			).ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(test.err)).
+				RunTestWithBp(t, `
+					android_app {
+						name: "foo",
+						srcs: ["a.java"],
+						sdk_version: "current",
+						size_baseline: {`+test.prop+`},
+					}
+				`)
+		})
+	}
+}
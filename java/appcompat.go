@@ -0,0 +1,148 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+// This file scans the preinstalled apps for uses of non-SDK APIs with veridex, the static checker
+// of the hidden API restrictions, against the hidden API flags of the platform being built. Each
+// app gets a report of its uses by hidden API list, and an app with an appcompat_budget fails to
+// install when it uses more blocked or unsupported APIs than its budget. `m appcompat_report`
+// aggregates the reports of all the preinstalled apps into $OUT/soong/appcompat_report.json.
+
+import (
+	"strconv"
+
+	"android/soong/android"
+)
+
+func init() {
+	RegisterAppcompatBuildComponents(android.InitRegistrationContext)
+}
+
+func RegisterAppcompatBuildComponents(ctx android.RegistrationContext) {
+	ctx.RegisterSingletonType("appcompat_report", appcompatReportSingletonFactory)
+}
+
+var PrepareForTestWithAppcompatReport = android.FixtureRegisterWithContext(RegisterAppcompatBuildComponents)
+
+// The dex files of the SDK stubs that veridex resolves the references of the apps against.
+var appcompatCoreStubs = []string{
+	"prebuilts/runtime/appcompat/system-stubs.zip",
+	"prebuilts/runtime/appcompat/org.apache.http.legacy-stubs.zip",
+}
+
+type AppcompatProperties struct {
+	// The maximum number of uses of non-SDK APIs that are blocked or unsupported for apps, as
+	// found by veridex in the APK. Installing the app fails when it uses more.
+	Appcompat_budget *int64
+}
+
+type appcompat struct {
+	appcompatProperties AppcompatProperties
+
+	// The veridex report of the APK, only set for preinstalled apps.
+	appcompatReport android.Path
+}
+
+// AppcompatReport returns the report of the uses of non-SDK APIs by the app, or nil if the app is
+// not preinstalled.
+func (a *appcompat) AppcompatReport() android.Path {
+	return a.appcompatReport
+}
+
+// scanAppcompat creates the rules that scan the preinstalled APK for uses of non-SDK APIs. It
+// returns a file created by a rule that checks the uses against the budget of the app, or nil if
+// the app has no budget. The caller must make installing the APK depend on the returned file.
+func (a *appcompat) scanAppcompat(ctx android.ModuleContext, apk android.Path) android.Path {
+	if !ctx.Device() || ctx.InstallInTestcases() || ctx.Config().IsEnvTrue("UNSAFE_DISABLE_HIDDENAPI_FLAGS") {
+		return nil
+	}
+	budget := a.appcompatProperties.Appcompat_budget
+	if budget != nil && *budget < 0 {
+		ctx.PropertyErrorf("appcompat_budget", "must not be negative, got %d", *budget)
+		return nil
+	}
+
+	report := android.PathForModuleOut(ctx, "appcompat", "report.json")
+	veridex := ctx.Config().HostToolPath(ctx, "veridex")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	cmd := rule.Command().
+		BuiltTool("appcompat_scan").
+		Text("scan").
+		FlagWithArg("--veridex ", veridex.String()).
+		ImplicitTool(veridex).
+		FlagWithInput("--apk ", apk).
+		FlagWithInputList("--core-stubs ", android.PathsForSource(ctx, appcompatCoreStubs), ":").
+		FlagWithInput("--api-flags ", hiddenAPISingletonPaths(ctx).flags).
+		FlagWithArg("--module ", ctx.ModuleName()).
+		FlagWithOutput("--output ", report)
+	if budget != nil {
+		cmd.FlagWithArg("--budget ", strconv.FormatInt(*budget, 10))
+	}
+	rule.Build("appcompat_scan", "appcompat scan")
+	a.appcompatReport = report
+
+	if budget == nil {
+		return nil
+	}
+	checkFile := android.PathForModuleOut(ctx, "appcompat", "budget.stamp")
+	rule = android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		BuiltTool("appcompat_scan").
+		Text("check").
+		FlagWithInput("--report ", report).
+		FlagWithOutput("--stamp ", checkFile)
+	rule.Build("appcompat_budget", "appcompat budget check")
+	return checkFile
+}
+
+func appcompatReportSingletonFactory() android.Singleton {
+	return &appcompatReportSingleton{}
+}
+
+type appcompatReportSingleton struct {
+	report android.Path
+}
+
+func (s *appcompatReportSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	var reports android.Paths
+	ctx.VisitAllModules(func(module android.Module) {
+		if !module.Enabled() {
+			return
+		}
+		if app, ok := module.(interface{ AppcompatReport() android.Path }); ok && app.AppcompatReport() != nil {
+			reports = append(reports, app.AppcompatReport())
+		}
+	})
+	if len(reports) == 0 {
+		return
+	}
+
+	s.report = android.PathForOutput(ctx, "appcompat_report.json")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		BuiltTool("appcompat_scan").
+		Text("merge").
+		FlagWithOutput("--output ", s.report).
+		Inputs(android.SortedUniquePaths(reports))
+	rule.Build("appcompat_report", "appcompat report")
+
+	ctx.Phony("appcompat_report", s.report)
+}
+
+func (s *appcompatReportSingleton) MakeVars(ctx android.MakeVarsContext) {
+	if s.report != nil {
+		ctx.DistForGroup("appcompat", s.report)
+	}
+}
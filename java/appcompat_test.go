@@ -0,0 +1,87 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestAppcompat(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		PrepareForTestWithAppcompatReport,
+	).RunTestWithBp(t, `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+			sdk_version: "current",
+			appcompat_budget: 3,
+		}
+
+		android_app {
+			name: "bar",
+			srcs: ["a.java"],
+			sdk_version: "current",
+		}
+
+		android_test {
+			name: "baz",
+			srcs: ["a.java"],
+			sdk_version: "current",
+		}
+	`)
+
+	foo := result.ModuleForTests("foo", "android_common")
+	scan := foo.Rule("appcompat_scan")
+	android.AssertStringDoesContain(t, "scanned apk", scan.RuleParams.Command,
+		"--apk out/soong/.intermediates/foo/android_common/foo.apk")
+	android.AssertStringDoesContain(t, "api flags", scan.RuleParams.Command,
+		"--api-flags out/soong/hiddenapi/hiddenapi-flags.csv")
+	android.AssertStringDoesContain(t, "budget", scan.RuleParams.Command, "--budget 3")
+	check := foo.Rule("appcompat_budget")
+	android.AssertStringListContains(t, "checked report", check.Implicits.RelativeToTop().Strings(),
+		"out/soong/.intermediates/foo/android_common/appcompat/report.json")
+
+	bar := result.ModuleForTests("bar", "android_common")
+	android.AssertStringDoesNotContain(t, "budget", bar.Rule("appcompat_scan").RuleParams.Command, "--budget")
+	if bar.MaybeRule("appcompat_budget").Rule != nil {
+		t.Errorf("expected no appcompat budget check for bar")
+	}
+
+	// Test apps are not preinstalled.
+	if result.ModuleForTests("baz", "android_common").MaybeRule("appcompat_scan").Rule != nil {
+		t.Errorf("expected no appcompat scan for baz")
+	}
+
+	report := result.SingletonForTests("appcompat_report").Rule("appcompat_report")
+	reports := report.Implicits.RelativeToTop().Strings()
+	android.AssertStringListContains(t, "reports", reports,
+		"out/soong/.intermediates/bar/android_common/appcompat/report.json")
+	android.AssertStringListContains(t, "reports", reports,
+		"out/soong/.intermediates/foo/android_common/appcompat/report.json")
+}
+
+func TestAppcompatNegativeBudget(t *testing.T) {
+	testJavaError(t, `appcompat_budget: must not be negative, got -1`, `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+			sdk_version: "current",
+			appcompat_budget: -1,
+		}
+	`)
+}
@@ -111,6 +111,15 @@ type CommonProperties struct {
 	// The number of Java source entries each Javac instance can process
 	Javac_shard_size *int64
 
+	// The version of the JDK that compiles the sources of the module, one of the versions in
+	// prebuilts/jdk that the build allows. Defaults to the DefaultJdkVersion of the product, or to
+	// the JDK of the build.
+	Jdk_version *string
+
+	// The maximum number of warnings javac may report for the sources of the module. The build of
+	// the module fails when javac reports more.
+	Javac_warning_budget *int64
+
 	// Add host jdk tools.jar to bootclasspath
 	Use_tools_jar *bool
 
@@ -435,6 +444,23 @@ type Module struct {
 	outputFile       android.Path
 	extraOutputFiles android.Paths
 
+	// Properties of the runtime classpath check, only set for java binaries and apps.
+	runtimeClasspathProperties             *RuntimeClasspathProperties
+	runtimeClasspathIgnoresResourceClasses bool
+
+	// text file listing the jars of the runtime classpath, in order
+	runtimeClasspathManifest android.Path
+
+	// Properties of the baseline profiles, only set for apps and Android libraries.
+	baselineProfileProperties *BaselineProfileProperties
+	compilesBaselineProfile   bool
+
+	// human-readable baseline profiles of the module and its static libraries
+	baselineProfiles android.Paths
+
+	// zip containing the compiled baseline profile to embed into the APK
+	baselineProfileZip android.Path
+
 	exportAidlIncludeDirs     android.Paths
 	ignoredAidlPermissionList android.Paths
 
@@ -483,6 +509,20 @@ type Module struct {
 	// list of the xref extraction files
 	kytheFiles android.Paths
 
+	// list of the diagnostics files of the javac actions
+	javacDiagnostics android.Paths
+
+	// header jar of the sources of the module without its static libs, or nil if it can't be
+	// separated from headerJarFile
+	directHeaderJar android.Path
+
+	// names of the static libs of the module, transitively
+	staticLibModules []string
+
+	// static libs of the direct dependencies that are on the compile classpath of the module
+	// without being direct dependencies themselves
+	strictJavaDepsLeaks []StrictJavaDepsLeak
+
 	// Collect the module directory for IDE info in java/jdeps.go.
 	modulePaths []string
 
@@ -594,6 +634,11 @@ func (j *Module) OutputFiles(tag string) (android.Paths, error) {
 		return android.Paths{j.implementationAndResourcesJar}, nil
 	case ".hjar":
 		return android.Paths{j.headerJarFile}, nil
+	case ".runtime_classpath":
+		if j.runtimeClasspathManifest != nil {
+			return android.Paths{j.runtimeClasspathManifest}, nil
+		}
+		return nil, fmt.Errorf("%q was requested, but no output file was found.", tag)
 	case ".proguard_map":
 		if j.dexer.proguardDictionary.Valid() {
 			return android.Paths{j.dexer.proguardDictionary.Path()}, nil
@@ -672,6 +717,14 @@ func (j *Module) MinSdkVersion(ctx android.EarlyModuleContext) android.ApiLevel
 	return j.SdkVersion(ctx).ApiLevel
 }
 
+// Implements android.MinSdkVersionExplainer
+func (j *Module) ExplainMinSdkVersion(ctx android.EarlyModuleContext) (string, string) {
+	if j.deviceProperties.Min_sdk_version != nil {
+		return *j.deviceProperties.Min_sdk_version, "min_sdk_version"
+	}
+	return j.SdkVersion(ctx).Raw, "sdk_version"
+}
+
 func (j *Module) MaxSdkVersion(ctx android.EarlyModuleContext) android.ApiLevel {
 	if j.deviceProperties.Max_sdk_version != nil {
 		return android.ApiLevelFrom(ctx, *j.deviceProperties.Max_sdk_version)
@@ -898,6 +951,10 @@ func (j *Module) collectBuilderFlags(ctx android.ModuleContext, deps deps) javaB
 	// javaVersion flag.
 	flags.javaVersion = getJavaVersion(ctx, String(j.properties.Java_version), android.SdkContext(j))
 
+	// javac of the JDK version of the module.
+	flags.javacCmd = config.JavacCmdForJdkVersion(
+		config.JdkVersions.Select(ctx, j.properties.Jdk_version, ctx.Config().DefaultJdkVersion()))
+
 	epEnabled := j.properties.Errorprone.Enabled
 	if (ctx.Config().RunErrorProne() && epEnabled == nil) || Bool(epEnabled) {
 		if config.ErrorProneClasspath == nil && !ctx.Config().RunningInsideUnitTest() {
@@ -1041,6 +1098,7 @@ func (j *Module) compile(ctx android.ModuleContext, aaptSrcJar android.Path) {
 
 	deps := j.collectDeps(ctx)
 	flags := j.collectBuilderFlags(ctx, deps)
+	j.collectBaselineProfiles(ctx)
 
 	if flags.javaVersion.usesJavaModules() {
 		j.properties.Srcs = append(j.properties.Srcs, j.properties.Openjdk9.Srcs...)
@@ -1216,6 +1274,9 @@ func (j *Module) compile(ctx android.ModuleContext, aaptSrcJar android.Path) {
 		if ctx.Failed() {
 			return
 		}
+		if headerJarFileWithoutDepsOrJarjar != nil && len(kotlinHeaderJars) == 0 && j.expandJarjarRules == nil {
+			j.directHeaderJar = headerJarFileWithoutDepsOrJarjar
+		}
 	}
 	if len(uniqueJavaFiles) > 0 || len(srcJars) > 0 {
 		hasErrorproneableFiles := false
@@ -1331,6 +1392,7 @@ func (j *Module) compile(ctx android.ModuleContext, aaptSrcJar android.Path) {
 	if len(deps.staticJars) > 0 {
 		jars = append(jars, deps.staticJars...)
 	}
+	runtimeClasspathJars := append(android.Paths(nil), jars...)
 
 	manifest := j.overrideManifest
 	if !manifest.Valid() && j.properties.Manifest != nil {
@@ -1444,6 +1506,35 @@ func (j *Module) compile(ctx android.ModuleContext, aaptSrcJar android.Path) {
 		}
 	}
 
+	// Check the warnings of javac if the module has a warning budget.
+	if budget := j.properties.Javac_warning_budget; budget != nil && *budget < 0 {
+		ctx.PropertyErrorf("javac_warning_budget", "must not be negative, got %d", *budget)
+	} else if budget != nil && len(j.javacDiagnostics) > 0 {
+		// Time stamp file created by the warning budget check rule.
+		budgetFile := android.PathForModuleOut(ctx, "javac-warning-budget.stamp")
+
+		// As for the package check, the output jar is copied to another path that has a validate
+		// dependency on the check, and that becomes the output file of this module.
+		inputFile := outputFile
+		outputFile = android.PathForModuleOut(ctx, "javac-warning-budget", jarName).OutputPath
+		ctx.Build(pctx, android.BuildParams{
+			Rule:       android.Cp,
+			Input:      inputFile,
+			Output:     outputFile,
+			Validation: budgetFile,
+		})
+
+		CheckJavacWarningBudget(ctx, budgetFile, j.javacDiagnostics, *budget)
+	}
+
+	// Check for classes that are provided by more than one jar on the runtime classpath.
+	if j.runtimeClasspathProperties != nil {
+		outputFile = j.checkRuntimeClasspath(ctx, runtimeClasspathJars, outputFile, jarName)
+		if ctx.Failed() {
+			return
+		}
+	}
+
 	j.implementationJarFile = outputFile
 	if j.headerJarFile == nil {
 		j.headerJarFile = j.implementationJarFile
@@ -1527,6 +1618,9 @@ func (j *Module) compile(ctx android.ModuleContext, aaptSrcJar android.Path) {
 
 			j.dexJarFile = makeDexJarPathFromPath(dexOutputFile)
 
+			// Baseline profile, which is also used by dexpreopt
+			j.compileBaselineProfile(ctx, dexOutputFile)
+
 			// Dexpreopting
 			j.dexpreopt(ctx, dexOutputFile)
 
@@ -1596,6 +1690,8 @@ func (j *Module) compile(ctx android.ModuleContext, aaptSrcJar android.Path) {
 		HeaderJars:                     android.PathsIfNonNil(j.headerJarFile),
 		TransitiveLibsHeaderJars:       j.transitiveLibsHeaderJars,
 		TransitiveStaticLibsHeaderJars: j.transitiveStaticLibsHeaderJars,
+		DirectHeaderJars:               android.PathsIfNonNil(j.directHeaderJar),
+		HeaderJarsStaticLibs:           j.staticLibModules,
 		ImplementationAndResourcesJars: android.PathsIfNonNil(j.implementationAndResourcesJar),
 		ImplementationJars:             android.PathsIfNonNil(j.implementationJarFile),
 		ResourceJars:                   android.PathsIfNonNil(j.resourceJar),
@@ -1641,7 +1737,8 @@ func (j *Module) compileJavaClasses(ctx android.ModuleContext, jarName string, i
 	}
 
 	classes := android.PathForModuleOut(ctx, "javac", jarName).OutputPath
-	TransformJavaToClasses(ctx, classes, idx, srcFiles, srcJars, flags, extraJarDeps)
+	diagnostics := TransformJavaToClasses(ctx, classes, idx, srcFiles, srcJars, flags, extraJarDeps)
+	j.javacDiagnostics = append(j.javacDiagnostics, diagnostics)
 
 	if ctx.Config().EmitXrefRules() {
 		extractionFile := android.PathForModuleOut(ctx, kzipName)
@@ -2009,9 +2106,12 @@ func (j *Module) collectDeps(ctx android.ModuleContext) deps {
 	sdkLinkType, _ := j.getSdkLinkType(ctx, ctx.ModuleName())
 
 	j.collectTransitiveHeaderJars(ctx)
+	var staticLibModules []string
+	strictJavaDeps := newStrictJavaDepsChecker(ctx)
 	ctx.VisitDirectDeps(func(module android.Module) {
 		otherName := ctx.OtherModuleName(module)
 		tag := ctx.OtherModuleDependencyTag(module)
+		strictJavaDeps.addDirectDep(otherName)
 
 		if IsJniDepTag(tag) {
 			// Handled by AndroidApp.collectAppDeps
@@ -2048,7 +2148,7 @@ func (j *Module) collectDeps(ctx android.ModuleContext) deps {
 				if _, ok := module.(*Plugin); ok {
 					ctx.ModuleErrorf("a java_plugin (%s) cannot be used as a libs dependency", otherName)
 				}
-				deps.classpath = append(deps.classpath, dep.HeaderJars...)
+				deps.classpath = append(deps.classpath, strictJavaDeps.compileHeaderJars(otherName, dep)...)
 				deps.dexClasspath = append(deps.dexClasspath, dep.HeaderJars...)
 				deps.aidlIncludeDirs = append(deps.aidlIncludeDirs, dep.AidlIncludeDirs...)
 				addPlugins(&deps, dep.ExportedPlugins, dep.ExportedPluginClasses...)
@@ -2059,8 +2159,10 @@ func (j *Module) collectDeps(ctx android.ModuleContext) deps {
 				if _, ok := module.(*Plugin); ok {
 					ctx.ModuleErrorf("a java_plugin (%s) cannot be used as a static_libs dependency", otherName)
 				}
-				deps.classpath = append(deps.classpath, dep.HeaderJars...)
+				deps.classpath = append(deps.classpath, strictJavaDeps.compileHeaderJars(otherName, dep)...)
 				deps.staticJars = append(deps.staticJars, dep.ImplementationJars...)
+				staticLibModules = append(staticLibModules, otherName)
+				staticLibModules = append(staticLibModules, dep.HeaderJarsStaticLibs...)
 				deps.staticHeaderJars = append(deps.staticHeaderJars, dep.HeaderJars...)
 				deps.staticResourceJars = append(deps.staticResourceJars, dep.ResourceJars...)
 				deps.aidlIncludeDirs = append(deps.aidlIncludeDirs, dep.AidlIncludeDirs...)
@@ -2126,6 +2228,7 @@ func (j *Module) collectDeps(ctx android.ModuleContext) deps {
 				deps.classpath = append(deps.classpath, dep.Srcs()...)
 				deps.staticJars = append(deps.staticJars, dep.Srcs()...)
 				deps.staticHeaderJars = append(deps.staticHeaderJars, dep.Srcs()...)
+				staticLibModules = append(staticLibModules, otherName)
 			}
 		} else {
 			switch tag {
@@ -2151,6 +2254,9 @@ func (j *Module) collectDeps(ctx android.ModuleContext) deps {
 		addCLCFromDep(ctx, module, j.classLoaderContexts)
 	})
 
+	j.staticLibModules = android.FirstUniqueStrings(staticLibModules)
+	j.strictJavaDepsLeaks = strictJavaDeps.leaks()
+
 	return deps
 }
 
@@ -0,0 +1,139 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+// This file compiles the baseline profiles of apps. A baseline profile is a human-readable list of
+// the classes and methods that an app uses at startup and in its critical user journeys. The
+// baseline profiles of an android_app and of the android_library modules it statically links are
+// merged and compiled with profgen into a binary ART profile for the app's dex files. The binary
+// profile is embedded in the APK, where ART and the app store find it, and guides dexpreopt unless
+// the app sets dex_preopt.profile. profgen silently drops the rules that don't match the dex
+// files, so the build fails if the profile references a class that is not in them.
+
+import (
+	"github.com/google/blueprint"
+
+	"android/soong/android"
+)
+
+type BaselineProfileProperties struct {
+	// Human-readable baseline profiles of the module, e.g. baseline-prof.txt. The baseline
+	// profiles of android_library modules are compiled into the apps that statically link them.
+	Baseline_profiles []string `android:"path"`
+}
+
+// BaselineProfileInfo is provided by modules with baseline profiles to the modules that statically
+// link them.
+type BaselineProfileInfo struct {
+	// Human-readable baseline profiles of the module and of its static libraries.
+	Profiles android.Paths
+}
+
+var BaselineProfileInfoProvider = blueprint.NewProvider(BaselineProfileInfo{})
+
+// initBaselineProfiles adds the baseline_profiles property to the module. Apps compile the
+// baseline profiles into their APK, Android libraries only export them.
+func (j *Module) initBaselineProfiles(compile bool) {
+	j.baselineProfileProperties = &BaselineProfileProperties{}
+	j.compilesBaselineProfile = compile
+	j.AddProperties(j.baselineProfileProperties)
+}
+
+// collectBaselineProfiles collects the baseline profiles of the module and of its static libraries,
+// and provides them to the modules that statically link it.
+func (j *Module) collectBaselineProfiles(ctx android.ModuleContext) {
+	if j.baselineProfileProperties != nil {
+		j.baselineProfiles = android.PathsForModuleSrc(ctx, j.baselineProfileProperties.Baseline_profiles)
+	}
+	ctx.VisitDirectDepsWithTag(staticLibTag, func(module android.Module) {
+		if ctx.OtherModuleHasProvider(module, BaselineProfileInfoProvider) {
+			info := ctx.OtherModuleProvider(module, BaselineProfileInfoProvider).(BaselineProfileInfo)
+			j.baselineProfiles = append(j.baselineProfiles, info.Profiles...)
+		}
+	})
+	j.baselineProfiles = android.FirstUniquePaths(j.baselineProfiles)
+
+	if len(j.baselineProfiles) > 0 {
+		ctx.SetProvider(BaselineProfileInfoProvider, BaselineProfileInfo{Profiles: j.baselineProfiles})
+	}
+}
+
+// compileBaselineProfile compiles the baseline profiles of an app into a binary profile for its dex
+// jar, which is used by dexpreopt and embedded in the APK.
+func (j *Module) compileBaselineProfile(ctx android.ModuleContext, dexJar android.Path) {
+	if !j.compilesBaselineProfile || len(j.baselineProfiles) == 0 {
+		return
+	}
+
+	profile := android.PathForModuleOut(ctx, "baseline_profile", "baseline-prof.txt")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().Text("cat").Inputs(j.baselineProfiles).FlagWithOutput("> ", profile)
+	rule.Build("baseline_profile_merge", "merge baseline profiles")
+
+	// The classes of the profile must be in the dex files, after R8 renamed them.
+	checkFile := android.PathForModuleOut(ctx, "baseline_profile", "check.stamp")
+	rule = android.NewRuleBuilder(pctx, ctx)
+	cmd := rule.Command().BuiltTool("check_baseline_profile").
+		FlagWithInput("--profile ", profile).
+		FlagWithInput("--dex_jar ", dexJar)
+	if j.dexer.proguardDictionary.Valid() {
+		cmd.FlagWithInput("--map ", j.dexer.proguardDictionary.Path())
+	}
+	cmd.FlagWithOutput("--out ", checkFile)
+	rule.Build("baseline_profile_check", "check baseline profile")
+
+	binaryProfile := android.PathForModuleOut(ctx, "baseline_profile", "baseline.prof")
+	binaryProfileMetadata := android.PathForModuleOut(ctx, "baseline_profile", "baseline.profm")
+	rule = android.NewRuleBuilder(pctx, ctx)
+	cmd = rule.Command().BuiltTool("profgen").
+		Text("bin").
+		Input(profile).
+		FlagWithInput("--apk ", dexJar)
+	if j.dexer.proguardDictionary.Valid() {
+		cmd.FlagWithInput("--map ", j.dexer.proguardDictionary.Path())
+	}
+	cmd.FlagWithOutput("--output ", binaryProfile).
+		FlagWithOutput("--output-meta ", binaryProfileMetadata).
+		Validation(checkFile)
+	rule.Build("baseline_profile_compile", "compile baseline profile")
+
+	zip := android.PathForModuleOut(ctx, "baseline_profile", "baseline_profile.zip")
+	rule = android.NewRuleBuilder(pctx, ctx)
+	rule.Command().BuiltTool("soong_zip").
+		FlagWithOutput("-o ", zip).
+		FlagWithArg("-P ", "assets/dexopt").
+		Flag("-j").
+		FlagWithInput("-f ", binaryProfile).
+		FlagWithInput("-f ", binaryProfileMetadata)
+	rule.Build("baseline_profile_zip", "zip baseline profile")
+	j.baselineProfileZip = zip
+
+	// An explicit dex_preopt.profile takes precedence over the baseline profiles.
+	if j.dexpreopter.inputProfilePathOnHost == nil && String(j.dexpreoptProperties.Dex_preopt.Profile) == "" {
+		j.dexpreopter.inputProfilePathOnHost = binaryProfile
+	}
+}
+
+// embedBaselineProfile returns the dex jar of an app with the compiled baseline profile added
+// under assets/dexopt, where ART looks for it in the APK.
+func (j *Module) embedBaselineProfile(ctx android.ModuleContext, dexJar android.Path) android.Path {
+	if j.baselineProfileZip == nil || dexJar == nil {
+		return dexJar
+	}
+	jar := android.PathForModuleOut(ctx, "baseline_profile", dexJar.Base())
+	TransformJarsToJar(ctx, jar, "for baseline profile", android.Paths{dexJar, j.baselineProfileZip},
+		android.OptionalPath{}, false, nil, nil)
+	return jar
+}
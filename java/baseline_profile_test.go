@@ -0,0 +1,113 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestBaselineProfiles(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		android.FixtureMergeMockFs(android.MockFS{
+			"foo-prof.txt": nil,
+			"lib-prof.txt": nil,
+			"bar-prof.txt": nil,
+			"bar.prof.txt": nil,
+		}),
+	).RunTestWithBp(t, `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+			sdk_version: "current",
+			static_libs: ["lib"],
+			baseline_profiles: ["foo-prof.txt"],
+		}
+
+		android_app {
+			name: "bar",
+			srcs: ["a.java"],
+			sdk_version: "current",
+			baseline_profiles: ["bar-prof.txt"],
+			dex_preopt: {
+				profile: "bar.prof.txt",
+			},
+		}
+
+		android_app {
+			name: "baz",
+			srcs: ["a.java"],
+			sdk_version: "current",
+		}
+
+		android_library {
+			name: "lib",
+			srcs: ["b.java"],
+			sdk_version: "current",
+			baseline_profiles: ["lib-prof.txt"],
+		}
+	`)
+
+	foo := result.ModuleForTests("foo", "android_common")
+
+	// The profiles of the app and of its static libraries are merged.
+	merge := foo.Rule("baseline_profile_merge")
+	android.AssertPathsRelativeToTopEquals(t, "merged profiles",
+		[]string{"foo-prof.txt", "lib-prof.txt"}, merge.Implicits)
+
+	// The merged profile is checked against the dex jar and compiled for it.
+	check := foo.Rule("baseline_profile_check")
+	android.AssertStringDoesContain(t, "check dex jar", check.RuleParams.Command,
+		"--dex_jar out/soong/.intermediates/foo/android_common/dex/foo.jar")
+	android.AssertStringDoesContain(t, "check proguard dictionary", check.RuleParams.Command,
+		"--map out/soong/.intermediates/foo/android_common/proguard_dictionary")
+
+	compile := foo.Rule("baseline_profile_compile")
+	android.AssertStringDoesContain(t, "profgen outputs", compile.RuleParams.Command,
+		"--output out/soong/.intermediates/foo/android_common/baseline_profile/baseline.prof "+
+			"--output-meta out/soong/.intermediates/foo/android_common/baseline_profile/baseline.profm")
+	android.AssertPathsRelativeToTopEquals(t, "profgen validations",
+		[]string{"out/soong/.intermediates/foo/android_common/baseline_profile/check.stamp"},
+		compile.Validations)
+
+	// The binary profile is embedded in the APK and used by dexpreopt.
+	apk := foo.Output("foo-unsigned.apk")
+	android.AssertStringListContains(t, "apk inputs", android.PathsRelativeToTop(apk.Inputs),
+		"out/soong/.intermediates/foo/android_common/baseline_profile/foo.jar")
+	android.AssertPathRelativeToTopEquals(t, "dexpreopt profile",
+		"out/soong/.intermediates/foo/android_common/baseline_profile/baseline.prof",
+		foo.Module().(*AndroidApp).dexpreopter.inputProfilePathOnHost)
+
+	// An explicit dex_preopt.profile is used by dexpreopt instead.
+	bar := result.ModuleForTests("bar", "android_common")
+	bar.Rule("baseline_profile_compile")
+	if p := bar.Module().(*AndroidApp).dexpreopter.inputProfilePathOnHost; p != nil {
+		t.Errorf("expected no dexpreopt input profile for bar, got %s", p)
+	}
+
+	// Apps without baseline profiles don't compile one.
+	baz := result.ModuleForTests("baz", "android_common")
+	if baz.MaybeRule("baseline_profile_compile").Rule != nil {
+		t.Errorf("expected no baseline profile for baz")
+	}
+
+	// Libraries only export their profiles.
+	lib := result.ModuleForTests("lib", "android_common")
+	if lib.MaybeRule("baseline_profile_compile").Rule != nil {
+		t.Errorf("expected no baseline profile for lib")
+	}
+}
@@ -27,9 +27,24 @@ import (
 	"github.com/google/blueprint/proptools"
 
 	"android/soong/android"
+	"android/soong/java/config"
 	"android/soong/remoteexec"
 )
 
+// javacCommand is the command of the javac rules, with the templates of the remote execution of
+// javac and soong_zip.
+const javacCommand = `rm -rf "$outDir" "$annoDir" "$srcJarDir" "$out" && mkdir -p "$outDir" "$annoDir" "$srcJarDir" && ` +
+	`${config.ZipSyncCmd} -d $srcJarDir -l $srcJarDir/list -f "*.java" $srcJars && ` +
+	`(if [ -s $srcJarDir/list ] || [ -s $out.rsp ] ; then ` +
+	`${config.SoongJavacWrapper} --diagnostics_out $diagnostics $javaTemplate$javacCmd ` +
+	`${config.JavacHeapFlags} ${config.JavacVmFlags} ${config.CommonJdkFlags} ` +
+	`$processorpath $processor $javacFlags $bootClasspath $classpath ` +
+	`-source $javaVersion -target $javaVersion ` +
+	`-d $outDir -s $annoDir @$out.rsp @$srcJarDir/list ; ` +
+	`else ${config.SoongJavacWrapper} --diagnostics_out $diagnostics ; fi ) && ` +
+	`$zipTemplate${config.SoongZipCmd} -jar -o $out -C $outDir -D $outDir && ` +
+	`rm -rf "$srcJarDir"`
+
 var (
 	pctx = android.NewPackageContext("android/soong/java")
 
@@ -42,18 +57,9 @@ var (
 	// TODO(b/143658984): goma can't handle the --system argument to javac.
 	javac, javacRE = pctx.MultiCommandRemoteStaticRules("javac",
 		blueprint.RuleParams{
-			Command: `rm -rf "$outDir" "$annoDir" "$srcJarDir" "$out" && mkdir -p "$outDir" "$annoDir" "$srcJarDir" && ` +
-				`${config.ZipSyncCmd} -d $srcJarDir -l $srcJarDir/list -f "*.java" $srcJars && ` +
-				`(if [ -s $srcJarDir/list ] || [ -s $out.rsp ] ; then ` +
-				`${config.SoongJavacWrapper} $javaTemplate${config.JavacCmd} ` +
-				`${config.JavacHeapFlags} ${config.JavacVmFlags} ${config.CommonJdkFlags} ` +
-				`$processorpath $processor $javacFlags $bootClasspath $classpath ` +
-				`-source $javaVersion -target $javaVersion ` +
-				`-d $outDir -s $annoDir @$out.rsp @$srcJarDir/list ; fi ) && ` +
-				`$zipTemplate${config.SoongZipCmd} -jar -o $out -C $outDir -D $outDir && ` +
-				`rm -rf "$srcJarDir"`,
+			Command: javacCommand,
 			CommandDeps: []string{
-				"${config.JavacCmd}",
+				"$javacCmd",
 				"${config.SoongZipCmd}",
 				"${config.ZipSyncCmd}",
 			},
@@ -74,7 +80,66 @@ var (
 				Platform:     map[string]string{remoteexec.PoolKey: "${config.REJavaPool}"},
 			},
 		}, []string{"javacFlags", "bootClasspath", "classpath", "processorpath", "processor", "srcJars", "srcJarDir",
-			"outDir", "annoDir", "javaVersion"}, nil)
+			"outDir", "annoDir", "javaVersion", "diagnostics", "javacCmd"}, nil)
+
+	// javacCached is like javac, but runs the whole command through the action cache in
+	// $actionCache, which restores the jar and the diagnostics from the cache when the same
+	// command with the same inputs already ran in this or another output directory.
+	javacCached = pctx.AndroidStaticRule("javacCached",
+		blueprint.RuleParams{
+			Command: `$actionCache bash -c ` +
+				proptools.ShellEscape(strings.NewReplacer("$javaTemplate", "", "$zipTemplate", "").Replace(javacCommand)),
+			CommandDeps: []string{
+				"$javacCmd",
+				"${config.SoongZipCmd}",
+				"${config.ZipSyncCmd}",
+			},
+			CommandOrderOnly: []string{"${config.SoongJavacWrapper}"},
+			Rspfile:          "$out.rsp",
+			RspfileContent:   "$in",
+		}, "javacFlags", "bootClasspath", "classpath", "processorpath", "processor", "srcJars", "srcJarDir",
+		"outDir", "annoDir", "javaVersion", "diagnostics", "javacCmd", "actionCache")
+
+	// javacIncremental is like javac, but keeps $outDir between builds and lets incremental_javac
+	// recompile only the sources affected by a change. It can't be used with annotation
+	// processors or compiler plugins, whose outputs can't be attributed to single sources.
+	// The javac wrapper runs incremental_javac, which doesn't run javac when no source needs to be
+	// recompiled, so that the diagnostics are always written. incremental_javac lists the sources
+	// it recompiled or removed in $stateFile.stale, and the wrapper keeps the diagnostics of the
+	// other sources from the previous diagnostics file, so that they cover all the sources.
+	javacIncremental = pctx.AndroidStaticRule("javacIncremental",
+		blueprint.RuleParams{
+			Command: `rm -rf "$annoDir" "$srcJarDir" "$out" && mkdir -p "$outDir" "$annoDir" "$srcJarDir" && ` +
+				`${config.ZipSyncCmd} -d $srcJarDir -l $srcJarDir/list -f "*.java" $srcJars && ` +
+				`${config.SoongJavacWrapper} --diagnostics_out $diagnostics --stale_sources $stateFile.stale ` +
+				`${config.IncrementalJavacCmd} --out_dir $outDir --state $stateFile ` +
+				`--stale_sources_out $stateFile.stale ` +
+				`--srcs_rsp $out.rsp --srcs_list $srcJarDir/list $classpath -- ` +
+				`$javacCmd ` +
+				`${config.JavacHeapFlags} ${config.JavacVmFlags} ${config.CommonJdkFlags} ` +
+				`-proc:none $javacFlags $bootClasspath ` +
+				`-source $javaVersion -target $javaVersion -s $annoDir && ` +
+				`${config.SoongZipCmd} -jar -o $out -C $outDir -D $outDir && ` +
+				`rm -rf "$srcJarDir"`,
+			CommandDeps: []string{
+				"${config.IncrementalJavacCmd}",
+				"$javacCmd",
+				"${config.SoongZipCmd}",
+				"${config.ZipSyncCmd}",
+			},
+			CommandOrderOnly: []string{"${config.SoongJavacWrapper}"},
+			Rspfile:          "$out.rsp",
+			RspfileContent:   "$in",
+		}, "javacFlags", "bootClasspath", "classpath", "srcJars", "srcJarDir", "outDir", "annoDir",
+		"stateFile", "javaVersion", "diagnostics", "javacCmd")
+
+	// javacWarningBudget fails when the javac actions of a module report more warnings, in their
+	// diagnostics files, than the javac_warning_budget of the module.
+	javacWarningBudget = pctx.AndroidStaticRule("javacWarningBudget",
+		blueprint.RuleParams{
+			Command:     `${config.SoongJavacWrapper} --check_warning_budget $budget $in && touch $out`,
+			CommandDeps: []string{"${config.SoongJavacWrapper}"},
+		}, "budget")
 
 	_ = pctx.VariableFunc("kytheCorpus",
 		func(ctx android.PackageVarContext) string { return ctx.Config().XrefCorpusName() })
@@ -229,6 +294,15 @@ var (
 		},
 		"packages")
 
+	runtimeClasspathCheck = pctx.AndroidStaticRule("runtimeClasspathCheck",
+		blueprint.RuleParams{
+			Command: "rm -f $out && " +
+				"${config.RuntimeClasspathCheckCmd} $flags $in $allowedClasses && " +
+				"touch $out",
+			CommandDeps: []string{"${config.RuntimeClasspathCheckCmd}"},
+		},
+		"flags", "allowedClasses")
+
 	jetifier = pctx.AndroidStaticRule("jetifier",
 		blueprint.RuleParams{
 			Command:     "${config.JavaCmd}  ${config.JavaVmFlags} -jar ${config.JetifierJar} -l error -o $out -i $in -t epoch",
@@ -246,6 +320,30 @@ var (
 			CommandDeps: []string{"${config.ZipAlign}"},
 		},
 	)
+
+	normalizeJar = pctx.AndroidStaticRule("normalizeJar",
+		blueprint.RuleParams{
+			Command:     `${config.Zip2ZipCmd} -i $in -o $out -j -t -e`,
+			CommandDeps: []string{"${config.Zip2ZipCmd}"},
+		},
+	)
+
+	normalizeR8Map = pctx.AndroidStaticRule("normalizeR8Map",
+		blueprint.RuleParams{
+			Command:     `${config.NormalizeR8MapCmd} -i $in -o $out`,
+			CommandDeps: []string{"${config.NormalizeR8MapCmd}"},
+		},
+	)
+
+	// checkNormalizedJar fails if a normalized jar still has timestamps of the time of the build,
+	// which would make it differ between builds.
+	checkNormalizedJar = pctx.AndroidStaticRule("checkNormalizedJar",
+		blueprint.RuleParams{
+			Command:     `${config.FindEmbeddedTimestampsCmd} $epochFlag -o $out $in`,
+			CommandDeps: []string{"${config.FindEmbeddedTimestampsCmd}"},
+		},
+		"epochFlag",
+	)
 )
 
 func init() {
@@ -256,6 +354,10 @@ func init() {
 type javaBuilderFlags struct {
 	javacFlags string
 
+	// javacCmd is the javac of the JDK version of the module, the javac of the default JDK if it
+	// is empty.
+	javacCmd string
+
 	// bootClasspath is the list of jars that form the boot classpath (generally the java.* and
 	// android.* classes) for tools that still use it.  javac targeting 1.9 or higher uses
 	// systemModules and java9Classpath instead.
@@ -292,8 +394,10 @@ type javaBuilderFlags struct {
 	proto android.ProtoFlags
 }
 
+// TransformJavaToClasses compiles the java sources into .class files, and returns the JSON file
+// of the warnings and errors that javac reported.
 func TransformJavaToClasses(ctx android.ModuleContext, outputFile android.WritablePath, shardIdx int,
-	srcFiles, srcJars android.Paths, flags javaBuilderFlags, deps android.Paths) {
+	srcFiles, srcJars android.Paths, flags javaBuilderFlags, deps android.Paths) android.WritablePath {
 
 	// Compile java sources into .class files
 	desc := "javac"
@@ -301,7 +405,24 @@ func TransformJavaToClasses(ctx android.ModuleContext, outputFile android.Writab
 		desc += strconv.Itoa(shardIdx)
 	}
 
-	transformJavaToClasses(ctx, outputFile, shardIdx, srcFiles, srcJars, flags, deps, "javac", desc)
+	return transformJavaToClasses(ctx, normalizedJarInput(ctx, outputFile), shardIdx, srcFiles, srcJars,
+		flags, deps, "javac", desc)
+}
+
+// CheckJavacWarningBudget fails the build of timestamp when the diagnostics files report more
+// warnings than budget.
+func CheckJavacWarningBudget(ctx android.ModuleContext, timestamp android.WritablePath,
+	diagnostics android.Paths, budget int64) {
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        javacWarningBudget,
+		Description: "javac warning budget",
+		Output:      timestamp,
+		Inputs:      diagnostics,
+		Args: map[string]string{
+			"budget": strconv.FormatInt(budget, 10),
+		},
+	})
 }
 
 // Emits the rule to generate Xref input file (.kzip file) for the given set of source files and source jars
@@ -477,7 +598,7 @@ func TurbineApt(ctx android.ModuleContext, outputSrcJar, outputResJar android.Wr
 func transformJavaToClasses(ctx android.ModuleContext, outputFile android.WritablePath,
 	shardIdx int, srcFiles, srcJars android.Paths,
 	flags javaBuilderFlags, deps android.Paths,
-	intermediatesDir, desc string) {
+	intermediatesDir, desc string) android.WritablePath {
 
 	deps = append(deps, srcJars...)
 
@@ -517,29 +638,90 @@ func transformJavaToClasses(ctx android.ModuleContext, outputFile android.Writab
 		outDir = filepath.Join(shardDir, outDir)
 		annoDir = filepath.Join(shardDir, annoDir)
 	}
+	diagnostics := android.PathForModuleOut(ctx, intermediatesDir, outDir+".diagnostics.json")
+	javacCmd := flags.javacCmd
+	if javacCmd == "" {
+		javacCmd = config.JavacCmdForJdkVersion(config.JdkVersions.Default)
+	}
 	rule := javac
 	if ctx.Config().UseRBE() && ctx.Config().IsEnvTrue("RBE_JAVAC") {
 		rule = javacRE
+	} else if ctx.Config().IncrementalJavac() && len(flags.processorPath) == 0 {
+		ctx.Build(pctx, android.BuildParams{
+			Rule:           javacIncremental,
+			Description:    desc + " (incremental)",
+			Output:         outputFile,
+			ImplicitOutput: diagnostics,
+			Inputs:         srcFiles,
+			Implicits:      deps,
+			Args: map[string]string{
+				"javacFlags":    flags.javacFlags,
+				"bootClasspath": bootClasspath,
+				"classpath":     classpath.FormJavaClassPath("--classpath"),
+				"srcJars":       strings.Join(srcJars.Strings(), " "),
+				"srcJarDir":     android.PathForModuleOut(ctx, intermediatesDir, srcJarDir).String(),
+				"outDir":        android.PathForModuleOut(ctx, intermediatesDir, outDir).String(),
+				"annoDir":       android.PathForModuleOut(ctx, intermediatesDir, annoDir).String(),
+				"stateFile":     android.PathForModuleOut(ctx, intermediatesDir, outDir+".incremental.json").String(),
+				"javaVersion":   flags.javaVersion.String(),
+				"diagnostics":   diagnostics.String(),
+				"javacCmd":      javacCmd,
+			},
+		})
+		return diagnostics
+	}
+	args := map[string]string{
+		"javacFlags":    flags.javacFlags,
+		"bootClasspath": bootClasspath,
+		"classpath":     classpath.FormJavaClassPath("-classpath"),
+		"processorpath": flags.processorPath.FormJavaClassPath("-processorpath"),
+		"processor":     processor,
+		"srcJars":       strings.Join(srcJars.Strings(), " "),
+		"srcJarDir":     android.PathForModuleOut(ctx, intermediatesDir, srcJarDir).String(),
+		"outDir":        android.PathForModuleOut(ctx, intermediatesDir, outDir).String(),
+		"annoDir":       android.PathForModuleOut(ctx, intermediatesDir, annoDir).String(),
+		"javaVersion":   flags.javaVersion.String(),
+		"diagnostics":   diagnostics.String(),
+		"javacCmd":      javacCmd,
+	}
+	if rule == javac && ctx.Config().ActionCacheDir() != "" && javacArgsCacheable(args) {
+		// The jar and the diagnostics only depend on the sources in $out.rsp, the other inputs
+		// of the rule and the tools.
+		cacheInputs := append(android.Paths{}, deps...)
+		for _, tool := range []string{"zipsync", "soong_zip", "soong_javac_wrapper"} {
+			cacheInputs = append(cacheInputs, ctx.Config().HostToolPath(ctx, tool))
+		}
+		actionCacheCmd, actionCacheDeps := android.ActionCacheCommand(ctx, android.ActionCacheParams{
+			InputsFile: android.PathForModuleOut(ctx, intermediatesDir, outDir+".action_cache_inputs.rsp"),
+			Inputs:     cacheInputs,
+			RspFiles:   []string{outputFile.String() + ".rsp"},
+			Outputs:    android.WritablePaths{outputFile, diagnostics},
+		})
+		rule = javacCached
+		args["actionCache"] = actionCacheCmd
+		deps = append(deps, actionCacheDeps...)
 	}
 	ctx.Build(pctx, android.BuildParams{
-		Rule:        rule,
-		Description: desc,
-		Output:      outputFile,
-		Inputs:      srcFiles,
-		Implicits:   deps,
-		Args: map[string]string{
-			"javacFlags":    flags.javacFlags,
-			"bootClasspath": bootClasspath,
-			"classpath":     classpath.FormJavaClassPath("-classpath"),
-			"processorpath": flags.processorPath.FormJavaClassPath("-processorpath"),
-			"processor":     processor,
-			"srcJars":       strings.Join(srcJars.Strings(), " "),
-			"srcJarDir":     android.PathForModuleOut(ctx, intermediatesDir, srcJarDir).String(),
-			"outDir":        android.PathForModuleOut(ctx, intermediatesDir, outDir).String(),
-			"annoDir":       android.PathForModuleOut(ctx, intermediatesDir, annoDir).String(),
-			"javaVersion":   flags.javaVersion.String(),
-		},
+		Rule:           rule,
+		Description:    desc,
+		Output:         outputFile,
+		ImplicitOutput: diagnostics,
+		Inputs:         srcFiles,
+		Implicits:      deps,
+		Args:           args,
 	})
+	return diagnostics
+}
+
+// javacArgsCacheable returns whether the javac command with the given arguments can be quoted for
+// the action cache, which runs it with bash -c. Arguments with single quotes can't.
+func javacArgsCacheable(args map[string]string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, "'") {
+			return false
+		}
+	}
+	return true
 }
 
 func TransformResourcesToJar(ctx android.ModuleContext, outputFile android.WritablePath,
@@ -627,6 +809,27 @@ func CheckJarPackages(ctx android.ModuleContext, outputFile android.WritablePath
 	})
 }
 
+// CheckRuntimeClasspath checks that no class is provided by more than one of the jars listed in
+// the runtime classpath manifest, except for the allowed classes.
+func CheckRuntimeClasspath(ctx android.ModuleContext, outputFile android.WritablePath,
+	manifest android.Path, jars android.Paths, allowedClasses []string, ignoreResourceClasses bool) {
+	flags := ""
+	if ignoreResourceClasses {
+		flags = "--ignore-resource-classes"
+	}
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        runtimeClasspathCheck,
+		Description: "runtimeClasspathCheck",
+		Output:      outputFile,
+		Input:       manifest,
+		Implicits:   jars,
+		Args: map[string]string{
+			"flags":          flags,
+			"allowedClasses": strings.Join(proptools.NinjaAndShellEscapeList(allowedClasses), " "),
+		},
+	})
+}
+
 func TransformJetifier(ctx android.ModuleContext, outputFile android.WritablePath,
 	inputFile android.Path) {
 	ctx.Build(pctx, android.BuildParams{
@@ -650,6 +853,59 @@ func TransformZipAlign(ctx android.ModuleContext, outputFile android.WritablePat
 	})
 }
 
+// normalizedJarInput returns the path that an action producing outputFile should write to. When
+// Config.NormalizeJavaOutputs is set it is an intermediate path, and a rule is added to copy it
+// to outputFile with its entries sorted in jar order and its timestamps fixed. Otherwise it is
+// outputFile itself. The normalized jar is validated with find_embedded_timestamps, which fails
+// the build if it still has timestamps of the time of the build.
+func normalizedJarInput(ctx android.ModuleContext, outputFile android.WritablePath) android.WritablePath {
+	if !ctx.Config().NormalizeJavaOutputs() {
+		return outputFile
+	}
+	unnormalized := android.PathForModuleOut(ctx, "unnormalized", outputFile.Rel())
+	validation := android.PathForModuleOut(ctx, "embedded_timestamps", outputFile.Rel()+".txt")
+
+	epochFlag := ""
+	if epoch, ok := ctx.Config().SourceDateEpoch(); ok {
+		epochFlag = "-epoch " + strconv.FormatInt(epoch, 10)
+	}
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        checkNormalizedJar,
+		Description: "check embedded timestamps " + outputFile.Base(),
+		Input:       outputFile,
+		Output:      validation,
+		Args: map[string]string{
+			"epochFlag": epochFlag,
+		},
+	})
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        normalizeJar,
+		Description: "normalize " + outputFile.Base(),
+		Input:       unnormalized,
+		Output:      outputFile,
+		Validation:  validation,
+	})
+	return unnormalized
+}
+
+// normalizedR8MapInput returns the path that R8 should write the mapping file outputFile to. When
+// Config.NormalizeJavaOutputs is set it is an intermediate path, and a rule is added to copy it to
+// outputFile with its classes and members in a canonical order. Otherwise it is outputFile itself.
+func normalizedR8MapInput(ctx android.ModuleContext, outputFile android.WritablePath) android.WritablePath {
+	if !ctx.Config().NormalizeJavaOutputs() {
+		return outputFile
+	}
+	unnormalized := android.PathForModuleOut(ctx, "unnormalized", outputFile.Rel())
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        normalizeR8Map,
+		Description: "normalize " + outputFile.Base(),
+		Input:       unnormalized,
+		Output:      outputFile,
+	})
+	return unnormalized
+}
+
 type classpath android.Paths
 
 func (x *classpath) formJoinedClassPath(optName string, sep string) string {
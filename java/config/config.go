@@ -53,6 +53,16 @@ var (
 		"core-oj",
 		"core-libart",
 	}
+
+	// JdkVersions are the JDK versions in prebuilts/jdk that java modules may be compiled with.
+	// The default is the JDK that soong_ui sets up as ANDROID_JAVA_HOME.
+	JdkVersions = android.ToolchainVersions{
+		Name:            "JDK",
+		Property:        "jdk_version",
+		ProductVariable: "DefaultJdkVersion",
+		Allowed:         []string{"17", "21"},
+		Default:         "17",
+	}
 )
 
 var (
@@ -139,6 +149,10 @@ func init() {
 	pctx.SourcePathVariable("JavaToolchain", "${JavaHome}/bin")
 	pctx.SourcePathVariableWithEnvOverride("JavacCmd",
 		"${JavaToolchain}/javac", "ALTERNATE_JAVAC")
+	for _, version := range JdkVersions.Allowed {
+		pctx.SourcePathVariable("Jdk"+version+"JavacCmd",
+			"prebuilts/jdk/jdk"+version+"/${hostPrebuiltTag}/bin/javac")
+	}
 	pctx.SourcePathVariable("JavaCmd", "${JavaToolchain}/java")
 	pctx.SourcePathVariable("JarCmd", "${JavaToolchain}/jar")
 	pctx.SourcePathVariable("JavadocCmd", "${JavaToolchain}/javadoc")
@@ -152,10 +166,13 @@ func init() {
 
 	pctx.SourcePathVariable("JarArgsCmd", "build/soong/scripts/jar-args.sh")
 	pctx.SourcePathVariable("PackageCheckCmd", "build/soong/scripts/package-check.sh")
+	pctx.SourcePathVariable("RuntimeClasspathCheckCmd", "build/soong/scripts/runtime-classpath-check.sh")
 	pctx.HostBinToolVariable("ExtractJarPackagesCmd", "extract_jar_packages")
 	pctx.HostBinToolVariable("SoongZipCmd", "soong_zip")
 	pctx.HostBinToolVariable("MergeZipsCmd", "merge_zips")
 	pctx.HostBinToolVariable("Zip2ZipCmd", "zip2zip")
+	pctx.HostBinToolVariable("FindEmbeddedTimestampsCmd", "find_embedded_timestamps")
+	pctx.HostBinToolVariable("NormalizeR8MapCmd", "normalize_r8_map")
 	pctx.HostBinToolVariable("ZipSyncCmd", "zipsync")
 	pctx.HostBinToolVariable("ApiCheckCmd", "apicheck")
 	pctx.HostBinToolVariable("D8Cmd", "d8")
@@ -182,6 +199,7 @@ func init() {
 	pctx.HostJavaToolVariable("D8Jar", "d8.jar")
 
 	pctx.HostBinToolVariable("SoongJavacWrapper", "soong_javac_wrapper")
+	pctx.HostBinToolVariable("IncrementalJavacCmd", "incremental_javac")
 	pctx.HostBinToolVariable("DexpreoptGen", "dexpreopt_gen")
 
 	pctx.StaticVariableWithEnvOverride("REJavaPool", "RBE_JAVA_POOL", "java16")
@@ -299,3 +317,12 @@ func javaHome(ctx android.PathContext) android.SourcePath {
 		return android.PathForSource(ctx, ctx.Config().Getenv("ANDROID_JAVA_HOME"))
 	})
 }
+
+// JavacCmdForJdkVersion returns the javac of a version of JdkVersions. The default version is the
+// javac of ANDROID_JAVA_HOME, which can be overridden with ALTERNATE_JAVAC.
+func JavacCmdForJdkVersion(version string) string {
+	if version == JdkVersions.Default {
+		return "${config.JavacCmd}"
+	}
+	return "${config.Jdk" + version + "JavacCmd}"
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"encoding/json"
+	"sort"
+
+	"android/soong/android"
+	"android/soong/cc"
+	cc_config "android/soong/cc/config"
+	"android/soong/java/config"
+)
+
+// When SOONG_COVERAGE_DATA is set to a directory of coverage data collected from a coverage
+// build, i.e. .profraw or .profdata files of native code built with CLANG_COVERAGE=true and .ec
+// files of Java code built with EMMA_INSTRUMENT=true, `m coverage-report` merges it with the
+// instrumented modules of the build into a tree-wide report in $OUT_DIR/soong/coverage_report:
+// coverage.lcov, summary.json with the coverage rolled up by directory, and index.html.
+
+func init() {
+	RegisterCoverageReportBuildComponents(android.InitRegistrationContext)
+}
+
+func RegisterCoverageReportBuildComponents(ctx android.RegistrationContext) {
+	ctx.RegisterSingletonType("coverage_report", coverageReportSingletonFactory)
+}
+
+var PrepareForTestWithCoverageReport = android.FixtureRegisterWithContext(RegisterCoverageReportBuildComponents)
+
+// CoverageReportNativeModule is a native module in the manifest of the coverage report.
+type CoverageReportNativeModule struct {
+	Module string `json:"module"`
+	Dir    string `json:"dir"`
+
+	// The unstripped binary or shared library that llvm-cov reads the coverage mapping of.
+	Object string `json:"object"`
+}
+
+// CoverageReportJavaModule is a Java module in the manifest of the coverage report.
+type CoverageReportJavaModule struct {
+	Module string `json:"module"`
+	Dir    string `json:"dir"`
+
+	// The classes that jacoco instrumented, and the Java sources they are compiled from.
+	ClassesJar string   `json:"classes_jar"`
+	Srcs       []string `json:"srcs,omitempty"`
+}
+
+// CoverageReportManifest is the manifest of the instrumented modules passed to coverage_report.
+type CoverageReportManifest struct {
+	Native []CoverageReportNativeModule `json:"native"`
+	Java   []CoverageReportJavaModule   `json:"java"`
+}
+
+func coverageReportSingletonFactory() android.Singleton {
+	return &coverageReportSingleton{}
+}
+
+type coverageReportSingleton struct{}
+
+// coverageReportSrcs returns the Java sources of a module, which jacoco reports the coverage of.
+func (j *Module) coverageReportSrcs() android.Paths {
+	var srcs android.Paths
+	for _, src := range j.uniqueSrcFiles {
+		if src.Ext() == ".java" {
+			srcs = append(srcs, src)
+		}
+	}
+	return srcs
+}
+
+func (s *coverageReportSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	dataDir := ctx.Config().Getenv("SOONG_COVERAGE_DATA")
+	if dataDir == "" {
+		return
+	}
+
+	manifest := CoverageReportManifest{
+		Native: []CoverageReportNativeModule{},
+		Java:   []CoverageReportJavaModule{},
+	}
+	var inputs android.Paths
+	ctx.VisitAllModules(func(module android.Module) {
+		if !module.Enabled() {
+			return
+		}
+		if c, ok := module.(*cc.Module); ok {
+			if object := c.CoverageObjectFile(); object.Valid() {
+				manifest.Native = append(manifest.Native, CoverageReportNativeModule{
+					Module: ctx.ModuleName(c),
+					Dir:    ctx.ModuleDir(c),
+					Object: object.String(),
+				})
+				inputs = append(inputs, object.Path())
+			}
+			return
+		}
+		j, ok := module.(interface{ JacocoReportClassesFile() android.Path })
+		if !ok || j.JacocoReportClassesFile() == nil {
+			return
+		}
+		javaModule := CoverageReportJavaModule{
+			Module:     ctx.ModuleName(module),
+			Dir:        ctx.ModuleDir(module),
+			ClassesJar: j.JacocoReportClassesFile().String(),
+		}
+		if m, ok := module.(interface{ coverageReportSrcs() android.Paths }); ok {
+			javaModule.Srcs = m.coverageReportSrcs().Strings()
+		}
+		manifest.Java = append(manifest.Java, javaModule)
+		inputs = append(inputs, j.JacocoReportClassesFile())
+	})
+
+	sort.SliceStable(manifest.Native, func(i, j int) bool {
+		return manifest.Native[i].Object < manifest.Native[j].Object
+	})
+	sort.SliceStable(manifest.Java, func(i, j int) bool {
+		return manifest.Java[i].ClassesJar < manifest.Java[j].ClassesJar
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	manifestFile := android.PathForOutput(ctx, "coverage_report", "manifest.json")
+	android.WriteFileRule(ctx, manifestFile, string(data))
+
+	// The coverage data is collected outside of the build, so the report is regenerated every time
+	// it is built through a dependency on a phony target without inputs.
+	force := android.PathForPhony(ctx, "coverage-report-force")
+	ctx.Build(pctx, android.BuildParams{
+		Rule:   android.Phony,
+		Output: force,
+	})
+
+	outDir := android.PathForOutput(ctx, "coverage_report")
+	report := outDir.Join(ctx, "summary.json")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		BuiltTool("coverage_report").
+		FlagWithInput("--manifest ", manifestFile).
+		FlagWithArg("--data-dir ", dataDir).
+		FlagWithInput("--llvm-profdata ", cc_config.ClangPath(ctx, "bin/llvm-profdata")).
+		FlagWithInput("--llvm-cov ", cc_config.ClangPath(ctx, "bin/llvm-cov")).
+		FlagWithInput("--java ", config.JavaCmd(ctx)).
+		FlagWithInput("--jacoco-cli ", ctx.Config().HostJavaToolPath(ctx, "jacoco-cli.jar")).
+		FlagWithArg("--out-dir ", outDir.String()).
+		Implicits(android.FirstUniquePaths(inputs)).
+		Implicit(force).
+		ImplicitOutput(outDir.Join(ctx, "coverage.lcov")).
+		ImplicitOutput(outDir.Join(ctx, "index.html")).
+		ImplicitOutput(report)
+	rule.Build("coverage_report", "coverage report")
+
+	ctx.Phony("coverage-report", report)
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestCoverageReport(t *testing.T) {
+	bp := `
+		java_library {
+			name: "ims-common",
+			srcs: ["a.java"],
+			sdk_version: "current",
+		}
+
+		java_library {
+			name: "uninstrumented",
+			srcs: ["a.java"],
+			sdk_version: "current",
+		}
+	`
+
+	result := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		PrepareForTestWithCoverageReport,
+		android.FixtureMergeEnv(map[string]string{
+			"EMMA_INSTRUMENT_FRAMEWORK": "true",
+			"SOONG_COVERAGE_DATA":       "/tmp/coverage",
+		}),
+	).RunTestWithBp(t, bp)
+
+	singleton := result.SingletonForTests("coverage_report")
+	var manifest CoverageReportManifest
+	content := android.ContentFromFileRuleForTests(t, singleton.Output("coverage_report/manifest.json"))
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(manifest.Java) != 1 {
+		t.Fatalf("expected only ims-common to be instrumented, got %+v", manifest.Java)
+	}
+	module := manifest.Java[0]
+	android.AssertStringEquals(t, "module", "ims-common", module.Module)
+	if !strings.HasSuffix(module.ClassesJar, "/jacoco-report-classes/ims-common.jar") {
+		t.Errorf("unexpected classes jar %q", module.ClassesJar)
+	}
+	android.AssertDeepEquals(t, "srcs", []string{"a.java"}, module.Srcs)
+
+	report := singleton.Output("coverage_report/summary.json")
+	android.AssertStringDoesContain(t, "command", report.RuleParams.Command, "--data-dir /tmp/coverage")
+
+	// The report is only generated when SOONG_COVERAGE_DATA is set.
+	result = android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		PrepareForTestWithCoverageReport,
+	).RunTestWithBp(t, bp)
+	if result.SingletonForTests("coverage_report").MaybeOutput("coverage_report/summary.json").Rule != nil {
+		t.Errorf("expected no coverage report without SOONG_COVERAGE_DATA")
+	}
+}
@@ -368,10 +368,13 @@ func (d *dexer) compileDex(ctx android.ModuleContext, dexParams *compileDexParam
 		mergeZipsFlags = "-stripFile META-INF/*.kotlin_module -stripFile **/*.kotlin_builtins"
 	}
 
+	dexOutput := normalizedJarInput(ctx, javalibJar)
+
 	useR8 := d.effectiveOptimizeEnabled()
 	if useR8 {
 		proguardDictionary := android.PathForModuleOut(ctx, "proguard_dictionary")
 		d.proguardDictionary = android.OptionalPathForPath(proguardDictionary)
+		r8Dictionary := normalizedR8MapInput(ctx, proguardDictionary)
 		proguardConfiguration := android.PathForModuleOut(ctx, "proguard_configuration")
 		d.proguardConfiguration = android.OptionalPathForPath(proguardConfiguration)
 		proguardUsageDir := android.PathForModuleOut(ctx, "proguard_usage")
@@ -385,7 +388,7 @@ func (d *dexer) compileDex(ctx android.ModuleContext, dexParams *compileDexParam
 		args := map[string]string{
 			"r8Flags":        strings.Join(append(commonFlags, r8Flags...), " "),
 			"zipFlags":       zipFlags,
-			"outDict":        proguardDictionary.String(),
+			"outDict":        r8Dictionary.String(),
 			"outConfig":      proguardConfiguration.String(),
 			"outUsageDir":    proguardUsageDir.String(),
 			"outUsage":       proguardUsage.String(),
@@ -401,8 +404,8 @@ func (d *dexer) compileDex(ctx android.ModuleContext, dexParams *compileDexParam
 		ctx.Build(pctx, android.BuildParams{
 			Rule:            rule,
 			Description:     "r8",
-			Output:          javalibJar,
-			ImplicitOutputs: android.WritablePaths{proguardDictionary, proguardUsageZip},
+			Output:          dexOutput,
+			ImplicitOutputs: android.WritablePaths{r8Dictionary, proguardUsageZip},
 			Input:           dexParams.classesJar,
 			Implicits:       r8Deps,
 			Args:            args,
@@ -417,7 +420,7 @@ func (d *dexer) compileDex(ctx android.ModuleContext, dexParams *compileDexParam
 		ctx.Build(pctx, android.BuildParams{
 			Rule:        rule,
 			Description: "d8",
-			Output:      javalibJar,
+			Output:      dexOutput,
 			Input:       dexParams.classesJar,
 			Implicits:   d8Deps,
 			Args: map[string]string{
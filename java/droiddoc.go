@@ -813,6 +813,9 @@ func (d *Droiddoc) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 
 		d.postDoclavaCmds(ctx, rule)
 		desc = "doclava"
+
+		// Doclava embeds the date of the build in the docs.
+		rule.NondeterministicOutputs()
 	}
 
 	rule.Command().
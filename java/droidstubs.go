@@ -539,6 +539,10 @@ func metalavaCmd(ctx android.ModuleContext, rule *android.RuleBuilder, javaVersi
 	return cmd
 }
 
+// metalavaMemoryModel estimates the peak memory of metalava from its number of sources, see
+// android.UseHighmemPool.
+var metalavaMemoryModel = android.ActionMemoryModel{Name: "metalava", BaseMB: 1024, PerInputKB: 512}
+
 func (d *Droidstubs) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 	deps := d.Javadoc.collectDeps(ctx)
 
@@ -554,7 +558,9 @@ func (d *Droidstubs) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 		android.PathForModuleOut(ctx, "metalava.sbox.textproto")).
 		SandboxInputs()
 
-	if BoolDefault(d.properties.High_mem, false) {
+	inputs := len(d.Javadoc.srcFiles) + len(d.Javadoc.srcJars)
+	if BoolDefault(d.properties.High_mem, false) ||
+		android.UseHighmemPool(ctx, metalavaMemoryModel, android.PathForModuleOut(ctx, "metalava"), inputs) {
 		// This metalava run uses lots of memory, restrict the number of metalava jobs that can run in parallel.
 		rule.HighMem()
 	}
@@ -569,6 +575,10 @@ func (d *Droidstubs) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 	}
 
 	srcJarList := zipSyncCmd(ctx, rule, srcJarDir, d.Javadoc.srcJars)
+	if generateStubs {
+		android.RecordGeneratedSources(ctx, "metalava", android.Paths{d.Javadoc.stubsSrcJar},
+			append(append(android.Paths{}, d.Javadoc.srcFiles...), d.Javadoc.srcJars...))
+	}
 
 	homeDir := android.PathForModuleOut(ctx, "metalava", "home")
 	cmd := metalavaCmd(ctx, rule, javaVersion, d.Javadoc.srcFiles, srcJarList,
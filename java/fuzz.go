@@ -77,6 +77,15 @@ func JavaFuzzFactory() android.Module {
 	return module
 }
 
+var _ cc.JniSanitizeable = (*JavaFuzzTest)(nil)
+
+// IsSanitizerEnabledForJni returns true for the fuzzer sanitizer on Linux hosts, so that the JNI
+// libraries of the fuzz target and their shared dependencies are built with the fuzzer
+// instrumentation that Jazzer needs to guide fuzzing through native code.
+func (j *JavaFuzzTest) IsSanitizerEnabledForJni(sanitizerName string) bool {
+	return sanitizerName == "fuzzer" && j.Os() == android.Linux
+}
+
 func (j *JavaFuzzTest) DepsMutator(ctx android.BottomUpMutatorContext) {
 	if len(j.testProperties.Jni_libs) > 0 {
 		for _, target := range ctx.MultiTargets() {
@@ -94,4 +94,12 @@ func TestJavaFuzz(t *testing.T) {
 		t.Errorf(`expected foo test data relative path [%q], got %q`,
 			expected, fooJniFilePaths.Strings())
 	}
+
+	if runtime.GOOS == "linux" {
+		// The JNI libraries are built with fuzzer instrumentation.
+		libjni := ctx.ModuleForTests("libjni", "linux_glibc_x86_64_shared_fuzzer").Module().(*cc.Module)
+		if !libjni.IsSanitizerEnabled(cc.Fuzzer) {
+			t.Errorf("expected libjni to be built with the fuzzer sanitizer")
+		}
+	}
 }
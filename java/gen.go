@@ -55,6 +55,7 @@ func genAidl(ctx android.ModuleContext, aidlFiles android.Paths, aidlGlobalFlags
 	for i, shard := range shards {
 		srcJarFile := android.PathForModuleGen(ctx, "aidl", "aidl"+strconv.Itoa(i)+".srcjar")
 		srcJarFiles = append(srcJarFiles, srcJarFile)
+		android.RecordGeneratedSources(ctx, "aidl", android.Paths{srcJarFile}, shard)
 
 		outDir := srcJarFile.ReplaceExtension(ctx, "tmp")
 
@@ -236,6 +236,16 @@ type JavaInfo struct {
 	// set of header jars for all transitive static libs deps
 	TransitiveStaticLibsHeaderJars *android.DepSet
 
+	// DirectHeaderJars is a list of jars that contain the header classes of the sources of this
+	// module only, without those of its static libs.  Modules depending on this module are
+	// compiled against them instead of HeaderJars when the product sets Strict_java_deps.  If
+	// nil, the classes can't be separated and HeaderJars is used instead.
+	DirectHeaderJars android.Paths
+
+	// HeaderJarsStaticLibs is the list of the static libs of this module, transitively, whose
+	// classes are merged into HeaderJars.
+	HeaderJarsStaticLibs []string
+
 	// ImplementationAndResourceJars is a list of jars that contain the implementations of classes
 	// in the module as well as any resources included in the module.
 	ImplementationAndResourcesJars android.Paths
@@ -1059,6 +1069,22 @@ func (j *JavaTestImport) InstallInTestcases() bool {
 	return true
 }
 
+func (j *Test) TestSuites() []string {
+	return j.testProperties.Test_suites
+}
+
+func (j *TestHelperLibrary) TestSuites() []string {
+	return j.testHelperLibraryProperties.Test_suites
+}
+
+func (j *JavaTestImport) TestSuites() []string {
+	return j.prebuiltTestProperties.Test_suites
+}
+
+var _ android.TestSuiteModule = (*Test)(nil)
+var _ android.TestSuiteModule = (*TestHelperLibrary)(nil)
+var _ android.TestSuiteModule = (*JavaTestImport)(nil)
+
 func (j *TestHost) addDataDeviceBinsDeps(ctx android.BottomUpMutatorContext) {
 	if len(j.testHostProperties.Data_device_bins_first) > 0 {
 		deviceVariations := ctx.Config().AndroidFirstDeviceTarget.Variations()
@@ -1544,6 +1570,7 @@ func BinaryFactory() android.Module {
 
 	module.addHostAndDeviceProperties()
 	module.AddProperties(&module.binaryProperties)
+	module.initRuntimeClasspathCheck(false)
 
 	module.Module.properties.Installable = proptools.BoolPtr(true)
 
@@ -1563,6 +1590,7 @@ func BinaryHostFactory() android.Module {
 
 	module.addHostProperties()
 	module.AddProperties(&module.binaryProperties)
+	module.initRuntimeClasspathCheck(false)
 
 	module.Module.properties.Installable = proptools.BoolPtr(true)
 
@@ -2614,6 +2642,7 @@ func DefaultsFactory() android.Module {
 		&LintProperties{},
 		&appTestHelperAppProperties{},
 		&JavaApiLibraryProperties{},
+		&android.SizeBaselineProperties{},
 	)
 
 	android.InitDefaultsModule(module)
@@ -2636,6 +2665,8 @@ func (ks *kytheExtractJavaSingleton) GenerateBuildActions(ctx android.SingletonC
 	})
 	// TODO(asmundak): perhaps emit a rule to output a warning if there were no xrefTargets
 	if len(xrefTargets) > 0 {
+		// Link the generated sources in the compilation units to their origin.
+		xrefTargets = append(xrefTargets, android.GeneratedSourcesXrefPath(ctx))
 		ctx.Phony("xref_java", xrefTargets...)
 	}
 }
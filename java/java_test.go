@@ -1001,6 +1001,54 @@ func TestSharding(t *testing.T) {
 	}
 }
 
+func TestJavacWarningBudget(t *testing.T) {
+	ctx, _ := testJava(t, `
+		java_library {
+			name: "foo",
+			srcs: ["a.java"],
+		}
+
+		java_library {
+			name: "bar",
+			srcs: ["a.java","b.java","c.java"],
+			javac_shard_size: 1,
+			javac_warning_budget: 10,
+		}
+		`)
+
+	// Every javac action writes its diagnostics.
+	foo := ctx.ModuleForTests("foo", "android_common")
+	fooDiagnostics := foo.Output("javac/classes.diagnostics.json")
+	android.AssertStringEquals(t, "foo diagnostics rule", "javac", fooDiagnostics.Rule.String())
+	android.AssertStringDoesContain(t, "foo diagnostics", fooDiagnostics.Args["diagnostics"], "classes.diagnostics.json")
+	if foo.MaybeOutput("javac-warning-budget.stamp").Rule != nil {
+		t.Errorf("expected no warning budget check for foo")
+	}
+
+	// The warnings of the shards of bar are checked together against the budget of the module.
+	bar := ctx.ModuleForTests("bar", "android_common")
+	check := bar.Output("javac-warning-budget.stamp")
+	android.AssertStringEquals(t, "budget", "10", check.Args["budget"])
+	var expected []string
+	for i := 0; i < 3; i++ {
+		expected = append(expected, "out/soong/.intermediates/bar/android_common/javac/shard"+strconv.Itoa(i)+"/classes.diagnostics.json")
+	}
+	android.AssertPathsRelativeToTopEquals(t, "budget inputs", expected, check.Inputs)
+
+	barJar := bar.Output("javac-warning-budget/bar.jar")
+	android.AssertStringEquals(t, "validation", check.Output.String(), barJar.Validation.String())
+}
+
+func TestJavacWarningBudgetNegative(t *testing.T) {
+	testJavaError(t, `javac_warning_budget: must not be negative`, `
+		java_library {
+			name: "foo",
+			srcs: ["a.java"],
+			javac_warning_budget: -1,
+		}
+		`)
+}
+
 func TestExcludeFileGroupInSrcs(t *testing.T) {
 	ctx, _ := testJava(t, `
 		java_library {
@@ -1573,6 +1621,202 @@ func TestErrorproneEnabledOnlyByEnvironmentVariable(t *testing.T) {
 	}
 }
 
+func TestIncrementalJavac(t *testing.T) {
+	bp := `
+		java_library {
+			name: "foo",
+			srcs: ["a.java"],
+			libs: ["bar"],
+		}
+
+		java_library {
+			name: "bar",
+			srcs: ["b.java"],
+			plugins: ["plugin"],
+		}
+
+		java_plugin {
+			name: "plugin",
+		}
+	`
+	ctx := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		android.FixtureMergeEnv(map[string]string{
+			"SOONG_INCREMENTAL_JAVAC": "true",
+		}),
+	).RunTestWithBp(t, bp)
+
+	foo := ctx.ModuleForTests("foo", "android_common").Output("javac/foo.jar")
+	android.AssertStringEquals(t, "foo rule", javacIncremental.String(), foo.Rule.String())
+	android.AssertStringDoesContain(t, "foo classpath", foo.Args["classpath"], "--classpath ")
+	android.AssertStringDoesContain(t, "foo state file", foo.Args["stateFile"], "javac/classes.incremental.json")
+
+	// Modules with annotation processors are always compiled from scratch.
+	bar := ctx.ModuleForTests("bar", "android_common").Output("javac/bar.jar")
+	android.AssertStringEquals(t, "bar rule", javac.String(), bar.Rule.String())
+}
+
+func TestJavacActionCache(t *testing.T) {
+	bp := `
+		java_library {
+			name: "foo",
+			srcs: ["a.java"],
+			libs: ["bar"],
+		}
+
+		java_library {
+			name: "bar",
+			srcs: ["b.java"],
+			javacflags: ["-Afoo='bar'"],
+		}
+	`
+	ctx := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		android.FixtureMergeEnv(map[string]string{
+			"SOONG_ACTION_CACHE_DIR": "/tmp/action_cache",
+		}),
+	).RunTestWithBp(t, bp)
+
+	foo := ctx.ModuleForTests("foo", "android_common")
+	fooJavac := foo.Output("javac/foo.jar")
+	android.AssertStringEquals(t, "foo rule", javacCached.String(), fooJavac.Rule.String())
+	android.AssertStringDoesContain(t, "foo action cache", fooJavac.Args["actionCache"],
+		"--cache_dir /tmp/action_cache ")
+	android.AssertStringDoesContain(t, "foo sources", fooJavac.Args["actionCache"],
+		"/foo/android_common/javac/foo.jar.rsp ")
+	android.AssertStringDoesContain(t, "foo diagnostics", fooJavac.Args["actionCache"],
+		"/foo/android_common/javac/classes.diagnostics.json ")
+
+	inputs := android.ContentFromFileRuleForTests(t, foo.Output("javac/classes.action_cache_inputs.rsp"))
+	android.AssertStringDoesContain(t, "foo inputs", inputs, "bar/android_common/turbine-combined/bar.jar")
+	android.AssertStringDoesContain(t, "foo tools", inputs, "bin/soong_zip")
+
+	// Flags with single quotes can't be quoted for the action cache.
+	bar := ctx.ModuleForTests("bar", "android_common").Output("javac/bar.jar")
+	android.AssertStringEquals(t, "bar rule", javac.String(), bar.Rule.String())
+}
+
+func TestJdkVersion(t *testing.T) {
+	bp := `
+		java_library {
+			name: "foo",
+			srcs: ["a.java"],
+		}
+
+		java_library {
+			name: "bar",
+			srcs: ["b.java"],
+			jdk_version: "21",
+		}
+	`
+
+	result := PrepareForTestWithJavaDefaultModules.RunTestWithBp(t, bp)
+	foo := result.ModuleForTests("foo", "android_common").Output("javac/foo.jar")
+	android.AssertStringEquals(t, "foo javac", "${config.JavacCmd}", foo.Args["javacCmd"])
+	bar := result.ModuleForTests("bar", "android_common").Output("javac/bar.jar")
+	android.AssertStringEquals(t, "bar javac", "${config.Jdk21JavacCmd}", bar.Args["javacCmd"])
+
+	// The default of the product applies to the modules that don't select a version.
+	result = android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.DefaultJdkVersion = proptools.StringPtr("21")
+		}),
+	).RunTestWithBp(t, bp)
+	foo = result.ModuleForTests("foo", "android_common").Output("javac/foo.jar")
+	android.AssertStringEquals(t, "foo javac", "${config.Jdk21JavacCmd}", foo.Args["javacCmd"])
+}
+
+func TestJdkVersionNotAllowed(t *testing.T) {
+	testJavaError(t, `jdk_version: JDK version "11" is not one of the allowed versions`, `
+		java_library {
+			name: "foo",
+			srcs: ["a.java"],
+			jdk_version: "11",
+		}
+	`)
+}
+
+func TestNormalizeJavaOutputs(t *testing.T) {
+	bp := `
+		java_library {
+			name: "foo",
+			srcs: ["a.java"],
+			installable: true,
+		}
+
+		android_app {
+			name: "app",
+			srcs: ["a.java"],
+			platform_apis: true,
+		}
+	`
+	ctx := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		android.FixtureMergeEnv(map[string]string{
+			"SOONG_NORMALIZE_JAVA_OUTPUTS": "true",
+		}),
+	).RunTestWithBp(t, bp)
+
+	foo := ctx.ModuleForTests("foo", "android_common")
+	for _, jar := range []string{"javac/foo.jar", "dex/foo.jar"} {
+		normalize := foo.Output(jar)
+		android.AssertStringEquals(t, jar+" rule", normalizeJar.String(), normalize.Rule.String())
+		android.AssertPathRelativeToTopEquals(t, jar+" input",
+			"out/soong/.intermediates/foo/android_common/unnormalized/"+jar, normalize.Input)
+		android.AssertPathRelativeToTopEquals(t, jar+" validation",
+			"out/soong/.intermediates/foo/android_common/embedded_timestamps/"+jar+".txt",
+			normalize.Validation)
+
+		check := foo.Output("embedded_timestamps/" + jar + ".txt")
+		android.AssertStringEquals(t, jar+" check rule", checkNormalizedJar.String(), check.Rule.String())
+		android.AssertPathRelativeToTopEquals(t, jar+" check input",
+			"out/soong/.intermediates/foo/android_common/"+jar, check.Input)
+	}
+
+	javac := foo.Description("javac")
+	android.AssertPathRelativeToTopEquals(t, "javac output",
+		"out/soong/.intermediates/foo/android_common/unnormalized/javac/foo.jar", javac.Output)
+
+	app := ctx.ModuleForTests("app", "android_common")
+	normalizeMap := app.Output("proguard_dictionary")
+	android.AssertStringEquals(t, "mapping file rule", normalizeR8Map.String(), normalizeMap.Rule.String())
+	android.AssertPathRelativeToTopEquals(t, "mapping file input",
+		"out/soong/.intermediates/app/android_common/unnormalized/proguard_dictionary", normalizeMap.Input)
+	r8 := app.Rule("r8")
+	android.AssertStringEquals(t, "r8 mapping file",
+		"out/soong/.intermediates/app/android_common/unnormalized/proguard_dictionary",
+		android.StringRelativeToTop(ctx.Config, r8.Args["outDict"]))
+}
+
+func TestJavaWithConfigSnapshot(t *testing.T) {
+	// The config set up for the tests of a package can be reused by other tests, including tests of
+	// other packages, through a snapshot.
+	fixture := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		android.FixtureMergeEnv(map[string]string{
+			"SOONG_NORMALIZE_JAVA_OUTPUTS": "true",
+		}),
+	).Fixture(t)
+	snapshot, err := android.SnapshotTestConfig(fixture.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := android.GroupFixturePreparers(
+		android.FixtureRestoreConfigSnapshot(snapshot),
+		PrepareForTestWithJavaDefaultModules,
+	).RunTestWithBp(t, `
+		java_library {
+			name: "foo",
+			srcs: ["a.java"],
+		}
+	`)
+
+	foo := result.ModuleForTests("foo", "android_common")
+	android.AssertStringEquals(t, "javac/foo.jar rule", normalizeJar.String(), foo.Output("javac/foo.jar").Rule.String())
+}
+
 func TestDataDeviceBinsBuildsDeviceBinary(t *testing.T) {
 	testCases := []struct {
 		dataDeviceBinType  string
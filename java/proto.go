@@ -38,6 +38,7 @@ func genProto(ctx android.ModuleContext, protoFiles android.Paths, flags android
 	for i, shard := range shards {
 		srcJarFile := android.PathForModuleGen(ctx, "proto", "proto"+strconv.Itoa(i)+".srcjar")
 		srcJarFiles = append(srcJarFiles, srcJarFile)
+		android.RecordGeneratedSources(ctx, "proto", android.Paths{srcJarFile}, shard)
 
 		outDir := srcJarFile.ReplaceExtension(ctx, "tmp")
 
@@ -0,0 +1,108 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"encoding/json"
+
+	"android/soong/android"
+)
+
+// The resources of an app and of the android_library modules it statically links are checked for
+// identical resources defined under different names, which each end up in the APK. The report of
+// each app, resource_duplicates.json in its intermediates directory, lists the duplicated resource
+// files and values with the bytes they waste, by library. `m resource-duplicates` builds the
+// reports of all the apps.
+
+// resourceDuplicatesManifestLibrary is an entry of the manifest passed to find_duplicate_resources.
+type resourceDuplicatesManifestLibrary struct {
+	Library string
+	Dirs    []resourceDuplicatesManifestDir
+}
+
+type resourceDuplicatesManifestDir struct {
+	Dir   string
+	Files []string
+}
+
+// resourceDirsProvider is implemented by the modules that compile resources from source
+// directories, i.e. the modules that embed aapt.
+type resourceDirsProvider interface {
+	aaptResourceDirs() []globbedResourceDir
+}
+
+func (a *aapt) aaptResourceDirs() []globbedResourceDir {
+	return a.resourceDirs
+}
+
+// resourceDuplicatesBuildActions creates the rule that checks the resources of the app and of the
+// android_library modules it statically links for duplicates.
+func resourceDuplicatesBuildActions(ctx android.ModuleContext, a *aapt) {
+	var manifest []resourceDuplicatesManifestLibrary
+	var inputs android.Paths
+	addLibrary := func(name string, dirs []globbedResourceDir) {
+		lib := resourceDuplicatesManifestLibrary{Library: name}
+		for _, dir := range dirs {
+			lib.Dirs = append(lib.Dirs, resourceDuplicatesManifestDir{
+				Dir:   dir.dir.String(),
+				Files: dir.files.Strings(),
+			})
+			inputs = append(inputs, dir.files...)
+		}
+		manifest = append(manifest, lib)
+	}
+
+	addLibrary(ctx.ModuleName(), a.aaptResourceDirs())
+	seen := map[string]bool{ctx.ModuleName(): true}
+	ctx.WalkDeps(func(child, parent android.Module) bool {
+		if ctx.OtherModuleDependencyTag(child) != staticLibTag {
+			return false
+		}
+		lib, ok := child.(resourceDirsProvider)
+		if !ok {
+			return false
+		}
+		name := ctx.OtherModuleName(child)
+		if !seen[name] {
+			seen[name] = true
+			addLibrary(name, lib.aaptResourceDirs())
+		}
+		return true
+	})
+
+	if len(inputs) == 0 {
+		return
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		ctx.ModuleErrorf("%s", err)
+		return
+	}
+	manifestFile := android.PathForModuleOut(ctx, "resource_duplicates", "manifest.json")
+	android.WriteFileRule(ctx, manifestFile, string(data))
+
+	report := android.PathForModuleOut(ctx, "resource_duplicates.json")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		BuiltTool("find_duplicate_resources").
+		FlagWithArg("-app ", ctx.ModuleName()).
+		FlagWithInput("-manifest ", manifestFile).
+		Implicits(android.FirstUniquePaths(inputs)).
+		FlagWithOutput("-o ", report)
+	rule.Build("resource_duplicates", "find duplicate resources")
+
+	ctx.Phony("resource-duplicates", report)
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"encoding/json"
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestResourceDuplicates(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		android.FixtureMergeMockFs(android.MockFS{
+			"app/res/values/strings.xml":  nil,
+			"liba/res/values/strings.xml": nil,
+			"libb/res/drawable/icon.png":  nil,
+		}),
+	).RunTestWithBp(t, `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+			sdk_version: "current",
+			resource_dirs: ["app/res"],
+			static_libs: ["liba", "libjava"],
+		}
+
+		android_library {
+			name: "liba",
+			srcs: ["a.java"],
+			sdk_version: "current",
+			resource_dirs: ["liba/res"],
+			static_libs: ["libb"],
+		}
+
+		android_library {
+			name: "libb",
+			srcs: ["a.java"],
+			sdk_version: "current",
+			resource_dirs: ["libb/res"],
+		}
+
+		java_library {
+			name: "libjava",
+			srcs: ["a.java"],
+			sdk_version: "current",
+		}
+	`)
+
+	foo := result.ModuleForTests("foo", "android_common")
+
+	var manifest []resourceDuplicatesManifestLibrary
+	content := android.ContentFromFileRuleForTests(t, foo.Output("resource_duplicates/manifest.json"))
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	android.AssertDeepEquals(t, "manifest", []resourceDuplicatesManifestLibrary{
+		{Library: "foo", Dirs: []resourceDuplicatesManifestDir{{Dir: "app/res", Files: []string{"app/res/values/strings.xml"}}}},
+		{Library: "liba", Dirs: []resourceDuplicatesManifestDir{{Dir: "liba/res", Files: []string{"liba/res/values/strings.xml"}}}},
+		{Library: "libb", Dirs: []resourceDuplicatesManifestDir{{Dir: "libb/res", Files: []string{"libb/res/drawable/icon.png"}}}},
+	}, manifest)
+
+	report := foo.Output("resource_duplicates.json")
+	android.AssertStringDoesContain(t, "command", report.RuleParams.Command, "find_duplicate_resources -app foo")
+	for _, file := range []string{
+		"app/res/values/strings.xml",
+		"liba/res/values/strings.xml",
+		"libb/res/drawable/icon.png",
+	} {
+		android.AssertStringListContains(t, "implicits", report.Implicits.Strings(), file)
+	}
+}
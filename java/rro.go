@@ -152,14 +152,12 @@ func (r *RuntimeResourceOverlay) GenerateAndroidBuildActions(ctx android.ModuleC
 	_, _, certificates := collectAppDeps(ctx, r, false, false)
 	r.certificate, certificates = processMainCert(r.ModuleBase, String(r.properties.Certificate), certificates, ctx)
 	signed := android.PathForModuleOut(ctx, "signed", r.Name()+".apk")
-	var lineageFile android.Path
+	signingLineage := SigningLineage{RotationMinSdkVersion: String(r.properties.RotationMinSdkVersion)}
 	if lineage := String(r.properties.Lineage); lineage != "" {
-		lineageFile = android.PathForModuleSrc(ctx, lineage)
+		signingLineage.File = android.PathForModuleSrc(ctx, lineage)
 	}
 
-	rotationMinSdkVersion := String(r.properties.RotationMinSdkVersion)
-
-	SignAppPackage(ctx, signed, r.aapt.exportPackage, certificates, nil, lineageFile, rotationMinSdkVersion)
+	SignAppPackage(ctx, signed, r.aapt.exportPackage, certificates, nil, signingLineage)
 
 	r.outputFile = signed
 	partition := rroPartition(ctx)
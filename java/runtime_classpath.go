@@ -0,0 +1,88 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+// This file checks the runtime classpath of java binaries and apps. The classes of a module and of
+// its static libraries are merged into a single jar, and a class that is provided by more than one
+// of them silently resolves to the first copy. When the copies differ, the binary or app fails at
+// runtime, e.g. with a NoSuchMethodError, on whichever device happens to exercise the code. The
+// jars are listed in order in a runtime classpath manifest, and the build fails if two of them
+// provide the same class, unless the class is allowed.
+
+import (
+	"strings"
+
+	"android/soong/android"
+)
+
+type RuntimeClasspathProperties struct {
+	Runtime_classpath struct {
+		// If set to false, don't fail the build when more than one jar on the runtime classpath
+		// provides the same class. Defaults to true.
+		Check_duplicate_classes *bool
+
+		// Fully qualified names of classes that are allowed to be provided by more than one jar
+		// on the runtime classpath, which also allows their nested classes, or packages followed
+		// by ".*", which also allows their sub-packages. The first jar on the classpath provides
+		// the class at runtime.
+		Allowed_duplicate_classes []string
+	}
+}
+
+// initRuntimeClasspathCheck enables the runtime classpath manifest and check for the module. Apps
+// ignore the R and Manifest classes, which aapt2 generates into every Android library and which
+// the app's own copies with the final resource IDs are expected to override.
+func (j *Module) initRuntimeClasspathCheck(ignoreResourceClasses bool) {
+	j.runtimeClasspathProperties = &RuntimeClasspathProperties{}
+	j.runtimeClasspathIgnoresResourceClasses = ignoreResourceClasses
+	j.AddProperties(j.runtimeClasspathProperties)
+}
+
+// checkRuntimeClasspath writes the runtime classpath manifest of the module, and returns a copy of
+// the jar that depends on the duplicate class check so that using the jar runs the check.
+func (j *Module) checkRuntimeClasspath(ctx android.ModuleContext, jars android.Paths,
+	jar android.OutputPath, jarName string) android.OutputPath {
+
+	manifest := android.PathForModuleOut(ctx, "runtime_classpath.txt")
+	android.WriteFileRule(ctx, manifest, strings.Join(jars.Strings(), "\n"))
+	j.runtimeClasspathManifest = manifest
+
+	props := &j.runtimeClasspathProperties.Runtime_classpath
+	for _, class := range props.Allowed_duplicate_classes {
+		if class == "" || strings.ContainsAny(class, "/ ") {
+			ctx.PropertyErrorf("runtime_classpath.allowed_duplicate_classes",
+				"invalid class %q, use dot notation for classes and packages", class)
+		}
+	}
+	if !BoolDefault(props.Check_duplicate_classes, true) || len(jars) < 2 || ctx.Failed() {
+		return jar
+	}
+
+	// Time stamp file created by the runtime classpath check rule.
+	checkFile := android.PathForModuleOut(ctx, "runtime-classpath-check.stamp")
+	CheckRuntimeClasspath(ctx, checkFile, manifest, jars, props.Allowed_duplicate_classes,
+		j.runtimeClasspathIgnoresResourceClasses)
+
+	checkedJar := android.PathForModuleOut(ctx, "runtime-classpath-check", jarName).OutputPath
+	ctx.Build(pctx, android.BuildParams{
+		Rule:   android.Cp,
+		Input:  jar,
+		Output: checkedJar,
+		// Make sure that any dependency on the output file will cause ninja to run the runtime
+		// classpath check rule.
+		Validation: checkFile,
+	})
+	return checkedJar
+}
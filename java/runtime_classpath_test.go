@@ -0,0 +1,125 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"strings"
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestRuntimeClasspathCheck(t *testing.T) {
+	result := prepareForJavaTest.RunTestWithBp(t, `
+		java_library_host {
+			name: "foo",
+			srcs: ["a.java"],
+		}
+
+		java_library_host {
+			name: "baz",
+			srcs: ["c.java"],
+		}
+
+		java_binary_host {
+			name: "bar",
+			srcs: ["b.java"],
+			static_libs: ["foo", "baz"],
+			runtime_classpath: {
+				allowed_duplicate_classes: ["com.example.Foo", "com.example.shared.*"],
+			},
+		}
+
+		java_binary_host {
+			name: "unchecked",
+			srcs: ["b.java"],
+			static_libs: ["foo"],
+			runtime_classpath: {
+				check_duplicate_classes: false,
+			},
+		}
+	`)
+
+	buildOS := result.Config.BuildOS.String()
+	bar := result.ModuleForTests("bar", buildOS+"_common")
+	foo := result.ModuleForTests("foo", buildOS+"_common")
+	baz := result.ModuleForTests("baz", buildOS+"_common")
+
+	expectedClasspath := []string{
+		bar.Output("javac/bar.jar").Output.String(),
+		foo.Output("javac/foo.jar").Output.String(),
+		baz.Output("javac/baz.jar").Output.String(),
+	}
+	manifest := bar.Output("runtime_classpath.txt")
+	android.AssertStringEquals(t, "runtime classpath manifest", strings.Join(expectedClasspath, "\n"),
+		android.ContentFromFileRuleForTests(t, manifest))
+
+	check := bar.Rule("runtimeClasspathCheck")
+	android.AssertPathRelativeToTopEquals(t, "check input", android.PathRelativeToTop(manifest.Output), check.Input)
+	android.AssertStringEquals(t, "allowed classes", "com.example.Foo 'com.example.shared.*'",
+		check.Args["allowedClasses"])
+	android.AssertStringEquals(t, "flags", "", check.Args["flags"])
+
+	// The jar that is installed depends on the check.
+	checked := bar.Output("runtime-classpath-check/bar.jar")
+	android.AssertPathRelativeToTopEquals(t, "check validation", android.PathRelativeToTop(check.Output), checked.Validation)
+
+	android.AssertPathsRelativeToTopEquals(t, "runtime classpath output file",
+		[]string{android.PathRelativeToTop(manifest.Output)}, bar.OutputFiles(t, ".runtime_classpath"))
+
+	unchecked := result.ModuleForTests("unchecked", buildOS+"_common")
+	unchecked.Output("runtime_classpath.txt")
+	if unchecked.MaybeRule("runtimeClasspathCheck").Rule != nil {
+		t.Errorf("expected no runtime classpath check with check_duplicate_classes: false")
+	}
+}
+
+func TestRuntimeClasspathCheckApp(t *testing.T) {
+	result := prepareForJavaTest.RunTestWithBp(t, `
+		android_library {
+			name: "foo",
+			srcs: ["a.java"],
+			sdk_version: "current",
+		}
+
+		android_app {
+			name: "app",
+			srcs: ["b.java"],
+			static_libs: ["foo"],
+			sdk_version: "current",
+		}
+	`)
+
+	check := result.ModuleForTests("app", "android_common").Rule("runtimeClasspathCheck")
+	android.AssertStringEquals(t, "flags", "--ignore-resource-classes", check.Args["flags"])
+}
+
+func TestRuntimeClasspathCheckInvalidAllowedClass(t *testing.T) {
+	testJavaError(t, `invalid class "com/example/Foo", use dot notation for classes and packages`, `
+		java_library_host {
+			name: "foo",
+			srcs: ["a.java"],
+		}
+
+		java_binary_host {
+			name: "bar",
+			srcs: ["b.java"],
+			static_libs: ["foo"],
+			runtime_classpath: {
+				allowed_duplicate_classes: ["com/example/Foo"],
+			},
+		}
+	`)
+}
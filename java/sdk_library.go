@@ -2102,6 +2102,7 @@ type bazelSdkLibraryAttributes struct {
 // java_sdk_library bp2build converter
 func (module *SdkLibrary) ConvertWithBp2build(ctx android.TopDownMutatorContext) {
 	if ctx.ModuleType() != "java_sdk_library" {
+		ctx.MarkBp2buildUnconvertible(android.UnconvertedReasonTypeUnsupported, "")
 		return
 	}
 
@@ -0,0 +1,188 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"encoding/json"
+	"sort"
+
+	"android/soong/android"
+)
+
+// The header jar of a java library merges the classes of its static libs, so a module that
+// depends on the library can compile against the classes of those static libs without depending
+// on them, similar to the transitive classpath that Bazel's strict_java_deps rejects.  When the
+// product sets Strict_java_deps, modules are compiled against only the classes of the sources of
+// their direct dependencies.
+//
+// `m strict_java_deps` writes $OUT_DIR/soong/strict_java_deps.json, which lists for every module
+// the static libs that are on its compile classpath only through a direct dependency, and so have
+// to be added to its libs or static_libs if it uses them before Strict_java_deps can be set.
+
+func init() {
+	RegisterStrictJavaDepsBuildComponents(android.InitRegistrationContext)
+}
+
+func RegisterStrictJavaDepsBuildComponents(ctx android.RegistrationContext) {
+	ctx.RegisterSingletonType("strict_java_deps", strictJavaDepsSingletonFactory)
+}
+
+var PrepareForTestWithStrictJavaDeps = android.FixtureRegisterWithContext(RegisterStrictJavaDepsBuildComponents)
+
+// StrictJavaDepsLeak is a static lib of a direct dependency of a module that is not a direct
+// dependency of the module itself.
+type StrictJavaDepsLeak struct {
+	// The static lib, which is the dependency to add to the module if it uses its classes.
+	Dep string `json:"dep"`
+
+	// The direct dependency of the module that the static lib is merged into.
+	Via string `json:"via"`
+}
+
+// StrictJavaDepsModule is a module in the strict java deps report.
+type StrictJavaDepsModule struct {
+	Module string               `json:"module"`
+	Dir    string               `json:"dir"`
+	Leaks  []StrictJavaDepsLeak `json:"leaks"`
+}
+
+// StrictJavaDepsReport is the migration report of the modules that would lose classes from their
+// compile classpath with Strict_java_deps.
+type StrictJavaDepsReport struct {
+	// Whether the product sets Strict_java_deps.
+	Strict  bool                   `json:"strict"`
+	Modules []StrictJavaDepsModule `json:"modules"`
+}
+
+// strictJavaDepsChecker selects the header jars of the direct dependencies of a module that it
+// is compiled against, and collects the static libs of those dependencies that leak onto its
+// compile classpath.
+type strictJavaDepsChecker struct {
+	strict     bool
+	directDeps map[string]bool
+	candidates []StrictJavaDepsLeak
+}
+
+func newStrictJavaDepsChecker(ctx android.BaseModuleContext) *strictJavaDepsChecker {
+	return &strictJavaDepsChecker{
+		strict:     ctx.Config().StrictJavaDeps(),
+		directDeps: make(map[string]bool),
+	}
+}
+
+func (c *strictJavaDepsChecker) addDirectDep(name string) {
+	c.directDeps[name] = true
+}
+
+// compileHeaderJars returns the header jars of the direct dependency name to put on the compile
+// classpath.
+func (c *strictJavaDepsChecker) compileHeaderJars(name string, dep JavaInfo) android.Paths {
+	if dep.DirectHeaderJars == nil {
+		return dep.HeaderJars
+	}
+	for _, lib := range dep.HeaderJarsStaticLibs {
+		c.candidates = append(c.candidates, StrictJavaDepsLeak{Dep: lib, Via: name})
+	}
+	if c.strict {
+		return dep.DirectHeaderJars
+	}
+	return dep.HeaderJars
+}
+
+// leaks returns the static libs of the direct dependencies that are not direct dependencies
+// themselves, once each.
+func (c *strictJavaDepsChecker) leaks() []StrictJavaDepsLeak {
+	var leaks []StrictJavaDepsLeak
+	seen := make(map[string]bool)
+	for _, leak := range c.candidates {
+		if c.directDeps[leak.Dep] || seen[leak.Dep] {
+			continue
+		}
+		seen[leak.Dep] = true
+		leaks = append(leaks, leak)
+	}
+	return leaks
+}
+
+// StrictJavaDepsLeaks returns the static libs of the direct dependencies of the module that are
+// on its compile classpath without being direct dependencies.
+func (j *Module) StrictJavaDepsLeaks() []StrictJavaDepsLeak {
+	return j.strictJavaDepsLeaks
+}
+
+func strictJavaDepsSingletonFactory() android.Singleton {
+	return &strictJavaDepsSingleton{}
+}
+
+type strictJavaDepsSingleton struct{}
+
+func (s *strictJavaDepsSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	type moduleKey struct{ dir, name string }
+	modules := make(map[moduleKey]*StrictJavaDepsModule)
+	ctx.VisitAllModules(func(module android.Module) {
+		if !module.Enabled() {
+			return
+		}
+		j, ok := module.(interface{ StrictJavaDepsLeaks() []StrictJavaDepsLeak })
+		if !ok || len(j.StrictJavaDepsLeaks()) == 0 {
+			return
+		}
+		// Merge the leaks of all the variants of the module.
+		key := moduleKey{ctx.ModuleDir(module), ctx.ModuleName(module)}
+		m := modules[key]
+		if m == nil {
+			m = &StrictJavaDepsModule{Module: key.name, Dir: key.dir}
+			modules[key] = m
+		}
+		for _, leak := range j.StrictJavaDepsLeaks() {
+			if !strictJavaDepsHasLeak(m.Leaks, leak.Dep) {
+				m.Leaks = append(m.Leaks, leak)
+			}
+		}
+	})
+
+	report := StrictJavaDepsReport{
+		Strict:  ctx.Config().StrictJavaDeps(),
+		Modules: []StrictJavaDepsModule{},
+	}
+	for _, m := range modules {
+		sort.SliceStable(m.Leaks, func(i, j int) bool { return m.Leaks[i].Dep < m.Leaks[j].Dep })
+		report.Modules = append(report.Modules, *m)
+	}
+	sort.Slice(report.Modules, func(i, j int) bool {
+		if report.Modules[i].Dir != report.Modules[j].Dir {
+			return report.Modules[i].Dir < report.Modules[j].Dir
+		}
+		return report.Modules[i].Module < report.Modules[j].Module
+	})
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		ctx.Errorf("%s", err.Error())
+		return
+	}
+	reportFile := android.PathForOutput(ctx, "strict_java_deps.json")
+	android.WriteFileRule(ctx, reportFile, string(data))
+	ctx.Phony("strict_java_deps", reportFile)
+}
+
+func strictJavaDepsHasLeak(leaks []StrictJavaDepsLeak, dep string) bool {
+	for _, leak := range leaks {
+		if leak.Dep == dep {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"android/soong/android"
+
+	"github.com/google/blueprint/proptools"
+)
+
+const strictJavaDepsBp = `
+	java_library {
+		name: "foo",
+		srcs: ["a.java"],
+		libs: ["bar", "qux"],
+	}
+
+	java_library {
+		name: "bar",
+		srcs: ["b.java"],
+		static_libs: ["baz"],
+	}
+
+	java_library {
+		name: "baz",
+		srcs: ["c.java"],
+		static_libs: ["qux"],
+	}
+
+	java_library {
+		name: "qux",
+		srcs: ["d.java"],
+	}
+`
+
+func strictJavaDepsReportForTests(t *testing.T, result *android.TestResult) StrictJavaDepsReport {
+	t.Helper()
+	singleton := result.SingletonForTests("strict_java_deps")
+	var report StrictJavaDepsReport
+	content := android.ContentFromFileRuleForTests(t, singleton.Output("strict_java_deps.json"))
+	if err := json.Unmarshal([]byte(content), &report); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestStrictJavaDepsReport(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		PrepareForTestWithStrictJavaDeps,
+	).RunTestWithBp(t, strictJavaDepsBp)
+
+	// Without Strict_java_deps the header jars of the static libs of bar are on the classpath.
+	javac := result.ModuleForTests("foo", "android_common").Rule("javac")
+	barTurbine := filepath.Join("out", "soong", ".intermediates", "bar", "android_common", "turbine-combined", "bar.jar")
+	android.AssertStringDoesContain(t, "foo classpath", javac.Args["classpath"], barTurbine)
+
+	report := strictJavaDepsReportForTests(t, result)
+	android.AssertBoolEquals(t, "strict", false, report.Strict)
+	// qux is a direct dependency of foo, so only baz leaks onto its classpath.
+	android.AssertDeepEquals(t, "modules", []StrictJavaDepsModule{
+		{
+			Module: "bar",
+			Dir:    ".",
+			Leaks:  []StrictJavaDepsLeak{{Dep: "qux", Via: "baz"}},
+		},
+		{
+			Module: "foo",
+			Dir:    ".",
+			Leaks:  []StrictJavaDepsLeak{{Dep: "baz", Via: "bar"}},
+		},
+	}, report.Modules)
+}
+
+func TestStrictJavaDeps(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		PrepareForTestWithJavaDefaultModules,
+		PrepareForTestWithStrictJavaDeps,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.Strict_java_deps = proptools.BoolPtr(true)
+		}),
+	).RunTestWithBp(t, strictJavaDepsBp)
+
+	foo := result.ModuleForTests("foo", "android_common")
+	javac := foo.Rule("javac")
+	barTurbine := filepath.Join("out", "soong", ".intermediates", "bar", "android_common", "turbine", "bar.jar")
+	barCombined := filepath.Join("out", "soong", ".intermediates", "bar", "android_common", "turbine-combined", "bar.jar")
+	android.AssertStringDoesContain(t, "foo classpath", javac.Args["classpath"], barTurbine)
+	android.AssertStringDoesNotContain(t, "foo classpath", javac.Args["classpath"], barCombined)
+
+	// The header jar of bar still contains the classes of its static libs.
+	bazTurbine := filepath.Join("out", "soong", ".intermediates", "baz", "android_common", "turbine-combined", "baz.jar")
+	combined := result.ModuleForTests("bar", "android_common").Description("for turbine")
+	android.AssertStringListContains(t, "bar header jar inputs", combined.Inputs.RelativeToTop().Strings(), bazTurbine)
+
+	report := strictJavaDepsReportForTests(t, result)
+	android.AssertBoolEquals(t, "strict", true, report.Strict)
+	android.AssertIntEquals(t, "modules", 2, len(report.Modules))
+}
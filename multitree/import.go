@@ -42,6 +42,10 @@ type importedFileGroup struct {
 
 	properties importedFileGroupProperties
 	srcs       android.Paths
+
+	// True if the imported modules are resolved through the inter-tree interface of the
+	// secondary tree.
+	fromSecondaryTree bool
 }
 
 func (ifg *importedFileGroup) Name() string {
@@ -70,6 +74,11 @@ func (ifg *importedFileGroup) Srcs() android.Paths {
 }
 
 func (ifg *importedFileGroup) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	if ifg.fromSecondaryTree {
+		ifg.srcs = ifg.secondaryTreeSrcs(ctx)
+		return
+	}
+
 	// srcs from this module must not be used. Adding a dot path to avoid the empty
 	// source failure. Still soong returns error when a module wants to build against
 	// this source, which is intended.
@@ -84,6 +93,20 @@ func MultitreeImportedRenameMutator(ctx android.BottomUpMutatorContext) {
 	if m, ok := ctx.Module().(MultitreeImportedModuleInterface); ok {
 		name := m.GetMultitreeImportedModuleName()
 		if !ctx.OtherModuleExists(name) {
+			if ifg, ok := m.(*importedFileGroup); ok {
+				iface, err := getSecondaryTreeInterface(ctx.Config())
+				if err != nil {
+					ctx.ModuleErrorf("%s", err)
+					return
+				}
+				if iface != nil {
+					// The imported modules are resolved through the inter-tree interface of
+					// the secondary tree.
+					ifg.fromSecondaryTree = true
+					ctx.Rename(name)
+					return
+				}
+			}
 			// Provide an empty filegroup not to break the build while updating the metadata.
 			// In other cases, soong will report an error to guide users to run 'm update-meta'
 			// first.
@@ -94,3 +117,27 @@ func MultitreeImportedRenameMutator(ctx android.BottomUpMutatorContext) {
 		}
 	}
 }
+
+// secondaryTreeSrcs returns the files exported by the imported modules of the secondary tree,
+// through the link to the secondary tree in the Soong output directory.
+func (ifg *importedFileGroup) secondaryTreeSrcs(ctx android.ModuleContext) android.Paths {
+	iface, err := getSecondaryTreeInterface(ctx.Config())
+	if err != nil {
+		ctx.ModuleErrorf("%s", err)
+		return nil
+	}
+	ctx.AddNinjaFileDeps(iface.file)
+
+	var srcs android.Paths
+	for _, imported := range ifg.properties.Imported {
+		files, ok := iface.exportedFiles(imported)
+		if !ok {
+			ctx.PropertyErrorf("imported", "%q is not exported by the secondary tree %s", imported, iface.root)
+			continue
+		}
+		for _, f := range files {
+			srcs = append(srcs, android.PathForOutput(ctx, secondaryTreeLinkDir, f))
+		}
+	}
+	return srcs
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multitree
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"android/soong/android"
+)
+
+// A secondary tree is a read-only checkout, e.g. a prebuilt platform tree, whose modules this tree
+// references with imported_filegroup modules instead of having their sources. The modules of the
+// secondary tree that can be referenced are those exported in its inter-tree interface definition,
+// the out/soong/multitree/metadata.json file written by 'm update-meta' in that tree.
+//
+// soong_ui validates SOONG_SECONDARY_TREE, makes it absolute and links it to
+// out/soong/multitree/secondary so that the exported files are inputs of the build actions of
+// this tree.
+
+const (
+	secondaryTreeEnvVar       = "SOONG_SECONDARY_TREE"
+	secondaryTreeOutDirEnvVar = "SOONG_SECONDARY_TREE_OUT_DIR"
+)
+
+// secondaryTreeLinkDir is the link to the secondary tree, relative to the Soong output directory.
+// It must match the one created by soong_ui in ui/build/soong.go.
+var secondaryTreeLinkDir = filepath.Join("multitree", "secondary")
+
+// secondaryTreeInterface is the inter-tree interface definition of the secondary tree.
+type secondaryTreeInterface struct {
+	// The root of the secondary tree.
+	root string
+
+	// The metadata.json file of the secondary tree.
+	file string
+
+	// The files exported by the modules of the secondary tree, keyed by "<module>:<tag>", relative
+	// to the root of the secondary tree.
+	exported map[string][]string
+}
+
+var secondaryTreeInterfaceKey = android.NewOnceKey("secondaryTreeInterface")
+
+type secondaryTreeInterfaceResult struct {
+	iface *secondaryTreeInterface
+	err   error
+}
+
+// getSecondaryTreeInterface returns the inter-tree interface definition of the secondary tree, or
+// nil if there is no secondary tree.
+func getSecondaryTreeInterface(config android.Config) (*secondaryTreeInterface, error) {
+	result := config.Once(secondaryTreeInterfaceKey, func() interface{} {
+		root := config.Getenv(secondaryTreeEnvVar)
+		if root == "" {
+			return secondaryTreeInterfaceResult{}
+		}
+		outDir := config.Getenv(secondaryTreeOutDirEnvVar)
+		if outDir == "" {
+			outDir = "out"
+		}
+		iface, err := loadSecondaryTreeInterface(root, outDir)
+		return secondaryTreeInterfaceResult{iface, err}
+	}).(secondaryTreeInterfaceResult)
+	return result.iface, result.err
+}
+
+func loadSecondaryTreeInterface(root, outDir string) (*secondaryTreeInterface, error) {
+	if !filepath.IsAbs(outDir) {
+		outDir = filepath.Join(root, outDir)
+	}
+	file := filepath.Join(outDir, "soong", "multitree", "metadata.json")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the inter-tree interface of %s=%s, run 'm update-meta' in it first: %s",
+			secondaryTreeEnvVar, root, err)
+	}
+
+	var metadata metadataJsonFlags
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+
+	exported := make(map[string][]string, len(metadata.Exported))
+	for key, files := range metadata.Exported {
+		for _, f := range files {
+			// The exported files are relative to the root of the secondary tree, unless its
+			// OUT_DIR was absolute.
+			if filepath.IsAbs(f) {
+				rel, err := filepath.Rel(root, f)
+				if err != nil || strings.HasPrefix(rel, "..") {
+					return nil, fmt.Errorf("%s: %q exported by %q is outside of %s", file, f, key, root)
+				}
+				f = rel
+			}
+			exported[key] = append(exported[key], f)
+		}
+	}
+
+	return &secondaryTreeInterface{
+		root:     root,
+		file:     file,
+		exported: exported,
+	}, nil
+}
+
+// exportedFiles returns the files exported by a module of the secondary tree, where name is
+// "<module>" for its default outputs or "<module>:<tag>".
+func (s *secondaryTreeInterface) exportedFiles(name string) ([]string, bool) {
+	if !strings.Contains(name, ":") {
+		name += ":"
+	}
+	files, ok := s.exported[name]
+	return files, ok
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2026 The Android Open Source Project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package provenance
+
+// This file generates a signed SLSA provenance attestation of the prebuilt artifacts that are
+// installed by the build, so that the dist artifacts carry their supply chain information without
+// an external wrapper. The attestation is an in-toto statement in a DSSE envelope, with the
+// prebuilt artifacts as its subjects, recording the source manifest, the environment and the
+// digests of the build tools as the inputs of the build.
+
+import (
+	"encoding/json"
+
+	"github.com/google/blueprint/proptools"
+
+	"android/soong/android"
+)
+
+// attestationEnv are the environment variables that are always recorded in the attestation.
+var attestationEnv = []string{
+	"TARGET_PRODUCT",
+	"TARGET_BUILD_VARIANT",
+	"TARGET_BUILD_APPS",
+	"TARGET_BUILD_UNBUNDLED",
+}
+
+// attestationTools are the host tools whose digests are recorded in the attestation.
+var attestationTools = []string{
+	"soong_build",
+	"soong_zip",
+	"merge_zips",
+	"gen_provenance_metadata",
+}
+
+// generateAttestation generates the provenance attestation of the merged provenance metadata, if
+// the product signs one.
+func (p *provenanceInfoSingleton) generateAttestation(ctx android.SingletonContext) {
+	config, ok := ctx.Config().ProvenanceAttestation()
+	if !ok {
+		return
+	}
+
+	env := make(map[string]string)
+	for _, name := range android.FirstUniqueStrings(append(attestationEnv, config.Env...)) {
+		env[name] = ctx.Config().Getenv(name)
+	}
+	envJson, err := json.Marshal(env)
+	if err != nil {
+		ctx.Errorf("failed to encode provenance attestation environment: %s", err)
+		return
+	}
+	envFile := android.PathForOutput(ctx, "provenance_attestation", "env.json")
+	android.WriteFileRule(ctx, envFile, string(envJson))
+
+	p.attestationFile = android.PathForOutput(ctx, "provenance_attestation.intoto.jsonl")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	cmd := rule.Command().BuiltTool("gen_provenance_attestation").
+		FlagWithInput("--metadata ", p.mergedMetaDataFile).
+		FlagWithInput("--env ", envFile).
+		FlagWithInput("--key ", ctx.Config().ProvenanceAttestationSigningKey(ctx))
+	if keyId := proptools.String(config.KeyId); keyId != "" {
+		cmd.FlagWithArg("--key_id ", keyId)
+	}
+	if builderId := proptools.String(config.BuilderId); builderId != "" {
+		cmd.FlagWithArg("--builder_id ", builderId)
+	}
+	if buildId := ctx.Config().BuildId(); buildId != "" {
+		cmd.FlagWithArg("--build_id ", buildId)
+	}
+	if manifest := proptools.String(config.Manifest); manifest != "" {
+		cmd.FlagWithInput("--manifest ", android.PathForSource(ctx, manifest))
+	}
+	for _, tool := range attestationTools {
+		path := ctx.Config().HostToolPath(ctx, tool)
+		cmd.FlagWithArg("--tool ", tool+"="+path.String()).Implicit(path)
+	}
+	cmd.FlagWithOutput("--out ", p.attestationFile)
+	rule.Build("provenance_attestation", "generate provenance attestation")
+
+	ctx.Phony("provenance_attestation", p.attestationFile)
+	ctx.Phony("droidcore", android.PathForPhony(ctx, "provenance_attestation"))
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2026 The Android Open Source Project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package provenance
+
+import (
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+
+	"android/soong/android"
+)
+
+func TestProvenanceAttestation(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		PrepareForTestWithProvenanceSingleton,
+		android.PrepareForTestWithAndroidMk,
+		android.FixtureMergeEnv(map[string]string{
+			"TARGET_PRODUCT": "aosp_arm64",
+			"EXTRA_VAR":      "extra",
+		}),
+		android.FixtureAddFile("manifest.xml", nil),
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.ProvenanceAttestation = &android.ProvenanceAttestation{
+				SigningKey: proptools.StringPtr("/secure/provenance.pem"),
+				KeyId:      proptools.StringPtr("provenance-key-1"),
+				BuilderId:  proptools.StringPtr("https://ci.example.com"),
+				Manifest:   proptools.StringPtr("manifest.xml"),
+				Env:        []string{"EXTRA_VAR"},
+			}
+		}),
+	).RunTestWithBp(t, "")
+
+	singleton := result.SingletonForTests("provenance_metadata_singleton")
+
+	env := singleton.Output("provenance_attestation/env.json")
+	android.AssertStringEquals(t, "env",
+		`{"EXTRA_VAR":"extra","TARGET_BUILD_APPS":"","TARGET_BUILD_UNBUNDLED":"","TARGET_BUILD_VARIANT":"","TARGET_PRODUCT":"aosp_arm64"}`+"\n",
+		android.ContentFromFileRuleForTests(t, env))
+
+	attestation := singleton.Rule("provenance_attestation")
+	android.AssertPathRelativeToTopEquals(t, "output",
+		"out/soong/provenance_attestation.intoto.jsonl", attestation.Output)
+	command := attestation.RuleParams.Command
+	android.AssertStringDoesContain(t, "inputs", command,
+		"--metadata out/soong/provenance_metadata.textproto --env out/soong/provenance_attestation/env.json")
+	android.AssertStringDoesContain(t, "key", command,
+		"--key /secure/provenance.pem --key_id provenance-key-1 --builder_id https://ci.example.com")
+	android.AssertStringListContains(t, "key input", attestation.Inputs.Strings(), "/secure/provenance.pem")
+	android.AssertStringDoesContain(t, "manifest", command, "--manifest manifest.xml")
+	android.AssertStringDoesContain(t, "tool", command,
+		"--tool soong_zip=out/soong/host/linux-x86/bin/soong_zip")
+	android.AssertStringDoesContain(t, "out", command,
+		"--out out/soong/provenance_attestation.intoto.jsonl")
+}
+
+func TestProvenanceAttestationWithoutKey(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		PrepareForTestWithProvenanceSingleton,
+		android.PrepareForTestWithAndroidMk,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.ProvenanceAttestation = &android.ProvenanceAttestation{
+				BuilderId: proptools.StringPtr("https://ci.example.com"),
+			}
+		}),
+	).RunTestWithBp(t, "")
+
+	singleton := result.SingletonForTests("provenance_metadata_singleton")
+	if singleton.MaybeRule("provenance_attestation").Rule != nil {
+		t.Errorf("expected no provenance attestation without a signing key")
+	}
+}
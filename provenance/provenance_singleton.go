@@ -62,6 +62,7 @@ func provenanceInfoSingletonFactory() android.Singleton {
 
 type provenanceInfoSingleton struct {
 	mergedMetaDataFile android.OutputPath
+	attestationFile    android.WritablePath
 }
 
 func (p *provenanceInfoSingleton) GenerateBuildActions(context android.SingletonContext) {
@@ -87,6 +88,8 @@ func (p *provenanceInfoSingleton) GenerateBuildActions(context android.Singleton
 	})
 
 	context.Phony("droidcore", android.PathForPhony(context, "provenance_metadata"))
+
+	p.generateAttestation(context)
 }
 
 func moduleFilter(module android.Module) bool {
@@ -117,6 +120,9 @@ func GenerateArtifactProvenanceMetaData(ctx android.ModuleContext, artifactPath
 
 func (p *provenanceInfoSingleton) MakeVars(ctx android.MakeVarsContext) {
 	ctx.DistForGoal("droidcore", p.mergedMetaDataFile)
+	if p.attestationFile != nil {
+		ctx.DistForGoal("droidcore", p.attestationFile)
+	}
 }
 
 var _ android.SingletonMakeVarsProvider = (*provenanceInfoSingleton)(nil)
@@ -0,0 +1,174 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Generates a signed SLSA provenance attestation of prebuilt artifacts.
+
+The attestation is an in-toto statement with a SLSA v1 provenance predicate,
+whose subjects are the artifacts of the merged provenance metadata, wrapped in
+a DSSE envelope signed with openssl. It is written as a single line, following
+the .intoto.jsonl convention.
+"""
+
+import argparse
+import base64
+import hashlib
+import json
+import subprocess
+import sys
+import tempfile
+
+import google.protobuf.text_format as text_format
+import provenance_metadata_pb2
+
+PAYLOAD_TYPE = 'application/vnd.in-toto+json'
+STATEMENT_TYPE = 'https://in-toto.io/Statement/v1'
+PREDICATE_TYPE = 'https://slsa.dev/provenance/v1'
+BUILD_TYPE = 'https://source.android.com/docs/setup/build/soong/provenance/v1'
+
+
+def ParseArgs(argv):
+  parser = argparse.ArgumentParser(description='Create a signed provenance attestation of prebuilt artifacts')
+  parser.add_argument('--metadata', help='Merged provenance metadata of the artifacts', required=True)
+  parser.add_argument('--env', help='JSON file of the environment of the build', required=True)
+  parser.add_argument('--key', help='PEM private key that signs the attestation', required=True)
+  parser.add_argument('--key_id', help='Identifier of the signing key', default='')
+  parser.add_argument('--builder_id', help='Identifier of the build platform', default='')
+  parser.add_argument('--build_id', help='Identifier of the build', default='')
+  parser.add_argument('--manifest', help='Snapshot of the source manifest of the checkout')
+  parser.add_argument('--tool', help='Host tool of the build, as name=path', action='append', default=[])
+  parser.add_argument('--out', help='Path of the attestation', required=True)
+  return parser.parse_args(argv)
+
+
+def Sha256(path):
+  h = hashlib.sha256()
+  with open(path, 'rb') as f:
+    for chunk in iter(lambda: f.read(1 << 20), b''):
+      h.update(chunk)
+  return h.hexdigest()
+
+
+def ReadMetadata(path):
+  metadata_list = provenance_metadata_pb2.ProvenanceMetaDataList()
+  with open(path, 'rt') as f:
+    text_format.Parse(f.read(), metadata_list)
+  return metadata_list.metadata
+
+
+def Subjects(metadata):
+  """Returns the in-toto subjects of the artifacts, sorted by install path."""
+  subjects = [{
+      'name': m.artifact_install_path,
+      'digest': {'sha256': m.artifact_sha256},
+  } for m in metadata]
+  return sorted(subjects, key=lambda s: s['name'])
+
+
+def Statement(metadata, env, tools, manifest=None, builder_id='', build_id=''):
+  """Returns the in-toto statement of the artifacts.
+
+  tools maps the names of host tools to their sha256 digests, and manifest is a
+  (path, sha256) tuple of the source manifest.
+  """
+  dependencies = []
+  if manifest:
+    dependencies.append({
+        'name': 'manifest',
+        'uri': manifest[0],
+        'digest': {'sha256': manifest[1]},
+    })
+  for name in sorted(tools):
+    dependencies.append({'name': name, 'digest': {'sha256': tools[name]}})
+  for m in sorted(metadata, key=lambda m: m.artifact_path):
+    dependency = {
+        'name': m.module_name,
+        'uri': m.artifact_path,
+        'digest': {'sha256': m.artifact_sha256},
+    }
+    if m.attestation_path:
+      dependency['annotations'] = {'attestation': m.attestation_path}
+    dependencies.append(dependency)
+
+  run_details = {'builder': {'id': builder_id}}
+  if build_id:
+    run_details['metadata'] = {'invocationId': build_id}
+
+  return {
+      '_type': STATEMENT_TYPE,
+      'subject': Subjects(metadata),
+      'predicateType': PREDICATE_TYPE,
+      'predicate': {
+          'buildDefinition': {
+              'buildType': BUILD_TYPE,
+              'externalParameters': {'env': env},
+              'resolvedDependencies': dependencies,
+          },
+          'runDetails': run_details,
+      },
+  }
+
+
+def PreAuthEncoding(payload_type, payload):
+  """Returns the DSSE pre-authentication encoding that is signed."""
+  payload_type = payload_type.encode()
+  return b'DSSEv1 %d %s %d %s' % (len(payload_type), payload_type, len(payload), payload)
+
+
+def Sign(key, data):
+  with tempfile.NamedTemporaryFile() as f:
+    f.write(data)
+    f.flush()
+    return subprocess.check_output(['openssl', 'dgst', '-sha256', '-sign', key, f.name])
+
+
+def Envelope(statement, key, key_id, sign=Sign):
+  """Returns the DSSE envelope of the statement, signed with key."""
+  payload = json.dumps(statement, sort_keys=True, separators=(',', ':')).encode()
+  signature = sign(key, PreAuthEncoding(PAYLOAD_TYPE, payload))
+  return {
+      'payloadType': PAYLOAD_TYPE,
+      'payload': base64.b64encode(payload).decode(),
+      'signatures': [{
+          'keyid': key_id,
+          'sig': base64.b64encode(signature).decode(),
+      }],
+  }
+
+
+def main(argv):
+  args = ParseArgs(argv)
+
+  with open(args.env, 'rt') as f:
+    env = json.load(f)
+  tools = {}
+  for tool in args.tool:
+    name, _, path = tool.partition('=')
+    tools[name] = Sha256(path)
+  manifest = (args.manifest, Sha256(args.manifest)) if args.manifest else None
+
+  statement = Statement(ReadMetadata(args.metadata), env, tools, manifest,
+                        args.builder_id, args.build_id)
+  try:
+    envelope = Envelope(statement, args.key, args.key_id)
+  except (OSError, subprocess.CalledProcessError) as e:
+    sys.exit('failed to sign the provenance attestation with %s: %s' % (args.key, e))
+
+  with open(args.out, 'wt') as f:
+    f.write(json.dumps(envelope, sort_keys=True, separators=(',', ':')) + '\n')
+
+
+if __name__ == '__main__':
+  main(sys.argv[1:])
@@ -0,0 +1,98 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import base64
+import json
+import unittest
+
+import gen_provenance_attestation
+import provenance_metadata_pb2
+
+
+def Metadata(module_name, artifact_path, install_path, sha256, attestation_path=''):
+  m = provenance_metadata_pb2.ProvenanceMetadata()
+  m.module_name = module_name
+  m.artifact_path = artifact_path
+  m.artifact_install_path = install_path
+  m.artifact_sha256 = sha256
+  m.attestation_path = attestation_path
+  return m
+
+
+class ProvenanceAttestationTest(unittest.TestCase):
+
+  metadata = [
+      Metadata('foo', 'prebuilts/foo.apk', '/system/app/foo/foo.apk', 'aaaa'),
+      Metadata('bar', 'prebuilts/bar.apex', '/system/apex/bar.apex', 'bbbb',
+               'prebuilts/bar.apex.intoto.jsonl'),
+  ]
+
+  def test_statement(self):
+    statement = gen_provenance_attestation.Statement(
+        self.metadata, {'TARGET_PRODUCT': 'aosp_arm64'}, {'soong_zip': 'cccc'},
+        manifest=('manifest.xml', 'dddd'), builder_id='https://ci.example',
+        build_id='1234')
+
+    self.assertEqual(statement['_type'], 'https://in-toto.io/Statement/v1')
+    self.assertEqual(statement['predicateType'], 'https://slsa.dev/provenance/v1')
+    self.assertEqual(statement['subject'], [
+        {'name': '/system/apex/bar.apex', 'digest': {'sha256': 'bbbb'}},
+        {'name': '/system/app/foo/foo.apk', 'digest': {'sha256': 'aaaa'}},
+    ])
+
+    definition = statement['predicate']['buildDefinition']
+    self.assertEqual(definition['externalParameters'], {'env': {'TARGET_PRODUCT': 'aosp_arm64'}})
+    self.assertEqual(definition['resolvedDependencies'], [
+        {'name': 'manifest', 'uri': 'manifest.xml', 'digest': {'sha256': 'dddd'}},
+        {'name': 'soong_zip', 'digest': {'sha256': 'cccc'}},
+        {'name': 'bar', 'uri': 'prebuilts/bar.apex', 'digest': {'sha256': 'bbbb'},
+         'annotations': {'attestation': 'prebuilts/bar.apex.intoto.jsonl'}},
+        {'name': 'foo', 'uri': 'prebuilts/foo.apk', 'digest': {'sha256': 'aaaa'}},
+    ])
+    self.assertEqual(statement['predicate']['runDetails'], {
+        'builder': {'id': 'https://ci.example'},
+        'metadata': {'invocationId': '1234'},
+    })
+
+  def test_pre_auth_encoding(self):
+    self.assertEqual(
+        gen_provenance_attestation.PreAuthEncoding('application/vnd.in-toto+json', b'{}'),
+        b'DSSEv1 28 application/vnd.in-toto+json 2 {}')
+
+  def test_envelope(self):
+    signed = []
+
+    def sign(key, data):
+      signed.append((key, data))
+      return b'signature'
+
+    statement = {'_type': 'https://in-toto.io/Statement/v1', 'subject': []}
+    envelope = gen_provenance_attestation.Envelope(statement, 'key.pem', 'key-1', sign=sign)
+
+    payload = base64.b64decode(envelope['payload'])
+    self.assertEqual(json.loads(payload), statement)
+    self.assertEqual(envelope['payloadType'], 'application/vnd.in-toto+json')
+    self.assertEqual(envelope['signatures'], [
+        {'keyid': 'key-1', 'sig': base64.b64encode(b'signature').decode()},
+    ])
+    self.assertEqual(signed, [
+        ('key.pem', gen_provenance_attestation.PreAuthEncoding(
+            'application/vnd.in-toto+json', payload)),
+    ])
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
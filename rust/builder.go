@@ -29,7 +29,7 @@ var (
 	_     = pctx.SourcePathVariable("mkcraterspCmd", "build/soong/scripts/mkcratersp.py")
 	rustc = pctx.AndroidStaticRule("rustc",
 		blueprint.RuleParams{
-			Command: "$envVars $rustcCmd " +
+			Command: "$envVars $compilerCache$rustcCmd " +
 				"-C linker=$mkcraterspCmd " +
 				"--emit link -o $out --emit dep-info=$out.d.raw $in ${libFlags} $rustcFlags" +
 				" && grep \"^$out:\" $out.d.raw > $out.d",
@@ -42,7 +42,7 @@ var (
 			Deps:    blueprint.DepsGCC,
 			Depfile: "$out.d",
 		},
-		"rustcFlags", "libFlags", "envVars")
+		"rustcFlags", "libFlags", "envVars", "compilerCache")
 	rustLink = pctx.AndroidStaticRule("rustLink",
 		blueprint.RuleParams{
 			Command: "${config.RustLinker} -o $out ${crtBegin} ${config.RustLinkerArgs} @$in ${linkFlags} ${crtEnd}",
@@ -252,7 +252,8 @@ func transformSrctoCrate(ctx ModuleContext, main android.Path, deps PathDeps, fl
 	rustcFlags = append(rustcFlags, "--sysroot=/dev/null")
 
 	// Enable incremental compilation if requested by user
-	if ctx.Config().IsEnvTrue("SOONG_RUSTC_INCREMENTAL") {
+	incremental := ctx.Config().IsEnvTrue("SOONG_RUSTC_INCREMENTAL")
+	if incremental {
 		incrementalPath := android.PathForOutput(ctx, "rustc").String()
 
 		rustcFlags = append(rustcFlags, "-Cincremental="+incrementalPath)
@@ -357,17 +358,23 @@ func transformSrctoCrate(ctx ModuleContext, main android.Path, deps PathDeps, fl
 		rustcOutputFile = android.PathForModuleOut(ctx, outputFile.Base()+".rsp")
 	}
 
+	args := map[string]string{
+		"rustcFlags": strings.Join(rustcFlags, " "),
+		"libFlags":   strings.Join(libFlags, " "),
+		"envVars":    strings.Join(envVars, " "),
+	}
+	// sccache only caches libraries, and not incremental compiles.
+	if cache, ok := ctx.Config().CompilerCache(); ok && cache.SupportsRust() && !usesLinker && !incremental {
+		args["compilerCache"] = cache.Exec + " "
+	}
+
 	ctx.Build(pctx, android.BuildParams{
 		Rule:        rustc,
 		Description: "rustc " + main.Rel(),
 		Output:      rustcOutputFile,
 		Inputs:      inputs,
 		Implicits:   implicits,
-		Args: map[string]string{
-			"rustcFlags": strings.Join(rustcFlags, " "),
-			"libFlags":   strings.Join(libFlags, " "),
-			"envVars":    strings.Join(envVars, " "),
-		},
+		Args:        args,
 	})
 
 	if usesLinker {
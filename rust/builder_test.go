@@ -14,7 +14,11 @@
 
 package rust
 
-import "testing"
+import (
+	"testing"
+
+	"android/soong/android"
+)
 
 func TestSourceProviderCollision(t *testing.T) {
 	testRustError(t, "multiple source providers generate the same filename output: bindings.rs", `
@@ -40,3 +44,52 @@ func TestSourceProviderCollision(t *testing.T) {
 		}
 	`)
 }
+
+func TestCompilerCache(t *testing.T) {
+	bp := `
+		rust_library_host {
+			name: "libfoo",
+			srcs: ["foo.rs"],
+			crate_name: "foo",
+		}
+	`
+
+	testCases := []struct {
+		name string
+		env  map[string]string
+		rlib string
+	}{
+		{
+			name: "sccache",
+			env:  map[string]string{"SOONG_COMPILER_CACHE": "sccache", "SOONG_COMPILER_CACHE_EXEC": "/usr/bin/sccache"},
+			rlib: "/usr/bin/sccache ",
+		},
+		{
+			name: "ccache",
+			env:  map[string]string{"SOONG_COMPILER_CACHE": "ccache", "SOONG_COMPILER_CACHE_EXEC": "/usr/bin/ccache"},
+		},
+		{
+			name: "incremental",
+			env: map[string]string{
+				"SOONG_COMPILER_CACHE":      "sccache",
+				"SOONG_COMPILER_CACHE_EXEC": "/usr/bin/sccache",
+				"SOONG_RUSTC_INCREMENTAL":   "true",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := android.GroupFixturePreparers(
+				prepareForRustTest,
+				android.FixtureMergeEnv(tc.env),
+			).RunTestWithBp(t, bp)
+
+			rlib := result.ModuleForTests("libfoo", "linux_glibc_x86_64_rlib_rlib-std").Rule("rustc")
+			android.AssertStringEquals(t, "rlib compiler cache", tc.rlib, rlib.Args["compilerCache"])
+			dylib := result.ModuleForTests("libfoo", "linux_glibc_x86_64_dylib").Rule("rustc")
+			// sccache doesn't cache crates that are linked.
+			android.AssertStringEquals(t, "dylib compiler cache", "", dylib.Args["compilerCache"])
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 The Android Open Source Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+
+	"android/soong/android"
+)
+
+var (
+	WasiRustFlags     = []string{}
+	WasiRustLinkFlags = []string{}
+)
+
+func init() {
+	registerToolchainFactory(android.Wasi, android.Wasm32, wasiWasm32ToolchainFactory)
+
+	pctx.StaticVariable("WasiToolchainRustFlags", strings.Join(WasiRustFlags, " "))
+	pctx.StaticVariable("WasiToolchainLinkFlags", strings.Join(WasiRustLinkFlags, " "))
+}
+
+type toolchainWasiWasm32 struct {
+	toolchain32Bit
+}
+
+func (toolchainWasiWasm32) Supported() bool {
+	return true
+}
+
+func (toolchainWasiWasm32) Bionic() bool {
+	return false
+}
+
+func (t *toolchainWasiWasm32) Name() string {
+	return "wasm32"
+}
+
+func (t *toolchainWasiWasm32) RustTriple() string {
+	return "wasm32-wasi"
+}
+
+func (t *toolchainWasiWasm32) ExecutableSuffix() string {
+	return ".wasm"
+}
+
+func (t *toolchainWasiWasm32) ToolchainLinkFlags() string {
+	// Prepend the lld flags from cc_config so we stay in sync with cc
+	return "${cc_config.WasiLldflags} ${config.WasiToolchainLinkFlags}"
+}
+
+func (t *toolchainWasiWasm32) ToolchainRustFlags() string {
+	return "${config.WasiToolchainRustFlags}"
+}
+
+func wasiWasm32ToolchainFactory(arch android.Arch) Toolchain {
+	return toolchainWasiWasm32Singleton
+}
+
+var toolchainWasiWasm32Singleton Toolchain = &toolchainWasiWasm32{}
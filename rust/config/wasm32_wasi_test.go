@@ -0,0 +1,55 @@
+// Copyright 2026 The Android Open Source Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"android/soong/android"
+)
+
+func TestWasiWasm32Toolchain(t *testing.T) {
+	toolchain := FindToolchain(android.Wasi, android.Arch{ArchType: android.Wasm32})
+
+	testCases := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"Name", toolchain.Name(), "wasm32"},
+		{"RustTriple", toolchain.RustTriple(), "wasm32-wasi"},
+		{"ExecutableSuffix", toolchain.ExecutableSuffix(), ".wasm"},
+		{"ToolchainRustFlags", toolchain.ToolchainRustFlags(), "${config.WasiToolchainRustFlags}"},
+		{"ToolchainLinkFlags", toolchain.ToolchainLinkFlags(), "${cc_config.WasiLldflags} ${config.WasiToolchainLinkFlags}"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if testCase.output != testCase.expected {
+				t.Error("Output doesn't match expected", testCase.output, testCase.expected)
+			}
+		})
+	}
+
+	if toolchain.Is64Bit() {
+		t.Error("wasm32 toolchain is 64-bit")
+	}
+	if toolchain.Bionic() {
+		t.Error("wasm32 toolchain uses Bionic")
+	}
+	if !toolchain.Supported() {
+		t.Error("wasm32 toolchain isn't supported")
+	}
+}
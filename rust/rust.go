@@ -1317,6 +1317,9 @@ func (mod *Module) depsToPaths(ctx android.ModuleContext) PathDeps {
 				depPaths.depClangFlags = append(depPaths.depClangFlags, exportedInfo.Flags...)
 				depPaths.depGeneratedHeaders = append(depPaths.depGeneratedHeaders, exportedInfo.GeneratedHeaders...)
 				directStaticLibDeps = append(directStaticLibDeps, ccDep)
+				if sanitizeableDep, ok := ccDep.(cc.PlatformSanitizeable); ok {
+					cc.CheckLinkUnitSanitizers(ctx, mod, sanitizeableDep, depName)
+				}
 
 				// Record baseLibName for snapshots.
 				mod.Properties.SnapshotStaticLibs = append(mod.Properties.SnapshotStaticLibs, cc.BaseLibName(depName))
@@ -1680,6 +1683,11 @@ func (mod *Module) MinSdkVersion() string {
 	return String(mod.Properties.Min_sdk_version)
 }
 
+// Implements android.MinSdkVersionExplainer
+func (mod *Module) ExplainMinSdkVersion(ctx android.EarlyModuleContext) (string, string) {
+	return mod.MinSdkVersion(), "min_sdk_version"
+}
+
 // Implements android.ApexModule
 func (mod *Module) ShouldSupportSdkVersion(ctx android.BaseModuleContext, sdkVersion android.ApiLevel) error {
 	minSdkVersion := mod.MinSdkVersion()
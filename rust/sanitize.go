@@ -39,6 +39,20 @@ type SanitizeProperties struct {
 		Fuzzer      *bool `android:"arch_variant"`
 		Never       *bool `android:"arch_variant"`
 
+		// The sanitizers of cc that are not supported by rust modules. Enabling them is an error,
+		// see unsupportedSanitizers.
+		Thread           *bool    `android:"arch_variant"`
+		All_undefined    *bool    `android:"arch_variant"`
+		Undefined        *bool    `android:"arch_variant"`
+		Misc_undefined   []string `android:"arch_variant"`
+		Safestack        *bool    `android:"arch_variant"`
+		Cfi              *bool    `android:"arch_variant"`
+		Integer_overflow *bool    `android:"arch_variant"`
+		Scudo            *bool    `android:"arch_variant"`
+		Scs              *bool    `android:"arch_variant"`
+		Memtag_stack     *bool    `android:"arch_variant"`
+		Writeonly        *bool    `android:"arch_variant"`
+
 		// Sanitizers to run in the diagnostic mode (as opposed to the release mode).
 		// Replaces abort() on error with a human-readable error message.
 		// Address and Thread sanitizers always run in diagnostic mode.
@@ -47,6 +61,12 @@ type SanitizeProperties struct {
 			// requires sanitizer.memtag: true
 			// if set, enables sync memory tagging
 			Memtag_heap *bool `android:"arch_variant"`
+
+			// Not supported by rust modules, see unsupportedSanitizers.
+			Undefined        *bool    `android:"arch_variant"`
+			Cfi              *bool    `android:"arch_variant"`
+			Integer_overflow *bool    `android:"arch_variant"`
+			Misc_undefined   []string `android:"arch_variant"`
 		}
 	}
 	SanitizerEnabled bool `blueprint:"mutated"`
@@ -91,6 +111,42 @@ var hwasanFlags = []string{
 	"-C llvm-args=--hwasan-with-ifunc",
 }
 
+// supportedSanitizers are the sanitize properties of cc that rust modules support.
+var supportedSanitizers = []string{"address", "hwaddress", "fuzzer", "memtag_heap"}
+
+// unsupportedSanitizers returns the sanitize properties of cc that are enabled on the module but
+// that rust modules don't support: rustc has no equivalent for them, or they are not wired up to
+// the rust toolchain and runtimes of the build.
+func (sanitize *sanitize) unsupportedSanitizers() []string {
+	s := &sanitize.Properties.Sanitize
+	var unsupported []string
+	for _, p := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"thread", Bool(s.Thread)},
+		{"all_undefined", Bool(s.All_undefined)},
+		{"undefined", Bool(s.Undefined)},
+		{"misc_undefined", len(s.Misc_undefined) > 0},
+		{"safestack", Bool(s.Safestack)},
+		{"cfi", Bool(s.Cfi)},
+		{"integer_overflow", Bool(s.Integer_overflow)},
+		{"scudo", Bool(s.Scudo)},
+		{"scs", Bool(s.Scs)},
+		{"memtag_stack", Bool(s.Memtag_stack)},
+		{"writeonly", Bool(s.Writeonly)},
+		{"diag.undefined", Bool(s.Diag.Undefined)},
+		{"diag.cfi", Bool(s.Diag.Cfi)},
+		{"diag.integer_overflow", Bool(s.Diag.Integer_overflow)},
+		{"diag.misc_undefined", len(s.Diag.Misc_undefined) > 0},
+	} {
+		if p.enabled {
+			unsupported = append(unsupported, p.name)
+		}
+	}
+	return unsupported
+}
+
 func boolPtr(v bool) *bool {
 	if v {
 		return &v
@@ -108,6 +164,11 @@ func (sanitize *sanitize) props() []interface{} {
 func (sanitize *sanitize) begin(ctx BaseModuleContext) {
 	s := &sanitize.Properties.Sanitize
 
+	for _, name := range sanitize.unsupportedSanitizers() {
+		ctx.PropertyErrorf("sanitize."+name, "is not supported by rust modules, the supported sanitizers are %s",
+			strings.Join(supportedSanitizers, ", "))
+	}
+
 	// Never always wins.
 	if Bool(s.Never) {
 		return
@@ -204,6 +265,13 @@ func (sanitize *sanitize) begin(ctx BaseModuleContext) {
 		s.Address = nil
 	}
 
+	// The fuzzer is instrumented with HWASan rather than ASan where HWASan is available, so it
+	// must not also enable ASan, or the module would link the ASan runtime with HWASan
+	// instrumentation.
+	if Bool(s.Fuzzer) && fuzzerUsesHwasan(ctx) {
+		s.Address = nil
+	}
+
 	// Memtag_heap is only implemented on AArch64.
 	if ctx.Arch().ArchType != android.Arm64 || !ctx.Os().Bionic() {
 		s.Memtag_heap = nil
@@ -220,13 +288,19 @@ type sanitize struct {
 	Properties SanitizeProperties
 }
 
+// fuzzerUsesHwasan returns true if the fuzzer is instrumented with HWASan rather than ASan, which
+// requires the top-byte-ignore feature of AArch64 devices.
+func fuzzerUsesHwasan(ctx android.BaseModuleContext) bool {
+	return ctx.Arch().ArchType == android.Arm64 && ctx.Os().Bionic()
+}
+
 func (sanitize *sanitize) flags(ctx ModuleContext, flags Flags, deps PathDeps) (Flags, PathDeps) {
 	if !sanitize.Properties.SanitizerEnabled {
 		return flags, deps
 	}
 	if Bool(sanitize.Properties.Sanitize.Fuzzer) {
 		flags.RustFlags = append(flags.RustFlags, fuzzerFlags...)
-		if ctx.Arch().ArchType == android.Arm64 && ctx.Os().Bionic() {
+		if fuzzerUsesHwasan(ctx) {
 			flags.RustFlags = append(flags.RustFlags, hwasanFlags...)
 		} else {
 			flags.RustFlags = append(flags.RustFlags, asanFlags...)
@@ -274,13 +348,13 @@ func rustSanitizerRuntimeMutator(mctx android.BottomUpMutatorContext) {
 		var deps []string
 
 		if mod.IsSanitizerEnabled(cc.Asan) ||
-			(mod.IsSanitizerEnabled(cc.Fuzzer) && (mctx.Arch().ArchType != android.Arm64 || !mctx.Os().Bionic())) {
+			(mod.IsSanitizerEnabled(cc.Fuzzer) && !fuzzerUsesHwasan(mctx)) {
 			variations = append(variations,
 				blueprint.Variation{Mutator: "link", Variation: "shared"})
 			depTag = cc.SharedDepTag()
 			deps = []string{config.LibclangRuntimeLibrary(mod.toolchain(mctx), "asan")}
 		} else if mod.IsSanitizerEnabled(cc.Hwasan) ||
-			(mod.IsSanitizerEnabled(cc.Fuzzer) && mctx.Arch().ArchType == android.Arm64 && mctx.Os().Bionic()) {
+			(mod.IsSanitizerEnabled(cc.Fuzzer) && fuzzerUsesHwasan(mctx)) {
 			// TODO(b/204776996): HWASan for static Rust binaries isn't supported yet.
 			if binary, ok := mod.compiler.(binaryInterface); ok {
 				if binary.staticallyLinked() {
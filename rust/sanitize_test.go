@@ -363,3 +363,100 @@ func TestSanitizeMemtagHeapWithSanitizeDeviceDiag(t *testing.T) {
 	checkHasMemtagNote(t, ctx.ModuleForTests("unset_test_override_default_disable", variant), Sync)
 	checkHasMemtagNote(t, ctx.ModuleForTests("unset_test_override_default_sync", variant), Sync)
 }
+
+func TestSanitizeFuzzerWithAddressUsesHwasan(t *testing.T) {
+	ctx := testRust(t, `
+			rust_fuzz {
+				name: "fuzz_address",
+				srcs: ["foo.rs"],
+				sanitize: { address: true },
+			}
+	`)
+
+	// The fuzzer is instrumented with HWASan on arm64 devices, so it must not also be built with
+	// ASan or link the ASan runtime.
+	fuzz := ctx.ModuleForTests("fuzz_address", "android_arm64_armv8-a_fuzzer")
+	rustcFlags := fuzz.Rule("rustc").Args["rustcFlags"]
+	if !strings.Contains(rustcFlags, "-Z sanitizer=hwaddress") {
+		t.Errorf("expected fuzz_address to be built with hwaddress, got %q", rustcFlags)
+	}
+	if strings.Contains(rustcFlags, "-Z sanitizer=address") {
+		t.Errorf("expected fuzz_address not to be built with address, got %q", rustcFlags)
+	}
+
+	sharedLibs := fuzz.Module().(*Module).Properties.AndroidMkSharedLibs
+	if !android.PrefixInList(sharedLibs, "libclang_rt.hwasan") {
+		t.Errorf("expected fuzz_address to link the HWASan runtime, got %q", sharedLibs)
+	}
+	if android.PrefixInList(sharedLibs, "libclang_rt.asan") {
+		t.Errorf("expected fuzz_address not to link the ASan runtime, got %q", sharedLibs)
+	}
+}
+
+func TestSanitizeLinkUnitWithCcStaticLib(t *testing.T) {
+	ctx := testRust(t, `
+			rust_binary {
+				name: "bin_address",
+				srcs: ["foo.rs"],
+				static_libs: ["libcc_static"],
+				sanitize: { address: true },
+			}
+			rust_binary {
+				name: "bin_no_sanitize",
+				srcs: ["foo.rs"],
+				static_libs: ["libcc_static"],
+			}
+			cc_library_static {
+				name: "libcc_static",
+				srcs: ["foo.c"],
+			}
+	`)
+
+	// The cc static library is linked in the variant that matches the sanitizers of the rust
+	// binary that links it.
+	for _, tc := range []struct {
+		module  string
+		variant string
+		libDir  string
+	}{
+		{"bin_address", "android_arm64_armv8-a_asan", "android_arm64_armv8-a_static_asan"},
+		{"bin_no_sanitize", "android_arm64_armv8-a", "android_arm64_armv8-a_static"},
+	} {
+		link := ctx.ModuleForTests(tc.module, tc.variant).Rule("rustLink")
+		found := false
+		for _, implicit := range link.Implicits {
+			if strings.Contains(implicit.String(), "libcc_static/"+tc.libDir+"/") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %s to link libcc_static from %s, got %q", tc.module, tc.libDir, link.Implicits.Strings())
+		}
+	}
+}
+
+func TestSanitizeUnsupported(t *testing.T) {
+	testRustError(t, `sanitize.cfi: is not supported by rust modules, the supported sanitizers are address, hwaddress, fuzzer, memtag_heap`, `
+			rust_binary {
+				name: "bin_cfi",
+				srcs: ["foo.rs"],
+				sanitize: { cfi: true },
+			}
+	`)
+	testRustError(t, `sanitize.diag.misc_undefined: is not supported by rust modules`, `
+			rust_binary {
+				name: "bin_misc_undefined",
+				srcs: ["foo.rs"],
+				sanitize: { diag: { misc_undefined: ["bounds"] } },
+			}
+	`)
+
+	// Disabling a sanitizer that rust doesn't support is not an error.
+	testRust(t, `
+			rust_binary {
+				name: "bin_no_thread",
+				srcs: ["foo.rs"],
+				sanitize: { thread: false },
+			}
+	`)
+}
@@ -0,0 +1,165 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Scans preinstalled apps for uses of non-SDK APIs with veridex.
+
+The scan command runs veridex on an APK and writes a JSON report of the non-SDK
+APIs that the app links against or accesses through reflection, counted by
+hidden API list. The check command fails when an app uses more blocked or
+unsupported APIs than its budget, and the merge command aggregates the reports
+of all the apps of a build.
+"""
+
+import argparse
+import collections
+import json
+import re
+import subprocess
+import sys
+
+_USE_RE = re.compile(r'^#\d+: (Linking|Reflection) (\S+) (\S+)')
+
+# The hidden API lists whose uses count against the budget of an app.
+_RESTRICTED_LISTS = ('blocked', 'unsupported')
+_RESTRICTED_PREFIXES = ('max-target-',)
+
+
+def parse_args(args):
+  """Parse commandline arguments."""
+  parser = argparse.ArgumentParser()
+  commands = parser.add_subparsers(dest='command', required=True)
+
+  scan = commands.add_parser('scan', help='scan an APK with veridex')
+  scan.add_argument('--veridex', required=True, help='path to veridex')
+  scan.add_argument('--apk', required=True, help='APK to scan')
+  scan.add_argument('--core-stubs', required=True,
+                    help='colon separated dex files of the SDK stubs')
+  scan.add_argument('--api-flags', required=True,
+                    help='hidden API flags of the platform')
+  scan.add_argument('--module', required=True, help='name of the app module')
+  scan.add_argument('--budget', type=int,
+                    help='maximum number of uses of restricted APIs')
+  scan.add_argument('--output', required=True, help='report to write')
+
+  check = commands.add_parser('check', help='check a report against its budget')
+  check.add_argument('--report', required=True, help='report of the app')
+  check.add_argument('--stamp', required=True,
+                     help='file to touch when the check passes')
+
+  merge = commands.add_parser('merge', help='merge the reports of the apps')
+  merge.add_argument('--output', required=True, help='report to write')
+  merge.add_argument('reports', nargs='*', help='reports of the apps')
+  return parser.parse_args(args)
+
+
+def is_restricted(api_list):
+  """Returns whether the uses of APIs of a hidden API list are restricted."""
+  for flag in api_list.split(','):
+    if flag in _RESTRICTED_LISTS or flag.startswith(_RESTRICTED_PREFIXES):
+      return True
+  return False
+
+
+def parse_veridex_output(output):
+  """Returns the uses of non-SDK APIs printed by veridex."""
+  uses = []
+  for line in output.splitlines():
+    match = _USE_RE.match(line.strip())
+    if match:
+      uses.append({
+          'kind': match.group(1).lower(),
+          'list': match.group(2),
+          'api': match.group(3),
+      })
+  return uses
+
+
+def make_report(module, uses, budget):
+  """Returns the report of the uses of non-SDK APIs by an app."""
+  counts = collections.Counter(use['list'] for use in uses)
+  return {
+      'module': module,
+      'budget': budget,
+      'counts': dict(sorted(counts.items())),
+      'restricted': sum(1 for use in uses if is_restricted(use['list'])),
+      'uses': sorted(uses, key=lambda use: (use['list'], use['api'], use['kind'])),
+  }
+
+
+def over_budget(report):
+  """Returns whether an app uses more restricted APIs than its budget."""
+  return report['budget'] is not None and report['restricted'] > report['budget']
+
+
+def merge_reports(reports):
+  """Returns the aggregated report of the apps of a build."""
+  apps = []
+  for report in sorted(reports, key=lambda report: report['module']):
+    app = dict(report)
+    del app['uses']
+    apps.append(app)
+  return {
+      'apps': apps,
+      'restricted': sum(app['restricted'] for app in apps),
+      'over_budget': [app['module'] for app in apps if over_budget(app)],
+  }
+
+
+def scan(args):
+  output = subprocess.check_output([
+      args.veridex,
+      '--dex-file=' + args.apk,
+      '--core-stubs=' + args.core_stubs,
+      '--api-flags=' + args.api_flags,
+      '--exclude-api-lists=sdk,invalid',
+  ], universal_newlines=True)
+  report = make_report(args.module, parse_veridex_output(output), args.budget)
+  with open(args.output, 'w') as f:
+    json.dump(report, f, indent=2, sort_keys=True)
+
+
+def check(args):
+  with open(args.report) as f:
+    report = json.load(f)
+  if over_budget(report):
+    print('error: %s uses %d blocked or unsupported non-SDK APIs, its '
+          'appcompat_budget is %d:' % (report['module'], report['restricted'],
+                                       report['budget']), file=sys.stderr)
+    for use in report['uses']:
+      if is_restricted(use['list']):
+        print('  %s %s (%s)' % (use['list'], use['api'], use['kind']),
+              file=sys.stderr)
+    sys.exit(1)
+  with open(args.stamp, 'w'):
+    pass
+
+
+def merge(args):
+  reports = []
+  for path in args.reports:
+    with open(path) as f:
+      reports.append(json.load(f))
+  with open(args.output, 'w') as f:
+    json.dump(merge_reports(reports), f, indent=2, sort_keys=True)
+
+
+def main():
+  args = parse_args(sys.argv[1:])
+  {'scan': scan, 'check': check, 'merge': merge}[args.command](args)
+
+
+if __name__ == '__main__':
+  main()
@@ -0,0 +1,82 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for appcompat_scan.py."""
+
+import unittest
+
+import appcompat_scan
+
+VERIDEX_OUTPUT = """
+#1: Linking blocked Landroid/app/Activity;->mToken:Landroid/os/IBinder; use(s):
+       Lcom/example/Foo;->bar()V
+
+#2: Linking max-target-o Landroid/view/View;->mContext:Landroid/content/Context; use(s):
+       Lcom/example/Foo;->baz()V
+
+#3: Reflection unsupported Landroid/os/SystemProperties;->get potential use(s):
+       Lcom/example/Foo;->qux()V
+
+#4: Linking unsupported,core-platform-api Ldalvik/system/VMRuntime;->getRuntime()Ldalvik/system/VMRuntime; use(s):
+       Lcom/example/Foo;->quux()V
+
+4 hidden API(s) used: 3 linked against, 1 through reflection
+"""
+
+
+class AppcompatScanTest(unittest.TestCase):
+
+  def test_parse_veridex_output(self):
+    uses = appcompat_scan.parse_veridex_output(VERIDEX_OUTPUT)
+    self.assertEqual(len(uses), 4)
+    self.assertEqual(uses[0], {
+        'kind': 'linking',
+        'list': 'blocked',
+        'api': 'Landroid/app/Activity;->mToken:Landroid/os/IBinder;',
+    })
+    self.assertEqual(uses[2]['kind'], 'reflection')
+
+  def test_is_restricted(self):
+    self.assertTrue(appcompat_scan.is_restricted('blocked'))
+    self.assertTrue(appcompat_scan.is_restricted('max-target-r'))
+    self.assertTrue(appcompat_scan.is_restricted('unsupported,core-platform-api'))
+    self.assertFalse(appcompat_scan.is_restricted('sdk'))
+
+  def test_report_and_budget(self):
+    uses = appcompat_scan.parse_veridex_output(VERIDEX_OUTPUT)
+    report = appcompat_scan.make_report('Foo', uses, 3)
+    self.assertEqual(report['restricted'], 4)
+    self.assertEqual(report['counts']['unsupported'], 1)
+    self.assertTrue(appcompat_scan.over_budget(report))
+    self.assertFalse(appcompat_scan.over_budget(
+        appcompat_scan.make_report('Foo', uses, 4)))
+    self.assertFalse(appcompat_scan.over_budget(
+        appcompat_scan.make_report('Foo', uses, None)))
+
+  def test_merge_reports(self):
+    uses = appcompat_scan.parse_veridex_output(VERIDEX_OUTPUT)
+    merged = appcompat_scan.merge_reports([
+        appcompat_scan.make_report('Foo', uses, 1),
+        appcompat_scan.make_report('Bar', [], None),
+    ])
+    self.assertEqual([app['module'] for app in merged['apps']], ['Bar', 'Foo'])
+    self.assertNotIn('uses', merged['apps'][1])
+    self.assertEqual(merged['restricted'], 4)
+    self.assertEqual(merged['over_budget'], ['Foo'])
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
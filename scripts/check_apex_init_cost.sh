@@ -0,0 +1,62 @@
+#!/bin/bash -e
+
+# Copyright 2026 Google Inc. All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Estimates the boot-time initialization cost of an APEX and checks it against
+# the budget declared in its init_cost_budget property.
+#
+# Usage: check_apex_init_cost.sh <apex name> <warn only> <report file> \
+#            <max payload size> [<metric> <value> <limit>]... -- [<payload file>]...
+#
+# The payload size is the total size of the given payload files. Other metrics
+# are computed by the build system and passed in. A limit of -1 means that the
+# metric is not limited. The estimate of each metric is written to the report
+# file. If any limit is exceeded the script fails, unless <warn only> is "true"
+# in which case only a warning is printed.
+
+apex_name="$1"
+warn_only="$2"
+report="$3"
+max_payload_size="$4"
+shift 4
+
+metrics=()
+while [[ $# -gt 0 && "$1" != "--" ]]; do
+    metrics+=("$1 $2 $3")
+    shift 3
+done
+shift
+
+payload_size=0
+for f in "$@"; do
+    payload_size=$((payload_size + $(stat -L -c %s "${f}")))
+done
+
+rm -f "${report}"
+exceeded=0
+for m in "payload_size ${payload_size} ${max_payload_size}" "${metrics[@]}"; do
+    read -r name value limit <<< "${m}"
+    echo "${name} ${value} ${limit}" >> "${report}"
+    if [[ "${limit}" -ge 0 && "${value}" -gt "${limit}" ]]; then
+        echo "${apex_name}: estimated ${name} ${value} exceeds the init_cost_budget limit of ${limit}" >&2
+        exceeded=1
+    fi
+done
+
+if [[ ${exceeded} -ne 0 && "${warn_only}" != "true" ]]; then
+    echo "${apex_name}: boot-time initialization cost budget exceeded." >&2
+    echo "Reduce the contents of the APEX or raise its init_cost_budget." >&2
+    exit 1
+fi
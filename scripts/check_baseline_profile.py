@@ -0,0 +1,158 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Checks that the classes of a baseline profile exist in the dex files of an app.
+
+profgen silently drops the rules of a baseline profile that don't match the dex
+files, so a class that was renamed, or removed by R8, would stop being
+precompiled without anyone noticing. The classes referenced by the
+human-readable profile are compared with the class definitions of the dex files
+in the dex jar, after mapping them with the R8 dictionary if there is one.
+Wildcard rules are not checked.
+"""
+
+import argparse
+import re
+import struct
+import sys
+import zipfile
+
+# A rule of a human-readable profile, e.g. "HSPLcom/example/Foo;->bar()V".
+_RULE_RE = re.compile(r'^[HSP]*(L[^;]+;)')
+
+_DEX_CLASS_RE = re.compile(r'^classes[0-9]*\.dex$')
+
+
+def parse_args(args):
+  """Parse commandline arguments."""
+  parser = argparse.ArgumentParser()
+  parser.add_argument('--profile', required=True,
+                      help='merged human-readable baseline profile')
+  parser.add_argument('--dex_jar', required=True,
+                      help='jar containing the dex files of the app')
+  parser.add_argument('--map',
+                      help='R8 dictionary of the dex files')
+  parser.add_argument('--out', required=True,
+                      help='file to touch when the check passes')
+  return parser.parse_args(args)
+
+
+def read_uleb128(data, offset):
+  """Returns the value of the uleb128 at offset and the offset after it."""
+  result = 0
+  shift = 0
+  while True:
+    byte = data[offset]
+    offset += 1
+    result |= (byte & 0x7f) << shift
+    if byte & 0x80 == 0:
+      return result, offset
+    shift += 7
+
+
+def dex_classes(data):
+  """Returns the descriptors of the classes defined in a dex file."""
+  string_ids_off, = struct.unpack_from('<I', data, 0x3c)
+  type_ids_off, = struct.unpack_from('<I', data, 0x44)
+  class_defs_size, class_defs_off = struct.unpack_from('<II', data, 0x60)
+  classes = set()
+  for i in range(class_defs_size):
+    class_idx, = struct.unpack_from('<I', data, class_defs_off + i * 32)
+    descriptor_idx, = struct.unpack_from('<I', data, type_ids_off + class_idx * 4)
+    string_data_off, = struct.unpack_from('<I', data, string_ids_off + descriptor_idx * 4)
+    _, start = read_uleb128(data, string_data_off)
+    end = data.index(b'\0', start)
+    classes.add(data[start:end].decode('utf-8', errors='replace'))
+  return classes
+
+
+def dex_jar_classes(path):
+  """Returns the descriptors of the classes defined in the dex files of a jar."""
+  classes = set()
+  with zipfile.ZipFile(path) as jar:
+    for name in jar.namelist():
+      if _DEX_CLASS_RE.match(name):
+        classes.update(dex_classes(jar.read(name)))
+  return classes
+
+
+def descriptor(class_name):
+  """Returns the descriptor of a class name in dot notation."""
+  return 'L' + class_name.replace('.', '/') + ';'
+
+
+def read_class_map(path):
+  """Returns the obfuscated descriptors of the classes in an R8 dictionary."""
+  class_map = {}
+  with open(path) as f:
+    for line in f:
+      if line.startswith(('#', ' ')) or ' -> ' not in line:
+        continue
+      original, obfuscated = line.rstrip().rstrip(':').split(' -> ')
+      class_map[descriptor(original)] = descriptor(obfuscated)
+  return class_map
+
+
+def profile_classes(path):
+  """Returns the descriptors of the classes referenced by a profile."""
+  classes = {}
+  with open(path) as f:
+    for line_number, line in enumerate(f, 1):
+      line = line.strip()
+      if not line or line.startswith('#'):
+        continue
+      match = _RULE_RE.match(line)
+      if not match or '*' in match.group(1):
+        continue
+      classes.setdefault(match.group(1), line_number)
+  return classes
+
+
+def missing_classes(profile, dex_jar, class_map=None):
+  """Returns the classes of the profile that are not in the dex jar."""
+  existing = dex_jar_classes(dex_jar)
+  missing = []
+  for cls, line_number in sorted(profile_classes(profile).items(),
+                                 key=lambda item: item[1]):
+    if class_map is not None:
+      if cls not in class_map:
+        missing.append((cls, line_number))
+        continue
+      cls_in_dex = class_map[cls]
+    else:
+      cls_in_dex = cls
+    if cls_in_dex not in existing:
+      missing.append((cls, line_number))
+  return missing
+
+
+def main():
+  args = parse_args(sys.argv[1:])
+  class_map = read_class_map(args.map) if args.map else None
+  missing = missing_classes(args.profile, args.dex_jar, class_map)
+  if missing:
+    for cls, line_number in missing:
+      print('%s:%d: %s is not in the dex files of the app' %
+            (args.profile, line_number, cls), file=sys.stderr)
+    print('Remove the classes from the baseline profile, or keep them with a '
+          'proguard rule if they are removed by R8.', file=sys.stderr)
+    sys.exit(1)
+  with open(args.out, 'w'):
+    pass
+
+
+if __name__ == '__main__':
+  main()
@@ -0,0 +1,104 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for check_baseline_profile.py."""
+
+import os
+import struct
+import tempfile
+import unittest
+import zipfile
+
+import check_baseline_profile
+
+
+def make_dex(classes):
+  """Returns a dex file that only has the ids needed to define the classes."""
+  header_size = 0x70
+  count = len(classes)
+  string_ids_off = header_size
+  type_ids_off = string_ids_off + 4 * count
+  class_defs_off = type_ids_off + 4 * count
+  string_data_off = class_defs_off + 32 * count
+
+  data = bytearray(header_size)
+  struct.pack_into('<II', data, 0x38, count, string_ids_off)
+  struct.pack_into('<II', data, 0x40, count, type_ids_off)
+  struct.pack_into('<II', data, 0x60, count, class_defs_off)
+
+  string_data = bytearray()
+  for cls in classes:
+    data += struct.pack('<I', string_data_off + len(string_data))
+    string_data += bytes([len(cls)]) + cls.encode() + b'\0'
+  for i in range(count):
+    data += struct.pack('<I', i)
+  for i in range(count):
+    data += struct.pack('<I', i) + bytes(28)
+  return bytes(data + string_data)
+
+
+class CheckBaselineProfileTest(unittest.TestCase):
+
+  def setUp(self):
+    self._tmp = tempfile.TemporaryDirectory()
+    self.dex_jar = os.path.join(self._tmp.name, 'dex.jar')
+    with zipfile.ZipFile(self.dex_jar, 'w') as jar:
+      jar.writestr('classes.dex', make_dex(['Lcom/example/Foo;', 'La/a;']))
+      jar.writestr('classes2.dex', make_dex(['Lcom/example/Bar;']))
+    self.profile = os.path.join(self._tmp.name, 'baseline-prof.txt')
+
+  def tearDown(self):
+    self._tmp.cleanup()
+
+  def missing(self, profile, class_map=None):
+    with open(self.profile, 'w') as f:
+      f.write(profile)
+    return check_baseline_profile.missing_classes(self.profile, self.dex_jar,
+                                                  class_map)
+
+  def test_dex_jar_classes(self):
+    self.assertEqual(check_baseline_profile.dex_jar_classes(self.dex_jar),
+                     {'Lcom/example/Foo;', 'La/a;', 'Lcom/example/Bar;'})
+
+  def test_existing_classes(self):
+    self.assertEqual(self.missing(
+        '# comment\n'
+        'Lcom/example/Foo;\n'
+        'HSPLcom/example/Bar;-><init>()V\n'
+        'Lcom/example/gone/**;\n'), [])
+
+  def test_missing_classes(self):
+    self.assertEqual(self.missing(
+        'Lcom/example/Foo;\n'
+        'PLcom/example/Baz;->run()V\n'),
+                     [('Lcom/example/Baz;', 2)])
+
+  def test_obfuscated_classes(self):
+    class_map_path = os.path.join(self._tmp.name, 'proguard_dictionary')
+    with open(class_map_path, 'w') as f:
+      f.write('# compiler: R8\n'
+              'com.example.Shrunk -> a.a:\n'
+              '    void run() -> a\n')
+    class_map = check_baseline_profile.read_class_map(class_map_path)
+    self.assertEqual(class_map, {'Lcom/example/Shrunk;': 'La/a;'})
+    self.assertEqual(self.missing(
+        'Lcom/example/Shrunk;\n'
+        'Lcom/example/Foo;\n', class_map),
+                     [('Lcom/example/Foo;', 2)])
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
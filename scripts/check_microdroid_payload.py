@@ -0,0 +1,153 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""A tool for validating the payload of a Microdroid virtual machine.
+
+The VM payload config must have a task to run and list exactly the apexes of the
+payload, and the apexes must be signed. The validated config is copied to the
+output, so that the payload image is only built from a valid config.
+"""
+
+from __future__ import print_function
+
+import argparse
+import json
+import shutil
+import subprocess
+import sys
+
+KNOWN_KEYS = frozenset([
+    'version', 'os', 'task', 'apexes', 'extra_apks', 'prefer_staged',
+    'export_tombstones', 'enable_authfs'
+])
+
+TASK_TYPES = frozenset(['microdroid_launcher', 'executable'])
+
+
+def parse_args():
+    """Parse commandline arguments."""
+
+    parser = argparse.ArgumentParser()
+    parser.add_argument('--vm-config', required=True,
+                        help='the VM payload config, vm_config.json')
+    parser.add_argument('--apksigner', required=True,
+                        help='path to apksigner')
+    parser.add_argument('--apex', action='append', default=[],
+                        help='<name>=<path> of an apex of the payload')
+    parser.add_argument('-o', dest='output', required=True,
+                        help='output path of the validated config')
+    return parser.parse_args()
+
+
+def parse_apexes(values):
+    """Returns the paths of the apexes by name from <name>=<path> arguments."""
+
+    apexes = {}
+    for value in values:
+        name, sep, path = value.partition('=')
+        if not sep or not name or not path:
+            raise ValueError('--apex must be <name>=<path>, got %r' % value)
+        apexes[name] = path
+    return apexes
+
+
+def check_vm_config(config, apexes):
+    """Returns the errors of a VM payload config for a payload with the given apexes."""
+
+    if not isinstance(config, dict):
+        return ['the config must be a JSON object']
+
+    errors = []
+    for key in sorted(set(config) - KNOWN_KEYS):
+        errors.append('unknown key %r' % key)
+
+    os_config = config.get('os')
+    if os_config is not None:
+        if not isinstance(os_config, dict) or not isinstance(
+                os_config.get('name'), str):
+            errors.append('os must be an object with a name')
+
+    task = config.get('task')
+    if not isinstance(task, dict):
+        errors.append('task is required')
+    else:
+        if task.get('type') not in TASK_TYPES:
+            errors.append('task type must be one of %s, got %r' %
+                          (', '.join(sorted(TASK_TYPES)), task.get('type')))
+        if not isinstance(task.get('command'), str) or not task['command']:
+            errors.append('task command is required')
+
+    listed = set()
+    for apex in config.get('apexes') or []:
+        name = apex.get('name') if isinstance(apex, dict) else None
+        if not isinstance(name, str) or not name:
+            errors.append('apexes must be objects with a name')
+            continue
+        if name in listed:
+            errors.append('apex %r is listed more than once' % name)
+        listed.add(name)
+    for name in sorted(listed - set(apexes)):
+        errors.append('apex %r of the config is not in the payload' % name)
+    for name in sorted(set(apexes) - listed):
+        errors.append('apex %r of the payload is not listed in the config' %
+                      name)
+    return errors
+
+
+def check_signatures(apksigner, apexes):
+    """Returns the errors of apexes whose signature apksigner fails to verify."""
+
+    errors = []
+    for name in sorted(apexes):
+        proc = subprocess.run([apksigner, 'verify', apexes[name]],
+                              stdout=subprocess.PIPE,
+                              stderr=subprocess.STDOUT,
+                              check=False)
+        if proc.returncode != 0:
+            errors.append('apex %r has no valid signature: %s' %
+                          (name, proc.stdout.decode('utf-8').strip()))
+    return errors
+
+
+def main():
+    """Program entry point."""
+    try:
+        args = parse_args()
+        apexes = parse_apexes(args.apex)
+        with open(args.vm_config) as f:
+            try:
+                config = json.load(f)
+            except ValueError as err:
+                raise ValueError('%s is not valid JSON: %s' %
+                                 (args.vm_config, err))
+
+        errors = check_vm_config(config, apexes)
+        errors.extend(check_signatures(args.apksigner, apexes))
+        if errors:
+            for error in errors:
+                print('%s: %s' % (args.vm_config, error), file=sys.stderr)
+            sys.exit(1)
+
+        shutil.copyfile(args.vm_config, args.output)
+
+    # pylint: disable=broad-except
+    except Exception as err:
+        print('error: ' + str(err), file=sys.stderr)
+        sys.exit(-1)
+
+
+if __name__ == '__main__':
+    main()
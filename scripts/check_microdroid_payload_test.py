@@ -0,0 +1,110 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for check_microdroid_payload.py."""
+
+import sys
+import unittest
+
+import check_microdroid_payload
+
+sys.dont_write_bytecode = True
+
+APEXES = {'com.android.foo': 'out/foo.apex'}
+
+
+class CheckVmConfigTest(unittest.TestCase):
+    """Unit tests for check_vm_config."""
+
+    def config(self, **kwargs):
+        config = {
+            'os': {'name': 'microdroid'},
+            'task': {'type': 'microdroid_launcher', 'command': 'libfoo.so'},
+            'apexes': [{'name': 'com.android.foo'}],
+        }
+        config.update(kwargs)
+        return config
+
+    def test_valid(self):
+        self.assertEqual(
+            check_microdroid_payload.check_vm_config(self.config(), APEXES), [])
+
+    def test_not_an_object(self):
+        self.assertEqual(check_microdroid_payload.check_vm_config([], APEXES),
+                         ['the config must be a JSON object'])
+
+    def test_unknown_key(self):
+        self.assertEqual(
+            check_microdroid_payload.check_vm_config(
+                self.config(apexs=[]), APEXES), ["unknown key 'apexs'"])
+
+    def test_task(self):
+        config = self.config(task={'type': 'shell'})
+        self.assertEqual(
+            check_microdroid_payload.check_vm_config(config, APEXES), [
+                "task type must be one of executable, microdroid_launcher, "
+                "got 'shell'",
+                'task command is required',
+            ])
+        del config['task']
+        self.assertEqual(
+            check_microdroid_payload.check_vm_config(config, APEXES),
+            ['task is required'])
+
+    def test_apexes(self):
+        config = self.config(apexes=[{'name': 'com.android.bar'}])
+        self.assertEqual(
+            check_microdroid_payload.check_vm_config(config, APEXES), [
+                "apex 'com.android.bar' of the config is not in the payload",
+                "apex 'com.android.foo' of the payload is not listed in the "
+                "config",
+            ])
+
+    def test_duplicate_apexes(self):
+        config = self.config(apexes=[{
+            'name': 'com.android.foo'
+        }, {
+            'name': 'com.android.foo'
+        }])
+        self.assertEqual(
+            check_microdroid_payload.check_vm_config(config, APEXES),
+            ["apex 'com.android.foo' is listed more than once"])
+
+
+class ParseApexesTest(unittest.TestCase):
+    """Unit tests for parse_apexes."""
+
+    def test_parse_apexes(self):
+        self.assertEqual(
+            check_microdroid_payload.parse_apexes(
+                ['com.android.foo=out/foo.apex']), APEXES)
+        with self.assertRaises(ValueError):
+            check_microdroid_payload.parse_apexes(['out/foo.apex'])
+
+
+class CheckSignaturesTest(unittest.TestCase):
+    """Unit tests for check_signatures."""
+
+    def test_check_signatures(self):
+        self.assertEqual(
+            check_microdroid_payload.check_signatures('true', APEXES), [])
+        self.assertEqual(
+            check_microdroid_payload.check_signatures('false', APEXES),
+            ["apex 'com.android.foo' has no valid signature: "])
+
+
+if __name__ == '__main__':
+    unittest.main(verbosity=2)
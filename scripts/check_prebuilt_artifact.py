@@ -0,0 +1,208 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""A tool for checking that a prebuilt APK or APEX agrees with the build system."""
+
+from __future__ import print_function
+
+import argparse
+import re
+import subprocess
+import sys
+import zipfile
+
+# The API level from which each dex format version is supported.
+DEX_VERSION_MIN_SDK = {
+    '035': 1,
+    '037': 24,
+    '038': 26,
+    '039': 28,
+    '040': 34,
+}
+
+MIN_SDK_VERSION_PATTERN = re.compile(
+    r':minSdkVersion\(0x0101020c\)=(?:"([^"]*)"|\(type 0x10\)(0x[0-9a-f]+)|(\S+))')
+
+
+def parse_args():
+    """Parse commandline arguments."""
+
+    parser = argparse.ArgumentParser()
+    parser.add_argument('--aapt2', help='path to aapt2 executable')
+    parser.add_argument('--deapexer', help='path to deapexer executable')
+    parser.add_argument('--debugfs', help='path to debugfs executable')
+    parser.add_argument('--blkid', help='path to blkid executable')
+    parser.add_argument('--fsckerofs', help='path to fsck.erofs executable')
+    parser.add_argument(
+        '--min-sdk-version',
+        help='the minSdkVersion that the artifact is expected to declare')
+    parser.add_argument(
+        '--native-lib',
+        dest='native_libs',
+        action='append',
+        default=None,
+        help='a native library that the artifact is expected to embed')
+    parser.add_argument(
+        '--no-native-libs',
+        action='store_true',
+        help='the artifact is expected to embed no native library')
+    parser.add_argument(
+        '--has-code',
+        choices=['true', 'false'],
+        help='whether the artifact is expected to contain dex code')
+    parser.add_argument('input', help='input APK or APEX file')
+    return parser.parse_args()
+
+
+def extract_min_sdk_version(xmltree):
+    """Returns the minSdkVersion from an aapt2 dump of AndroidManifest.xml."""
+
+    match = MIN_SDK_VERSION_PATTERN.search(xmltree)
+    if match is None:
+        return '1'
+    if match.group(2):
+        return str(int(match.group(2), 16))
+    return match.group(1) or match.group(3)
+
+
+def apk_native_libs(names):
+    """Returns the native libraries of an APK from its zip entries, relative to lib/."""
+
+    return sorted(
+        name[len('lib/'):]
+        for name in names
+        if name.startswith('lib/') and name.endswith('.so'))
+
+
+def apex_native_libs(paths):
+    """Returns the native libraries of an APEX from the paths in its payload."""
+
+    pattern = re.compile(r'^lib(64)?/.*\.so$')
+    return sorted(p.lstrip('/') for p in paths if pattern.match(p.lstrip('/')))
+
+
+def dex_versions(apk):
+    """Returns the format versions of the dex files of an APK."""
+
+    versions = {}
+    for name in apk.namelist():
+        if re.match(r'^classes\d*\.dex$', name):
+            header = apk.open(name).read(8)
+            if header[:4] != b'dex\n':
+                raise RuntimeError('%s is not a dex file' % name)
+            versions[name] = header[4:7].decode('ascii')
+    return versions
+
+
+def check_dex_versions(versions, min_sdk_version):
+    """Returns the errors for dex files that min_sdk_version doesn't support."""
+
+    if not min_sdk_version.isdigit():
+        return []
+    errors = []
+    for name, version in sorted(versions.items()):
+        required = DEX_VERSION_MIN_SDK.get(version)
+        if required is None:
+            errors.append('%s has unknown dex version %s' % (name, version))
+        elif int(min_sdk_version) < required:
+            errors.append('%s has dex version %s, which requires API level %d, '
+                          'but minSdkVersion is %s' % (name, version, required,
+                                                       min_sdk_version))
+    return errors
+
+
+def check_native_libs(actual, expected):
+    """Returns the errors for native libraries that differ from the expected ones."""
+
+    errors = []
+    missing = sorted(set(expected) - set(actual))
+    extra = sorted(set(actual) - set(expected))
+    if missing:
+        errors.append('native_libs lists libraries that are not embedded: %s' %
+                      ', '.join(missing))
+    if extra:
+        errors.append('native_libs does not list embedded libraries: %s' %
+                      ', '.join(extra))
+    return errors
+
+
+def list_apex(args):
+    """Returns the paths of the files in the payload of an APEX."""
+
+    output = subprocess.check_output([
+        args.deapexer, '--debugfs_path', args.debugfs, '--blkid_path',
+        args.blkid, '--fsckerofs_path', args.fsckerofs, 'list', args.input
+    ]).decode('utf-8')
+    return output.splitlines()
+
+
+def main():
+    """Program entry point."""
+    try:
+        args = parse_args()
+        is_apex = args.input.endswith('.apex') or args.input.endswith('.capex')
+        errors = []
+
+        expected_native_libs = args.native_libs
+        if args.no_native_libs:
+            expected_native_libs = []
+
+        min_sdk_version = None
+        if args.min_sdk_version is not None:
+            xmltree = subprocess.check_output([
+                args.aapt2, 'dump', 'xmltree', '--file', 'AndroidManifest.xml',
+                args.input
+            ]).decode('utf-8')
+            min_sdk_version = extract_min_sdk_version(xmltree)
+            if min_sdk_version != args.min_sdk_version:
+                errors.append(
+                    'min_sdk_version is %s, but the manifest declares minSdkVersion %s'
+                    % (args.min_sdk_version, min_sdk_version))
+
+        if is_apex:
+            if expected_native_libs is not None:
+                errors.extend(
+                    check_native_libs(apex_native_libs(list_apex(args)),
+                                      expected_native_libs))
+        else:
+            with zipfile.ZipFile(args.input) as apk:
+                if expected_native_libs is not None:
+                    errors.extend(
+                        check_native_libs(apk_native_libs(apk.namelist()),
+                                          expected_native_libs))
+                versions = dex_versions(apk)
+                if args.has_code is not None:
+                    has_code = len(versions) > 0
+                    if has_code != (args.has_code == 'true'):
+                        errors.append(
+                            'has_code is %s, but the APK %s dex files' %
+                            (args.has_code,
+                             'contains' if has_code else 'contains no'))
+                if min_sdk_version is not None:
+                    errors.extend(check_dex_versions(versions, min_sdk_version))
+
+        if errors:
+            raise RuntimeError('%s does not match its Android.bp:\n  %s' %
+                               (args.input, '\n  '.join(errors)))
+
+    # pylint: disable=broad-except
+    except Exception as err:
+        print('error: ' + str(err), file=sys.stderr)
+        sys.exit(-1)
+
+
+if __name__ == '__main__':
+    main()
@@ -0,0 +1,121 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for check_prebuilt_artifact.py."""
+
+import io
+import sys
+import unittest
+import zipfile
+
+import check_prebuilt_artifact
+
+sys.dont_write_bytecode = True
+
+
+class ExtractMinSdkVersionTest(unittest.TestCase):
+    """Unit tests for extract_min_sdk_version function."""
+
+    def test_decimal(self):
+        xmltree = ('E: uses-sdk (line=5)\n'
+                   '  A: http://schemas.android.com/apk/res/android:'
+                   'minSdkVersion(0x0101020c)=29\n')
+        self.assertEqual(
+            check_prebuilt_artifact.extract_min_sdk_version(xmltree), '29')
+
+    def test_hex(self):
+        xmltree = ('  A: http://schemas.android.com/apk/res/android:'
+                   'minSdkVersion(0x0101020c)=(type 0x10)0x1d\n')
+        self.assertEqual(
+            check_prebuilt_artifact.extract_min_sdk_version(xmltree), '29')
+
+    def test_codename(self):
+        xmltree = ('  A: http://schemas.android.com/apk/res/android:'
+                   'minSdkVersion(0x0101020c)="Tiramisu" (Raw: "Tiramisu")\n')
+        self.assertEqual(
+            check_prebuilt_artifact.extract_min_sdk_version(xmltree),
+            'Tiramisu')
+
+    def test_missing(self):
+        self.assertEqual(
+            check_prebuilt_artifact.extract_min_sdk_version('E: manifest\n'),
+            '1')
+
+
+class NativeLibsTest(unittest.TestCase):
+    """Unit tests for the native library checks."""
+
+    def test_apk_native_libs(self):
+        names = [
+            'AndroidManifest.xml', 'classes.dex', 'lib/arm64-v8a/libfoo.so',
+            'lib/armeabi-v7a/libfoo.so', 'assets/lib/libbar.so'
+        ]
+        self.assertEqual(
+            check_prebuilt_artifact.apk_native_libs(names),
+            ['arm64-v8a/libfoo.so', 'armeabi-v7a/libfoo.so'])
+
+    def test_apex_native_libs(self):
+        paths = [
+            '/apex_manifest.pb', '/lib64/libfoo.so', '/lib64/bionic/libc.so',
+            '/bin/foo', '/etc/lib/libbar.so'
+        ]
+        self.assertEqual(
+            check_prebuilt_artifact.apex_native_libs(paths),
+            ['lib64/bionic/libc.so', 'lib64/libfoo.so'])
+
+    def test_check_native_libs(self):
+        errors = check_prebuilt_artifact.check_native_libs(
+            ['arm64-v8a/libfoo.so', 'arm64-v8a/libbar.so'],
+            ['arm64-v8a/libfoo.so', 'arm64-v8a/libbaz.so'])
+        self.assertEqual(errors, [
+            'native_libs lists libraries that are not embedded: '
+            'arm64-v8a/libbaz.so',
+            'native_libs does not list embedded libraries: arm64-v8a/libbar.so',
+        ])
+
+
+class DexTest(unittest.TestCase):
+    """Unit tests for the dex checks."""
+
+    def test_dex_versions(self):
+        buf = io.BytesIO()
+        with zipfile.ZipFile(buf, 'w') as apk:
+            apk.writestr('classes.dex', b'dex\n035\x00')
+            apk.writestr('classes2.dex', b'dex\n039\x00')
+            apk.writestr('assets/foo.dex', b'dex\n040\x00')
+        with zipfile.ZipFile(buf) as apk:
+            self.assertEqual(
+                check_prebuilt_artifact.dex_versions(apk), {
+                    'classes.dex': '035',
+                    'classes2.dex': '039'
+                })
+
+    def test_check_dex_versions(self):
+        versions = {'classes.dex': '035', 'classes2.dex': '039'}
+        self.assertEqual(
+            check_prebuilt_artifact.check_dex_versions(versions, '28'), [])
+        self.assertEqual(
+            check_prebuilt_artifact.check_dex_versions(versions, '26'), [
+                'classes2.dex has dex version 039, which requires API level '
+                '28, but minSdkVersion is 26'
+            ])
+        self.assertEqual(
+            check_prebuilt_artifact.check_dex_versions(versions, 'Tiramisu'),
+            [])
+
+
+if __name__ == '__main__':
+    unittest.main(verbosity=2)
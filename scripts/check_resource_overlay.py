@@ -0,0 +1,141 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Checks that a resource overlay only overrides existing resources of an app.
+
+aapt2 silently adds the resources of an overlay that don't exist in the app
+when --auto-add-overlay is passed, which it is for every app with static
+libraries. A misspelled resource name in a product overlay is then ignored
+instead of changing the app. The resources of the overlay directories are
+compared with the resources of the app's own resource directories and the R.txt
+files of its static libraries.
+"""
+
+import argparse
+import os
+import sys
+from xml.etree import ElementTree
+
+# Elements of values files that don't define a resource.
+_IGNORED_VALUES_ELEMENTS = {'eat-comment', 'java-symbol', 'public', 'skip',
+                            'add-resource', 'overlayable', 'staging-public-group',
+                            'public-group'}
+
+# Resource types of values elements whose tag name is not the resource type.
+_VALUES_ELEMENT_TYPES = {
+    'declare-styleable': 'styleable',
+    'integer-array': 'array',
+    'string-array': 'array',
+}
+
+
+def parse_args(args):
+  """Parse commandline arguments."""
+  parser = argparse.ArgumentParser()
+  parser.add_argument('--resource_dir', action='append', default=[],
+                      help='resource directory of the app')
+  parser.add_argument('--r_txt', action='append', default=[],
+                      help='R.txt file of a static library of the app')
+  parser.add_argument('--overlay_dir', action='append', default=[],
+                      help='resource overlay directory to check')
+  parser.add_argument('--out', required=True,
+                      help='file to touch when the check passes')
+  return parser.parse_args(args)
+
+
+def resource_name(name):
+  """Returns the name of a resource as it appears in R.txt."""
+  return name.replace('.', '_').replace(':', '_')
+
+
+def values_resources(path):
+  """Returns the (type, name) of the resources defined in a values file."""
+  resources = set()
+  root = ElementTree.parse(path).getroot()
+  for element in root:
+    if not isinstance(element.tag, str) or element.tag in _IGNORED_VALUES_ELEMENTS:
+      continue
+    name = element.get('name')
+    if not name:
+      continue
+    res_type = element.get('type') if element.tag == 'item' else None
+    res_type = res_type or _VALUES_ELEMENT_TYPES.get(element.tag, element.tag)
+    resources.add((res_type, resource_name(name)))
+  return resources
+
+
+def resource_dir_resources(resource_dir):
+  """Returns the (type, name) of the resources in a resource directory."""
+  resources = {}
+  for type_dir in sorted(os.listdir(resource_dir)):
+    type_path = os.path.join(resource_dir, type_dir)
+    if not os.path.isdir(type_path) or type_dir.startswith('.'):
+      continue
+    res_type = type_dir.split('-')[0]
+    for file_name in sorted(os.listdir(type_path)):
+      path = os.path.join(type_path, file_name)
+      if not os.path.isfile(path) or file_name.startswith('.'):
+        continue
+      if res_type == 'values':
+        defined = values_resources(path)
+      else:
+        defined = {(res_type, resource_name(file_name.split('.')[0]))}
+      for resource in defined:
+        resources.setdefault(resource, path)
+  return resources
+
+
+def r_txt_resources(path):
+  """Returns the (type, name) of the resources listed in an R.txt file."""
+  resources = set()
+  with open(path) as f:
+    for line in f:
+      fields = line.split()
+      if len(fields) >= 3:
+        resources.add((fields[1], fields[2]))
+  return resources
+
+
+def missing_resources(resource_dirs, r_txts, overlay_dirs):
+  """Returns the resources of the overlay dirs that the app doesn't have."""
+  existing = set()
+  for resource_dir in resource_dirs:
+    existing.update(resource_dir_resources(resource_dir))
+  for r_txt in r_txts:
+    existing.update(r_txt_resources(r_txt))
+
+  missing = []
+  for overlay_dir in overlay_dirs:
+    for resource, path in sorted(resource_dir_resources(overlay_dir).items()):
+      if resource not in existing:
+        missing.append((resource, path))
+  return missing
+
+
+def main():
+  args = parse_args(sys.argv[1:])
+  missing = missing_resources(args.resource_dir, args.r_txt, args.overlay_dir)
+  if missing:
+    for (res_type, name), path in missing:
+      print('%s: @%s/%s does not override a resource of the app' %
+            (path, res_type, name), file=sys.stderr)
+    sys.exit(1)
+  with open(args.out, 'w'):
+    pass
+
+
+if __name__ == '__main__':
+  main()
@@ -0,0 +1,79 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for check_resource_overlay.py."""
+
+import os
+import tempfile
+import unittest
+
+import check_resource_overlay
+
+
+def write_file(root, path, content=''):
+  path = os.path.join(root, path)
+  os.makedirs(os.path.dirname(path), exist_ok=True)
+  with open(path, 'w') as f:
+    f.write(content)
+
+
+class CheckResourceOverlayTest(unittest.TestCase):
+
+  def setUp(self):
+    self._tmp = tempfile.TemporaryDirectory()
+    self.root = self._tmp.name
+    self.res = os.path.join(self.root, 'res')
+    self.overlay = os.path.join(self.root, 'overlay')
+    write_file(self.res, 'values/strings.xml', """<resources>
+      <string name="app_name">App</string>
+      <string-array name="choices"><item>a</item></string-array>
+      <style name="Theme.App" />
+      <item type="id" name="button" />
+    </resources>""")
+    write_file(self.res, 'drawable-hdpi/icon.png')
+    write_file(self.root, 'R.txt',
+               'int color lib_accent 0x7f010000\n'
+               'int[] styleable LibView { 0x7f020000 }\n')
+
+  def tearDown(self):
+    self._tmp.cleanup()
+
+  def missing(self):
+    return [resource for resource, _ in check_resource_overlay.missing_resources(
+        [self.res], [os.path.join(self.root, 'R.txt')], [self.overlay])]
+
+  def test_overrides_existing_resources(self):
+    write_file(self.overlay, 'values-de/strings.xml', """<resources>
+      <string name="app_name">Anwendung</string>
+      <array name="choices"><item>b</item></array>
+      <style name="Theme.App" />
+      <color name="lib_accent">#ff0000</color>
+      <declare-styleable name="LibView" />
+      <eat-comment />
+    </resources>""")
+    write_file(self.overlay, 'drawable-xxhdpi/icon.webp')
+    self.assertEqual(self.missing(), [])
+
+  def test_new_resources(self):
+    write_file(self.overlay, 'values/strings.xml', """<resources>
+      <string name="app_nmae">Typo</string>
+    </resources>""")
+    write_file(self.overlay, 'drawable/logo.png')
+    self.assertEqual(self.missing(), [('drawable', 'logo'), ('string', 'app_nmae')])
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
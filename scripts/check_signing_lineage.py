@@ -0,0 +1,93 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Checks that a signing certificate lineage rotates to an app's certificate.
+
+apksig signs an APK with the newest signer of the lineage for APK Signature
+Scheme v3, so a lineage that doesn't end with the certificate the app is signed
+with produces an APK that fails to install, or silently loses the rotation on
+older releases. The certificates of the lineage are printed with apksigner and
+the newest one is compared with the signing certificate.
+"""
+
+import argparse
+import base64
+import hashlib
+import re
+import subprocess
+import sys
+
+_DIGEST_RE = re.compile(
+    r'^Signer #(\d+) in lineage certificate SHA-256 digest: ([0-9a-fA-F]+)$')
+
+
+def parse_args(args):
+  """Parse commandline arguments."""
+  parser = argparse.ArgumentParser()
+  parser.add_argument('--apksigner', required=True,
+                      help='path to apksigner')
+  parser.add_argument('--lineage', required=True,
+                      help='signing certificate lineage')
+  parser.add_argument('--certificate', required=True,
+                      help='x509 PEM certificate the app is signed with')
+  parser.add_argument('--out', required=True,
+                      help='file to touch when the check passes')
+  return parser.parse_args(args)
+
+
+def certificate_digest(pem):
+  """Returns the SHA-256 digest of the first certificate of a PEM file."""
+  match = re.search(r'-----BEGIN CERTIFICATE-----(.*?)-----END CERTIFICATE-----',
+                    pem, re.DOTALL)
+  if not match:
+    raise ValueError('no certificate found')
+  return hashlib.sha256(base64.b64decode(''.join(match.group(1).split()))).hexdigest()
+
+
+def lineage_digests(output):
+  """Returns the SHA-256 digests of the signers printed by apksigner, oldest first."""
+  digests = []
+  for line in output.splitlines():
+    match = _DIGEST_RE.match(line.strip())
+    if match:
+      digests.append((int(match.group(1)), match.group(2).lower()))
+  return [digest for _, digest in sorted(digests)]
+
+
+def main():
+  args = parse_args(sys.argv[1:])
+  output = subprocess.check_output(
+      [args.apksigner, 'lineage', '--in', args.lineage, '--print-certs'],
+      text=True)
+  digests = lineage_digests(output)
+  with open(args.certificate) as f:
+    digest = certificate_digest(f.read())
+
+  if not digests:
+    sys.exit('%s: no signers found in the lineage' % args.lineage)
+  if digests[-1] != digest:
+    if digest in digests:
+      sys.exit('%s: %s is signer #%d of %d, but an app must be signed with the '
+               'newest signer of its lineage' %
+               (args.lineage, args.certificate, digests.index(digest) + 1,
+                len(digests)))
+    sys.exit('%s: %s is not in the lineage' % (args.lineage, args.certificate))
+  with open(args.out, 'w'):
+    pass
+
+
+if __name__ == '__main__':
+  main()
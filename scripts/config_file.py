@@ -0,0 +1,301 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Merges and validates the JSON and text proto files of config_file modules.
+
+The merge command applies overlays onto a config file in order. JSON objects
+are merged recursively, other JSON values in an overlay replace the value they
+override, and a null value removes the key. Text protos are merged with proto
+MergeFrom semantics: singular fields are replaced, message fields are merged
+and repeated fields are appended.
+
+The validate command checks that a JSON file conforms to a JSON schema, or that
+a text proto parses as a message type of a proto descriptor set.
+"""
+
+import argparse
+import json
+import re
+import sys
+
+_FORMATS = ['json', 'textproto']
+
+
+def parse_args(args):
+  """Parse commandline arguments."""
+  parser = argparse.ArgumentParser()
+  subparsers = parser.add_subparsers(dest='command', required=True)
+
+  merge = subparsers.add_parser('merge', help='apply overlays onto a config file')
+  merge.add_argument('--format', choices=_FORMATS, required=True)
+  merge.add_argument('--descriptor_set', help='proto descriptor set of a text proto')
+  merge.add_argument('--message_type', help='full name of the message of a text proto')
+  merge.add_argument('--out', required=True, help='merged config file')
+  merge.add_argument('input', help='config file')
+  merge.add_argument('overlays', nargs='*', help='overlays, applied in order')
+
+  validate = subparsers.add_parser('validate', help='validate a config file')
+  validate.add_argument('--format', choices=_FORMATS, required=True)
+  validate.add_argument('--schema', help='JSON schema of a JSON file')
+  validate.add_argument('--descriptor_set', help='proto descriptor set of a text proto')
+  validate.add_argument('--message_type', help='full name of the message of a text proto')
+  validate.add_argument('--stamp', required=True,
+                        help='file to touch when the config file is valid')
+  validate.add_argument('input', help='config file')
+
+  return parser.parse_args(args)
+
+
+def merge_json(base, overlay):
+  """Returns base with overlay applied onto it."""
+  if not isinstance(base, dict) or not isinstance(overlay, dict):
+    return overlay
+  merged = dict(base)
+  for key, value in overlay.items():
+    if value is None:
+      merged.pop(key, None)
+    elif key in merged:
+      merged[key] = merge_json(merged[key], value)
+    else:
+      merged[key] = value
+  return merged
+
+
+_JSON_TYPES = {
+    'object': lambda v: isinstance(v, dict),
+    'array': lambda v: isinstance(v, list),
+    'string': lambda v: isinstance(v, str),
+    'integer': lambda v: isinstance(v, int) and not isinstance(v, bool),
+    'number': lambda v: isinstance(v, (int, float)) and not isinstance(v, bool),
+    'boolean': lambda v: isinstance(v, bool),
+    'null': lambda v: v is None,
+}
+
+
+class JsonSchemaValidator:
+  """Validates JSON values against a JSON schema.
+
+  Supports the subset of JSON schema that config files use: type, enum, const,
+  properties, required, additionalProperties, items, the numeric, string and
+  array bounds, pattern, allOf, anyOf, oneOf and local $ref.
+  """
+
+  def __init__(self, schema):
+    self.root = schema
+
+  def validate(self, value):
+    """Returns the errors of value, empty if it conforms to the schema."""
+    errors = []
+    self._validate(value, self.root, '', errors)
+    return errors
+
+  def _resolve(self, ref):
+    if not ref.startswith('#'):
+      raise ValueError('unsupported $ref %r, only local references are supported' % ref)
+    schema = self.root
+    for part in ref[1:].split('/')[1:]:
+      schema = schema[part.replace('~1', '/').replace('~0', '~')]
+    return schema
+
+  def _validate(self, value, schema, path, errors):
+    where = path or '/'
+    if schema is True or schema == {}:
+      return
+    if schema is False:
+      errors.append('%s: not allowed' % where)
+      return
+    if '$ref' in schema:
+      self._validate(value, self._resolve(schema['$ref']), path, errors)
+
+    if 'type' in schema:
+      types = schema['type'] if isinstance(schema['type'], list) else [schema['type']]
+      if not any(_JSON_TYPES[t](value) for t in types):
+        errors.append('%s: expected %s, got %s' % (where, ' or '.join(types),
+                                                   json.dumps(value)))
+        return
+    if 'enum' in schema and value not in schema['enum']:
+      errors.append('%s: %s is not one of %s' % (where, json.dumps(value),
+                                                 json.dumps(schema['enum'])))
+    if 'const' in schema and value != schema['const']:
+      errors.append('%s: expected %s, got %s' % (where, json.dumps(schema['const']),
+                                                 json.dumps(value)))
+
+    if _JSON_TYPES['number'](value):
+      if 'minimum' in schema and value < schema['minimum']:
+        errors.append('%s: %s is less than %s' % (where, value, schema['minimum']))
+      if 'maximum' in schema and value > schema['maximum']:
+        errors.append('%s: %s is greater than %s' % (where, value, schema['maximum']))
+      if 'exclusiveMinimum' in schema and value <= schema['exclusiveMinimum']:
+        errors.append('%s: %s is not greater than %s' % (where, value,
+                                                         schema['exclusiveMinimum']))
+      if 'exclusiveMaximum' in schema and value >= schema['exclusiveMaximum']:
+        errors.append('%s: %s is not less than %s' % (where, value,
+                                                      schema['exclusiveMaximum']))
+
+    if isinstance(value, str):
+      if 'minLength' in schema and len(value) < schema['minLength']:
+        errors.append('%s: %s is shorter than %d' % (where, json.dumps(value),
+                                                     schema['minLength']))
+      if 'maxLength' in schema and len(value) > schema['maxLength']:
+        errors.append('%s: %s is longer than %d' % (where, json.dumps(value),
+                                                    schema['maxLength']))
+      if 'pattern' in schema and not re.search(schema['pattern'], value):
+        errors.append('%s: %s does not match %s' % (where, json.dumps(value),
+                                                    json.dumps(schema['pattern'])))
+
+    if isinstance(value, list):
+      if 'minItems' in schema and len(value) < schema['minItems']:
+        errors.append('%s: expected at least %d items, got %d' % (
+            where, schema['minItems'], len(value)))
+      if 'maxItems' in schema and len(value) > schema['maxItems']:
+        errors.append('%s: expected at most %d items, got %d' % (
+            where, schema['maxItems'], len(value)))
+      if 'items' in schema:
+        for i, item in enumerate(value):
+          self._validate(item, schema['items'], '%s/%d' % (path, i), errors)
+
+    if isinstance(value, dict):
+      for key in schema.get('required', []):
+        if key not in value:
+          errors.append('%s: missing required property %s' % (where, json.dumps(key)))
+      properties = schema.get('properties', {})
+      additional = schema.get('additionalProperties', True)
+      for key, item in value.items():
+        item_path = '%s/%s' % (path, key)
+        if key in properties:
+          self._validate(item, properties[key], item_path, errors)
+        elif additional is False:
+          errors.append('%s: unexpected property %s' % (where, json.dumps(key)))
+        else:
+          self._validate(item, additional, item_path, errors)
+
+    for sub in schema.get('allOf', []):
+      self._validate(value, sub, path, errors)
+    if 'anyOf' in schema:
+      if not any(not self._errors(value, sub, path) for sub in schema['anyOf']):
+        errors.append('%s: does not match any schema of anyOf' % where)
+    if 'oneOf' in schema:
+      matches = sum(1 for sub in schema['oneOf'] if not self._errors(value, sub, path))
+      if matches != 1:
+        errors.append('%s: matches %d schemas of oneOf, expected 1' % (where, matches))
+
+  def _errors(self, value, schema, path):
+    errors = []
+    self._validate(value, schema, path, errors)
+    return errors
+
+
+def message_class(descriptor_set, message_type):
+  """Returns the class of a message type of a serialized FileDescriptorSet."""
+  # pylint: disable=import-outside-toplevel
+  from google.protobuf import descriptor_pb2
+  from google.protobuf import descriptor_pool
+  from google.protobuf import message_factory
+
+  files = descriptor_pb2.FileDescriptorSet()
+  files.ParseFromString(descriptor_set)
+  pool = descriptor_pool.DescriptorPool()
+  for file in files.file:
+    pool.Add(file)
+  try:
+    descriptor = pool.FindMessageTypeByName(message_type)
+  except KeyError:
+    raise ValueError('message type %s not found in the descriptor set' % message_type)
+  if hasattr(message_factory, 'GetMessageClass'):
+    return message_factory.GetMessageClass(descriptor)
+  return message_factory.MessageFactory(pool).GetPrototype(descriptor)
+
+
+def parse_text_proto(cls, text, name):
+  """Parses a text proto, raising ValueError with the file name on errors."""
+  # pylint: disable=import-outside-toplevel
+  from google.protobuf import text_format
+
+  try:
+    return text_format.Parse(text, cls())
+  except text_format.ParseError as e:
+    raise ValueError('%s:%s' % (name, e))
+
+
+def read_file(path, mode='r'):
+  with open(path, mode) as f:
+    return f.read()
+
+
+def load_json(path):
+  try:
+    return json.loads(read_file(path))
+  except ValueError as e:
+    raise ValueError('%s: %s' % (path, e))
+
+
+def text_proto_class(args):
+  if not args.descriptor_set or not args.message_type:
+    raise ValueError('text protos require --descriptor_set and --message_type')
+  return message_class(read_file(args.descriptor_set, 'rb'), args.message_type)
+
+
+def merge(args):
+  """Writes the config file with the overlays applied to args.out."""
+  if args.format == 'json':
+    merged = load_json(args.input)
+    for overlay in args.overlays:
+      merged = merge_json(merged, load_json(overlay))
+    output = json.dumps(merged, indent=2) + '\n'
+  else:
+    # pylint: disable=import-outside-toplevel
+    from google.protobuf import text_format
+
+    cls = text_proto_class(args)
+    merged = parse_text_proto(cls, read_file(args.input), args.input)
+    for overlay in args.overlays:
+      merged.MergeFrom(parse_text_proto(cls, read_file(overlay), overlay))
+    output = text_format.MessageToString(merged)
+  with open(args.out, 'w') as f:
+    f.write(output)
+
+
+def validate(args):
+  """Returns the errors of the config file."""
+  if args.format == 'json':
+    value = load_json(args.input)
+    if not args.schema:
+      return []
+    return ['%s: %s' % (args.input, e)
+            for e in JsonSchemaValidator(load_json(args.schema)).validate(value)]
+  parse_text_proto(text_proto_class(args), read_file(args.input), args.input)
+  return []
+
+
+def main():
+  args = parse_args(sys.argv[1:])
+  try:
+    if args.command == 'merge':
+      merge(args)
+      return
+    errors = validate(args)
+  except ValueError as e:
+    errors = [str(e)]
+  if errors:
+    for error in errors:
+      print('error: %s' % error, file=sys.stderr)
+    sys.exit(1)
+  with open(args.stamp, 'w'):
+    pass
+
+
+if __name__ == '__main__':
+  main()
@@ -0,0 +1,114 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for config_file.py."""
+
+import unittest
+
+import config_file
+
+
+class MergeJsonTest(unittest.TestCase):
+
+  def test_merge_objects(self):
+    base = {'a': 1, 'b': {'c': 2, 'd': 3}}
+    overlay = {'b': {'d': 4, 'e': 5}, 'f': 6}
+    self.assertEqual(config_file.merge_json(base, overlay),
+                     {'a': 1, 'b': {'c': 2, 'd': 4, 'e': 5}, 'f': 6})
+
+  def test_replace_arrays_and_scalars(self):
+    base = {'a': [1, 2], 'b': 'x', 'c': {'d': 1}}
+    overlay = {'a': [3], 'b': 'y', 'c': 2}
+    self.assertEqual(config_file.merge_json(base, overlay), {'a': [3], 'b': 'y', 'c': 2})
+
+  def test_null_removes_key(self):
+    base = {'a': 1, 'b': {'c': 2, 'd': 3}}
+    overlay = {'a': None, 'b': {'c': None}, 'e': None}
+    self.assertEqual(config_file.merge_json(base, overlay), {'b': {'d': 3}})
+
+  def test_does_not_modify_base(self):
+    base = {'a': {'b': 1}}
+    config_file.merge_json(base, {'a': {'b': 2}})
+    self.assertEqual(base, {'a': {'b': 1}})
+
+
+class JsonSchemaValidatorTest(unittest.TestCase):
+
+  schema = {
+      'type': 'object',
+      'required': ['name', 'size'],
+      'additionalProperties': False,
+      'properties': {
+          'name': {'type': 'string', 'pattern': '^[a-z_]+$'},
+          'size': {'type': 'integer', 'minimum': 1, 'maximum': 10},
+          'mode': {'enum': ['fast', 'slow']},
+          'tags': {'type': 'array', 'items': {'$ref': '#/definitions/tag'},
+                   'maxItems': 2},
+      },
+      'definitions': {
+          'tag': {'type': 'string', 'minLength': 1},
+      },
+  }
+
+  def validate(self, value):
+    return config_file.JsonSchemaValidator(self.schema).validate(value)
+
+  def test_valid(self):
+    self.assertEqual(self.validate({'name': 'foo', 'size': 3, 'mode': 'fast',
+                                    'tags': ['a', 'b']}), [])
+
+  def test_type(self):
+    self.assertEqual(self.validate([]), ['/: expected object, got []'])
+    self.assertEqual(self.validate({'name': 'foo', 'size': True}),
+                     ['/size: expected integer, got true'])
+
+  def test_required_and_additional_properties(self):
+    self.assertEqual(self.validate({'name': 'foo', 'color': 'red'}), [
+        '/: missing required property "size"',
+        '/: unexpected property "color"',
+    ])
+
+  def test_bounds(self):
+    self.assertEqual(self.validate({'name': 'Foo', 'size': 11}), [
+        '/name: "Foo" does not match "^[a-z_]+$"',
+        '/size: 11 is greater than 10',
+    ])
+
+  def test_enum(self):
+    self.assertEqual(self.validate({'name': 'foo', 'size': 1, 'mode': 'medium'}),
+                     ['/mode: "medium" is not one of ["fast", "slow"]'])
+
+  def test_items_and_ref(self):
+    self.assertEqual(self.validate({'name': 'foo', 'size': 1, 'tags': ['', 'b', 'c']}), [
+        '/tags: expected at most 2 items, got 3',
+        '/tags/0: "" is shorter than 1',
+    ])
+
+  def test_one_of(self):
+    validator = config_file.JsonSchemaValidator(
+        {'oneOf': [{'type': 'integer'}, {'type': 'number'}]})
+    self.assertEqual(validator.validate(1.5), [])
+    self.assertEqual(validator.validate(1),
+                     ['/: matches 2 schemas of oneOf, expected 1'])
+
+  def test_remote_ref(self):
+    validator = config_file.JsonSchemaValidator({'$ref': 'other.json'})
+    with self.assertRaises(ValueError):
+      validator.validate(1)
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
@@ -0,0 +1,238 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""A tool for merging the coverage of the modules of a build into a tree-wide report.
+
+The coverage data collected from devices or hosts, .profraw and .profdata files
+for native code and .ec files for Java code, is merged with the instrumented
+binaries and classes listed in the manifest written by Soong into an lcov
+report, a JSON summary with the coverage rolled up by directory, and an HTML
+index of the directories.
+"""
+
+from __future__ import print_function
+
+import argparse
+import collections
+import html
+import json
+import os
+import subprocess
+import sys
+import xml.etree.ElementTree as ET
+
+
+def parse_args():
+    """Parse commandline arguments."""
+
+    parser = argparse.ArgumentParser()
+    parser.add_argument('--manifest', required=True,
+                        help='JSON manifest of the instrumented modules')
+    parser.add_argument('--data-dir', required=True,
+                        help='directory with the collected coverage data')
+    parser.add_argument('--llvm-profdata', help='path to llvm-profdata')
+    parser.add_argument('--llvm-cov', help='path to llvm-cov')
+    parser.add_argument('--java', help='path to java')
+    parser.add_argument('--jacoco-cli', help='path to jacoco-cli.jar')
+    parser.add_argument('--out-dir', required=True, help='output directory')
+    return parser.parse_args()
+
+
+def find_data_files(data_dir):
+    """Returns the native and Java coverage data files under data_dir."""
+
+    native, java = [], []
+    for root, _, files in os.walk(data_dir):
+        for f in files:
+            path = os.path.join(root, f)
+            if f.endswith('.profraw') or f.endswith('.profdata'):
+                native.append(path)
+            elif f.endswith('.ec'):
+                java.append(path)
+    return sorted(native), sorted(java)
+
+
+def parse_lcov(text):
+    """Returns the line hit counts by source file of an lcov report."""
+
+    coverage = collections.defaultdict(dict)
+    source = None
+    for line in text.splitlines():
+        line = line.strip()
+        if line.startswith('SF:'):
+            source = os.path.normpath(line[len('SF:'):])
+        elif line.startswith('DA:') and source is not None:
+            fields = line[len('DA:'):].split(',')
+            number, hits = int(fields[0]), int(fields[1])
+            coverage[source][number] = coverage[source].get(number, 0) + hits
+        elif line == 'end_of_record':
+            source = None
+    return coverage
+
+
+def merge_coverage(dst, src):
+    """Adds the line hit counts of src to dst."""
+
+    for source, lines in src.items():
+        dst_lines = dst.setdefault(source, {})
+        for number, hits in lines.items():
+            dst_lines[number] = dst_lines.get(number, 0) + hits
+
+
+def format_lcov(coverage):
+    """Returns the lcov report of line hit counts by source file."""
+
+    out = []
+    for source in sorted(coverage):
+        lines = coverage[source]
+        out.append('SF:%s' % source)
+        for number in sorted(lines):
+            out.append('DA:%d,%d' % (number, lines[number]))
+        out.append('LH:%d' % sum(1 for hits in lines.values() if hits > 0))
+        out.append('LF:%d' % len(lines))
+        out.append('end_of_record')
+    return '\n'.join(out) + '\n' if out else ''
+
+
+def source_path(package, sourcefile, srcs):
+    """Returns the source file of the tree that a jacoco source file comes from."""
+
+    suffix = '/'.join(p for p in (package, sourcefile) if p)
+    for src in srcs:
+        if src == suffix or src.endswith('/' + suffix):
+            return src
+    return suffix
+
+
+def parse_jacoco_xml(text, srcs):
+    """Returns the line hit counts by source file of a jacoco XML report."""
+
+    coverage = collections.defaultdict(dict)
+    root = ET.fromstring(text)
+    for package in root.iter('package'):
+        for sourcefile in package.findall('sourcefile'):
+            path = source_path(package.get('name'), sourcefile.get('name'), srcs)
+            for line in sourcefile.findall('line'):
+                # A line is hit if any of its instructions is covered.
+                coverage[path][int(line.get('nr'))] = int(line.get('ci', '0'))
+    return coverage
+
+
+def rollup(coverage):
+    """Returns the lines found and hit by directory, including the root directory '.'."""
+
+    dirs = collections.defaultdict(lambda: {'lines_found': 0, 'lines_hit': 0})
+    for source, lines in coverage.items():
+        found = len(lines)
+        hit = sum(1 for hits in lines.values() if hits > 0)
+        d = os.path.dirname(source)
+        while True:
+            dirs[d or '.']['lines_found'] += found
+            dirs[d or '.']['lines_hit'] += hit
+            if not d:
+                break
+            d = os.path.dirname(d)
+    return dict(dirs)
+
+
+def percent(stats):
+    if stats['lines_found'] == 0:
+        return 0.0
+    return 100.0 * stats['lines_hit'] / stats['lines_found']
+
+
+def format_html(dirs):
+    """Returns the HTML index of the coverage by directory."""
+
+    rows = []
+    for d in sorted(dirs):
+        stats = dirs[d]
+        rows.append(
+            '<tr><td>%s</td><td>%d</td><td>%d</td><td>%.1f%%</td></tr>' %
+            (html.escape(d), stats['lines_hit'], stats['lines_found'],
+             percent(stats)))
+    return ('<!DOCTYPE html>\n<html><head><title>Coverage</title></head><body>\n'
+            '<table>\n<tr><th>Directory</th><th>Lines hit</th>'
+            '<th>Lines found</th><th>Coverage</th></tr>\n' + '\n'.join(rows) +
+            '\n</table>\n</body></html>\n')
+
+
+def native_coverage(args, manifest, profiles):
+    """Returns the coverage of the native modules of the manifest."""
+
+    objects = [m['object'] for m in manifest.get('native') or []]
+    if not objects or not profiles:
+        return {}
+    profdata = os.path.join(args.out_dir, 'merged.profdata')
+    subprocess.check_call([args.llvm_profdata, 'merge', '-sparse', '-o',
+                           profdata] + profiles)
+    cmd = [args.llvm_cov, 'export', '-format=lcov',
+           '-instr-profile=' + profdata, objects[0]]
+    for obj in objects[1:]:
+        cmd.extend(['-object', obj])
+    return parse_lcov(subprocess.check_output(cmd).decode('utf-8'))
+
+
+def java_coverage(args, manifest, execs):
+    """Returns the coverage of the Java modules of the manifest."""
+
+    modules = manifest.get('java') or []
+    if not modules or not execs:
+        return {}
+    xml_report = os.path.join(args.out_dir, 'jacoco.xml')
+    cmd = [args.java, '-jar', args.jacoco_cli, 'report'] + execs
+    for m in modules:
+        cmd.extend(['--classfiles', m['classes_jar']])
+    cmd.extend(['--xml', xml_report, '--quiet'])
+    subprocess.check_call(cmd)
+    srcs = [src for m in modules for src in m.get('srcs') or []]
+    with open(xml_report) as f:
+        return parse_jacoco_xml(f.read(), srcs)
+
+
+def main():
+    """Program entry point."""
+    try:
+        args = parse_args()
+        with open(args.manifest) as f:
+            manifest = json.load(f)
+        if not os.path.isdir(args.data_dir):
+            raise RuntimeError('coverage data directory %s does not exist' %
+                               args.data_dir)
+        if not os.path.isdir(args.out_dir):
+            os.makedirs(args.out_dir)
+
+        profiles, execs = find_data_files(args.data_dir)
+        coverage = {}
+        merge_coverage(coverage, native_coverage(args, manifest, profiles))
+        merge_coverage(coverage, java_coverage(args, manifest, execs))
+        dirs = rollup(coverage)
+
+        with open(os.path.join(args.out_dir, 'coverage.lcov'), 'w') as f:
+            f.write(format_lcov(coverage))
+        with open(os.path.join(args.out_dir, 'summary.json'), 'w') as f:
+            json.dump({'dirs': dirs}, f, indent=2, sort_keys=True)
+        with open(os.path.join(args.out_dir, 'index.html'), 'w') as f:
+            f.write(format_html(dirs))
+
+    # pylint: disable=broad-except
+    except Exception as err:
+        print('error: ' + str(err), file=sys.stderr)
+        sys.exit(-1)
+
+
+if __name__ == '__main__':
+    main()
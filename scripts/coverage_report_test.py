@@ -0,0 +1,108 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for coverage_report.py."""
+
+import sys
+import unittest
+
+import coverage_report
+
+sys.dont_write_bytecode = True
+
+
+class LcovTest(unittest.TestCase):
+    """Unit tests for the lcov functions."""
+
+    def test_parse_and_merge(self):
+        first = coverage_report.parse_lcov(
+            'TN:\nSF:external/foo/foo.cpp\nDA:1,1\nDA:2,0\nLH:1\nLF:2\n'
+            'end_of_record\n')
+        second = coverage_report.parse_lcov(
+            'SF:external/foo/foo.cpp\nDA:2,3\nend_of_record\n'
+            'SF:external/foo/bar.cpp\nDA:5,0\nend_of_record\n')
+        coverage = {}
+        coverage_report.merge_coverage(coverage, first)
+        coverage_report.merge_coverage(coverage, second)
+        self.assertEqual(coverage, {
+            'external/foo/foo.cpp': {1: 1, 2: 3},
+            'external/foo/bar.cpp': {5: 0},
+        })
+        self.assertEqual(
+            coverage_report.format_lcov(coverage),
+            'SF:external/foo/bar.cpp\nDA:5,0\nLH:0\nLF:1\nend_of_record\n'
+            'SF:external/foo/foo.cpp\nDA:1,1\nDA:2,3\nLH:2\nLF:2\n'
+            'end_of_record\n')
+
+
+class JacocoTest(unittest.TestCase):
+    """Unit tests for parse_jacoco_xml."""
+
+    def test_parse_jacoco_xml(self):
+        xml = ('<report name="r">'
+               '<package name="com/android/foo">'
+               '<sourcefile name="Foo.java">'
+               '<line nr="3" mi="0" ci="2" mb="0" cb="0"/>'
+               '<line nr="4" mi="1" ci="0" mb="0" cb="0"/>'
+               '</sourcefile>'
+               '</package>'
+               '<package name="com/android/bar">'
+               '<sourcefile name="Bar.java">'
+               '<line nr="7" mi="0" ci="1" mb="0" cb="0"/>'
+               '</sourcefile>'
+               '</package>'
+               '</report>')
+        srcs = ['frameworks/foo/src/com/android/foo/Foo.java']
+        self.assertEqual(
+            coverage_report.parse_jacoco_xml(xml, srcs), {
+                'frameworks/foo/src/com/android/foo/Foo.java': {3: 2, 4: 0},
+                'com/android/bar/Bar.java': {7: 1},
+            })
+
+
+class RollupTest(unittest.TestCase):
+    """Unit tests for rollup and format_html."""
+
+    def test_rollup(self):
+        coverage = {
+            'external/foo/foo.cpp': {1: 1, 2: 0},
+            'external/foo/sub/bar.cpp': {1: 1},
+            'frameworks/baz/Baz.java': {1: 0},
+        }
+        dirs = coverage_report.rollup(coverage)
+        self.assertEqual(dirs['.'], {'lines_found': 4, 'lines_hit': 2})
+        self.assertEqual(dirs['external'], {'lines_found': 3, 'lines_hit': 2})
+        self.assertEqual(dirs['external/foo'], {
+            'lines_found': 3,
+            'lines_hit': 2
+        })
+        self.assertEqual(dirs['external/foo/sub'], {
+            'lines_found': 1,
+            'lines_hit': 1
+        })
+        self.assertEqual(dirs['frameworks/baz'], {
+            'lines_found': 1,
+            'lines_hit': 0
+        })
+
+        html = coverage_report.format_html(dirs)
+        self.assertIn(
+            '<tr><td>external/foo</td><td>2</td><td>3</td><td>66.7%</td></tr>',
+            html)
+
+
+if __name__ == '__main__':
+    unittest.main(verbosity=2)
@@ -0,0 +1,323 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Runs javac incrementally within a single module.
+
+The classes directory of the previous compilation is kept together with a state
+file that records the hash of each source file, the classes compiled from it
+and the classes referenced by each class. On the next run only the changed
+sources and the sources whose classes directly reference a class of a changed
+or removed source are recompiled, against the remaining classes of the module.
+
+With --stale_sources_out, the sources that an incremental compilation
+recompiled or removed are written to a file, so that soong_javac_wrapper can
+keep the diagnostics of the other sources from the previous compilation. The
+file is not written for a full compilation.
+
+Any change to the javac command line or to the classpath falls back to a full
+compilation. Changes that are not visible in the constant pool of the
+referencing class, e.g. an inlined compile time constant, are not tracked.
+"""
+
+import argparse
+import hashlib
+import json
+import os
+import re
+import shutil
+import struct
+import subprocess
+import sys
+
+from ninja_rsp import NinjaRspFileReader
+
+STATE_VERSION = 1
+
+_PACKAGE_RE = re.compile(r'^\s*package\s+([\w.]+)\s*;', re.MULTILINE)
+_DESCRIPTOR_CLASS_RE = re.compile(r'L([\w/$]+)[;<]')
+
+
+def parse_args(args):
+  """Parse commandline arguments."""
+  parser = argparse.ArgumentParser()
+  parser.add_argument('--out_dir', required=True,
+                      help='directory to write .class files to')
+  parser.add_argument('--state', required=True,
+                      help='file that keeps the state between compilations')
+  parser.add_argument('--srcs_rsp', action='append', default=[],
+                      help='ninja rsp file listing source files')
+  parser.add_argument('--srcs_list', action='append', default=[],
+                      help='file listing source files, one per line')
+  parser.add_argument('--stale_sources_out',
+                      help='file to write the recompiled and removed sources '
+                      'of an incremental compilation to')
+  parser.add_argument('--classpath', default='',
+                      help='colon separated classpath to compile against')
+  parser.add_argument('javac', nargs=argparse.REMAINDER,
+                      help='javac command line without sources, -d and -classpath')
+  args = parser.parse_args(args)
+  if args.javac and args.javac[0] == '--':
+    args.javac = args.javac[1:]
+  if not args.javac:
+    parser.error('missing javac command line')
+  return args
+
+
+def read_class_info(data):
+  """Returns the name, the source file and the referenced classes of a class file.
+
+  Class names are in the internal form, e.g. java/lang/Object. The source file
+  is None if the class file has no SourceFile attribute.
+  """
+  if data[:4] != b'\xca\xfe\xba\xbe':
+    raise ValueError('not a class file')
+  cp_count = struct.unpack_from('>H', data, 8)[0]
+  utf8 = {}
+  class_name_indices = {}
+  pos = 10
+  i = 1
+  while i < cp_count:
+    tag = data[pos]
+    pos += 1
+    if tag == 1:  # Utf8
+      length = struct.unpack_from('>H', data, pos)[0]
+      utf8[i] = data[pos + 2:pos + 2 + length].decode('utf-8', 'replace')
+      pos += 2 + length
+    elif tag == 7:  # Class
+      class_name_indices[i] = struct.unpack_from('>H', data, pos)[0]
+      pos += 2
+    elif tag in (8, 16, 19, 20):  # String, MethodType, Module, Package
+      pos += 2
+    elif tag == 15:  # MethodHandle
+      pos += 3
+    elif tag in (3, 4, 9, 10, 11, 12, 17, 18):
+      pos += 4
+    elif tag in (5, 6):  # Long and Double take two entries
+      pos += 8
+      i += 1
+    else:
+      raise ValueError('unknown constant pool tag %d' % tag)
+    i += 1
+
+  this_class = struct.unpack_from('>H', data, pos + 2)[0]
+  pos += 6
+  interfaces_count = struct.unpack_from('>H', data, pos)[0]
+  pos += 2 + 2 * interfaces_count
+
+  def skip_attributes(pos):
+    count = struct.unpack_from('>H', data, pos)[0]
+    pos += 2
+    for _ in range(count):
+      length = struct.unpack_from('>I', data, pos + 2)[0]
+      pos += 6 + length
+    return pos
+
+  for _ in range(2):  # fields and methods
+    count = struct.unpack_from('>H', data, pos)[0]
+    pos += 2
+    for _ in range(count):
+      pos = skip_attributes(pos + 6)
+
+  source_file = None
+  count = struct.unpack_from('>H', data, pos)[0]
+  pos += 2
+  for _ in range(count):
+    name_index, length = struct.unpack_from('>HI', data, pos)
+    if utf8.get(name_index) == 'SourceFile':
+      source_file = utf8[struct.unpack_from('>H', data, pos + 6)[0]]
+    pos += 6 + length
+
+  name = utf8[class_name_indices[this_class]]
+  refs = set()
+  for index in class_name_indices.values():
+    ref = utf8[index]
+    if ref.startswith('['):
+      refs.update(_DESCRIPTOR_CLASS_RE.findall(ref))
+    else:
+      refs.add(ref)
+  for value in utf8.values():
+    refs.update(_DESCRIPTOR_CLASS_RE.findall(value))
+  refs.discard(name)
+  return name, source_file, refs
+
+
+def source_key(path, contents):
+  """Returns the (package, file name) pair that class files use to refer to a source."""
+  match = _PACKAGE_RE.search(contents)
+  package = match.group(1).replace('.', '/') if match else ''
+  return package, os.path.basename(path)
+
+
+def compute_fingerprint(javac, classpath):
+  """Returns a fingerprint of everything other than the sources that affects the output."""
+  entries = []
+  for entry in classpath.split(':') if classpath else []:
+    try:
+      st = os.stat(entry)
+      entries.append([entry, st.st_size, st.st_mtime_ns])
+    except OSError:
+      entries.append([entry, None, None])
+  return hashlib.sha1(json.dumps([javac, entries]).encode()).hexdigest()
+
+
+def affected_sources(state, hashes):
+  """Returns the sources to recompile and the sources whose classes are stale.
+
+  The stale sources are the changed, removed and recompiled sources.
+  """
+  old_hashes = state['sources']
+  changed = {s for s, h in hashes.items() if old_hashes.get(s) != h}
+  removed = {s for s in old_hashes if s not in hashes}
+
+  dirty_classes = set()
+  for s in changed | removed:
+    dirty_classes.update(state['classes'].get(s, []))
+
+  dependents = set()
+  for s in hashes:
+    if s in changed:
+      continue
+    for c in state['classes'].get(s, []):
+      if dirty_classes.intersection(state['refs'].get(c, [])):
+        dependents.add(s)
+        break
+
+  to_compile = changed | dependents
+  return to_compile, to_compile | removed
+
+
+def list_class_files(out_dir):
+  for root, _, files in os.walk(out_dir):
+    for f in files:
+      if f.endswith('.class'):
+        yield os.path.join(root, f)
+
+
+def scan_classes(out_dir, keys):
+  """Returns the classes of each source and the references of each class.
+
+  Returns None if a class file can't be attributed to exactly one source.
+  """
+  by_key = {}
+  for src, key in keys.items():
+    by_key.setdefault(key, []).append(src)
+
+  classes = {}
+  refs = {}
+  for path in list_class_files(out_dir):
+    with open(path, 'rb') as f:
+      name, source_file, class_refs = read_class_info(f.read())
+    srcs = by_key.get((os.path.dirname(name), source_file), [])
+    if len(srcs) != 1:
+      return None
+    classes.setdefault(srcs[0], []).append(name)
+    refs[name] = sorted(class_refs)
+  return {s: sorted(c) for s, c in classes.items()}, refs
+
+
+def run_javac(javac, out_dir, classpath, srcs, rsp):
+  with open(rsp, 'w') as f:
+    f.write('\n'.join(srcs))
+  cmd = javac + ['-d', out_dir]
+  if classpath:
+    cmd += ['-classpath', classpath]
+  cmd.append('@' + rsp)
+  return subprocess.call(cmd)
+
+
+def main(argv):
+  args = parse_args(argv)
+
+  # A stale sources file of a previous compilation must not be used for this one.
+  if args.stale_sources_out and os.path.exists(args.stale_sources_out):
+    os.remove(args.stale_sources_out)
+
+  srcs = []
+  for rsp in args.srcs_rsp:
+    srcs.extend(NinjaRspFileReader(rsp))
+  for lst in args.srcs_list:
+    with open(lst) as f:
+      srcs.extend(line.strip() for line in f if line.strip())
+  srcs = sorted(set(srcs))
+
+  hashes = {}
+  keys = {}
+  for src in srcs:
+    with open(src, 'rb') as f:
+      contents = f.read()
+    hashes[src] = hashlib.sha1(contents).hexdigest()
+    keys[src] = source_key(src, contents.decode('utf-8', 'replace'))
+
+  fingerprint = compute_fingerprint(args.javac, args.classpath)
+
+  state = None
+  if os.path.isdir(args.out_dir):
+    try:
+      with open(args.state) as f:
+        state = json.load(f)
+    except (OSError, ValueError):
+      pass
+  if state and (state.get('version') != STATE_VERSION or
+                state.get('fingerprint') != fingerprint):
+    state = None
+
+  # The state is only valid once the compilation below has succeeded.
+  if os.path.exists(args.state):
+    os.remove(args.state)
+
+  rsp = args.state + '.rsp'
+  if state is None:
+    shutil.rmtree(args.out_dir, ignore_errors=True)
+    os.makedirs(args.out_dir)
+    to_compile = srcs
+    classpath = args.classpath
+  else:
+    to_compile, stale = affected_sources(state, hashes)
+    for s in stale:
+      for c in state['classes'].get(s, []):
+        path = os.path.join(args.out_dir, c + '.class')
+        if os.path.exists(path):
+          os.remove(path)
+    to_compile = sorted(to_compile)
+    classpath = ':'.join(filter(None, [args.out_dir, args.classpath]))
+    if args.stale_sources_out:
+      with open(args.stale_sources_out, 'w') as f:
+        json.dump({'stale': sorted(stale), 'compiled': bool(to_compile)}, f)
+
+  if to_compile:
+    ret = run_javac(args.javac, args.out_dir, classpath, to_compile, rsp)
+    if ret != 0:
+      return ret
+
+  scanned = scan_classes(args.out_dir, keys)
+  if scanned is None:
+    # Without a reliable mapping from classes to sources the next compilation
+    # has to be a full one.
+    return 0
+  classes, refs = scanned
+  with open(args.state, 'w') as f:
+    json.dump({
+        'version': STATE_VERSION,
+        'fingerprint': fingerprint,
+        'sources': hashes,
+        'classes': classes,
+        'refs': refs,
+    }, f)
+  return 0
+
+
+if __name__ == '__main__':
+  sys.exit(main(sys.argv[1:]))
@@ -0,0 +1,128 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for incremental_javac.py."""
+
+import struct
+import unittest
+
+import incremental_javac
+
+
+def make_class_file(name, source_file, refs, descriptors=()):
+  """Returns the bytes of a minimal class file."""
+  pool = []
+
+  def utf8(value):
+    encoded = value.encode()
+    pool.append(struct.pack('>BH', 1, len(encoded)) + encoded)
+    return len(pool)
+
+  def clazz(value):
+    index = utf8(value)
+    pool.append(struct.pack('>BH', 7, index))
+    return len(pool)
+
+  this_class = clazz(name)
+  for ref in refs:
+    clazz(ref)
+  for descriptor in descriptors:
+    utf8(descriptor)
+  # A Long constant takes two entries of the constant pool.
+  pool.append(struct.pack('>BQ', 5, 42))
+  pool.append(b'')
+  source_file_attr = utf8('SourceFile')
+  source_file_index = utf8(source_file)
+
+  data = b'\xca\xfe\xba\xbe' + struct.pack('>HHH', 0, 52, len(pool) + 1)
+  data += b''.join(pool)
+  data += struct.pack('>HHHH', 0x21, this_class, 0, 0)  # flags, this, super, interfaces
+  data += struct.pack('>HH', 0, 0)  # fields, methods
+  data += struct.pack('>HHIH', 1, source_file_attr, 2, source_file_index)
+  return data
+
+
+class ReadClassInfoTest(unittest.TestCase):
+
+  def test_read_class_info(self):
+    data = make_class_file('a/b/Foo', 'Foo.java', ['a/b/Bar', '[La/c/Baz;'],
+                           ['(La/d/Qux;)V'])
+    name, source_file, refs = incremental_javac.read_class_info(data)
+    self.assertEqual(name, 'a/b/Foo')
+    self.assertEqual(source_file, 'Foo.java')
+    self.assertEqual(refs, {'a/b/Bar', 'a/c/Baz', 'a/d/Qux'})
+
+  def test_not_a_class_file(self):
+    with self.assertRaises(ValueError):
+      incremental_javac.read_class_info(b'PK\x03\x04')
+
+
+class SourceKeyTest(unittest.TestCase):
+
+  def test_source_key(self):
+    self.assertEqual(
+        incremental_javac.source_key('src/x/Foo.java', '// c\npackage a.b;\nclass Foo {}'),
+        ('a/b', 'Foo.java'))
+    self.assertEqual(
+        incremental_javac.source_key('Foo.java', 'class Foo {}'), ('', 'Foo.java'))
+
+
+class AffectedSourcesTest(unittest.TestCase):
+
+  state = {
+      'sources': {'A.java': '1', 'B.java': '2', 'C.java': '3', 'D.java': '4'},
+      'classes': {
+          'A.java': ['A', 'A$Inner'],
+          'B.java': ['B'],
+          'C.java': ['C'],
+          'D.java': ['D'],
+      },
+      'refs': {
+          'A': ['java/lang/Object'],
+          'A$Inner': [],
+          'B': ['A$Inner'],
+          'C': ['B'],
+          'D': ['C'],
+      },
+  }
+
+  def test_unchanged(self):
+    to_compile, stale = incremental_javac.affected_sources(
+        self.state, {'A.java': '1', 'B.java': '2', 'C.java': '3', 'D.java': '4'})
+    self.assertEqual(to_compile, set())
+    self.assertEqual(stale, set())
+
+  def test_changed_recompiles_direct_dependents(self):
+    to_compile, stale = incremental_javac.affected_sources(
+        self.state, {'A.java': 'x', 'B.java': '2', 'C.java': '3', 'D.java': '4'})
+    self.assertEqual(to_compile, {'A.java', 'B.java'})
+    self.assertEqual(stale, {'A.java', 'B.java'})
+
+  def test_removed(self):
+    to_compile, stale = incremental_javac.affected_sources(
+        self.state, {'A.java': '1', 'B.java': '2', 'D.java': '4'})
+    self.assertEqual(to_compile, {'D.java'})
+    self.assertEqual(stale, {'C.java', 'D.java'})
+
+  def test_added(self):
+    to_compile, stale = incremental_javac.affected_sources(
+        self.state, {'A.java': '1', 'B.java': '2', 'C.java': '3', 'D.java': '4', 'E.java': '5'})
+    self.assertEqual(to_compile, {'E.java'})
+    self.assertEqual(stale, {'E.java'})
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
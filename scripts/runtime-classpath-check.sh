@@ -0,0 +1,99 @@
+#!/bin/bash
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -e
+
+ignore_resource_classes=false
+if [[ "$1" == "--ignore-resource-classes" ]]; then
+  ignore_resource_classes=true
+  shift
+fi
+
+if [[ $# -lt 1 ]]; then
+  cat <<EOF
+Usage:
+  runtime-classpath-check.sh [--ignore-resource-classes] <classpath-manifest> [allowed-class...]
+Checks that no class file is provided by more than one of the jar files listed
+in <classpath-manifest>, one per line. An allowed class is the fully qualified
+name of a class, which also allows its nested classes, or a package followed by
+".*", which also allows its sub-packages. --ignore-resource-classes skips the R
+and Manifest classes that aapt2 generates into every Android library.
+EOF
+  exit 1
+fi
+
+manifest=$1
+shift
+if [[ ! -f ${manifest} ]]; then
+  echo "classpath manifest \"${manifest}\" does not exist."
+  exit 1
+fi
+
+for class in "$@"; do
+  if [[ "${class}" = */* ]]; then
+    echo "Invalid class \"${class}\". Use dot notation for classes and packages."
+    exit 1
+  fi
+done
+
+# List "<class> <jar>" for the class files of each jar, in classpath order.
+list_classes() {
+  local jar
+  while read -r jar; do
+    if [[ -z "${jar}" ]]; then
+      continue
+    fi
+    zipinfo -1 "${jar}" 2>/dev/null | grep '\.class$' | grep -v -e '^META-INF/' \
+      -e '\(^\|/\)module-info\.class$' -e '\(^\|/\)package-info\.class$' |
+      sed -e 's|\.class$||' -e 's|/|.|g' -e "s|\$| ${jar}|" || true
+  done < "${manifest}"
+}
+
+list_classes | awk -v allowed="$*" -v ignore_resource_classes="${ignore_resource_classes}" '
+function is_allowed(class,    i, pattern) {
+  if (ignore_resource_classes == "true" && class ~ /(^|\.)(R|Manifest)(\$[^.]*)?$/) {
+    return 1
+  }
+  for (i = 1; i <= num_allowed; i++) {
+    pattern = allowed_classes[i]
+    if (pattern ~ /\.\*$/) {
+      if (index(class, substr(pattern, 1, length(pattern) - 1)) == 1) {
+        return 1
+      }
+    } else if (class == pattern || index(class, pattern "$") == 1) {
+      return 1
+    }
+  }
+  return 0
+}
+BEGIN {
+  num_allowed = split(allowed, allowed_classes, " ")
+}
+{
+  if (!($1 in provided_by)) {
+    provided_by[$1] = $2
+  } else if (provided_by[$1] != $2 && !is_allowed($1)) {
+    print "Class " $1 " is provided by both " provided_by[$1] " and " $2 "."
+    failed = 1
+  }
+}
+END {
+  if (failed) {
+    print "Only the first of the jars provides the class at runtime. Remove the duplicate from one of the"
+    print "dependencies, or add the class to runtime_classpath.allowed_duplicate_classes."
+    exit 1
+  }
+}'
@@ -0,0 +1,89 @@
+#!/bin/bash
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -e
+
+usage() {
+  cat <<EOF
+Usage:
+  size-baseline-check.sh [--update] [--warn] [--max-increase-bytes <bytes>]
+      [--max-increase-percent <percent>] <artifact> <baseline> <stamp>
+Checks that the size of <artifact> doesn't exceed the size in bytes in the
+<baseline> file by more than the allowed increase, and touches <stamp>. With
+--warn, an artifact that is too large is only reported. With --update, the
+size of <artifact> is written to <baseline> instead.
+EOF
+  exit 1
+}
+
+update=
+warn=
+max_increase_bytes=0
+max_increase_percent=
+while [[ $# -gt 3 ]]; do
+  case "$1" in
+    --update) update=true; shift ;;
+    --warn) warn=true; shift ;;
+    --max-increase-bytes) max_increase_bytes=$2; shift 2 ;;
+    --max-increase-percent) max_increase_percent=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+if [[ $# -ne 3 ]]; then
+  usage
+fi
+
+artifact=$1
+baseline=$2
+stamp=$3
+
+size=$(stat -L -c %s "${artifact}")
+
+if [[ -n "${update}" ]]; then
+  if [[ ! -f "${baseline}" ]] || [[ "$(grep -v '^#' "${baseline}" | head -n 1)" != "${size}" ]]; then
+    {
+      echo "# Size in bytes of $(basename "${artifact}"), update with UPDATE_SIZE_BASELINES=true."
+      echo "${size}"
+    } > "${baseline}"
+    echo "Updated ${baseline} to ${size} bytes" >&2
+  fi
+  touch "${stamp}"
+  exit 0
+fi
+
+expected=$(grep -v '^#' "${baseline}" | head -n 1 | tr -d '[:space:]')
+if ! [[ "${expected}" =~ ^[0-9]+$ ]]; then
+  echo "${baseline}: expected a size in bytes, got \"${expected}\"" >&2
+  exit 1
+fi
+
+allowed=${max_increase_bytes}
+if [[ -n "${max_increase_percent}" ]]; then
+  allowed=$(( expected * max_increase_percent / 100 ))
+fi
+
+if (( size > expected + allowed )); then
+  msg="${artifact} is ${size} bytes, $(( size - expected )) bytes larger than the baseline of ${expected} bytes in ${baseline}, which allows an increase of ${allowed} bytes."
+  if [[ -n "${warn}" ]]; then
+    echo "warning: ${msg}" >&2
+  else
+    echo "error: ${msg}" >&2
+    echo "If the increase is expected, update the baseline by building with UPDATE_SIZE_BASELINES=true." >&2
+    exit 1
+  fi
+fi
+
+touch "${stamp}"
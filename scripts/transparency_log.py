@@ -0,0 +1,113 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Writes the binary transparency log entries of the signed artifacts of a build.
+
+Soong writes a manifest of the signed APKs and APEXes that the build installs,
+with the certificates, signing certificate lineage and APEX payload key they
+are signed with. The entries of the log identify each artifact by its path on
+the device and the SHA-256 digest of its content, and its signers by the
+SHA-256 digests of the DER encoded certificates and of the lineage and payload
+key files.
+"""
+
+import argparse
+import hashlib
+import json
+import sys
+
+import check_signing_lineage
+
+SCHEMA_VERSION = 1
+
+
+def parse_args(args):
+  """Parse commandline arguments."""
+  parser = argparse.ArgumentParser()
+  parser.add_argument('--manifest', required=True,
+                      help='JSON manifest of the signed artifacts')
+  parser.add_argument('--output', required=True,
+                      help='file to write the transparency log to')
+  return parser.parse_args(args)
+
+
+def file_digest(path):
+  """Returns the SHA-256 digest and the size of a file."""
+  digest = hashlib.sha256()
+  size = 0
+  with open(path, 'rb') as f:
+    for chunk in iter(lambda: f.read(1 << 20), b''):
+      digest.update(chunk)
+      size += len(chunk)
+  return digest.hexdigest(), size
+
+
+def log_entry(artifact):
+  """Returns the transparency log entry of a signed artifact of the manifest."""
+  digest, size = file_digest(artifact['file'])
+  certificates = []
+  for certificate in artifact.get('certificates') or []:
+    with open(certificate) as f:
+      certificates.append(check_signing_lineage.certificate_digest(f.read()))
+  signing = {
+      'certificate_sha256': certificates,
+      'presigned': artifact.get('presigned', False),
+  }
+  if artifact.get('lineage'):
+    signing['lineage_sha256'] = file_digest(artifact['lineage'])[0]
+  entry = {
+      'path': artifact['path'],
+      'kind': artifact['kind'],
+      'module': artifact['module'],
+      'module_dir': artifact['module_dir'],
+      'sha256': digest,
+      'size': size,
+      'signing': signing,
+  }
+  if artifact.get('payload_public_key'):
+    entry['apex_payload_key_sha256'] = file_digest(
+        artifact['payload_public_key'])[0]
+  return entry
+
+
+def transparency_log(manifest):
+  """Returns the transparency log of the signed artifacts of the manifest."""
+  entries = [log_entry(artifact) for artifact in manifest['entries']]
+  entries.sort(key=lambda entry: entry['path'])
+  return {
+      'schema_version': SCHEMA_VERSION,
+      'product': manifest['product'],
+      'build_id': manifest['build_id'],
+      'entries': entries,
+  }
+
+
+def main():
+  args = parse_args(sys.argv[1:])
+  try:
+    with open(args.manifest) as f:
+      manifest = json.load(f)
+    log = transparency_log(manifest)
+  except (OSError, ValueError, KeyError) as err:
+    print('error: %s' % err, file=sys.stderr)
+    sys.exit(1)
+  with open(args.output, 'w') as f:
+    json.dump(log, f, indent=2, sort_keys=True)
+    f.write('\n')
+
+
+if __name__ == '__main__':
+  main()
@@ -0,0 +1,105 @@
+#!/usr/bin/env python3
+#
+# Copyright (C) 2026 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for transparency_log.py."""
+
+import base64
+import hashlib
+import os
+import tempfile
+import unittest
+
+import transparency_log
+
+
+class TransparencyLogTest(unittest.TestCase):
+
+  def setUp(self):
+    self.dir = tempfile.TemporaryDirectory()
+    self.addCleanup(self.dir.cleanup)
+
+  def write(self, name, content):
+    path = os.path.join(self.dir.name, name)
+    with open(path, 'wb') as f:
+      f.write(content)
+    return path
+
+  def test_transparency_log(self):
+    der = b'\x30\x82\x01\x0a not really a certificate'
+    pem = self.write('cert.x509.pem', b'-----BEGIN CERTIFICATE-----\n' +
+                     base64.b64encode(der) + b'\n-----END CERTIFICATE-----\n')
+    manifest = {
+        'product': 'coral',
+        'build_id': 'ABC',
+        'entries': [
+            {
+                'kind': 'apk',
+                'module': 'Foo',
+                'module_dir': 'packages/apps/Foo',
+                'path': '/system/app/Foo/Foo.apk',
+                'file': self.write('Foo.apk', b'apk'),
+                'certificates': [pem],
+                'lineage': self.write('lineage', b'lineage'),
+            },
+            {
+                'kind': 'apex',
+                'module': 'com.android.foo',
+                'module_dir': 'packages/modules/Foo',
+                'path': '/system/apex/com.android.foo.apex',
+                'file': self.write('com.android.foo.apex', b'apex'),
+                'certificates': [pem],
+                'payload_public_key': self.write('foo.avbpubkey', b'key'),
+            },
+            {
+                'kind': 'apk',
+                'module': 'Bar',
+                'module_dir': 'vendor/Bar',
+                'path': '/product/app/Bar/Bar.apk',
+                'file': self.write('Bar.apk', b'bar'),
+                'certificates': [],
+                'presigned': True,
+            },
+        ],
+    }
+
+    log = transparency_log.transparency_log(manifest)
+
+    self.assertEqual(log['schema_version'], 1)
+    self.assertEqual(log['product'], 'coral')
+    self.assertEqual([e['path'] for e in log['entries']], [
+        '/product/app/Bar/Bar.apk',
+        '/system/apex/com.android.foo.apex',
+        '/system/app/Foo/Foo.apk',
+    ])
+    bar, apex, foo = log['entries']
+    self.assertEqual(foo['sha256'], hashlib.sha256(b'apk').hexdigest())
+    self.assertEqual(foo['size'], 3)
+    self.assertEqual(foo['signing'], {
+        'certificate_sha256': [hashlib.sha256(der).hexdigest()],
+        'lineage_sha256': hashlib.sha256(b'lineage').hexdigest(),
+        'presigned': False,
+    })
+    self.assertEqual(apex['apex_payload_key_sha256'],
+                     hashlib.sha256(b'key').hexdigest())
+    self.assertNotIn('apex_payload_key_sha256', foo)
+    self.assertEqual(bar['signing'], {
+        'certificate_sha256': [],
+        'presigned': True,
+    })
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
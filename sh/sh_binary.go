@@ -49,6 +49,7 @@ func registerShBuildComponents(ctx android.RegistrationContext) {
 	ctx.RegisterModuleType("sh_binary_host", ShBinaryHostFactory)
 	ctx.RegisterModuleType("sh_test", ShTestFactory)
 	ctx.RegisterModuleType("sh_test_host", ShTestHostFactory)
+	ctx.RegisterSingletonType("shellcheck_report", shellcheckReportSingletonFactory)
 }
 
 // Test fixture preparer that will register most sh build components.
@@ -154,11 +155,15 @@ type ShBinary struct {
 	android.ModuleBase
 	android.BazelModuleBase
 
-	properties shBinaryProperties
+	properties           shBinaryProperties
+	shellcheckProperties shellcheckProperties
 
 	sourceFilePath android.Path
 	outputFilePath android.OutputPath
 	installedFile  android.InstallPath
+
+	// shellcheck findings of the script, or nil if it is not checked
+	shellcheckFindings android.Path
 }
 
 var _ android.HostToolProvider = (*ShBinary)(nil)
@@ -256,9 +261,10 @@ func (s *ShBinary) generateAndroidBuildActions(ctx android.ModuleContext) {
 	// This ensures that outputFilePath has the correct name for others to
 	// use, as the source file may have a different name.
 	ctx.Build(pctx, android.BuildParams{
-		Rule:   android.CpExecutable,
-		Output: s.outputFilePath,
-		Input:  s.sourceFilePath,
+		Rule:       android.CpExecutable,
+		Output:     s.outputFilePath,
+		Input:      s.sourceFilePath,
+		Validation: s.shellcheck(ctx),
 	})
 }
 
@@ -432,6 +438,12 @@ func (s *ShTest) InstallInData() bool {
 	return true
 }
 
+func (s *ShTest) TestSuites() []string {
+	return s.testProperties.Test_suites
+}
+
+var _ android.TestSuiteModule = (*ShTest)(nil)
+
 func (s *ShTest) AndroidMkEntries() []android.AndroidMkEntries {
 	return []android.AndroidMkEntries{android.AndroidMkEntries{
 		Class:      "NATIVE_TESTS",
@@ -475,7 +487,7 @@ func (s *ShTest) AndroidMkEntries() []android.AndroidMkEntries {
 }
 
 func InitShBinaryModule(s *ShBinary) {
-	s.AddProperties(&s.properties)
+	s.AddProperties(&s.properties, &s.shellcheckProperties)
 	android.InitBazelModule(s)
 }
 
@@ -0,0 +1,158 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sh
+
+// This file runs shellcheck on the scripts of sh_binary and sh_test modules. Modules opt in with
+// shellcheck.enabled, or a product enables shellcheck for the whole tree with ShellcheckEnabled,
+// except in the ShellcheckExcludeDirs directories. The findings of a module are written to a file
+// that is collected into a tree-wide report, and a validation of the module's output fails the
+// build if there are any.
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/blueprint/proptools"
+
+	"android/soong/android"
+)
+
+type shellcheckProperties struct {
+	Shellcheck struct {
+		// If true, check the script with shellcheck. Defaults to true if the product enables
+		// shellcheck and the module is not in a directory excluded from it.
+		Enabled *bool
+
+		// The minimum severity of the findings that fail the build: "error", "warning", "info" or
+		// "style". Defaults to "warning".
+		Severity *string
+
+		// shellcheck checks to ignore, e.g. "SC2034".
+		Exclude []string
+	}
+}
+
+var shellcheckSeverities = []string{"error", "warning", "info", "style"}
+
+var shellcheckCodePattern = regexp.MustCompile(`^SC[0-9]+$`)
+
+// shellcheckEnabled returns whether the script of the module is checked with shellcheck.
+func (s *ShBinary) shellcheckEnabled(ctx android.ModuleContext) bool {
+	props := &s.shellcheckProperties.Shellcheck
+	if props.Enabled != nil {
+		return *props.Enabled
+	}
+	return ctx.Config().ShellcheckEnabledForDir(ctx.ModuleDir())
+}
+
+// shellcheck returns a file created by a rule that fails if shellcheck finds problems in the
+// script of the module, or nil if the script is not checked.
+func (s *ShBinary) shellcheck(ctx android.ModuleContext) android.Path {
+	if !s.shellcheckEnabled(ctx) {
+		return nil
+	}
+	// sh_binary is also used for batch files and other interpreted scripts.
+	if ext := filepath.Ext(s.sourceFilePath.Base()); ext != "" && ext != ".sh" && ext != ".bash" {
+		return nil
+	}
+
+	props := &s.shellcheckProperties.Shellcheck
+	severity := proptools.StringDefault(props.Severity, "warning")
+	if !android.InList(severity, shellcheckSeverities) {
+		ctx.PropertyErrorf("shellcheck.severity", "must be one of %q, got %q", shellcheckSeverities, severity)
+	}
+	for _, code := range props.Exclude {
+		if !shellcheckCodePattern.MatchString(code) {
+			ctx.PropertyErrorf("shellcheck.exclude", "invalid check %q, expected e.g. \"SC2034\"", code)
+		}
+	}
+
+	// shellcheck exits with 1 when it finds problems, which are collected into the report
+	// before failing the build.
+	findings := android.PathForModuleOut(ctx, "shellcheck", "findings.txt")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	cmd := rule.Command().
+		PrebuiltBuildTool(ctx, "shellcheck").
+		Flag("--format=gcc").
+		FlagWithArg("--severity=", severity)
+	if len(props.Exclude) > 0 {
+		cmd.FlagWithArg("--exclude=", strings.Join(props.Exclude, ","))
+	}
+	cmd.Input(s.sourceFilePath).
+		FlagWithOutput("> ", findings).
+		Text("|| [ $? -eq 1 ]")
+	rule.Build("shellcheck", "shellcheck "+s.sourceFilePath.Rel())
+	s.shellcheckFindings = findings
+
+	checkFile := android.PathForModuleOut(ctx, "shellcheck", "check.stamp")
+	msg := "shellcheck found problems in " + s.sourceFilePath.String() +
+		", fix them or ignore the checks with shellcheck.exclude"
+	rule = android.NewRuleBuilder(pctx, ctx)
+	rule.Command().
+		Text("if [ -s").Input(findings).Text("]; then").
+		Text("cat").Input(findings).Text(">&2;").
+		Text("echo").Text(proptools.ShellEscape(msg)).Text(">&2;").
+		Text("exit 1;").
+		Text("fi")
+	rule.Command().Text("touch").Output(checkFile)
+	rule.Build("shellcheck_check", "check shellcheck findings "+s.sourceFilePath.Rel())
+
+	return checkFile
+}
+
+type shellcheckedModule interface {
+	shellcheckFindingsFile() android.Path
+}
+
+func (s *ShBinary) shellcheckFindingsFile() android.Path {
+	return s.shellcheckFindings
+}
+
+func shellcheckReportSingletonFactory() android.Singleton {
+	return &shellcheckReportSingleton{}
+}
+
+type shellcheckReportSingleton struct {
+	report android.WritablePath
+}
+
+// GenerateBuildActions collects the shellcheck findings of all modules into a report, which can
+// be built with the shellcheck-report goal without failing on the findings.
+func (s *shellcheckReportSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	var findings android.Paths
+	ctx.VisitAllModules(func(module android.Module) {
+		if sh, ok := module.(shellcheckedModule); ok && module.Enabled() && sh.shellcheckFindingsFile() != nil {
+			findings = append(findings, sh.shellcheckFindingsFile())
+		}
+	})
+	if len(findings) == 0 {
+		return
+	}
+
+	s.report = android.PathForOutput(ctx, "shellcheck-report.txt")
+	rule := android.NewRuleBuilder(pctx, ctx)
+	rule.Command().Text("cat").Inputs(android.SortedUniquePaths(findings)).FlagWithOutput("> ", s.report)
+	rule.Build("shellcheck_report", "shellcheck report")
+
+	ctx.Phony("shellcheck-report", s.report)
+}
+
+func (s *shellcheckReportSingleton) MakeVars(ctx android.MakeVarsContext) {
+	if s.report == nil {
+		return
+	}
+	ctx.DistForGoal("shellcheck-report", s.report)
+}
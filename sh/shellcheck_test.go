@@ -0,0 +1,127 @@
+package sh
+
+import (
+	"testing"
+
+	"github.com/google/blueprint/proptools"
+
+	"android/soong/android"
+)
+
+func TestShellcheck(t *testing.T) {
+	result := prepareForShTest.RunTestWithBp(t, `
+		sh_binary {
+			name: "foo",
+			src: "test.sh",
+			shellcheck: {
+				enabled: true,
+				severity: "error",
+				exclude: ["SC2034", "SC2086"],
+			},
+		}
+	`)
+
+	foo := result.ModuleForTests("foo", "android_arm64_armv8-a")
+
+	shellcheck := foo.Rule("shellcheck")
+	android.AssertStringDoesContain(t, "shellcheck command", shellcheck.RuleParams.Command,
+		"--format=gcc --severity=error --exclude=SC2034,SC2086 test.sh > out/soong/.intermediates/foo/android_arm64_armv8-a/shellcheck/findings.txt")
+
+	check := foo.Rule("shellcheck_check")
+	android.AssertPathsRelativeToTopEquals(t, "check inputs",
+		[]string{"out/soong/.intermediates/foo/android_arm64_armv8-a/shellcheck/findings.txt"}, check.Implicits)
+
+	android.AssertPathRelativeToTopEquals(t, "validation",
+		"out/soong/.intermediates/foo/android_arm64_armv8-a/shellcheck/check.stamp", foo.Output("test.sh").Validation)
+
+	report := result.SingletonForTests("shellcheck_report").Rule("shellcheck_report")
+	android.AssertPathsRelativeToTopEquals(t, "report inputs",
+		[]string{"out/soong/.intermediates/foo/android_arm64_armv8-a/shellcheck/findings.txt"}, report.Implicits)
+	android.AssertPathRelativeToTopEquals(t, "report", "out/soong/shellcheck-report.txt", report.Output)
+}
+
+func TestShellcheckProductDefault(t *testing.T) {
+	result := android.GroupFixturePreparers(
+		prepareForShTest,
+		android.FixtureModifyProductVariables(func(variables android.FixtureProductVariables) {
+			variables.ShellcheckEnabled = proptools.BoolPtr(true)
+			variables.ShellcheckExcludeDirs = []string{"vendor/"}
+		}),
+		android.FixtureAddTextFile("vendor/foo/Android.bp", `
+			sh_binary {
+				name: "vendor_script",
+				src: "script.sh",
+			}
+		`),
+		android.FixtureAddFile("vendor/foo/script.sh", nil),
+		android.FixtureAddFile("testdata/script.bat", nil),
+	).RunTestWithBp(t, `
+		sh_binary {
+			name: "checked",
+			src: "test.sh",
+		}
+
+		sh_binary {
+			name: "opted_out",
+			src: "test.sh",
+			shellcheck: {
+				enabled: false,
+			},
+		}
+
+		sh_binary {
+			name: "batch",
+			src: "testdata/script.bat",
+		}
+	`)
+
+	for _, test := range []struct {
+		name    string
+		checked bool
+	}{
+		{name: "checked", checked: true},
+		{name: "opted_out", checked: false},
+		{name: "batch", checked: false},
+		{name: "vendor_script", checked: false},
+	} {
+		module := result.ModuleForTests(test.name, "android_arm64_armv8-a")
+		checked := module.MaybeRule("shellcheck").Rule != nil
+		android.AssertBoolEquals(t, test.name+" checked", test.checked, checked)
+	}
+}
+
+func TestShellcheckErrors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		shellcheck string
+		err        string
+	}{
+		{
+			name:       "severity",
+			shellcheck: `severity: "fatal"`,
+			err:        `shellcheck.severity: must be one of \["error" "warning" "info" "style"\], got "fatal"`,
+		},
+		{
+			name:       "exclude",
+			shellcheck: `exclude: ["2034"]`,
+			err:        `shellcheck.exclude: invalid check "2034", expected e.g. "SC2034"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			prepareForShTest.
+				ExtendWithErrorHandler(android.FixtureExpectsAtLeastOneErrorMatchingPattern(test.err)).
+				RunTestWithBp(t, `
+					sh_binary {
+						name: "foo",
+						src: "test.sh",
+						shellcheck: {
+							enabled: true,
+							`+test.shellcheck+`,
+						},
+					}
+				`)
+		})
+	}
+}
@@ -98,6 +98,7 @@ func (g *syspropJavaGenRule) GenerateAndroidBuildActions(ctx android.ModuleConte
 		})
 
 		g.genSrcjars = append(g.genSrcjars, srcJarFile)
+		android.RecordGeneratedSources(ctx, "sysprop", android.Paths{srcJarFile}, android.Paths{syspropFile})
 	}
 }
 
@@ -0,0 +1,214 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint/pathtools"
+)
+
+// The build is bootstrapped in two stages: the first parses every Android.bp file of the tree with
+// blueprint to write bootstrap.ninja, which builds soong_build, and the second runs that ninja file,
+// which rebuilds soong_build if needed and runs it. When only Android.bp files that don't define Go
+// packages change, the first stage writes the same bootstrap.ninja again. With
+// SOONG_REUSE_BOOTSTRAP=true the first stage is skipped when its inputs are the same as when
+// bootstrap.ninja was last written, as recorded by a digest in bootstrap.reuse.json:
+//   - the soong_build invocations,
+//   - the list of Android.bp files,
+//   - the contents of every Android.bp file that defines a Go module type, which covers Go
+//     packages added to or removed from any Android.bp file,
+//   - the Go files in the directories of the Go packages,
+//   - and the results of the globs of the bootstrap, which cover Go files added in
+//     subdirectories.
+// Every Android.bp file is read to find the ones that define Go module types, which is still much
+// cheaper than parsing them.
+
+// bootstrapReuseFile is the name of the file in the Soong state directory that records what the
+// last bootstrap.ninja was written from.
+const bootstrapReuseFile = "bootstrap.reuse.json"
+
+type bootstrapReuseState struct {
+	// The digest of the soong_build invocations, of the list of Android.bp files and of the files in
+	// Dirs.
+	Digest string
+
+	// The directories of the Go packages and binaries of the last bootstrap.
+	Dirs []string
+
+	// The globs of the last bootstrap.
+	Globs []bootstrapGlob
+}
+
+// bootstrapGlob is a glob of the bootstrap, whose results are part of the digest.
+type bootstrapGlob struct {
+	Pattern  string
+	Excludes []string
+}
+
+// goModuleTypes matches the definition of a module of the Go module types of the bootstrap.
+var goModuleTypes = regexp.MustCompile(`\b(bootstrap_go_package|bootstrap_go_binary|blueprint_go_binary)\s*\{`)
+
+// reuseBootstrap returns whether the first stage of the bootstrap may be skipped when its inputs
+// didn't change.
+func reuseBootstrap(config Config) bool {
+	return config.Environment().IsEnvTrue("SOONG_REUSE_BOOTSTRAP")
+}
+
+// bootstrapDigest returns the digest of key, which describes the soong_build invocations, of the
+// list of the Android.bp files of the tree, of the Android.bp files that define Go module types, of
+// the Android.bp and Go files in dirs, relative to the root of the source tree, and of the results
+// of globs.
+func bootstrapDigest(key, androidBpList string, dirs []string, globs []bootstrapGlob) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, key)
+	list, err := ioutil.ReadFile(androidBpList)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(list)
+	io.WriteString(h, "\x00list\x00"+hex.EncodeToString(sum[:]))
+
+	scanner := bufio.NewScanner(bytes.NewReader(list))
+	for scanner.Scan() {
+		androidBp := scanner.Text()
+		if androidBp == "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(androidBp)
+		if os.IsNotExist(err) {
+			// The list is out of date, the bootstrap reports missing files.
+			io.WriteString(h, "\x00missing\x00"+androidBp)
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		if goModuleTypes.Match(data) {
+			sum := sha256.Sum256(data)
+			io.WriteString(h, "\x00go\x00"+androidBp+"\x00"+hex.EncodeToString(sum[:]))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	for _, glob := range globs {
+		result, err := pathtools.Glob(glob.Pattern, glob.Excludes, pathtools.FollowSymlinks)
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(h, "\x00glob\x00"+glob.Pattern+"\x00"+strings.Join(glob.Excludes, "\x00"))
+		for _, match := range result.Matches {
+			io.WriteString(h, "\x00match\x00"+match)
+		}
+	}
+
+	for _, dir := range dirs {
+		io.WriteString(h, "\x00dir\x00"+dir)
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			io.WriteString(h, "\x00missing")
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.Mode().IsRegular() || (name != "Android.bp" && !strings.HasSuffix(name, ".go")) {
+				continue
+			}
+			data, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return "", err
+			}
+			sum := sha256.Sum256(data)
+			io.WriteString(h, "\x00file\x00"+name+"\x00"+hex.EncodeToString(sum[:]))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// canReuseBootstrap returns whether bootstrap.ninja was written from the same soong_build
+// invocations and Go packages as the current ones, and so doesn't need to be written again.
+func canReuseBootstrap(ctx Context, config Config, key string) bool {
+	if _, err := os.Stat(config.BootstrapNinjaFile()); err != nil {
+		return false
+	}
+	data, err := ioutil.ReadFile(filepath.Join(config.SoongStateDir(), bootstrapReuseFile))
+	if err != nil {
+		return false
+	}
+	var state bootstrapReuseState
+	if err := json.Unmarshal(data, &state); err != nil {
+		ctx.Verbosef("ignoring invalid %s: %s", bootstrapReuseFile, err)
+		return false
+	}
+	digest, err := bootstrapDigest(key, androidBpListFile(config), state.Dirs, state.Globs)
+	if err != nil {
+		ctx.Verbosef("not reusing bootstrap.ninja: %s", err)
+		return false
+	}
+	return digest == state.Digest
+}
+
+// recordBootstrap records the digest of the inputs of the bootstrap.ninja that was just written,
+// with dirs the directories of its Go packages and binaries and globs the globs it ran.
+func recordBootstrap(ctx Context, config Config, key string, dirs []string, globs []bootstrapGlob) {
+	reuseFile := filepath.Join(config.SoongStateDir(), bootstrapReuseFile)
+	dirs = sortedUniqueDirs(dirs)
+	digest, err := bootstrapDigest(key, androidBpListFile(config), dirs, globs)
+	if err != nil {
+		// The next build bootstraps again.
+		ctx.Verbosef("not recording the bootstrap digest: %s", err)
+		os.Remove(reuseFile)
+		return
+	}
+	data, err := json.MarshalIndent(bootstrapReuseState{Digest: digest, Dirs: dirs, Globs: globs}, "", "  ")
+	if err != nil {
+		ctx.Fatalf("failed to marshal %s: %s", bootstrapReuseFile, err)
+	}
+	if err := ioutil.WriteFile(reuseFile, data, 0666); err != nil {
+		ctx.Fatalf("failed to write %s: %s", reuseFile, err)
+	}
+}
+
+// androidBpListFile returns the list of the Android.bp files of the tree, which the bootstrap parses.
+func androidBpListFile(config Config) string {
+	return filepath.Join(config.FileListDir(), "Android.bp.list")
+}
+
+func sortedUniqueDirs(dirs []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, dir := range dirs {
+		if !seen[dir] {
+			seen[dir] = true
+			unique = append(unique, dir)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanReuseBootstrap(t *testing.T) {
+	ctx := testContext()
+	outDir := t.TempDir()
+	config := Config{&configImpl{
+		environ: &Environment{"OUT_DIR=" + outDir, "SOONG_REUSE_BOOTSTRAP=true"},
+	}}
+	if !reuseBootstrap(config) {
+		t.Fatalf("expected SOONG_REUSE_BOOTSTRAP=true to reuse the bootstrap")
+	}
+
+	src := t.TempDir()
+	goPackage := filepath.Join(src, "build", "soong", "android")
+	other := filepath.Join(src, "packages", "apps", "Foo")
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(goPackage, "Android.bp"), `bootstrap_go_package { name: "soong-android" }`)
+	write(filepath.Join(goPackage, "module.go"), "package android")
+	write(filepath.Join(other, "Android.bp"), `android_app { name: "Foo" }`)
+	write(config.BootstrapNinjaFile(), "")
+	androidBps := filepath.Join(goPackage, "Android.bp") + "\n" + filepath.Join(other, "Android.bp") + "\n"
+	write(androidBpListFile(config), androidBps)
+
+	const key = "invocations"
+	if canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected no reuse without a recorded bootstrap")
+	}
+	recordBootstrap(ctx, config, key, []string{goPackage, goPackage}, nil)
+
+	if !canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected reuse when nothing changed")
+	}
+
+	write(filepath.Join(other, "Android.bp"), `android_app { name: "Foo", srcs: ["a.java"] }`)
+	if !canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected reuse when only an Android.bp file without Go packages changed")
+	}
+
+	if canReuseBootstrap(ctx, config, "other invocations") {
+		t.Errorf("expected no reuse when the soong_build invocations changed")
+	}
+
+	write(filepath.Join(goPackage, "module.go"), "package android\n")
+	if canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected no reuse when a Go file changed")
+	}
+	recordBootstrap(ctx, config, key, []string{goPackage}, nil)
+
+	write(filepath.Join(goPackage, "new.go"), "package android")
+	if canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected no reuse when a Go file was added")
+	}
+	recordBootstrap(ctx, config, key, []string{goPackage}, nil)
+
+	write(filepath.Join(goPackage, "Android.bp"), `bootstrap_go_package { name: "soong-android", deps: ["soong-new"] }`)
+	if canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected no reuse when an Android.bp file with Go packages changed")
+	}
+	recordBootstrap(ctx, config, key, []string{goPackage}, nil)
+
+	// A new Go package is in a directory that the last bootstrap didn't know about.
+	newPackage := filepath.Join(src, "build", "soong", "new")
+	write(filepath.Join(newPackage, "Android.bp"), `bootstrap_go_package { name: "soong-new" }`)
+	if !canReuseBootstrap(ctx, config, key) {
+		t.Fatalf("expected reuse before the list of Android.bp files changed")
+	}
+	write(androidBpListFile(config), androidBps+filepath.Join(newPackage, "Android.bp")+"\n")
+	if canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected no reuse when an Android.bp file was added")
+	}
+	recordBootstrap(ctx, config, key, []string{goPackage, newPackage}, nil)
+
+	// A Go package added to an existing Android.bp file in a directory without Go packages.
+	write(filepath.Join(other, "Android.bp"), `android_app { name: "Foo" }
+bootstrap_go_package { name: "soong-foo" }`)
+	if canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected no reuse when a Go package was added to an existing Android.bp file")
+	}
+	recordBootstrap(ctx, config, key, []string{goPackage, newPackage, other}, nil)
+
+	write(filepath.Join(other, "Android.bp"), `android_app { name: "Foo" }`)
+	if canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected no reuse when a Go package was removed from an Android.bp file")
+	}
+	recordBootstrap(ctx, config, key, []string{goPackage, newPackage}, nil)
+
+	// A Go file added in a subdirectory of a Go package is only seen by its glob.
+	write(filepath.Join(goPackage, "sub", "a.go"), "package sub")
+	globs := []bootstrapGlob{{Pattern: filepath.Join(goPackage, "**", "*.go")}}
+	recordBootstrap(ctx, config, key, []string{goPackage, newPackage}, globs)
+	if !canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected reuse when the glob results didn't change")
+	}
+	write(filepath.Join(goPackage, "sub", "b.go"), "package sub")
+	if canReuseBootstrap(ctx, config, key) {
+		t.Errorf("expected no reuse when a Go file was added in a subdirectory")
+	}
+}
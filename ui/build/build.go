@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"sync"
 	"text/template"
+	"time"
 
 	"android/soong/ui/metrics"
 )
@@ -59,7 +60,7 @@ func SetupOutDir(ctx Context, config Config) {
 }
 
 var combinedBuildNinjaTemplate = template.Must(template.New("combined").Parse(`
-builddir = {{.OutDir}}
+builddir = {{.NinjaStateDir}}
 {{if .UseRemoteBuild }}pool local_pool
  depth = {{.Parallel}}
 {{end -}}
@@ -138,36 +139,6 @@ func checkProblematicFiles(ctx Context) {
 	}
 }
 
-// checkCaseSensitivity issues a warning if a case-insensitive file system is being used.
-func checkCaseSensitivity(ctx Context, config Config) {
-	outDir := config.OutDir()
-	lowerCase := filepath.Join(outDir, "casecheck.txt")
-	upperCase := filepath.Join(outDir, "CaseCheck.txt")
-	lowerData := "a"
-	upperData := "B"
-
-	if err := ioutil.WriteFile(lowerCase, []byte(lowerData), 0666); err != nil { // a+rw
-		ctx.Fatalln("Failed to check case sensitivity:", err)
-	}
-
-	if err := ioutil.WriteFile(upperCase, []byte(upperData), 0666); err != nil { // a+rw
-		ctx.Fatalln("Failed to check case sensitivity:", err)
-	}
-
-	res, err := ioutil.ReadFile(lowerCase)
-	if err != nil {
-		ctx.Fatalln("Failed to check case sensitivity:", err)
-	}
-
-	if string(res) != lowerData {
-		ctx.Println("************************************************************")
-		ctx.Println("You are building on a case-insensitive filesystem.")
-		ctx.Println("Please move your source tree to a case-sensitive filesystem.")
-		ctx.Println("************************************************************")
-		ctx.Fatalln("Case-insensitive filesystems not supported")
-	}
-}
-
 // help prints a help/usage message, via the build/make/help.sh script.
 func help(ctx Context, config Config) {
 	cmd := Command(ctx, config, "help.sh", "build/make/help.sh")
@@ -246,8 +217,9 @@ func Build(ctx Context, config Config) {
 
 	SetupOutDir(ctx, config)
 
-	// checkCaseSensitivity issues a warning if a case-insensitive file system is being used.
-	checkCaseSensitivity(ctx, config)
+	// checkHostPrerequisites aborts the build if the host is missing a prerequisite of the build,
+	// such as a case-sensitive file system or a recent enough glibc.
+	checkHostPrerequisites(ctx, config)
 
 	ensureEmptyDirectoriesExist(ctx, config.TempDir())
 
@@ -266,7 +238,12 @@ func Build(ctx Context, config Config) {
 			startRBE(ctx, config)
 			close(rbeCh)
 		}()
-		defer DumpRBEMetrics(ctx, config, filepath.Join(config.LogsDir(), "rbe_metrics.pb"))
+		rbeStarted := time.Now()
+		defer func() {
+			DumpRBEMetrics(ctx, config, filepath.Join(config.LogsDir(), "rbe_metrics.pb"))
+			WriteRemoteCacheMetrics(ctx, config,
+				filepath.Join(config.LogsDir(), config.GetLogsPrefix()+"soong_build_metrics.pb"), rbeStarted)
+		}()
 	} else {
 		close(rbeCh)
 	}
@@ -329,6 +306,7 @@ func Build(ctx Context, config Config) {
 		if what&RunKati != 0 {
 			installCleanIfNecessary(ctx, config)
 		}
+		defer startCompilerCache(ctx, config)()
 		runNinjaForBuild(ctx, config)
 	}
 
@@ -0,0 +1,266 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"android/soong/ui/metrics"
+)
+
+// compilerCacheMetricsFilename is the name of the file in the logs directory that records the
+// compiler cache statistics of the build.
+const compilerCacheMetricsFilename = "compiler_cache_metrics.json"
+
+// compilerCache returns the name and the binary of the compiler cache that soong_build wraps the
+// compile actions with, see android.Config.CompilerCache. The CompilerCache product variable isn't
+// known here, so the name defaults to the name of the binary.
+func compilerCache(config Config) (name, exec string) {
+	env := config.Environment()
+	name, _ = env.Get("SOONG_COMPILER_CACHE")
+	if name == "" && env.IsEnvTrue("USE_CCACHE") {
+		name = "ccache"
+	}
+	exec, _ = env.Get("SOONG_COMPILER_CACHE_EXEC")
+	if exec == "" && name == "ccache" {
+		exec, _ = env.Get("CCACHE_EXEC")
+	}
+	if name == "" && exec != "" {
+		name = filepath.Base(exec)
+	}
+	return name, exec
+}
+
+// checkCompilerCache fails if the compiler cache is misconfigured, as the compile actions would
+// otherwise fail or silently not be cached.
+func checkCompilerCache(ctx Context, config Config) error {
+	name, exec := compilerCache(config)
+	if name == "" {
+		return nil
+	}
+	if name != "ccache" && name != "sccache" {
+		return fmt.Errorf("The compiler cache is %q, but only ccache and sccache are supported.\n"+
+			"Set SOONG_COMPILER_CACHE to ccache or sccache.", name)
+	}
+	if exec == "" {
+		return fmt.Errorf("The compiler cache is %s, but SOONG_COMPILER_CACHE_EXEC is not set.\n"+
+			"Set SOONG_COMPILER_CACHE_EXEC to the path of the %s binary, for example\n"+
+			"SOONG_COMPILER_CACHE_EXEC=/usr/bin/%s, or unset SOONG_COMPILER_CACHE and USE_CCACHE.",
+			name, name, name)
+	}
+	if info, err := os.Stat(exec); err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+		return fmt.Errorf("The %s binary %q is not an executable file.\n"+
+			"Install %s and set SOONG_COMPILER_CACHE_EXEC to its path, or unset SOONG_COMPILER_CACHE\n"+
+			"and USE_CCACHE.", name, exec, name)
+	}
+	return nil
+}
+
+// compilerCacheSandboxArgs returns the nsjail arguments that let the compile actions use the
+// compiler cache.
+func compilerCacheSandboxArgs(config Config) []string {
+	name, exec := compilerCache(config)
+	if exec == "" {
+		return nil
+	}
+
+	switch name {
+	case "ccache":
+		// The cache directory is bind mounted from CCACHE_DIR when it is set, otherwise ccache
+		// uses the default directory in the home directory.
+		if _, ok := config.Environment().Get("CCACHE_DIR"); ok {
+			return nil
+		}
+		if home, ok := config.Environment().Get("HOME"); ok {
+			dir := filepath.Join(home, ".cache", "ccache")
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				return []string{"-B", dir}
+			}
+		}
+	case "sccache":
+		// The sccache client talks to the server that soong_ui started over localhost.
+		return []string{"-N"}
+	}
+	return nil
+}
+
+// compilerCacheStats are the statistics of a compiler cache.
+type compilerCacheStats struct {
+	CompilerCache string `json:"compiler_cache"`
+	Hits          int64  `json:"hits"`
+	Misses        int64  `json:"misses"`
+	Uncacheable   int64  `json:"uncacheable"`
+}
+
+// sub returns the statistics since earlier stats, or s if the statistics were reset in between.
+func (s compilerCacheStats) sub(earlier compilerCacheStats) compilerCacheStats {
+	if s.Hits < earlier.Hits || s.Misses < earlier.Misses || s.Uncacheable < earlier.Uncacheable {
+		return s
+	}
+	s.Hits -= earlier.Hits
+	s.Misses -= earlier.Misses
+	s.Uncacheable -= earlier.Uncacheable
+	return s
+}
+
+// ccacheUncacheableStats are the counters of `ccache --print-stats` for compiles that ccache ran
+// without caching them.
+var ccacheUncacheableStats = []string{
+	"autoconf_test",
+	"bad_compiler_arguments",
+	"called_for_preprocessing",
+	"could_not_use_modules",
+	"could_not_use_precompiled_header",
+	"multiple_source_files",
+	"unsupported_code_directive",
+	"unsupported_compiler_option",
+	"unsupported_source_language",
+}
+
+// parseCcacheStats parses the tab separated output of `ccache --print-stats`.
+func parseCcacheStats(out []byte) (compilerCacheStats, error) {
+	stats := compilerCacheStats{CompilerCache: "ccache"}
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch key := fields[0]; {
+		case key == "direct_cache_hit" || key == "preprocessed_cache_hit":
+			stats.Hits += value
+		case key == "cache_miss":
+			stats.Misses += value
+		case inList(key, ccacheUncacheableStats):
+			stats.Uncacheable += value
+		default:
+			continue
+		}
+		found = true
+	}
+	if !found {
+		return stats, fmt.Errorf("no statistics in the output of ccache --print-stats")
+	}
+	return stats, nil
+}
+
+// parseSccacheStats parses the output of `sccache --show-stats --stats-format=json`.
+func parseSccacheStats(out []byte) (compilerCacheStats, error) {
+	var data struct {
+		Stats struct {
+			CacheHits struct {
+				Counts map[string]int64 `json:"counts"`
+			} `json:"cache_hits"`
+			CacheMisses struct {
+				Counts map[string]int64 `json:"counts"`
+			} `json:"cache_misses"`
+			RequestsNotCacheable int64 `json:"requests_not_cacheable"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(out, &data); err != nil {
+		return compilerCacheStats{}, err
+	}
+
+	stats := compilerCacheStats{
+		CompilerCache: "sccache",
+		Uncacheable:   data.Stats.RequestsNotCacheable,
+	}
+	for _, count := range data.Stats.CacheHits.Counts {
+		stats.Hits += count
+	}
+	for _, count := range data.Stats.CacheMisses.Counts {
+		stats.Misses += count
+	}
+	return stats, nil
+}
+
+func readCompilerCacheStats(ctx Context, config Config, name, exec string) (compilerCacheStats, error) {
+	switch name {
+	case "ccache":
+		out, err := Command(ctx, config, "ccache stats", exec, "--print-stats").Output()
+		if err != nil {
+			return compilerCacheStats{}, err
+		}
+		return parseCcacheStats(out)
+	case "sccache":
+		out, err := Command(ctx, config, "sccache stats", exec, "--show-stats", "--stats-format=json").Output()
+		if err != nil {
+			return compilerCacheStats{}, err
+		}
+		return parseSccacheStats(out)
+	}
+	return compilerCacheStats{}, fmt.Errorf("unsupported compiler cache %q", name)
+}
+
+// startCompilerCache prepares the compiler cache for a build, and returns a function that records
+// the statistics of the build in the logs directory.
+func startCompilerCache(ctx Context, config Config) func() {
+	filename := filepath.Join(config.LogsDir(), config.GetLogsPrefix()+compilerCacheMetricsFilename)
+	os.Remove(filename)
+
+	name, exec := compilerCache(config)
+	if exec == "" || config.UseGoma() || config.UseRBE() {
+		return func() {}
+	}
+
+	ctx.BeginTrace(metrics.RunSetupTool, "compiler_cache")
+	defer ctx.EndTrace()
+
+	if name == "sccache" {
+		// The server outlives the build, start it outside of the sandbox of the compile actions.
+		// Starting it fails if it is already running.
+		if out, err := Command(ctx, config, "sccache server", exec, "--start-server").CombinedOutput(); err != nil {
+			ctx.Verbosef("sccache --start-server: %v\n%s", err, out)
+		}
+	}
+
+	before, err := readCompilerCacheStats(ctx, config, name, exec)
+	if err != nil {
+		ctx.Verbosef("Failed to read the statistics of %s: %v", name, err)
+		return func() {}
+	}
+
+	return func() {
+		after, err := readCompilerCacheStats(ctx, config, name, exec)
+		if err != nil {
+			ctx.Verbosef("Failed to read the statistics of %s: %v", name, err)
+			return
+		}
+		stats := after.sub(before)
+		ctx.Verbosef("%s: %d hits, %d misses, %d uncacheable", name, stats.Hits, stats.Misses, stats.Uncacheable)
+
+		data, err := json.Marshal(stats)
+		if err != nil {
+			ctx.Verbosef("Failed to marshal the statistics of %s: %v", name, err)
+			return
+		}
+		if err := ioutil.WriteFile(filename, data, 0666); err != nil { // a+rw
+			ctx.Verbosef("Failed to write %s: %v", filename, err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompilerCache(t *testing.T) {
+	testCases := []struct {
+		description string
+		env         map[string]string
+		name        string
+		exec        string
+	}{
+		{
+			description: "none",
+		},
+		{
+			description: "sccache",
+			env:         map[string]string{"SOONG_COMPILER_CACHE": "sccache", "SOONG_COMPILER_CACHE_EXEC": "/bin/sccache"},
+			name:        "sccache",
+			exec:        "/bin/sccache",
+		},
+		{
+			description: "name from binary",
+			env:         map[string]string{"SOONG_COMPILER_CACHE_EXEC": "/bin/sccache"},
+			name:        "sccache",
+			exec:        "/bin/sccache",
+		},
+		{
+			description: "USE_CCACHE",
+			env:         map[string]string{"USE_CCACHE": "true", "CCACHE_EXEC": "/bin/ccache"},
+			name:        "ccache",
+			exec:        "/bin/ccache",
+		},
+		{
+			description: "CCACHE_EXEC only applies to ccache",
+			env:         map[string]string{"SOONG_COMPILER_CACHE": "sccache", "CCACHE_EXEC": "/bin/ccache"},
+			name:        "sccache",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			env := Environment{}
+			for k, v := range tt.env {
+				env.Set(k, v)
+			}
+			config := Config{&configImpl{environ: &env}}
+
+			name, exec := compilerCache(config)
+			if name != tt.name || exec != tt.exec {
+				t.Errorf("expected %q, %q, got %q, %q", tt.name, tt.exec, name, exec)
+			}
+		})
+	}
+}
+
+func TestCheckCompilerCache(t *testing.T) {
+	ctx := testContext()
+
+	tmpDir := t.TempDir()
+	ccache := filepath.Join(tmpDir, "ccache")
+	if err := os.WriteFile(ccache, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	notExecutable := filepath.Join(tmpDir, "not_executable")
+	if err := os.WriteFile(notExecutable, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		env         map[string]string
+		fail        bool
+	}{
+		{
+			description: "compiler cache disabled",
+		},
+		{
+			description: "ccache enabled",
+			env:         map[string]string{"SOONG_COMPILER_CACHE": "ccache", "SOONG_COMPILER_CACHE_EXEC": ccache},
+		},
+		{
+			description: "USE_CCACHE",
+			env:         map[string]string{"USE_CCACHE": "true", "CCACHE_EXEC": ccache},
+		},
+		{
+			description: "unknown compiler cache",
+			env:         map[string]string{"SOONG_COMPILER_CACHE": "distcc", "SOONG_COMPILER_CACHE_EXEC": ccache},
+			fail:        true,
+		},
+		{
+			description: "no binary",
+			env:         map[string]string{"USE_CCACHE": "true"},
+			fail:        true,
+		},
+		{
+			description: "missing binary",
+			env:         map[string]string{"SOONG_COMPILER_CACHE": "ccache", "SOONG_COMPILER_CACHE_EXEC": filepath.Join(tmpDir, "missing")},
+			fail:        true,
+		},
+		{
+			description: "binary not executable",
+			env:         map[string]string{"SOONG_COMPILER_CACHE": "ccache", "SOONG_COMPILER_CACHE_EXEC": notExecutable},
+			fail:        true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			env := Environment{}
+			for k, v := range tt.env {
+				env.Set(k, v)
+			}
+			config := Config{&configImpl{environ: &env}}
+
+			err := checkCompilerCache(ctx, config)
+			if tt.fail && err == nil {
+				t.Errorf("expected an error")
+			} else if !tt.fail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseCcacheStats(t *testing.T) {
+	out := "stats_updated_timestamp\t1700000000\n" +
+		"direct_cache_hit\t10\n" +
+		"preprocessed_cache_hit\t2\n" +
+		"cache_miss\t5\n" +
+		"called_for_link\t7\n" +
+		"unsupported_compiler_option\t3\n"
+
+	stats, err := parseCcacheStats([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := compilerCacheStats{CompilerCache: "ccache", Hits: 12, Misses: 5, Uncacheable: 3}
+	if stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+
+	if _, err := parseCcacheStats([]byte("Usage: ccache [options]\n")); err == nil {
+		t.Errorf("expected an error for output without statistics")
+	}
+}
+
+func TestParseSccacheStats(t *testing.T) {
+	out := `{
+		"stats": {
+			"compile_requests": 20,
+			"requests_not_cacheable": 4,
+			"cache_hits": {"counts": {"C/C++": 6, "Rust": 3}, "adv_counts": {}},
+			"cache_misses": {"counts": {"C/C++": 2}, "adv_counts": {}}
+		},
+		"cache_location": "Local disk"
+	}`
+
+	stats, err := parseSccacheStats([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := compilerCacheStats{CompilerCache: "sccache", Hits: 9, Misses: 2, Uncacheable: 4}
+	if stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestCompilerCacheStatsSub(t *testing.T) {
+	before := compilerCacheStats{CompilerCache: "ccache", Hits: 10, Misses: 5, Uncacheable: 1}
+	after := compilerCacheStats{CompilerCache: "ccache", Hits: 15, Misses: 7, Uncacheable: 1}
+	expected := compilerCacheStats{CompilerCache: "ccache", Hits: 5, Misses: 2}
+	if got := after.sub(before); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	// The statistics were reset during the build.
+	reset := compilerCacheStats{CompilerCache: "ccache", Hits: 3, Misses: 1}
+	if got := reset.sub(before); got != reset {
+		t.Errorf("expected %+v, got %+v", reset, got)
+	}
+}
@@ -78,6 +78,13 @@ type configImpl struct {
 	queryview         bool
 	reportMkMetrics   bool // Collect and report mk2bp migration progress metrics.
 	soongDocs         bool
+	impact            bool // Write the impact of a change to $SOONG_IMPACT_OF.
+	explain           bool // Write the action that generates $SOONG_EXPLAIN.
+	query             bool // Write the modules that match $SOONG_QUERY.
+	listModules       bool // Write the modules that match $SOONG_LIST_MODULES_FILTER.
+	mutatorPipeline   bool // Write the mutator pipeline and the variants and dependencies of each mutator.
+	configDump        bool // Write the fully resolved configuration of the product.
+	moduleDeps        bool // Write the direct dependents of each module variant.
 	multitreeBuild    bool // This is a multitree build.
 	skipConfig        bool
 	skipKati          bool
@@ -111,11 +118,17 @@ type configImpl struct {
 	bazelDevMode     bool
 	bazelStagingMode bool
 
+	// Whether to run the Bazel queries of mixed builds even if their outputs are cached.
+	forceBazelRequery bool
+
 	// Set by multiproduct_kati
 	emptyNinjaFile bool
 
 	metricsUploader string
 
+	// The read-only tree that modules are imported from, if any.
+	secondaryTree string
+
 	bazelForceEnabledModules string
 
 	includeTags    []string
@@ -138,6 +151,8 @@ const (
 	EXTERNAL_FILE
 	// ninja uses a prioritized module list from Soong
 	HINT_FROM_SOONG
+	// ninja uses the estimated duration of every action from Soong
+	SOONG_ACTIONS
 )
 const srcDirFileCheck = "build/soong/root.bp"
 
@@ -346,6 +361,10 @@ func NewConfig(ctx Context, args ...string) Config {
 		ret.environ.Set("OUT_DIR", outDir)
 	}
 
+	if err := validateStateScope(ret.StateScope()); err != nil {
+		ctx.Fatalln(err)
+	}
+
 	// loadEnvConfig needs to know what the OUT_DIR is, so it should
 	// be called after we determine the appropriate out directory.
 	bc := os.Getenv("ANDROID_BUILD_ENVIRONMENT_CONFIG")
@@ -509,14 +528,50 @@ func NewConfig(ctx Context, args ...string) Config {
 
 	outDir := ret.OutDir()
 	buildDateTimeFile := filepath.Join(outDir, "build_date.txt")
+	sourceDateEpoch, _ := ret.environ.Get("SOURCE_DATE_EPOCH")
+	if sourceDateEpoch != "" {
+		if epoch, err := strconv.ParseInt(sourceDateEpoch, 10, 64); err != nil || epoch < 0 {
+			ctx.Fatalf("SOURCE_DATE_EPOCH must be a non-negative number of seconds since the Unix epoch, got %q", sourceDateEpoch)
+		}
+	}
 	if buildDateTime, ok := ret.environ.Get("BUILD_DATETIME"); ok && buildDateTime != "" {
 		ret.buildDateTime = buildDateTime
+	} else if sourceDateEpoch != "" {
+		// Pin the build date to the epoch so that it isn't embedded in the outputs.
+		ret.buildDateTime = sourceDateEpoch
 	} else {
 		ret.buildDateTime = strconv.FormatInt(time.Now().Unix(), 10)
 	}
 
 	ret.environ.Set("BUILD_DATETIME_FILE", buildDateTimeFile)
 
+	if secondaryTree, ok := ret.environ.Get("SOONG_SECONDARY_TREE"); ok && secondaryTree != "" {
+		// soong_build resolves the modules imported from the secondary tree through the
+		// link that runSoong creates to it, so it must not depend on the working directory.
+		absSecondaryTree, err := filepath.Abs(secondaryTree)
+		if err != nil {
+			ctx.Fatalf("Failed to get the absolute path of SOONG_SECONDARY_TREE: %v", err)
+		}
+		if info, err := os.Stat(absSecondaryTree); err != nil || !info.IsDir() {
+			ctx.Fatalf("SOONG_SECONDARY_TREE must be the root of a source tree, got %q", secondaryTree)
+		}
+		ret.environ.Set("SOONG_SECONDARY_TREE", absSecondaryTree)
+		ret.secondaryTree = absSecondaryTree
+	}
+
+	if coverageData, ok := ret.environ.Get("SOONG_COVERAGE_DATA"); ok && coverageData != "" {
+		// The path may be relative to the directory m is run from, while the coverage report rule
+		// runs from the top of the tree.
+		absCoverageData, err := filepath.Abs(coverageData)
+		if err != nil {
+			ctx.Fatalf("Failed to get the absolute path of SOONG_COVERAGE_DATA: %v", err)
+		}
+		if info, err := os.Stat(absCoverageData); err != nil || !info.IsDir() {
+			ctx.Fatalf("SOONG_COVERAGE_DATA must be a directory of coverage data, got %q", coverageData)
+		}
+		ret.environ.Set("SOONG_COVERAGE_DATA", absCoverageData)
+	}
+
 	if ret.UseRBE() {
 		for k, v := range getRBEVars(ctx, Config{ret}) {
 			ret.environ.Set(k, v)
@@ -568,7 +623,7 @@ func getNinjaWeightListSourceInMetric(s NinjaWeightListSource) *smpb.BuildConfig
 		return smpb.BuildConfig_EVENLY_DISTRIBUTED.Enum()
 	case EXTERNAL_FILE:
 		return smpb.BuildConfig_EXTERNAL_FILE.Enum()
-	case HINT_FROM_SOONG:
+	case HINT_FROM_SOONG, SOONG_ACTIONS:
 		return smpb.BuildConfig_HINT_FROM_SOONG.Enum()
 	default:
 		return smpb.BuildConfig_NOT_USED.Enum()
@@ -843,6 +898,8 @@ func (c *configImpl) parseArgs(ctx Context, args []string) {
 			c.bazelDevMode = true
 		} else if arg == "--bazel-mode-staging" {
 			c.bazelStagingMode = true
+		} else if arg == "--force-bazel-requery" {
+			c.forceBazelRequery = true
 		} else if arg == "--search-api-dir" {
 			c.searchApiDir = true
 		} else if strings.HasPrefix(arg, "--ninja_weight_source=") {
@@ -855,6 +912,8 @@ func (c *configImpl) parseArgs(ctx Context, args []string) {
 				c.ninjaWeightListSource = NOT_USED
 			} else if source == "soong" {
 				c.ninjaWeightListSource = HINT_FROM_SOONG
+			} else if source == "soong_actions" {
+				c.ninjaWeightListSource = SOONG_ACTIONS
 			} else if strings.HasPrefix(source, "file,") {
 				c.ninjaWeightListSource = EXTERNAL_FILE
 				filePath := strings.TrimPrefix(source, "file,")
@@ -862,7 +921,7 @@ func (c *configImpl) parseArgs(ctx Context, args []string) {
 				if err != nil {
 					ctx.Fatalf("Malformed weight list from %s: %s", filePath, err)
 				}
-				_, err = copyFile(filePath, filepath.Join(c.OutDir(), ".ninja_weight_list"))
+				_, err = copyFile(filePath, filepath.Join(c.NinjaStateDir(), ".ninja_weight_list"))
 				if err != nil {
 					ctx.Fatalf("Error to copy ninja weight list from %s: %s", filePath, err)
 				}
@@ -929,6 +988,20 @@ func (c *configImpl) parseArgs(ctx Context, args []string) {
 			c.queryview = true
 		} else if arg == "soong_docs" {
 			c.soongDocs = true
+		} else if arg == "impact" {
+			c.impact = true
+		} else if arg == "explain" {
+			c.explain = true
+		} else if arg == "query" {
+			c.query = true
+		} else if arg == "list_modules" {
+			c.listModules = true
+		} else if arg == "mutator_pipeline" {
+			c.mutatorPipeline = true
+		} else if arg == "dump_config" {
+			c.configDump = true
+		} else if arg == "module_deps" {
+			c.moduleDeps = true
 		} else {
 			if arg == "checkbuild" {
 				c.checkbuild = true
@@ -936,6 +1009,13 @@ func (c *configImpl) parseArgs(ctx Context, args []string) {
 			c.arguments = append(c.arguments, arg)
 		}
 	}
+	if c.dist {
+		// The outputs of the dist groups selected with DIST_GROUPS are copied by the goals of the
+		// groups, see distGroupGoal in android/androidmk.go.
+		for _, group := range c.DistGroups() {
+			c.arguments = append(c.arguments, "dist_group_"+group)
+		}
+	}
 	if (!c.bazelProdMode) && (!c.bazelDevMode) && (!c.bazelStagingMode) {
 		c.bazelProdMode = defaultBazelProdMode(c)
 	}
@@ -1024,7 +1104,7 @@ func (c *configImpl) SoongBuildInvocationNeeded() bool {
 		return true
 	}
 
-	if !c.JsonModuleGraph() && !c.Bp2Build() && !c.Queryview() && !c.SoongDocs() && !c.ApiBp2build() {
+	if !c.JsonModuleGraph() && !c.Bp2Build() && !c.Queryview() && !c.SoongDocs() && !c.ApiBp2build() && !c.Impact() && !c.Explain() && !c.Query() && !c.ListModules() && !c.MutatorPipeline() && !c.ConfigDump() && !c.ModuleDeps() {
 		// Command line was empty, the default Ninja target is built
 		return true
 	}
@@ -1096,19 +1176,19 @@ func (c *configImpl) HostToolDir() string {
 }
 
 func (c *configImpl) NamedGlobFile(name string) string {
-	return shared.JoinPath(c.SoongOutDir(), "globs-"+name+".ninja")
+	return shared.JoinPath(c.SoongStateDir(), "globs-"+name+".ninja")
 }
 
 func (c *configImpl) UsedEnvFile(tag string) string {
-	return shared.JoinPath(c.SoongOutDir(), usedEnvFile+"."+tag)
+	return shared.JoinPath(c.SoongStateDir(), usedEnvFile+"."+tag)
 }
 
 func (c *configImpl) Bp2BuildFilesMarkerFile() string {
-	return shared.JoinPath(c.SoongOutDir(), "bp2build_files_marker")
+	return shared.JoinPath(c.SoongStateDir(), "bp2build_files_marker")
 }
 
 func (c *configImpl) Bp2BuildWorkspaceMarkerFile() string {
-	return shared.JoinPath(c.SoongOutDir(), "bp2build_workspace_marker")
+	return shared.JoinPath(c.SoongStateDir(), "bp2build_workspace_marker")
 }
 
 func (c *configImpl) SoongDocsHtml() string {
@@ -1116,11 +1196,11 @@ func (c *configImpl) SoongDocsHtml() string {
 }
 
 func (c *configImpl) QueryviewMarkerFile() string {
-	return shared.JoinPath(c.SoongOutDir(), "queryview.marker")
+	return shared.JoinPath(c.SoongStateDir(), "queryview.marker")
 }
 
 func (c *configImpl) ApiBp2buildMarkerFile() string {
-	return shared.JoinPath(c.SoongOutDir(), "api_bp2build.marker")
+	return shared.JoinPath(c.SoongStateDir(), "api_bp2build.marker")
 }
 
 func (c *configImpl) ModuleGraphFile() string {
@@ -1131,8 +1211,40 @@ func (c *configImpl) ModuleActionsFile() string {
 	return shared.JoinPath(c.SoongOutDir(), "module-actions.json")
 }
 
+func (c *configImpl) ImpactFile() string {
+	return shared.JoinPath(c.SoongOutDir(), "impact.json")
+}
+
+func (c *configImpl) ExplainFile() string {
+	return shared.JoinPath(c.SoongOutDir(), "explain.txt")
+}
+
+func (c *configImpl) QueryFile() string {
+	return shared.JoinPath(c.SoongOutDir(), "query.txt")
+}
+
+func (c *configImpl) ListModulesFile() string {
+	return shared.JoinPath(c.SoongOutDir(), "module_list.tsv")
+}
+
+func (c *configImpl) MutatorPipelineFile() string {
+	return shared.JoinPath(c.SoongOutDir(), "mutator_pipeline.json")
+}
+
+func (c *configImpl) ConfigDumpFile() string {
+	return shared.JoinPath(c.SoongOutDir(), "config_dump.json")
+}
+
+func (c *configImpl) ModuleDepsFile() string {
+	return shared.JoinPath(c.SoongOutDir(), "module_deps.json")
+}
+
+func (c *configImpl) DependencyCyclesFile() string {
+	return shared.JoinPath(c.SoongOutDir(), "dependency_cycles.stamp")
+}
+
 func (c *configImpl) TempDir() string {
-	return shared.TempDirForOutDir(c.SoongOutDir())
+	return shared.TempDirForOutDir(c.SoongStateDir())
 }
 
 func (c *configImpl) FileListDir() string {
@@ -1156,6 +1268,12 @@ func (c *configImpl) Dist() bool {
 	return c.dist
 }
 
+// DistGroups returns the dist groups selected with DIST_GROUPS, a comma or space separated list.
+func (c *configImpl) DistGroups() []string {
+	groups, _ := c.environ.Get("DIST_GROUPS")
+	return strings.Fields(strings.ReplaceAll(groups, ",", " "))
+}
+
 func (c *configImpl) JsonModuleGraph() bool {
 	return c.jsonModuleGraph
 }
@@ -1176,6 +1294,34 @@ func (c *configImpl) SoongDocs() bool {
 	return c.soongDocs
 }
 
+func (c *configImpl) Impact() bool {
+	return c.impact
+}
+
+func (c *configImpl) Explain() bool {
+	return c.explain
+}
+
+func (c *configImpl) Query() bool {
+	return c.query
+}
+
+func (c *configImpl) ListModules() bool {
+	return c.listModules
+}
+
+func (c *configImpl) MutatorPipeline() bool {
+	return c.mutatorPipeline
+}
+
+func (c *configImpl) ConfigDump() bool {
+	return c.configDump
+}
+
+func (c *configImpl) ModuleDeps() bool {
+	return c.moduleDeps
+}
+
 func (c *configImpl) IsVerbose() bool {
 	return c.verbose
 }
@@ -1184,6 +1330,18 @@ func (c *configImpl) MultitreeBuild() bool {
 	return c.multitreeBuild
 }
 
+// SecondaryTree returns the absolute path of the read-only tree that modules are imported from,
+// or "" if there is none.
+func (c *configImpl) SecondaryTree() string {
+	return c.secondaryTree
+}
+
+// SecondaryTreeLink returns the link to the secondary tree that soong_build resolves the modules
+// imported from it through. It must match secondaryTreeLinkDir in multitree/secondary_tree.go.
+func (c *configImpl) SecondaryTreeLink() string {
+	return filepath.Join(c.SoongOutDir(), "multitree", "secondary")
+}
+
 func (c *configImpl) NinjaWeightListSource() NinjaWeightListSource {
 	return c.ninjaWeightListSource
 }
@@ -1536,15 +1694,15 @@ func (c *configImpl) HasKatiSuffix() bool {
 }
 
 func (c *configImpl) KatiEnvFile() string {
-	return filepath.Join(c.OutDir(), "env"+c.KatiSuffix()+".sh")
+	return filepath.Join(c.NinjaStateDir(), "env"+c.KatiSuffix()+".sh")
 }
 
 func (c *configImpl) KatiBuildNinjaFile() string {
-	return filepath.Join(c.OutDir(), "build"+c.KatiSuffix()+katiBuildSuffix+".ninja")
+	return filepath.Join(c.NinjaStateDir(), "build"+c.KatiSuffix()+katiBuildSuffix+".ninja")
 }
 
 func (c *configImpl) KatiPackageNinjaFile() string {
-	return filepath.Join(c.OutDir(), "build"+c.KatiSuffix()+katiPackageSuffix+".ninja")
+	return filepath.Join(c.NinjaStateDir(), "build"+c.KatiSuffix()+katiPackageSuffix+".ninja")
 }
 
 func (c *configImpl) SoongVarsFile() string {
@@ -1552,14 +1710,20 @@ func (c *configImpl) SoongVarsFile() string {
 }
 
 func (c *configImpl) SoongNinjaFile() string {
-	return filepath.Join(c.SoongOutDir(), "build.ninja")
+	return filepath.Join(c.SoongStateDir(), "build.ninja")
+}
+
+// BootstrapNinjaFile returns the ninja file that runs soong_build, which names the files of the
+// state directory.
+func (c *configImpl) BootstrapNinjaFile() string {
+	return filepath.Join(c.SoongStateDir(), "bootstrap.ninja")
 }
 
 func (c *configImpl) CombinedNinjaFile() string {
 	if c.katiSuffix == "" {
-		return filepath.Join(c.OutDir(), "combined.ninja")
+		return filepath.Join(c.NinjaStateDir(), "combined.ninja")
 	}
-	return filepath.Join(c.OutDir(), "combined"+c.KatiSuffix()+".ninja")
+	return filepath.Join(c.NinjaStateDir(), "combined"+c.KatiSuffix()+".ninja")
 }
 
 func (c *configImpl) SoongAndroidMk() string {
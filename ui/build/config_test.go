@@ -158,6 +158,20 @@ func TestConfigParseArgsVars(t *testing.T) {
 			expectedEnv: []string{"A="},
 			remaining:   []string{"=b"},
 		},
+
+		{
+			args: []string{"dist", "DIST_GROUPS=symbols,sbom"},
+
+			expectedEnv: []string{"DIST_GROUPS=symbols,sbom"},
+			remaining:   []string{"dist_group_symbols", "dist_group_sbom"},
+		},
+		{
+			env:  []string{"DIST_GROUPS=symbols"},
+			args: []string{"droid"},
+
+			expectedEnv: []string{"DIST_GROUPS=symbols"},
+			remaining:   []string{"droid"},
+		},
 	}
 
 	for _, tc := range testCases {
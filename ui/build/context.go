@@ -52,6 +52,9 @@ func (c ContextImpl) BeginTrace(name, desc string) {
 	if c.Metrics != nil {
 		c.Metrics.EventTracer.Begin(name, desc)
 	}
+	if c.Status != nil {
+		c.Status.BeginPhase(desc)
+	}
 }
 
 // EndTrace finishes the last Duration Event.
@@ -62,6 +65,9 @@ func (c ContextImpl) EndTrace() {
 	if c.Metrics != nil {
 		c.Metrics.SetTimeMetrics(c.Metrics.EventTracer.End())
 	}
+	if c.Status != nil {
+		c.Status.EndPhase()
+	}
 }
 
 // CompleteTrace writes a trace with a beginning and end times.
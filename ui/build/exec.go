@@ -175,6 +175,13 @@ func (c *Cmd) RunAndPrintOrFatal() {
 // RunAndStreamOrFatal will run the command, while running print
 // any output, then handle any errors with a call to ctx.Fatal
 func (c *Cmd) RunAndStreamOrFatal() {
+	c.reportError(c.RunAndStream())
+}
+
+// RunAndStream will run the command, while running print any output, then
+// return the error of the command, so the caller can act on a failure before
+// reporting it.
+func (c *Cmd) RunAndStream() error {
 	out, err := c.StdoutPipe()
 	if err != nil {
 		c.ctx.Fatal(err)
@@ -200,5 +207,5 @@ func (c *Cmd) RunAndStreamOrFatal() {
 
 	err = c.Wait()
 	st.Finish()
-	c.reportError(err)
+	return err
 }
@@ -19,6 +19,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"android/soong/finder"
@@ -74,6 +75,8 @@ func NewSourceFinder(ctx Context, config Config) (f *finder.Finder) {
 			"AndroidProducts.mk",
 			// General Soong build definitions, using the Blueprint syntax.
 			"Android.bp",
+			// Programs that generate Soong build definitions at load time.
+			moduleListProviderName,
 			// Bazel build definitions.
 			"BUILD.bazel",
 			// Bazel build definitions.
@@ -210,6 +213,8 @@ func FindSources(ctx Context, config Config, f *finder.Finder) {
 	if len(androidBps) == 0 {
 		ctx.Fatalf("No Android.bp found")
 	}
+	// Add the Blueprint files contributed by module list providers.
+	androidBps = append(androidBps, runModuleListProviders(ctx, config, f)...)
 	err = dumpListToFile(ctx, config, androidBps, filepath.Join(dumpDir, "Android.bp.list"))
 	if err != nil {
 		ctx.Fatalf("Could not find modules: %v", err)
@@ -235,8 +240,15 @@ func dumpListToFile(ctx Context, config Config, list []string, filePath string)
 	desiredBytes := []byte(desiredText)
 	actualBytes, readErr := ioutil.ReadFile(filePath)
 	if readErr != nil || !bytes.Equal(desiredBytes, actualBytes) {
-		err = ioutil.WriteFile(filePath, desiredBytes, 0777)
+		// Builds in other SOONG_STATE_SCOPEs may read the list at the same time, so replace it
+		// instead of truncating it.
+		tempPath := filePath + ".tmp." + strconv.Itoa(os.Getpid())
+		err = ioutil.WriteFile(tempPath, desiredBytes, 0777)
+		if err == nil {
+			err = os.Rename(tempPath, filePath)
+		}
 		if err != nil {
+			os.Remove(tempPath)
 			return err
 		}
 	}
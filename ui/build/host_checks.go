@@ -0,0 +1,133 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// skipHostChecksEnv is a comma separated list of host checks to skip, or "all".
+const skipHostChecksEnv = "SOONG_SKIP_HOST_CHECKS"
+
+// hostCheck validates a prerequisite of the build on the host, so that a missing one fails the
+// build up front with a message that explains how to fix it, instead of failing a tool deep in
+// the build. soong_build only runs under soong_ui, so the checks cover it too.
+type hostCheck struct {
+	// Name of the check in SOONG_SKIP_HOST_CHECKS.
+	name string
+
+	// Whether a failure of the check only prints a warning, as the build can continue without
+	// the prerequisite.
+	warning bool
+
+	// check returns an error that explains how to fix the host if the prerequisite is missing.
+	check func(ctx Context, config Config) error
+}
+
+func hostChecks() []hostCheck {
+	checks := []hostCheck{
+		{name: "case_sensitivity", check: checkCaseSensitivity},
+		{name: "compiler_cache", check: checkCompilerCache},
+	}
+	return append(checks, platformHostChecks()...)
+}
+
+// hostCheckFailures runs the checks that are not skipped by SOONG_SKIP_HOST_CHECKS, and returns
+// the messages of the failed ones.
+func hostCheckFailures(ctx Context, config Config, checks []hostCheck) (warnings, errs []string, err error) {
+	skip := make(map[string]bool)
+	if v, ok := config.Environment().Get(skipHostChecksEnv); ok && v != "" {
+		var names []string
+		for _, check := range checks {
+			names = append(names, check.name)
+		}
+		sort.Strings(names)
+
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name != "all" && !inList(name, names) {
+				return nil, nil, fmt.Errorf("Unknown host check %q in %s, valid checks are: all, %s",
+					name, skipHostChecksEnv, strings.Join(names, ", "))
+			}
+			skip[name] = true
+		}
+	}
+
+	for _, check := range checks {
+		if skip["all"] || skip[check.name] {
+			ctx.Verbosef("Skipping host check %s", check.name)
+			continue
+		}
+		if err := check.check(ctx, config); err != nil {
+			msg := fmt.Sprintf("%s\n(set %s=%s to skip this check)", err, skipHostChecksEnv, check.name)
+			if check.warning {
+				warnings = append(warnings, msg)
+			} else {
+				errs = append(errs, msg)
+			}
+		}
+	}
+	return warnings, errs, nil
+}
+
+// checkHostPrerequisites fails the build if a prerequisite of the build is missing on the host.
+func checkHostPrerequisites(ctx Context, config Config) {
+	warnings, errs, err := hostCheckFailures(ctx, config, hostChecks())
+	if err != nil {
+		ctx.Fatalln(err)
+	}
+
+	for _, msg := range append(warnings, errs...) {
+		ctx.Println("************************************************************")
+		ctx.Println(msg)
+		ctx.Println("************************************************************")
+	}
+	if len(errs) > 0 {
+		ctx.Fatalln("The host does not meet the prerequisites of the build")
+	}
+}
+
+// checkCaseSensitivity fails if a case-insensitive file system is being used.
+func checkCaseSensitivity(ctx Context, config Config) error {
+	outDir := config.OutDir()
+	lowerCase := filepath.Join(outDir, "casecheck.txt")
+	upperCase := filepath.Join(outDir, "CaseCheck.txt")
+	lowerData := "a"
+	upperData := "B"
+
+	if err := ioutil.WriteFile(lowerCase, []byte(lowerData), 0666); err != nil { // a+rw
+		return fmt.Errorf("Failed to check case sensitivity: %w", err)
+	}
+
+	if err := ioutil.WriteFile(upperCase, []byte(upperData), 0666); err != nil { // a+rw
+		return fmt.Errorf("Failed to check case sensitivity: %w", err)
+	}
+
+	res, err := ioutil.ReadFile(lowerCase)
+	if err != nil {
+		return fmt.Errorf("Failed to check case sensitivity: %w", err)
+	}
+
+	if string(res) != lowerData {
+		return errors.New("You are building on a case-insensitive filesystem.\n" +
+			"Please move your source tree to a case-sensitive filesystem.")
+	}
+	return nil
+}
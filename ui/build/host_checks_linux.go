@@ -0,0 +1,148 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// minGlibcVersion is the oldest glibc that the prebuilt host toolchains run on.
+var minGlibcVersion = [2]int{2, 17}
+
+// dynamicLinker is the interpreter of the prebuilt x86_64 host tools.
+const dynamicLinker = "/lib64/ld-linux-x86-64.so.2"
+
+func platformHostChecks() []hostCheck {
+	return []hostCheck{
+		{name: "glibc", check: checkGlibc},
+		{name: "dynamic_linker", check: checkDynamicLinker},
+		{name: "syscalls", check: checkSyscalls},
+		{name: "user_namespaces", warning: true, check: checkUserNamespaces},
+	}
+}
+
+// checkGlibc fails if the host libc is not glibc, or is older than the prebuilt toolchains need.
+func checkGlibc(ctx Context, config Config) error {
+	out, err := exec.Command("getconf", "GNU_LIBC_VERSION").Output()
+	if err != nil {
+		// getconf only knows GNU_LIBC_VERSION on glibc, fall back to the banner of ldd.
+		out, _ = exec.Command("ldd", "--version").CombinedOutput()
+	}
+
+	version, ok := parseGlibcVersion(string(out))
+	if !ok {
+		return fmt.Errorf("Could not find the glibc version of the host, which is not glibc based or\n"+
+			"has no getconf or ldd in PATH. The prebuilt host toolchains need glibc %d.%d or later;\n"+
+			"on a musl based distribution, build in a glibc container or chroot.",
+			minGlibcVersion[0], minGlibcVersion[1])
+	}
+	ctx.Verbosef("Host glibc version: %d.%d", version[0], version[1])
+
+	if version[0] < minGlibcVersion[0] ||
+		(version[0] == minGlibcVersion[0] && version[1] < minGlibcVersion[1]) {
+		return fmt.Errorf("The host has glibc %d.%d, but the prebuilt host toolchains need glibc %d.%d or later.\n"+
+			"Please upgrade the host, or build in a container of a supported distribution.",
+			version[0], version[1], minGlibcVersion[0], minGlibcVersion[1])
+	}
+	return nil
+}
+
+// parseGlibcVersion returns the glibc version from the output of `getconf GNU_LIBC_VERSION`
+// ("glibc 2.35") or the first line of `ldd --version` ("ldd (GNU libc) 2.35").
+func parseGlibcVersion(out string) ([2]int, bool) {
+	line := strings.SplitN(strings.TrimSpace(out), "\n", 2)[0]
+	if !strings.Contains(strings.ToLower(line), "glibc") && !strings.Contains(line, "GNU libc") {
+		return [2]int{}, false
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return [2]int{}, false
+	}
+	parts := strings.Split(fields[len(fields)-1], ".")
+	if len(parts) < 2 {
+		return [2]int{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return [2]int{}, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return [2]int{}, false
+	}
+	return [2]int{major, minor}, true
+}
+
+// checkDynamicLinker fails if the prebuilt host tools can't be executed because the host has no
+// dynamic linker at the standard path, which is the case on NixOS and Guix.
+func checkDynamicLinker(ctx Context, config Config) error {
+	if runtime.GOARCH != "amd64" {
+		return nil
+	}
+	if _, err := os.Stat(dynamicLinker); err != nil {
+		return fmt.Errorf("The prebuilt host tools need the dynamic linker %s, which does not exist.\n"+
+			"On NixOS, enable programs.nix-ld or build in an FHS environment (buildFHSEnv).", dynamicLinker)
+	}
+	return nil
+}
+
+// checkSyscalls fails if the kernel doesn't support the system calls that the build tools rely
+// on, as is the case on some emulation layers such as WSL 1.
+func checkSyscalls(ctx Context, config Config) error {
+	f, err := os.Create(filepath.Join(config.OutDir(), "syscallcheck.lock"))
+	if err != nil {
+		return fmt.Errorf("Failed to check system calls: %w", err)
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return fmt.Errorf("The kernel does not support flock(2) on %s: %v.\n"+
+			"Please move the output directory to a local filesystem, or build on a native Linux kernel.",
+			config.OutDir(), err)
+	}
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	var fds [2]int
+	if err := syscall.Pipe2(fds[:], syscall.O_CLOEXEC); err != nil {
+		return errors.New("The kernel does not support pipe2(2), please build on a native Linux kernel.")
+	}
+	syscall.Close(fds[0])
+	syscall.Close(fds[1])
+	return nil
+}
+
+// checkUserNamespaces warns if unprivileged user namespaces are disabled, in which case the build
+// runs without the nsjail sandbox.
+func checkUserNamespaces(ctx Context, config Config) error {
+	cmd := exec.Command("/bin/true")
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS,
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Unprivileged user namespaces are disabled (%v), so the build runs without\n"+
+			"the nsjail sandbox. Enable them with `sysctl kernel.unprivileged_userns_clone=1` or\n"+
+			"`sysctl user.max_user_namespaces=<n>`, depending on the distribution.", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"testing"
+)
+
+func TestParseGlibcVersion(t *testing.T) {
+	testCases := []struct {
+		out     string
+		version [2]int
+		ok      bool
+	}{
+		{"glibc 2.35\n", [2]int{2, 35}, true},
+		{"ldd (Ubuntu GLIBC 2.35-0ubuntu3.1) 2.35\nCopyright (C) 2022 Free Software Foundation, Inc.\n", [2]int{2, 35}, true},
+		{"ldd (GNU libc) 2.17\n", [2]int{2, 17}, true},
+		{"ldd (GNU libc) 2.38.9000\n", [2]int{2, 38}, true},
+		{"musl libc (x86_64)\nVersion 1.2.4\n", [2]int{}, false},
+		{"glibc\n", [2]int{}, false},
+		{"", [2]int{}, false},
+	}
+
+	for _, tt := range testCases {
+		version, ok := parseGlibcVersion(tt.out)
+		if ok != tt.ok || version != tt.version {
+			t.Errorf("parseGlibcVersion(%q) = %v, %v; expected %v, %v", tt.out, version, ok, tt.version, tt.ok)
+		}
+	}
+}
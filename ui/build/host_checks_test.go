@@ -0,0 +1,92 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestHostCheckFailures(t *testing.T) {
+	ctx := testContext()
+
+	pass := func(ctx Context, config Config) error { return nil }
+	fail := func(ctx Context, config Config) error { return errors.New("failed") }
+	checks := []hostCheck{
+		{name: "a", check: pass},
+		{name: "b", check: fail},
+		{name: "c", warning: true, check: fail},
+	}
+
+	testCases := []struct {
+		description string
+		skip        string
+		warnings    []string
+		errs        []string
+		err         string
+	}{
+		{
+			description: "no skip",
+			warnings:    []string{"failed\n(set SOONG_SKIP_HOST_CHECKS=c to skip this check)"},
+			errs:        []string{"failed\n(set SOONG_SKIP_HOST_CHECKS=b to skip this check)"},
+		},
+		{
+			description: "skip one",
+			skip:        "b",
+			warnings:    []string{"failed\n(set SOONG_SKIP_HOST_CHECKS=c to skip this check)"},
+		},
+		{
+			description: "skip several",
+			skip:        "b, c",
+		},
+		{
+			description: "skip all",
+			skip:        "all",
+		},
+		{
+			description: "unknown check",
+			skip:        "b,d",
+			err:         `Unknown host check "d" in SOONG_SKIP_HOST_CHECKS, valid checks are: all, a, b, c`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			env := Environment{}
+			if tt.skip != "" {
+				env.Set(skipHostChecksEnv, tt.skip)
+			}
+			config := Config{&configImpl{environ: &env}}
+
+			warnings, errs, err := hostCheckFailures(ctx, config, checks)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("expected warnings %q, got %q", tt.warnings, warnings)
+			}
+			if !reflect.DeepEqual(errs, tt.errs) {
+				t.Errorf("expected errors %q, got %q", tt.errs, errs)
+			}
+		})
+	}
+}
@@ -76,7 +76,7 @@ func runKati(ctx Context, config Config, extraSuffix string, args []string, envF
 		// Instead of executing commands directly, generate a Ninja file.
 		"--ninja",
 		// Generate Ninja files in the output directory.
-		"--ninja_dir=" + config.OutDir(),
+		"--ninja_dir=" + config.NinjaStateDir(),
 		// Filename suffix of the generated Ninja file.
 		"--ninja_suffix=" + config.KatiSuffix() + extraSuffix,
 		// Remove common parts at the beginning of a Ninja file, like build_dir,
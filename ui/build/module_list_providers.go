@@ -0,0 +1,128 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+
+	"android/soong/finder"
+	"android/soong/makedeps"
+	"android/soong/ui/metrics"
+)
+
+// This file implements module list providers. A module list provider is a
+// checked-in executable named Android.bp.gen that contributes synthetic
+// Blueprint module definitions at load time. It replaces the pattern of
+// genrules writing Android.bp files into the output directory, which is
+// invisible to the finder and racy with respect to soong_build.
+//
+// Every provider is invoked from the root of the source tree, inside the same
+// sandbox that soong_build runs in, as:
+//
+//   <dir>/Android.bp.gen --dir <dir> --out <output Android.bp> --depfile <depfile>
+//
+// The provider writes Blueprint definitions to the output file and a
+// Make-style depfile listing every input it read. Providers are only re-run
+// when the provider itself or one of the listed inputs changed. The generated
+// files are appended to Android.bp.list so that soong_build parses them like
+// any other Android.bp file. The source paths of the generated modules, e.g.
+// their srcs, are relative to the directory of the provider, not to the
+// directory of the generated file.
+
+const moduleListProviderName = "Android.bp.gen"
+
+// moduleListProviderOutDir returns the directory that holds the Blueprint files
+// written by module list providers. soong_build maps the directories in it back
+// to the directories of the providers, see generatedBpDirName in android.
+func moduleListProviderOutDir(config Config) string {
+	return filepath.Join(config.SoongOutDir(), ".generated_bp")
+}
+
+// runModuleListProviders runs every module list provider found by <f> and
+// returns the paths to the Blueprint files they generated.
+func runModuleListProviders(ctx Context, config Config, f *finder.Finder) []string {
+	providers := f.FindNamedAt(".", moduleListProviderName)
+	if len(providers) == 0 {
+		return nil
+	}
+
+	ctx.BeginTrace(metrics.RunSetupTool, "module list providers")
+	defer ctx.EndTrace()
+
+	var generated []string
+	for _, provider := range providers {
+		dir := filepath.Dir(provider)
+		out := filepath.Join(moduleListProviderOutDir(config), dir, "Android.bp")
+		depfile := out + ".d"
+
+		if stale, err := moduleListProviderStale(provider, out, depfile); err != nil {
+			ctx.Fatalf("Could not check module list provider %s: %v", provider, err)
+		} else if stale {
+			ensureDirectoriesExist(ctx, filepath.Dir(out))
+			cmd := Command(ctx, config, "module list provider "+provider, provider,
+				"--dir", dir, "--out", out, "--depfile", depfile)
+			cmd.Sandbox = soongSandbox
+			cmd.RunAndStreamOrFatal()
+		}
+
+		if _, err := os.Stat(out); err != nil {
+			ctx.Fatalf("Module list provider %s did not write %s: %v", provider, out, err)
+		}
+		generated = append(generated, out)
+	}
+
+	return generated
+}
+
+// moduleListProviderStale returns true if the Blueprint file generated by
+// <provider> needs to be regenerated, because it doesn't exist yet, the
+// provider changed, or one of the inputs recorded in <depfile> changed.
+func moduleListProviderStale(provider, out, depfile string) (bool, error) {
+	outInfo, err := os.Stat(out)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	r, err := os.Open(depfile)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer r.Close()
+
+	deps, err := makedeps.Parse(depfile, r)
+	if err != nil {
+		return false, err
+	}
+
+	for _, input := range append([]string{provider}, deps.Inputs...) {
+		info, err := os.Stat(input)
+		if os.IsNotExist(err) {
+			// A deleted input always forces the provider to run again.
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+		if info.ModTime().After(outInfo.ModTime()) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
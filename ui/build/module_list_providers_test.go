@@ -0,0 +1,81 @@
+// Copyright 2023 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestModuleListProviderStale(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	provider := filepath.Join(tmpDir, "Android.bp.gen")
+	input := filepath.Join(tmpDir, "modules.txt")
+	out := filepath.Join(tmpDir, "out", "Android.bp")
+	depfile := out + ".d"
+
+	writeFile := func(path, contents string, mtime time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkStale := func(name string, expected bool) {
+		t.Helper()
+		stale, err := moduleListProviderStale(provider, out, depfile)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		if stale != expected {
+			t.Errorf("%s: expected stale to be %t, got %t", name, expected, stale)
+		}
+	}
+
+	old := time.Now().Add(-time.Hour)
+	now := time.Now()
+
+	writeFile(provider, "#!/bin/bash", old)
+	writeFile(input, "foo", old)
+	checkStale("missing output", true)
+
+	writeFile(out, "", now)
+	checkStale("missing depfile", true)
+
+	writeFile(depfile, out+": "+input+"\n", now)
+	checkStale("up to date", false)
+
+	writeFile(input, "bar", now.Add(time.Minute))
+	checkStale("changed input", true)
+
+	writeFile(input, "bar", old)
+	writeFile(provider, "#!/bin/sh", now.Add(time.Minute))
+	checkStale("changed provider", true)
+
+	writeFile(provider, "#!/bin/sh", old)
+	if err := os.Remove(input); err != nil {
+		t.Fatal(err)
+	}
+	checkStale("deleted input", true)
+}
@@ -36,7 +36,7 @@ const (
 )
 
 func useNinjaBuildLog(ctx Context, config Config, cmd *Cmd) {
-	ninjaLogFile := filepath.Join(config.OutDir(), ninjaLogFileName)
+	ninjaLogFile := filepath.Join(config.NinjaStateDir(), ninjaLogFileName)
 	data, err := os.ReadFile(ninjaLogFile)
 	var outputBuilder strings.Builder
 	if err == nil {
@@ -67,7 +67,7 @@ func useNinjaBuildLog(ctx Context, config Config, cmd *Cmd) {
 		ctx.Verbosef("There is an error during reading ninja log, so ninja will use empty weight list: %s", err)
 	}
 
-	weightListFile := filepath.Join(config.OutDir(), ninjaWeightListFileName)
+	weightListFile := filepath.Join(config.NinjaStateDir(), ninjaWeightListFileName)
 
 	err = os.WriteFile(weightListFile, []byte(outputBuilder.String()), 0644)
 	if err == nil {
@@ -84,10 +84,14 @@ func runNinjaForBuild(ctx Context, config Config) {
 	ctx.BeginTrace(metrics.PrimaryNinja, "ninja")
 	defer ctx.EndTrace()
 
+	if config.StateScope() != "" {
+		seedNinjaState(ctx, config.OutDir(), config.NinjaStateDir())
+	}
+
 	// Sets up the FIFO status updater that reads the Ninja protobuf output, and
 	// translates it to the soong_ui status output, displaying real-time
 	// progress of the build.
-	fifo := filepath.Join(config.OutDir(), ".ninja_fifo")
+	fifo := filepath.Join(config.NinjaStateDir(), ".ninja_fifo")
 	nr := status.NewNinjaReader(ctx, ctx.Status.StartTool(), fifo)
 	defer nr.Close()
 
@@ -137,8 +141,12 @@ func runNinjaForBuild(ctx Context, config Config) {
 		cmd.Args = append(cmd.Args, "-o", "usesweightlist=/dev/null")
 	case EXTERNAL_FILE:
 		fallthrough
-	case HINT_FROM_SOONG:
+	case SOONG_ACTIONS:
 		// The weight list is already copied/generated.
+		ninjaWeightListPath := filepath.Join(config.NinjaStateDir(), ninjaWeightListFileName)
+		cmd.Args = append(cmd.Args, "-o", "usesweightlist="+ninjaWeightListPath)
+	case HINT_FROM_SOONG:
+		// The weight list is already generated, by soong_build in the output directory.
 		ninjaWeightListPath := filepath.Join(config.OutDir(), ninjaWeightListFileName)
 		cmd.Args = append(cmd.Args, "-o", "usesweightlist="+ninjaWeightListPath)
 	}
@@ -233,6 +241,12 @@ func runNinjaForBuild(ctx Context, config Config) {
 			"CCACHE_BASEDIR",
 			"CCACHE_CPP2",
 			"CCACHE_DIR",
+			"CCACHE_EXEC",
+
+			// sccache settings
+			"SCCACHE_DIR",
+			"SCCACHE_CACHE_SIZE",
+			"SCCACHE_SERVER_PORT",
 
 			// LLVM compiler wrapper options
 			"TOOLCHAIN_RUSAGE_OUTPUT",
@@ -259,7 +273,7 @@ func runNinjaForBuild(ctx Context, config Config) {
 	// Write the file in every single run. This is fine because
 	// 1. It is not a dep of Soong analysis, so will not retrigger Soong analysis.
 	// 2. Is is fairly lightweight (~1Kb)
-	ninjaEnvVarsFile := shared.JoinPath(config.SoongOutDir(), ninjaEnvFileName)
+	ninjaEnvVarsFile := shared.JoinPath(config.SoongStateDir(), ninjaEnvFileName)
 	err = os.WriteFile(ninjaEnvVarsFile, data, 0666)
 	if err != nil {
 		ctx.Panicf("Could not write ninja environment file %s", err)
@@ -273,7 +287,7 @@ func runNinjaForBuild(ctx Context, config Config) {
 	ticker := time.NewTicker(ninjaHeartbeatDuration)
 	defer ticker.Stop()
 	ninjaChecker := &ninjaStucknessChecker{
-		logPath: filepath.Join(config.OutDir(), ninjaLogFileName),
+		logPath: filepath.Join(config.NinjaStateDir(), ninjaLogFileName),
 	}
 	go func() {
 		for {
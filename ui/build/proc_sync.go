@@ -29,11 +29,27 @@ import (
 // This file provides cross-process synchronization methods
 // i.e. making sure only one Soong process is running for a given output directory
 
-func BecomeSingletonOrFail(ctx Context, config Config) (lock *fileLock) {
+// BecomeSingletonOrFail waits until no other Soong process is running in the output directory, and
+// fails if that takes too long. When the state of the build is scoped with SOONG_STATE_SCOPE, the
+// files that the build writes are in the state directory of the scope, and it only reads the
+// shared build outputs. It takes a shared lock of the output directory, which only unscoped builds
+// take exclusively, and an exclusive lock of the state directory instead, so that builds in other
+// scopes may run at the same time.
+func BecomeSingletonOrFail(ctx Context, config Config) (locks fileLocks) {
 	lockingInfo, err := newLock(config.OutDir())
 	if err != nil {
 		ctx.Logger.Fatal(err)
 	}
+	locks = fileLocks{lockingInfo}
+	if config.StateScope() != "" {
+		lockingInfo.Shared = true
+		scopeLock, err := newLock(config.SoongStateDir())
+		if err != nil {
+			ctx.Logger.Fatal(err)
+		}
+		locks = append(locks, scopeLock)
+	}
+
 	lockfilePollDuration := time.Second
 	lockfileTimeout := time.Second * 10
 	if envTimeout := os.Getenv("SOONG_LOCK_TIMEOUT"); envTimeout != "" {
@@ -42,11 +58,13 @@ func BecomeSingletonOrFail(ctx Context, config Config) (lock *fileLock) {
 			ctx.Logger.Fatalf("failure parsing SOONG_LOCK_TIMEOUT %q: %s", envTimeout, err)
 		}
 	}
-	err = lockSynchronous(*lockingInfo, newSleepWaiter(lockfilePollDuration, lockfileTimeout), ctx.Logger)
-	if err != nil {
-		ctx.Logger.Fatal(err)
+	for _, lock := range locks {
+		err = lockSynchronous(*lock, newSleepWaiter(lockfilePollDuration, lockfileTimeout), ctx.Logger)
+		if err != nil {
+			ctx.Logger.Fatal(err)
+		}
 	}
-	return lockingInfo
+	return locks
 }
 
 type lockable interface {
@@ -59,18 +77,37 @@ var _ lockable = (*fileLock)(nil)
 
 type fileLock struct {
 	File *os.File
+	// Whether the lock is shared with other processes that take a shared lock.
+	Shared bool
 }
 
 func (l fileLock) description() (path string) {
 	return l.File.Name()
 }
 func (l fileLock) tryLock() (err error) {
-	return syscall.Flock(int(l.File.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	how := syscall.LOCK_EX
+	if l.Shared {
+		how = syscall.LOCK_SH
+	}
+	return syscall.Flock(int(l.File.Fd()), how|syscall.LOCK_NB)
 }
 func (l fileLock) Unlock() (err error) {
 	return l.File.Close()
 }
 
+// fileLocks are the locks taken by a build.
+type fileLocks []*fileLock
+
+// Unlock releases the locks in the reverse order they were taken.
+func (l fileLocks) Unlock() (err error) {
+	for i := len(l) - 1; i >= 0; i-- {
+		if unlockErr := l[i].Unlock(); err == nil {
+			err = unlockErr
+		}
+	}
+	return err
+}
+
 func lockSynchronous(lock lockable, waiter waiter, logger logger.Logger) (err error) {
 
 	waited := false
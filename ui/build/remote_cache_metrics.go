@@ -0,0 +1,262 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"android/soong/ui/metrics"
+	soong_metrics_proto "android/soong/ui/metrics/metrics_proto"
+	reproxy_log_proto "android/soong/ui/metrics/reproxy_log_proto"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// soongActionMetadataFilename is the name of the file in the Soong output directory that
+	// soong_build writes the cacheability of the actions of the build to when building with RBE.
+	soongActionMetadataFilename = "soong_action_metadata.json"
+
+	// unknownModuleType is the module type of the actions whose outputs are not in the output
+	// directory of a Soong module, like the actions of Make.
+	unknownModuleType = "unknown"
+
+	// reproxyLogDelimiter separates the records in the reproxy logs in the text and reducedtext
+	// formats.
+	reproxyLogDelimiter = "\n\n\n"
+)
+
+// actionMetadata is the cacheability of the actions of a rule, see android.ActionMetadata.
+type actionMetadata struct {
+	Actions                 int `json:"actions"`
+	VolatileInputs          int `json:"volatile_inputs"`
+	NondeterministicOutputs int `json:"nondeterministic_outputs"`
+}
+
+// soongActionMetadata is the content of soong_action_metadata.json, see
+// android.SoongActionMetadata.
+type soongActionMetadata struct {
+	ModuleTypes map[string]struct {
+		Variants int                        `json:"variants"`
+		Rules    map[string]*actionMetadata `json:"rules"`
+	} `json:"module_types"`
+	ModuleOutDirs map[string]string `json:"module_out_dirs"`
+}
+
+// parseReproxyLog parses the records in a reproxy log in the text or reducedtext format, which
+// are LogRecord protos in the text format. The fields that soong_ui doesn't need are discarded.
+func parseReproxyLog(r io.Reader) ([]*reproxy_log_proto.LogRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*reproxy_log_proto.LogRecord
+	unmarshal := prototext.UnmarshalOptions{DiscardUnknown: true}
+	for _, text := range strings.Split(string(data), reproxyLogDelimiter) {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		record := &reproxy_log_proto.LogRecord{}
+		if err := unmarshal.Unmarshal([]byte(text), record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// cacheHit returns whether the outputs of a remote action were found in the remote cache.
+func cacheHit(record *reproxy_log_proto.LogRecord) bool {
+	const cacheHit = reproxy_log_proto.CommandResultStatus_CACHE_HIT
+	return record.GetRemoteMetadata().GetCacheHit() ||
+		record.GetRemoteMetadata().GetResult().GetStatus() == cacheHit ||
+		record.GetResult().GetStatus() == cacheHit
+}
+
+// moduleTypeOfOutput returns the module type of the module whose output directory contains the
+// output.
+func moduleTypeOfOutput(moduleOutDirs map[string]string, execRoot, output string) string {
+	candidates := []string{filepath.Clean(output)}
+	if !filepath.IsAbs(output) && execRoot != "" {
+		candidates = append(candidates, filepath.Join(execRoot, output))
+	}
+	for _, dir := range candidates {
+		for dir != "." && dir != "/" {
+			if moduleType, ok := moduleOutDirs[dir]; ok {
+				return moduleType
+			}
+			dir = filepath.Dir(dir)
+		}
+	}
+	return unknownModuleType
+}
+
+// mergeRemoteCacheMetrics attributes the remote cache hits and misses of the actions to module
+// types, and adds the cacheability of the actions of every module type from soong_build.
+func mergeRemoteCacheMetrics(metadata *soongActionMetadata, records []*reproxy_log_proto.LogRecord) *soong_metrics_proto.RemoteCacheMetrics {
+	byModuleType := make(map[string]*soong_metrics_proto.ModuleTypeRemoteCacheMetrics)
+	get := func(moduleType string) *soong_metrics_proto.ModuleTypeRemoteCacheMetrics {
+		if byModuleType[moduleType] == nil {
+			byModuleType[moduleType] = &soong_metrics_proto.ModuleTypeRemoteCacheMetrics{
+				ModuleType:  proto.String(moduleType),
+				CacheHits:   proto.Uint32(0),
+				CacheMisses: proto.Uint32(0),
+			}
+		}
+		return byModuleType[moduleType]
+	}
+
+	result := &soong_metrics_proto.RemoteCacheMetrics{
+		CacheHits:   proto.Uint32(0),
+		CacheMisses: proto.Uint32(0),
+	}
+	for _, record := range records {
+		outputs := record.GetCommand().GetOutput().GetOutputFiles()
+		if record.RemoteMetadata == nil || len(outputs) == 0 {
+			continue
+		}
+		m := get(moduleTypeOfOutput(metadata.ModuleOutDirs, record.GetCommand().GetExecRoot(), outputs[0]))
+		if cacheHit(record) {
+			*m.CacheHits++
+			*result.CacheHits++
+		} else {
+			*m.CacheMisses++
+			*result.CacheMisses++
+		}
+	}
+
+	for moduleType, typeMetadata := range metadata.ModuleTypes {
+		if len(typeMetadata.Rules) == 0 {
+			continue
+		}
+		m := get(moduleType)
+		for rule, ruleMetadata := range typeMetadata.Rules {
+			m.Rules = append(m.Rules, &soong_metrics_proto.RuleCacheability{
+				Rule:                    proto.String(rule),
+				Actions:                 proto.Uint32(uint32(ruleMetadata.Actions)),
+				VolatileInputs:          proto.Uint32(uint32(ruleMetadata.VolatileInputs)),
+				NondeterministicOutputs: proto.Uint32(uint32(ruleMetadata.NondeterministicOutputs)),
+			})
+		}
+		sort.Slice(m.Rules, func(i, j int) bool {
+			return m.Rules[i].GetRule() < m.Rules[j].GetRule()
+		})
+	}
+
+	for _, m := range byModuleType {
+		result.ModuleTypes = append(result.ModuleTypes, m)
+	}
+	// The module types with the most cache misses first.
+	sort.Slice(result.ModuleTypes, func(i, j int) bool {
+		a, b := result.ModuleTypes[i], result.ModuleTypes[j]
+		if a.GetCacheMisses() != b.GetCacheMisses() {
+			return a.GetCacheMisses() > b.GetCacheMisses()
+		}
+		return a.GetModuleType() < b.GetModuleType()
+	})
+	return result
+}
+
+// readReproxyLogs parses the reproxy logs in the RBE log directory that were written since the
+// given time.
+func readReproxyLogs(config Config, since time.Time) ([]*reproxy_log_proto.LogRecord, error) {
+	var records []*reproxy_log_proto.LogRecord
+	for _, pattern := range []string{"*.rpl", "*.rrpl"} {
+		files, err := filepath.Glob(filepath.Join(config.rbeProxyLogsDir(), pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if info, err := os.Stat(file); err != nil || info.ModTime().Before(since) {
+				continue
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			fileRecords, err := parseReproxyLog(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			records = append(records, fileRecords...)
+		}
+	}
+	return records, nil
+}
+
+// addRemoteCacheMetrics adds the remote cache metrics to the soong_build metrics in the file.
+// soong_build doesn't write its metrics when it doesn't run, in which case the file is created
+// with only the remote cache metrics.
+func addRemoteCacheMetrics(soongBuildMetricsFile string, remoteCacheMetrics *soong_metrics_proto.RemoteCacheMetrics) error {
+	soongBuildMetrics := &soong_metrics_proto.SoongBuildMetrics{}
+	if data, err := ioutil.ReadFile(soongBuildMetricsFile); err == nil {
+		if err := proto.Unmarshal(data, soongBuildMetrics); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", soongBuildMetricsFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	soongBuildMetrics.RemoteCacheMetrics = remoteCacheMetrics
+
+	data, err := proto.Marshal(soongBuildMetrics)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(soongBuildMetricsFile, data, 0666) // a+rw
+}
+
+// WriteRemoteCacheMetrics adds the remote cache hits and misses of a build with RBE by module type
+// to the soong_build metrics, by merging the reproxy logs with the action metadata from
+// soong_build. The RBE proxy must have been shut down, see DumpRBEMetrics.
+func WriteRemoteCacheMetrics(ctx Context, config Config, soongBuildMetricsFile string, since time.Time) {
+	if !config.StartRBE() {
+		return
+	}
+
+	ctx.BeginTrace(metrics.RunShutdownTool, "write_remote_cache_metrics")
+	defer ctx.EndTrace()
+
+	metadata := &soongActionMetadata{}
+	data, err := ioutil.ReadFile(filepath.Join(config.SoongOutDir(), soongActionMetadataFilename))
+	if err != nil {
+		ctx.Verbosef("Failed to read the soong action metadata: %v", err)
+		return
+	}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		ctx.Verbosef("Failed to parse the soong action metadata: %v", err)
+		return
+	}
+
+	records, err := readReproxyLogs(config, since)
+	if err != nil {
+		ctx.Verbosef("Failed to read the reproxy logs: %v", err)
+		return
+	}
+
+	if err := addRemoteCacheMetrics(soongBuildMetricsFile, mergeRemoteCacheMetrics(metadata, records)); err != nil {
+		ctx.Verbosef("Failed to write the remote cache metrics: %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	soong_metrics_proto "android/soong/ui/metrics/metrics_proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const testReproxyLog = `command: {
+  identifiers: {
+    command_id: "1"
+    tool_name: "re-client"
+  }
+  exec_root: "/src"
+  output: {
+    output_files: "out/soong/.intermediates/foo/libfoo/android_arm64_static/obj/foo.o"
+    output_files: "out/soong/.intermediates/foo/libfoo/android_arm64_static/obj/foo.o.d"
+  }
+}
+result: {
+  status: CACHE_HIT
+}
+remote_metadata: {
+  result: {
+    status: CACHE_HIT
+  }
+  cache_hit: true
+}
+
+
+command: {
+  exec_root: "/src"
+  output: {
+    output_files: "out/soong/.intermediates/bar/bar/android_common/javac/bar.jar"
+  }
+}
+result: {
+  status: SUCCESS
+}
+remote_metadata: {
+  result: {
+    status: SUCCESS
+  }
+}
+
+
+command: {
+  exec_root: "/src"
+  output: {
+    output_files:  "out/target/product/generic/obj/baz.o"
+  }
+}
+result: {
+  status: SUCCESS
+}
+remote_metadata: {
+  cache_hit: false
+}
+
+
+command: {
+  exec_root: "/src"
+  output: {
+    output_files: "out/soong/.intermediates/bar/bar/android_common/local.txt"
+  }
+}
+result: {
+  status: SUCCESS
+}
+local_metadata: {
+  executed_locally: true
+}
+`
+
+func TestParseReproxyLog(t *testing.T) {
+	records, err := parseReproxyLog(strings.NewReader(testReproxyLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type action struct {
+		execRoot string
+		outputs  []string
+		remote   bool
+		cacheHit bool
+	}
+	var actions []action
+	for _, record := range records {
+		actions = append(actions, action{
+			execRoot: record.GetCommand().GetExecRoot(),
+			outputs:  record.GetCommand().GetOutput().GetOutputFiles(),
+			remote:   record.RemoteMetadata != nil,
+			cacheHit: cacheHit(record),
+		})
+	}
+
+	expected := []action{
+		{
+			execRoot: "/src",
+			outputs: []string{
+				"out/soong/.intermediates/foo/libfoo/android_arm64_static/obj/foo.o",
+				"out/soong/.intermediates/foo/libfoo/android_arm64_static/obj/foo.o.d",
+			},
+			remote:   true,
+			cacheHit: true,
+		},
+		{
+			execRoot: "/src",
+			outputs:  []string{"out/soong/.intermediates/bar/bar/android_common/javac/bar.jar"},
+			remote:   true,
+		},
+		{
+			execRoot: "/src",
+			outputs:  []string{"out/target/product/generic/obj/baz.o"},
+			remote:   true,
+		},
+		{
+			execRoot: "/src",
+			outputs:  []string{"out/soong/.intermediates/bar/bar/android_common/local.txt"},
+		},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actions)
+	}
+}
+
+func TestParseReproxyLogError(t *testing.T) {
+	_, err := parseReproxyLog(strings.NewReader(testReproxyLog + "\n\n\nresult: {\n  status: NOT_A_STATUS\n}\n"))
+	if err == nil {
+		t.Error("expected an error for an invalid record")
+	}
+}
+
+func TestMergeRemoteCacheMetrics(t *testing.T) {
+	records, err := parseReproxyLog(strings.NewReader(testReproxyLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := &soongActionMetadata{
+		ModuleOutDirs: map[string]string{
+			"out/soong/.intermediates/foo/libfoo/android_arm64_static": "cc_library",
+			"/src/out/soong/.intermediates/bar/bar/android_common":     "java_library",
+		},
+	}
+	metadata.ModuleTypes = map[string]struct {
+		Variants int                        `json:"variants"`
+		Rules    map[string]*actionMetadata `json:"rules"`
+	}{
+		"cc_library": {
+			Variants: 1,
+			Rules: map[string]*actionMetadata{
+				"android/soong/cc.cc": {Actions: 1},
+			},
+		},
+		"java_library": {
+			Variants: 1,
+			Rules: map[string]*actionMetadata{
+				"javac":   {Actions: 1, VolatileInputs: 1},
+				"turbine": {Actions: 1, NondeterministicOutputs: 1},
+			},
+		},
+		"filegroup": {
+			Variants: 1,
+		},
+	}
+
+	expected := &soong_metrics_proto.RemoteCacheMetrics{
+		CacheHits:   proto.Uint32(1),
+		CacheMisses: proto.Uint32(2),
+		ModuleTypes: []*soong_metrics_proto.ModuleTypeRemoteCacheMetrics{
+			{
+				ModuleType:  proto.String("java_library"),
+				CacheHits:   proto.Uint32(0),
+				CacheMisses: proto.Uint32(1),
+				Rules: []*soong_metrics_proto.RuleCacheability{
+					{
+						Rule:                    proto.String("javac"),
+						Actions:                 proto.Uint32(1),
+						VolatileInputs:          proto.Uint32(1),
+						NondeterministicOutputs: proto.Uint32(0),
+					},
+					{
+						Rule:                    proto.String("turbine"),
+						Actions:                 proto.Uint32(1),
+						VolatileInputs:          proto.Uint32(0),
+						NondeterministicOutputs: proto.Uint32(1),
+					},
+				},
+			},
+			{
+				ModuleType:  proto.String(unknownModuleType),
+				CacheHits:   proto.Uint32(0),
+				CacheMisses: proto.Uint32(1),
+			},
+			{
+				ModuleType:  proto.String("cc_library"),
+				CacheHits:   proto.Uint32(1),
+				CacheMisses: proto.Uint32(0),
+				Rules: []*soong_metrics_proto.RuleCacheability{
+					{
+						Rule:                    proto.String("android/soong/cc.cc"),
+						Actions:                 proto.Uint32(1),
+						VolatileInputs:          proto.Uint32(0),
+						NondeterministicOutputs: proto.Uint32(0),
+					},
+				},
+			},
+		},
+	}
+	if got := mergeRemoteCacheMetrics(metadata, records); !proto.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestAddRemoteCacheMetrics(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "soong_build_metrics.pb")
+	remoteCacheMetrics := &soong_metrics_proto.RemoteCacheMetrics{
+		CacheHits:   proto.Uint32(3),
+		CacheMisses: proto.Uint32(1),
+	}
+
+	check := func(expected *soong_metrics_proto.SoongBuildMetrics) {
+		t.Helper()
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := &soong_metrics_proto.SoongBuildMetrics{}
+		if err := proto.Unmarshal(data, got); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	}
+
+	// Without metrics from soong_build.
+	if err := addRemoteCacheMetrics(file, remoteCacheMetrics); err != nil {
+		t.Fatal(err)
+	}
+	check(&soong_metrics_proto.SoongBuildMetrics{RemoteCacheMetrics: remoteCacheMetrics})
+
+	// The metrics from soong_build are kept.
+	data, err := proto.Marshal(&soong_metrics_proto.SoongBuildMetrics{Modules: proto.Uint32(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := addRemoteCacheMetrics(file, remoteCacheMetrics); err != nil {
+		t.Fatal(err)
+	}
+	check(&soong_metrics_proto.SoongBuildMetrics{
+		Modules:            proto.Uint32(10),
+		RemoteCacheMetrics: remoteCacheMetrics,
+	})
+}
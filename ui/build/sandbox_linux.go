@@ -29,6 +29,9 @@ type Sandbox struct {
 	DisableWhenUsingGoma bool
 
 	AllowBuildBrokenUsesNetwork bool
+
+	// Whether the commands use the compiler cache.
+	AllowCompilerCache bool
 }
 
 var (
@@ -45,6 +48,7 @@ var (
 		DisableWhenUsingGoma: true,
 
 		AllowBuildBrokenUsesNetwork: true,
+		AllowCompilerCache:          true,
 	}
 )
 
@@ -232,6 +236,10 @@ func (c *Cmd) wrapSandbox() {
 		sandboxArgs = append(sandboxArgs, "-B", ccacheDir)
 	}
 
+	if c.Sandbox.AllowCompilerCache {
+		sandboxArgs = append(sandboxArgs, compilerCacheSandboxArgs(c.config)...)
+	}
+
 	// Stop nsjail from parsing arguments
 	sandboxArgs = append(sandboxArgs, "--")
 
@@ -15,11 +15,13 @@
 package build
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"android/soong/bazel"
 	"android/soong/ui/metrics"
@@ -43,6 +45,14 @@ const (
 	queryviewTag         = "queryview"
 	apiBp2buildTag       = "api_bp2build"
 	soongDocsTag         = "soong_docs"
+	impactTag            = "impact"
+	explainTag           = "explain"
+	queryTag             = "query"
+	listModulesTag       = "list_modules"
+	mutatorPipelineTag   = "mutator_pipeline"
+	configDumpTag        = "dump_config"
+	moduleDepsTag        = "module_deps"
+	dependencyCyclesTag  = "dependency_cycles"
 
 	// bootstrapEpoch is used to determine if an incremental build is incompatible with the current
 	// version of bootstrap and needs cleaning before continuing the build.  Increment this for
@@ -122,7 +132,7 @@ func (c BlueprintConfig) PrimaryBuilderInvocations() []bootstrap.PrimaryBuilderI
 
 func environmentArgs(config Config, tag string) []string {
 	return []string{
-		"--available_env", shared.JoinPath(config.SoongOutDir(), availableEnvFile),
+		"--available_env", shared.JoinPath(config.SoongStateDir(), availableEnvFile),
 		"--used_env", config.UsedEnvFile(tag),
 	}
 }
@@ -196,7 +206,7 @@ func (pb PrimaryBuilderFactory) primaryBuilderInvocation() bootstrap.PrimaryBuil
 	var allArgs []string
 	allArgs = append(allArgs, pb.specificArgs...)
 	allArgs = append(allArgs,
-		"--globListDir", pb.name,
+		"--globListDir", pb.config.GlobListDir(pb.name),
 		"--globFile", pb.config.NamedGlobFile(pb.name))
 
 	allArgs = append(allArgs, commonArgs...)
@@ -229,13 +239,13 @@ func (pb PrimaryBuilderFactory) primaryBuilderInvocation() bootstrap.PrimaryBuil
 // .soong.bootstrap.epoch.<epoch> file doesn't exist.
 func bootstrapEpochCleanup(ctx Context, config Config) {
 	epochFile := fmt.Sprintf(".soong.bootstrap.epoch.%d", bootstrapEpoch)
-	epochPath := filepath.Join(config.SoongOutDir(), epochFile)
+	epochPath := filepath.Join(config.SoongStateDir(), epochFile)
 	if exists, err := fileExists(epochPath); err != nil {
 		ctx.Fatalf("failed to check if bootstrap epoch file %q exists: %q", epochPath, err)
 	} else if !exists {
 		// The tree is out of date for the current epoch, delete files used by bootstrap
 		// and force the primary builder to rerun.
-		os.Remove(filepath.Join(config.SoongOutDir(), "build.ninja"))
+		os.Remove(config.SoongNinjaFile())
 		for _, globFile := range bootstrapGlobFileList(config) {
 			os.Remove(globFile)
 		}
@@ -253,6 +263,14 @@ func bootstrapGlobFileList(config Config) []string {
 		config.NamedGlobFile(queryviewTag),
 		config.NamedGlobFile(apiBp2buildTag),
 		config.NamedGlobFile(soongDocsTag),
+		config.NamedGlobFile(impactTag),
+		config.NamedGlobFile(explainTag),
+		config.NamedGlobFile(queryTag),
+		config.NamedGlobFile(listModulesTag),
+		config.NamedGlobFile(mutatorPipelineTag),
+		config.NamedGlobFile(configDumpTag),
+		config.NamedGlobFile(moduleDepsTag),
+		config.NamedGlobFile(dependencyCyclesTag),
 	}
 }
 
@@ -279,6 +297,9 @@ func bootstrapBlueprint(ctx Context, config Config) {
 	if config.IsPersistentBazelEnabled() {
 		mainSoongBuildExtraArgs = append(mainSoongBuildExtraArgs, "--use-bazel-proxy")
 	}
+	if config.forceBazelRequery {
+		mainSoongBuildExtraArgs = append(mainSoongBuildExtraArgs, "--force_bazel_requery")
+	}
 	if len(config.bazelForceEnabledModules) > 0 {
 		mainSoongBuildExtraArgs = append(mainSoongBuildExtraArgs, "--bazel-force-enabled-modules="+config.bazelForceEnabledModules)
 	}
@@ -288,8 +309,19 @@ func bootstrapBlueprint(ctx Context, config Config) {
 	if config.buildFromTextStub {
 		mainSoongBuildExtraArgs = append(mainSoongBuildExtraArgs, "--build-from-text-stub")
 	}
+	if logModules, ok := config.Environment().Get("SOONG_LOG_MODULES"); ok && logModules != "" {
+		mainSoongBuildExtraArgs = append(mainSoongBuildExtraArgs, "--log_modules", logModules)
+	}
+	if config.NinjaWeightListSource() == SOONG_ACTIONS {
+		mainSoongBuildExtraArgs = append(mainSoongBuildExtraArgs, "--ninja_weight_file",
+			filepath.Join(config.NinjaStateDir(), ninjaWeightListFileName))
+	}
 
 	queryviewDir := filepath.Join(config.SoongOutDir(), "queryview")
+	queryviewArgs := []string{"--bazel_queryview_dir", queryviewDir}
+	if filter, ok := config.Environment().Get("SOONG_QUERYVIEW_FILTER"); ok && filter != "" {
+		queryviewArgs = append(queryviewArgs, "--bazel_queryview_filter", filter)
+	}
 	// The BUILD files will be generated in out/soong/.api_bp2build (no symlinks to src files)
 	// The final workspace will be generated in out/soong/api_bp2build
 	apiBp2buildDir := filepath.Join(config.SoongOutDir(), ".api_bp2build")
@@ -331,7 +363,35 @@ func bootstrapBlueprint(ctx Context, config Config) {
 			description:  fmt.Sprintf("generating the Soong module graph as a Bazel workspace at %s", queryviewDir),
 			config:       config,
 			output:       config.QueryviewMarkerFile(),
-			specificArgs: []string{"--bazel_queryview_dir", queryviewDir},
+			specificArgs: queryviewArgs,
+		},
+		{
+			name:         mutatorPipelineTag,
+			description:  fmt.Sprintf("writing the mutator pipeline at %s", config.MutatorPipelineFile()),
+			config:       config,
+			output:       config.MutatorPipelineFile(),
+			specificArgs: []string{"--mutator_pipeline_file", config.MutatorPipelineFile()},
+		},
+		{
+			name:         configDumpTag,
+			description:  fmt.Sprintf("writing the product configuration at %s", config.ConfigDumpFile()),
+			config:       config,
+			output:       config.ConfigDumpFile(),
+			specificArgs: []string{"--dump_config", config.ConfigDumpFile()},
+		},
+		{
+			name:         moduleDepsTag,
+			description:  fmt.Sprintf("writing the reverse dependencies of the modules at %s", config.ModuleDepsFile()),
+			config:       config,
+			output:       config.ModuleDepsFile(),
+			specificArgs: []string{"--module_deps_file", config.ModuleDepsFile()},
+		},
+		{
+			name:         dependencyCyclesTag,
+			description:  "explaining the dependency cycles between modules",
+			config:       config,
+			output:       config.DependencyCyclesFile(),
+			specificArgs: []string{"--dependency_cycles_file", config.DependencyCyclesFile()},
 		},
 		{
 			name:         apiBp2buildTag,
@@ -349,6 +409,62 @@ func bootstrapBlueprint(ctx Context, config Config) {
 		},
 	}
 
+	if config.Impact() {
+		impactOf, ok := config.Environment().Get("SOONG_IMPACT_OF")
+		if !ok || impactOf == "" {
+			ctx.Fatalln("SOONG_IMPACT_OF must be set to the source file whose impact to write")
+		}
+		pbfs = append(pbfs, PrimaryBuilderFactory{
+			name:         impactTag,
+			description:  fmt.Sprintf("writing the impact of a change to %s at %s", impactOf, config.ImpactFile()),
+			config:       config,
+			output:       config.ImpactFile(),
+			specificArgs: []string{"--impact_of", impactOf, "--impact_file", config.ImpactFile()},
+		})
+	}
+
+	if config.Explain() {
+		explain, ok := config.Environment().Get("SOONG_EXPLAIN")
+		if !ok || explain == "" {
+			ctx.Fatalln("SOONG_EXPLAIN must be set to the output file whose action to write")
+		}
+		pbfs = append(pbfs, PrimaryBuilderFactory{
+			name:         explainTag,
+			description:  fmt.Sprintf("writing the action of %s at %s", explain, config.ExplainFile()),
+			config:       config,
+			output:       config.ExplainFile(),
+			specificArgs: []string{"--explain", explain, "--explain_file", config.ExplainFile()},
+		})
+	}
+
+	if config.Query() {
+		query, ok := config.Environment().Get("SOONG_QUERY")
+		if !ok || query == "" {
+			ctx.Fatalln("SOONG_QUERY must be set to the query of the module graph to evaluate")
+		}
+		pbfs = append(pbfs, PrimaryBuilderFactory{
+			name:         queryTag,
+			description:  fmt.Sprintf("writing the modules matching %s at %s", query, config.QueryFile()),
+			config:       config,
+			output:       config.QueryFile(),
+			specificArgs: []string{"--query", query, "--query_file", config.QueryFile()},
+		})
+	}
+
+	if config.ListModules() {
+		listModulesArgs := []string{"--list_modules", "--list_modules_file", config.ListModulesFile()}
+		if filter, ok := config.Environment().Get("SOONG_LIST_MODULES_FILTER"); ok && filter != "" {
+			listModulesArgs = append(listModulesArgs, "--list_modules_filter", filter)
+		}
+		pbfs = append(pbfs, PrimaryBuilderFactory{
+			name:         listModulesTag,
+			description:  fmt.Sprintf("writing the list of modules at %s", config.ListModulesFile()),
+			config:       config,
+			output:       config.ListModulesFile(),
+			specificArgs: listModulesArgs,
+		})
+	}
+
 	// Figure out which invocations will be run under the debugger:
 	//   * SOONG_DELVE if set specifies listening port
 	//   * SOONG_DELVE_STEPS if set specifies specific invocations to be debugged, otherwise all are
@@ -411,8 +527,8 @@ func bootstrapBlueprint(ctx Context, config Config) {
 	}
 
 	blueprintArgs := bootstrap.Args{
-		ModuleListFile: filepath.Join(config.FileListDir(), "Android.bp.list"),
-		OutFile:        shared.JoinPath(config.SoongOutDir(), "bootstrap.ninja"),
+		ModuleListFile: androidBpListFile(config),
+		OutFile:        config.BootstrapNinjaFile(),
 		EmptyNinjaFile: false,
 	}
 
@@ -431,9 +547,46 @@ func bootstrapBlueprint(ctx Context, config Config) {
 		primaryBuilderInvocations: invocations,
 	}
 
+	var reuseKey string
+	if reuseBootstrap(config) {
+		key, err := json.Marshal(struct {
+			Epoch       int
+			Args        bootstrap.Args
+			RunGoTests  bool
+			Debug       bool
+			Subninjas   []string
+			Invocations []bootstrap.PrimaryBuilderInvocation
+		}{bootstrapEpoch, blueprintArgs, blueprintConfig.runGoTests, blueprintConfig.debugCompilation,
+			blueprintConfig.subninjas, invocations})
+		if err != nil {
+			ctx.Fatalf("failed to marshal the soong_build invocations: %s", err)
+		}
+		reuseKey = string(key)
+		if canReuseBootstrap(ctx, config, reuseKey) {
+			ctx.Verboseln("Reusing bootstrap.ninja, SOONG_REUSE_BOOTSTRAP is set and its inputs didn't change")
+			return
+		}
+	}
+
 	// since `bootstrap.ninja` is regenerated unconditionally, we ignore the deps, i.e. little
 	// reason to write a `bootstrap.ninja.d` file
 	_ = bootstrap.RunBlueprint(blueprintArgs, bootstrap.DoEverything, blueprintCtx, blueprintConfig)
+
+	if reuseBootstrap(config) {
+		// Only the Go packages and binaries are known to the bootstrap, other module types are
+		// ignored.
+		var dirs []string
+		blueprintCtx.VisitAllModules(func(m blueprint.Module) {
+			dirs = append(dirs, blueprintCtx.ModuleDir(m))
+		})
+		var globs []bootstrapGlob
+		for _, glob := range blueprintCtx.Globs() {
+			globs = append(globs, bootstrapGlob{Pattern: glob.Pattern, Excludes: glob.Excludes})
+		}
+		recordBootstrap(ctx, config, reuseKey, dirs, globs)
+	} else {
+		os.Remove(filepath.Join(config.SoongStateDir(), bootstrapReuseFile))
+	}
 }
 
 func checkEnvironmentFile(currentEnv *Environment, envFile string) {
@@ -447,6 +600,29 @@ func checkEnvironmentFile(currentEnv *Environment, envFile string) {
 	}
 }
 
+// linkSecondaryTree points the link in the Soong output directory that soong_build resolves the
+// modules imported from the secondary tree through to SOONG_SECONDARY_TREE, or removes it if
+// there is no secondary tree.
+func linkSecondaryTree(ctx Context, config Config) {
+	link := config.SecondaryTreeLink()
+	target := config.SecondaryTree()
+	if current, err := os.Readlink(link); err == nil && current == target {
+		return
+	}
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		ctx.Fatalf("Failed to remove %s: %v", link, err)
+	}
+	if target == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(link), 0777); err != nil {
+		ctx.Fatalf("Failed to create %s: %v", filepath.Dir(link), err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		ctx.Fatalf("Failed to link %s to SOONG_SECONDARY_TREE: %v", link, err)
+	}
+}
+
 func runSoong(ctx Context, config Config) {
 	ctx.BeginTrace(metrics.RunSoong, "soong")
 	defer ctx.EndTrace()
@@ -455,11 +631,13 @@ func runSoong(ctx Context, config Config) {
 	// .used with the ones that were actually used. The latter is used to
 	// determine whether Soong needs to be re-run since why re-run it if only
 	// unused variables were changed?
-	envFile := filepath.Join(config.SoongOutDir(), availableEnvFile)
+	envFile := filepath.Join(config.SoongStateDir(), availableEnvFile)
 
 	// This is done unconditionally, but does not take a measurable amount of time
 	bootstrapBlueprint(ctx, config)
 
+	linkSecondaryTree(ctx, config)
+
 	soongBuildEnv := config.Environment().Copy()
 	soongBuildEnv.Set("TOP", os.Getenv("TOP"))
 	// For Bazel mixed builds.
@@ -508,12 +686,41 @@ func runSoong(ctx Context, config Config) {
 		if config.SoongDocs() {
 			checkEnvironmentFile(soongBuildEnv, config.UsedEnvFile(soongDocsTag))
 		}
+
+		if config.Impact() {
+			checkEnvironmentFile(soongBuildEnv, config.UsedEnvFile(impactTag))
+		}
+
+		if config.Explain() {
+			checkEnvironmentFile(soongBuildEnv, config.UsedEnvFile(explainTag))
+		}
+
+		if config.Query() {
+			checkEnvironmentFile(soongBuildEnv, config.UsedEnvFile(queryTag))
+		}
+
+		if config.ListModules() {
+			checkEnvironmentFile(soongBuildEnv, config.UsedEnvFile(listModulesTag))
+		}
+
+		if config.MutatorPipeline() {
+			checkEnvironmentFile(soongBuildEnv, config.UsedEnvFile(mutatorPipelineTag))
+		}
+
+		if config.ConfigDump() {
+			checkEnvironmentFile(soongBuildEnv, config.UsedEnvFile(configDumpTag))
+		}
+
+		if config.ModuleDeps() {
+			checkEnvironmentFile(soongBuildEnv, config.UsedEnvFile(moduleDepsTag))
+		}
 	}()
 
 	runMicrofactory(ctx, config, "bpglob", "github.com/google/blueprint/bootstrap/bpglob",
 		map[string]string{"github.com/google/blueprint": "build/blueprint"})
 
-	ninja := func(name, ninjaFile string, targets ...string) {
+	// ninja runs the targets of ninjaFile, and returns the command and its error.
+	ninja := func(name, ninjaFile string, targets ...string) (*Cmd, error) {
 		ctx.BeginTrace(metrics.RunSoong, name)
 		defer ctx.EndTrace()
 
@@ -523,7 +730,7 @@ func runSoong(ctx Context, config Config) {
 			defer bazelProxy.Close()
 		}
 
-		fifo := filepath.Join(config.OutDir(), ".ninja_fifo")
+		fifo := filepath.Join(config.NinjaStateDir(), ".ninja_fifo")
 		nr := status.NewNinjaReader(ctx, ctx.Status.StartTool(), fifo)
 		defer nr.Close()
 
@@ -537,7 +744,7 @@ func runSoong(ctx Context, config Config) {
 			"-w", "missingoutfile=err",
 			"-j", strconv.Itoa(config.Parallel()),
 			"--frontend_file", fifo,
-			"-f", filepath.Join(config.SoongOutDir(), ninjaFile),
+			"-f", ninjaFile,
 		}
 
 		if extra, ok := config.Environment().Get("SOONG_UI_NINJA_ARGS"); ok {
@@ -557,7 +764,7 @@ func runSoong(ctx Context, config Config) {
 
 		cmd.Environment = &ninjaEnv
 		cmd.Sandbox = soongSandbox
-		cmd.RunAndStreamOrFatal()
+		return cmd, cmd.RunAndStream()
 	}
 
 	targets := make([]string, 0, 0)
@@ -582,12 +789,56 @@ func runSoong(ctx Context, config Config) {
 		targets = append(targets, config.SoongDocsHtml())
 	}
 
+	if config.Impact() {
+		targets = append(targets, config.ImpactFile())
+	}
+
+	if config.Explain() {
+		targets = append(targets, config.ExplainFile())
+	}
+
+	if config.Query() {
+		targets = append(targets, config.QueryFile())
+	}
+
+	if config.ListModules() {
+		targets = append(targets, config.ListModulesFile())
+	}
+
+	if config.MutatorPipeline() {
+		targets = append(targets, config.MutatorPipelineFile())
+	}
+
+	if config.ConfigDump() {
+		targets = append(targets, config.ConfigDumpFile())
+	}
+
+	if config.ModuleDeps() {
+		targets = append(targets, config.ModuleDepsFile())
+	}
+
 	if config.SoongBuildInvocationNeeded() {
 		// This build generates <builddir>/build.ninja, which is used later by build/soong/ui/build/build.go#Build().
 		targets = append(targets, config.SoongNinjaFile())
 	}
 
-	ninja("bootstrap", "bootstrap.ninja", targets...)
+	bootstrapNinjaFile := config.BootstrapNinjaFile()
+	if config.StateScope() != "" {
+		// Run bootstrap.ninja with the build directory of the scope, so that builds in other scopes
+		// don't write the same .ninja_log while soong_build runs.
+		seedNinjaState(ctx, config.SoongOutDir(), config.BootstrapNinjaBuildDir())
+		bootstrapNinjaFile = scopedBootstrapNinjaFile(ctx, config)
+	}
+
+	cycles := &dependencyCycleOutput{}
+	ctx.Status.AddOutput(cycles)
+	cmd, err := ninja("bootstrap", bootstrapNinjaFile, targets...)
+	if err != nil && cycles.found.Load() {
+		// soong_build exits on the first errors of blueprint, so explain the dependency cycles with
+		// another soong_build invocation before failing. It fails too, with the explanation.
+		ninja("dependency cycles", bootstrapNinjaFile, config.DependencyCyclesFile())
+	}
+	cmd.reportError(err)
 
 	distGzipFile(ctx, config, config.SoongNinjaFile(), "soong")
 	distFile(ctx, config, config.SoongVarsFile(), "soong")
@@ -602,6 +853,26 @@ func runSoong(ctx Context, config Config) {
 	}
 }
 
+// dependencyCycleOutput is a status.StatusOutput that records whether an action failed with a
+// dependency cycle between modules.
+type dependencyCycleOutput struct {
+	found atomic.Bool
+}
+
+func (d *dependencyCycleOutput) StartAction(action *status.Action, counts status.Counts) {}
+
+func (d *dependencyCycleOutput) FinishAction(result status.ActionResult, counts status.Counts) {
+	if result.Error != nil && strings.Contains(result.Output, "encountered dependency cycle") {
+		d.found.Store(true)
+	}
+}
+
+func (d *dependencyCycleOutput) Message(level status.MsgLevel, msg string) {}
+
+func (d *dependencyCycleOutput) Flush() {}
+
+func (d *dependencyCycleOutput) Write(p []byte) (n int, err error) { return len(p), nil }
+
 func runMicrofactory(ctx Context, config Config, name string, pkg string, mapping map[string]string) {
 	ctx.BeginTrace(metrics.RunSoong, name)
 	defer ctx.EndTrace()
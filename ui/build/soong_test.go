@@ -0,0 +1,51 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLinkSecondaryTree(t *testing.T) {
+	ctx := testContext()
+	outDir := t.TempDir()
+	secondaryTree := t.TempDir()
+	otherTree := t.TempDir()
+
+	newConfig := func(tree string) Config {
+		return Config{&configImpl{
+			environ:       &Environment{"OUT_DIR=" + outDir},
+			secondaryTree: tree,
+		}}
+	}
+	link := filepath.Join(outDir, "soong", "multitree", "secondary")
+
+	linkSecondaryTree(ctx, newConfig(secondaryTree))
+	if target, err := os.Readlink(link); err != nil || target != secondaryTree {
+		t.Errorf("expected %s to link to %s, got %q, %v", link, secondaryTree, target, err)
+	}
+
+	linkSecondaryTree(ctx, newConfig(otherTree))
+	if target, err := os.Readlink(link); err != nil || target != otherTree {
+		t.Errorf("expected %s to link to %s, got %q, %v", link, otherTree, target, err)
+	}
+
+	linkSecondaryTree(ctx, newConfig(""))
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", link, err)
+	}
+}
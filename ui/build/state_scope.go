@@ -0,0 +1,161 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// On build farms several users or branches may share an OUT_DIR whose build outputs are built once
+// and then only read. SOONG_STATE_SCOPE, e.g. set to "$USER-$BRANCH", gives each of them its own
+// copy of everything that soong_ui and soong_build write for a build, other than the build outputs
+// themselves: the environment files, the marker files of the soong_build invocations, the ninja
+// files of soong_build and Kati, the glob files, the temporary directory and the .ninja_log and
+// .ninja_deps of the ninja invocations. They are kept in
+// $OUT_DIR/soong/scopes/<scope> instead of $OUT_DIR/soong and $OUT_DIR, so that a build in one
+// scope doesn't invalidate or corrupt the state of the others, e.g. when it regenerates build.ninja
+// with its own environment.
+//
+// The file lists of the finder only depend on the source tree, so they stay shared, and are
+// replaced instead of rewritten in place so that other scopes never read a partial list.
+//
+// A scoped build only reads the shared build outputs, so it takes a shared lock of the OUT_DIR and
+// an exclusive lock of its scope: builds in different scopes run concurrently, while builds in the
+// same scope and unscoped builds, which write the shared outputs, wait for each other.
+//
+// The first build in a scope starts from a copy of the .ninja_log and .ninja_deps of the unscoped
+// builds, so that ninja doesn't consider the shared outputs out of date only because the scope
+// has no record of the commands that built them.
+
+const stateScopeEnv = "SOONG_STATE_SCOPE"
+
+var stateScopeRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateStateScope returns an error if scope can't be used as the name of a directory of scoped
+// state. An empty scope is valid, it disables the scoping.
+func validateStateScope(scope string) error {
+	if scope != "" && !stateScopeRegexp.MatchString(scope) {
+		return fmt.Errorf("invalid %s %q: it must start with a letter or a digit, and only contain letters, digits, '.', '_' and '-'",
+			stateScopeEnv, scope)
+	}
+	return nil
+}
+
+// StateScope returns the scope of the writable Soong state of this build, or an empty string if
+// the state isn't scoped.
+func (c *configImpl) StateScope() string {
+	scope, _ := c.environ.Get(stateScopeEnv)
+	return scope
+}
+
+// SoongStateDir returns the directory of the writable Soong state of this build, which is the
+// Soong output directory unless the state is scoped.
+func (c *configImpl) SoongStateDir() string {
+	if scope := c.StateScope(); scope != "" {
+		return filepath.Join(c.SoongOutDir(), "scopes", scope)
+	}
+	return c.SoongOutDir()
+}
+
+// GlobListDir returns the directory of the glob lists of the soong_build invocation name, relative
+// to the glob directory of the Soong output directory.
+func (c *configImpl) GlobListDir(name string) string {
+	if scope := c.StateScope(); scope != "" {
+		return filepath.Join("scopes", scope, name)
+	}
+	return name
+}
+
+// NinjaStateDir returns the directory of the combined and Kati ninja files of this build, which is
+// also the build directory of the ninja that runs them and holds its .ninja_log. It is the output
+// directory unless the state is scoped.
+func (c *configImpl) NinjaStateDir() string {
+	if c.StateScope() != "" {
+		return c.SoongStateDir()
+	}
+	return c.OutDir()
+}
+
+// BootstrapNinjaBuildDir returns the build directory of the ninja that runs bootstrap.ninja, which
+// holds its .ninja_log. It is the Soong output directory, where bootstrap.ninja puts it, unless the
+// state is scoped.
+func (c *configImpl) BootstrapNinjaBuildDir() string {
+	if c.StateScope() != "" {
+		return filepath.Join(c.SoongStateDir(), "bootstrap")
+	}
+	return c.SoongOutDir()
+}
+
+// scopedBootstrapNinjaFile writes the ninja file that runs bootstrap.ninja with the build
+// directory of the scope, and returns it. bootstrap.ninja sets the build directory to the shared
+// Soong output directory, but only the build directory of the top level ninja file is used.
+func scopedBootstrapNinjaFile(ctx Context, config Config) string {
+	buildDir := config.BootstrapNinjaBuildDir()
+	file := filepath.Join(buildDir, "bootstrap.scope.ninja")
+	contents := fmt.Sprintf("builddir = %s\nsubninja %s\n", buildDir, config.BootstrapNinjaFile())
+	if err := os.MkdirAll(buildDir, 0777); err != nil {
+		ctx.Fatalf("failed to create %s: %s", buildDir, err)
+	}
+	if err := os.WriteFile(file, []byte(contents), 0666); err != nil {
+		ctx.Fatalf("failed to write %s: %s", file, err)
+	}
+	return file
+}
+
+// seedNinjaState copies the .ninja_log and .ninja_deps of the unscoped builds in from into the
+// build directory to of a scope that doesn't have them yet.
+func seedNinjaState(ctx Context, from, to string) {
+	for _, name := range []string{ninjaLogFileName, ".ninja_deps"} {
+		dst := filepath.Join(to, name)
+		if _, err := os.Stat(dst); err == nil || !os.IsNotExist(err) {
+			continue
+		}
+		if err := copyNinjaStateFile(filepath.Join(from, name), dst); err != nil && !os.IsNotExist(err) {
+			ctx.Verbosef("failed to copy the %s of the unscoped builds: %s", name, err)
+		}
+	}
+}
+
+func copyNinjaStateFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+		return err
+	}
+	// Copy to a temporary file first, so an interrupted copy doesn't leave a truncated log behind.
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
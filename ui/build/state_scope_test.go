@@ -0,0 +1,158 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateStateScope(t *testing.T) {
+	for _, scope := range []string{"", "alice", "bob-main", "ci_1.2"} {
+		if err := validateStateScope(scope); err != nil {
+			t.Errorf("expected %q to be valid, got %s", scope, err)
+		}
+	}
+	for _, scope := range []string{"..", ".hidden", "alice/main", "-bob", "a b"} {
+		if err := validateStateScope(scope); err == nil {
+			t.Errorf("expected %q to be invalid", scope)
+		}
+	}
+}
+
+func TestSoongStateDir(t *testing.T) {
+	unscoped := Config{&configImpl{environ: &Environment{"OUT_DIR=out"}, katiSuffix: "-test"}}
+	if got, expected := unscoped.TempDir(), filepath.Join("out", "soong", ".temp"); got != expected {
+		t.Errorf("expected the temporary directory %q, got %q", expected, got)
+	}
+
+	scoped := Config{&configImpl{environ: &Environment{"OUT_DIR=out", "SOONG_STATE_SCOPE=alice-main"}, katiSuffix: "-test"}}
+	stateDir := filepath.Join("out", "soong", "scopes", "alice-main")
+	if got := scoped.SoongStateDir(); got != stateDir {
+		t.Errorf("expected the state directory %q, got %q", stateDir, got)
+	}
+	for _, file := range []string{
+		scoped.TempDir(),
+		scoped.UsedEnvFile(soongBuildTag),
+		scoped.Bp2BuildFilesMarkerFile(),
+		scoped.QueryviewMarkerFile(),
+		scoped.BootstrapNinjaFile(),
+		scoped.SoongNinjaFile(),
+		scoped.NamedGlobFile(soongBuildTag),
+		scoped.KatiBuildNinjaFile(),
+		scoped.CombinedNinjaFile(),
+		filepath.Join(scoped.NinjaStateDir(), ninjaLogFileName),
+		scoped.BootstrapNinjaBuildDir(),
+	} {
+		if !strings.HasPrefix(file, stateDir+string(filepath.Separator)) {
+			t.Errorf("expected %q to be in %q", file, stateDir)
+		}
+	}
+	if got, expected := scoped.GlobListDir(soongBuildTag), filepath.Join("scopes", "alice-main", soongBuildTag); got != expected {
+		t.Errorf("expected the glob list directory %q, got %q", expected, got)
+	}
+
+	for file, expected := range map[string]string{
+		unscoped.SoongNinjaFile():           filepath.Join("out", "soong", "build.ninja"),
+		unscoped.CombinedNinjaFile():        filepath.Join("out", "combined-test.ninja"),
+		unscoped.BootstrapNinjaBuildDir():   filepath.Join("out", "soong"),
+		unscoped.NinjaStateDir():            "out",
+		unscoped.GlobListDir("soong_build"): "soong_build",
+	} {
+		if file != expected {
+			t.Errorf("expected the unscoped path %q, got %q", expected, file)
+		}
+	}
+}
+
+func TestScopedLocks(t *testing.T) {
+	outDir := t.TempDir()
+	lock := func(shared bool, dir string) (*fileLock, error) {
+		t.Helper()
+		l, err := newLock(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Shared = shared
+		t.Cleanup(func() { l.Unlock() })
+		return l, l.tryLock()
+	}
+
+	// Builds in different scopes share the lock of the output directory.
+	if _, err := lock(true, outDir); err != nil {
+		t.Fatalf("failed to take a shared lock: %s", err)
+	}
+	if _, err := lock(true, outDir); err != nil {
+		t.Errorf("expected a second shared lock to succeed, got %s", err)
+	}
+	if _, err := lock(false, filepath.Join(outDir, "soong", "scopes", "alice")); err != nil {
+		t.Errorf("failed to lock the first scope: %s", err)
+	}
+	if _, err := lock(false, filepath.Join(outDir, "soong", "scopes", "bob")); err != nil {
+		t.Errorf("expected the lock of another scope to succeed, got %s", err)
+	}
+
+	// Builds in the same scope and unscoped builds wait.
+	if _, err := lock(false, filepath.Join(outDir, "soong", "scopes", "alice")); err == nil {
+		t.Errorf("expected the lock of the same scope to fail")
+	}
+	if _, err := lock(false, outDir); err == nil {
+		t.Errorf("expected the exclusive lock of the output directory to fail")
+	}
+}
+
+func TestSeedNinjaState(t *testing.T) {
+	ctx := testContext()
+	outDir := t.TempDir()
+	scopeDir := filepath.Join(outDir, "soong", "scopes", "alice")
+	if err := os.WriteFile(filepath.Join(outDir, ninjaLogFileName), []byte("shared log"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first build of a scope starts from the log of the unscoped builds, without the missing
+	// deps log.
+	seedNinjaState(ctx, outDir, scopeDir)
+	if data, err := os.ReadFile(filepath.Join(scopeDir, ninjaLogFileName)); err != nil || string(data) != "shared log" {
+		t.Errorf("expected the shared log to be copied, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(scopeDir, ".ninja_deps")); !os.IsNotExist(err) {
+		t.Errorf("expected no deps log, got %v", err)
+	}
+
+	// Later builds keep the log of the scope.
+	if err := os.WriteFile(filepath.Join(scopeDir, ninjaLogFileName), []byte("scoped log"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	seedNinjaState(ctx, outDir, scopeDir)
+	if data, err := os.ReadFile(filepath.Join(scopeDir, ninjaLogFileName)); err != nil || string(data) != "scoped log" {
+		t.Errorf("expected the log of the scope to be kept, got %q, %v", data, err)
+	}
+}
+
+func TestScopedBootstrapNinjaFile(t *testing.T) {
+	outDir := t.TempDir()
+	config := Config{&configImpl{environ: &Environment{"OUT_DIR=" + outDir, "SOONG_STATE_SCOPE=alice"}}}
+	file := scopedBootstrapNinjaFile(testContext(), config)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "builddir = " + config.BootstrapNinjaBuildDir() + "\nsubninja " + config.BootstrapNinjaFile() + "\n"
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
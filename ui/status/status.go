@@ -213,6 +213,16 @@ type StatusOutput interface {
 	Write(p []byte) (n int, err error)
 }
 
+// PhaseStatusOutput is implemented by the StatusOutputs that show the phase of the build, e.g.
+// "soong" or "kati build".
+type PhaseStatusOutput interface {
+	// BeginPhase is called when a phase of the build starts. Phases may be nested.
+	BeginPhase(name string)
+
+	// EndPhase is called when the last phase that was started ends.
+	EndPhase()
+}
+
 // Status is the multiplexer / accumulator between ToolStatus instances (via
 // StartTool) and StatusOutputs (via AddOutput). There's generally one of these
 // per build process (though tools like multiproduct_kati may have multiple
@@ -258,6 +268,31 @@ func (s *Status) Finish() {
 	}
 }
 
+// BeginPhase reports the start of a phase of the build to the outputs that show it.
+func (s *Status) BeginPhase(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, o := range s.outputs {
+		if p, ok := o.(PhaseStatusOutput); ok {
+			p.BeginPhase(name)
+		}
+	}
+}
+
+// EndPhase reports the end of the last phase of the build that was started to the outputs that
+// show it.
+func (s *Status) EndPhase() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, o := range s.outputs {
+		if p, ok := o.(PhaseStatusOutput); ok {
+			p.EndPhase()
+		}
+	}
+}
+
 func (s *Status) updateTotalActions(diff int) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -423,6 +423,10 @@ func (ansiImpl) bold() string {
 	return "\x1b[1m"
 }
 
+func (ansiImpl) reverse() string {
+	return "\x1b[7m"
+}
+
 func (ansiImpl) regular() string {
 	return "\x1b[0m"
 }
@@ -16,6 +16,7 @@ package terminal
 
 import (
 	"io"
+	"os"
 
 	"android/soong/ui/status"
 )
@@ -26,10 +27,19 @@ import (
 //
 // statusFormat takes nearly all the same options as NINJA_STATUS.
 // %c is currently unsupported.
-func NewStatusOutput(w io.Writer, statusFormat string, forceSimpleOutput, quietBuild, forceKeepANSI bool) status.StatusOutput {
+//
+// useTui replaces the status of smart terminals with a terminal UI.
+func NewStatusOutput(w io.Writer, statusFormat string, forceSimpleOutput, quietBuild, forceKeepANSI, useTui bool) status.StatusOutput {
 	formatter := newFormatter(statusFormat, quietBuild)
 
 	if !forceSimpleOutput && isSmartTerminal(w) {
+		if useTui {
+			var input *os.File
+			if isSmartTerminal(os.Stdin) {
+				input = os.Stdin
+			}
+			return NewTuiStatusOutput(w, input, formatter)
+		}
 		return NewSmartStatusOutput(w, formatter)
 	} else {
 		return NewSimpleStatusOutput(w, formatter, forceKeepANSI)
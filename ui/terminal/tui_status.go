@@ -0,0 +1,514 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"android/soong/ui/status"
+)
+
+const (
+	// The number of lines of the panel above the running actions: the phase, the progress and the
+	// last status message.
+	tuiHeaderHeight = 3
+	// The number of lines of the panel below the running actions, that show the command line of
+	// the selected action.
+	tuiCommandHeight = 4
+	// The maximum number of running actions shown in the panel.
+	tuiMaxActionRows = 10
+	// The period over which the throughput of the actions is computed.
+	tuiThroughputWindow = 5 * time.Second
+	// The period at which the panel is redrawn.
+	tuiRefreshPeriod = 250 * time.Millisecond
+)
+
+// tuiStatusOutput is a full terminal UI that shows the current phase of the build, its progress and
+// throughput, and the actions that are currently running. The running actions can be selected with
+// the arrow keys, and enter shows the command line of the selected action. The output of the
+// actions and the messages scroll above the panel.
+type tuiStatusOutput struct {
+	writer    io.Writer
+	formatter formatter
+	input     *os.File
+
+	lock sync.Mutex
+
+	termWidth, termHeight int
+	actionRows            int
+
+	phases        []string
+	counts        status.Counts
+	statusMessage string
+	startTime     time.Time
+	finishTimes   []time.Time
+
+	runningActions []actionTableEntry
+	selected       int
+	showCommand    bool
+
+	flushed         bool
+	savedTermios    *syscall.Termios
+	ticker          *time.Ticker
+	done            chan bool
+	sigwinch        chan os.Signal
+	sigwinchHandled chan bool
+}
+
+// NewTuiStatusOutput returns a StatusOutput that shows the status of the build in a terminal UI.
+// Keys are read from input if it is not nil. It falls back to the smart terminal status if the
+// terminal is too small for the panel.
+func NewTuiStatusOutput(w io.Writer, input *os.File, formatter formatter) status.StatusOutput {
+	width, height, ok := termSize(w)
+	if !ok || height < tuiHeaderHeight+1+tuiCommandHeight+2 {
+		return NewSmartStatusOutput(w, formatter)
+	}
+
+	s := &tuiStatusOutput{
+		writer:    w,
+		formatter: formatter,
+		input:     input,
+
+		termWidth:  width,
+		termHeight: height,
+
+		startTime: time.Now(),
+
+		done:     make(chan bool),
+		sigwinch: make(chan os.Signal),
+	}
+	s.computeActionRows()
+
+	// Add empty lines at the bottom of the screen to scroll back the existing history and make
+	// room for the panel.
+	for i := 0; i < s.panelHeight(); i++ {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprint(w, ansi.hideCursor())
+	s.render()
+
+	if s.input != nil && s.enableRawInput() {
+		go s.readInput()
+	}
+	s.startTick()
+	s.startSigwinch()
+
+	return s
+}
+
+func (s *tuiStatusOutput) BeginPhase(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.phases = append(s.phases, name)
+}
+
+func (s *tuiStatusOutput) EndPhase() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.phases) > 0 {
+		s.phases = s.phases[:len(s.phases)-1]
+	}
+}
+
+func (s *tuiStatusOutput) Message(level status.MsgLevel, message string) {
+	if level < status.StatusLvl {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if level > status.StatusLvl {
+		s.print(s.formatter.message(level, message))
+	} else {
+		s.statusMessage = message
+	}
+}
+
+func (s *tuiStatusOutput) StartAction(action *status.Action, counts status.Counts) {
+	startTime := time.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.counts = counts
+	s.runningActions = append(s.runningActions, actionTableEntry{
+		action:    action,
+		startTime: startTime,
+	})
+}
+
+func (s *tuiStatusOutput) FinishAction(result status.ActionResult, counts status.Counts) {
+	finishTime := time.Now()
+	output := s.formatter.result(result)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.counts = counts
+	s.finishTimes = append(s.finishTimes, finishTime)
+	for i, runningAction := range s.runningActions {
+		if runningAction.action == result.Action {
+			s.runningActions = append(s.runningActions[:i], s.runningActions[i+1:]...)
+			if i < s.selected {
+				s.selected--
+			}
+			break
+		}
+	}
+	s.clampSelection()
+
+	if output != "" {
+		s.print(output)
+	}
+}
+
+func (s *tuiStatusOutput) Flush() {
+	// Stop the tick outside of the lock, the goroutine in startTick may be waiting for the lock.
+	s.stopTick()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.flushed {
+		return
+	}
+	s.flushed = true
+
+	s.stopSigwinch()
+	s.restoreInput()
+
+	// Clear the panel, then give the whole terminal back to the scrolling region.
+	s.runningActions = nil
+	s.phases = nil
+	s.statusMessage = ""
+	s.render()
+	fmt.Fprint(s.writer, ansi.resetScrollingMargins())
+	fmt.Fprint(s.writer, ansi.setCursor(s.termHeight-s.panelHeight(), 1))
+	fmt.Fprint(s.writer, ansi.showCursor())
+}
+
+func (s *tuiStatusOutput) Write(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.print(string(p))
+	return len(p), nil
+}
+
+// print writes text to the scrolling region above the panel.
+func (s *tuiStatusOutput) print(str string) {
+	fmt.Fprint(s.writer, ansi.setCursor(s.scrollingHeight(), 1))
+	fmt.Fprint(s.writer, str)
+	if len(str) == 0 || str[len(str)-1] != '\n' {
+		fmt.Fprint(s.writer, "\n")
+	}
+}
+
+func (s *tuiStatusOutput) computeActionRows() {
+	rows := s.termHeight - tuiHeaderHeight - tuiCommandHeight - 1
+	if rows > tuiMaxActionRows {
+		rows = tuiMaxActionRows
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	s.actionRows = rows
+}
+
+func (s *tuiStatusOutput) panelHeight() int {
+	return tuiHeaderHeight + s.actionRows + tuiCommandHeight
+}
+
+func (s *tuiStatusOutput) scrollingHeight() int {
+	return s.termHeight - s.panelHeight()
+}
+
+func (s *tuiStatusOutput) clampSelection() {
+	if s.selected >= len(s.runningActions) {
+		s.selected = len(s.runningActions) - 1
+	}
+	if s.selected < 0 {
+		s.selected = 0
+	}
+}
+
+// throughput returns the number of actions finished per second over the last
+// tuiThroughputWindow.
+func (s *tuiStatusOutput) throughput(now time.Time) float64 {
+	cutoff := now.Add(-tuiThroughputWindow)
+	i := 0
+	for i < len(s.finishTimes) && s.finishTimes[i].Before(cutoff) {
+		i++
+	}
+	s.finishTimes = s.finishTimes[i:]
+
+	window := now.Sub(s.startTime)
+	if window > tuiThroughputWindow {
+		window = tuiThroughputWindow
+	}
+	if window <= 0 {
+		return 0
+	}
+	return float64(len(s.finishTimes)) / window.Seconds()
+}
+
+// panelLines returns the lines of the panel, without the control codes that position them.
+func (s *tuiStatusOutput) panelLines(now time.Time) []string {
+	lines := make([]string, 0, s.panelHeight())
+
+	phase := "waiting"
+	if len(s.phases) > 0 {
+		phase = strings.Join(s.phases, " > ")
+	}
+	lines = append(lines, ansi.bold()+elide("phase: "+phase, s.termWidth)+ansi.regular())
+
+	progress := ""
+	if s.counts.TotalActions > 0 {
+		progress = s.formatter.progress(s.counts)
+	}
+	lines = append(lines, elide(fmt.Sprintf("%s%s %.1f actions/s, %d running",
+		progress, progressBar(s.counts, 20), s.throughput(now), len(s.runningActions)), s.termWidth))
+
+	lines = append(lines, elide(s.statusMessage, s.termWidth))
+
+	// Scroll the running actions so that the selected action is visible.
+	first := 0
+	if s.selected >= s.actionRows {
+		first = s.selected - s.actionRows + 1
+	}
+	for row := 0; row < s.actionRows; row++ {
+		i := first + row
+		if i >= len(s.runningActions) {
+			lines = append(lines, "")
+			continue
+		}
+		entry := s.runningActions[i]
+		seconds := int(now.Sub(entry.startTime).Round(time.Second).Seconds())
+		desc := entry.action.Description
+		if desc == "" {
+			desc = entry.action.Command
+		}
+		marker := "  "
+		if i == s.selected {
+			marker = "> "
+		}
+		prefix := fmt.Sprintf("%s%2d:%02d ", marker, seconds/60, seconds%60)
+		line := prefix + elide(desc, s.termWidth-len(prefix))
+		if i == s.selected {
+			line = ansi.reverse() + line + ansi.regular()
+		}
+		lines = append(lines, line)
+	}
+
+	var command []string
+	if s.showCommand && s.selected < len(s.runningActions) {
+		command = wrap(s.runningActions[s.selected].action.Command, s.termWidth, tuiCommandHeight)
+	} else {
+		command = []string{elide("up/down: select an action, enter: show its command line", s.termWidth)}
+	}
+	for row := 0; row < tuiCommandHeight; row++ {
+		if row < len(command) {
+			lines = append(lines, command[row])
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	return lines
+}
+
+// render redraws the panel at the bottom of the terminal.
+func (s *tuiStatusOutput) render() {
+	scrollingHeight := s.scrollingHeight()
+
+	// Update the scrolling region in case the height of the terminal changed.
+	fmt.Fprint(s.writer, ansi.setScrollingMargins(1, scrollingHeight))
+
+	for i, line := range s.panelLines(time.Now()) {
+		fmt.Fprint(s.writer, ansi.setCursor(scrollingHeight+1+i, 1), line, ansi.clearToEndOfLine())
+	}
+
+	// Move the cursor back to the last line of the scrolling region.
+	fmt.Fprint(s.writer, ansi.setCursor(scrollingHeight, 1))
+}
+
+// handleInput handles the keys read from the terminal.
+func (s *tuiStatusOutput) handleInput(input []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.flushed {
+		return
+	}
+
+	for len(input) > 0 {
+		switch {
+		case strings.HasPrefix(string(input), "\x1b[A"):
+			s.selected--
+			input = input[3:]
+		case strings.HasPrefix(string(input), "\x1b[B"):
+			s.selected++
+			input = input[3:]
+		case input[0] == 'k':
+			s.selected--
+			input = input[1:]
+		case input[0] == 'j':
+			s.selected++
+			input = input[1:]
+		case input[0] == '\r' || input[0] == '\n':
+			s.showCommand = !s.showCommand
+			input = input[1:]
+		default:
+			input = input[1:]
+		}
+	}
+	s.clampSelection()
+	s.render()
+}
+
+func (s *tuiStatusOutput) readInput() {
+	buf := make([]byte, 64)
+	for {
+		n, err := s.input.Read(buf)
+		if n > 0 {
+			s.handleInput(buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// enableRawInput turns off the line buffering and the echo of the terminal, so that keys can be
+// read as they are pressed. It returns false if the input is not a terminal.
+func (s *tuiStatusOutput) enableRawInput() bool {
+	var termios syscall.Termios
+	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL, s.input.Fd(),
+		ioctlGetTermios, uintptr(unsafe.Pointer(&termios)), 0, 0, 0); err != 0 {
+		return false
+	}
+	saved := termios
+	termios.Lflag &^= syscall.ICANON | syscall.ECHO
+	termios.Cc[syscall.VMIN] = 1
+	termios.Cc[syscall.VTIME] = 0
+	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL, s.input.Fd(),
+		ioctlSetTermios, uintptr(unsafe.Pointer(&termios)), 0, 0, 0); err != 0 {
+		return false
+	}
+	s.savedTermios = &saved
+	return true
+}
+
+func (s *tuiStatusOutput) restoreInput() {
+	if s.savedTermios == nil {
+		return
+	}
+	syscall.Syscall6(syscall.SYS_IOCTL, s.input.Fd(),
+		ioctlSetTermios, uintptr(unsafe.Pointer(s.savedTermios)), 0, 0, 0)
+	s.savedTermios = nil
+}
+
+func (s *tuiStatusOutput) startTick() {
+	s.ticker = time.NewTicker(tuiRefreshPeriod)
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.lock.Lock()
+				if !s.flushed {
+					s.render()
+				}
+				s.lock.Unlock()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+func (s *tuiStatusOutput) stopTick() {
+	if s.ticker == nil {
+		return
+	}
+	s.ticker.Stop()
+	s.done <- true
+	s.ticker = nil
+}
+
+func (s *tuiStatusOutput) startSigwinch() {
+	signal.Notify(s.sigwinch, syscall.SIGWINCH)
+	go func() {
+		for range s.sigwinch {
+			s.lock.Lock()
+			if w, h, ok := termSize(s.writer); ok {
+				s.termWidth, s.termHeight = w, h
+				s.computeActionRows()
+				s.render()
+			}
+			s.lock.Unlock()
+			if s.sigwinchHandled != nil {
+				s.sigwinchHandled <- true
+			}
+		}
+	}()
+}
+
+func (s *tuiStatusOutput) stopSigwinch() {
+	signal.Stop(s.sigwinch)
+	close(s.sigwinch)
+}
+
+// progressBar returns a bar of the given width that shows the fraction of the actions that are
+// finished.
+func progressBar(counts status.Counts, width int) string {
+	filled := 0
+	if counts.TotalActions > 0 {
+		filled = counts.FinishedActions * width / counts.TotalActions
+	}
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", width-filled) + "]"
+}
+
+// wrap splits a string into at most maxLines lines of the given width, eliding the end of the last
+// line if the string doesn't fit.
+func wrap(str string, width, maxLines int) []string {
+	if width <= 0 {
+		return []string{str}
+	}
+	var lines []string
+	for len(str) > 0 && len(lines) < maxLines {
+		if len(str) <= width {
+			lines = append(lines, str)
+			return lines
+		}
+		lines = append(lines, str[:width])
+		str = str[width:]
+	}
+	if len(str) > 0 && len(lines) > 0 && width > 3 {
+		last := lines[len(lines)-1]
+		lines[len(lines)-1] = last[:width-3] + "..."
+	}
+	return lines
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"android/soong/ui/status"
+)
+
+func TestTuiStatusOutput(t *testing.T) {
+	term := &fakeSmartTerminal{termWidth: 60, termHeight: 30}
+	stat := NewTuiStatusOutput(term, nil, newFormatter("", false))
+	tui, ok := stat.(*tuiStatusOutput)
+	if !ok {
+		t.Fatalf("expected a terminal UI, got %T", stat)
+	}
+
+	panel := func() []string {
+		tui.lock.Lock()
+		defer tui.lock.Unlock()
+		return tui.panelLines(time.Now())
+	}
+	contains := func(lines []string, s string) bool {
+		for _, line := range lines {
+			if strings.Contains(line, s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	tui.BeginPhase("soong")
+	tui.BeginPhase("blueprint bootstrap")
+	runner := newRunner(stat, 3)
+	action1 := &status.Action{Description: "action1", Command: "touch out1"}
+	action2 := &status.Action{Description: "action2", Command: "touch out2"}
+	runner.startAction(action1)
+	runner.startAction(action2)
+	stat.Message(status.StatusLvl, "including build/make/core/main.mk ...")
+
+	lines := panel()
+	if !strings.Contains(lines[0], "phase: soong > blueprint bootstrap") {
+		t.Errorf("expected the phases in %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "[  0% 0/3] [....................]") {
+		t.Errorf("expected the progress in %q", lines[1])
+	}
+	if lines[2] != "including build/make/core/main.mk ..." {
+		t.Errorf("expected the status message, got %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "> ") || !strings.Contains(lines[3], "action1") {
+		t.Errorf("expected action1 to be selected, got %q", lines[3])
+	}
+	if contains(lines, "touch out") {
+		t.Errorf("expected no command line, got %q", lines)
+	}
+
+	// Select the second action and show its command line.
+	tui.handleInput([]byte("\x1b[B\r"))
+	lines = panel()
+	if !strings.Contains(lines[4], "> ") || !strings.Contains(lines[4], "action2") {
+		t.Errorf("expected action2 to be selected, got %q", lines[4])
+	}
+	if !contains(lines, "touch out2") {
+		t.Errorf("expected the command line of action2, got %q", lines)
+	}
+
+	// Finishing the selected action moves the selection to the remaining action.
+	runner.finishAction(status.ActionResult{Action: action2, Output: "output2"})
+	lines = panel()
+	if !strings.Contains(lines[3], "> ") || !strings.Contains(lines[3], "action1") {
+		t.Errorf("expected action1 to be selected, got %q", lines[3])
+	}
+	if !contains(lines, "touch out1") {
+		t.Errorf("expected the command line of action1, got %q", lines)
+	}
+
+	tui.EndPhase()
+	lines = panel()
+	if !strings.Contains(lines[0], "phase: soong") || strings.Contains(lines[0], "bootstrap") {
+		t.Errorf("expected the outer phase in %q", lines[0])
+	}
+
+	runner.finishAction(status.ActionResult{Action: action1})
+	stat.Flush()
+
+	output := term.String()
+	if !strings.Contains(output, "output2\n") {
+		t.Errorf("expected the output of action2 in %q", output)
+	}
+	if !strings.HasSuffix(output, ansi.resetScrollingMargins()+ansi.setCursor(13, 1)+ansi.showCursor()) {
+		t.Errorf("expected the terminal to be restored, got %q", output)
+	}
+}
+
+func TestTuiStatusOutputSmallTerminal(t *testing.T) {
+	term := &fakeSmartTerminal{termWidth: 60, termHeight: 5}
+	stat := NewTuiStatusOutput(term, nil, newFormatter("", false))
+	if _, ok := stat.(*smartStatusOutput); !ok {
+		t.Errorf("expected the smart terminal status, got %T", stat)
+	}
+	stat.Flush()
+}
+
+func TestWrap(t *testing.T) {
+	testCases := []struct {
+		str      string
+		width    int
+		maxLines int
+		expected []string
+	}{
+		{"abc", 5, 2, []string{"abc"}},
+		{"abcdefgh", 5, 2, []string{"abcde", "fgh"}},
+		{"abcdefghijklmno", 5, 2, []string{"abcde", "fg..."}},
+	}
+	for _, tc := range testCases {
+		if got := wrap(tc.str, tc.width, tc.maxLines); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("wrap(%q, %d, %d): expected %q, got %q", tc.str, tc.width, tc.maxLines, tc.expected, got)
+		}
+	}
+}
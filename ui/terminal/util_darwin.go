@@ -18,4 +18,7 @@ import (
 	"syscall"
 )
 
-const ioctlGetTermios = syscall.TIOCGETA
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
@@ -18,4 +18,7 @@ import (
 	"syscall"
 )
 
-const ioctlGetTermios = syscall.TCGETS
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
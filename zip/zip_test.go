@@ -24,6 +24,7 @@ import (
 	"syscall"
 	"testing"
 
+	"android/soong/jar"
 	"android/soong/third_party/zip"
 
 	"github.com/google/blueprint/pathtools"
@@ -128,6 +129,9 @@ func fileArgsBuilder() *FileArgsBuilder {
 }
 
 func TestZip(t *testing.T) {
+	var defaultTime zip.FileHeader
+	defaultTime.SetModTime(jar.DefaultTime)
+
 	testCases := []struct {
 		name               string
 		args               *FileArgsBuilder
@@ -603,6 +607,13 @@ func TestZip(t *testing.T) {
 					t.Errorf("incorrect file %s extra want %v got %v", want.Name,
 						want.Extra, got.Extra)
 				}
+
+				// The entries have a fixed time rather than the time of the build, so the zip
+				// files don't depend on SOURCE_DATE_EPOCH.
+				if got.ModifiedDate != defaultTime.ModifiedDate || got.ModifiedTime != defaultTime.ModifiedTime {
+					t.Errorf("incorrect file %s time want %v got %v", want.Name,
+						defaultTime.ModTime(), got.ModTime())
+				}
 			}
 		})
 	}